@@ -0,0 +1,42 @@
+// Package main prints the mera_settings value for an encrypted setting.
+//
+// Usage:
+//
+//	SETTINGS_ENCRYPTION_KEY=... go run ./cmd/encrypt-setting "<plain value>"
+//
+// Store the output in setting_value with value_encrypted = 1.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/clinova/simrs/backend/internal/common/config"
+	"github.com/clinova/simrs/backend/pkg/secret"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("usage: encrypt-setting <plain value>")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	cipher, err := secret.NewCipher(cfg.Settings.EncryptionKey)
+	if err != nil {
+		log.Fatalf("Failed to initialize cipher: %v", err)
+	}
+
+	encrypted, err := cipher.Encrypt(os.Args[1])
+	if err != nil {
+		log.Fatalf("Failed to encrypt value: %v", err)
+	}
+
+	value, _ := json.Marshal(encrypted)
+	fmt.Println(string(value))
+}
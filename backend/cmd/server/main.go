@@ -2,8 +2,13 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"time"
 
 	auditlogHandler "github.com/clinova/simrs/backend/internal/auditlog/handler"
 	"github.com/clinova/simrs/backend/internal/auth/handler"
@@ -17,6 +22,9 @@ import (
 	"github.com/clinova/simrs/backend/pkg/audit"
 	"github.com/clinova/simrs/backend/pkg/jwt"
 	"github.com/clinova/simrs/backend/pkg/password"
+	"github.com/clinova/simrs/backend/pkg/redis"
+	"github.com/clinova/simrs/backend/pkg/secret"
+	"github.com/clinova/simrs/backend/pkg/storage"
 )
 
 func main() {
@@ -47,6 +55,7 @@ func main() {
 	roleRepo := repository.NewMySQLRoleRepository(db)
 	permissionRepo := repository.NewMySQLPermissionRepository(db)
 	sessionRepo := repository.NewMySQLSessionRepository(db)
+	mfaRepo := repository.NewMySQLMFARepository(db)
 
 	_ = roleRepo // Available for future role management
 
@@ -54,29 +63,110 @@ func main() {
 	jwtManager := jwt.NewManager(cfg.JWT.Secret, cfg.JWT.AccessTokenExpiry, cfg.JWT.RefreshTokenExpiry)
 	passwordHasher := password.NewHasher(cfg.Bcrypt.Cost)
 
+	// Initialize settings cipher for encrypted bridging credentials and MFA secrets
+	settingsCipher, err := secret.NewCipher(cfg.Settings.EncryptionKey)
+	if err != nil {
+		log.Println("Warning: SETTINGS_ENCRYPTION_KEY not set, encrypted settings and MFA are unavailable")
+	}
+
 	// Initialize services (with audit logger)
-	authService := service.NewAuthService(userRepo, sessionRepo, permissionRepo, jwtManager, passwordHasher, auditLogger)
+	mfaService := service.NewMFAService(mfaRepo, userRepo, permissionRepo, settingsCipher, passwordHasher, auditLogger, service.MFAPolicy{
+		Issuer:              cfg.MFA.Issuer,
+		ChallengeExpiry:     cfg.MFA.ChallengeExpiry,
+		RequiredRoles:       cfg.MFA.RequiredRoles,
+		RequiredPermissions: cfg.MFA.RequiredPermissions,
+	})
+	if !mfaService.Available() && (len(cfg.MFA.RequiredRoles) > 0 || len(cfg.MFA.RequiredPermissions) > 0) {
+		log.Println("Warning: MFA is required by MFA_REQUIRED_ROLES/MFA_REQUIRED_PERMISSIONS but cannot be enforced without SETTINGS_ENCRYPTION_KEY")
+	}
+	passwordService := service.NewPasswordService(userRepo, passwordHasher, service.PasswordPolicy{
+		Policy: password.Policy{
+			MinLength:     cfg.Password.MinLength,
+			RequireUpper:  cfg.Password.RequireUpper,
+			RequireLower:  cfg.Password.RequireLower,
+			RequireDigit:  cfg.Password.RequireDigit,
+			RequireSymbol: cfg.Password.RequireSymbol,
+			RejectCommon:  cfg.Password.RejectCommon,
+		},
+		HistoryCount: cfg.Password.HistoryCount,
+		MaxAge:       cfg.Password.MaxAge,
+		ChangeExpiry: cfg.Password.ChangeExpiry,
+	})
+	// Staff and verifier logins share one lockout policy
+	lockoutPolicy := service.LockoutPolicy{
+		Threshold:    cfg.Lockout.Threshold,
+		BaseDuration: cfg.Lockout.BaseDuration,
+		MaxDuration:  cfg.Lockout.MaxDuration,
+		ResetAfter:   cfg.Lockout.ResetAfter,
+	}
+	authService := service.NewAuthService(userRepo, sessionRepo, permissionRepo, jwtManager, passwordHasher, passwordService, mfaService, lockoutPolicy, cfg.JWT.RefreshReuseGrace, auditLogger)
 	sessionService := service.NewSessionService(sessionRepo, userRepo, auditLogger)
 	permissionService := service.NewPermissionService(permissionRepo, userRepo)
 
+	// Background jobs and the server stop on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Connect to Redis for rate limits shared across instances; per-process
+	// memory only when it is not configured. A configured Redis that stays
+	// unreachable stops startup, so instances never split their limits.
+	var redisClient *redis.Client
+	if cfg.Redis.Addr != "" {
+		redisClient = redis.NewClient(redis.Config{
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+		defer redisClient.Close()
+		if err := redisClient.PingRetry(ctx, 5, 2*time.Second); err != nil {
+			log.Fatalf("Failed to connect to Redis %s: %v", cfg.Redis.Addr, err)
+		}
+		log.Println("Rate limit store: redis", cfg.Redis.Addr)
+	} else {
+		log.Println("Rate limit store: memory")
+	}
+
 	// Initialize auth router
-	authRouter := handler.NewRouter(jwtManager, authService, sessionService, permissionService)
+	authRouter := handler.NewRouter(jwtManager, authService, sessionService, permissionService, mfaService, passwordService, redisClient)
 
 	// Initialize middleware for other routers
 	jwtMiddleware := middleware.NewJWTMiddleware(jwtManager, sessionService)
 	permMiddleware := middleware.NewPermissionMiddleware(permissionService)
 
 	// Initialize user management router
-	usermgmtRouter := usermgmtHandler.NewRouter(db, auditLogger, passwordHasher, jwtMiddleware, permMiddleware)
+	usermgmtRouter := usermgmtHandler.NewRouter(db, auditLogger, passwordService, jwtMiddleware, permMiddleware)
 	usermgmtRouter.RegisterRoutes(authRouter.GetEngine(), permissionService)
 
 	// Initialize audit log router
 	auditlogRouter := auditlogHandler.NewRouter(auditLogPath, jwtMiddleware, permMiddleware)
 	auditlogRouter.RegisterRoutes(authRouter.GetEngine())
 
+	// Initialize document storage for uploaded claim files
+	documentStore, err := storage.New(storage.Config{
+		Driver:    cfg.Storage.Driver,
+		LocalRoot: cfg.Storage.LocalRoot,
+		S3: storage.S3Config{
+			Endpoint:  cfg.Storage.S3Endpoint,
+			Region:    cfg.Storage.S3Region,
+			Bucket:    cfg.Storage.S3Bucket,
+			AccessKey: cfg.Storage.S3AccessKey,
+			SecretKey: cfg.Storage.S3SecretKey,
+			Prefix:    cfg.Storage.S3Prefix,
+			PathStyle: cfg.Storage.S3PathStyle,
+		},
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize document storage: %v", err)
+	}
+	log.Println("Document storage:", cfg.Storage.Driver)
+
 	// Initialize Vedika router
-	vedikaRouter := vedikaHandler.NewRouter(db, auditLogger, jwtMiddleware, permMiddleware)
+	vedikaRouter := vedikaHandler.NewRouter(db, auditLogger, cfg.Settings.Environment, settingsCipher, documentStore, jwtManager, passwordHasher, lockoutPolicy, jwtMiddleware, permMiddleware, redisClient, middleware.RateLimitConfig{
+		MaxRequests: cfg.RateLimit.HeavyMax,
+		Window:      cfg.RateLimit.HeavyWindow,
+	})
 	vedikaRouter.RegisterRoutes(authRouter.GetEngine(), permissionService)
+	vedikaRouter.StartBackgroundJobs(ctx)
 
 	// Start server
 	addr := ":" + cfg.Server.Port
@@ -84,15 +174,26 @@ func main() {
 	log.Println("API Endpoints:")
 	log.Println("  Auth:")
 	log.Println("    POST /auth/login")
+	log.Println("    POST /auth/login/mfa")
+	log.Println("    POST /auth/login/mfa/enroll")
+	log.Println("    POST /auth/login/password")
+	log.Println("    GET  /auth/password/policy")
 	log.Println("    POST /auth/logout")
 	log.Println("    POST /auth/refresh")
 	log.Println("    GET  /auth/me")
 	log.Println("    GET  /auth/sessions")
 	log.Println("    POST /auth/sessions/:id/revoke")
+	log.Println("    POST /auth/password")
+	log.Println("    GET  /auth/mfa")
+	log.Println("    POST /auth/mfa/enroll")
+	log.Println("    POST /auth/mfa/activate")
+	log.Println("    POST /auth/mfa/disable")
+	log.Println("    POST /auth/mfa/recovery-codes")
 	log.Println("  User Management:")
 	log.Println("    GET/POST  /admin/users")
 	log.Println("    GET/PUT   /admin/users/:id")
 	log.Println("    POST      /admin/users/:id/copy-access")
+	log.Println("    POST      /admin/users/:id/unlock")
 	log.Println("  Role Management:")
 	log.Println("    GET/POST  /admin/roles")
 	log.Println("    PUT/DEL   /admin/roles/:id")
@@ -104,16 +205,52 @@ func main() {
 	log.Println("  Vedika (Claim Management):")
 	log.Println("    GET       /admin/vedika/dashboard")
 	log.Println("    GET       /admin/vedika/dashboard/trend")
+	log.Println("    GET       /admin/vedika/dashboard/breakdown")
+	log.Println("    GET       /admin/vedika/reports/variance")
+	log.Println("    GET       /admin/vedika/reports/aging")
 	log.Println("    GET       /admin/vedika/index")
+	log.Println("    GET       /admin/vedika/index/export")
 	log.Println("    GET       /admin/vedika/claim/:no_rawat")
 	log.Println("    POST      /admin/vedika/claim/:no_rawat/status")
+	log.Println("    POST      /admin/vedika/claim/assign/:no_rawat")
+	log.Println("    POST      /admin/vedika/claim/unassign/:no_rawat")
+	log.Println("    POST/PUT  /admin/vedika/claim/lock/:no_rawat")
+	log.Println("    DELETE    /admin/vedika/claim/lock/:no_rawat")
+	log.Println("    POST      /admin/vedika/claim/lock-break/:no_rawat")
+	log.Println("    POST      /admin/vedika/assignment/claim-next")
+	log.Println("    POST      /admin/vedika/assignment/distribute")
+	log.Println("    GET       /admin/vedika/claim/history/:no_rawat")
+	log.Println("    GET       /admin/vedika/claim/completeness/:no_rawat")
+	log.Println("    GET/POST  /admin/vedika/claim/feedback/:no_rawat")
+	log.Println("    POST      /admin/vedika/feedback/:id/resolve")
+	log.Println("    GET       /admin/vedika/claim/inacbg/:no_rawat")
+	log.Println("    POST      /admin/vedika/claim/inacbg-grouper/:no_rawat")
+	log.Println("    POST      /admin/vedika/claim/inacbg-send/:no_rawat")
+	log.Println("    GET       /admin/vedika/bpjs/sep/:no_sep")
+	log.Println("    GET       /admin/vedika/bpjs/peserta/:no_kartu")
+	log.Println("    GET       /admin/vedika/bpjs/rujukan/:no_rujukan")
 	log.Println("    POST      /admin/vedika/claim/:no_rawat/diagnosis")
 	log.Println("    POST      /admin/vedika/claim/:no_rawat/procedure")
 	log.Println("    POST      /admin/vedika/claim/:no_rawat/documents")
+	log.Println("    GET       /admin/vedika/claim/documents/file/:no_rawat")
 	log.Println("    GET       /admin/vedika/claim/:no_rawat/resume")
 	log.Println("    GET       /admin/vedika/claim/:no_rawat/full")
+	log.Println("    GET       /admin/vedika/claim/pdf/:no_rawat")
+	log.Println("  Vedika Verifier Portal (BPJS):")
+	log.Println("    POST      /veda/login")
+	log.Println("    POST      /veda/logout")
+	log.Println("    GET       /veda/me")
+	log.Println("    GET       /veda/dashboard")
+	log.Println("    GET       /veda/claims")
+	log.Println("    GET       /veda/claim/full/:no_rawat")
+	log.Println("    GET       /veda/claim/pdf/:no_rawat")
+	log.Println("    GET       /veda/claim/history/:no_rawat")
+	log.Println("    GET/POST  /veda/claim/feedback/:no_rawat")
+	log.Println("    POST      /veda/claim/approve/:no_rawat")
+	log.Println("    POST      /veda/claim/return/:no_rawat")
 
-	if err := authRouter.Run(addr); err != nil {
+	if err := authRouter.Run(ctx, addr); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
+	log.Println("Server stopped")
 }
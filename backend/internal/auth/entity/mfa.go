@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// UserMFA holds the TOTP enrollment of a user.
+type UserMFA struct {
+	UserID          string     `json:"user_id"`
+	SecretEncrypted string     `json:"-"`
+	EnabledAt       *time.Time `json:"enabled_at,omitempty"`
+	LastUsedStep    int64      `json:"-"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// MFARecoveryCode is an unused recovery code of a user. CodeHash is a
+// bcrypt hash, so a code is found by comparing it against each one.
+type MFARecoveryCode struct {
+	ID       string
+	CodeHash string
+}
+
+// IsEnabled reports whether enrollment was confirmed with a valid code.
+func (m *UserMFA) IsEnabled() bool {
+	return m.EnabledAt != nil
+}
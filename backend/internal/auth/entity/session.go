@@ -4,14 +4,16 @@ import "time"
 
 // LoginSession represents an active or revoked login session.
 type LoginSession struct {
-	ID               string     `json:"id"`
-	UserID           string     `json:"user_id"`
-	RefreshTokenHash string     `json:"-"`
-	DeviceInfo       string     `json:"device_info,omitempty"`
-	IPAddress        string     `json:"ip_address,omitempty"`
-	CreatedAt        time.Time  `json:"created_at"`
-	LastSeenAt       time.Time  `json:"last_seen_at"`
-	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	ID                       string     `json:"id"`
+	UserID                   string     `json:"user_id"`
+	RefreshTokenHash         string     `json:"-"`
+	PreviousRefreshTokenHash string     `json:"-"` // Replaced by the last rotation
+	RefreshRotatedAt         *time.Time `json:"refresh_rotated_at,omitempty"`
+	DeviceInfo               string     `json:"device_info,omitempty"`
+	IPAddress                string     `json:"ip_address,omitempty"`
+	CreatedAt                time.Time  `json:"created_at"`
+	LastSeenAt               time.Time  `json:"last_seen_at"`
+	RevokedAt                *time.Time `json:"revoked_at,omitempty"`
 }
 
 func (s *LoginSession) IsActive() bool {
@@ -5,14 +5,32 @@ import "time"
 
 // User represents an authenticated user in the system.
 type User struct {
-	ID           string     `json:"id"`
-	Username     string     `json:"username"`
-	Email        string     `json:"email"`
-	PasswordHash string     `json:"-"`
-	IsActive     bool       `json:"is_active"`
-	LastLoginAt  *time.Time `json:"last_login_at,omitempty"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
+	ID                 string     `json:"id"`
+	Username           string     `json:"username"`
+	Email              string     `json:"email"`
+	PasswordHash       string     `json:"-"`
+	PasswordChangedAt  *time.Time `json:"password_changed_at,omitempty"`
+	MustChangePassword bool       `json:"must_change_password"` // Set when an administrator chose the password
+	IsActive           bool       `json:"is_active"`
+	LastLoginAt        *time.Time `json:"last_login_at,omitempty"`
+	FailedLoginCount   int        `json:"failed_login_count"`
+	LockedUntil        *time.Time `json:"locked_until,omitempty"` // Set after repeated failed logins
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// IsLocked reports whether login is refused at now because of failed attempts.
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// PasswordAge returns how long ago the password was changed, or zero when
+// that is unknown.
+func (u *User) PasswordAge(now time.Time) time.Duration {
+	if u.PasswordChangedAt == nil {
+		return 0
+	}
+	return now.Sub(*u.PasswordChangedAt)
 }
 
 // UserWithPermissions extends User with resolved effective permissions.
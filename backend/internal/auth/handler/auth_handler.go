@@ -16,10 +16,12 @@ type AuthHandler struct {
 	authService       *service.AuthService
 	sessionService    *service.SessionService
 	permissionService *service.PermissionService
+	mfaService        *service.MFAService
+	passwordService   *service.PasswordService
 }
 
-func NewAuthHandler(authSvc *service.AuthService, sessionSvc *service.SessionService, permSvc *service.PermissionService) *AuthHandler {
-	return &AuthHandler{authService: authSvc, sessionService: sessionSvc, permissionService: permSvc}
+func NewAuthHandler(authSvc *service.AuthService, sessionSvc *service.SessionService, permSvc *service.PermissionService, mfaSvc *service.MFAService, passwordSvc *service.PasswordService) *AuthHandler {
+	return &AuthHandler{authService: authSvc, sessionService: sessionSvc, permissionService: permSvc, mfaService: mfaSvc, passwordService: passwordSvc}
 }
 
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -43,18 +45,48 @@ func (h *AuthHandler) Login(c *gin.Context) {
 			response.Unauthorized(c, response.ErrCodeInvalidCredentials, "Username atau password salah")
 		case errors.Is(err, service.ErrUserInactive):
 			response.Unauthorized(c, response.ErrCodeUserInactive, "Akun pengguna tidak aktif")
+		case errors.Is(err, service.ErrAccountLocked):
+			response.Unauthorized(c, response.ErrCodeAccountLocked, "Akun terkunci sementara karena terlalu banyak percobaan login gagal")
 		default:
 			response.InternalServerError(c, "Gagal login")
 		}
 		return
 	}
 
+	respondLogin(c, result)
+}
+
+// respondLogin writes the tokens of a completed login, or the challenge a
+// login still has to pass.
+func respondLogin(c *gin.Context, result *service.LoginResponse) {
+	switch {
+	case result.MFA != nil:
+		response.Success(c, dto.MFAChallengeResponse{
+			MFARequired:        true,
+			MFAToken:           result.MFA.Token,
+			ExpiresAt:          result.MFA.ExpiresAt,
+			EnrollmentRequired: result.MFA.EnrollmentRequired,
+		})
+	case result.PasswordChange != nil:
+		response.Success(c, dto.PasswordChangeChallengeResponse{
+			PasswordChangeRequired: true,
+			PasswordChangeToken:    result.PasswordChange.Token,
+			ExpiresAt:              result.PasswordChange.ExpiresAt,
+			Reason:                 result.PasswordChange.Reason,
+			RecoveryCodes:          result.RecoveryCodes,
+		})
+	default:
+		response.Success(c, toLoginResponse(result))
+	}
+}
+
+func toLoginResponse(result *service.LoginResponse) dto.LoginResponse {
 	roleBriefs := make([]dto.RoleBrief, len(result.User.Roles))
 	for i, role := range result.User.Roles {
 		roleBriefs[i] = dto.RoleBrief{ID: role, Name: role}
 	}
 
-	resp := dto.LoginResponse{
+	return dto.LoginResponse{
 		User: dto.UserResponse{
 			ID:          result.User.ID,
 			Username:    result.User.Username,
@@ -70,9 +102,9 @@ func (h *AuthHandler) Login(c *gin.Context) {
 			TokenType:    result.Tokens.TokenType,
 			ExpiresAt:    result.Tokens.ExpiresAt,
 		},
-		Session: dto.SessionBrief{ID: result.SessionID, CreatedAt: result.Tokens.ExpiresAt},
+		Session:       dto.SessionBrief{ID: result.SessionID, CreatedAt: result.Tokens.ExpiresAt},
+		RecoveryCodes: result.RecoveryCodes,
 	}
-	response.Success(c, resp)
 }
 
 func (h *AuthHandler) Logout(c *gin.Context) {
@@ -95,11 +127,13 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 		return
 	}
 
-	tokens, err := h.authService.RefreshToken(c.Request.Context(), req.RefreshToken)
+	tokens, err := h.authService.RefreshToken(c.Request.Context(), req.RefreshToken, c.ClientIP())
 	if err != nil {
 		switch {
-		case errors.Is(err, service.ErrInvalidRefreshToken):
+		case errors.Is(err, service.ErrInvalidRefreshToken), errors.Is(err, service.ErrSessionNotFound):
 			response.Unauthorized(c, response.ErrCodeInvalidToken, "Refresh token tidak valid")
+		case errors.Is(err, service.ErrRefreshTokenReused):
+			response.Unauthorized(c, response.ErrCodeRefreshTokenReused, "Refresh token sudah pernah dipakai, sesi dicabut. Silakan login ulang")
 		case errors.Is(err, service.ErrSessionRevoked):
 			response.Unauthorized(c, response.ErrCodeSessionRevoked, "Sesi telah dibatalkan")
 		case errors.Is(err, service.ErrUserInactive):
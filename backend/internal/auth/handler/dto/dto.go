@@ -8,9 +8,29 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	User    UserResponse  `json:"user"`
-	Tokens  TokenResponse `json:"tokens"`
-	Session SessionBrief  `json:"session"`
+	User          UserResponse  `json:"user"`
+	Tokens        TokenResponse `json:"tokens"`
+	Session       SessionBrief  `json:"session"`
+	RecoveryCodes []string      `json:"recovery_codes,omitempty"`
+}
+
+// MFAChallengeResponse is returned by /auth/login instead of tokens when a
+// second factor is needed.
+type MFAChallengeResponse struct {
+	MFARequired        bool      `json:"mfa_required"`
+	MFAToken           string    `json:"mfa_token"`
+	ExpiresAt          time.Time `json:"expires_at"`
+	EnrollmentRequired bool      `json:"enrollment_required"`
+}
+
+type LoginMFARequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type MFATokenRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
 }
 
 type TokenResponse struct {
@@ -67,3 +87,67 @@ type SessionResponse struct {
 	IsActive   bool       `json:"is_active"`
 	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
 }
+
+type MFAStatusResponse struct {
+	Available         bool       `json:"available"`
+	Enabled           bool       `json:"enabled"`
+	Pending           bool       `json:"pending"`
+	Required          bool       `json:"required"`
+	EnabledAt         *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesLeft int        `json:"recovery_codes_left"`
+}
+
+type MFAEnrollResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type MFADisableRequest struct {
+	Password     string `json:"password" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// PasswordChangeChallengeResponse is returned by /auth/login instead of
+// tokens when the password must be changed first.
+type PasswordChangeChallengeResponse struct {
+	PasswordChangeRequired bool      `json:"password_change_required"`
+	PasswordChangeToken    string    `json:"password_change_token"`
+	ExpiresAt              time.Time `json:"expires_at"`
+	Reason                 string    `json:"reason"` // reset or expired
+	RecoveryCodes          []string  `json:"recovery_codes,omitempty"`
+}
+
+type LoginPasswordChangeRequest struct {
+	PasswordChangeToken string `json:"password_change_token" binding:"required"`
+	NewPassword         string `json:"new_password" binding:"required"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type ChangePasswordResponse struct {
+	RevokedSessions int `json:"revoked_sessions"`
+}
+
+type PasswordPolicyResponse struct {
+	MinLength     int  `json:"min_length"`
+	MaxLength     int  `json:"max_length"`
+	RequireUpper  bool `json:"require_upper"`
+	RequireLower  bool `json:"require_lower"`
+	RequireDigit  bool `json:"require_digit"`
+	RequireSymbol bool `json:"require_symbol"`
+	RejectCommon  bool `json:"reject_common"`
+	HistoryCount  int  `json:"history_count"`
+	MaxAgeDays    int  `json:"max_age_days"` // 0 when passwords do not expire
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clinova/simrs/backend/internal/auth/handler/dto"
+	"github.com/clinova/simrs/backend/internal/auth/handler/middleware"
+	"github.com/clinova/simrs/backend/internal/auth/service"
+	"github.com/clinova/simrs/backend/pkg/response"
+)
+
+// LoginMFA completes a login with the MFA challenge token from /auth/login.
+func (h *AuthHandler) LoginMFA(c *gin.Context) {
+	var req dto.LoginMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, response.ErrCodeValidationError, "Format data tidak valid")
+		return
+	}
+	if req.Code == "" && req.RecoveryCode == "" {
+		response.BadRequest(c, response.ErrCodeValidationError, "Kode OTP atau kode pemulihan wajib diisi")
+		return
+	}
+
+	result, err := h.authService.LoginMFA(c.Request.Context(), &service.LoginMFARequest{
+		MFAToken:     req.MFAToken,
+		Code:         req.Code,
+		RecoveryCode: req.RecoveryCode,
+		DeviceInfo:   c.GetHeader("User-Agent"),
+		IPAddress:    c.ClientIP(),
+	})
+	if err != nil {
+		handleMFAError(c, err, "Gagal login")
+		return
+	}
+	respondLogin(c, result)
+}
+
+// EnrollMFAChallenge starts the enrollment a login requires, using the MFA
+// challenge token.
+func (h *AuthHandler) EnrollMFAChallenge(c *gin.Context) {
+	var req dto.MFATokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, response.ErrCodeValidationError, "Format data tidak valid")
+		return
+	}
+
+	enrollment, err := h.authService.EnrollMFAChallenge(c.Request.Context(), req.MFAToken)
+	if err != nil {
+		handleMFAError(c, err, "Gagal memulai pendaftaran MFA")
+		return
+	}
+	response.Success(c, dto.MFAEnrollResponse{Secret: enrollment.Secret, ProvisioningURI: enrollment.ProvisioningURI})
+}
+
+func (h *AuthHandler) GetMFAStatus(c *gin.Context) {
+	status, err := h.mfaService.GetStatus(c.Request.Context(), middleware.GetUserID(c))
+	if err != nil {
+		response.InternalServerError(c, "Gagal mengambil status MFA")
+		return
+	}
+	response.Success(c, dto.MFAStatusResponse{
+		Available:         status.Available,
+		Enabled:           status.Enabled,
+		Pending:           status.Pending,
+		Required:          status.Required,
+		EnabledAt:         status.EnabledAt,
+		RecoveryCodesLeft: status.RecoveryCodesLeft,
+	})
+}
+
+func (h *AuthHandler) EnrollMFA(c *gin.Context) {
+	enrollment, err := h.mfaService.Enroll(c.Request.Context(), middleware.GetUserID(c))
+	if err != nil {
+		handleMFAError(c, err, "Gagal memulai pendaftaran MFA")
+		return
+	}
+	response.Success(c, dto.MFAEnrollResponse{Secret: enrollment.Secret, ProvisioningURI: enrollment.ProvisioningURI})
+}
+
+func (h *AuthHandler) ActivateMFA(c *gin.Context) {
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, response.ErrCodeValidationError, "Kode OTP wajib diisi")
+		return
+	}
+
+	codes, err := h.mfaService.Activate(c.Request.Context(), middleware.GetUserID(c), req.Code, c.ClientIP())
+	if err != nil {
+		handleMFAError(c, err, "Gagal mengaktifkan MFA")
+		return
+	}
+	response.SuccessWithMessage(c, "MFA berhasil diaktifkan", dto.MFARecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *AuthHandler) DisableMFA(c *gin.Context) {
+	var req dto.MFADisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, response.ErrCodeValidationError, "Format data tidak valid")
+		return
+	}
+	if req.Code == "" && req.RecoveryCode == "" {
+		response.BadRequest(c, response.ErrCodeValidationError, "Kode OTP atau kode pemulihan wajib diisi")
+		return
+	}
+
+	err := h.mfaService.Disable(c.Request.Context(), middleware.GetUserID(c), req.Password, req.Code, req.RecoveryCode, c.ClientIP())
+	if err != nil {
+		handleMFAError(c, err, "Gagal menonaktifkan MFA")
+		return
+	}
+	response.SuccessWithMessage(c, "MFA berhasil dinonaktifkan", nil)
+}
+
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, response.ErrCodeValidationError, "Kode OTP wajib diisi")
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(c.Request.Context(), middleware.GetUserID(c), req.Code, c.ClientIP())
+	if err != nil {
+		handleMFAError(c, err, "Gagal membuat kode pemulihan")
+		return
+	}
+	response.Success(c, dto.MFARecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func handleMFAError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidMFACode):
+		response.Unauthorized(c, response.ErrCodeInvalidMFACode, "Kode OTP atau kode pemulihan salah")
+	case errors.Is(err, service.ErrInvalidMFAToken):
+		response.Unauthorized(c, response.ErrCodeInvalidToken, "Token MFA tidak valid atau kedaluwarsa")
+	case errors.Is(err, service.ErrInvalidCredentials):
+		response.Unauthorized(c, response.ErrCodeInvalidCredentials, "Password salah")
+	case errors.Is(err, service.ErrUserInactive):
+		response.Unauthorized(c, response.ErrCodeUserInactive, "Akun pengguna tidak aktif")
+	case errors.Is(err, service.ErrAccountLocked):
+		response.Unauthorized(c, response.ErrCodeAccountLocked, "Akun terkunci sementara karena terlalu banyak percobaan login gagal")
+	case errors.Is(err, service.ErrUserNotFound):
+		response.NotFound(c, "Pengguna tidak ditemukan")
+	case errors.Is(err, service.ErrMFAUnavailable):
+		response.Error(c, http.StatusServiceUnavailable, response.ErrCodeMFAUnavailable, "MFA belum dapat digunakan")
+	case errors.Is(err, service.ErrMFANotEnrolled):
+		response.Error(c, http.StatusConflict, response.ErrCodeMFAState, "Pendaftaran MFA belum dimulai")
+	case errors.Is(err, service.ErrMFAAlreadyEnabled):
+		response.Error(c, http.StatusConflict, response.ErrCodeMFAState, "MFA sudah aktif")
+	case errors.Is(err, service.ErrMFANotEnabled):
+		response.Error(c, http.StatusConflict, response.ErrCodeMFAState, "MFA belum aktif")
+	case errors.Is(err, service.ErrMFARequired):
+		response.Error(c, http.StatusConflict, response.ErrCodeMFAState, "MFA wajib untuk akun ini dan tidak dapat dinonaktifkan")
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
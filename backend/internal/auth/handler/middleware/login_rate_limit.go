@@ -10,11 +10,13 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/clinova/simrs/backend/pkg/redis"
 	"github.com/clinova/simrs/backend/pkg/response"
 )
 
 // RateLimitStore defines the interface for rate limit storage.
-// Can be implemented with in-memory map (development) or Redis (production).
+// Implemented with an in-memory map (single instance, development) or
+// Redis (RedisRateLimitStore, shared across instances).
 type RateLimitStore interface {
 	// Increment increments the counter for the given key and returns the new count.
 	// If the key doesn't exist, it creates it with count 1.
@@ -38,8 +40,9 @@ func DefaultLoginRateLimitConfig() LoginRateLimitConfig {
 	}
 }
 
-// InMemoryRateLimitStore implements RateLimitStore using sync.Map.
-// For development/testing. Replace with Redis for production.
+// InMemoryRateLimitStore implements RateLimitStore with a fixed window per
+// key. For development/testing and single instances; use
+// RedisRateLimitStore in production.
 type InMemoryRateLimitStore struct {
 	mu      sync.RWMutex
 	entries map[string]*rateLimitEntry
@@ -134,6 +137,14 @@ func NewDefaultLoginRateLimiter() *LoginRateLimiter {
 	return NewLoginRateLimiter(store, config)
 }
 
+// NewLoginRateLimiterWithClient creates a rate limiter with default config,
+// stored in Redis when client is set. name separates the counters of
+// limiters sharing a Redis server.
+func NewLoginRateLimiterWithClient(client *redis.Client, name string) *LoginRateLimiter {
+	config := DefaultLoginRateLimitConfig()
+	return NewLoginRateLimiter(NewRateLimitStore(client, name, config.Window, config.MaxAttempts), config)
+}
+
 // loginRequestBody is used to peek at the username from request body.
 type loginRequestBody struct {
 	Username string `json:"username"`
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clinova/simrs/backend/pkg/response"
+)
+
+// RateLimitConfig holds the request limit of a rate limiter.
+type RateLimitConfig struct {
+	MaxRequests int           // Requests allowed per window; 0 disables the limiter
+	Window      time.Duration // Time window for rate limiting
+}
+
+// DefaultHeavyRateLimitConfig returns the default limit for expensive
+// endpoints (full claim detail, PDF bundles, exports): 30 per minute.
+func DefaultHeavyRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		MaxRequests: 30,
+		Window:      time.Minute,
+	}
+}
+
+// RateLimiter limits requests per user and route. The store's window must
+// match config.Window.
+type RateLimiter struct {
+	store  RateLimitStore
+	config RateLimitConfig
+}
+
+// NewRateLimiter creates a new rate limiter.
+func NewRateLimiter(store RateLimitStore, config RateLimitConfig) *RateLimiter {
+	return &RateLimiter{store: store, config: config}
+}
+
+// Middleware returns a Gin middleware keyed by the authenticated user, or
+// the client IP on routes without the JWT middleware.
+// Rate limit key format: "api:{route}:{user_id|ip}"
+func (r *RateLimiter) Middleware() gin.HandlerFunc {
+	return r.MiddlewareBy(GetUserID)
+}
+
+// MiddlewareBy returns a Gin middleware keyed by subject(c), falling back to
+// the client IP when it is empty.
+func (r *RateLimiter) MiddlewareBy(subject func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if r.config.MaxRequests <= 0 {
+			c.Next()
+			return
+		}
+
+		who := subject(c)
+		if who == "" {
+			who = "ip:" + c.ClientIP()
+		}
+		// FullPath is the route pattern, so all claims of a route share a limit
+		key := "api:" + c.FullPath() + ":" + who
+
+		count, err := r.store.Increment(key)
+		if err != nil {
+			// On error, allow the request (fail open)
+			c.Next()
+			return
+		}
+
+		remaining := r.config.MaxRequests - count
+		if remaining < 0 {
+			remaining = 0
+		}
+		c.Header("X-RateLimit-Limit", strconv.Itoa(r.config.MaxRequests))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+
+		if count > r.config.MaxRequests {
+			c.Header("Retry-After", strconv.Itoa(int(r.config.Window.Seconds())))
+			response.Error(c, 429, "RATE_LIMIT_EXCEEDED",
+				"Terlalu banyak permintaan. Silakan coba lagi nanti.")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
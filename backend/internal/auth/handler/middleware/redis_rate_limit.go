@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/clinova/simrs/backend/pkg/redis"
+)
+
+// redisTimeout bounds each rate limit round trip, so a slow Redis cannot
+// hold up requests. Callers fail open on errors.
+const redisTimeout = 2 * time.Second
+
+// RedisRateLimitStore implements RateLimitStore on a Redis-compatible
+// server with a sliding window: each request is a sorted set member scored
+// by its time, and the count is the number of members within the window.
+// Unlike the in-memory store it is shared by all instances and survives
+// restarts.
+type RedisRateLimitStore struct {
+	client *redis.Client
+	prefix string
+	window time.Duration
+	limit  int
+	now    func() time.Time
+}
+
+// NewRedisRateLimitStore creates a store whose keys are "ratelimit:{prefix}:{key}".
+// limit is the number of requests the caller allows per window: requests
+// past it are counted but not kept, so rejected requests do not hold the
+// window full. 0 keeps every request.
+func NewRedisRateLimitStore(client *redis.Client, prefix string, window time.Duration, limit int) *RedisRateLimitStore {
+	return &RedisRateLimitStore{client: client, prefix: "ratelimit:" + prefix + ":", window: window, limit: limit, now: time.Now}
+}
+
+// windowStart returns the lowest score, in microseconds, still inside the
+// window at now. Increment trims below it and GetCount counts from it, so a
+// request exactly one window old is out of the window for both.
+func (s *RedisRateLimitStore) windowStart(now time.Time) int64 {
+	return now.Add(-s.window).UnixMicro() + 1
+}
+
+// NewRateLimitStore returns a Redis store when client is set, otherwise an
+// in-memory store. limit is as for NewRedisRateLimitStore.
+func NewRateLimitStore(client *redis.Client, prefix string, window time.Duration, limit int) RateLimitStore {
+	if client == nil {
+		return NewInMemoryRateLimitStore(window)
+	}
+	return NewRedisRateLimitStore(client, prefix, window, limit)
+}
+
+// Increment records a request and returns the number of requests in the
+// last window, including this one. A request past the limit is removed
+// again, so only allowed requests stay in the window. Concurrent requests
+// at the limit may all be rejected, but no more than limit are allowed.
+func (s *RedisRateLimitStore) Increment(key string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	now := s.now()
+	k := s.prefix + key
+	member := fmt.Sprintf("%d-%s", now.UnixNano(), uuid.New().String()[:8])
+	results, err := s.client.Tx(ctx,
+		[]interface{}{"ZREMRANGEBYSCORE", k, "-inf", fmt.Sprintf("(%d", s.windowStart(now))},
+		[]interface{}{"ZADD", k, now.UnixMicro(), member},
+		[]interface{}{"ZCARD", k},
+		[]interface{}{"PEXPIRE", k, s.window.Milliseconds()},
+	)
+	if err != nil {
+		return 0, err
+	}
+	count, ok := results[2].(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected ZCARD reply %T", results[2])
+	}
+	if s.limit > 0 && int(count) > s.limit {
+		// The request is rejected either way; if ZREM fails its entry only
+		// expires with the window
+		s.client.Do(ctx, "ZREM", k, member)
+	}
+	return int(count), nil
+}
+
+// GetCount returns the number of requests in the last window.
+func (s *RedisRateLimitStore) GetCount(key string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	reply, err := s.client.Do(ctx, "ZCOUNT", s.prefix+key, s.windowStart(s.now()), "+inf")
+	if err != nil {
+		return 0, err
+	}
+	count, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected ZCOUNT reply %T", reply)
+	}
+	return int(count), nil
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/clinova/simrs/backend/pkg/redis"
+	"github.com/clinova/simrs/backend/pkg/redis/redistest"
+)
+
+func newRedisStore(t *testing.T, window time.Duration, limit int) *RedisRateLimitStore {
+	t.Helper()
+	srv, err := redistest.NewServer("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Close)
+	client := redis.NewClient(srv.Config())
+	t.Cleanup(func() { client.Close() })
+	return NewRedisRateLimitStore(client, "test", window, limit)
+}
+
+func TestRedisRateLimitStoreKeepsOnlyAllowedRequests(t *testing.T) {
+	store := newRedisStore(t, time.Minute, 3)
+
+	for want := 1; want <= 5; want++ {
+		got, err := store.Increment("k")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Rejected requests are counted once, then removed
+		if want > 3 && got != 4 {
+			t.Fatalf("request %d: count %d, want 4", want, got)
+		}
+		if want <= 3 && got != want {
+			t.Fatalf("request %d: count %d, want %d", want, got, want)
+		}
+	}
+	if n, err := store.GetCount("k"); err != nil || n != 3 {
+		t.Fatalf("GetCount = %d, %v; want 3", n, err)
+	}
+}
+
+func TestRedisRateLimitStoreSlidingWindow(t *testing.T) {
+	window := 200 * time.Millisecond
+	store := newRedisStore(t, window, 2)
+
+	store.Increment("k")
+	time.Sleep(window / 2)
+	store.Increment("k")
+	if n, _ := store.Increment("k"); n != 3 {
+		t.Fatalf("count at limit = %d, want 3 (rejected)", n)
+	}
+
+	// The first request leaves the window, the second is still in it
+	time.Sleep(window/2 + 20*time.Millisecond)
+	if n, _ := store.Increment("k"); n != 2 {
+		t.Fatalf("count after the first request aged out = %d, want 2", n)
+	}
+	if n, _ := store.GetCount("other"); n != 0 {
+		t.Fatalf("GetCount of an unused key = %d, want 0", n)
+	}
+}
+
+func TestRedisRateLimitStoreWindowBoundary(t *testing.T) {
+	store := newRedisStore(t, time.Minute, 0)
+	start := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return start }
+	if _, err := store.Increment("k"); err != nil {
+		t.Fatal(err)
+	}
+
+	// One microsecond before the first request leaves the window, both
+	// methods still count it
+	store.now = func() time.Time { return start.Add(time.Minute - time.Microsecond) }
+	if n, _ := store.GetCount("k"); n != 1 {
+		t.Fatalf("GetCount just inside the window = %d, want 1", n)
+	}
+
+	// Exactly one window later it is out for both
+	store.now = func() time.Time { return start.Add(time.Minute) }
+	if n, _ := store.GetCount("k"); n != 0 {
+		t.Fatalf("GetCount at the boundary = %d, want 0", n)
+	}
+	if n, _ := store.Increment("k"); n != 1 {
+		t.Fatalf("Increment at the boundary = %d, want 1", n)
+	}
+	if n, _ := store.GetCount("k"); n != 1 {
+		t.Fatalf("GetCount after Increment = %d, want 1", n)
+	}
+}
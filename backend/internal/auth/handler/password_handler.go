@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clinova/simrs/backend/internal/auth/handler/dto"
+	"github.com/clinova/simrs/backend/internal/auth/handler/middleware"
+	"github.com/clinova/simrs/backend/internal/auth/service"
+	"github.com/clinova/simrs/backend/pkg/password"
+	"github.com/clinova/simrs/backend/pkg/response"
+)
+
+// LoginPasswordChange completes a login whose password must be changed, with
+// the password change token from /auth/login.
+func (h *AuthHandler) LoginPasswordChange(c *gin.Context) {
+	var req dto.LoginPasswordChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, response.ErrCodeValidationError, "Token dan password baru wajib diisi")
+		return
+	}
+
+	result, err := h.authService.LoginPasswordChange(c.Request.Context(), &service.LoginPasswordChangeRequest{
+		Token:       req.PasswordChangeToken,
+		NewPassword: req.NewPassword,
+		DeviceInfo:  c.GetHeader("User-Agent"),
+		IPAddress:   c.ClientIP(),
+	})
+	if err != nil {
+		handlePasswordError(c, err, "Gagal login")
+		return
+	}
+	respondLogin(c, result)
+}
+
+// ChangePassword changes the password of the logged-in user and revokes the
+// user's other sessions.
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, response.ErrCodeValidationError, "Password lama dan password baru wajib diisi")
+		return
+	}
+
+	revoked, err := h.authService.ChangePassword(c.Request.Context(), &service.ChangePasswordRequest{
+		UserID:          middleware.GetUserID(c),
+		SessionID:       middleware.GetSessionID(c),
+		CurrentPassword: req.CurrentPassword,
+		NewPassword:     req.NewPassword,
+		IPAddress:       c.ClientIP(),
+	})
+	if err != nil {
+		handlePasswordError(c, err, "Gagal mengganti password")
+		return
+	}
+	response.SuccessWithMessage(c, "Password berhasil diganti", dto.ChangePasswordResponse{RevokedSessions: revoked})
+}
+
+// GetPasswordPolicy returns the password rules, for password forms.
+func (h *AuthHandler) GetPasswordPolicy(c *gin.Context) {
+	policy := h.passwordService.Policy()
+	response.Success(c, dto.PasswordPolicyResponse{
+		MinLength:     policy.MinLength,
+		MaxLength:     password.MaxLength,
+		RequireUpper:  policy.RequireUpper,
+		RequireLower:  policy.RequireLower,
+		RequireDigit:  policy.RequireDigit,
+		RequireSymbol: policy.RequireSymbol,
+		RejectCommon:  policy.RejectCommon,
+		HistoryCount:  policy.HistoryCount,
+		MaxAgeDays:    int(policy.MaxAge.Hours() / 24),
+	})
+}
+
+func handlePasswordError(c *gin.Context, err error, fallback string) {
+	var policyErr *password.PolicyError
+	switch {
+	case errors.As(err, &policyErr):
+		response.ErrorWithDetails(c, http.StatusBadRequest, response.ErrCodePasswordPolicy, "Password tidak memenuhi kebijakan password", gin.H{
+			"violations": policyErr.Violations,
+			"messages":   policyErr.Messages(),
+		})
+	case errors.Is(err, service.ErrInvalidPasswordChangeToken):
+		response.Unauthorized(c, response.ErrCodeInvalidToken, "Token ganti password tidak valid atau kedaluwarsa")
+	case errors.Is(err, service.ErrInvalidCredentials):
+		response.Unauthorized(c, response.ErrCodeInvalidCredentials, "Password lama salah")
+	case errors.Is(err, service.ErrUserInactive):
+		response.Unauthorized(c, response.ErrCodeUserInactive, "Akun pengguna tidak aktif")
+	case errors.Is(err, service.ErrAccountLocked):
+		response.Unauthorized(c, response.ErrCodeAccountLocked, "Akun terkunci sementara karena terlalu banyak percobaan login gagal")
+	case errors.Is(err, service.ErrUserNotFound):
+		response.NotFound(c, "Pengguna tidak ditemukan")
+	default:
+		response.InternalServerError(c, fallback)
+	}
+}
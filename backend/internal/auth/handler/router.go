@@ -1,18 +1,28 @@
 package handler
 
 import (
+	"context"
+	"net/http"
+	"time"
+
 	"github.com/gin-gonic/gin"
 
 	"github.com/clinova/simrs/backend/internal/auth/handler/middleware"
 	"github.com/clinova/simrs/backend/internal/auth/service"
 	"github.com/clinova/simrs/backend/pkg/jwt"
+	"github.com/clinova/simrs/backend/pkg/redis"
 )
 
+// shutdownTimeout bounds how long Run waits for in-flight requests on shutdown.
+const shutdownTimeout = 10 * time.Second
+
 type Router struct {
 	engine           *gin.Engine
 	jwtMiddleware    *middleware.JWTMiddleware
 	permMiddleware   *middleware.PermissionMiddleware
 	loginRateLimiter *middleware.LoginRateLimiter
+	mfaRateLimiter   *middleware.LoginRateLimiter
+	pwRateLimiter    *middleware.LoginRateLimiter
 	authHandler      *AuthHandler
 }
 
@@ -21,13 +31,18 @@ func NewRouter(
 	authService *service.AuthService,
 	sessionService *service.SessionService,
 	permissionService *service.PermissionService,
+	mfaService *service.MFAService,
+	passwordService *service.PasswordService,
+	redisClient *redis.Client,
 ) *Router {
 	r := &Router{
 		engine:           gin.Default(),
 		jwtMiddleware:    middleware.NewJWTMiddleware(jwtManager, sessionService),
 		permMiddleware:   middleware.NewPermissionMiddleware(permissionService),
-		loginRateLimiter: middleware.NewDefaultLoginRateLimiter(),
-		authHandler:      NewAuthHandler(authService, sessionService, permissionService),
+		loginRateLimiter: middleware.NewLoginRateLimiterWithClient(redisClient, "login"),
+		mfaRateLimiter:   middleware.NewLoginRateLimiterWithClient(redisClient, "login-mfa"),
+		pwRateLimiter:    middleware.NewLoginRateLimiterWithClient(redisClient, "login-password"),
+		authHandler:      NewAuthHandler(authService, sessionService, permissionService, mfaService, passwordService),
 	}
 	r.setupRoutes()
 	return r
@@ -38,8 +53,9 @@ func (r *Router) setupRoutes() {
 	r.engine.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, If-Match")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag, Content-Disposition, Retry-After, X-RateLimit-Limit, X-RateLimit-Remaining")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -57,6 +73,12 @@ func (r *Router) setupRoutes() {
 	{
 		// Login with rate limiting - max 10 attempts per 5 minutes per username+IP
 		auth.POST("/login", r.loginRateLimiter.Middleware(), r.authHandler.Login)
+		// Second login step - the body has no username, so this is limited per IP
+		auth.POST("/login/mfa", r.mfaRateLimiter.Middleware(), r.authHandler.LoginMFA)
+		auth.POST("/login/mfa/enroll", r.mfaRateLimiter.Middleware(), r.authHandler.EnrollMFAChallenge)
+		// Password change a login requires (reset by an administrator or expired), per IP
+		auth.POST("/login/password", r.pwRateLimiter.Middleware(), r.authHandler.LoginPasswordChange)
+		auth.GET("/password/policy", r.authHandler.GetPasswordPolicy)
 		auth.POST("/refresh", r.authHandler.Refresh)
 
 		protected := auth.Group("")
@@ -66,6 +88,12 @@ func (r *Router) setupRoutes() {
 			protected.GET("/me", r.authHandler.Me)
 			protected.GET("/sessions", r.authHandler.GetSessions)
 			protected.POST("/sessions/:id/revoke", r.authHandler.RevokeSession)
+			protected.POST("/password", r.authHandler.ChangePassword)
+			protected.GET("/mfa", r.authHandler.GetMFAStatus)
+			protected.POST("/mfa/enroll", r.authHandler.EnrollMFA)
+			protected.POST("/mfa/activate", r.authHandler.ActivateMFA)
+			protected.POST("/mfa/disable", r.authHandler.DisableMFA)
+			protected.POST("/mfa/recovery-codes", r.authHandler.RegenerateRecoveryCodes)
 		}
 	}
 }
@@ -74,6 +102,23 @@ func (r *Router) GetEngine() *gin.Engine {
 	return r.engine
 }
 
-func (r *Router) Run(addr string) error {
-	return r.engine.Run(addr)
+// Run serves HTTP on addr until ctx is done, then shuts down gracefully,
+// giving in-flight requests up to shutdownTimeout to finish.
+func (r *Router) Run(ctx context.Context, addr string) error {
+	srv := &http.Server{Addr: addr, Handler: r.engine}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
 }
@@ -3,6 +3,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/clinova/simrs/backend/internal/auth/entity"
 )
@@ -15,6 +16,18 @@ type UserRepository interface {
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
 	Update(ctx context.Context, user *entity.User) error
 	UpdateLastLogin(ctx context.Context, userID string) error
+	// UpdatePassword sets a new password hash, restarts its age and records
+	// it in the password history, keeping the keepHistory newest entries.
+	UpdatePassword(ctx context.Context, userID, hash string, mustChange bool, keepHistory int) error
+	// AddPasswordHistory records the password of a new user.
+	AddPasswordHistory(ctx context.Context, userID, hash string, keep int) error
+	// GetPasswordHistory returns up to limit previous password hashes, newest first.
+	GetPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error)
+	// RecordFailedLogin counts a failed login and returns the consecutive
+	// count. The count restarts when the previous failure is before resetBefore.
+	RecordFailedLogin(ctx context.Context, userID string, resetBefore time.Time) (int, error)
+	LockUntil(ctx context.Context, userID string, until time.Time) error
+	ResetFailedLogins(ctx context.Context, userID string) error
 	GetRolesByUserID(ctx context.Context, userID string) ([]entity.Role, error)
 	AssignRole(ctx context.Context, userID, roleID string) error
 	RemoveRole(ctx context.Context, userID, roleID string) error
@@ -54,6 +67,34 @@ type SessionRepository interface {
 	GetActiveByUserID(ctx context.Context, userID string) ([]entity.LoginSession, error)
 	GetAllByUserID(ctx context.Context, userID string) ([]entity.LoginSession, error)
 	UpdateLastSeen(ctx context.Context, sessionID string) error
+	// RotateRefreshToken replaces the refresh token hash of an active session,
+	// keeping the old one as the previous hash. It reports false when oldHash
+	// is no longer current, e.g. after a concurrent rotation.
+	RotateRefreshToken(ctx context.Context, sessionID, oldHash, newHash string) (bool, error)
+	// Revoke revokes an active session. It returns ErrSessionNotActive when
+	// the session is missing or already revoked.
 	Revoke(ctx context.Context, sessionID string) error
 	RevokeAllByUserID(ctx context.Context, userID string) error
+	// RevokeOthersByUserID revokes all active sessions of the user except keepSessionID.
+	RevokeOthersByUserID(ctx context.Context, userID, keepSessionID string) (int, error)
+}
+
+// MFARepository defines the interface for TOTP enrollment data access.
+type MFARepository interface {
+	GetByUserID(ctx context.Context, userID string) (*entity.UserMFA, error)
+	// SavePending stores a new, unconfirmed secret, replacing any pending one.
+	SavePending(ctx context.Context, userID, secretEncrypted string) error
+	// Enable confirms the enrollment at a time step and replaces the recovery codes.
+	Enable(ctx context.Context, userID string, step int64, codeHashes []string) error
+	// UseStep records an accepted time step. It returns false when the step
+	// is not newer than the last one accepted, so a code cannot be replayed.
+	UseStep(ctx context.Context, userID string, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+	// UnusedRecoveryCodes returns the recovery codes not used yet.
+	UnusedRecoveryCodes(ctx context.Context, userID string) ([]entity.MFARecoveryCode, error)
+	// UseRecoveryCode marks an unused recovery code as used. It returns false
+	// when the code was used meanwhile.
+	UseRecoveryCode(ctx context.Context, userID, codeID string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID string) (int, error)
+	Delete(ctx context.Context, userID string) error
 }
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/clinova/simrs/backend/internal/auth/entity"
+)
+
+type mysqlMFARepository struct {
+	db *sql.DB
+}
+
+func NewMySQLMFARepository(db *sql.DB) MFARepository {
+	return &mysqlMFARepository{db: db}
+}
+
+func (r *mysqlMFARepository) GetByUserID(ctx context.Context, userID string) (*entity.UserMFA, error) {
+	query := `SELECT user_id, secret_encrypted, enabled_at, last_used_step, created_at, updated_at FROM mera_user_mfa WHERE user_id = ?`
+	m := &entity.UserMFA{}
+	var enabledAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&m.UserID, &m.SecretEncrypted, &enabledAt, &m.LastUsedStep, &m.CreatedAt, &m.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if enabledAt.Valid {
+		m.EnabledAt = &enabledAt.Time
+	}
+	return m, nil
+}
+
+func (r *mysqlMFARepository) SavePending(ctx context.Context, userID, secretEncrypted string) error {
+	query := `INSERT INTO mera_user_mfa (user_id, secret_encrypted, enabled_at, last_used_step) VALUES (?, ?, NULL, 0)
+		ON DUPLICATE KEY UPDATE secret_encrypted = VALUES(secret_encrypted), enabled_at = NULL, last_used_step = 0`
+	_, err := r.db.ExecContext(ctx, query, userID, secretEncrypted)
+	return err
+}
+
+func (r *mysqlMFARepository) Enable(ctx context.Context, userID string, step int64, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE mera_user_mfa SET enabled_at = ?, last_used_step = ? WHERE user_id = ?`
+	if _, err := tx.ExecContext(ctx, query, time.Now(), step, userID); err != nil {
+		return err
+	}
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *mysqlMFARepository) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	query := `UPDATE mera_user_mfa SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?`
+	result, err := r.db.ExecContext(ctx, query, step, userID, step)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+func (r *mysqlMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID string, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mera_user_mfa_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	query := `INSERT INTO mera_user_mfa_recovery_codes (id, user_id, code_hash, created_at) VALUES (?, ?, ?, NOW())`
+	for _, hash := range codeHashes {
+		if _, err := tx.ExecContext(ctx, query, uuid.New().String(), userID, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *mysqlMFARepository) UnusedRecoveryCodes(ctx context.Context, userID string) ([]entity.MFARecoveryCode, error) {
+	query := `SELECT id, code_hash FROM mera_user_mfa_recovery_codes WHERE user_id = ? AND used_at IS NULL`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var codes []entity.MFARecoveryCode
+	for rows.Next() {
+		var c entity.MFARecoveryCode
+		if err := rows.Scan(&c.ID, &c.CodeHash); err != nil {
+			return nil, err
+		}
+		codes = append(codes, c)
+	}
+	return codes, rows.Err()
+}
+
+func (r *mysqlMFARepository) UseRecoveryCode(ctx context.Context, userID, codeID string) (bool, error) {
+	query := `UPDATE mera_user_mfa_recovery_codes SET used_at = ? WHERE id = ? AND user_id = ? AND used_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, time.Now(), codeID, userID)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+func (r *mysqlMFARepository) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	query := `SELECT COUNT(*) FROM mera_user_mfa_recovery_codes WHERE user_id = ? AND used_at IS NULL`
+	var count int
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+func (r *mysqlMFARepository) Delete(ctx context.Context, userID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mera_user_mfa_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mera_user_mfa WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -3,7 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"fmt"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,6 +11,10 @@ import (
 	"github.com/clinova/simrs/backend/internal/auth/entity"
 )
 
+// ErrSessionNotActive indicates Revoke found no active session, e.g. because
+// a concurrent request revoked it first.
+var ErrSessionNotActive = errors.New("session not found or already revoked")
+
 type mysqlSessionRepository struct {
 	db *sql.DB
 }
@@ -29,17 +33,23 @@ func (r *mysqlSessionRepository) Create(ctx context.Context, session *entity.Log
 }
 
 func (r *mysqlSessionRepository) GetByID(ctx context.Context, id string) (*entity.LoginSession, error) {
-	query := `SELECT id, user_id, refresh_token_hash, device_info, ip_address, created_at, last_seen_at, revoked_at FROM mera_login_sessions WHERE id = ?`
+	query := `SELECT id, user_id, refresh_token_hash, previous_refresh_token_hash, refresh_rotated_at, device_info, ip_address, created_at, last_seen_at, revoked_at FROM mera_login_sessions WHERE id = ?`
 	s := &entity.LoginSession{}
-	var deviceInfo, ipAddress sql.NullString
-	var revokedAt sql.NullTime
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&s.ID, &s.UserID, &s.RefreshTokenHash, &deviceInfo, &ipAddress, &s.CreatedAt, &s.LastSeenAt, &revokedAt)
+	var previousHash, deviceInfo, ipAddress sql.NullString
+	var rotatedAt, revokedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&s.ID, &s.UserID, &s.RefreshTokenHash, &previousHash, &rotatedAt, &deviceInfo, &ipAddress, &s.CreatedAt, &s.LastSeenAt, &revokedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	if previousHash.Valid {
+		s.PreviousRefreshTokenHash = previousHash.String
+	}
+	if rotatedAt.Valid {
+		s.RefreshRotatedAt = &rotatedAt.Time
+	}
 	if deviceInfo.Valid {
 		s.DeviceInfo = deviceInfo.String
 	}
@@ -53,17 +63,23 @@ func (r *mysqlSessionRepository) GetByID(ctx context.Context, id string) (*entit
 }
 
 func (r *mysqlSessionRepository) GetByRefreshTokenHash(ctx context.Context, hash string) (*entity.LoginSession, error) {
-	query := `SELECT id, user_id, refresh_token_hash, device_info, ip_address, created_at, last_seen_at, revoked_at FROM mera_login_sessions WHERE refresh_token_hash = ?`
+	query := `SELECT id, user_id, refresh_token_hash, previous_refresh_token_hash, refresh_rotated_at, device_info, ip_address, created_at, last_seen_at, revoked_at FROM mera_login_sessions WHERE refresh_token_hash = ?`
 	s := &entity.LoginSession{}
-	var deviceInfo, ipAddress sql.NullString
-	var revokedAt sql.NullTime
-	err := r.db.QueryRowContext(ctx, query, hash).Scan(&s.ID, &s.UserID, &s.RefreshTokenHash, &deviceInfo, &ipAddress, &s.CreatedAt, &s.LastSeenAt, &revokedAt)
+	var previousHash, deviceInfo, ipAddress sql.NullString
+	var rotatedAt, revokedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, hash).Scan(&s.ID, &s.UserID, &s.RefreshTokenHash, &previousHash, &rotatedAt, &deviceInfo, &ipAddress, &s.CreatedAt, &s.LastSeenAt, &revokedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	if previousHash.Valid {
+		s.PreviousRefreshTokenHash = previousHash.String
+	}
+	if rotatedAt.Valid {
+		s.RefreshRotatedAt = &rotatedAt.Time
+	}
 	if deviceInfo.Valid {
 		s.DeviceInfo = deviceInfo.String
 	}
@@ -77,12 +93,12 @@ func (r *mysqlSessionRepository) GetByRefreshTokenHash(ctx context.Context, hash
 }
 
 func (r *mysqlSessionRepository) GetActiveByUserID(ctx context.Context, userID string) ([]entity.LoginSession, error) {
-	query := `SELECT id, user_id, refresh_token_hash, device_info, ip_address, created_at, last_seen_at, revoked_at FROM mera_login_sessions WHERE user_id = ? AND revoked_at IS NULL ORDER BY last_seen_at DESC`
+	query := `SELECT id, user_id, refresh_token_hash, previous_refresh_token_hash, refresh_rotated_at, device_info, ip_address, created_at, last_seen_at, revoked_at FROM mera_login_sessions WHERE user_id = ? AND revoked_at IS NULL ORDER BY last_seen_at DESC`
 	return r.querySessions(ctx, query, userID)
 }
 
 func (r *mysqlSessionRepository) GetAllByUserID(ctx context.Context, userID string) ([]entity.LoginSession, error) {
-	query := `SELECT id, user_id, refresh_token_hash, device_info, ip_address, created_at, last_seen_at, revoked_at FROM mera_login_sessions WHERE user_id = ? ORDER BY created_at DESC`
+	query := `SELECT id, user_id, refresh_token_hash, previous_refresh_token_hash, refresh_rotated_at, device_info, ip_address, created_at, last_seen_at, revoked_at FROM mera_login_sessions WHERE user_id = ? ORDER BY created_at DESC`
 	return r.querySessions(ctx, query, userID)
 }
 
@@ -95,11 +111,17 @@ func (r *mysqlSessionRepository) querySessions(ctx context.Context, query string
 	var sessions []entity.LoginSession
 	for rows.Next() {
 		var s entity.LoginSession
-		var deviceInfo, ipAddress sql.NullString
-		var revokedAt sql.NullTime
-		if err := rows.Scan(&s.ID, &s.UserID, &s.RefreshTokenHash, &deviceInfo, &ipAddress, &s.CreatedAt, &s.LastSeenAt, &revokedAt); err != nil {
+		var previousHash, deviceInfo, ipAddress sql.NullString
+		var rotatedAt, revokedAt sql.NullTime
+		if err := rows.Scan(&s.ID, &s.UserID, &s.RefreshTokenHash, &previousHash, &rotatedAt, &deviceInfo, &ipAddress, &s.CreatedAt, &s.LastSeenAt, &revokedAt); err != nil {
 			return nil, err
 		}
+		if previousHash.Valid {
+			s.PreviousRefreshTokenHash = previousHash.String
+		}
+		if rotatedAt.Valid {
+			s.RefreshRotatedAt = &rotatedAt.Time
+		}
 		if deviceInfo.Valid {
 			s.DeviceInfo = deviceInfo.String
 		}
@@ -120,6 +142,17 @@ func (r *mysqlSessionRepository) UpdateLastSeen(ctx context.Context, sessionID s
 	return err
 }
 
+func (r *mysqlSessionRepository) RotateRefreshToken(ctx context.Context, sessionID, oldHash, newHash string) (bool, error) {
+	now := time.Now()
+	query := `UPDATE mera_login_sessions SET previous_refresh_token_hash = refresh_token_hash, refresh_token_hash = ?, refresh_rotated_at = ?, last_seen_at = ? WHERE id = ? AND refresh_token_hash = ? AND revoked_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, newHash, now, now, sessionID, oldHash)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
 func (r *mysqlSessionRepository) Revoke(ctx context.Context, sessionID string) error {
 	query := `UPDATE mera_login_sessions SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`
 	result, err := r.db.ExecContext(ctx, query, time.Now(), sessionID)
@@ -128,7 +161,7 @@ func (r *mysqlSessionRepository) Revoke(ctx context.Context, sessionID string) e
 	}
 	rows, _ := result.RowsAffected()
 	if rows == 0 {
-		return fmt.Errorf("session not found or already revoked")
+		return ErrSessionNotActive
 	}
 	return nil
 }
@@ -138,3 +171,13 @@ func (r *mysqlSessionRepository) RevokeAllByUserID(ctx context.Context, userID s
 	_, err := r.db.ExecContext(ctx, query, time.Now(), userID)
 	return err
 }
+
+func (r *mysqlSessionRepository) RevokeOthersByUserID(ctx context.Context, userID, keepSessionID string) (int, error) {
+	query := `UPDATE mera_login_sessions SET revoked_at = ? WHERE user_id = ? AND id <> ? AND revoked_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, time.Now(), userID, keepSessionID)
+	if err != nil {
+		return 0, err
+	}
+	rows, _ := result.RowsAffected()
+	return int(rows), nil
+}
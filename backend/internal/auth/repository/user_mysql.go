@@ -22,59 +22,77 @@ func (r *mysqlUserRepository) Create(ctx context.Context, user *entity.User) err
 	if user.ID == "" {
 		user.ID = uuid.New().String()
 	}
-	query := `INSERT INTO mera_users (id, username, email, password_hash, is_active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, NOW(), NOW())`
-	_, err := r.db.ExecContext(ctx, query, user.ID, user.Username, user.Email, user.PasswordHash, user.IsActive)
+	query := `INSERT INTO mera_users (id, username, email, password_hash, password_changed_at, must_change_password, is_active, created_at, updated_at) VALUES (?, ?, ?, ?, NOW(), ?, ?, NOW(), NOW())`
+	_, err := r.db.ExecContext(ctx, query, user.ID, user.Username, user.Email, user.PasswordHash, user.MustChangePassword, user.IsActive)
 	return err
 }
 
 func (r *mysqlUserRepository) GetByID(ctx context.Context, id string) (*entity.User, error) {
-	query := `SELECT id, username, email, password_hash, is_active, last_login_at, created_at, updated_at FROM mera_users WHERE id = ?`
+	query := `SELECT id, username, email, password_hash, password_changed_at, must_change_password, is_active, last_login_at, failed_login_count, locked_until, created_at, updated_at FROM mera_users WHERE id = ?`
 	user := &entity.User{}
-	var lastLoginAt sql.NullTime
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.IsActive, &lastLoginAt, &user.CreatedAt, &user.UpdatedAt)
+	var passwordChangedAt, lastLoginAt, lockedUntil sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &passwordChangedAt, &user.MustChangePassword, &user.IsActive, &lastLoginAt, &user.FailedLoginCount, &lockedUntil, &user.CreatedAt, &user.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	if passwordChangedAt.Valid {
+		user.PasswordChangedAt = &passwordChangedAt.Time
+	}
 	if lastLoginAt.Valid {
 		user.LastLoginAt = &lastLoginAt.Time
 	}
+	if lockedUntil.Valid {
+		user.LockedUntil = &lockedUntil.Time
+	}
 	return user, nil
 }
 
 func (r *mysqlUserRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
-	query := `SELECT id, username, email, password_hash, is_active, last_login_at, created_at, updated_at FROM mera_users WHERE username = ?`
+	query := `SELECT id, username, email, password_hash, password_changed_at, must_change_password, is_active, last_login_at, failed_login_count, locked_until, created_at, updated_at FROM mera_users WHERE username = ?`
 	user := &entity.User{}
-	var lastLoginAt sql.NullTime
-	err := r.db.QueryRowContext(ctx, query, username).Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.IsActive, &lastLoginAt, &user.CreatedAt, &user.UpdatedAt)
+	var passwordChangedAt, lastLoginAt, lockedUntil sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, username).Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &passwordChangedAt, &user.MustChangePassword, &user.IsActive, &lastLoginAt, &user.FailedLoginCount, &lockedUntil, &user.CreatedAt, &user.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	if passwordChangedAt.Valid {
+		user.PasswordChangedAt = &passwordChangedAt.Time
+	}
 	if lastLoginAt.Valid {
 		user.LastLoginAt = &lastLoginAt.Time
 	}
+	if lockedUntil.Valid {
+		user.LockedUntil = &lockedUntil.Time
+	}
 	return user, nil
 }
 
 func (r *mysqlUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
-	query := `SELECT id, username, email, password_hash, is_active, last_login_at, created_at, updated_at FROM mera_users WHERE email = ?`
+	query := `SELECT id, username, email, password_hash, password_changed_at, must_change_password, is_active, last_login_at, failed_login_count, locked_until, created_at, updated_at FROM mera_users WHERE email = ?`
 	user := &entity.User{}
-	var lastLoginAt sql.NullTime
-	err := r.db.QueryRowContext(ctx, query, email).Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.IsActive, &lastLoginAt, &user.CreatedAt, &user.UpdatedAt)
+	var passwordChangedAt, lastLoginAt, lockedUntil sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, email).Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &passwordChangedAt, &user.MustChangePassword, &user.IsActive, &lastLoginAt, &user.FailedLoginCount, &lockedUntil, &user.CreatedAt, &user.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	if passwordChangedAt.Valid {
+		user.PasswordChangedAt = &passwordChangedAt.Time
+	}
 	if lastLoginAt.Valid {
 		user.LastLoginAt = &lastLoginAt.Time
 	}
+	if lockedUntil.Valid {
+		user.LockedUntil = &lockedUntil.Time
+	}
 	return user, nil
 }
 
@@ -84,12 +102,135 @@ func (r *mysqlUserRepository) Update(ctx context.Context, user *entity.User) err
 	return err
 }
 
+func (r *mysqlUserRepository) UpdatePassword(ctx context.Context, userID, hash string, mustChange bool, keepHistory int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE mera_users SET password_hash = ?, password_changed_at = NOW(), must_change_password = ?, updated_at = NOW() WHERE id = ?`
+	if _, err := tx.ExecContext(ctx, query, hash, mustChange, userID); err != nil {
+		return err
+	}
+	if err := addPasswordHistory(ctx, tx, userID, hash, keepHistory); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *mysqlUserRepository) AddPasswordHistory(ctx context.Context, userID, hash string, keep int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := addPasswordHistory(ctx, tx, userID, hash, keep); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// addPasswordHistory records hash and drops all but the keep newest entries
+// of the user, the new one included.
+func addPasswordHistory(ctx context.Context, tx *sql.Tx, userID, hash string, keep int) error {
+	if keep <= 0 {
+		_, err := tx.ExecContext(ctx, `DELETE FROM mera_user_password_history WHERE user_id = ?`, userID)
+		return err
+	}
+
+	id := uuid.New().String()
+	query := `INSERT INTO mera_user_password_history (id, user_id, password_hash, created_at) VALUES (?, ?, ?, NOW())`
+	if _, err := tx.ExecContext(ctx, query, id, userID, hash); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM mera_user_password_history WHERE user_id = ? AND id <> ? ORDER BY seq DESC`, userID, id)
+	if err != nil {
+		return err
+	}
+	var stale []string
+	for kept := 1; rows.Next(); kept++ {
+		var oldID string
+		if err := rows.Scan(&oldID); err != nil {
+			rows.Close()
+			return err
+		}
+		if kept >= keep {
+			stale = append(stale, oldID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, oldID := range stale {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM mera_user_password_history WHERE id = ?`, oldID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *mysqlUserRepository) GetPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error) {
+	query := `SELECT password_hash FROM mera_user_password_history WHERE user_id = ? ORDER BY seq DESC LIMIT ?`
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, rows.Err()
+}
+
 func (r *mysqlUserRepository) UpdateLastLogin(ctx context.Context, userID string) error {
 	query := `UPDATE mera_users SET last_login_at = ? WHERE id = ?`
 	_, err := r.db.ExecContext(ctx, query, time.Now(), userID)
 	return err
 }
 
+func (r *mysqlUserRepository) RecordFailedLogin(ctx context.Context, userID string, resetBefore time.Time) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE mera_users SET
+		failed_login_count = IF(last_failed_login_at IS NULL OR last_failed_login_at < ?, 1, failed_login_count + 1),
+		last_failed_login_at = ?
+		WHERE id = ?`
+	if _, err := tx.ExecContext(ctx, query, resetBefore, time.Now(), userID); err != nil {
+		return 0, err
+	}
+	var count int
+	if err := tx.QueryRowContext(ctx, `SELECT failed_login_count FROM mera_users WHERE id = ?`, userID).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, tx.Commit()
+}
+
+func (r *mysqlUserRepository) LockUntil(ctx context.Context, userID string, until time.Time) error {
+	query := `UPDATE mera_users SET locked_until = ? WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, until, userID)
+	return err
+}
+
+func (r *mysqlUserRepository) ResetFailedLogins(ctx context.Context, userID string) error {
+	query := `UPDATE mera_users SET failed_login_count = 0, last_failed_login_at = NULL, locked_until = NULL WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, userID)
+	return err
+}
+
 func (r *mysqlUserRepository) GetRolesByUserID(ctx context.Context, userID string) ([]entity.Role, error) {
 	query := `SELECT r.id, r.name, r.description, r.created_at, r.updated_at FROM mera_roles r INNER JOIN mera_user_roles ur ON r.id = ur.role_id WHERE ur.user_id = ?`
 	rows, err := r.db.QueryContext(ctx, query, userID)
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/clinova/simrs/backend/internal/auth/entity"
+	"github.com/clinova/simrs/backend/internal/auth/repository"
+)
+
+// fakeUserRepo keeps users and their password history in memory. History
+// entries are kept in insertion order, which is what seq gives the MySQL
+// repository when several changes share a created_at second.
+type fakeUserRepo struct {
+	repository.UserRepository
+	users       map[string]*entity.User
+	history     map[string][]string // Oldest first
+	lastFailure map[string]time.Time
+}
+
+func newFakeUserRepo(users ...*entity.User) *fakeUserRepo {
+	r := &fakeUserRepo{
+		users:       map[string]*entity.User{},
+		history:     map[string][]string{},
+		lastFailure: map[string]time.Time{},
+	}
+	for _, u := range users {
+		r.users[u.ID] = u
+	}
+	return r
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id string) (*entity.User, error) {
+	u, ok := r.users[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *u
+	return &copied, nil
+}
+
+func (r *fakeUserRepo) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
+	for _, u := range r.users {
+		if u.Username == username {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) GetRolesByUserID(ctx context.Context, userID string) ([]entity.Role, error) {
+	return nil, nil
+}
+
+func (r *fakeUserRepo) UpdatePassword(ctx context.Context, userID, hash string, mustChange bool, keepHistory int) error {
+	u := r.users[userID]
+	now := time.Now()
+	u.PasswordHash = hash
+	u.PasswordChangedAt = &now
+	u.MustChangePassword = mustChange
+	return r.AddPasswordHistory(ctx, userID, hash, keepHistory)
+}
+
+func (r *fakeUserRepo) AddPasswordHistory(ctx context.Context, userID, hash string, keep int) error {
+	if keep <= 0 {
+		delete(r.history, userID)
+		return nil
+	}
+	h := append(r.history[userID], hash)
+	if len(h) > keep {
+		h = h[len(h)-keep:]
+	}
+	r.history[userID] = h
+	return nil
+}
+
+func (r *fakeUserRepo) GetPasswordHistory(ctx context.Context, userID string, limit int) ([]string, error) {
+	h := r.history[userID]
+	var newest []string
+	for i := len(h) - 1; i >= 0 && len(newest) < limit; i-- {
+		newest = append(newest, h[i])
+	}
+	return newest, nil
+}
+
+func (r *fakeUserRepo) RecordFailedLogin(ctx context.Context, userID string, resetBefore time.Time) (int, error) {
+	u := r.users[userID]
+	if last, ok := r.lastFailure[userID]; ok && last.Before(resetBefore) {
+		u.FailedLoginCount = 0
+	}
+	u.FailedLoginCount++
+	r.lastFailure[userID] = time.Now()
+	return u.FailedLoginCount, nil
+}
+
+func (r *fakeUserRepo) LockUntil(ctx context.Context, userID string, until time.Time) error {
+	r.users[userID].LockedUntil = &until
+	return nil
+}
+
+func (r *fakeUserRepo) ResetFailedLogins(ctx context.Context, userID string) error {
+	u := r.users[userID]
+	u.FailedLoginCount = 0
+	u.LockedUntil = nil
+	delete(r.lastFailure, userID)
+	return nil
+}
+
+func (r *fakeUserRepo) UpdateLastLogin(ctx context.Context, userID string) error {
+	now := time.Now()
+	r.users[userID].LastLoginAt = &now
+	return nil
+}
+
+// fakeSessionRepo keeps login sessions in memory.
+type fakeSessionRepo struct {
+	repository.SessionRepository
+	sessions map[string]*entity.LoginSession
+}
+
+func newFakeSessionRepo(sessions ...*entity.LoginSession) *fakeSessionRepo {
+	r := &fakeSessionRepo{sessions: map[string]*entity.LoginSession{}}
+	for _, s := range sessions {
+		r.sessions[s.ID] = s
+	}
+	return r
+}
+
+func (r *fakeSessionRepo) RevokeOthersByUserID(ctx context.Context, userID, keepSessionID string) (int, error) {
+	now := time.Now()
+	revoked := 0
+	for _, s := range r.sessions {
+		if s.UserID == userID && s.ID != keepSessionID && s.IsActive() {
+			s.RevokedAt = &now
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func (r *fakeSessionRepo) Create(ctx context.Context, session *entity.LoginSession) error {
+	r.sessions[session.ID] = session
+	return nil
+}
+
+func (r *fakeSessionRepo) GetByID(ctx context.Context, id string) (*entity.LoginSession, error) {
+	s, ok := r.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *s
+	return &copied, nil
+}
+
+func (r *fakeSessionRepo) GetByRefreshTokenHash(ctx context.Context, hash string) (*entity.LoginSession, error) {
+	for _, s := range r.sessions {
+		if s.RefreshTokenHash == hash {
+			copied := *s
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeSessionRepo) RotateRefreshToken(ctx context.Context, sessionID, oldHash, newHash string) (bool, error) {
+	s, ok := r.sessions[sessionID]
+	if !ok || s.RefreshTokenHash != oldHash || !s.IsActive() {
+		return false, nil
+	}
+	now := time.Now()
+	s.PreviousRefreshTokenHash = s.RefreshTokenHash
+	s.RefreshTokenHash = newHash
+	s.RefreshRotatedAt = &now
+	return true, nil
+}
+
+func (r *fakeSessionRepo) Revoke(ctx context.Context, sessionID string) error {
+	s, ok := r.sessions[sessionID]
+	if !ok || !s.IsActive() {
+		return repository.ErrSessionNotActive
+	}
+	now := time.Now()
+	s.RevokedAt = &now
+	return nil
+}
+
+// fakePermissionRepo grants no permissions.
+type fakePermissionRepo struct {
+	repository.PermissionRepository
+}
+
+func (fakePermissionRepo) GetEffectivePermissions(ctx context.Context, userID string) (map[string]bool, error) {
+	return map[string]bool{}, nil
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/clinova/simrs/backend/internal/auth/entity"
+	"github.com/clinova/simrs/backend/pkg/audit"
+)
+
+// Failed login reasons recorded in the audit log.
+const (
+	failedLoginUnknownUser     = "unknown_user"
+	failedLoginInvalidPassword = "invalid_password"
+	failedLoginInvalidMFACode  = "invalid_mfa_code"
+	failedLoginAccountLocked   = "account_locked"
+	failedLoginUserInactive    = "user_inactive"
+)
+
+// LockoutPolicy configures the account lockout after failed logins.
+type LockoutPolicy struct {
+	Threshold    int           // Failed logins before locking; 0 disables lockout
+	BaseDuration time.Duration // First lockout, doubled for each further failure
+	MaxDuration  time.Duration
+	ResetAfter   time.Duration // Counter restarts after this long without failures
+}
+
+// Duration returns how long to lock an account after the given number of
+// consecutive failures, or zero when it stays unlocked.
+func (p LockoutPolicy) Duration(failures int) time.Duration {
+	if p.Threshold <= 0 || failures < p.Threshold {
+		return 0
+	}
+	d := p.BaseDuration
+	// Without a maximum, doubling stops before it overflows
+	for i := p.Threshold; i < failures && (p.MaxDuration <= 0 || d < p.MaxDuration) && d <= math.MaxInt64/2; i++ {
+		d *= 2
+	}
+	if p.MaxDuration > 0 && d > p.MaxDuration {
+		d = p.MaxDuration
+	}
+	return d
+}
+
+// FailedLogin is a failed login to count and write to the audit log.
+type FailedLogin struct {
+	Username string
+	IP       string
+	Reason   string
+	Module   string // Audit log module
+	Actor    audit.Actor
+	Data     map[string]interface{} // Audit fields besides username, reason and IP
+	Summary  string                 // Audit summary; the end of a new lock is appended
+	// Count and Lock are set for a known account whose failure counts
+	// towards the lockout.
+	Count func(ctx context.Context, resetBefore time.Time) (int, error)
+	Lock  func(ctx context.Context, until time.Time) error
+}
+
+// RecordFailedLogin counts a failed login, locks the account once the
+// policy says so, and writes the failure to the audit log. It reports
+// whether the account is now locked. Counter errors are logged rather than
+// returned, so they never turn a failed login into a 500. Staff and
+// verifier logins share it so both lock out the same way.
+func (p LockoutPolicy) RecordFailedLogin(ctx context.Context, auditLogger *audit.Logger, f FailedLogin) bool {
+	data := map[string]interface{}{
+		"username":   f.Username,
+		"reason":     f.Reason,
+		"ip_address": f.IP,
+	}
+	for k, v := range f.Data {
+		data[k] = v
+	}
+	summary := f.Summary
+
+	var lockedUntil *time.Time
+	if f.Count != nil {
+		now := time.Now()
+		var resetBefore time.Time
+		if p.ResetAfter > 0 {
+			resetBefore = now.Add(-p.ResetAfter)
+		}
+		count, err := f.Count(ctx, resetBefore)
+		if err != nil {
+			log.Printf("Gagal mencatat login gagal %s: %v", f.Username, err)
+		} else if d := p.Duration(count); d > 0 {
+			until := now.Add(d)
+			if err := f.Lock(ctx, until); err != nil {
+				log.Printf("Gagal mengunci akun %s: %v", f.Username, err)
+			} else {
+				lockedUntil = &until
+			}
+		}
+		data["failed_login_count"] = count
+		if lockedUntil != nil {
+			data["locked_until"] = lockedUntil
+			summary += fmt.Sprintf(", akun dikunci sampai %s", lockedUntil.Format("2006-01-02 15:04:05"))
+		}
+	}
+
+	if auditLogger != nil {
+		if err := auditLogger.LogInsert(audit.InsertParams{
+			Module: f.Module,
+			Entity: audit.Entity{
+				Table:      "login_attempts",
+				PrimaryKey: map[string]string{"username": f.Username},
+			},
+			InsertedData: data,
+			BusinessKey:  f.Username,
+			Actor:        f.Actor,
+			IP:           f.IP,
+			Summary:      summary,
+		}); err != nil {
+			log.Printf("Gagal menulis audit log login gagal: %v", err)
+		}
+	}
+
+	return lockedUntil != nil
+}
+
+// recordFailedLogin records a failed login of a staff user; user is nil for
+// an unknown username. It reports whether the account is now locked.
+func (s *AuthService) recordFailedLogin(ctx context.Context, user *entity.User, username, ip, reason string) bool {
+	f := FailedLogin{
+		Username: username,
+		IP:       ip,
+		Reason:   reason,
+		Module:   "auth",
+		Actor:    audit.Actor{Username: username},
+		Summary:  fmt.Sprintf("Login gagal untuk %s dari IP %s (%s)", username, ip, reason),
+	}
+	if user != nil {
+		f.Actor.UserID = user.ID
+		f.Data = map[string]interface{}{"user_id": user.ID}
+		if reason != failedLoginAccountLocked && reason != failedLoginUserInactive {
+			f.Count = func(ctx context.Context, resetBefore time.Time) (int, error) {
+				return s.userRepo.RecordFailedLogin(ctx, user.ID, resetBefore)
+			}
+			f.Lock = func(ctx context.Context, until time.Time) error {
+				return s.userRepo.LockUntil(ctx, user.ID, until)
+			}
+		}
+	}
+	return s.lockout.RecordFailedLogin(ctx, s.auditLogger, f)
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/clinova/simrs/backend/internal/auth/entity"
+	"github.com/clinova/simrs/backend/pkg/jwt"
+)
+
+func TestLockoutPolicyDuration(t *testing.T) {
+	capped := LockoutPolicy{Threshold: 5, BaseDuration: time.Minute, MaxDuration: 15 * time.Minute}
+	uncapped := LockoutPolicy{Threshold: 3, BaseDuration: 5 * time.Minute}
+
+	tests := []struct {
+		name     string
+		policy   LockoutPolicy
+		failures int
+		want     time.Duration
+	}{
+		{"disabled", LockoutPolicy{BaseDuration: time.Minute}, 10, 0},
+		{"no failures", capped, 0, 0},
+		{"below threshold", capped, 4, 0},
+		{"at threshold", capped, 5, time.Minute},
+		{"one beyond", capped, 6, 2 * time.Minute},
+		{"two beyond", capped, 7, 4 * time.Minute},
+		{"three beyond", capped, 8, 8 * time.Minute},
+		{"reaches cap", capped, 9, 15 * time.Minute},
+		{"far beyond cap", capped, 100, 15 * time.Minute},
+		{"base above cap", LockoutPolicy{Threshold: 1, BaseDuration: time.Hour, MaxDuration: time.Minute}, 1, time.Minute},
+		{"uncapped at threshold", uncapped, 3, 5 * time.Minute},
+		{"uncapped beyond", uncapped, 6, 40 * time.Minute},
+		{"uncapped stops before overflow", uncapped, 1000, 5 * time.Minute << 24},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.Duration(tt.failures); got != tt.want {
+			t.Errorf("%s: Duration(%d) = %v, want %v", tt.name, tt.failures, got, tt.want)
+		}
+	}
+}
+
+func newLockoutTest(t *testing.T, policy LockoutPolicy) (*AuthService, *fakeUserRepo) {
+	t.Helper()
+	users := newFakeUserRepo(&entity.User{ID: "u1", Username: "staff1", PasswordHash: mustHash(t, "right-pass"), IsActive: true})
+	s := &AuthService{
+		userRepo:        users,
+		sessionRepo:     newFakeSessionRepo(),
+		permissionRepo:  fakePermissionRepo{},
+		jwtManager:      jwt.NewManager("test-secret", time.Minute, time.Hour),
+		passwordHasher:  testHasher,
+		passwordService: NewPasswordService(users, testHasher, PasswordPolicy{}),
+		lockout:         policy,
+	}
+	return s, users
+}
+
+func TestLoginLocksAfterThreshold(t *testing.T) {
+	ctx := context.Background()
+	s, users := newLockoutTest(t, LockoutPolicy{Threshold: 3, BaseDuration: time.Minute, MaxDuration: time.Hour})
+	wrong := &LoginRequest{Username: "staff1", Password: "wrong-pass"}
+
+	for i := 1; i < 3; i++ {
+		if _, err := s.Login(ctx, wrong); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("failure %d: got %v, want ErrInvalidCredentials", i, err)
+		}
+	}
+	if _, err := s.Login(ctx, wrong); !errors.Is(err, ErrAccountLocked) {
+		t.Fatalf("failure at threshold: got %v, want ErrAccountLocked", err)
+	}
+	user := users.users["u1"]
+	if user.LockedUntil == nil || time.Until(*user.LockedUntil) > time.Minute {
+		t.Fatalf("locked until %v, want about a minute from now", user.LockedUntil)
+	}
+
+	// The right password does not get in during the lockout, and the
+	// refused attempt does not extend it
+	lockedUntil := *user.LockedUntil
+	if _, err := s.Login(ctx, &LoginRequest{Username: "staff1", Password: "right-pass"}); !errors.Is(err, ErrAccountLocked) {
+		t.Fatalf("right password while locked: got %v, want ErrAccountLocked", err)
+	}
+	if user.FailedLoginCount != 3 || !user.LockedUntil.Equal(lockedUntil) {
+		t.Fatalf("locked attempt changed the lockout: count %d, until %v", user.FailedLoginCount, user.LockedUntil)
+	}
+}
+
+func TestLoginSuccessResetsFailedLogins(t *testing.T) {
+	ctx := context.Background()
+	s, users := newLockoutTest(t, LockoutPolicy{Threshold: 3, BaseDuration: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.Login(ctx, &LoginRequest{Username: "staff1", Password: "wrong-pass"}); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("got %v, want ErrInvalidCredentials", err)
+		}
+	}
+	if users.users["u1"].FailedLoginCount != 2 {
+		t.Fatalf("failed login count %d, want 2", users.users["u1"].FailedLoginCount)
+	}
+
+	resp, err := s.Login(ctx, &LoginRequest{Username: "staff1", Password: "right-pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Tokens == nil {
+		t.Fatal("login did not create a session")
+	}
+	if users.users["u1"].FailedLoginCount != 0 {
+		t.Fatalf("failed login count %d after success, want 0", users.users["u1"].FailedLoginCount)
+	}
+
+	// Two more failures stay below the threshold again
+	for i := 0; i < 2; i++ {
+		if _, err := s.Login(ctx, &LoginRequest{Username: "staff1", Password: "wrong-pass"}); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("after reset: got %v, want ErrInvalidCredentials", err)
+		}
+	}
+}
+
+func TestLoginFailuresRestartAfterQuietPeriod(t *testing.T) {
+	ctx := context.Background()
+	s, users := newLockoutTest(t, LockoutPolicy{Threshold: 3, BaseDuration: time.Minute, ResetAfter: time.Hour})
+	wrong := &LoginRequest{Username: "staff1", Password: "wrong-pass"}
+
+	for i := 0; i < 2; i++ {
+		s.Login(ctx, wrong)
+	}
+	users.lastFailure["u1"] = time.Now().Add(-2 * time.Hour)
+	if _, err := s.Login(ctx, wrong); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("failure after quiet period: got %v, want ErrInvalidCredentials", err)
+	}
+	if users.users["u1"].FailedLoginCount != 1 {
+		t.Fatalf("failed login count %d, want 1", users.users["u1"].FailedLoginCount)
+	}
+}
+
+func TestRecordFailedLoginHelper(t *testing.T) {
+	ctx := context.Background()
+	policy := LockoutPolicy{Threshold: 2, BaseDuration: time.Minute}
+
+	count := 0
+	var lockedUntil time.Time
+	f := FailedLogin{
+		Username: "verifier1",
+		Reason:   "invalid_password",
+		Count: func(ctx context.Context, resetBefore time.Time) (int, error) {
+			count++
+			return count, nil
+		},
+		Lock: func(ctx context.Context, until time.Time) error {
+			lockedUntil = until
+			return nil
+		},
+	}
+	if policy.RecordFailedLogin(ctx, nil, f) {
+		t.Fatal("locked below the threshold")
+	}
+	if !policy.RecordFailedLogin(ctx, nil, f) || lockedUntil.IsZero() {
+		t.Fatal("not locked at the threshold")
+	}
+
+	// Unknown or uncounted accounts are only audited
+	if policy.RecordFailedLogin(ctx, nil, FailedLogin{Username: "nobody"}) {
+		t.Fatal("uncounted failure locked an account")
+	}
+
+	// A counter error never locks or fails the login
+	f.Count = func(ctx context.Context, resetBefore time.Time) (int, error) {
+		return 0, errors.New("database down")
+	}
+	if policy.RecordFailedLogin(ctx, nil, f) {
+		t.Fatal("locked after a counter error")
+	}
+}
@@ -0,0 +1,517 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/clinova/simrs/backend/internal/auth/entity"
+	"github.com/clinova/simrs/backend/internal/auth/repository"
+	"github.com/clinova/simrs/backend/pkg/audit"
+	"github.com/clinova/simrs/backend/pkg/password"
+	"github.com/clinova/simrs/backend/pkg/secret"
+	"github.com/clinova/simrs/backend/pkg/totp"
+)
+
+var (
+	ErrMFAUnavailable    = errors.New("mfa is not available: encryption key not configured")
+	ErrMFANotEnrolled    = errors.New("mfa enrollment not started")
+	ErrMFAAlreadyEnabled = errors.New("mfa already enabled")
+	ErrMFANotEnabled     = errors.New("mfa not enabled")
+	ErrMFARequired       = errors.New("mfa is required for this user")
+	ErrInvalidMFACode    = errors.New("invalid mfa code")
+	ErrInvalidMFAToken   = errors.New("invalid mfa token")
+)
+
+// recoveryCodeCount is the number of recovery codes issued at a time.
+const recoveryCodeCount = 10
+
+// recoveryCodeBytes is the random length of a recovery code, 16 base32
+// characters.
+const recoveryCodeBytes = 10
+
+// MFAPolicy configures TOTP two-factor login.
+type MFAPolicy struct {
+	Issuer              string
+	ChallengeExpiry     time.Duration
+	RequiredRoles       []string // Role names
+	RequiredPermissions []string // Permission codes
+}
+
+// MFAService handles TOTP enrollment and verification. Secrets are stored
+// encrypted, so MFA is unavailable without a cipher.
+type MFAService struct {
+	mfaRepo        repository.MFARepository
+	userRepo       repository.UserRepository
+	permissionRepo repository.PermissionRepository
+	cipher         *secret.Cipher
+	passwordHasher *password.Hasher
+	auditLogger    *audit.Logger
+	policy         MFAPolicy
+}
+
+func NewMFAService(
+	mfaRepo repository.MFARepository,
+	userRepo repository.UserRepository,
+	permissionRepo repository.PermissionRepository,
+	cipher *secret.Cipher,
+	passwordHasher *password.Hasher,
+	auditLogger *audit.Logger,
+	policy MFAPolicy,
+) *MFAService {
+	return &MFAService{
+		mfaRepo:        mfaRepo,
+		userRepo:       userRepo,
+		permissionRepo: permissionRepo,
+		cipher:         cipher,
+		passwordHasher: passwordHasher,
+		auditLogger:    auditLogger,
+		policy:         policy,
+	}
+}
+
+// Available reports whether MFA can be used at all.
+func (s *MFAService) Available() bool {
+	return s.cipher != nil
+}
+
+// MFAStatus is the MFA state of a user.
+type MFAStatus struct {
+	Available         bool
+	Enabled           bool
+	Pending           bool // Enrollment started but not confirmed
+	Required          bool
+	EnabledAt         *time.Time
+	RecoveryCodesLeft int
+}
+
+// MFAEnrollment is a new TOTP secret awaiting confirmation.
+type MFAEnrollment struct {
+	Secret          string
+	ProvisioningURI string // otpauth:// URI for the QR code
+}
+
+// MFAChallenge is returned by Login instead of tokens when a second factor
+// is needed. EnrollmentRequired is set for users who must use MFA but have
+// not enabled it yet.
+type MFAChallenge struct {
+	Token              string
+	ExpiresAt          time.Time
+	EnrollmentRequired bool
+}
+
+// IsRequired reports whether the policy requires MFA for the user.
+func (s *MFAService) IsRequired(ctx context.Context, userID string) (bool, error) {
+	if len(s.policy.RequiredRoles) > 0 {
+		roles, err := s.userRepo.GetRolesByUserID(ctx, userID)
+		if err != nil {
+			return false, err
+		}
+		for _, r := range roles {
+			for _, name := range s.policy.RequiredRoles {
+				if strings.EqualFold(r.Name, name) {
+					return true, nil
+				}
+			}
+		}
+	}
+	if len(s.policy.RequiredPermissions) > 0 {
+		perms, err := s.permissionRepo.GetEffectivePermissions(ctx, userID)
+		if err != nil {
+			return false, err
+		}
+		for _, code := range s.policy.RequiredPermissions {
+			if perms[code] {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (s *MFAService) GetStatus(ctx context.Context, userID string) (*MFAStatus, error) {
+	status := &MFAStatus{Available: s.Available()}
+	required, err := s.IsRequired(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	status.Required = required
+
+	m, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return status, nil
+	}
+	status.Enabled = m.IsEnabled()
+	status.Pending = !m.IsEnabled()
+	status.EnabledAt = m.EnabledAt
+	if status.Enabled {
+		if status.RecoveryCodesLeft, err = s.mfaRepo.CountRecoveryCodes(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// Enroll starts enrollment with a new secret, replacing any pending one.
+// MFA stays off until Activate confirms a code from the authenticator app.
+func (s *MFAService) Enroll(ctx context.Context, userID string) (*MFAEnrollment, error) {
+	if !s.Available() {
+		return nil, ErrMFAUnavailable
+	}
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	m, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m != nil && m.IsEnabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	key, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.cipher.Encrypt(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.SavePending(ctx, userID, encrypted); err != nil {
+		return nil, err
+	}
+
+	return &MFAEnrollment{
+		Secret:          key,
+		ProvisioningURI: totp.ProvisioningURI(s.policy.Issuer, user.Username, key),
+	}, nil
+}
+
+// Activate confirms a pending enrollment with a code and returns the
+// recovery codes. They are only shown once.
+func (s *MFAService) Activate(ctx context.Context, userID, code, ip string) ([]string, error) {
+	if !s.Available() {
+		return nil, ErrMFAUnavailable
+	}
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	m, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if m.IsEnabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	step, err := s.validateCode(m, code)
+	if err != nil {
+		return nil, err
+	}
+	codes, hashes, err := s.generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.Enable(ctx, userID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	// Audit log for MFA enrollment
+	if s.auditLogger != nil {
+		if err := s.auditLogger.LogInsert(audit.InsertParams{
+			Module: "auth",
+			Entity: audit.Entity{
+				Table:      "user_mfa",
+				PrimaryKey: map[string]string{"user_id": userID},
+			},
+			InsertedData: map[string]interface{}{
+				"user_id":        userID,
+				"recovery_codes": len(codes),
+			},
+			BusinessKey: user.Username,
+			Actor:       audit.Actor{UserID: user.ID, Username: user.Username},
+			IP:          ip,
+			Summary:     fmt.Sprintf("Pengguna %s mengaktifkan autentikasi dua faktor", user.Username),
+		}); err != nil {
+			log.Printf("Gagal menulis audit log aktivasi MFA: %v", err)
+		}
+	}
+
+	return codes, nil
+}
+
+// Disable turns MFA off after checking the password and a code or
+// recovery code. Users the policy requires MFA of cannot disable it.
+func (s *MFAService) Disable(ctx context.Context, userID, pass, code, recoveryCode, ip string) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.passwordHasher.Verify(pass, user.PasswordHash); err != nil {
+		return ErrInvalidCredentials
+	}
+	m, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if m == nil || !m.IsEnabled() {
+		return ErrMFANotEnabled
+	}
+	required, err := s.IsRequired(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFARequired
+	}
+	if err := s.verify(ctx, user, m, code, recoveryCode, ip); err != nil {
+		return err
+	}
+
+	if err := s.mfaRepo.Delete(ctx, userID); err != nil {
+		return err
+	}
+
+	// Audit log for MFA removal
+	if s.auditLogger != nil {
+		if err := s.auditLogger.LogDelete(audit.DeleteParams{
+			Module: "auth",
+			Entity: audit.Entity{
+				Table:      "user_mfa",
+				PrimaryKey: map[string]string{"user_id": userID},
+			},
+			DeletedData: map[string]interface{}{
+				"user_id":    userID,
+				"enabled_at": m.EnabledAt,
+			},
+			Where:       map[string]interface{}{"user_id": userID},
+			BusinessKey: user.Username,
+			Actor:       audit.Actor{UserID: user.ID, Username: user.Username},
+			IP:          ip,
+			Summary:     fmt.Sprintf("Pengguna %s menonaktifkan autentikasi dua faktor", user.Username),
+		}); err != nil {
+			log.Printf("Gagal menulis audit log nonaktif MFA: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes after checking a code.
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID, code, ip string) ([]string, error) {
+	if !s.Available() {
+		return nil, ErrMFAUnavailable
+	}
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	m, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil || !m.IsEnabled() {
+		return nil, ErrMFANotEnabled
+	}
+	if err := s.verify(ctx, user, m, code, "", ip); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := s.generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	if s.auditLogger != nil {
+		if err := s.auditLogger.LogInsert(audit.InsertParams{
+			Module: "auth",
+			Entity: audit.Entity{
+				Table:      "user_mfa_recovery_codes",
+				PrimaryKey: map[string]string{"user_id": userID},
+			},
+			InsertedData: map[string]interface{}{
+				"user_id":        userID,
+				"recovery_codes": len(codes),
+			},
+			BusinessKey: user.Username,
+			Actor:       audit.Actor{UserID: user.ID, Username: user.Username},
+			IP:          ip,
+			Summary:     fmt.Sprintf("Pengguna %s membuat ulang kode pemulihan MFA", user.Username),
+		}); err != nil {
+			log.Printf("Gagal menulis audit log kode pemulihan MFA: %v", err)
+		}
+	}
+
+	return codes, nil
+}
+
+// loginState reports whether the user has MFA enabled and whether the
+// policy requires it. Both are false when MFA is unavailable.
+func (s *MFAService) loginState(ctx context.Context, userID string) (enabled, required bool, err error) {
+	if !s.Available() {
+		return false, false, nil
+	}
+	m, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return false, false, err
+	}
+	required, err = s.IsRequired(ctx, userID)
+	if err != nil {
+		return false, false, err
+	}
+	return m != nil && m.IsEnabled(), required, nil
+}
+
+// verifyLogin checks the second factor of a login. A user who had to
+// enroll during login confirms the pending enrollment with the code, and
+// gets the recovery codes back.
+func (s *MFAService) verifyLogin(ctx context.Context, user *entity.User, code, recoveryCode, ip string) ([]string, error) {
+	if !s.Available() {
+		return nil, ErrMFAUnavailable
+	}
+	m, err := s.mfaRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if !m.IsEnabled() {
+		return s.Activate(ctx, user.ID, code, ip)
+	}
+	return nil, s.verify(ctx, user, m, code, recoveryCode, ip)
+}
+
+// verify checks a code, or else a recovery code, of an enabled enrollment.
+func (s *MFAService) verify(ctx context.Context, user *entity.User, m *entity.UserMFA, code, recoveryCode, ip string) error {
+	if code != "" {
+		step, err := s.validateCode(m, code)
+		if err != nil {
+			return err
+		}
+		ok, err := s.mfaRepo.UseStep(ctx, user.ID, step)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	normalized := normalizeRecoveryCode(recoveryCode)
+	if normalized == "" {
+		return ErrInvalidMFACode
+	}
+	unused, err := s.mfaRepo.UnusedRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	var match *entity.MFARecoveryCode
+	for i := range unused {
+		if s.passwordHasher.Verify(normalized, unused[i].CodeHash) == nil {
+			match = &unused[i]
+			break
+		}
+	}
+	if match == nil {
+		return ErrInvalidMFACode
+	}
+	ok, err := s.mfaRepo.UseRecoveryCode(ctx, user.ID, match.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidMFACode
+	}
+
+	if s.auditLogger != nil {
+		if err := s.auditLogger.LogInsert(audit.InsertParams{
+			Module: "auth",
+			Entity: audit.Entity{
+				Table:      "user_mfa_recovery_codes",
+				PrimaryKey: map[string]string{"user_id": user.ID},
+			},
+			InsertedData: map[string]interface{}{
+				"action":  "use_recovery_code",
+				"user_id": user.ID,
+			},
+			BusinessKey: user.Username,
+			Actor:       audit.Actor{UserID: user.ID, Username: user.Username},
+			IP:          ip,
+			Summary:     fmt.Sprintf("Pengguna %s memakai kode pemulihan MFA", user.Username),
+		}); err != nil {
+			log.Printf("Gagal menulis audit log kode pemulihan MFA: %v", err)
+		}
+	}
+	return nil
+}
+
+// validateCode checks a TOTP code against the enrollment secret and
+// returns its time step. Steps at or before the last accepted one are
+// rejected.
+func (s *MFAService) validateCode(m *entity.UserMFA, code string) (int64, error) {
+	key, err := s.cipher.Decrypt(m.SecretEncrypted)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decrypt mfa secret: %w", err)
+	}
+	step, ok := totp.Validate(key, code, time.Now())
+	if !ok || step <= m.LastUsedStep {
+		return 0, ErrInvalidMFACode
+	}
+	return step, nil
+}
+
+func (s *MFAService) getUser(ctx context.Context, userID string) (*entity.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// generateRecoveryCodes returns new recovery codes (XXXX-XXXX-XXXX-XXXX,
+// 80 random bits) and their bcrypt hashes.
+func (s *MFAService) generateRecoveryCodes() (codes, hashes []string, err error) {
+	codes = make([]string, recoveryCodeCount)
+	hashes = make([]string, recoveryCodeCount)
+	buf := make([]byte, recoveryCodeBytes)
+	for i := range codes {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := base32.StdEncoding.EncodeToString(buf)
+		codes[i] = raw[:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:]
+		if hashes[i], err = s.passwordHasher.Hash(raw); err != nil {
+			return nil, nil, fmt.Errorf("failed to hash recovery code: %w", err)
+		}
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode drops separators and case, so codes may be typed
+// as shown or without the dash.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	return code
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/clinova/simrs/backend/internal/auth/entity"
+	"github.com/clinova/simrs/backend/internal/auth/repository"
+	"github.com/clinova/simrs/backend/pkg/password"
+	"github.com/clinova/simrs/backend/pkg/secret"
+	"github.com/clinova/simrs/backend/pkg/totp"
+)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	s := &MFAService{passwordHasher: password.NewHasher(4)}
+	codes, hashes, err := s.generateRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != recoveryCodeCount || len(hashes) != recoveryCodeCount {
+		t.Fatalf("got %d codes and %d hashes, want %d", len(codes), len(hashes), recoveryCodeCount)
+	}
+	for i, code := range codes {
+		if len(code) != 19 {
+			t.Errorf("code %q is not XXXX-XXXX-XXXX-XXXX", code)
+		}
+		if err := s.passwordHasher.Verify(normalizeRecoveryCode(code), hashes[i]); err != nil {
+			t.Errorf("code %q does not match its hash: %v", code, err)
+		}
+		if i > 0 && s.passwordHasher.Verify(normalizeRecoveryCode(code), hashes[0]) == nil {
+			t.Errorf("code %q matches the hash of %q", code, codes[0])
+		}
+	}
+}
+
+// fakeMFARepo records accepted steps the way UseStep's conditional UPDATE does.
+type fakeMFARepo struct {
+	repository.MFARepository
+	lastUsedStep int64
+}
+
+func (f *fakeMFARepo) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	if step <= f.lastUsedStep {
+		return false, nil
+	}
+	f.lastUsedStep = step
+	return true, nil
+}
+
+func TestVerifyRejectsReusedStep(t *testing.T) {
+	cipher, err := secret.NewCipher("test-passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := cipher.Encrypt(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := totp.Code(key, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &fakeMFARepo{}
+	s := &MFAService{mfaRepo: repo, cipher: cipher}
+	user := &entity.User{ID: "u1", Username: "staff1"}
+	ctx := context.Background()
+
+	stale := &entity.UserMFA{UserID: user.ID, SecretEncrypted: encrypted}
+	if err := s.verify(ctx, user, stale, code, "", ""); err != nil {
+		t.Fatalf("first use: got %v, want nil", err)
+	}
+
+	// A second request that loaded the enrollment before the first one
+	// recorded its step passes validateCode but loses on UseStep.
+	if err := s.verify(ctx, user, stale, code, "", ""); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("concurrent reuse: got %v, want ErrInvalidMFACode", err)
+	}
+
+	// A fresh load carries last_used_step, so validateCode rejects the code.
+	current := &entity.UserMFA{UserID: user.ID, SecretEncrypted: encrypted, LastUsedStep: repo.lastUsedStep}
+	if _, err := s.validateCode(current, code); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("reuse in same step: got %v, want ErrInvalidMFACode", err)
+	}
+}
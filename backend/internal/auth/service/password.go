@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/clinova/simrs/backend/internal/auth/entity"
+	"github.com/clinova/simrs/backend/internal/auth/repository"
+	"github.com/clinova/simrs/backend/pkg/audit"
+	"github.com/clinova/simrs/backend/pkg/password"
+)
+
+var ErrInvalidPasswordChangeToken = errors.New("invalid or expired password change token")
+
+// Reasons a login must change the password first.
+const (
+	PasswordChangeReset   = "reset"   // Set by an administrator
+	PasswordChangeExpired = "expired" // Older than PasswordPolicy.MaxAge
+)
+
+// PasswordPolicy configures password rules, history and expiry.
+type PasswordPolicy struct {
+	password.Policy
+	HistoryCount int           // Last passwords that may not be reused, the current one included; 0 disables
+	MaxAge       time.Duration // Passwords older than this must be changed at login; 0 disables
+	ChangeExpiry time.Duration // Lifetime of the password change token from /auth/login
+}
+
+// PasswordChangeChallenge is returned by a login whose password must be
+// changed before a session is created.
+type PasswordChangeChallenge struct {
+	Token     string
+	ExpiresAt time.Time
+	Reason    string
+}
+
+// LoginPasswordChangeRequest completes a login by setting a new password.
+type LoginPasswordChangeRequest struct {
+	Token       string
+	NewPassword string
+	DeviceInfo  string
+	IPAddress   string
+}
+
+// ChangePasswordRequest is a self-service password change.
+type ChangePasswordRequest struct {
+	UserID          string
+	SessionID       string // Kept active; the user's other sessions are revoked
+	CurrentPassword string
+	NewPassword     string
+	IPAddress       string
+}
+
+// PasswordService enforces the password policy on every password set.
+type PasswordService struct {
+	userRepo       repository.UserRepository
+	passwordHasher *password.Hasher
+	policy         PasswordPolicy
+}
+
+func NewPasswordService(userRepo repository.UserRepository, passwordHasher *password.Hasher, policy PasswordPolicy) *PasswordService {
+	return &PasswordService{userRepo: userRepo, passwordHasher: passwordHasher, policy: policy}
+}
+
+// Policy returns the configured policy.
+func (s *PasswordService) Policy() PasswordPolicy {
+	return s.policy
+}
+
+// Validate checks plain against the policy and, for an existing user, the
+// password history. It returns a *password.PolicyError on violations.
+func (s *PasswordService) Validate(ctx context.Context, user *entity.User, username, plain string) error {
+	if err := s.policy.Check(plain, username); err != nil {
+		return err
+	}
+	if user == nil || s.policy.HistoryCount <= 0 {
+		return nil
+	}
+
+	history, err := s.userRepo.GetPasswordHistory(ctx, user.ID, s.policy.HistoryCount)
+	if err != nil {
+		return err
+	}
+	// The current hash covers users whose history predates the policy
+	seen := map[string]bool{}
+	for _, hash := range append([]string{user.PasswordHash}, history...) {
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		if s.passwordHasher.Verify(plain, hash) == nil {
+			return &password.PolicyError{Violations: []string{password.ViolationReused}, MinLength: s.policy.MinLength}
+		}
+	}
+	return nil
+}
+
+// SetPassword validates and stores a new password of an existing user.
+// mustChange forces the user to change it at the next login.
+func (s *PasswordService) SetPassword(ctx context.Context, user *entity.User, plain string, mustChange bool) error {
+	if err := s.Validate(ctx, user, user.Username, plain); err != nil {
+		return err
+	}
+	hash, err := s.passwordHasher.Hash(plain)
+	if err != nil {
+		return err
+	}
+	return s.userRepo.UpdatePassword(ctx, user.ID, hash, mustChange, s.policy.HistoryCount)
+}
+
+// HashNew validates and hashes the password of a user being created. Call
+// RecordHistory once the user is stored.
+func (s *PasswordService) HashNew(ctx context.Context, username, plain string) (string, error) {
+	if err := s.Validate(ctx, nil, username, plain); err != nil {
+		return "", err
+	}
+	return s.passwordHasher.Hash(plain)
+}
+
+// RecordHistory adds the password of a new user to the history.
+func (s *PasswordService) RecordHistory(ctx context.Context, userID, hash string) error {
+	return s.userRepo.AddPasswordHistory(ctx, userID, hash, s.policy.HistoryCount)
+}
+
+// ChangeReason returns why the user must change the password before logging
+// in, or "" when the password may be used.
+func (s *PasswordService) ChangeReason(user *entity.User, now time.Time) string {
+	if user.MustChangePassword {
+		return PasswordChangeReset
+	}
+	if s.policy.MaxAge > 0 && user.PasswordAge(now) > s.policy.MaxAge {
+		return PasswordChangeExpired
+	}
+	return ""
+}
+
+// finishLogin asks for a new password when the current one must be changed,
+// and otherwise creates the session.
+func (s *AuthService) finishLogin(ctx context.Context, user *entity.User, deviceInfo, ipAddress string) (*LoginResponse, error) {
+	reason := s.passwordService.ChangeReason(user, time.Now())
+	if reason == "" {
+		return s.completeLogin(ctx, user, deviceInfo, ipAddress)
+	}
+
+	token, expiresAt, err := s.jwtManager.GeneratePasswordChangeToken(user.ID, s.passwordService.policy.ChangeExpiry)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResponse{PasswordChange: &PasswordChangeChallenge{
+		Token:     token,
+		ExpiresAt: expiresAt,
+		Reason:    reason,
+	}}, nil
+}
+
+// LoginPasswordChange completes a login started by Login with the password
+// change token and the new password.
+func (s *AuthService) LoginPasswordChange(ctx context.Context, req *LoginPasswordChangeRequest) (*LoginResponse, error) {
+	claims, err := s.jwtManager.ValidatePasswordChangeToken(req.Token)
+	if err != nil {
+		return nil, ErrInvalidPasswordChangeToken
+	}
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidPasswordChangeToken
+	}
+	now := time.Now()
+	// The token is spent once the password no longer needs changing
+	reason := s.passwordService.ChangeReason(user, now)
+	if reason == "" {
+		return nil, ErrInvalidPasswordChangeToken
+	}
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+	if user.IsLocked(now) {
+		return nil, ErrAccountLocked
+	}
+
+	if err := s.passwordService.SetPassword(ctx, user, req.NewPassword, false); err != nil {
+		return nil, err
+	}
+	s.logPasswordChange(user, req.IPAddress, fmt.Sprintf("Pengguna %s mengganti password saat login (%s)", user.Username, reason))
+
+	return s.completeLogin(ctx, user, req.DeviceInfo, req.IPAddress)
+}
+
+// ChangePassword changes the password of a logged-in user after checking the
+// current one, and revokes the user's other sessions.
+func (s *AuthService) ChangePassword(ctx context.Context, req *ChangePasswordRequest) (int, error) {
+	user, err := s.userRepo.GetByID(ctx, req.UserID)
+	if err != nil {
+		return 0, err
+	}
+	if user == nil {
+		return 0, ErrUserNotFound
+	}
+	if user.IsLocked(time.Now()) {
+		return 0, ErrAccountLocked
+	}
+
+	// A wrong current password counts towards the lockout, so a stolen
+	// session cannot be used to guess it
+	if err := s.passwordHasher.Verify(req.CurrentPassword, user.PasswordHash); err != nil {
+		if s.recordFailedLogin(ctx, user, user.Username, req.IPAddress, failedLoginInvalidPassword) {
+			return 0, ErrAccountLocked
+		}
+		return 0, ErrInvalidCredentials
+	}
+
+	if err := s.passwordService.SetPassword(ctx, user, req.NewPassword, false); err != nil {
+		return 0, err
+	}
+
+	revoked, err := s.sessionRepo.RevokeOthersByUserID(ctx, user.ID, req.SessionID)
+	if err != nil {
+		log.Printf("Gagal mencabut sesi lain %s: %v", user.Username, err)
+	}
+	s.logPasswordChange(user, req.IPAddress, fmt.Sprintf("Pengguna %s mengganti password, %d sesi lain dicabut", user.Username, revoked))
+
+	return revoked, nil
+}
+
+func (s *AuthService) logPasswordChange(user *entity.User, ip, summary string) {
+	if s.auditLogger == nil {
+		return
+	}
+	if err := s.auditLogger.LogUpdate(audit.UpdateParams{
+		Module: "auth",
+		Entity: audit.Entity{
+			Table:      "users",
+			PrimaryKey: map[string]string{"id": user.ID},
+		},
+		ChangedColumns: map[string]audit.ColumnChange{
+			"password_hash": {Old: "[REDACTED]", New: "[REDACTED]"},
+		},
+		Where:       map[string]interface{}{"id": user.ID},
+		BusinessKey: user.Username,
+		Actor:       audit.Actor{UserID: user.ID, Username: user.Username},
+		IP:          ip,
+		Summary:     summary,
+	}); err != nil {
+		log.Printf("Gagal menulis audit log ganti password: %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/clinova/simrs/backend/internal/auth/entity"
+	"github.com/clinova/simrs/backend/pkg/password"
+)
+
+var testHasher = password.NewHasher(4)
+
+func mustHash(t *testing.T, plain string) string {
+	t.Helper()
+	hash, err := testHasher.Hash(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func isViolation(err error, violation string) bool {
+	var policyErr *password.PolicyError
+	return errors.As(err, &policyErr) && reflect.DeepEqual(policyErr.Violations, []string{violation})
+}
+
+func TestPasswordHistoryReuse(t *testing.T) {
+	ctx := context.Background()
+	user := &entity.User{ID: "u1", Username: "staff1"}
+	repo := newFakeUserRepo(user)
+	s := NewPasswordService(repo, testHasher, PasswordPolicy{HistoryCount: 3})
+
+	// Created and then changed twice within the same second
+	user.PasswordHash = mustHash(t, "first-pass")
+	if err := s.RecordHistory(ctx, user.ID, user.PasswordHash); err != nil {
+		t.Fatal(err)
+	}
+	for _, plain := range []string{"second-pass", "third-pass"} {
+		if err := s.SetPassword(ctx, user, plain, false); err != nil {
+			t.Fatalf("set %s: %v", plain, err)
+		}
+		*user = *repo.users[user.ID]
+	}
+
+	for _, plain := range []string{"first-pass", "second-pass", "third-pass"} {
+		if err := s.Validate(ctx, user, user.Username, plain); !isViolation(err, password.ViolationReused) {
+			t.Errorf("%s within history: got %v, want reused", plain, err)
+		}
+	}
+
+	// A fourth password pushes the oldest one out of the window
+	if err := s.SetPassword(ctx, user, "fourth-pass", false); err != nil {
+		t.Fatal(err)
+	}
+	*user = *repo.users[user.ID]
+	if got := len(repo.history[user.ID]); got != 3 {
+		t.Fatalf("history keeps %d entries, want 3", got)
+	}
+	if err := s.Validate(ctx, user, user.Username, "first-pass"); err != nil {
+		t.Errorf("first-pass outside history: got %v, want nil", err)
+	}
+	if err := s.Validate(ctx, user, user.Username, "second-pass"); !isViolation(err, password.ViolationReused) {
+		t.Errorf("second-pass within history: got %v, want reused", err)
+	}
+}
+
+func TestPasswordHistoryCoversCurrentHash(t *testing.T) {
+	// Users created before the history table have no entries yet
+	user := &entity.User{ID: "u1", Username: "staff1", PasswordHash: mustHash(t, "legacy-pass")}
+	s := NewPasswordService(newFakeUserRepo(user), testHasher, PasswordPolicy{HistoryCount: 5})
+	if err := s.Validate(context.Background(), user, user.Username, "legacy-pass"); !isViolation(err, password.ViolationReused) {
+		t.Fatalf("got %v, want reused", err)
+	}
+
+	s = NewPasswordService(newFakeUserRepo(user), testHasher, PasswordPolicy{})
+	if err := s.Validate(context.Background(), user, user.Username, "legacy-pass"); err != nil {
+		t.Fatalf("history disabled: got %v, want nil", err)
+	}
+}
+
+func TestChangeReason(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-91 * 24 * time.Hour)
+	recent := now.Add(-time.Hour)
+	maxAge := 90 * 24 * time.Hour
+
+	tests := []struct {
+		name   string
+		user   entity.User
+		maxAge time.Duration
+		want   string
+	}{
+		{"fresh", entity.User{PasswordChangedAt: &recent}, maxAge, ""},
+		{"expired", entity.User{PasswordChangedAt: &old}, maxAge, PasswordChangeExpired},
+		{"expiry disabled", entity.User{PasswordChangedAt: &old}, 0, ""},
+		{"unknown age", entity.User{}, maxAge, ""},
+		{"reset", entity.User{PasswordChangedAt: &recent, MustChangePassword: true}, maxAge, PasswordChangeReset},
+		{"reset wins over expiry", entity.User{PasswordChangedAt: &old, MustChangePassword: true}, maxAge, PasswordChangeReset},
+	}
+	for _, tt := range tests {
+		s := &PasswordService{policy: PasswordPolicy{MaxAge: tt.maxAge}}
+		if got := s.ChangeReason(&tt.user, now); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestChangePasswordRevokesOtherSessions(t *testing.T) {
+	ctx := context.Background()
+	user := &entity.User{ID: "u1", Username: "staff1", PasswordHash: mustHash(t, "old-pass"), IsActive: true, MustChangePassword: true}
+	users := newFakeUserRepo(user)
+	sessions := newFakeSessionRepo(
+		&entity.LoginSession{ID: "current", UserID: "u1"},
+		&entity.LoginSession{ID: "laptop", UserID: "u1"},
+		&entity.LoginSession{ID: "phone", UserID: "u1"},
+		&entity.LoginSession{ID: "someone-else", UserID: "u2"},
+	)
+	s := &AuthService{
+		userRepo:        users,
+		sessionRepo:     sessions,
+		passwordHasher:  testHasher,
+		passwordService: NewPasswordService(users, testHasher, PasswordPolicy{HistoryCount: 3}),
+	}
+
+	req := &ChangePasswordRequest{UserID: "u1", SessionID: "current", CurrentPassword: "wrong", NewPassword: "new-pass"}
+	if _, err := s.ChangePassword(ctx, req); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("wrong current password: got %v, want ErrInvalidCredentials", err)
+	}
+	if users.users["u1"].FailedLoginCount != 1 {
+		t.Fatalf("wrong current password not counted as a failed login")
+	}
+	for id, sess := range sessions.sessions {
+		if !sess.IsActive() {
+			t.Fatalf("session %s revoked by a failed change", id)
+		}
+	}
+
+	req.CurrentPassword = "old-pass"
+	req.NewPassword = "old-pass"
+	if _, err := s.ChangePassword(ctx, req); !isViolation(err, password.ViolationReused) {
+		t.Fatalf("reusing current password: got %v, want reused", err)
+	}
+
+	req.NewPassword = "new-pass"
+	revoked, err := s.ChangePassword(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revoked != 2 {
+		t.Fatalf("revoked %d sessions, want 2", revoked)
+	}
+	for id, want := range map[string]bool{"current": true, "laptop": false, "phone": false, "someone-else": true} {
+		if got := sessions.sessions[id].IsActive(); got != want {
+			t.Errorf("session %s active = %v, want %v", id, got, want)
+		}
+	}
+
+	stored := users.users["u1"]
+	if testHasher.Verify("new-pass", stored.PasswordHash) != nil {
+		t.Error("new password not stored")
+	}
+	if stored.MustChangePassword {
+		t.Error("must_change_password not cleared by the user's own change")
+	}
+}
@@ -25,16 +25,24 @@ var (
 	ErrSessionRevoked      = errors.New("session has been revoked")
 	ErrSessionNotFound     = errors.New("session not found")
 	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused, session revoked")
+	ErrAccountLocked       = errors.New("account is temporarily locked")
 )
 
 // AuthService handles authentication operations.
 type AuthService struct {
-	userRepo       repository.UserRepository
-	sessionRepo    repository.SessionRepository
-	permissionRepo repository.PermissionRepository
-	jwtManager     *jwt.Manager
-	passwordHasher *password.Hasher
-	auditLogger    *audit.Logger
+	userRepo        repository.UserRepository
+	sessionRepo     repository.SessionRepository
+	permissionRepo  repository.PermissionRepository
+	jwtManager      *jwt.Manager
+	passwordHasher  *password.Hasher
+	passwordService *PasswordService
+	mfaService      *MFAService
+	lockout         LockoutPolicy
+	// refreshReuseGrace is how long after a rotation the previous refresh
+	// token is refused without revoking the session
+	refreshReuseGrace time.Duration
+	auditLogger       *audit.Logger
 }
 
 func NewAuthService(
@@ -43,15 +51,23 @@ func NewAuthService(
 	permissionRepo repository.PermissionRepository,
 	jwtManager *jwt.Manager,
 	passwordHasher *password.Hasher,
+	passwordService *PasswordService,
+	mfaService *MFAService,
+	lockout LockoutPolicy,
+	refreshReuseGrace time.Duration,
 	auditLogger *audit.Logger,
 ) *AuthService {
 	return &AuthService{
-		userRepo:       userRepo,
-		sessionRepo:    sessionRepo,
-		permissionRepo: permissionRepo,
-		jwtManager:     jwtManager,
-		passwordHasher: passwordHasher,
-		auditLogger:    auditLogger,
+		userRepo:          userRepo,
+		sessionRepo:       sessionRepo,
+		permissionRepo:    permissionRepo,
+		jwtManager:        jwtManager,
+		passwordHasher:    passwordHasher,
+		passwordService:   passwordService,
+		mfaService:        mfaService,
+		lockout:           lockout,
+		refreshReuseGrace: refreshReuseGrace,
+		auditLogger:       auditLogger,
 	}
 }
 
@@ -62,10 +78,25 @@ type LoginRequest struct {
 	IPAddress  string
 }
 
+// LoginResponse holds either the tokens of a new session, an MFA challenge
+// when a second factor is still needed, or a password change challenge when
+// the password must be changed first.
 type LoginResponse struct {
-	User      *UserInfo
-	Tokens    *jwt.TokenPair
-	SessionID string
+	User           *UserInfo
+	Tokens         *jwt.TokenPair
+	SessionID      string
+	MFA            *MFAChallenge
+	PasswordChange *PasswordChangeChallenge
+	RecoveryCodes  []string // Set when MFA was enrolled during login
+}
+
+// LoginMFARequest completes a login with a TOTP code or a recovery code.
+type LoginMFARequest struct {
+	MFAToken     string
+	Code         string
+	RecoveryCode string
+	DeviceInfo   string
+	IPAddress    string
 }
 
 type UserInfo struct {
@@ -84,17 +115,112 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*LoginRespo
 		return nil, err
 	}
 	if user == nil {
+		s.recordFailedLogin(ctx, nil, req.Username, req.IPAddress, failedLoginUnknownUser)
 		return nil, ErrInvalidCredentials
 	}
 
+	// A locked account is refused before the password is checked, so
+	// guessing cannot continue during the lockout.
+	if user.IsLocked(time.Now()) {
+		s.recordFailedLogin(ctx, user, user.Username, req.IPAddress, failedLoginAccountLocked)
+		return nil, ErrAccountLocked
+	}
+
 	if err := s.passwordHasher.Verify(req.Password, user.PasswordHash); err != nil {
+		if s.recordFailedLogin(ctx, user, user.Username, req.IPAddress, failedLoginInvalidPassword) {
+			return nil, ErrAccountLocked
+		}
 		return nil, ErrInvalidCredentials
 	}
 
 	if !user.IsActive {
+		s.recordFailedLogin(ctx, user, user.Username, req.IPAddress, failedLoginUserInactive)
 		return nil, ErrUserInactive
 	}
 
+	if s.mfaService != nil {
+		enabled, required, err := s.mfaService.loginState(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if enabled || required {
+			token, expiresAt, err := s.jwtManager.GenerateMFAToken(user.ID, s.mfaService.policy.ChallengeExpiry)
+			if err != nil {
+				return nil, err
+			}
+			return &LoginResponse{MFA: &MFAChallenge{
+				Token:              token,
+				ExpiresAt:          expiresAt,
+				EnrollmentRequired: !enabled,
+			}}, nil
+		}
+	}
+
+	return s.finishLogin(ctx, user, req.DeviceInfo, req.IPAddress)
+}
+
+// LoginMFA completes a login started by Login with the MFA challenge token
+// and a TOTP code or recovery code.
+func (s *AuthService) LoginMFA(ctx context.Context, req *LoginMFARequest) (*LoginResponse, error) {
+	user, err := s.mfaChallengeUser(ctx, req.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+	recoveryCodes, err := s.mfaService.verifyLogin(ctx, user, req.Code, req.RecoveryCode, req.IPAddress)
+	if errors.Is(err, ErrInvalidMFACode) {
+		if s.recordFailedLogin(ctx, user, user.Username, req.IPAddress, failedLoginInvalidMFACode) {
+			return nil, ErrAccountLocked
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.finishLogin(ctx, user, req.DeviceInfo, req.IPAddress)
+	if err != nil {
+		return nil, err
+	}
+	result.RecoveryCodes = recoveryCodes
+	return result, nil
+}
+
+// EnrollMFAChallenge starts MFA enrollment for a user whose login requires
+// it, authenticated by the MFA challenge token.
+func (s *AuthService) EnrollMFAChallenge(ctx context.Context, mfaToken string) (*MFAEnrollment, error) {
+	user, err := s.mfaChallengeUser(ctx, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.mfaService.Enroll(ctx, user.ID)
+}
+
+func (s *AuthService) mfaChallengeUser(ctx context.Context, mfaToken string) (*entity.User, error) {
+	if s.mfaService == nil {
+		return nil, ErrMFAUnavailable
+	}
+	claims, err := s.jwtManager.ValidateMFAToken(mfaToken)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidMFAToken
+	}
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+	if user.IsLocked(time.Now()) {
+		return nil, ErrAccountLocked
+	}
+	return user, nil
+}
+
+// completeLogin creates the session of an authenticated user.
+func (s *AuthService) completeLogin(ctx context.Context, user *entity.User, deviceInfo, ipAddress string) (*LoginResponse, error) {
 	sessionID := uuid.New().String()
 	tokens, err := s.jwtManager.GenerateTokenPair(user.ID, sessionID)
 	if err != nil {
@@ -105,8 +231,8 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*LoginRespo
 		ID:               sessionID,
 		UserID:           user.ID,
 		RefreshTokenHash: jwt.HashToken(tokens.RefreshToken),
-		DeviceInfo:       req.DeviceInfo,
-		IPAddress:        req.IPAddress,
+		DeviceInfo:       deviceInfo,
+		IPAddress:        ipAddress,
 	}
 
 	if err := s.sessionRepo.Create(ctx, session); err != nil {
@@ -114,6 +240,11 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*LoginRespo
 	}
 
 	s.userRepo.UpdateLastLogin(ctx, user.ID)
+	if user.FailedLoginCount > 0 || user.LockedUntil != nil {
+		if err := s.userRepo.ResetFailedLogins(ctx, user.ID); err != nil {
+			log.Printf("Gagal mereset hitungan login gagal %s: %v", user.Username, err)
+		}
+	}
 
 	roles, _ := s.userRepo.GetRolesByUserID(ctx, user.ID)
 	roleNames := make([]string, len(roles))
@@ -154,13 +285,13 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*LoginRespo
 			InsertedData: map[string]interface{}{
 				"id":          sessionID,
 				"user_id":     user.ID,
-				"device_info": req.DeviceInfo,
-				"ip_address":  req.IPAddress,
+				"device_info": deviceInfo,
+				"ip_address":  ipAddress,
 			},
 			BusinessKey: user.Username,
 			Actor:       audit.Actor{UserID: user.ID, Username: user.Username},
-			IP:          req.IPAddress,
-			Summary:     fmt.Sprintf("Pengguna %s berhasil login dari IP %s", user.Username, req.IPAddress),
+			IP:          ipAddress,
+			Summary:     fmt.Sprintf("Pengguna %s berhasil login dari IP %s", user.Username, ipAddress),
 		}); err != nil {
 			log.Printf("Gagal menulis audit log login: %v", err)
 		}
@@ -173,7 +304,10 @@ func (s *AuthService) Logout(ctx context.Context, sessionID string) error {
 	return s.sessionRepo.Revoke(ctx, sessionID)
 }
 
-func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*jwt.TokenPair, error) {
+// RefreshToken rotates the refresh token of a session: the presented token
+// is replaced by a new one on the same session. A token of the session that
+// is no longer current means it was copied, so the session is revoked.
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken, ipAddress string) (*jwt.TokenPair, error) {
 	claims, err := s.jwtManager.ValidateRefreshToken(refreshToken)
 	if err != nil {
 		return nil, ErrInvalidRefreshToken
@@ -185,7 +319,7 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*j
 		return nil, err
 	}
 	if session == nil {
-		return nil, ErrSessionNotFound
+		return nil, s.handleRefreshReuse(ctx, claims, tokenHash, ipAddress)
 	}
 	if !session.IsActive() {
 		return nil, ErrSessionRevoked
@@ -202,27 +336,77 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*j
 		return nil, ErrUserInactive
 	}
 
-	s.sessionRepo.Revoke(ctx, session.ID)
+	tokens, err := s.jwtManager.GenerateTokenPair(user.ID, session.ID)
+	if err != nil {
+		return nil, err
+	}
 
-	newSessionID := uuid.New().String()
-	tokens, err := s.jwtManager.GenerateTokenPair(user.ID, newSessionID)
+	rotated, err := s.sessionRepo.RotateRefreshToken(ctx, session.ID, tokenHash, jwt.HashToken(tokens.RefreshToken))
 	if err != nil {
 		return nil, err
 	}
+	if !rotated {
+		// Another request rotated or revoked the session in the meantime
+		return nil, s.handleRefreshReuse(ctx, claims, tokenHash, ipAddress)
+	}
 
-	newSession := &entity.LoginSession{
-		ID:               newSessionID,
-		UserID:           user.ID,
-		RefreshTokenHash: jwt.HashToken(tokens.RefreshToken),
-		DeviceInfo:       session.DeviceInfo,
-		IPAddress:        session.IPAddress,
+	return tokens, nil
+}
+
+// handleRefreshReuse decides what a validly signed refresh token that is not
+// the session's current one means. The previous token shortly after a
+// rotation is a concurrent refresh (e.g. two browser tabs) and is only
+// refused; any other old token revokes the session.
+func (s *AuthService) handleRefreshReuse(ctx context.Context, claims *jwt.Claims, tokenHash, ipAddress string) error {
+	session, err := s.sessionRepo.GetByID(ctx, claims.SessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.UserID != claims.UserID {
+		return ErrSessionNotFound
+	}
+	if !session.IsActive() {
+		return ErrSessionRevoked
+	}
+	if session.PreviousRefreshTokenHash == tokenHash && session.RefreshRotatedAt != nil &&
+		time.Since(*session.RefreshRotatedAt) <= s.refreshReuseGrace {
+		return ErrInvalidRefreshToken
 	}
 
-	if err := s.sessionRepo.Create(ctx, newSession); err != nil {
-		return nil, err
+	if err := s.sessionRepo.Revoke(ctx, session.ID); err != nil {
+		// A concurrent replay revoked it between GetByID and here
+		if errors.Is(err, repository.ErrSessionNotActive) {
+			return ErrSessionRevoked
+		}
+		return err
 	}
 
-	return tokens, nil
+	if s.auditLogger != nil {
+		username := "unknown"
+		if user, _ := s.userRepo.GetByID(ctx, session.UserID); user != nil {
+			username = user.Username
+		}
+		if err := s.auditLogger.LogUpdate(audit.UpdateParams{
+			Module: "auth",
+			Entity: audit.Entity{
+				Table:      "login_sessions",
+				PrimaryKey: map[string]string{"id": session.ID},
+			},
+			ChangedColumns: map[string]audit.ColumnChange{
+				"revoked_at": {Old: nil, New: time.Now()},
+			},
+			Where:       map[string]interface{}{"id": session.ID},
+			BusinessKey: username,
+			Actor:       audit.Actor{UserID: session.UserID, Username: username},
+			IP:          ipAddress,
+			Summary: fmt.Sprintf("Refresh token lama sesi %s milik %s dipakai ulang dari IP %s (sesi dibuat dari IP %s), sesi dicabut",
+				session.ID[:8], username, ipAddress, session.IPAddress),
+		}); err != nil {
+			log.Printf("Gagal menulis audit log pemakaian ulang refresh token: %v", err)
+		}
+	}
+
+	return ErrRefreshTokenReused
 }
 
 func (s *AuthService) GetCurrentUser(ctx context.Context, userID string) (*entity.UserWithPermissions, error) {
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/clinova/simrs/backend/internal/auth/entity"
+	"github.com/clinova/simrs/backend/pkg/jwt"
+)
+
+// newRefreshTest returns a service with one active session and its refresh token.
+func newRefreshTest(t *testing.T, grace time.Duration) (*AuthService, *fakeSessionRepo, string) {
+	t.Helper()
+	manager := jwt.NewManager("test-secret", time.Minute, time.Hour)
+	tokens, err := manager.GenerateTokenPair("u1", "session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions := newFakeSessionRepo(&entity.LoginSession{
+		ID:               "session-1",
+		UserID:           "u1",
+		RefreshTokenHash: jwt.HashToken(tokens.RefreshToken),
+		CreatedAt:        time.Now(),
+	})
+	s := &AuthService{
+		userRepo:          newFakeUserRepo(&entity.User{ID: "u1", Username: "staff1", IsActive: true}),
+		sessionRepo:       sessions,
+		jwtManager:        manager,
+		refreshReuseGrace: grace,
+	}
+	return s, sessions, tokens.RefreshToken
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	ctx := context.Background()
+	s, sessions, first := newRefreshTest(t, time.Minute)
+
+	second, err := s.RefreshToken(ctx, first, "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := sessions.sessions["session-1"]
+	if session.RefreshTokenHash != jwt.HashToken(second.RefreshToken) {
+		t.Fatal("session does not hold the new refresh token")
+	}
+	if session.PreviousRefreshTokenHash != jwt.HashToken(first) || session.RefreshRotatedAt == nil {
+		t.Fatal("rotation did not keep the previous refresh token")
+	}
+
+	third, err := s.RefreshToken(ctx, second.RefreshToken, "10.0.0.1")
+	if err != nil {
+		t.Fatalf("refresh with the rotated token: %v", err)
+	}
+	if third.RefreshToken == second.RefreshToken {
+		t.Fatal("refresh token not rotated")
+	}
+}
+
+func TestRefreshTokenGraceWindow(t *testing.T) {
+	ctx := context.Background()
+	s, sessions, first := newRefreshTest(t, time.Minute)
+	second, err := s.RefreshToken(ctx, first, "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A client retrying with the token it just rotated is refused, nothing more
+	if _, err := s.RefreshToken(ctx, first, "10.0.0.1"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("previous token within grace: got %v, want ErrInvalidRefreshToken", err)
+	}
+	if !sessions.sessions["session-1"].IsActive() {
+		t.Fatal("session revoked within the grace window")
+	}
+	if _, err := s.RefreshToken(ctx, second.RefreshToken, "10.0.0.1"); err != nil {
+		t.Fatalf("current token after a retry: %v", err)
+	}
+
+	// The grace window only covers the token replaced by the last rotation
+	if _, err := s.RefreshToken(ctx, first, "10.0.0.1"); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("older token: got %v, want ErrRefreshTokenReused", err)
+	}
+	if sessions.sessions["session-1"].IsActive() {
+		t.Fatal("session not revoked after reuse")
+	}
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	ctx := context.Background()
+	s, sessions, first := newRefreshTest(t, time.Minute)
+	second, err := s.RefreshToken(ctx, first, "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotatedAt := time.Now().Add(-2 * time.Minute)
+	sessions.sessions["session-1"].RefreshRotatedAt = &rotatedAt
+
+	if _, err := s.RefreshToken(ctx, first, "10.0.0.2"); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("previous token after grace: got %v, want ErrRefreshTokenReused", err)
+	}
+	if sessions.sessions["session-1"].IsActive() {
+		t.Fatal("session not revoked after reuse")
+	}
+
+	// The legitimate holder is logged out too
+	if _, err := s.RefreshToken(ctx, second.RefreshToken, "10.0.0.1"); !errors.Is(err, ErrSessionRevoked) {
+		t.Fatalf("current token after revocation: got %v, want ErrSessionRevoked", err)
+	}
+	if _, err := s.RefreshToken(ctx, first, "10.0.0.2"); !errors.Is(err, ErrSessionRevoked) {
+		t.Fatalf("replay after revocation: got %v, want ErrSessionRevoked", err)
+	}
+}
+
+// racingSessionRepo revokes a session right after it is read, as a
+// concurrent replay that wins the race would.
+type racingSessionRepo struct {
+	*fakeSessionRepo
+}
+
+func (r racingSessionRepo) GetByID(ctx context.Context, id string) (*entity.LoginSession, error) {
+	session, err := r.fakeSessionRepo.GetByID(ctx, id)
+	if session != nil {
+		if err := r.fakeSessionRepo.Revoke(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	return session, err
+}
+
+func TestRefreshTokenConcurrentReuse(t *testing.T) {
+	ctx := context.Background()
+	s, sessions, first := newRefreshTest(t, 0)
+	if _, err := s.RefreshToken(ctx, first, "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	s.sessionRepo = racingSessionRepo{sessions}
+	if _, err := s.RefreshToken(ctx, first, "10.0.0.2"); !errors.Is(err, ErrSessionRevoked) {
+		t.Fatalf("replay losing the revoke race: got %v, want ErrSessionRevoked", err)
+	}
+}
@@ -4,6 +4,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -11,10 +12,17 @@ import (
 
 // Config holds all application configuration values.
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	Bcrypt   BcryptConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	JWT       JWTConfig
+	Bcrypt    BcryptConfig
+	Settings  SettingsConfig
+	Storage   StorageConfig
+	MFA       MFAConfig
+	Lockout   LockoutConfig
+	Password  PasswordConfig
+	Redis     RedisConfig
+	RateLimit RateLimitConfig
 }
 
 // ServerConfig contains HTTP server settings.
@@ -37,6 +45,7 @@ type JWTConfig struct {
 	Secret             string
 	AccessTokenExpiry  time.Duration
 	RefreshTokenExpiry time.Duration
+	RefreshReuseGrace  time.Duration // Replaying the previous refresh token this soon after rotation is refused without revoking the session
 }
 
 // BcryptConfig contains password hashing settings.
@@ -44,6 +53,68 @@ type BcryptConfig struct {
 	Cost int
 }
 
+// SettingsConfig contains mera_settings access settings.
+type SettingsConfig struct {
+	Environment   string // dev, staging or prod row of mera_settings
+	EncryptionKey string // Passphrase for value_encrypted settings
+}
+
+// StorageConfig contains uploaded document storage settings.
+type StorageConfig struct {
+	Driver    string // local or s3
+	LocalRoot string // Directory holding pages/upload/... (legacy berkasrawat folder)
+
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3Prefix    string
+	S3PathStyle bool
+}
+
+// MFAConfig contains TOTP two-factor login settings.
+type MFAConfig struct {
+	Issuer              string        // Shown in authenticator apps
+	ChallengeExpiry     time.Duration // Lifetime of the MFA challenge token from /auth/login
+	RequiredRoles       []string      // Users with any of these roles must use MFA
+	RequiredPermissions []string      // Users with any of these permissions must use MFA
+}
+
+// LockoutConfig contains failed-login lockout settings.
+type LockoutConfig struct {
+	Threshold    int           // Failed logins before the account is locked
+	BaseDuration time.Duration // First lockout; doubles with each further failure
+	MaxDuration  time.Duration // Longest lockout
+	ResetAfter   time.Duration // Failure counter restarts after this long without failures
+}
+
+// PasswordConfig contains the password policy.
+type PasswordConfig struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	RejectCommon  bool          // Reject passwords on the bundled common-password list
+	HistoryCount  int           // Last passwords that may not be reused; 0 disables
+	MaxAge        time.Duration // Passwords older than this must be changed at login; 0 disables
+	ChangeExpiry  time.Duration // Lifetime of the password change token from /auth/login
+}
+
+// RedisConfig contains the Redis-compatible server shared by all instances.
+type RedisConfig struct {
+	Addr     string // host:port; empty keeps rate limits in memory
+	Password string
+	DB       int
+}
+
+// RateLimitConfig contains the per-user limit on expensive endpoints.
+type RateLimitConfig struct {
+	HeavyMax    int // Requests per window on full claim detail, PDFs and exports; 0 disables
+	HeavyWindow time.Duration
+}
+
 // Load reads configuration from environment variables.
 func Load() (*Config, error) {
 	_ = godotenv.Load()
@@ -58,11 +129,81 @@ func Load() (*Config, error) {
 		refreshExpiry = 7 * 24 * time.Hour
 	}
 
+	refreshReuseGrace, err := time.ParseDuration(getEnv("JWT_REFRESH_REUSE_GRACE", "10s"))
+	if err != nil {
+		refreshReuseGrace = 10 * time.Second
+	}
+
 	bcryptCost, err := strconv.Atoi(getEnv("BCRYPT_COST", "12"))
 	if err != nil {
 		bcryptCost = 12
 	}
 
+	mfaExpiry, err := time.ParseDuration(getEnv("MFA_CHALLENGE_EXPIRY", "5m"))
+	if err != nil {
+		mfaExpiry = 5 * time.Minute
+	}
+
+	lockoutThreshold, err := strconv.Atoi(getEnv("LOGIN_LOCKOUT_THRESHOLD", "5"))
+	if err != nil {
+		lockoutThreshold = 5
+	}
+
+	lockoutDuration, err := time.ParseDuration(getEnv("LOGIN_LOCKOUT_DURATION", "5m"))
+	if err != nil {
+		lockoutDuration = 5 * time.Minute
+	}
+
+	lockoutMax, err := time.ParseDuration(getEnv("LOGIN_LOCKOUT_MAX_DURATION", "24h"))
+	if err != nil {
+		lockoutMax = 24 * time.Hour
+	}
+
+	lockoutReset, err := time.ParseDuration(getEnv("LOGIN_LOCKOUT_RESET_AFTER", "24h"))
+	if err != nil {
+		lockoutReset = 24 * time.Hour
+	}
+
+	passwordMinLength, err := strconv.Atoi(getEnv("PASSWORD_MIN_LENGTH", "8"))
+	if err != nil {
+		passwordMinLength = 8
+	}
+
+	passwordHistory, err := strconv.Atoi(getEnv("PASSWORD_HISTORY", "5"))
+	if err != nil {
+		passwordHistory = 5
+	}
+
+	passwordMaxAge, err := time.ParseDuration(getEnv("PASSWORD_MAX_AGE", "0"))
+	if err != nil {
+		passwordMaxAge = 0
+	}
+
+	passwordChangeExpiry, err := time.ParseDuration(getEnv("PASSWORD_CHANGE_EXPIRY", "10m"))
+	if err != nil {
+		passwordChangeExpiry = 10 * time.Minute
+	}
+
+	redisDB, err := strconv.Atoi(getEnv("REDIS_DB", "0"))
+	if err != nil {
+		redisDB = 0
+	}
+
+	heavyMax, err := strconv.Atoi(getEnv("RATE_LIMIT_HEAVY_MAX", "30"))
+	if err != nil {
+		heavyMax = 30
+	}
+
+	heavyWindow, err := time.ParseDuration(getEnv("RATE_LIMIT_HEAVY_WINDOW", "1m"))
+	if err != nil {
+		heavyWindow = time.Minute
+	}
+
+	s3PathStyle, err := strconv.ParseBool(getEnv("DOCUMENT_S3_PATH_STYLE", "true"))
+	if err != nil {
+		s3PathStyle = true
+	}
+
 	return &Config{
 		Server: ServerConfig{
 			Port: getEnv("SERVER_PORT", "8080"),
@@ -79,10 +220,58 @@ func Load() (*Config, error) {
 			Secret:             getEnv("JWT_SECRET", "change-this-in-production"),
 			AccessTokenExpiry:  accessExpiry,
 			RefreshTokenExpiry: refreshExpiry,
+			RefreshReuseGrace:  refreshReuseGrace,
 		},
 		Bcrypt: BcryptConfig{
 			Cost: bcryptCost,
 		},
+		Settings: SettingsConfig{
+			Environment:   getEnv("SETTINGS_ENV", "prod"),
+			EncryptionKey: getEnv("SETTINGS_ENCRYPTION_KEY", ""),
+		},
+		Storage: StorageConfig{
+			Driver:      getEnv("DOCUMENT_STORAGE", "local"),
+			LocalRoot:   getEnv("DOCUMENT_LOCAL_ROOT", "../../webapps/berkasrawat"),
+			S3Endpoint:  getEnv("DOCUMENT_S3_ENDPOINT", ""),
+			S3Region:    getEnv("DOCUMENT_S3_REGION", "us-east-1"),
+			S3Bucket:    getEnv("DOCUMENT_S3_BUCKET", ""),
+			S3AccessKey: getEnv("DOCUMENT_S3_ACCESS_KEY", ""),
+			S3SecretKey: getEnv("DOCUMENT_S3_SECRET_KEY", ""),
+			S3Prefix:    getEnv("DOCUMENT_S3_PREFIX", ""),
+			S3PathStyle: s3PathStyle,
+		},
+		MFA: MFAConfig{
+			Issuer:              getEnv("MFA_ISSUER", "SIMRS"),
+			ChallengeExpiry:     mfaExpiry,
+			RequiredRoles:       getEnvList("MFA_REQUIRED_ROLES"),
+			RequiredPermissions: getEnvList("MFA_REQUIRED_PERMISSIONS"),
+		},
+		Lockout: LockoutConfig{
+			Threshold:    lockoutThreshold,
+			BaseDuration: lockoutDuration,
+			MaxDuration:  lockoutMax,
+			ResetAfter:   lockoutReset,
+		},
+		Password: PasswordConfig{
+			MinLength:     passwordMinLength,
+			RequireUpper:  getEnvBool("PASSWORD_REQUIRE_UPPER", true),
+			RequireLower:  getEnvBool("PASSWORD_REQUIRE_LOWER", true),
+			RequireDigit:  getEnvBool("PASSWORD_REQUIRE_DIGIT", true),
+			RequireSymbol: getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+			RejectCommon:  getEnvBool("PASSWORD_REJECT_COMMON", true),
+			HistoryCount:  passwordHistory,
+			MaxAge:        passwordMaxAge,
+			ChangeExpiry:  passwordChangeExpiry,
+		},
+		Redis: RedisConfig{
+			Addr:     getEnv("REDIS_ADDR", ""),
+			Password: getEnv("REDIS_PASSWORD", ""),
+			DB:       redisDB,
+		},
+		RateLimit: RateLimitConfig{
+			HeavyMax:    heavyMax,
+			HeavyWindow: heavyWindow,
+		},
 	}, nil
 }
 
@@ -97,3 +286,23 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvBool reads a boolean, falling back to defaultValue when unset or invalid.
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(getEnv(key, strconv.FormatBool(defaultValue)))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvList reads a comma-separated list, skipping empty items.
+func getEnvList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(getEnv(key, ""), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
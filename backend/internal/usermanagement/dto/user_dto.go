@@ -7,8 +7,10 @@ import "time"
 type CreateUserRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=50"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=8"`
+	Password string `json:"password" binding:"required"` // Checked against the password policy
 	IsActive bool   `json:"is_active"`
+	// MustChangePassword makes the user change the password at first login
+	MustChangePassword bool `json:"must_change_password"`
 }
 
 // UpdateUserRequest represents a request to update a user.
@@ -20,7 +22,7 @@ type UpdateUserRequest struct {
 
 // ResetPasswordRequest represents a request to reset password.
 type ResetPasswordRequest struct {
-	NewPassword string `json:"new_password" binding:"required,min=8"`
+	NewPassword string `json:"new_password" binding:"required"` // Checked against the password policy
 }
 
 // AssignRolesRequest represents a request to assign roles to user.
@@ -46,14 +48,18 @@ type CopyAccessRequest struct {
 
 // UserResponse represents a user in response.
 type UserResponse struct {
-	ID          string      `json:"id"`
-	Username    string      `json:"username"`
-	Email       string      `json:"email"`
-	IsActive    bool        `json:"is_active"`
-	LastLoginAt *time.Time  `json:"last_login_at,omitempty"`
-	CreatedAt   time.Time   `json:"created_at"`
-	UpdatedAt   time.Time   `json:"updated_at"`
-	Roles       []RoleBrief `json:"roles,omitempty"`
+	ID                 string      `json:"id"`
+	Username           string      `json:"username"`
+	Email              string      `json:"email"`
+	IsActive           bool        `json:"is_active"`
+	LastLoginAt        *time.Time  `json:"last_login_at,omitempty"`
+	FailedLoginCount   int         `json:"failed_login_count"`
+	LockedUntil        *time.Time  `json:"locked_until,omitempty"`
+	PasswordChangedAt  *time.Time  `json:"password_changed_at,omitempty"`
+	MustChangePassword bool        `json:"must_change_password"`
+	CreatedAt          time.Time   `json:"created_at"`
+	UpdatedAt          time.Time   `json:"updated_at"`
+	Roles              []RoleBrief `json:"roles,omitempty"`
 }
 
 // RoleBrief is a brief role info.
@@ -11,7 +11,6 @@ import (
 	"github.com/clinova/simrs/backend/internal/usermanagement/repository"
 	"github.com/clinova/simrs/backend/internal/usermanagement/service"
 	"github.com/clinova/simrs/backend/pkg/audit"
-	"github.com/clinova/simrs/backend/pkg/password"
 )
 
 // Router handles routing for user management domain.
@@ -27,7 +26,7 @@ type Router struct {
 func NewRouter(
 	db *sql.DB,
 	auditLogger *audit.Logger,
-	passwordHasher *password.Hasher,
+	passwordService *authService.PasswordService,
 	jwtMiddleware *middleware.JWTMiddleware,
 	permMiddleware *middleware.PermissionMiddleware,
 ) *Router {
@@ -37,7 +36,7 @@ func NewRouter(
 	permRepo := repository.NewMySQLPermissionRepository(db)
 
 	// Initialize services
-	userService := service.NewUserService(userRepo, roleRepo, passwordHasher, auditLogger)
+	userService := service.NewUserService(userRepo, roleRepo, passwordService, auditLogger)
 	roleService := service.NewRoleService(roleRepo, auditLogger)
 	permService := service.NewPermissionService(permRepo, auditLogger)
 
@@ -71,6 +70,7 @@ func (r *Router) RegisterRoutes(engine *gin.Engine, permissionService *authServi
 		usersWrite.PUT("/:id", r.userHandler.UpdateUser)
 		usersWrite.POST("/:id/activate", r.userHandler.ActivateUser)
 		usersWrite.POST("/:id/deactivate", r.userHandler.DeactivateUser)
+		usersWrite.POST("/:id/unlock", r.userHandler.UnlockUser)
 		usersWrite.POST("/:id/reset-password", r.userHandler.ResetPassword)
 		usersWrite.PUT("/:id/roles", r.userHandler.AssignRoles)
 		usersWrite.PUT("/:id/permissions", r.userHandler.AssignPermissions)
@@ -2,6 +2,8 @@
 package handler
 
 import (
+	"errors"
+	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -11,6 +13,7 @@ import (
 	"github.com/clinova/simrs/backend/internal/usermanagement/repository"
 	"github.com/clinova/simrs/backend/internal/usermanagement/service"
 	"github.com/clinova/simrs/backend/pkg/audit"
+	"github.com/clinova/simrs/backend/pkg/password"
 	"github.com/clinova/simrs/backend/pkg/response"
 )
 
@@ -40,10 +43,13 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 
 	actor := h.getActor(c)
 	user, err := h.userService.CreateUser(c.Request.Context(), actor, c.ClientIP(),
-		req.Username, req.Email, req.Password, req.IsActive)
+		req.Username, req.Email, req.Password, req.IsActive, req.MustChangePassword)
 	if err != nil {
+		var policyErr *password.PolicyError
 		if err == service.ErrUserExists {
 			response.BadRequest(c, "USER_EXISTS", "Username atau email sudah digunakan")
+		} else if errors.As(err, &policyErr) {
+			passwordPolicyError(c, policyErr)
 		} else {
 			response.InternalServerError(c, "Gagal membuat pengguna")
 		}
@@ -51,12 +57,14 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 	}
 
 	resp := dto.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		IsActive:  user.IsActive,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:                 user.ID,
+		Username:           user.Username,
+		Email:              user.Email,
+		IsActive:           user.IsActive,
+		PasswordChangedAt:  user.PasswordChangedAt,
+		MustChangePassword: user.MustChangePassword,
+		CreatedAt:          user.CreatedAt,
+		UpdatedAt:          user.UpdatedAt,
 	}
 	response.Success(c, resp)
 }
@@ -82,13 +90,17 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 	userResponses := make([]dto.UserResponse, len(users))
 	for i, u := range users {
 		userResponses[i] = dto.UserResponse{
-			ID:          u.ID,
-			Username:    u.Username,
-			Email:       u.Email,
-			IsActive:    u.IsActive,
-			LastLoginAt: u.LastLoginAt,
-			CreatedAt:   u.CreatedAt,
-			UpdatedAt:   u.UpdatedAt,
+			ID:                 u.ID,
+			Username:           u.Username,
+			Email:              u.Email,
+			IsActive:           u.IsActive,
+			LastLoginAt:        u.LastLoginAt,
+			FailedLoginCount:   u.FailedLoginCount,
+			LockedUntil:        u.LockedUntil,
+			PasswordChangedAt:  u.PasswordChangedAt,
+			MustChangePassword: u.MustChangePassword,
+			CreatedAt:          u.CreatedAt,
+			UpdatedAt:          u.UpdatedAt,
 		}
 	}
 
@@ -131,14 +143,18 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 
 	resp := dto.UserDetailResponse{
 		UserResponse: dto.UserResponse{
-			ID:          user.ID,
-			Username:    user.Username,
-			Email:       user.Email,
-			IsActive:    user.IsActive,
-			LastLoginAt: user.LastLoginAt,
-			CreatedAt:   user.CreatedAt,
-			UpdatedAt:   user.UpdatedAt,
-			Roles:       roleBriefs,
+			ID:                 user.ID,
+			Username:           user.Username,
+			Email:              user.Email,
+			IsActive:           user.IsActive,
+			LastLoginAt:        user.LastLoginAt,
+			FailedLoginCount:   user.FailedLoginCount,
+			LockedUntil:        user.LockedUntil,
+			PasswordChangedAt:  user.PasswordChangedAt,
+			MustChangePassword: user.MustChangePassword,
+			CreatedAt:          user.CreatedAt,
+			UpdatedAt:          user.UpdatedAt,
+			Roles:              roleBriefs,
 		},
 		PermissionOverrides: overrideResponses,
 	}
@@ -213,6 +229,23 @@ func (h *UserHandler) DeactivateUser(c *gin.Context) {
 	response.SuccessWithMessage(c, "Pengguna berhasil dinonaktifkan", nil)
 }
 
+// UnlockUser handles POST /admin/users/:id/unlock
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	userID := c.Param("id")
+	actor := h.getActor(c)
+
+	if err := h.userService.UnlockUser(c.Request.Context(), actor, c.ClientIP(), userID); err != nil {
+		if err == service.ErrUserNotFound {
+			response.NotFound(c, "Pengguna tidak ditemukan")
+		} else {
+			response.InternalServerError(c, "Gagal membuka kunci pengguna")
+		}
+		return
+	}
+
+	response.SuccessWithMessage(c, "Kunci pengguna berhasil dibuka", nil)
+}
+
 // ResetPassword handles POST /admin/users/:id/reset-password
 func (h *UserHandler) ResetPassword(c *gin.Context) {
 	userID := c.Param("id")
@@ -225,8 +258,11 @@ func (h *UserHandler) ResetPassword(c *gin.Context) {
 
 	actor := h.getActor(c)
 	if err := h.userService.ResetPassword(c.Request.Context(), actor, c.ClientIP(), userID, req.NewPassword); err != nil {
+		var policyErr *password.PolicyError
 		if err == service.ErrUserNotFound {
 			response.NotFound(c, "Pengguna tidak ditemukan")
+		} else if errors.As(err, &policyErr) {
+			passwordPolicyError(c, policyErr)
 		} else {
 			response.InternalServerError(c, "Gagal mereset password")
 		}
@@ -331,3 +367,11 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 
 	response.SuccessWithMessage(c, "Pengguna berhasil dihapus", nil)
 }
+
+// passwordPolicyError writes a 400 listing the password rules err breaks.
+func passwordPolicyError(c *gin.Context, err *password.PolicyError) {
+	response.ErrorWithDetails(c, http.StatusBadRequest, response.ErrCodePasswordPolicy, "Password tidak memenuhi kebijakan password", gin.H{
+		"violations": err.Violations,
+		"messages":   err.Messages(),
+	})
+}
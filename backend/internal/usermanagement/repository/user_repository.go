@@ -18,6 +18,7 @@ type UserRepository interface {
 	GetAll(ctx context.Context, limit, offset int) ([]entity.User, int, error)
 	Update(ctx context.Context, user *entity.User) error
 	SoftDelete(ctx context.Context, id string) error
+	Unlock(ctx context.Context, id string) error
 
 	// Role assignment
 	GetRolesByUserID(ctx context.Context, userID string) ([]entity.Role, error)
@@ -51,23 +52,23 @@ func NewMySQLUserRepository(db *sql.DB) *MySQLUserRepository {
 }
 
 func (r *MySQLUserRepository) Create(ctx context.Context, user *entity.User) error {
-	query := `INSERT INTO mera_users (id, username, email, password_hash, is_active, created_at, updated_at)
-			  VALUES (?, ?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO mera_users (id, username, email, password_hash, password_changed_at, must_change_password, is_active, created_at, updated_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
 	_, err := r.db.ExecContext(ctx, query,
-		user.ID, user.Username, user.Email, user.PasswordHash,
+		user.ID, user.Username, user.Email, user.PasswordHash, user.PasswordChangedAt, user.MustChangePassword,
 		user.IsActive, user.CreatedAt, user.UpdatedAt)
 	return err
 }
 
 func (r *MySQLUserRepository) GetByID(ctx context.Context, id string) (*entity.User, error) {
-	query := `SELECT id, username, email, password_hash, is_active, last_login_at, created_at, updated_at
+	query := `SELECT id, username, email, password_hash, password_changed_at, must_change_password, is_active, last_login_at, failed_login_count, locked_until, created_at, updated_at
 			  FROM mera_users WHERE id = ? AND deleted_at IS NULL`
 	row := r.db.QueryRowContext(ctx, query, id)
 	return scanUser(row)
 }
 
 func (r *MySQLUserRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
-	query := `SELECT id, username, email, password_hash, is_active, last_login_at, created_at, updated_at
+	query := `SELECT id, username, email, password_hash, password_changed_at, must_change_password, is_active, last_login_at, failed_login_count, locked_until, created_at, updated_at
 			  FROM mera_users WHERE username = ? AND deleted_at IS NULL`
 	row := r.db.QueryRowContext(ctx, query, username)
 	return scanUser(row)
@@ -82,7 +83,7 @@ func (r *MySQLUserRepository) GetAll(ctx context.Context, limit, offset int) ([]
 	}
 
 	// Get paginated users
-	query := `SELECT id, username, email, password_hash, is_active, last_login_at, created_at, updated_at
+	query := `SELECT id, username, email, password_hash, password_changed_at, must_change_password, is_active, last_login_at, failed_login_count, locked_until, created_at, updated_at
 			  FROM mera_users WHERE deleted_at IS NULL ORDER BY created_at DESC LIMIT ? OFFSET ?`
 	rows, err := r.db.QueryContext(ctx, query, limit, offset)
 	if err != nil {
@@ -93,14 +94,20 @@ func (r *MySQLUserRepository) GetAll(ctx context.Context, limit, offset int) ([]
 	var users []entity.User
 	for rows.Next() {
 		var u entity.User
-		var lastLogin sql.NullTime
-		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash,
-			&u.IsActive, &lastLogin, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		var passwordChangedAt, lastLogin, lockedUntil sql.NullTime
+		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &passwordChangedAt, &u.MustChangePassword,
+			&u.IsActive, &lastLogin, &u.FailedLoginCount, &lockedUntil, &u.CreatedAt, &u.UpdatedAt); err != nil {
 			return nil, 0, err
 		}
+		if passwordChangedAt.Valid {
+			u.PasswordChangedAt = &passwordChangedAt.Time
+		}
 		if lastLogin.Valid {
 			u.LastLoginAt = &lastLogin.Time
 		}
+		if lockedUntil.Valid {
+			u.LockedUntil = &lockedUntil.Time
+		}
 		users = append(users, u)
 	}
 	return users, total, nil
@@ -120,6 +127,14 @@ func (r *MySQLUserRepository) SoftDelete(ctx context.Context, id string) error {
 	return err
 }
 
+// Unlock clears the failed-login counter and lockout of a user.
+func (r *MySQLUserRepository) Unlock(ctx context.Context, id string) error {
+	query := `UPDATE mera_users SET failed_login_count = 0, last_failed_login_at = NULL, locked_until = NULL
+			  WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
 func (r *MySQLUserRepository) GetRolesByUserID(ctx context.Context, userID string) ([]entity.Role, error) {
 	query := `SELECT r.id, r.name, r.description FROM mera_roles r
 			  INNER JOIN mera_user_roles ur ON r.id = ur.role_id
@@ -247,17 +262,23 @@ func (r *MySQLUserRepository) CopyAccess(ctx context.Context, sourceUserID, targ
 
 func scanUser(row *sql.Row) (*entity.User, error) {
 	var u entity.User
-	var lastLogin sql.NullTime
-	err := row.Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash,
-		&u.IsActive, &lastLogin, &u.CreatedAt, &u.UpdatedAt)
+	var passwordChangedAt, lastLogin, lockedUntil sql.NullTime
+	err := row.Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &passwordChangedAt, &u.MustChangePassword,
+		&u.IsActive, &lastLogin, &u.FailedLoginCount, &lockedUntil, &u.CreatedAt, &u.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	if passwordChangedAt.Valid {
+		u.PasswordChangedAt = &passwordChangedAt.Time
+	}
 	if lastLogin.Valid {
 		u.LastLoginAt = &lastLogin.Time
 	}
+	if lockedUntil.Valid {
+		u.LockedUntil = &lockedUntil.Time
+	}
 	return &u, nil
 }
@@ -12,9 +12,9 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/clinova/simrs/backend/internal/auth/entity"
+	authService "github.com/clinova/simrs/backend/internal/auth/service"
 	"github.com/clinova/simrs/backend/internal/usermanagement/repository"
 	"github.com/clinova/simrs/backend/pkg/audit"
-	"github.com/clinova/simrs/backend/pkg/password"
 )
 
 var (
@@ -25,30 +25,30 @@ var (
 
 // UserService handles user management business logic.
 type UserService struct {
-	userRepo       repository.UserRepository
-	roleRepo       repository.RoleRepository
-	passwordHasher *password.Hasher
-	auditLogger    *audit.Logger
+	userRepo    repository.UserRepository
+	roleRepo    repository.RoleRepository
+	passwords   *authService.PasswordService
+	auditLogger *audit.Logger
 }
 
 // NewUserService creates a new user service.
 func NewUserService(
 	userRepo repository.UserRepository,
 	roleRepo repository.RoleRepository,
-	passwordHasher *password.Hasher,
+	passwords *authService.PasswordService,
 	auditLogger *audit.Logger,
 ) *UserService {
 	return &UserService{
-		userRepo:       userRepo,
-		roleRepo:       roleRepo,
-		passwordHasher: passwordHasher,
-		auditLogger:    auditLogger,
+		userRepo:    userRepo,
+		roleRepo:    roleRepo,
+		passwords:   passwords,
+		auditLogger: auditLogger,
 	}
 }
 
 // CreateUser creates a new user.
 func (s *UserService) CreateUser(ctx context.Context, actor audit.Actor, ip string,
-	username, email, plainPassword string, isActive bool) (*entity.User, error) {
+	username, email, plainPassword string, isActive, mustChangePassword bool) (*entity.User, error) {
 
 	// Check if user exists
 	existing, _ := s.userRepo.GetByUsername(ctx, username)
@@ -56,25 +56,31 @@ func (s *UserService) CreateUser(ctx context.Context, actor audit.Actor, ip stri
 		return nil, ErrUserExists
 	}
 
-	// Hash password
-	hashedPassword, err := s.passwordHasher.Hash(plainPassword)
+	// Check the password policy and hash
+	hashedPassword, err := s.passwords.HashNew(ctx, username, plainPassword)
 	if err != nil {
 		return nil, err
 	}
 
+	now := time.Now()
 	user := &entity.User{
-		ID:           uuid.New().String(),
-		Username:     username,
-		Email:        email,
-		PasswordHash: hashedPassword,
-		IsActive:     isActive,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		ID:                 uuid.New().String(),
+		Username:           username,
+		Email:              email,
+		PasswordHash:       hashedPassword,
+		PasswordChangedAt:  &now,
+		MustChangePassword: mustChangePassword,
+		IsActive:           isActive,
+		CreatedAt:          now,
+		UpdatedAt:          now,
 	}
 
 	if err := s.userRepo.Create(ctx, user); err != nil {
 		return nil, err
 	}
+	if err := s.passwords.RecordHistory(ctx, user.ID, hashedPassword); err != nil {
+		log.Printf("Gagal mencatat riwayat password %s: %v", user.Username, err)
+	}
 
 	// Audit log
 	if err := s.auditLogger.LogInsert(audit.InsertParams{
@@ -84,10 +90,11 @@ func (s *UserService) CreateUser(ctx context.Context, actor audit.Actor, ip stri
 			PrimaryKey: map[string]string{"id": user.ID},
 		},
 		InsertedData: map[string]interface{}{
-			"id":        user.ID,
-			"username":  user.Username,
-			"email":     user.Email,
-			"is_active": user.IsActive,
+			"id":                   user.ID,
+			"username":             user.Username,
+			"email":                user.Email,
+			"is_active":            user.IsActive,
+			"must_change_password": user.MustChangePassword,
 		},
 		BusinessKey: user.Username,
 		Actor:       actor,
@@ -224,8 +231,8 @@ func (s *UserService) ToggleUserActive(ctx context.Context, actor audit.Actor, i
 	return nil
 }
 
-// ResetPassword resets a user's password.
-func (s *UserService) ResetPassword(ctx context.Context, actor audit.Actor, ip string, userID, newPassword string) error {
+// UnlockUser clears the failed-login lockout of a user.
+func (s *UserService) UnlockUser(ctx context.Context, actor audit.Actor, ip string, userID string) error {
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return err
@@ -234,13 +241,45 @@ func (s *UserService) ResetPassword(ctx context.Context, actor audit.Actor, ip s
 		return ErrUserNotFound
 	}
 
-	hashedPassword, err := s.passwordHasher.Hash(newPassword)
+	if err := s.userRepo.Unlock(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.auditLogger.LogUpdate(audit.UpdateParams{
+		Module: "usermanagement",
+		Entity: audit.Entity{
+			Table:      "users",
+			PrimaryKey: map[string]string{"id": userID},
+		},
+		ChangedColumns: map[string]audit.ColumnChange{
+			"failed_login_count": {Old: user.FailedLoginCount, New: 0},
+			"locked_until":       {Old: user.LockedUntil, New: nil},
+		},
+		Where:       map[string]interface{}{"id": userID},
+		BusinessKey: user.Username,
+		Actor:       actor,
+		IP:          ip,
+		Summary:     fmt.Sprintf("Kunci akun pengguna %s dibuka oleh %s", user.Username, actor.Username),
+	}); err != nil {
+		log.Printf("Gagal menulis audit log: %v", err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets a user's password under the password policy. The user
+// must change it at the next login.
+func (s *UserService) ResetPassword(ctx context.Context, actor audit.Actor, ip string, userID, newPassword string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return err
 	}
+	if user == nil {
+		return ErrUserNotFound
+	}
 
-	user.PasswordHash = hashedPassword
-	if err := s.userRepo.Update(ctx, user); err != nil {
+	// The user must choose their own password at the next login
+	if err := s.passwords.SetPassword(ctx, user, newPassword, true); err != nil {
 		return err
 	}
 
@@ -251,7 +290,8 @@ func (s *UserService) ResetPassword(ctx context.Context, actor audit.Actor, ip s
 			PrimaryKey: map[string]string{"id": userID},
 		},
 		ChangedColumns: map[string]audit.ColumnChange{
-			"password_hash": {Old: "[REDACTED]", New: "[REDACTED]"},
+			"password_hash":        {Old: "[REDACTED]", New: "[REDACTED]"},
+			"must_change_password": {Old: user.MustChangePassword, New: true},
 		},
 		Where:       map[string]interface{}{"id": userID},
 		BusinessKey: user.Username,
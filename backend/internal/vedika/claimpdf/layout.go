@@ -0,0 +1,295 @@
+package claimpdf
+
+import (
+	"math"
+	"strings"
+
+	"github.com/clinova/simrs/backend/pkg/pdf"
+)
+
+// Page geometry in points (A4 portrait).
+const (
+	marginX      = 36.0
+	marginTop    = 42.0
+	marginBottom = 48.0
+	contentWidth = pdf.A4Width - 2*marginX
+
+	fontSize   = 8.5
+	lineHeight = fontSize * 1.3
+	cellPad    = 3.0
+)
+
+// layout flows blocks of text and tables down A4 pages.
+type layout struct {
+	doc  *pdf.Document
+	page *pdf.Page
+	y    float64
+}
+
+func newLayout(doc *pdf.Document) *layout {
+	return &layout{doc: doc}
+}
+
+func (l *layout) newPage() {
+	l.page = l.doc.AddPage()
+	l.y = marginTop
+}
+
+func (l *layout) bottom() float64 {
+	return pdf.A4Height - marginBottom
+}
+
+// ensure starts a new page unless h points fit below the cursor.
+func (l *layout) ensure(h float64) {
+	if l.page == nil || l.y+h > l.bottom() {
+		l.newPage()
+	}
+}
+
+func (l *layout) space(h float64) {
+	l.y += h
+}
+
+// title draws a centered document title, e.g. SURAT ELIGIBILITAS PESERTA.
+func (l *layout) title(text, subtitle string) {
+	l.ensure(40)
+	w := pdf.TextWidth(pdf.HelveticaBold, 12, text)
+	l.page.Text(marginX+(contentWidth-w)/2, l.y+12, pdf.HelveticaBold, 12, text)
+	l.y += 18
+	if subtitle != "" {
+		w = pdf.TextWidth(pdf.Helvetica, 9, subtitle)
+		l.page.Text(marginX+(contentWidth-w)/2, l.y+9, pdf.Helvetica, 9, subtitle)
+		l.y += 13
+	}
+	l.page.Line(marginX, l.y+2, marginX+contentWidth, l.y+2, 1)
+	l.y += 10
+}
+
+// section draws a numbered section band.
+func (l *layout) section(text string) {
+	l.ensure(60)
+	if l.y > marginTop {
+		l.y += 6
+	}
+	l.page.Rect(marginX, l.y, contentWidth, 16, 0.85, 0)
+	l.page.Text(marginX+4, l.y+11.5, pdf.HelveticaBold, 10, text)
+	l.y += 22
+}
+
+// subheading draws a bold caption above a block.
+func (l *layout) subheading(text string) {
+	l.ensure(40)
+	l.page.Text(marginX, l.y+fontSize+1, pdf.HelveticaBold, fontSize+1, text)
+	l.y += lineHeight + 4
+}
+
+// note draws a single muted line such as "Tidak ada data".
+func (l *layout) note(text string) {
+	l.ensure(lineHeight)
+	l.page.Text(marginX, l.y+fontSize, pdf.Helvetica, fontSize, text)
+	l.y += lineHeight + 2
+}
+
+// field is a label/value pair.
+type field struct {
+	label string
+	value string
+}
+
+// fields draws label : value rows in the given number of columns.
+func (l *layout) fields(items []field, columns int) {
+	if columns < 1 {
+		columns = 1
+	}
+	colWidth := contentWidth / float64(columns)
+	labelWidth := math.Min(110, colWidth*0.4)
+
+	for i := 0; i < len(items); i += columns {
+		row := items[i:min(i+columns, len(items))]
+
+		lines := make([][]string, len(row))
+		height := 0.0
+		for j, f := range row {
+			lines[j] = wrapText(pdf.Helvetica, fontSize, dash(f.value), colWidth-labelWidth-12)
+			height = math.Max(height, float64(len(lines[j]))*lineHeight)
+		}
+
+		l.ensure(math.Min(height, l.bottom()-marginTop))
+		for j, f := range row {
+			x := marginX + float64(j)*colWidth
+			l.page.Text(x, l.y+fontSize, pdf.HelveticaBold, fontSize, f.label)
+			l.page.Text(x+labelWidth, l.y+fontSize, pdf.Helvetica, fontSize, ":")
+			y := l.y
+			for _, line := range lines[j] {
+				if y+lineHeight > l.bottom() {
+					break
+				}
+				l.page.Text(x+labelWidth+8, y+fontSize, pdf.Helvetica, fontSize, line)
+				y += lineHeight
+			}
+		}
+		l.y += height + 1
+	}
+	l.y += 4
+}
+
+// paragraph draws a labelled block of free text, continuing across pages.
+func (l *layout) paragraph(label, text string) {
+	lines := wrapText(pdf.Helvetica, fontSize, dash(text), contentWidth-8)
+	l.ensure(2 * lineHeight)
+	l.page.Text(marginX, l.y+fontSize, pdf.HelveticaBold, fontSize, label)
+	l.y += lineHeight
+	for _, line := range lines {
+		l.ensure(lineHeight)
+		l.page.Text(marginX+8, l.y+fontSize, pdf.Helvetica, fontSize, line)
+		l.y += lineHeight
+	}
+	l.y += 3
+}
+
+// column describes a table column; width is a fraction of the content width.
+type column struct {
+	title string
+	width float64
+	right bool
+}
+
+// table draws a bordered table, repeating the header on each page and
+// splitting rows that do not fit.
+func (l *layout) table(cols []column, rows [][]string) {
+	widths := make([]float64, len(cols))
+	for i, c := range cols {
+		widths[i] = c.width * contentWidth
+	}
+
+	header := make([][]string, len(cols))
+	headerLines := 1
+	for i, c := range cols {
+		header[i] = wrapText(pdf.HelveticaBold, fontSize, c.title, widths[i]-2*cellPad)
+		headerLines = max(headerLines, len(header[i]))
+	}
+	headerHeight := float64(headerLines)*lineHeight + 2*cellPad
+
+	drawHeader := func() {
+		x := marginX
+		for i := range cols {
+			l.page.Rect(x, l.y, widths[i], headerHeight, 0.92, 0.5)
+			for j, line := range header[i] {
+				l.page.Text(x+cellPad, l.y+cellPad+fontSize+float64(j)*lineHeight, pdf.HelveticaBold, fontSize, line)
+			}
+			x += widths[i]
+		}
+		l.y += headerHeight
+	}
+
+	l.ensure(headerHeight + lineHeight + 2*cellPad)
+	drawHeader()
+
+	for _, row := range rows {
+		cells := make([][]string, len(cols))
+		for i := range cols {
+			value := ""
+			if i < len(row) {
+				value = row[i]
+			}
+			cells[i] = wrapText(pdf.Helvetica, fontSize, value, widths[i]-2*cellPad)
+		}
+
+		total := 1
+		for _, c := range cells {
+			total = max(total, len(c))
+		}
+
+		// Emit the row in as many page-sized chunks as needed
+		for start := 0; start < total; {
+			fit := int((l.bottom() - l.y - 2*cellPad) / lineHeight)
+			if fit < 1 {
+				l.newPage()
+				drawHeader()
+				continue
+			}
+			n := min(fit, total-start)
+			height := float64(n)*lineHeight + 2*cellPad
+
+			x := marginX
+			for i, c := range cols {
+				l.page.Rect(x, l.y, widths[i], height, -1, 0.5)
+				for j := start; j < start+n && j < len(cells[i]); j++ {
+					line := cells[i][j]
+					tx := x + cellPad
+					if c.right {
+						tx = x + widths[i] - cellPad - pdf.TextWidth(pdf.Helvetica, fontSize, line)
+					}
+					l.page.Text(tx, l.y+cellPad+fontSize+float64(j-start)*lineHeight, pdf.Helvetica, fontSize, line)
+				}
+				x += widths[i]
+			}
+			l.y += height
+			start += n
+		}
+	}
+	l.y += 6
+}
+
+// totalRow draws a right-aligned label/value line under a table.
+func (l *layout) totalRow(label, value string) {
+	l.ensure(lineHeight)
+	w := pdf.TextWidth(pdf.HelveticaBold, fontSize, value)
+	l.page.Text(marginX+contentWidth-180, l.y+fontSize, pdf.HelveticaBold, fontSize, label)
+	l.page.Text(marginX+contentWidth-cellPad-w, l.y+fontSize, pdf.HelveticaBold, fontSize, value)
+	l.y += lineHeight + 1
+}
+
+// wrapText breaks text into lines no wider than width, honoring newlines
+// and splitting words that are longer than a line.
+func wrapText(font pdf.Font, size float64, text string, width float64) []string {
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		words := strings.Fields(para)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+
+		line := ""
+		for _, word := range words {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if pdf.TextWidth(font, size, candidate) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			// Hard-break words wider than the line
+			for pdf.TextWidth(font, size, word) > width {
+				cut := len([]rune(word))
+				runes := []rune(word)
+				for cut > 1 && pdf.TextWidth(font, size, string(runes[:cut])) > width {
+					cut--
+				}
+				lines = append(lines, string(runes[:cut]))
+				word = string(runes[cut:])
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+
+	// Drop trailing blank lines
+	for len(lines) > 1 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func dash(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,682 @@
+// Package claimpdf renders the complete claim bundle of an episode
+// (SEP, medical record, billing, SPRI and uploaded documents) into a single
+// PDF, replacing the legacy mLITE getPDF() print view.
+package claimpdf
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/pkg/pdf"
+)
+
+// Options configures rendering.
+type Options struct {
+	// Institution is printed under document titles.
+	Institution string
+	// LoadFile reads a file by its path relative to the legacy webapps
+	// root, e.g. "berkasrawat/pages/upload/x.pdf". Attachments are
+	// skipped when nil.
+	LoadFile func(path string) ([]byte, error)
+	// PrintedAt is printed in the page footer.
+	PrintedAt time.Time
+}
+
+// Render builds the claim PDF for detail.
+func Render(detail *entity.ClaimFullDetail, opts Options) ([]byte, error) {
+	doc := pdf.New()
+	doc.SetTitle("Data Klaim " + detail.Patient.NoRawat)
+
+	r := &renderer{detail: detail, opts: opts, l: newLayout(doc)}
+	r.sep()
+	r.patient()
+	r.actions()
+	r.operations()
+	r.radiology()
+	r.laboratory()
+	r.pathology()
+	r.medicines()
+	r.resume()
+	r.billing()
+	r.spri()
+	r.documents()
+	r.footers()
+
+	return doc.Bytes()
+}
+
+type renderer struct {
+	detail *entity.ClaimFullDetail
+	opts   Options
+	l      *layout
+}
+
+// Section 1: SEP
+func (r *renderer) sep() {
+	l := r.l
+	l.newPage()
+	l.title("SURAT ELIGIBILITAS PESERTA", r.opts.Institution)
+
+	sep := r.detail.SEP
+	if sep == nil {
+		l.note("Episode ini tidak memiliki SEP.")
+		return
+	}
+	if sep.PRBStatus != "" {
+		l.note("PRB: " + sep.PRBStatus)
+	}
+
+	cob := "0. Tidak"
+	if sep.COB != "" && sep.COB != "0" && !strings.EqualFold(sep.COB, "0. Tidak") {
+		cob = "1. Ya"
+	}
+	l.fields([]field{
+		{"No. SEP", sep.NoSEP}, {"Peserta", sep.Peserta},
+		{"Tgl. SEP", sep.TglSEP}, {"COB", cob},
+		{"No. Kartu", fmt.Sprintf("%s (MR: %s)", sep.NoKartu, sep.NoRM)}, {"Jns. Rawat", sep.JenisPelayanan},
+		{"Nama Peserta", fmt.Sprintf("%s (%s)", sep.NamaPeserta, sep.JenisKelamin)}, {"Kls. Rawat", sep.KelasRawat},
+		{"Tgl. Lahir", sep.TglLahir}, {"Kls. Hak", sep.KelasHak},
+		{"No. Telepon", sep.NoTelp}, {"Masa Berlaku", sep.MasaBerlaku},
+		{"Sub/Spesialis", sep.PoliTujuan}, {"Tgl. Rujukan", sep.TglRujukan},
+		{"DPJP Yg Melayani", sep.DPJP}, {"Faskes Perujuk", sep.FaskesPerujuk},
+	}, 2)
+	l.fields([]field{
+		{"Diagnosa Awal", sep.DiagnosaAwal},
+		{"Catatan", sep.Catatan},
+	}, 1)
+	if sep.Live {
+		l.note("Data SEP diambil langsung dari VClaim BPJS.")
+	}
+
+	r.signatures("Pasien/Keluarga Pasien", sep.NamaPeserta, "Petugas BPJS Kesehatan", "")
+}
+
+// Section 2: patient, registration, diagnoses, procedures and SOAP
+func (r *renderer) patient() {
+	l := r.l
+	p := r.detail.Patient
+	l.newPage()
+	l.title("DATA KLAIM PASIEN", r.opts.Institution)
+
+	l.section("DATA PASIEN")
+	l.fields([]field{
+		{"No. RM", p.NoRM}, {"No. Rawat", p.NoRawat},
+		{"Nama Pasien", p.NamaPasien}, {"No. Registrasi", p.NoReg},
+		{"Alamat", p.Alamat}, {"Tgl. Registrasi", strings.TrimSpace(p.TglRegistrasi + " " + p.JamReg)},
+		{"Umur", p.Umur}, {"Unit/Poliklinik", p.Unit},
+		{"Jenis Kelamin", p.JenisKelamin}, {"Dokter", r.doctors()},
+		{"Tempat & Tgl. Lahir", joinNonEmpty(", ", p.TempatLahir, p.TglLahir)}, {"Cara Bayar", p.CaraBayar},
+		{"Ibu Kandung", p.IbuKandung}, {"Penanggung Jawab", p.PenanggungJawab},
+		{"Golongan Darah", p.GolDarah}, {"Alamat P.J.", p.AlamatPJ},
+		{"Status Nikah", p.StatusNikah}, {"Hubungan P.J.", p.HubunganPJ},
+		{"Agama", p.Agama}, {"Status", r.detail.StatusLanjut},
+		{"Pendidikan", p.Pendidikan}, {"Pertama Daftar", p.TglPertamaDaftar},
+	}, 2)
+
+	l.section("DIAGNOSA / ICD-10")
+	if len(r.detail.Diagnoses) == 0 {
+		l.note("Belum ada diagnosa.")
+	} else {
+		rows := make([][]string, 0, len(r.detail.Diagnoses))
+		for _, d := range r.detail.Diagnoses {
+			rows = append(rows, []string{strconv.Itoa(d.Prioritas), d.KodePenyakit, d.NamaPenyakit, d.StatusDx})
+		}
+		l.table([]column{{"No", 0.06, false}, {"Kode", 0.12, false}, {"Nama Penyakit", 0.64, false}, {"Status", 0.18, false}}, rows)
+	}
+
+	l.section("PROSEDUR / ICD-9-CM")
+	if len(r.detail.Procedures) == 0 {
+		l.note("Belum ada prosedur.")
+	} else {
+		rows := make([][]string, 0, len(r.detail.Procedures))
+		for _, p := range r.detail.Procedures {
+			rows = append(rows, []string{strconv.Itoa(p.Prioritas), p.Kode, p.Nama})
+		}
+		l.table([]column{{"No", 0.06, false}, {"Kode", 0.12, false}, {"Nama Tindakan", 0.82, false}}, rows)
+	}
+
+	r.soap("PEMERIKSAAN RAWAT JALAN (SOAP)", r.detail.SOAPExamsRalan)
+	r.soap("PEMERIKSAAN RAWAT INAP (SOAP)", r.detail.SOAPExamsRanap)
+}
+
+func (r *renderer) doctors() string {
+	p := r.detail.Patient
+	if len(p.DPJPList) > 0 {
+		return strings.Join(p.DPJPList, ", ")
+	}
+	return p.Dokter
+}
+
+func (r *renderer) soap(title string, exams []entity.SOAPExamination) {
+	if len(exams) == 0 {
+		return
+	}
+	r.l.section(title)
+
+	rows := make([][]string, 0, len(exams))
+	for _, e := range exams {
+		vitals := []string{
+			"Suhu: " + dash(e.SuhuTubuh), "Tensi: " + dash(e.Tensi), "Nadi: " + dash(e.Nadi),
+			"RR: " + dash(e.Respirasi), "TB: " + dash(e.Tinggi), "BB: " + dash(e.Berat),
+			"GCS: " + dash(e.GCS), "Kesadaran: " + dash(e.Kesadaran),
+		}
+		plan := e.RTL
+		if e.Instruksi != "" {
+			plan += "\nInstruksi: " + e.Instruksi
+		}
+		if e.Evaluasi != "" {
+			plan += "\nEvaluasi: " + e.Evaluasi
+		}
+		subjek := e.Keluhan
+		if e.Alergi != "" {
+			subjek += "\nAlergi: " + e.Alergi
+		}
+		rows = append(rows, []string{
+			e.TglPerawatan + "\n" + e.JamRawat,
+			strings.Join(vitals, "\n"),
+			subjek, e.Pemeriksaan, e.Penilaian, plan,
+		})
+	}
+	r.l.table([]column{
+		{"Tanggal", 0.10, false}, {"TTV", 0.15, false}, {"Subjek", 0.18, false},
+		{"Objek", 0.20, false}, {"Asesmen", 0.17, false}, {"Plan", 0.20, false},
+	}, rows)
+}
+
+// Section 3: medical actions and room stays
+func (r *renderer) actions() {
+	l := r.l
+	if len(r.detail.Actions) > 0 {
+		l.section("TINDAKAN MEDIS")
+		for _, group := range groupActions(r.detail.Actions) {
+			l.subheading(group.name)
+			rows := make([][]string, 0, len(group.items))
+			for _, a := range group.items {
+				rows = append(rows, []string{strings.TrimSpace(a.Tanggal + " " + a.Jam), a.Kode, a.Nama, a.Dokter, a.Petugas})
+			}
+			l.table([]column{
+				{"Tanggal", 0.15, false}, {"Kode", 0.12, false}, {"Nama Tindakan", 0.35, false},
+				{"Dokter", 0.19, false}, {"Petugas", 0.19, false},
+			}, rows)
+		}
+	}
+
+	if len(r.detail.RoomStays) > 0 {
+		l.section("KAMAR INAP")
+		rows := make([][]string, 0, len(r.detail.RoomStays))
+		for _, s := range r.detail.RoomStays {
+			rows = append(rows, []string{
+				strings.TrimSpace(s.TglMasuk + " " + s.JamMasuk),
+				strings.TrimSpace(s.TglKeluar + " " + s.JamKeluar),
+				strconv.Itoa(s.LamaInap),
+				joinNonEmpty(" - ", s.Kamar, s.Bangsal),
+				s.StatusPulang,
+			})
+		}
+		l.table([]column{
+			{"Tgl. Masuk", 0.18, false}, {"Tgl. Keluar", 0.18, false}, {"Lama Inap", 0.10, true},
+			{"Kamar", 0.36, false}, {"Status Pulang", 0.18, false},
+		}, rows)
+	}
+}
+
+// Section 4: operations
+func (r *renderer) operations() {
+	l := r.l
+	if len(r.detail.Operations) == 0 && len(r.detail.OpReports) == 0 {
+		return
+	}
+	l.section("OPERASI")
+	if len(r.detail.Operations) > 0 {
+		rows := make([][]string, 0, len(r.detail.Operations))
+		for _, o := range r.detail.Operations {
+			rows = append(rows, []string{o.TglOperasi, o.KodePaket, o.NamaTindakan, o.JenisAnastesi})
+		}
+		l.table([]column{
+			{"Tanggal", 0.18, false}, {"Kode Paket", 0.14, false}, {"Nama Tindakan", 0.48, false}, {"Anestesi", 0.20, false},
+		}, rows)
+	}
+
+	for _, rep := range r.detail.OpReports {
+		l.subheading("Laporan Operasi")
+		l.fields([]field{
+			{"Operasi Mulai", rep.Tanggal}, {"Selesai Operasi", rep.SelesaiOperasi},
+			{"Diagnosa Pre-op", rep.DiagnosaPreop}, {"Diagnosa Post-op", rep.DiagnosaPostop},
+			{"Jaringan Dieksisi", rep.JaringanDieksekusi}, {"Permintaan PA", rep.PermintaanPA},
+		}, 2)
+		l.paragraph("Laporan Operasi", rep.LaporanOperasi)
+		r.signatures("", "", "Dokter Operator", rep.DokterOperator)
+	}
+}
+
+// Section 5: radiology
+func (r *renderer) radiology() {
+	l := r.l
+	rad := r.detail.Radiology
+	if len(rad.Exams) == 0 && len(rad.Results) == 0 {
+		return
+	}
+	l.section("RADIOLOGI")
+	if len(rad.Exams) > 0 {
+		rows := make([][]string, 0, len(rad.Exams))
+		for _, e := range rad.Exams {
+			rows = append(rows, []string{strings.TrimSpace(e.TglPeriksa + " " + e.Jam), e.Kode, e.Nama, e.Dokter, e.Petugas})
+		}
+		l.table([]column{
+			{"Tanggal", 0.15, false}, {"Kode", 0.12, false}, {"Nama Pemeriksaan", 0.35, false},
+			{"Dokter", 0.19, false}, {"Petugas", 0.19, false},
+		}, rows)
+	}
+
+	for _, res := range rad.Results {
+		l.subheading("Hasil Pemeriksaan " + strings.TrimSpace(res.TglPeriksa+" "+res.Jam))
+		if res.Judul != "" {
+			l.fields([]field{{"Judul", res.Judul}}, 1)
+		}
+		l.paragraph("Klinis", res.Klinis)
+		l.paragraph("Hasil", res.Hasil)
+		if res.Kesan != "" {
+			l.paragraph("Kesan", res.Kesan)
+		}
+		if res.Saran != "" {
+			l.paragraph("Saran", res.Saran)
+		}
+		for _, p := range res.Gambar {
+			r.inlineImage("radiologi/"+p, 300)
+		}
+	}
+}
+
+// Section 6: laboratory
+func (r *renderer) laboratory() {
+	l := r.l
+	if len(r.detail.LabExams) == 0 {
+		return
+	}
+	l.section("PEMERIKSAAN LABORATORIUM")
+	for _, exam := range r.detail.LabExams {
+		title := strings.TrimSpace(exam.TglPeriksa+" "+exam.Jam) + " - " + exam.NamaTindakan
+		if exam.Dokter != "" {
+			title += " (" + exam.Dokter + ")"
+		}
+		l.subheading(title)
+		if len(exam.Details) == 0 {
+			l.note("Belum ada hasil.")
+			continue
+		}
+		rows := make([][]string, 0, len(exam.Details))
+		for _, d := range exam.Details {
+			rows = append(rows, []string{d.Pemeriksaan, d.Nilai, d.Satuan, d.NilaiRujukan, d.Keterangan})
+		}
+		l.table([]column{
+			{"Pemeriksaan", 0.34, false}, {"Hasil", 0.16, false}, {"Satuan", 0.12, false},
+			{"Nilai Rujukan", 0.22, false}, {"Keterangan", 0.16, false},
+		}, rows)
+	}
+}
+
+// Section 6.5: anatomical pathology
+func (r *renderer) pathology() {
+	l := r.l
+	for _, pa := range r.detail.LabPAReports {
+		l.section("HASIL PATOLOGI ANATOMI")
+		l.fields([]field{
+			{"No. Order", pa.NoOrder}, {"No. Sediaan", pa.NoSediaan},
+			{"Tgl. Permintaan", strings.TrimSpace(pa.TglPermintaan + " " + pa.JamPermintaan)}, {"Tgl. Hasil", strings.TrimSpace(pa.TglHasil + " " + pa.JamHasil)},
+			{"Pemeriksaan", pa.PemeriksaanPA}, {"Poli", pa.Poli},
+		}, 2)
+		l.paragraph("Diagnosa Klinis", pa.DiagnosaKlinis)
+		l.paragraph("Makroskopik", pa.Makroskopik)
+		l.paragraph("Mikroskopik", pa.Mikroskopik)
+		l.paragraph("Kesimpulan", pa.Kesimpulan)
+		if pa.Kesan != "" {
+			l.paragraph("Kesan", pa.Kesan)
+		}
+		r.signatures("", "", "Dokter Patologi Anatomi", pa.NamaDokter)
+	}
+}
+
+// Section 7: medicines
+func (r *renderer) medicines() {
+	l := r.l
+	if len(r.detail.Medicines) == 0 {
+		return
+	}
+	l.section("OBAT & FARMASI")
+	for _, group := range groupMedicines(r.detail.Medicines) {
+		l.subheading(group.name)
+		rows := make([][]string, 0, len(group.items))
+		for _, m := range group.items {
+			rows = append(rows, []string{
+				strings.TrimSpace(m.TglPerawatan + " " + m.Jam), m.KodeBrng, m.NamaObat,
+				strings.TrimSpace(quantity(m.Jumlah) + " " + m.Satuan), m.Dosis,
+			})
+		}
+		l.table([]column{
+			{"Tanggal", 0.16, false}, {"Kode", 0.13, false}, {"Nama Obat", 0.39, false},
+			{"Jumlah", 0.14, true}, {"Dosis", 0.18, false},
+		}, rows)
+	}
+}
+
+// Section 8: medical resume
+func (r *renderer) resume() {
+	l := r.l
+	if rj := r.detail.ResumeRalan; rj != nil {
+		l.section("RESUME MEDIS RAWAT JALAN")
+		l.fields([]field{
+			{"Diagnosa Utama", rj.DiagnosaUtama},
+			{"Diagnosa Sekunder", joinNonEmpty("; ", rj.DiagnosaSekunder1, rj.DiagnosaSekunder2, rj.DiagnosaSekunder3, rj.DiagnosaSekunder4)},
+			{"Prosedur Utama", rj.ProsedurUtama},
+			{"Prosedur Sekunder", joinNonEmpty("; ", rj.ProsedurSekunder1, rj.ProsedurSekunder2, rj.ProsedurSekunder3)},
+		}, 1)
+		l.paragraph("Keluhan", rj.KeluhanUtama)
+		l.paragraph("Pemeriksaan", rj.Pemeriksaan)
+		l.fields([]field{
+			{"Tensi", rj.Tensi}, {"Respirasi", rj.Respirasi},
+			{"Nadi", rj.Nadi}, {"Suhu", rj.Suhu},
+			{"Alergi", rj.Alergi}, {"Dirawat Inapkan", rj.DirawatInapkan},
+			{"Kunjungan Awal", rj.KunjunganAwal}, {"Kunjungan Lanjutan", rj.KunjunganLanjutan},
+			{"Observasi", rj.Observasi}, {"Post Operasi", rj.PostOperasi},
+		}, 2)
+		r.signatures("", "", "Dokter Pemeriksa", rj.NamaDokter)
+	}
+
+	if ri := r.detail.ResumeRanap; ri != nil {
+		l.section("RESUME MEDIS RAWAT INAP")
+		l.fields([]field{
+			{"Dokter DPJP", ri.NamaDokter},
+			{"Diagnosa Masuk", ri.DiagnosaAwal},
+		}, 1)
+		l.paragraph("Keluhan Utama & Riwayat Penyakit", ri.KeluhanUtama)
+		l.paragraph("Jalannya Penyakit Selama Perawatan", ri.JalannyaPenyakit)
+		l.paragraph("Pemeriksaan Fisik", ri.PemeriksaanFisik)
+		l.paragraph("Pemeriksaan Penunjang", ri.PemeriksaanPenunjang)
+		l.paragraph("Hasil Laboratorium", ri.HasilLaborat)
+		l.fields([]field{
+			{"Diagnosa Utama", ri.DiagnosaUtama},
+			{"Diagnosa Sekunder", joinNonEmpty("; ", ri.DiagnosaSekunder1, ri.DiagnosaSekunder2, ri.DiagnosaSekunder3, ri.DiagnosaSekunder4)},
+			{"Prosedur Utama", ri.ProsedurUtama},
+			{"Prosedur Sekunder", joinNonEmpty("; ", ri.ProsedurSekunder1, ri.ProsedurSekunder2, ri.ProsedurSekunder3)},
+		}, 1)
+		l.paragraph("Obat Pulang / Nasihat", ri.ObatPulang)
+		l.fields([]field{{"Kondisi Pulang", ri.KondisiPulang}}, 1)
+		r.signatures("", "", "Dokter Penanggung Jawab", ri.NamaDokter)
+	}
+}
+
+// Section 9: billing
+func (r *renderer) billing() {
+	b := r.detail.Billing
+	if b == nil {
+		return
+	}
+	l := r.l
+	l.newPage()
+	l.title("RINCIAN BIAYA PERAWATAN", r.opts.Institution)
+	l.fields([]field{
+		{"No. Rawat", r.detail.Patient.NoRawat}, {"No. Nota", b.NoNota},
+		{"Nama Pasien", r.detail.Patient.NamaPasien}, {"Tgl. Bayar", b.TglBayar},
+		{"No. RM", r.detail.Patient.NoRM}, {"Kasir", b.Kasir},
+	}, 2)
+
+	for _, cat := range b.Categories {
+		l.subheading(cat.Kategori)
+		rows := make([][]string, 0, len(cat.Items))
+		for i, item := range cat.Items {
+			no := item.No
+			if no == 0 {
+				no = i + 1
+			}
+			rows = append(rows, []string{
+				strconv.Itoa(no), item.NamaPerawatan, money(item.Biaya),
+				strconv.Itoa(item.Jumlah), money(item.Tambahan), money(item.TotalBiaya),
+			})
+		}
+		l.table([]column{
+			{"No", 0.06, true}, {"Nama Perawatan", 0.44, false}, {"Biaya", 0.14, true},
+			{"Jml", 0.07, true}, {"Tambahan", 0.13, true}, {"Total", 0.16, true},
+		}, rows)
+		l.totalRow("Subtotal", money(cat.Subtotal))
+		l.space(4)
+	}
+
+	l.totalRow("Jumlah Total", money(b.JumlahTotal))
+	l.totalRow("Potongan", money(b.Potongan))
+	l.totalRow("Jumlah Bayar", money(b.JumlahBayar))
+	if b.Terbilang != "" {
+		l.paragraph("Terbilang", b.Terbilang)
+	}
+	r.signatures("Keluarga Pasien", "", "Kasir", b.Kasir)
+}
+
+// Section 10: SPRI
+func (r *renderer) spri() {
+	s := r.detail.SPRI
+	if s == nil {
+		return
+	}
+	l := r.l
+	l.newPage()
+	l.title("SURAT PERINTAH RAWAT INAP", r.opts.Institution)
+	l.fields([]field{
+		{"No. Surat", s.NoSurat},
+		{"Tgl. Surat", s.TglSurat},
+		{"Kepada Yth", joinNonEmpty(" - ", s.NamaDokter, s.NamaPoli)},
+		{"No. Kartu", s.NoKartu},
+		{"Nama Pasien", fmt.Sprintf("%s (%s)", s.NamaPasien, s.JenisKelamin)},
+		{"Tgl. Lahir", s.TglLahir},
+		{"Diagnosa Awal", s.DiagnosaAwal},
+		{"Tgl. Rencana Inap", s.TglRencana},
+	}, 1)
+	r.signatures("", "", "Dokter", s.NamaDokter)
+}
+
+// Sections 11-13: uploaded documents, identity first, then radiology,
+// then the remaining care documents.
+func (r *renderer) documents() {
+	docs := append([]entity.DigitalDocument{}, r.detail.Documents...)
+	sort.SliceStable(docs, func(i, j int) bool {
+		return documentGroup(docs[i].Kategori) < documentGroup(docs[j].Kategori)
+	})
+
+	for _, d := range docs {
+		caption := d.Kategori + " - " + path.Base(d.LokasiFile)
+		if r.opts.LoadFile == nil {
+			r.missingDocument(caption, "penyimpanan berkas tidak tersedia")
+			continue
+		}
+		data, err := r.opts.LoadFile("berkasrawat/" + d.LokasiFile)
+		if err != nil {
+			r.missingDocument(caption, "berkas tidak ditemukan")
+			continue
+		}
+
+		if bytes.HasPrefix(bytes.TrimLeft(data[:min(len(data), 1024)], "\x00\r\n\t "), []byte("%PDF-")) {
+			if _, err := r.l.doc.ImportPDF(data); err != nil {
+				r.missingDocument(caption, "PDF tidak dapat dibaca ("+err.Error()+")")
+			}
+			// Later flowing content must not draw on an imported page
+			r.l.page = nil
+			continue
+		}
+
+		img, err := r.l.doc.AddImage(data)
+		if err != nil {
+			r.missingDocument(caption, "format berkas tidak didukung")
+			continue
+		}
+		r.l.newPage()
+		r.l.subheading(caption)
+		r.drawImage(img, pdf.A4Height-marginBottom-r.l.y)
+	}
+}
+
+// documentGroup orders documents as sections 11 (identity), 12 (radiology)
+// and 13 (care documents).
+func documentGroup(kategori string) int {
+	k := strings.ToUpper(kategori)
+	for _, word := range []string{"KTP", "KARTU", "KK", "IDENTITAS", "KELUARGA", "BPJS"} {
+		if strings.Contains(k, word) {
+			return 11
+		}
+	}
+	for _, word := range []string{"RADIOLOGI", "RONTGEN", "RO ", "CT", "USG", "MRI"} {
+		if strings.Contains(k, word) {
+			return 12
+		}
+	}
+	return 13
+}
+
+func (r *renderer) missingDocument(caption, reason string) {
+	r.l.newPage()
+	r.l.subheading(caption)
+	r.l.note("Berkas tidak dapat dilampirkan: " + reason + ".")
+	r.l.page = nil
+}
+
+// inlineImage draws an image file in the flow, at most maxHeight tall.
+func (r *renderer) inlineImage(file string, maxHeight float64) {
+	if r.opts.LoadFile == nil {
+		return
+	}
+	data, err := r.opts.LoadFile(file)
+	if err != nil {
+		r.l.note("Gambar " + path.Base(file) + " tidak ditemukan.")
+		return
+	}
+	img, err := r.l.doc.AddImage(data)
+	if err != nil {
+		r.l.note("Gambar " + path.Base(file) + " tidak dapat ditampilkan.")
+		return
+	}
+	r.l.ensure(maxHeight)
+	r.drawImage(img, maxHeight)
+}
+
+// drawImage scales img into the content width and maxHeight, centered.
+func (r *renderer) drawImage(img *pdf.Image, maxHeight float64) {
+	w, h := float64(img.Width), float64(img.Height)
+	scale := min(contentWidth/w, maxHeight/h)
+	w, h = w*scale, h*scale
+	r.l.page.Image(img, marginX+(contentWidth-w)/2, r.l.y, w, h)
+	r.l.y += h + 8
+}
+
+// signatures draws up to two signature blocks (left and right).
+func (r *renderer) signatures(leftRole, leftName, rightRole, rightName string) {
+	l := r.l
+	l.ensure(70)
+	l.y += 8
+	blockWidth := 180.0
+	draw := func(x float64, role, name string) {
+		if role == "" {
+			return
+		}
+		l.page.Text(x, l.y+fontSize, pdf.Helvetica, fontSize, role)
+		l.page.Line(x, l.y+48, x+blockWidth-20, l.y+48, 0.5)
+		if name != "" {
+			l.page.Text(x, l.y+48+fontSize+2, pdf.Helvetica, fontSize, name)
+		}
+	}
+	draw(marginX, leftRole, leftName)
+	draw(marginX+contentWidth-blockWidth, rightRole, rightName)
+	l.y += 70
+}
+
+// footers numbers every page, imported documents included.
+func (r *renderer) footers() {
+	pages := r.l.doc.Pages()
+	left := "No. Rawat " + r.detail.Patient.NoRawat + " - " + r.detail.Patient.NamaPasien
+	if !r.opts.PrintedAt.IsZero() {
+		left += " - Dicetak " + r.opts.PrintedAt.Format("02-01-2006 15:04")
+	}
+	for i, p := range pages {
+		w, h := p.Size()
+		right := fmt.Sprintf("Halaman %d dari %d", i+1, len(pages))
+		p.Text(marginX/2+4, h-14, pdf.Helvetica, 7, left)
+		p.Text(w-marginX/2-4-pdf.TextWidth(pdf.Helvetica, 7, right), h-14, pdf.Helvetica, 7, right)
+	}
+}
+
+type actionGroup struct {
+	name  string
+	items []entity.MedicalAction
+}
+
+// groupActions groups actions by category, keeping first-seen order.
+func groupActions(actions []entity.MedicalAction) []actionGroup {
+	var groups []actionGroup
+	index := map[string]int{}
+	for _, a := range actions {
+		i, ok := index[a.Kategori]
+		if !ok {
+			i = len(groups)
+			index[a.Kategori] = i
+			groups = append(groups, actionGroup{name: a.Kategori})
+		}
+		groups[i].items = append(groups[i].items, a)
+	}
+	return groups
+}
+
+type medicineGroup struct {
+	name  string
+	items []entity.MedicineItem
+}
+
+func groupMedicines(items []entity.MedicineItem) []medicineGroup {
+	var groups []medicineGroup
+	index := map[string]int{}
+	for _, m := range items {
+		i, ok := index[m.Kategori]
+		if !ok {
+			i = len(groups)
+			index[m.Kategori] = i
+			groups = append(groups, medicineGroup{name: m.Kategori})
+		}
+		groups[i].items = append(groups[i].items, m)
+	}
+	return groups
+}
+
+// money formats an amount in Indonesian style: 1.234.567
+func money(v float64) string {
+	neg := v < 0
+	if neg {
+		v = -v
+	}
+	s := strconv.FormatFloat(v, 'f', 0, 64)
+	var out []byte
+	for i := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			out = append(out, '.')
+		}
+		out = append(out, s[i])
+	}
+	if neg {
+		return "-" + string(out)
+	}
+	return string(out)
+}
+
+func quantity(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	var out []string
+	for _, p := range parts {
+		if strings.TrimSpace(p) != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, sep)
+}
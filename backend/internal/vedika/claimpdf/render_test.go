@@ -0,0 +1,90 @@
+package claimpdf
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/pkg/pdf"
+)
+
+func pageCount(t *testing.T, data []byte) int {
+	t.Helper()
+	r, err := pdf.NewReader(data)
+	if err != nil {
+		t.Fatalf("rendered PDF does not parse: %v", err)
+	}
+	pages, err := r.Pages()
+	if err != nil {
+		t.Fatalf("rendered PDF has no pages: %v", err)
+	}
+	return len(pages)
+}
+
+func TestRenderEmptyClaim(t *testing.T) {
+	detail := &entity.ClaimFullDetail{}
+	detail.Patient.NoRawat = "2026/01/01/000001"
+	detail.Patient.NamaPasien = "Budi"
+
+	data, err := Render(detail, Options{Institution: "RS Uji", PrintedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if n := pageCount(t, data); n < 1 {
+		t.Fatalf("got %d pages", n)
+	}
+}
+
+func TestRenderAttachments(t *testing.T) {
+	attachment := pdf.New()
+	attachment.AddPage()
+	attachment.AddPage()
+	attachmentPDF, err := attachment.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var scan bytes.Buffer
+	if err := png.Encode(&scan, image.NewGray(image.Rect(0, 0, 20, 30))); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]byte{
+		"berkasrawat/pages/upload/sep.pdf":   attachmentPDF,
+		"berkasrawat/pages/upload/ktp.png":   scan.Bytes(),
+		"berkasrawat/pages/upload/rusak.png": []byte("bukan gambar"),
+	}
+	load := func(path string) ([]byte, error) {
+		if data, ok := files[path]; ok {
+			return data, nil
+		}
+		return nil, errors.New("not found")
+	}
+
+	detail := &entity.ClaimFullDetail{Documents: []entity.DigitalDocument{
+		{Kategori: "SEP", LokasiFile: "pages/upload/sep.pdf"},
+		{Kategori: "KTP", LokasiFile: "pages/upload/ktp.png"},
+		{Kategori: "LAIN", LokasiFile: "pages/upload/rusak.png"},
+		{Kategori: "LAIN", LokasiFile: "pages/upload/hilang.pdf"},
+	}}
+	detail.Patient.NoRawat = "2026/01/01/000001"
+
+	without, err := Render(detail, Options{PrintedAt: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	with, err := Render(detail, Options{LoadFile: load, PrintedAt: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without storage each document gets a notice page. With it the PDF
+	// contributes its 2 pages and the scan 1; the broken and missing files
+	// keep their notice pages.
+	if got, want := pageCount(t, with), pageCount(t, without)+1; got != want {
+		t.Fatalf("got %d pages with attachments, want %d", got, want)
+	}
+}
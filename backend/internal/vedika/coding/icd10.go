@@ -0,0 +1,119 @@
+package coding
+
+import "strings"
+
+// codeRange is an inclusive range of ICD-10 three-character categories.
+type codeRange struct {
+	From, To string
+}
+
+func (r codeRange) contains(category string) bool {
+	return category >= r.From && category <= r.To
+}
+
+func inRanges(category string, ranges []codeRange) bool {
+	for _, r := range ranges {
+		if r.contains(category) {
+			return true
+		}
+	}
+	return false
+}
+
+// Normalize upper-cases a code and strips spaces.
+func Normalize(code string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+}
+
+// Key returns the normalized code without its dagger/asterisk markers, so
+// that A17.0† and A17.0 compare equal.
+func Key(code string) string {
+	return strings.TrimRight(Normalize(code), "*†+")
+}
+
+// Category returns the three-character ICD-10 category of a code (A09.9 -> A09).
+func Category(code string) string {
+	code = Key(code)
+	if len(code) < 3 {
+		return code
+	}
+	return code[:3]
+}
+
+// asteriskCategories are ICD-10 manifestation categories that may only be
+// coded together with their dagger etiology code.
+var asteriskCategories = map[string]bool{
+	"D63": true, "D77": true, "E35": true, "E90": true, "F00": true, "F02": true,
+	"G01": true, "G02": true, "G05": true, "G07": true, "G13": true, "G22": true,
+	"G26": true, "G32": true, "G46": true, "G53": true, "G55": true, "G59": true,
+	"G63": true, "G73": true, "G94": true, "G99": true, "H03": true, "H06": true,
+	"H13": true, "H19": true, "H22": true, "H28": true, "H32": true, "H36": true,
+	"H42": true, "H45": true, "H48": true, "H58": true, "H62": true, "H67": true,
+	"H75": true, "H82": true, "H94": true, "I32": true, "I39": true, "I41": true,
+	"I43": true, "I52": true, "I68": true, "I79": true, "I98": true, "J17": true,
+	"J91": true, "J99": true, "K23": true, "K67": true, "K77": true, "K87": true,
+	"K93": true, "L14": true, "L45": true, "L54": true, "L62": true, "L86": true,
+	"L99": true, "M01": true, "M03": true, "M07": true, "M09": true, "M14": true,
+	"M36": true, "M49": true, "M63": true, "M68": true, "M73": true, "M82": true,
+	"M90": true, "N08": true, "N16": true, "N22": true, "N29": true, "N33": true,
+	"N37": true, "N51": true, "N74": true, "N77": true,
+}
+
+// IsAsterisk reports whether code is a manifestation (asterisk) code, either
+// by category or by an explicit '*' marker.
+func IsAsterisk(code string) bool {
+	return strings.HasSuffix(Normalize(code), "*") || asteriskCategories[Category(code)]
+}
+
+// externalCause covers chapter XX, external causes of morbidity.
+var externalCause = []codeRange{{"V01", "Y98"}}
+
+// IsExternalCause reports whether code describes an external cause (V01-Y98).
+func IsExternalCause(code string) bool {
+	return inRanges(Category(code), externalCause)
+}
+
+// IsSymptom reports whether code is a symptom or abnormal finding (R00-R99).
+func IsSymptom(code string) bool {
+	return inRanges(Category(code), []codeRange{{"R00", "R99"}})
+}
+
+// femaleOnly and maleOnly are categories restricted to one sex.
+var (
+	femaleOnly = []codeRange{
+		{"C51", "C58"}, {"D06", "D06"}, {"D25", "D28"}, {"D39", "D39"},
+		{"E28", "E28"}, {"N70", "N98"}, {"O00", "O99"}, {"Z32", "Z37"},
+		{"Z39", "Z39"},
+	}
+	maleOnly = []codeRange{
+		{"C60", "C63"}, {"D29", "D29"}, {"D40", "D40"}, {"E29", "E29"},
+		{"N40", "N51"},
+	}
+)
+
+// Sex returns "P" for female-only codes, "L" for male-only codes and "" when
+// the code applies to both.
+func Sex(code string) string {
+	category := Category(code)
+	switch {
+	case inRanges(category, femaleOnly):
+		return "P"
+	case inRanges(category, maleOnly):
+		return "L"
+	}
+	return ""
+}
+
+// perinatal covers chapter XVI conditions originating in the perinatal
+// period and the liveborn infant category.
+var perinatal = []codeRange{{"P00", "P96"}, {"Z38", "Z38"}}
+
+// IsPerinatal reports whether code is expected only on newborn episodes.
+func IsPerinatal(code string) bool {
+	return inRanges(Category(code), perinatal)
+}
+
+// IsObstetric reports whether code is a pregnancy, childbirth or puerperium code.
+func IsObstetric(code string) bool {
+	return inRanges(Category(code), []codeRange{{"O00", "O99"}})
+}
@@ -0,0 +1,221 @@
+// Package coding validates ICD-10 diagnosis and ICD-9-CM procedure coding
+// of a claim before it is written to diagnosa_pasien / prosedur_pasien.
+package coding
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+)
+
+// Rule names reported in entity.CodingIssue.Rule.
+const (
+	RuleEmptyCode           = "empty_code"
+	RulePrimaryCount        = "primary_count"
+	RuleDuplicate           = "duplicate"
+	RuleAsteriskPrimary     = "asterisk_primary"
+	RuleExternalCause       = "external_cause_primary"
+	RuleUnacceptablePrimary = "unacceptable_primary"
+	RuleSymptomPrimary      = "symptom_primary"
+	RuleSex                 = "sex"
+	RuleAge                 = "age"
+)
+
+// Age bounds in days for age-specific codes.
+const (
+	neonatalDays     = 28
+	minObstetricDays = 10 * 365
+	maxObstetricDays = 55 * 365
+)
+
+// Validator checks coding sets against the built-in ICD rules and the
+// hospital's BPJS configuration.
+type Validator struct {
+	unacceptable []string
+}
+
+// NewValidator creates a validator from the vedika.coding setting.
+func NewValidator(cfg entity.CodingConfig) *Validator {
+	v := &Validator{}
+	for _, code := range cfg.UnacceptablePrimary {
+		if code = Normalize(code); code != "" {
+			v.unacceptable = append(v.unacceptable, code)
+		}
+	}
+	return v
+}
+
+// IsPrimary reports whether a submitted diagnosis line is the primary
+// diagnosis. An explicit status_dx wins over prioritas.
+func IsPrimary(d entity.DiagnosisUpdateRequest) bool {
+	if d.StatusDx != "" {
+		return strings.EqualFold(strings.TrimSpace(d.StatusDx), "Utama")
+	}
+	return d.Prioritas == 1
+}
+
+// ValidateDiagnoses checks a complete diagnosis set of an episode.
+func (v *Validator) ValidateDiagnoses(patient *entity.CodingPatient, lines []entity.DiagnosisUpdateRequest) *entity.CodingValidation {
+	result := entity.NewCodingValidation()
+	issue := func(line int, code, rule, severity, format string, args ...interface{}) {
+		result.Add(entity.CodingIssue{Line: line, Kode: code, Rule: rule, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	seen := make(map[string]int, len(lines))
+	primaries := 0
+	for i, d := range lines {
+		code := Normalize(d.KodePenyakit)
+		if code == "" {
+			issue(i, "", RuleEmptyCode, entity.SeverityError, "Kode diagnosa kosong")
+			continue
+		}
+		if first, ok := seen[Key(code)]; ok {
+			issue(i, code, RuleDuplicate, entity.SeverityError, "Kode %s sudah ada pada baris %d", code, first+1)
+			continue
+		}
+		seen[Key(code)] = i
+
+		if IsPrimary(d) {
+			primaries++
+			if primaries > 1 {
+				issue(i, code, RulePrimaryCount, entity.SeverityError, "Hanya boleh ada satu diagnosa utama")
+			}
+			v.checkPrimary(i, code, issue)
+		}
+		checkPatient(i, code, patient, issue)
+	}
+	if primaries == 0 {
+		issue(-1, "", RulePrimaryCount, entity.SeverityError, "Belum ada diagnosa utama")
+	}
+
+	return result
+}
+
+// checkPrimary applies the rules specific to the primary diagnosis.
+func (v *Validator) checkPrimary(line int, code string, issue func(int, string, string, string, string, ...interface{})) {
+	switch {
+	case IsAsterisk(code):
+		issue(line, code, RuleAsteriskPrimary, entity.SeverityError,
+			"Kode asterisk %s tidak boleh menjadi diagnosa utama; gunakan kode dagger etiologinya", code)
+	case IsExternalCause(code):
+		issue(line, code, RuleExternalCause, entity.SeverityError,
+			"Kode sebab luar %s (V01-Y98) tidak boleh menjadi diagnosa utama", code)
+	}
+
+	for _, prefix := range v.unacceptable {
+		if strings.HasPrefix(code, prefix) {
+			issue(line, code, RuleUnacceptablePrimary, entity.SeverityError,
+				"Kode %s tidak diterima BPJS sebagai diagnosa utama", code)
+			break
+		}
+	}
+
+	if IsSymptom(code) {
+		issue(line, code, RuleSymptomPrimary, entity.SeverityWarning,
+			"Kode gejala %s sebagai diagnosa utama; pastikan tidak ada diagnosa definitif", code)
+	}
+}
+
+// checkPatient applies sex- and age-specific rules.
+func checkPatient(line int, code string, patient *entity.CodingPatient, issue func(int, string, string, string, string, ...interface{})) {
+	if patient == nil {
+		return
+	}
+
+	if sex := Sex(code); sex != "" && patient.JenisKelamin != "" && !strings.EqualFold(patient.JenisKelamin, sex) {
+		label := "perempuan"
+		if sex == "L" {
+			label = "laki-laki"
+		}
+		issue(line, code, RuleSex, entity.SeverityError, "Kode %s hanya untuk pasien %s", code, label)
+	}
+
+	age := patient.AgeDays()
+	if age < 0 {
+		return
+	}
+	if IsPerinatal(code) && age > neonatalDays {
+		issue(line, code, RuleAge, entity.SeverityWarning,
+			"Kode perinatal %s pada pasien berusia lebih dari %d hari", code, neonatalDays)
+	}
+	if IsObstetric(code) && (age < minObstetricDays || age > maxObstetricDays) {
+		issue(line, code, RuleAge, entity.SeverityWarning,
+			"Kode obstetri %s di luar rentang usia reproduksi (10-55 tahun)", code)
+	}
+}
+
+// ValidateProcedures checks a complete procedure set of an episode.
+func (v *Validator) ValidateProcedures(lines []entity.ProcedureUpdateRequest) *entity.CodingValidation {
+	result := entity.NewCodingValidation()
+
+	seen := make(map[string]int, len(lines))
+	for i, p := range lines {
+		code := Normalize(p.Kode)
+		if code == "" {
+			result.Add(entity.CodingIssue{Line: i, Rule: RuleEmptyCode, Severity: entity.SeverityError, Message: "Kode prosedur kosong"})
+			continue
+		}
+		if first, ok := seen[Key(code)]; ok {
+			result.Add(entity.CodingIssue{Line: i, Kode: code, Rule: RuleDuplicate, Severity: entity.SeverityError,
+				Message: fmt.Sprintf("Kode %s sudah ada pada baris %d", code, first+1)})
+			continue
+		}
+		seen[Key(code)] = i
+	}
+
+	return result
+}
+
+// OrderDiagnoses returns the set with the primary diagnosis first at
+// prioritas 1 and the secondary diagnoses numbered from 2 in their submitted
+// priority order, with status_dx filled in. The set must have passed
+// ValidateDiagnoses.
+func OrderDiagnoses(lines []entity.DiagnosisUpdateRequest) []entity.DiagnosisUpdateRequest {
+	var primary []entity.DiagnosisUpdateRequest
+	var secondary []entity.DiagnosisUpdateRequest
+	for _, d := range lines {
+		d.KodePenyakit = Normalize(d.KodePenyakit)
+		if IsPrimary(d) {
+			d.StatusDx = "Utama"
+			primary = append(primary, d)
+		} else {
+			d.StatusDx = "Sekunder"
+			secondary = append(secondary, d)
+		}
+	}
+	sortByPrioritas(secondary, func(i int) int { return secondary[i].Prioritas })
+
+	ordered := append(primary, secondary...)
+	for i := range ordered {
+		ordered[i].Prioritas = i + 1
+	}
+	return ordered
+}
+
+// OrderProcedures numbers procedures from 1 in their submitted priority order.
+func OrderProcedures(lines []entity.ProcedureUpdateRequest) []entity.ProcedureUpdateRequest {
+	ordered := make([]entity.ProcedureUpdateRequest, len(lines))
+	for i, p := range lines {
+		p.Kode = Normalize(p.Kode)
+		ordered[i] = p
+	}
+	sortByPrioritas(ordered, func(i int) int { return ordered[i].Prioritas })
+	for i := range ordered {
+		ordered[i].Prioritas = i + 1
+	}
+	return ordered
+}
+
+// sortByPrioritas stable-sorts lines by prioritas, keeping lines without a
+// prioritas (0) after the numbered ones in submitted order.
+func sortByPrioritas[T any](lines []T, prioritas func(i int) int) {
+	key := func(i int) int {
+		if p := prioritas(i); p > 0 {
+			return p
+		}
+		return int(^uint(0) >> 1)
+	}
+	sort.SliceStable(lines, func(i, j int) bool { return key(i) < key(j) })
+}
@@ -0,0 +1,53 @@
+package coding
+
+import (
+	"testing"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+)
+
+func TestKey(t *testing.T) {
+	tests := map[string]string{
+		"a17.0†":  "A17.0",
+		"G01*":    "G01",
+		" e11.9 ": "E11.9",
+		"A17.0":   "A17.0",
+	}
+	for in, want := range tests {
+		if got := Key(in); got != want {
+			t.Errorf("Key(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestValidateDiagnosesDuplicateIgnoresMarkers(t *testing.T) {
+	v := NewValidator(entity.CodingConfig{})
+	result := v.ValidateDiagnoses(nil, []entity.DiagnosisUpdateRequest{
+		{KodePenyakit: "A17.0†", Prioritas: 1},
+		{KodePenyakit: "A17.0", Prioritas: 2},
+		{KodePenyakit: "G01*", Prioritas: 3},
+		{KodePenyakit: "g01", Prioritas: 4},
+	})
+
+	var dupLines []int
+	for _, issue := range result.Errors {
+		if issue.Rule == RuleDuplicate {
+			dupLines = append(dupLines, issue.Line)
+		}
+	}
+	if len(dupLines) != 2 || dupLines[0] != 1 || dupLines[1] != 3 {
+		t.Fatalf("duplicate lines = %v, want [1 3]; errors: %+v", dupLines, result.Errors)
+	}
+}
+
+func TestValidateProceduresDuplicate(t *testing.T) {
+	v := NewValidator(entity.CodingConfig{})
+	result := v.ValidateProcedures([]entity.ProcedureUpdateRequest{
+		{Kode: "89.03"},
+		{Kode: " 89.03"},
+		{Kode: "99.04"},
+	})
+	if result.Valid || len(result.Errors) != 1 || result.Errors[0].Line != 1 || result.Errors[0].Rule != RuleDuplicate {
+		t.Fatalf("errors = %+v, want one duplicate on line 1", result.Errors)
+	}
+}
@@ -0,0 +1,150 @@
+package entity
+
+// Aging buckets of days since service.
+const (
+	AgingBucket0to7   = "0-7"
+	AgingBucket8to14  = "8-14"
+	AgingBucket15to30 = "15-30"
+	AgingBucketOver30 = ">30"
+)
+
+// AgingBuckets lists the aging buckets in order.
+var AgingBuckets = []string{AgingBucket0to7, AgingBucket8to14, AgingBucket15to30, AgingBucketOver30}
+
+// AgingBucketOf returns the bucket of an age in days.
+func AgingBucketOf(days int) string {
+	switch {
+	case days <= 7:
+		return AgingBucket0to7
+	case days <= 14:
+		return AgingBucket8to14
+	case days <= 30:
+		return AgingBucket15to30
+	default:
+		return AgingBucketOver30
+	}
+}
+
+// SLAThreshold is the claim SLA of one jenis, in days. Zero disables a
+// threshold.
+type SLAThreshold struct {
+	SubmitDays int `json:"submit_days"` // Max age while still Rencana
+	StatusDays int `json:"status_days"` // Max time in Pengajuan, Perbaikan or Lengkap
+}
+
+// SLAConfig holds the claim SLA per jenis (vedika.sla).
+type SLAConfig struct {
+	Ralan SLAThreshold `json:"ralan"`
+	Ranap SLAThreshold `json:"ranap"`
+}
+
+// DefaultSLAConfig applies when vedika.sla is not configured.
+var DefaultSLAConfig = SLAConfig{
+	Ralan: SLAThreshold{SubmitDays: 30, StatusDays: 14},
+	Ranap: SLAThreshold{SubmitDays: 30, StatusDays: 14},
+}
+
+// For returns the threshold of a jenis.
+func (c SLAConfig) For(jenis JenisPelayanan) SLAThreshold {
+	if jenis == JenisRanap {
+		return c.Ranap
+	}
+	return c.Ralan
+}
+
+// IsOverdue reports whether a claim breaches the threshold. Rencana claims
+// are measured by age, processed claims by time in their status; Setuju
+// claims are done and never overdue.
+func (t SLAThreshold) IsOverdue(status ClaimStatus, ageDays, daysInStatus int) bool {
+	switch status.Normalize() {
+	case StatusSetuju:
+		return false
+	case StatusRencana:
+		return t.SubmitDays > 0 && ageDays > t.SubmitDays
+	default:
+		return t.StatusDays > 0 && daysInStatus > t.StatusDays
+	}
+}
+
+// ClaimAging is the age of a claim against its SLA. Age counts from
+// registration (ralan) or discharge (ranap); time in status from the last
+// change to the current status in the status history.
+type ClaimAging struct {
+	AgeDays      int    `json:"age_days"`
+	DaysInStatus int    `json:"days_in_status"`
+	Bucket       string `json:"bucket"` // Of AgeDays
+	Overdue      bool   `json:"overdue"`
+}
+
+// Evaluate sets the bucket and overdue flag of a claim in the status.
+func (a *ClaimAging) Evaluate(t SLAThreshold, status ClaimStatus) {
+	a.Bucket = AgingBucketOf(a.AgeDays)
+	a.Overdue = t.IsOverdue(status, a.AgeDays, a.DaysInStatus)
+}
+
+// AgingFilter selects the episodes of the aging report.
+type AgingFilter struct {
+	Range     DashboardRange
+	Carabayar []string // penjab.kd_pj
+}
+
+// AgingCount is the number of episodes of one jenis and status with the
+// same age and time in status.
+type AgingCount struct {
+	Jenis        JenisPelayanan
+	Status       ClaimStatus
+	AgeDays      int
+	DaysInStatus int
+	Count        int
+}
+
+// AgingBucketCount is the number of claims in one aging bucket.
+type AgingBucketCount struct {
+	Bucket string `json:"bucket"`
+	ClaimCount
+}
+
+// AgingRow holds the aging buckets of one status.
+type AgingRow struct {
+	Status  ClaimStatus        `json:"status"`
+	Buckets []AgingBucketCount `json:"buckets"`
+	Total   ClaimCount         `json:"total"`
+	Overdue ClaimCount         `json:"overdue"`
+}
+
+// NewAgingRow returns an empty row with every bucket.
+func NewAgingRow(status ClaimStatus) AgingRow {
+	row := AgingRow{Status: status, Buckets: make([]AgingBucketCount, len(AgingBuckets))}
+	for i, b := range AgingBuckets {
+		row.Buckets[i].Bucket = b
+	}
+	return row
+}
+
+// Add accumulates n claims of a jenis into the row.
+func (r *AgingRow) Add(jenis JenisPelayanan, bucket string, overdue bool, n int) {
+	counts := []*ClaimCount{&r.Total}
+	for i := range r.Buckets {
+		if r.Buckets[i].Bucket == bucket {
+			counts = append(counts, &r.Buckets[i].ClaimCount)
+		}
+	}
+	if overdue {
+		counts = append(counts, &r.Overdue)
+	}
+	for _, c := range counts {
+		if jenis == JenisRanap {
+			c.Ranap += n
+		} else {
+			c.Ralan += n
+		}
+	}
+}
+
+// AgingReport is the aging of the claims of a range by status.
+type AgingReport struct {
+	DashboardRange
+	SLA      SLAConfig  `json:"sla"`
+	Statuses []AgingRow `json:"statuses"`
+	Total    AgingRow   `json:"total"`
+}
@@ -0,0 +1,104 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAgingBucketOf(t *testing.T) {
+	tests := []struct {
+		days int
+		want string
+	}{
+		{0, AgingBucket0to7},
+		{7, AgingBucket0to7},
+		{8, AgingBucket8to14},
+		{14, AgingBucket8to14},
+		{15, AgingBucket15to30},
+		{30, AgingBucket15to30},
+		{31, AgingBucketOver30},
+		{400, AgingBucketOver30},
+	}
+	for _, tt := range tests {
+		if got := AgingBucketOf(tt.days); got != tt.want {
+			t.Errorf("AgingBucketOf(%d) = %s, want %s", tt.days, got, tt.want)
+		}
+	}
+}
+
+func TestSLAThresholdIsOverdue(t *testing.T) {
+	sla := SLAThreshold{SubmitDays: 30, StatusDays: 14}
+
+	tests := []struct {
+		name         string
+		threshold    SLAThreshold
+		status       ClaimStatus
+		age, inState int
+		want         bool
+	}{
+		{"rencana at limit", sla, StatusRencana, 30, 0, false},
+		{"rencana past limit", sla, StatusRencana, 31, 0, true},
+		{"rencana ignores time in status", sla, StatusRencana, 5, 40, false},
+		{"pengajuan at limit", sla, StatusPengajuan, 60, 14, false},
+		{"pengajuan past limit", sla, StatusPengajuan, 20, 15, true},
+		{"perbaikan past limit", sla, StatusPerbaikan, 20, 15, true},
+		{"lengkap past limit", sla, StatusLengkap, 20, 15, true},
+		{"legacy status name", sla, ClaimStatus("pengajuan"), 20, 15, true},
+		{"setuju is never overdue", sla, StatusSetuju, 400, 400, false},
+		{"submit threshold disabled", SLAThreshold{StatusDays: 14}, StatusRencana, 400, 0, false},
+		{"status threshold disabled", SLAThreshold{SubmitDays: 30}, StatusLengkap, 400, 400, false},
+	}
+	for _, tt := range tests {
+		if got := tt.threshold.IsOverdue(tt.status, tt.age, tt.inState); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSLAConfigForJenis(t *testing.T) {
+	cfg := SLAConfig{Ralan: SLAThreshold{SubmitDays: 7}, Ranap: SLAThreshold{SubmitDays: 30}}
+	if cfg.For(JenisRanap).SubmitDays != 30 || cfg.For(JenisRalan).SubmitDays != 7 {
+		t.Fatal("For does not pick the threshold of the jenis")
+	}
+
+	aging := ClaimAging{AgeDays: 10}
+	aging.Evaluate(cfg.For(JenisRalan), StatusRencana)
+	if aging.Bucket != AgingBucket8to14 || !aging.Overdue {
+		t.Fatalf("ralan: got %+v, want bucket 8-14 and overdue", aging)
+	}
+	aging.Evaluate(cfg.For(JenisRanap), StatusRencana)
+	if aging.Overdue {
+		t.Fatalf("ranap: got %+v, want within SLA", aging)
+	}
+}
+
+func TestAgingRowAdd(t *testing.T) {
+	row := NewAgingRow(StatusPengajuan)
+	var buckets []string
+	for _, b := range row.Buckets {
+		buckets = append(buckets, b.Bucket)
+	}
+	if !reflect.DeepEqual(buckets, AgingBuckets) {
+		t.Fatalf("buckets %v, want %v", buckets, AgingBuckets)
+	}
+
+	row.Add(JenisRalan, AgingBucket0to7, false, 3)
+	row.Add(JenisRanap, AgingBucket15to30, true, 2)
+	row.Add(JenisRalan, AgingBucket15to30, true, 1)
+
+	if row.Total != (ClaimCount{Ralan: 4, Ranap: 2}) {
+		t.Errorf("total %+v", row.Total)
+	}
+	if row.Overdue != (ClaimCount{Ralan: 1, Ranap: 2}) {
+		t.Errorf("overdue %+v", row.Overdue)
+	}
+	want := map[string]ClaimCount{
+		AgingBucket0to7:   {Ralan: 3},
+		AgingBucket15to30: {Ralan: 1, Ranap: 2},
+	}
+	for _, b := range row.Buckets {
+		if b.ClaimCount != want[b.Bucket] {
+			t.Errorf("bucket %s: %+v, want %+v", b.Bucket, b.ClaimCount, want[b.Bucket])
+		}
+	}
+}
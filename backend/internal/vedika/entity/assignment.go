@@ -0,0 +1,107 @@
+package entity
+
+import "time"
+
+// Assignment pool keys.
+const (
+	AssignByJenis = "jenis" // Pools keyed by ralan / ranap
+	AssignByUnit  = "unit"  // Pools keyed by kd_poli / kd_bangsal
+)
+
+// PermAssign allows assigning claims to any coder and running distribution.
+const PermAssign = "vedika.claim.assign"
+
+// AssignedBySystem is the assigned_by of automatic distribution.
+const AssignedBySystem = "system"
+
+// AssignmentConfig configures automatic distribution (vedika.assignment).
+type AssignmentConfig struct {
+	Enabled         bool                `json:"enabled"`
+	GroupBy         string              `json:"group_by"`
+	Pools           map[string][]string `json:"pools"`   // Pool key -> mera_users ids
+	Default         []string            `json:"default"` // Users for episodes without a pool
+	LookbackDays    int                 `json:"lookback_days"`
+	IntervalMinutes int                 `json:"interval_minutes"`
+}
+
+// Pool returns the coders an episode is distributed to.
+func (c AssignmentConfig) Pool(e AssignmentCandidate) []string {
+	key := e.Jenis
+	if c.GroupBy == AssignByUnit {
+		key = e.KdUnit
+	}
+	if pool := c.Pools[key]; len(pool) > 0 {
+		return pool
+	}
+	return c.Default
+}
+
+// AssignmentCandidate is an episode that can be assigned.
+type AssignmentCandidate struct {
+	NoRawat      string `json:"no_rawat"`
+	Jenis        string `json:"jenis"`   // ralan / ranap
+	KdUnit       string `json:"kd_unit"` // kd_poli / last kd_bangsal
+	TglPelayanan string `json:"tgl_pelayanan"`
+}
+
+// ClaimAssignment is the coder assigned to an episode (mera_vedika_assignment).
+type ClaimAssignment struct {
+	AssignmentCandidate
+	UserID     string    `json:"user_id"`
+	Username   string    `json:"username"`
+	AssignedBy string    `json:"assigned_by"`
+	AssignedAt time.Time `json:"assigned_at"`
+}
+
+// AssignRequest represents a request to assign a claim to a coder.
+type AssignRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// ClaimNextRequest selects the queue claim-next takes from. Dates default
+// to the active period.
+type ClaimNextRequest struct {
+	DateFrom string         `json:"date_from"`
+	DateTo   string         `json:"date_to"`
+	Jenis    JenisPelayanan `json:"jenis"`
+}
+
+// DistributeResult summarises a distribution run.
+type DistributeResult struct {
+	Assigned  int            `json:"assigned"`
+	Unpooled  int            `json:"unpooled"` // Episodes without a pool or default coders
+	PerUser   map[string]int `json:"per_user"`
+	StartedAt time.Time      `json:"started_at"`
+}
+
+// UserWorkload counts the claims assigned to one coder by status.
+type UserWorkload struct {
+	UserID    string `json:"user_id"`
+	Username  string `json:"username"`
+	Rencana   int    `json:"rencana"`
+	Pengajuan int    `json:"pengajuan"`
+	Perbaikan int    `json:"perbaikan"`
+	Lengkap   int    `json:"lengkap"`
+	Setuju    int    `json:"setuju"`
+	Open      int    `json:"open"` // Rencana + Perbaikan: claims waiting on the coder
+	Total     int    `json:"total"`
+}
+
+// Add counts n claims of status into the workload.
+func (w *UserWorkload) Add(status ClaimStatus, n int) {
+	switch status.Normalize() {
+	case StatusRencana:
+		w.Rencana += n
+		w.Open += n
+	case StatusPengajuan:
+		w.Pengajuan += n
+	case StatusPerbaikan:
+		w.Perbaikan += n
+		w.Open += n
+	case StatusLengkap:
+		w.Lengkap += n
+	case StatusSetuju:
+		w.Setuju += n
+	}
+	w.Total += n
+}
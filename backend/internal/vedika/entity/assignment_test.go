@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAssignmentConfigPool(t *testing.T) {
+	byJenis := AssignmentConfig{
+		GroupBy: AssignByJenis,
+		Pools:   map[string][]string{"ralan": {"u1", "u2"}, "ranap": {}},
+		Default: []string{"u9"},
+	}
+	byUnit := AssignmentConfig{
+		GroupBy: AssignByUnit,
+		Pools:   map[string][]string{"INT": {"u3"}},
+	}
+
+	tests := []struct {
+		name string
+		cfg  AssignmentConfig
+		e    AssignmentCandidate
+		want []string
+	}{
+		{"jenis pool", byJenis, AssignmentCandidate{Jenis: "ralan", KdUnit: "INT"}, []string{"u1", "u2"}},
+		{"empty pool falls back to default", byJenis, AssignmentCandidate{Jenis: "ranap"}, []string{"u9"}},
+		{"unit pool", byUnit, AssignmentCandidate{Jenis: "ralan", KdUnit: "INT"}, []string{"u3"}},
+		{"unit ignores jenis", byUnit, AssignmentCandidate{Jenis: "INT", KdUnit: "OBG"}, nil},
+	}
+	for _, tt := range tests {
+		if got := tt.cfg.Pool(tt.e); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUserWorkloadAdd(t *testing.T) {
+	var w UserWorkload
+	w.Add(StatusRencana, 2)
+	w.Add(ClaimStatus("PERBAIKAN"), 1)
+	w.Add(StatusPengajuan, 4)
+	w.Add(StatusLengkap, 3)
+	w.Add(StatusSetuju, 1)
+
+	want := UserWorkload{Rencana: 2, Perbaikan: 1, Pengajuan: 4, Lengkap: 3, Setuju: 1, Open: 3, Total: 11}
+	if w != want {
+		t.Fatalf("workload %+v, want %+v", w, want)
+	}
+}
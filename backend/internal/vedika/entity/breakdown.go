@@ -0,0 +1,84 @@
+package entity
+
+// Dashboard breakdown groupings.
+const (
+	BreakdownByUnit      = "unit" // Poli for ralan, last bangsal for ranap
+	BreakdownByDPJP      = "dpjp"
+	BreakdownByCarabayar = "carabayar" // penjab.kd_pj
+)
+
+// IsValidBreakdownGroup reports whether g is a supported grouping.
+func IsValidBreakdownGroup(g string) bool {
+	switch g {
+	case BreakdownByUnit, BreakdownByDPJP, BreakdownByCarabayar:
+		return true
+	}
+	return false
+}
+
+// BreakdownFilter selects the episodes of a dashboard breakdown.
+type BreakdownFilter struct {
+	Range     DashboardRange
+	Carabayar []string // penjab.kd_pj
+	GroupBy   string
+}
+
+// BreakdownCount is the number of episodes of one group, jenis and status.
+// Status is StatusRencana for episodes not yet in mlite_vedika.
+type BreakdownCount struct {
+	Key    string
+	Label  string
+	Jenis  JenisPelayanan
+	Status ClaimStatus
+	Count  int
+}
+
+// BreakdownGroup holds the dashboard counts of one group. Jenis is set when
+// grouping by unit, since poli and bangsal codes are separate.
+type BreakdownGroup struct {
+	Key   string         `json:"key"`
+	Label string         `json:"label"`
+	Jenis JenisPelayanan `json:"jenis,omitempty"`
+	DashboardCounts
+	Belum  ClaimCount `json:"belum"` // Episodes not yet in mlite_vedika
+	Setuju ClaimCount `json:"setuju"`
+}
+
+// Add accumulates n episodes of a jenis and status into the group.
+// Unknown statuses are skipped, as on the dashboard cards.
+func (g *BreakdownGroup) Add(jenis JenisPelayanan, status ClaimStatus, n int) {
+	var count *ClaimCount
+	switch status.Normalize() {
+	case StatusRencana:
+		count = &g.Belum
+	case StatusPengajuan:
+		count = &g.Pengajuan
+	case StatusLengkap:
+		count = &g.Lengkap
+	case StatusPerbaikan:
+		count = &g.Perbaikan
+	case StatusSetuju:
+		count = &g.Setuju
+	default:
+		return
+	}
+	if jenis == JenisRanap {
+		count.Ranap += n
+	} else {
+		count.Ralan += n
+	}
+}
+
+// Finish derives rencana and maturasi once all counts are added.
+func (g *BreakdownGroup) Finish() {
+	g.DashboardCounts = NewDashboardCounts(g.Belum, g.Pengajuan, g.Lengkap, g.Perbaikan, g.Setuju)
+}
+
+// DashboardBreakdown is the dashboard counts of a range grouped by unit,
+// DPJP or carabayar.
+type DashboardBreakdown struct {
+	DashboardRange
+	GroupBy string           `json:"group_by"`
+	Groups  []BreakdownGroup `json:"groups"`
+	Total   BreakdownGroup   `json:"total"`
+}
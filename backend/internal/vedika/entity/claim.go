@@ -73,12 +73,10 @@ func (s ClaimStatus) Normalize() ClaimStatus {
 
 // DashboardSummary contains summary card data for the dashboard.
 type DashboardSummary struct {
-	Period    string         `json:"period"`
-	Rencana   ClaimCount     `json:"rencana"`
-	Pengajuan ClaimCount     `json:"pengajuan"`
-	Lengkap   ClaimCount     `json:"lengkap"`
-	Perbaikan ClaimCount     `json:"perbaikan"`
-	Maturasi  MaturasiPersen `json:"maturasi"`
+	DashboardRange
+	DashboardCounts
+	Workload   []UserWorkload       `json:"workload"`             // Assigned claims per coder, by service date
+	Comparison *DashboardComparison `json:"comparison,omitempty"` // Same range one month earlier
 }
 
 // ClaimCount holds counts split by Ralan and Ranap.
@@ -119,18 +117,38 @@ type ClaimEpisode struct {
 
 	// Claim Status
 	Status ClaimStatus `json:"status"`
+
+	// Assigned coder (mera_users.username), empty when unassigned
+	AssignedTo string `json:"assigned_to"`
+
+	// Age against the SLA setting, set on index lists
+	Aging *ClaimAging `json:"aging,omitempty"`
+}
+
+// IndexExportRow is a ClaimEpisode enriched with coding and billing data
+// for the index export.
+type IndexExportRow struct {
+	ClaimEpisode
+
+	NoSEP            string   `json:"no_sep"`
+	NoKartu          string   `json:"no_kartu"`
+	DiagnosaUtama    string   `json:"diagnosa_utama"`
+	DiagnosaSekunder []string `json:"diagnosa_sekunder"`
+	Prosedur         []string `json:"prosedur"`
+	TotalBilling     float64  `json:"total_billing"` // mlite_billing.jumlah_total, else SUM(billing.totalbiaya)
 }
 
 // IndexFilter contains filter parameters for Index workbench.
 // Uses explicit date range, NOT active_period.
 type IndexFilter struct {
-	DateFrom string         `json:"date_from"` // Required, YYYY-MM-DD
-	DateTo   string         `json:"date_to"`   // Required, YYYY-MM-DD
-	Status   ClaimStatus    `json:"status"`    // Required
-	Jenis    JenisPelayanan `json:"jenis"`     // Optional, ralan or ranap
-	Search   string         `json:"search"`    // Optional
-	Page     int            `json:"page"`
-	Limit    int            `json:"limit"`
+	DateFrom   string         `json:"date_from"`   // Required, YYYY-MM-DD
+	DateTo     string         `json:"date_to"`     // Required, YYYY-MM-DD
+	Status     ClaimStatus    `json:"status"`      // Required
+	Jenis      JenisPelayanan `json:"jenis"`       // Optional, ralan or ranap
+	Search     string         `json:"search"`      // Optional
+	AssignedTo string         `json:"assigned_to"` // Optional, mera_users.id of the assigned coder ("my queue")
+	Page       int            `json:"page"`
+	Limit      int            `json:"limit"`
 }
 
 // ClaimDetail contains full claim context for detail view.
@@ -163,6 +181,12 @@ type ClaimDetail struct {
 	// Status
 	Status ClaimStatus `json:"status"`
 
+	// Medical data version, sent back in If-Match when editing
+	Version string `json:"version"`
+
+	// Coder currently editing the claim; nil when free
+	Lock *EditLock `json:"lock"`
+
 	// Configuration
 	LegacyWebAppURL string `json:"legacy_webapp_url,omitempty"`
 }
@@ -214,6 +238,7 @@ type MedicalResume struct {
 	Terapi           string `json:"terapi"`
 	Anjuran          string `json:"anjuran"`
 	DokterPJ         string `json:"dokter_pj"`
+	Version          string `json:"version,omitempty"` // Medical data version (responses only)
 }
 
 // StatusUpdateRequest represents request to update claim status.
@@ -231,8 +256,16 @@ type BatchStatusUpdateRequest struct {
 
 // BatchUpdateResult contains result of batch update operation.
 type BatchUpdateResult struct {
-	Updated int `json:"updated"`
-	Failed  int `json:"failed"`
+	Updated int              `json:"updated"`
+	Failed  int              `json:"failed"`
+	Errors  []BatchItemError `json:"errors,omitempty"`
+}
+
+// BatchItemError explains why a single claim in a batch was not updated.
+type BatchItemError struct {
+	NoRawat string `json:"no_rawat"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
 }
 
 // DiagnosisUpdateRequest represents request to update diagnosis.
@@ -276,6 +309,15 @@ type VedikaSetting struct {
 	IsActive     bool   `json:"is_active"`
 }
 
+// Institution is the hospital identity printed on claim documents.
+type Institution struct {
+	Nama    string `json:"nama"`
+	Alamat  string `json:"alamat"`
+	Kota    string `json:"kota"`
+	Telepon string `json:"telepon"`
+	Email   string `json:"email"`
+}
+
 // Legacy ClaimFilter for dashboard (kept for backward compatibility).
 type ClaimFilter struct {
 	Jenis     JenisPelayanan `json:"jenis"`
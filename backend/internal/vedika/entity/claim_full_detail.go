@@ -28,8 +28,9 @@ type SEPDetail struct {
 	Catatan        string `json:"catatan"`
 	TglRujukan     string `json:"tgl_rujukan"`
 	MasaBerlaku    string `json:"masa_berlaku"`
-	COB            string `json:"cob"`        // Coordination of Benefit
-	PRBStatus      string `json:"prb_status"` // dari bpjs_prb
+	COB            string `json:"cob"`            // Coordination of Benefit
+	PRBStatus      string `json:"prb_status"`     // dari bpjs_prb
+	Live           bool   `json:"live,omitempty"` // fetched from VClaim, not bridging_sep
 }
 
 // =============================================================================
@@ -329,13 +330,15 @@ type MedicalResumeRanap struct {
 
 // BillingItem represents a single billing line item.
 type BillingItem struct {
-	No            int     `json:"no"`
-	NamaPerawatan string  `json:"nama_perawatan"`
-	Pemisah       string  `json:"pemisah"`
-	Biaya         float64 `json:"biaya"`
-	Jumlah        int     `json:"jumlah"`
-	Tambahan      float64 `json:"tambahan"`
-	TotalBiaya    float64 `json:"total_biaya"`
+	No             int     `json:"no"`
+	NamaPerawatan  string  `json:"nama_perawatan"`
+	Pemisah        string  `json:"pemisah"`
+	KdJenisPrw     string  `json:"kd_jenis_prw,omitempty"` // Source tariff code, when known
+	Biaya          float64 `json:"biaya"`
+	Jumlah         int     `json:"jumlah"`
+	Tambahan       float64 `json:"tambahan"`
+	TotalBiaya     float64 `json:"total_biaya"`
+	KategoriINACBG string  `json:"kategori_inacbg"` // INA-CBG cost component, see TarifComponents
 }
 
 // BillingCategory represents a billing category (e.g., Obat, Jasa Dokter).
@@ -356,6 +359,7 @@ type BillingSummary struct {
 	Potongan    float64           `json:"potongan"`
 	JumlahBayar float64           `json:"jumlah_bayar"`
 	Terbilang   string            `json:"terbilang"`
+	TarifINACBG []TarifTotal      `json:"tarif_inacbg"` // Totals per INA-CBG cost component (E-Klaim tarif_rs)
 }
 
 // =============================================================================
@@ -417,8 +421,10 @@ type ClaimFullDetail struct {
 	Billing        *BillingSummary     `json:"billing"`
 	SPRI           *SPRIDetail         `json:"spri"`
 	Documents      []DigitalDocument   `json:"documents"`
+	OpenFeedback   []FeedbackNote      `json:"open_feedback"` // Unresolved correction notes
 
 	// Meta
 	StatusLanjut string      `json:"status_lanjut"` // Ralan / Ranap
 	ClaimStatus  ClaimStatus `json:"claim_status"`
+	Version      string      `json:"version"` // Medical data version, sent back in If-Match when editing
 }
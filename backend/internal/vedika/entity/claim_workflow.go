@@ -0,0 +1,171 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidTransition indicates a claim status change the workflow does not allow.
+var ErrInvalidTransition = errors.New("invalid claim status transition")
+
+// ErrStatusChanged indicates the claim status changed between the workflow
+// check and the write, e.g. by a concurrent request.
+var ErrStatusChanged = errors.New("claim status changed concurrently")
+
+// Permission codes that guard status transitions.
+const (
+	PermUpdateStatus = "vedika.claim.update_status"
+	PermVerify       = "vedika.verify"
+)
+
+// StatusTransition describes a single allowed edge in the claim workflow.
+type StatusTransition struct {
+	From       ClaimStatus `json:"from"`
+	To         ClaimStatus `json:"to"`
+	Permission string      `json:"permission"` // Permission required to take this edge
+}
+
+// claimWorkflow is the Vedika claim transition graph.
+//
+//	Rencana -> Pengajuan -> Lengkap -> Setuju
+//	               |   ^       |
+//	               v   |       v
+//	             Perbaikan <---+
+//
+// Re-open paths: Lengkap -> Pengajuan, Setuju -> Perbaikan (verifier only).
+// Verifiers decide on complete claims only: Lengkap -> Setuju or Lengkap ->
+// Perbaikan. An edge may appear once per permission; holding any of them is
+// enough.
+var claimWorkflow = []StatusTransition{
+	{From: StatusRencana, To: StatusPengajuan, Permission: PermUpdateStatus},
+	{From: StatusPengajuan, To: StatusLengkap, Permission: PermUpdateStatus},
+	{From: StatusPengajuan, To: StatusPerbaikan, Permission: PermUpdateStatus},
+	{From: StatusPerbaikan, To: StatusPengajuan, Permission: PermUpdateStatus},
+	{From: StatusPerbaikan, To: StatusLengkap, Permission: PermUpdateStatus},
+	{From: StatusLengkap, To: StatusPerbaikan, Permission: PermUpdateStatus},
+	{From: StatusLengkap, To: StatusPerbaikan, Permission: PermVerify},
+	{From: StatusLengkap, To: StatusSetuju, Permission: PermVerify},
+
+	// Re-open paths
+	{From: StatusLengkap, To: StatusPengajuan, Permission: PermUpdateStatus},
+	{From: StatusSetuju, To: StatusPerbaikan, Permission: PermVerify},
+}
+
+// FindTransition returns the workflow edge from -> to, or nil if none exists.
+func FindTransition(from, to ClaimStatus) *StatusTransition {
+	from, to = from.Normalize(), to.Normalize()
+	for i := range claimWorkflow {
+		if claimWorkflow[i].From == from && claimWorkflow[i].To == to {
+			t := claimWorkflow[i]
+			return &t
+		}
+	}
+	return nil
+}
+
+// AllowedTransitions returns every edge leaving the given status.
+func AllowedTransitions(from ClaimStatus) []StatusTransition {
+	from = from.Normalize()
+	result := []StatusTransition{}
+	for _, t := range claimWorkflow {
+		if t.From == from {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// CheckTransition validates a status change against the workflow graph and the
+// permissions granted to the actor. Returns a *TransitionError on rejection.
+func CheckTransition(noRawat string, from, to ClaimStatus, granted map[string]bool) error {
+	from, to = from.Normalize(), to.Normalize()
+
+	t := FindTransition(from, to)
+	if t == nil {
+		return &TransitionError{
+			NoRawat: noRawat,
+			From:    from,
+			To:      to,
+			Allowed: AllowedTransitions(from),
+		}
+	}
+
+	for _, edge := range claimWorkflow {
+		if edge.From == from && edge.To == to && granted[edge.Permission] {
+			return nil
+		}
+	}
+
+	return &TransitionError{
+		NoRawat:            noRawat,
+		From:               from,
+		To:                 to,
+		RequiredPermission: t.Permission,
+	}
+}
+
+// TransitionError describes a rejected claim status change.
+type TransitionError struct {
+	NoRawat            string             `json:"no_rawat"`
+	From               ClaimStatus        `json:"from"`
+	To                 ClaimStatus        `json:"to"`
+	RequiredPermission string             `json:"required_permission,omitempty"` // Set when the edge exists but the actor lacks permission
+	Allowed            []StatusTransition `json:"allowed,omitempty"`             // Set when the edge does not exist
+	OpenFeedback       int                `json:"open_feedback,omitempty"`       // Set when unresolved notes block leaving Perbaikan
+	Incomplete         []ChecklistItem    `json:"incomplete,omitempty"`          // Set when mandatory checklist items block Lengkap
+}
+
+// Error implements error.
+func (e *TransitionError) Error() string {
+	if len(e.Incomplete) > 0 {
+		return fmt.Sprintf("%s: %s -> %s blocked by %d incomplete checklist items", ErrInvalidTransition, e.From, e.To, len(e.Incomplete))
+	}
+	if e.OpenFeedback > 0 {
+		return fmt.Sprintf("%s: %s -> %s blocked by %d unresolved feedback notes", ErrInvalidTransition, e.From, e.To, e.OpenFeedback)
+	}
+	if e.RequiredPermission != "" {
+		return fmt.Sprintf("%s: %s -> %s requires %s", ErrInvalidTransition, e.From, e.To, e.RequiredPermission)
+	}
+	return fmt.Sprintf("%s: %s -> %s", ErrInvalidTransition, e.From, e.To)
+}
+
+// Is reports ErrInvalidTransition so callers can use errors.Is.
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrInvalidTransition
+}
+
+// StatusHistoryEntry is a single recorded status change from mera_vedika_status_history.
+type StatusHistoryEntry struct {
+	ID        string      `json:"id"`
+	NoRawat   string      `json:"no_rawat"`
+	NoSEP     string      `json:"no_sep"`
+	OldStatus ClaimStatus `json:"old_status"`
+	NewStatus ClaimStatus `json:"new_status"`
+	Username  string      `json:"username"`
+	Catatan   string      `json:"catatan,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// StatusTimeline is the full status history of a claim.
+type StatusTimeline struct {
+	NoRawat         string               `json:"no_rawat"`
+	CurrentStatus   ClaimStatus          `json:"current_status"`
+	PerbaikanRounds int                  `json:"perbaikan_rounds"` // Times the claim was returned for correction
+	History         []StatusHistoryEntry `json:"history"`
+}
+
+// NewStatusTimeline builds a timeline from history entries ordered oldest first.
+func NewStatusTimeline(noRawat string, current ClaimStatus, history []StatusHistoryEntry) *StatusTimeline {
+	timeline := &StatusTimeline{
+		NoRawat:       noRawat,
+		CurrentStatus: current.Normalize(),
+		History:       history,
+	}
+	for _, h := range history {
+		if h.NewStatus == StatusPerbaikan {
+			timeline.PerbaikanRounds++
+		}
+	}
+	return timeline
+}
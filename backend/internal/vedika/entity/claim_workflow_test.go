@@ -0,0 +1,135 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+)
+
+var allStatuses = []ClaimStatus{StatusRencana, StatusPengajuan, StatusPerbaikan, StatusLengkap, StatusSetuju}
+
+// wantWorkflow lists every allowed edge with the permissions that may take it.
+var wantWorkflow = map[[2]ClaimStatus][]string{
+	{StatusRencana, StatusPengajuan}:   {PermUpdateStatus},
+	{StatusPengajuan, StatusLengkap}:   {PermUpdateStatus},
+	{StatusPengajuan, StatusPerbaikan}: {PermUpdateStatus},
+	{StatusPerbaikan, StatusPengajuan}: {PermUpdateStatus},
+	{StatusPerbaikan, StatusLengkap}:   {PermUpdateStatus},
+	{StatusLengkap, StatusPerbaikan}:   {PermUpdateStatus, PermVerify},
+	{StatusLengkap, StatusPengajuan}:   {PermUpdateStatus},
+	{StatusLengkap, StatusSetuju}:      {PermVerify},
+	{StatusSetuju, StatusPerbaikan}:    {PermVerify},
+}
+
+func TestCheckTransitionGraph(t *testing.T) {
+	for _, from := range allStatuses {
+		for _, to := range allStatuses {
+			perms, allowed := wantWorkflow[[2]ClaimStatus{from, to}]
+
+			for _, perm := range []string{PermUpdateStatus, PermVerify} {
+				granted := map[string]bool{perm: true}
+				err := CheckTransition("2026/01/01/000001", from, to, granted)
+
+				want := allowed && contains(perms, perm)
+				if want && err != nil {
+					t.Errorf("%s -> %s with %s: error = %v, want allowed", from, to, perm, err)
+				}
+				if !want && !errors.Is(err, ErrInvalidTransition) {
+					t.Errorf("%s -> %s with %s: error = %v, want ErrInvalidTransition", from, to, perm, err)
+				}
+			}
+
+			err := CheckTransition("2026/01/01/000001", from, to, map[string]bool{PermUpdateStatus: true, PermVerify: true})
+			if allowed != (err == nil) {
+				t.Errorf("%s -> %s with all permissions: error = %v, want allowed=%v", from, to, err, allowed)
+			}
+		}
+	}
+}
+
+func TestCheckTransitionErrorDetails(t *testing.T) {
+	// Missing edge: lists the edges leaving the current status
+	err := CheckTransition("2026/01/01/000001", StatusRencana, StatusSetuju, map[string]bool{PermVerify: true})
+	var transitionErr *TransitionError
+	if !errors.As(err, &transitionErr) {
+		t.Fatalf("error = %v, want *TransitionError", err)
+	}
+	if transitionErr.RequiredPermission != "" || len(transitionErr.Allowed) != 1 || transitionErr.Allowed[0].To != StatusPengajuan {
+		t.Fatalf("missing edge error = %+v, want Allowed [Pengajuan]", transitionErr)
+	}
+
+	// Existing edge without permission: names the permission
+	err = CheckTransition("2026/01/01/000001", StatusLengkap, StatusSetuju, map[string]bool{PermUpdateStatus: true})
+	if !errors.As(err, &transitionErr) || transitionErr.RequiredPermission != PermVerify {
+		t.Fatalf("error = %v, want RequiredPermission %s", err, PermVerify)
+	}
+
+	// Verifiers cannot decide on a claim that has not been marked Lengkap
+	err = CheckTransition("2026/01/01/000001", StatusPengajuan, StatusSetuju, map[string]bool{PermVerify: true})
+	if !errors.As(err, &transitionErr) || transitionErr.RequiredPermission != "" {
+		t.Fatalf("Pengajuan -> Setuju error = %v, want missing edge", err)
+	}
+	err = CheckTransition("2026/01/01/000001", StatusPengajuan, StatusPerbaikan, map[string]bool{PermVerify: true})
+	if !errors.As(err, &transitionErr) || transitionErr.RequiredPermission != PermUpdateStatus {
+		t.Fatalf("Pengajuan -> Perbaikan error = %v, want RequiredPermission %s", err, PermUpdateStatus)
+	}
+}
+
+func TestCheckTransitionNormalizesStatus(t *testing.T) {
+	if err := CheckTransition("2026/01/01/000001", "PENGAJUAN", "lengkap", map[string]bool{PermUpdateStatus: true}); err != nil {
+		t.Fatalf("error = %v, want upper/lower case statuses accepted", err)
+	}
+}
+
+func TestAllowedTransitions(t *testing.T) {
+	for _, from := range allStatuses {
+		want := map[ClaimStatus]bool{}
+		for edge := range wantWorkflow {
+			if edge[0] == from {
+				want[edge[1]] = true
+			}
+		}
+		got := map[ClaimStatus]bool{}
+		for _, edge := range AllowedTransitions(from) {
+			got[edge.To] = true
+		}
+		if len(got) != len(want) {
+			t.Errorf("AllowedTransitions(%s) = %v, want %v", from, got, want)
+		}
+		for to := range want {
+			if !got[to] {
+				t.Errorf("AllowedTransitions(%s) misses %s", from, to)
+			}
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestNewStatusTimeline(t *testing.T) {
+	history := []StatusHistoryEntry{
+		{ID: "a", OldStatus: StatusRencana, NewStatus: StatusPengajuan},
+		{ID: "b", OldStatus: StatusPengajuan, NewStatus: StatusPerbaikan},
+		{ID: "c", OldStatus: StatusPerbaikan, NewStatus: StatusPengajuan},
+		{ID: "d", OldStatus: StatusPengajuan, NewStatus: StatusPerbaikan},
+	}
+	timeline := NewStatusTimeline("2026/01/01/000001", ClaimStatus("perbaikan"), history)
+
+	if timeline.CurrentStatus != StatusPerbaikan {
+		t.Fatalf("current status %s, want normalized Perbaikan", timeline.CurrentStatus)
+	}
+	if timeline.PerbaikanRounds != 2 {
+		t.Fatalf("perbaikan rounds %d, want 2", timeline.PerbaikanRounds)
+	}
+	for i, id := range []string{"a", "b", "c", "d"} {
+		if timeline.History[i].ID != id {
+			t.Fatalf("history reordered: entry %d is %s, want %s", i, timeline.History[i].ID, id)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// Coding issue severities. Errors block saving; warnings are returned with
+// the saved result.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// CodingConfig configures the coding validator (vedika.coding).
+type CodingConfig struct {
+	// ICD-10 codes or code prefixes BPJS does not accept as primary diagnosis
+	UnacceptablePrimary []string `json:"unacceptable_primary"`
+}
+
+// CodingPatient holds the patient facts coding rules are checked against.
+type CodingPatient struct {
+	JenisKelamin  string    `json:"jenis_kelamin"` // L / P
+	TglLahir      time.Time `json:"tgl_lahir"`
+	TglRegistrasi time.Time `json:"tgl_registrasi"`
+	StatusLanjut  string    `json:"status_lanjut"`
+}
+
+// AgeDays returns the age at registration in days, or -1 when unknown.
+func (p *CodingPatient) AgeDays() int {
+	if p.TglLahir.IsZero() || p.TglRegistrasi.IsZero() || p.TglRegistrasi.Before(p.TglLahir) {
+		return -1
+	}
+	return int(p.TglRegistrasi.Sub(p.TglLahir).Hours() / 24)
+}
+
+// CodingIssue is a rule violation on one submitted line.
+type CodingIssue struct {
+	Line     int    `json:"line"` // Zero-based index in the request, -1 for the whole set
+	Kode     string `json:"kode,omitempty"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// CodingValidation is the outcome of validating a diagnosis or procedure set.
+type CodingValidation struct {
+	Valid    bool          `json:"valid"` // No blocking errors
+	Errors   []CodingIssue `json:"errors"`
+	Warnings []CodingIssue `json:"warnings"`
+}
+
+// NewCodingValidation returns an empty, valid result.
+func NewCodingValidation() *CodingValidation {
+	return &CodingValidation{Valid: true, Errors: []CodingIssue{}, Warnings: []CodingIssue{}}
+}
+
+// Add records an issue under its severity.
+func (v *CodingValidation) Add(issue CodingIssue) {
+	if issue.Severity == SeverityError {
+		v.Valid = false
+		v.Errors = append(v.Errors, issue)
+		return
+	}
+	v.Warnings = append(v.Warnings, issue)
+}
+
+// CodingError rejects a diagnosis or procedure set with blocking issues.
+type CodingError struct {
+	NoRawat    string            `json:"no_rawat"`
+	Kind       string            `json:"kind"` // diagnosis / procedure
+	Validation *CodingValidation `json:"validation"`
+}
+
+// Error implements error.
+func (e *CodingError) Error() string {
+	return fmt.Sprintf("%s coding of %s rejected: %d errors", e.Kind, e.NoRawat, len(e.Validation.Errors))
+}
@@ -0,0 +1,201 @@
+package entity
+
+import "strings"
+
+// Completeness check keys. Required documents are reported as
+// CheckDocumentPrefix + master_berkas_digital.kode.
+const (
+	CheckSEP            = "sep"
+	CheckDiagnosaUtama  = "diagnosa_utama"
+	CheckResume         = "resume"
+	CheckLaporanOperasi = "laporan_operasi"
+	CheckSPRI           = "spri"
+	CheckDocumentPrefix = "document:"
+)
+
+// CompletenessConfig configures the completeness checker (vedika.completeness).
+type CompletenessConfig struct {
+	RequiredDocumentsRalan []string `json:"required_documents_ralan"` // master_berkas_digital.kode
+	RequiredDocumentsRanap []string `json:"required_documents_ranap"`
+	Advisory               []string `json:"advisory"` // Check keys reported without blocking Lengkap
+}
+
+// ChecklistItem is one line of the "kelengkapan berkas" checklist.
+type ChecklistItem struct {
+	Key       string `json:"key"`
+	Label     string `json:"label"`
+	Mandatory bool   `json:"mandatory"`
+	Passed    bool   `json:"passed"`
+	Message   string `json:"message,omitempty"` // Why the item failed
+}
+
+// CompletenessReport is the evaluated checklist of a claim.
+type CompletenessReport struct {
+	NoRawat      string          `json:"no_rawat"`
+	StatusLanjut string          `json:"status_lanjut"`
+	Complete     bool            `json:"complete"` // Every mandatory item passed
+	Items        []ChecklistItem `json:"items"`
+}
+
+// FailedMandatory returns the mandatory items that did not pass.
+func (r *CompletenessReport) FailedMandatory() []ChecklistItem {
+	failed := []ChecklistItem{}
+	for _, item := range r.Items {
+		if item.Mandatory && !item.Passed {
+			failed = append(failed, item)
+		}
+	}
+	return failed
+}
+
+// completenessRule is a single check of the completeness engine.
+type completenessRule struct {
+	Key     string
+	Label   string
+	Applies func(d *ClaimFullDetail) bool   // nil applies to every claim
+	Check   func(d *ClaimFullDetail) string // Failure message, "" when passed
+}
+
+// completenessRules are the BPJS requirements every claim is checked against,
+// before the configured document categories.
+var completenessRules = []completenessRule{
+	{
+		Key:   CheckSEP,
+		Label: "SEP tersedia",
+		Check: func(d *ClaimFullDetail) string {
+			if d.SEP == nil || d.SEP.NoSEP == "" {
+				return "Episode belum memiliki SEP"
+			}
+			return ""
+		},
+	},
+	{
+		Key:   CheckDiagnosaUtama,
+		Label: "Diagnosa utama (ICD-10) terisi",
+		Check: func(d *ClaimFullDetail) string {
+			for _, dx := range d.Diagnoses {
+				if dx.Prioritas == 1 && dx.KodePenyakit != "" {
+					return ""
+				}
+			}
+			return "Belum ada diagnosa dengan prioritas 1"
+		},
+	},
+	{
+		Key:   CheckResume,
+		Label: "Resume medis ditandatangani DPJP",
+		Check: func(d *ClaimFullDetail) string {
+			var kdDokter, diagnosa string
+			if d.IsRanap() {
+				if d.ResumeRanap == nil {
+					return "Resume medis rawat inap belum dibuat"
+				}
+				kdDokter, diagnosa = d.ResumeRanap.KdDokter, d.ResumeRanap.DiagnosaUtama
+			} else {
+				if d.ResumeRalan == nil {
+					return "Resume medis rawat jalan belum dibuat"
+				}
+				kdDokter, diagnosa = d.ResumeRalan.KdDokter, d.ResumeRalan.DiagnosaUtama
+			}
+			if strings.TrimSpace(diagnosa) == "" {
+				return "Diagnosa utama pada resume belum diisi"
+			}
+			if strings.TrimSpace(kdDokter) == "" {
+				return "Resume belum ditandatangani DPJP"
+			}
+			return ""
+		},
+	},
+	{
+		Key:     CheckLaporanOperasi,
+		Label:   "Laporan operasi",
+		Applies: func(d *ClaimFullDetail) bool { return len(d.Operations) > 0 },
+		Check: func(d *ClaimFullDetail) string {
+			for _, r := range d.OpReports {
+				if strings.TrimSpace(r.LaporanOperasi) != "" {
+					return ""
+				}
+			}
+			return "Episode memiliki tindakan operasi tanpa laporan operasi"
+		},
+	},
+	{
+		Key:     CheckSPRI,
+		Label:   "Surat Perintah Rawat Inap (SPRI)",
+		Applies: func(d *ClaimFullDetail) bool { return d.IsRanap() },
+		Check: func(d *ClaimFullDetail) string {
+			if d.SPRI == nil || d.SPRI.NoSurat == "" {
+				return "SPRI rawat inap belum diterbitkan"
+			}
+			return ""
+		},
+	},
+}
+
+// IsRanap reports whether the claim is an inpatient episode.
+func (d *ClaimFullDetail) IsRanap() bool {
+	return strings.EqualFold(d.StatusLanjut, "Ranap")
+}
+
+// CheckCompleteness evaluates a claim against the built-in rules and the
+// document categories required for its care type. categoryNames maps
+// master_berkas_digital.kode to nama for the checklist labels.
+func CheckCompleteness(noRawat string, d *ClaimFullDetail, cfg CompletenessConfig, categoryNames map[string]string) *CompletenessReport {
+	advisory := make(map[string]bool, len(cfg.Advisory))
+	for _, key := range cfg.Advisory {
+		advisory[key] = true
+	}
+
+	report := &CompletenessReport{
+		NoRawat:      noRawat,
+		StatusLanjut: d.StatusLanjut,
+		Complete:     true,
+		Items:        []ChecklistItem{},
+	}
+	add := func(key, label, message string) {
+		item := ChecklistItem{
+			Key:       key,
+			Label:     label,
+			Mandatory: !advisory[key],
+			Passed:    message == "",
+			Message:   message,
+		}
+		if item.Mandatory && !item.Passed {
+			report.Complete = false
+		}
+		report.Items = append(report.Items, item)
+	}
+
+	for _, rule := range completenessRules {
+		if rule.Applies != nil && !rule.Applies(d) {
+			continue
+		}
+		add(rule.Key, rule.Label, rule.Check(d))
+	}
+
+	required := cfg.RequiredDocumentsRalan
+	if d.IsRanap() {
+		required = cfg.RequiredDocumentsRanap
+	}
+	uploaded := make(map[string]string, len(d.Documents))
+	for _, doc := range d.Documents {
+		uploaded[doc.Kode] = doc.Kategori
+	}
+	for _, kode := range required {
+		kategori, ok := uploaded[kode]
+		label := categoryNames[kode]
+		if label == "" {
+			label = kategori
+		}
+		if label == "" {
+			label = "Berkas " + kode
+		}
+		message := ""
+		if !ok {
+			message = label + " belum diunggah"
+		}
+		add(CheckDocumentPrefix+kode, label, message)
+	}
+
+	return report
+}
@@ -0,0 +1,163 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+// completeRalan returns an outpatient claim that passes every built-in rule.
+func completeRalan() *ClaimFullDetail {
+	return &ClaimFullDetail{
+		SEP:          &SEPDetail{NoSEP: "0301R0010126V000001"},
+		Diagnoses:    []DiagnosisItem{{KodePenyakit: "I10", Prioritas: 1}},
+		ResumeRalan:  &MedicalResumeRalan{KdDokter: "D001", DiagnosaUtama: "Hipertensi"},
+		StatusLanjut: "Ralan",
+	}
+}
+
+// completeRanap returns an inpatient claim that passes every built-in rule.
+func completeRanap() *ClaimFullDetail {
+	d := completeRalan()
+	d.StatusLanjut = "Ranap"
+	d.ResumeRalan = nil
+	d.ResumeRanap = &MedicalResumeRanap{KdDokter: "D001", DiagnosaUtama: "Hipertensi"}
+	d.SPRI = &SPRIDetail{NoSurat: "SPRI/001"}
+	return d
+}
+
+func failedKeys(r *CompletenessReport) []string {
+	keys := []string{}
+	for _, item := range r.FailedMandatory() {
+		keys = append(keys, item.Key)
+	}
+	return keys
+}
+
+func TestCheckCompletenessRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		detail func() *ClaimFullDetail
+		failed []string
+	}{
+		{"complete ralan", completeRalan, []string{}},
+		{"complete ranap", completeRanap, []string{}},
+		{"no SEP", func() *ClaimFullDetail {
+			d := completeRalan()
+			d.SEP = nil
+			return d
+		}, []string{CheckSEP}},
+		{"only secondary diagnosis", func() *ClaimFullDetail {
+			d := completeRalan()
+			d.Diagnoses = []DiagnosisItem{{KodePenyakit: "E11", Prioritas: 2}}
+			return d
+		}, []string{CheckDiagnosaUtama}},
+		{"resume not signed", func() *ClaimFullDetail {
+			d := completeRalan()
+			d.ResumeRalan.KdDokter = " "
+			return d
+		}, []string{CheckResume}},
+		{"ranap uses the ranap resume", func() *ClaimFullDetail {
+			d := completeRanap()
+			d.ResumeRalan = &MedicalResumeRalan{KdDokter: "D001", DiagnosaUtama: "Hipertensi"}
+			d.ResumeRanap = nil
+			return d
+		}, []string{CheckResume}},
+		{"ranap without SPRI", func() *ClaimFullDetail {
+			d := completeRanap()
+			d.SPRI = &SPRIDetail{}
+			return d
+		}, []string{CheckSPRI}},
+		{"operation without report", func() *ClaimFullDetail {
+			d := completeRalan()
+			d.Operations = []OperationItem{{KodePaket: "OP01"}}
+			d.OpReports = []OperationReport{{LaporanOperasi: "  "}}
+			return d
+		}, []string{CheckLaporanOperasi}},
+		{"operation with report", func() *ClaimFullDetail {
+			d := completeRalan()
+			d.Operations = []OperationItem{{KodePaket: "OP01"}}
+			d.OpReports = []OperationReport{{LaporanOperasi: "Appendektomi"}}
+			return d
+		}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := CheckCompleteness("2026/01/01/000001", tt.detail(), CompletenessConfig{}, nil)
+			if got := failedKeys(report); !reflect.DeepEqual(got, tt.failed) {
+				t.Fatalf("failed %v, want %v", got, tt.failed)
+			}
+			if report.Complete != (len(tt.failed) == 0) {
+				t.Fatalf("complete = %v with failed %v", report.Complete, tt.failed)
+			}
+		})
+	}
+}
+
+func TestCheckCompletenessOnlyAppliesRelevantRules(t *testing.T) {
+	report := CheckCompleteness("2026/01/01/000001", completeRalan(), CompletenessConfig{}, nil)
+	for _, item := range report.Items {
+		if item.Key == CheckSPRI || item.Key == CheckLaporanOperasi {
+			t.Errorf("%s checked on an outpatient claim without operations", item.Key)
+		}
+	}
+}
+
+func TestCheckCompletenessAdvisoryDoesNotBlock(t *testing.T) {
+	d := completeRanap()
+	d.SPRI = nil
+	cfg := CompletenessConfig{Advisory: []string{CheckSPRI}}
+
+	report := CheckCompleteness("2026/01/01/000001", d, cfg, nil)
+	if !report.Complete {
+		t.Fatalf("advisory failure blocked completeness: %v", failedKeys(report))
+	}
+	var found bool
+	for _, item := range report.Items {
+		if item.Key == CheckSPRI {
+			found = true
+			if item.Mandatory || item.Passed || item.Message == "" {
+				t.Fatalf("advisory item %+v, want a failed, non-mandatory item with a message", item)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("advisory item missing from the checklist")
+	}
+}
+
+func TestCheckCompletenessRequiredDocuments(t *testing.T) {
+	cfg := CompletenessConfig{
+		RequiredDocumentsRalan: []string{"001"},
+		RequiredDocumentsRanap: []string{"001", "002", "003"},
+	}
+	names := map[string]string{"001": "Resume Medis"}
+
+	d := completeRanap()
+	d.Documents = []DigitalDocument{{Kode: "001", Kategori: "resume"}, {Kode: "002", Kategori: "Hasil Lab"}}
+	report := CheckCompleteness("2026/01/01/000001", d, cfg, names)
+	if got, want := failedKeys(report), []string{CheckDocumentPrefix + "003"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ranap failed %v, want %v", got, want)
+	}
+
+	labels := map[string]string{}
+	for _, item := range report.Items {
+		labels[item.Key] = item.Label
+	}
+	// Configured name first, then the uploaded category, then the kode
+	for key, want := range map[string]string{
+		CheckDocumentPrefix + "001": "Resume Medis",
+		CheckDocumentPrefix + "002": "Hasil Lab",
+		CheckDocumentPrefix + "003": "Berkas 003",
+	} {
+		if labels[key] != want {
+			t.Errorf("label of %s = %q, want %q", key, labels[key], want)
+		}
+	}
+
+	// Outpatient claims only need the ralan list
+	d = completeRalan()
+	d.Documents = []DigitalDocument{{Kode: "001"}}
+	if report := CheckCompleteness("2026/01/01/000002", d, cfg, names); !report.Complete {
+		t.Fatalf("ralan failed %v, want complete", failedKeys(report))
+	}
+}
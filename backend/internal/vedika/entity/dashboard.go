@@ -0,0 +1,162 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxDashboardDays caps a custom dashboard date range.
+const MaxDashboardDays = 366
+
+// DashboardRange is the inclusive date range (YYYY-MM-DD) the dashboard
+// counts. Period (YYYY-MM) is set when the range is one whole month.
+// Ralan episodes fall in the range by registration date, ranap episodes by
+// discharge date.
+type DashboardRange struct {
+	Period   string `json:"period,omitempty"`
+	DateFrom string `json:"date_from"`
+	DateTo   string `json:"date_to"`
+}
+
+// PeriodRange returns the range of a whole month (YYYY-MM).
+func PeriodRange(period string) (DashboardRange, error) {
+	start, err := time.Parse("2006-01", period)
+	if err != nil {
+		return DashboardRange{}, fmt.Errorf("invalid period %q: %w", period, err)
+	}
+	return monthRange(start), nil
+}
+
+// NewDashboardRange returns the range between two dates (YYYY-MM-DD), at
+// most MaxDashboardDays long. A range covering exactly one month gets its
+// Period.
+func NewDashboardRange(dateFrom, dateTo string) (DashboardRange, error) {
+	from, err := time.Parse("2006-01-02", dateFrom)
+	if err != nil {
+		return DashboardRange{}, errors.New("date_from must be YYYY-MM-DD")
+	}
+	to, err := time.Parse("2006-01-02", dateTo)
+	if err != nil {
+		return DashboardRange{}, errors.New("date_to must be YYYY-MM-DD")
+	}
+	if to.Before(from) {
+		return DashboardRange{}, errors.New("date_to must not be before date_from")
+	}
+	if to.After(from.AddDate(0, 0, MaxDashboardDays-1)) {
+		return DashboardRange{}, fmt.Errorf("date range must not exceed %d days", MaxDashboardDays)
+	}
+
+	if from.Day() == 1 && to.Equal(from.AddDate(0, 1, -1)) {
+		return monthRange(from), nil
+	}
+	return DashboardRange{DateFrom: dateFrom, DateTo: dateTo}, nil
+}
+
+// monthRange returns the range of the month starting at start.
+func monthRange(start time.Time) DashboardRange {
+	return DashboardRange{
+		Period:   start.Format("2006-01"),
+		DateFrom: start.Format("2006-01-02"),
+		DateTo:   start.AddDate(0, 1, -1).Format("2006-01-02"),
+	}
+}
+
+// PreviousMonth returns the same range one month earlier, for
+// month-over-month comparison. A whole month maps to the previous whole
+// month; other dates keep their day, clamped to the end of a shorter month.
+func (r DashboardRange) PreviousMonth() DashboardRange {
+	from, _ := time.Parse("2006-01-02", r.DateFrom)
+	if r.Period != "" {
+		return monthRange(from.AddDate(0, -1, 0))
+	}
+	to, _ := time.Parse("2006-01-02", r.DateTo)
+	return DashboardRange{
+		DateFrom: previousMonthDay(from).Format("2006-01-02"),
+		DateTo:   previousMonthDay(to).Format("2006-01-02"),
+	}
+}
+
+// previousMonthDay returns the same day of the previous month, or its last
+// day when the month is shorter (31 March -> 28/29 February).
+func previousMonthDay(t time.Time) time.Time {
+	first := time.Date(t.Year(), t.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1)
+	if t.Day() > last.Day() {
+		return last
+	}
+	return first.AddDate(0, 0, t.Day()-1)
+}
+
+// Label returns the period, or the date range for a custom range.
+func (r DashboardRange) Label() string {
+	if r.Period != "" {
+		return r.Period
+	}
+	return r.DateFrom + " s.d. " + r.DateTo
+}
+
+// DashboardCounts holds the dashboard card counts of one range.
+type DashboardCounts struct {
+	Rencana   ClaimCount     `json:"rencana"`
+	Pengajuan ClaimCount     `json:"pengajuan"`
+	Lengkap   ClaimCount     `json:"lengkap"`
+	Perbaikan ClaimCount     `json:"perbaikan"`
+	Maturasi  MaturasiPersen `json:"maturasi"`
+}
+
+// NewDashboardCounts builds the card counts from per-status counts, where
+// belum counts episodes not yet in mlite_vedika. Rencana is every episode of
+// the range; maturasi is the share already processed (pengajuan, lengkap,
+// perbaikan or setuju).
+func NewDashboardCounts(belum, pengajuan, lengkap, perbaikan, setuju ClaimCount) DashboardCounts {
+	processed := ClaimCount{
+		Ralan: pengajuan.Ralan + lengkap.Ralan + perbaikan.Ralan + setuju.Ralan,
+		Ranap: pengajuan.Ranap + lengkap.Ranap + perbaikan.Ranap + setuju.Ranap,
+	}
+	rencana := ClaimCount{
+		Ralan: belum.Ralan + processed.Ralan,
+		Ranap: belum.Ranap + processed.Ranap,
+	}
+
+	var maturasi MaturasiPersen
+	if rencana.Ralan > 0 {
+		maturasi.Ralan = float64(processed.Ralan) / float64(rencana.Ralan) * 100
+	}
+	if rencana.Ranap > 0 {
+		maturasi.Ranap = float64(processed.Ranap) / float64(rencana.Ranap) * 100
+	}
+
+	return DashboardCounts{
+		Rencana:   rencana,
+		Pengajuan: pengajuan,
+		Lengkap:   lengkap,
+		Perbaikan: perbaikan,
+		Maturasi:  maturasi,
+	}
+}
+
+// Sub returns c minus prev. Maturasi deltas are in percentage points.
+func (c DashboardCounts) Sub(prev DashboardCounts) DashboardCounts {
+	sub := func(a, b ClaimCount) ClaimCount {
+		return ClaimCount{Ralan: a.Ralan - b.Ralan, Ranap: a.Ranap - b.Ranap}
+	}
+	return DashboardCounts{
+		Rencana:   sub(c.Rencana, prev.Rencana),
+		Pengajuan: sub(c.Pengajuan, prev.Pengajuan),
+		Lengkap:   sub(c.Lengkap, prev.Lengkap),
+		Perbaikan: sub(c.Perbaikan, prev.Perbaikan),
+		Maturasi: MaturasiPersen{
+			Ralan: c.Maturasi.Ralan - prev.Maturasi.Ralan,
+			Ranap: c.Maturasi.Ranap - prev.Maturasi.Ranap,
+		},
+	}
+}
+
+// DashboardComparison compares a dashboard with the same range one month
+// earlier. Delta is current minus previous.
+type DashboardComparison struct {
+	DashboardRange
+	Previous DashboardCounts `json:"previous"`
+	Delta    DashboardCounts `json:"delta"`
+}
@@ -0,0 +1,87 @@
+package entity
+
+import "testing"
+
+func TestPeriodRange(t *testing.T) {
+	tests := []struct {
+		period   string
+		from, to string
+	}{
+		{"2026-01", "2026-01-01", "2026-01-31"},
+		{"2024-02", "2024-02-01", "2024-02-29"},
+		{"2026-02", "2026-02-01", "2026-02-28"},
+		{"2026-12", "2026-12-01", "2026-12-31"},
+	}
+	for _, tt := range tests {
+		rng, err := PeriodRange(tt.period)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.period, err)
+		}
+		if rng != (DashboardRange{Period: tt.period, DateFrom: tt.from, DateTo: tt.to}) {
+			t.Errorf("%s: got %+v", tt.period, rng)
+		}
+	}
+	for _, bad := range []string{"", "2026-13", "2026-1", "2026-01-01", "Jan 2026"} {
+		if _, err := PeriodRange(bad); err == nil {
+			t.Errorf("period %q accepted", bad)
+		}
+	}
+}
+
+func TestNewDashboardRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     DashboardRange
+		wantErr  bool
+	}{
+		{"whole month gets its period", "2026-03-01", "2026-03-31", DashboardRange{Period: "2026-03", DateFrom: "2026-03-01", DateTo: "2026-03-31"}, false},
+		{"leap february", "2024-02-01", "2024-02-29", DashboardRange{Period: "2024-02", DateFrom: "2024-02-01", DateTo: "2024-02-29"}, false},
+		{"partial month", "2026-03-01", "2026-03-30", DashboardRange{DateFrom: "2026-03-01", DateTo: "2026-03-30"}, false},
+		{"across months", "2026-01-15", "2026-02-14", DashboardRange{DateFrom: "2026-01-15", DateTo: "2026-02-14"}, false},
+		{"single day", "2026-03-05", "2026-03-05", DashboardRange{DateFrom: "2026-03-05", DateTo: "2026-03-05"}, false},
+		{"longest range", "2026-01-01", "2027-01-01", DashboardRange{DateFrom: "2026-01-01", DateTo: "2027-01-01"}, false},
+		{"one day too long", "2026-01-01", "2027-01-02", DashboardRange{}, true},
+		{"reversed", "2026-03-10", "2026-03-09", DashboardRange{}, true},
+		{"bad date_from", "2026-3-1", "2026-03-31", DashboardRange{}, true},
+		{"bad date_to", "2026-03-01", "2026-02-30", DashboardRange{}, true},
+	}
+	for _, tt := range tests {
+		got, err := NewDashboardRange(tt.from, tt.to)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got error %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDashboardRangePreviousMonth(t *testing.T) {
+	tests := []struct {
+		name string
+		rng  DashboardRange
+		want DashboardRange
+	}{
+		{"whole month", DashboardRange{Period: "2026-03", DateFrom: "2026-03-01", DateTo: "2026-03-31"}, DashboardRange{Period: "2026-02", DateFrom: "2026-02-01", DateTo: "2026-02-28"}},
+		{"across a year", DashboardRange{Period: "2026-01", DateFrom: "2026-01-01", DateTo: "2026-01-31"}, DashboardRange{Period: "2025-12", DateFrom: "2025-12-01", DateTo: "2025-12-31"}},
+		{"custom range", DashboardRange{DateFrom: "2026-05-10", DateTo: "2026-05-20"}, DashboardRange{DateFrom: "2026-04-10", DateTo: "2026-04-20"}},
+		{"clamped to a shorter month", DashboardRange{DateFrom: "2026-03-15", DateTo: "2026-03-31"}, DashboardRange{DateFrom: "2026-02-15", DateTo: "2026-02-28"}},
+		{"clamped to leap february", DashboardRange{DateFrom: "2024-03-30", DateTo: "2024-03-31"}, DashboardRange{DateFrom: "2024-02-29", DateTo: "2024-02-29"}},
+	}
+	for _, tt := range tests {
+		if got := tt.rng.PreviousMonth(); got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDashboardRangeLabel(t *testing.T) {
+	if got := (DashboardRange{Period: "2026-01", DateFrom: "2026-01-01", DateTo: "2026-01-31"}).Label(); got != "2026-01" {
+		t.Errorf("month label %q", got)
+	}
+	if got := (DashboardRange{DateFrom: "2026-01-05", DateTo: "2026-01-20"}).Label(); got != "2026-01-05 s.d. 2026-01-20" {
+		t.Errorf("range label %q", got)
+	}
+}
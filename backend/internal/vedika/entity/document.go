@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// Document formats accepted by the upload pipeline, as detected from content.
+const (
+	DocumentTypePDF  = "pdf"
+	DocumentTypeJPEG = "jpeg"
+	DocumentTypePNG  = "png"
+)
+
+// Upload rule defaults for categories without a mera_vedika_document_rules row.
+const (
+	DefaultDocumentMaxSizeKB = 10240
+	DefaultDocumentTypes     = "pdf,jpeg,png"
+)
+
+// DocumentRule is the upload rule of a master_berkas_digital category.
+type DocumentRule struct {
+	Kode         string   `json:"kode"`
+	Nama         string   `json:"nama"`
+	MaxSizeKB    int      `json:"max_size_kb"`
+	AllowedTypes []string `json:"allowed_types"`  // pdf, jpeg, png
+	ConvertToPDF bool     `json:"convert_to_pdf"` // Store JPEG/PNG scans as single-page PDFs
+}
+
+// MaxBytes returns the size limit in bytes.
+func (r *DocumentRule) MaxBytes() int64 {
+	return int64(r.MaxSizeKB) * 1024
+}
+
+// Allows reports whether the detected document type is accepted.
+func (r *DocumentRule) Allows(docType string) bool {
+	for _, t := range r.AllowedTypes {
+		if t == docType {
+			return true
+		}
+	}
+	return false
+}
+
+// DocumentFile is the upload metadata of a berkas_digital_perawatan file,
+// stored in mera_vedika_documents.
+type DocumentFile struct {
+	ID           string    `json:"id"`
+	NoRawat      string    `json:"no_rawat"`
+	Kode         string    `json:"kode"`
+	LokasiFile   string    `json:"lokasi_file"`
+	SHA256       string    `json:"sha256"` // Of the uploaded content, before conversion
+	OriginalName string    `json:"original_name"`
+	ContentType  string    `json:"content_type"` // Of the stored file
+	Size         int64     `json:"size"`         // Of the stored file
+	Converted    bool      `json:"converted"`
+	UploadedBy   string    `json:"uploaded_by"`
+	UploadedAt   time.Time `json:"uploaded_at"`
+}
+
+// Upload rejection codes.
+const (
+	UploadUnknownCategory = "UNKNOWN_CATEGORY"
+	UploadTooLarge        = "FILE_TOO_LARGE"
+	UploadUnsupportedType = "UNSUPPORTED_FILE_TYPE"
+	UploadCorruptFile     = "CORRUPT_FILE"
+	UploadDuplicate       = "DUPLICATE_DOCUMENT"
+)
+
+// UploadError describes a document upload rejected by validation.
+type UploadError struct {
+	Code         string   `json:"code"`
+	Kode         string   `json:"kode"`
+	Message      string   `json:"message"`
+	DetectedType string   `json:"detected_type,omitempty"` // Sniffed MIME type
+	AllowedTypes []string `json:"allowed_types,omitempty"`
+	MaxSizeKB    int      `json:"max_size_kb,omitempty"`
+	Existing     string   `json:"existing,omitempty"` // lokasi_file of the identical document
+}
+
+// Error implements error.
+func (e *UploadError) Error() string {
+	return fmt.Sprintf("upload rejected (%s): %s", e.Code, e.Message)
+}
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// EditLockTTL is how long a lock lives without a heartbeat. Clients should
+// heartbeat well within it, e.g. every 30 seconds.
+const EditLockTTL = 2 * time.Minute
+
+// ErrEditLockNotHeld indicates a heartbeat or edit without holding the
+// claim's lock; it was never taken, expired or was broken, and the claim
+// must be reopened.
+var ErrEditLockNotHeld = errors.New("edit lock not held")
+
+// EditLock is a soft lock on a claim held by the coder editing it
+// (mera_vedika_edit_lock).
+type EditLock struct {
+	NoRawat    string    `json:"no_rawat"`
+	UserID     string    `json:"user_id"`
+	Username   string    `json:"username"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Active reports whether the lock is still held at now.
+func (l *EditLock) Active(now time.Time) bool {
+	return l != nil && now.Before(l.ExpiresAt)
+}
+
+// ClaimLockedError is returned when another coder holds the claim's lock.
+type ClaimLockedError struct {
+	NoRawat string    `json:"no_rawat"`
+	Holder  *EditLock `json:"holder"`
+}
+
+// Error implements error.
+func (e *ClaimLockedError) Error() string {
+	return fmt.Sprintf("claim %s is locked by %s until %s", e.NoRawat, e.Holder.Username, e.Holder.ExpiresAt.Format(time.RFC3339))
+}
@@ -0,0 +1,53 @@
+package entity
+
+import "time"
+
+// FeedbackNote is a correction note on a claim, stored in mlite_vedika_feedback.
+// Root notes (ParentID nil) carry the resolved flag; replies belong to their root's thread.
+type FeedbackNote struct {
+	ID         int64          `json:"id"`
+	NoSEP      string         `json:"no_sep"`
+	ParentID   *int64         `json:"parent_id,omitempty"`
+	Tanggal    string         `json:"tanggal"`
+	Catatan    string         `json:"catatan"`
+	Username   string         `json:"username"`
+	IsResolved bool           `json:"is_resolved"`
+	ResolvedBy string         `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time     `json:"resolved_at,omitempty"`
+	CreatedAt  *time.Time     `json:"created_at,omitempty"`
+	Replies    []FeedbackNote `json:"replies,omitempty"`
+}
+
+// FeedbackThreads groups all notes of a claim into threads.
+type FeedbackThreads struct {
+	NoRawat   string         `json:"no_rawat"`
+	NoSEP     string         `json:"no_sep"`
+	OpenCount int            `json:"open_count"`
+	Threads   []FeedbackNote `json:"threads"`
+}
+
+// BuildFeedbackThreads nests replies under their root notes.
+// Notes must be ordered oldest first; orphaned replies are promoted to roots.
+func BuildFeedbackThreads(notes []FeedbackNote) []FeedbackNote {
+	index := make(map[int64]int, len(notes))
+	threads := []FeedbackNote{}
+
+	for _, n := range notes {
+		if n.ParentID != nil {
+			if pos, ok := index[*n.ParentID]; ok {
+				threads[pos].Replies = append(threads[pos].Replies, n)
+				continue
+			}
+		}
+		index[n.ID] = len(threads)
+		threads = append(threads, n)
+	}
+
+	return threads
+}
+
+// FeedbackCreateRequest represents request to add a note or reply.
+type FeedbackCreateRequest struct {
+	Catatan  string `json:"catatan" binding:"required"`
+	ParentID *int64 `json:"parent_id"` // Optional, reply to an existing note
+}
@@ -0,0 +1,44 @@
+package entity
+
+import "time"
+
+// InacbgStatus is the E-Klaim bridging state of a claim.
+type InacbgStatus string
+
+const (
+	InacbgGrouped InacbgStatus = "grouped" // set_claim_data + grouper done
+	InacbgFinal   InacbgStatus = "final"   // claim_final done
+	InacbgSent    InacbgStatus = "sent"    // send_claim_individual done
+)
+
+// InacbgGrouping is the stored E-Klaim grouping result of a claim (mera_vedika_inacbg).
+type InacbgGrouping struct {
+	ID             string       `json:"id"`
+	NoRawat        string       `json:"no_rawat"`
+	NoSEP          string       `json:"no_sep"`
+	CBGCode        string       `json:"cbg_code"`
+	CBGDescription string       `json:"cbg_description"`
+	CBGTariff      float64      `json:"cbg_tariff"`
+	SpecialCMG     []string     `json:"special_cmg"`
+	TotalTariff    float64      `json:"total_tariff"` // CBG + sub-acute + chronic + special CMG
+	TarifRS        float64      `json:"tarif_rs"`     // Hospital tariff sent in set_claim_data
+	KelasRawat     string       `json:"kelas_rawat"`
+	INACBGVersion  string       `json:"inacbg_version"`
+	Status         InacbgStatus `json:"status"`
+	CoderNIK       string       `json:"coder_nik"`
+	GroupedBy      string       `json:"grouped_by"`
+	GroupedAt      time.Time    `json:"grouped_at"`
+	FinalizedAt    *time.Time   `json:"finalized_at,omitempty"`
+	SentAt         *time.Time   `json:"sent_at,omitempty"`
+}
+
+// InacbgGroupRequest represents request to group a claim in E-Klaim.
+type InacbgGroupRequest struct {
+	CoderNIK   string   `json:"coder_nik" binding:"required"`
+	SpecialCMG []string `json:"special_cmg"` // Optional, runs grouper stage 2
+}
+
+// InacbgSendRequest represents request to finalize and send a grouped claim.
+type InacbgSendRequest struct {
+	CoderNIK string `json:"coder_nik" binding:"required"`
+}
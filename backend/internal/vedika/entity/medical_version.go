@@ -0,0 +1,91 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrVersionRequired indicates a medical data edit without an If-Match version.
+var ErrVersionRequired = errors.New("medical data version required")
+
+// MedicalDataState is the editable medical data of a claim (diagnoses,
+// procedures, resume) and its version. Editors send the version back in
+// If-Match; a mismatch means someone else changed the claim meanwhile.
+type MedicalDataState struct {
+	NoRawat    string          `json:"no_rawat"`
+	Version    string          `json:"version"`
+	Diagnoses  []DiagnosisItem `json:"diagnoses"`
+	Procedures []ProcedureItem `json:"procedures"`
+	Resume     *MedicalResume  `json:"resume"`
+}
+
+// NewMedicalDataState builds the state and derives its version from the
+// content, so edits made outside Vedika (e.g. in SIMRS) change it too.
+func NewMedicalDataState(noRawat string, diagnoses []DiagnosisItem, procedures []ProcedureItem, resume *MedicalResume) *MedicalDataState {
+	return &MedicalDataState{
+		NoRawat:    noRawat,
+		Version:    medicalDataVersion(diagnoses, procedures, resume),
+		Diagnoses:  diagnoses,
+		Procedures: procedures,
+		Resume:     resume,
+	}
+}
+
+// medicalDataVersion hashes the coded lines in a stable order and the
+// resume fields. Display names are left out; they follow the codes.
+func medicalDataVersion(diagnoses []DiagnosisItem, procedures []ProcedureItem, resume *MedicalResume) string {
+	type line struct {
+		Kode      string `json:"k"`
+		Status    string `json:"s,omitempty"`
+		Prioritas int    `json:"p"`
+	}
+
+	dx := make([]line, 0, len(diagnoses))
+	for _, d := range diagnoses {
+		dx = append(dx, line{Kode: d.KodePenyakit, Status: d.StatusDx, Prioritas: d.Prioritas})
+	}
+	px := make([]line, 0, len(procedures))
+	for _, p := range procedures {
+		px = append(px, line{Kode: p.Kode, Prioritas: p.Prioritas})
+	}
+	for _, lines := range [][]line{dx, px} {
+		sort.Slice(lines, func(i, j int) bool {
+			if lines[i].Prioritas != lines[j].Prioritas {
+				return lines[i].Prioritas < lines[j].Prioritas
+			}
+			return lines[i].Kode < lines[j].Kode
+		})
+	}
+
+	var rs []string
+	if resume != nil {
+		rs = []string{resume.KeluhanUtama, resume.PemeriksaanFisik, resume.DiagnosaAkhir,
+			resume.Terapi, resume.Anjuran, resume.DokterPJ}
+	}
+
+	payload, _ := json.Marshal(struct {
+		D []line   `json:"d"`
+		P []line   `json:"p"`
+		R []string `json:"r"`
+	}{dx, px, rs})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:16])
+}
+
+// VersionConflictError is returned when a medical data edit was based on a
+// version that is no longer current. Current is the server state to merge
+// against.
+type VersionConflictError struct {
+	NoRawat  string            `json:"no_rawat"`
+	Expected string            `json:"expected"`
+	Current  *MedicalDataState `json:"current"`
+}
+
+// Error implements error.
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("medical data of %s changed: expected version %s, current %s", e.NoRawat, e.Expected, e.Current.Version)
+}
@@ -0,0 +1,161 @@
+package entity
+
+import "strings"
+
+// INA-CBG cost components, named after the E-Klaim tarif_rs keys.
+const (
+	TarifProsedurNonBedah = "prosedur_non_bedah"
+	TarifProsedurBedah    = "prosedur_bedah"
+	TarifKonsultasi       = "konsultasi"
+	TarifTenagaAhli       = "tenaga_ahli"
+	TarifKeperawatan      = "keperawatan"
+	TarifPenunjang        = "penunjang"
+	TarifRadiologi        = "radiologi"
+	TarifLaboratorium     = "laboratorium"
+	TarifPelayananDarah   = "pelayanan_darah"
+	TarifRehabilitasi     = "rehabilitasi"
+	TarifKamar            = "kamar"
+	TarifRawatIntensif    = "rawat_intensif"
+	TarifObat             = "obat"
+	TarifObatKronis       = "obat_kronis"
+	TarifObatKemoterapi   = "obat_kemoterapi"
+	TarifAlkes            = "alkes"
+	TarifBMHP             = "bmhp"
+	TarifSewaAlat         = "sewa_alat"
+)
+
+// TarifComponents lists the cost components in E-Klaim order with their labels.
+var TarifComponents = []struct{ Kode, Label string }{
+	{TarifProsedurNonBedah, "Prosedur Non Bedah"},
+	{TarifProsedurBedah, "Prosedur Bedah"},
+	{TarifKonsultasi, "Konsultasi"},
+	{TarifTenagaAhli, "Tenaga Ahli"},
+	{TarifKeperawatan, "Keperawatan"},
+	{TarifPenunjang, "Penunjang"},
+	{TarifRadiologi, "Radiologi"},
+	{TarifLaboratorium, "Laboratorium"},
+	{TarifPelayananDarah, "Pelayanan Darah"},
+	{TarifRehabilitasi, "Rehabilitasi"},
+	{TarifKamar, "Kamar / Akomodasi"},
+	{TarifRawatIntensif, "Rawat Intensif"},
+	{TarifObat, "Obat"},
+	{TarifObatKronis, "Obat Kronis"},
+	{TarifObatKemoterapi, "Obat Kemoterapi"},
+	{TarifAlkes, "Alkes"},
+	{TarifBMHP, "BMHP"},
+	{TarifSewaAlat, "Sewa Alat"},
+}
+
+// IsTarifComponent reports whether kode is a known cost component.
+func IsTarifComponent(kode string) bool {
+	for _, c := range TarifComponents {
+		if c.Kode == kode {
+			return true
+		}
+	}
+	return false
+}
+
+// TarifMapping classifies billing lines into INA-CBG cost components
+// (vedika.tarif_mapping). Lines are matched by kd_jenis_prw first, then by
+// pemisah; unmatched lines go to Default or, without a default, are guessed
+// from their name.
+type TarifMapping struct {
+	JenisPrw map[string]string `json:"kd_jenis_prw"` // kd_jenis_prw -> component
+	Pemisah  map[string]string `json:"pemisah"`      // billing pemisah -> component, case-insensitive
+	Default  string            `json:"default"`
+}
+
+// Classify returns the cost component of a billing line in category kategori.
+func (m TarifMapping) Classify(kategori string, item BillingItem) string {
+	if kd := strings.TrimSpace(item.KdJenisPrw); kd != "" {
+		if c := m.JenisPrw[kd]; c != "" {
+			return c
+		}
+	}
+	if pemisah := strings.TrimSpace(item.Pemisah); pemisah != "" {
+		for key, c := range m.Pemisah {
+			if strings.EqualFold(strings.TrimSpace(key), pemisah) {
+				return c
+			}
+		}
+	}
+	if m.Default != "" {
+		return m.Default
+	}
+	return guessTarifComponent(kategori + " " + item.NamaPerawatan)
+}
+
+// TarifTotal is the billed amount of one cost component.
+type TarifTotal struct {
+	Kode  string  `json:"kode"`
+	Label string  `json:"label"`
+	Total float64 `json:"total"`
+}
+
+// ClassifyTarif tags every billing line with its cost component and fills
+// TarifINACBG with the per-component totals, in E-Klaim order.
+func (b *BillingSummary) ClassifyTarif(m TarifMapping) {
+	totals := make(map[string]float64, len(TarifComponents))
+	for i := range b.Categories {
+		cat := &b.Categories[i]
+		for j := range cat.Items {
+			item := &cat.Items[j]
+			item.KategoriINACBG = m.Classify(cat.Kategori, *item)
+			totals[item.KategoriINACBG] += item.TotalBiaya
+		}
+	}
+
+	b.TarifINACBG = make([]TarifTotal, 0, len(TarifComponents))
+	for _, c := range TarifComponents {
+		b.TarifINACBG = append(b.TarifINACBG, TarifTotal{Kode: c.Kode, Label: c.Label, Total: totals[c.Kode]})
+	}
+}
+
+// guessTarifComponent picks a component from a billing line's label by
+// keyword. Unrecognised lines count as prosedur non-bedah.
+func guessTarifComponent(label string) string {
+	label = strings.ToLower(label)
+	switch {
+	case containsAny(label, "icu", "iccu", "picu", "nicu", "hcu", "intensif"):
+		return TarifRawatIntensif
+	case containsAny(label, "kamar", "bangsal", "akomodasi"):
+		return TarifKamar
+	case containsAny(label, "operasi", "bedah"):
+		return TarifProsedurBedah
+	case containsAny(label, "konsul", "visite", "visit"):
+		return TarifKonsultasi
+	case containsAny(label, "laborat"):
+		return TarifLaboratorium
+	case containsAny(label, "radiolog", "rontgen", "usg", "ct scan", "mri"):
+		return TarifRadiologi
+	case containsAny(label, "darah", "transfusi"):
+		return TarifPelayananDarah
+	case containsAny(label, "fisioterapi", "rehab"):
+		return TarifRehabilitasi
+	case containsAny(label, "kemoterapi"):
+		return TarifObatKemoterapi
+	case containsAny(label, "bhp", "bmhp"):
+		return TarifBMHP
+	case containsAny(label, "obat", "farmasi", "resep"):
+		return TarifObat
+	case containsAny(label, "alkes"):
+		return TarifAlkes
+	case containsAny(label, "sewa"):
+		return TarifSewaAlat
+	case containsAny(label, "perawat", "keperawatan"):
+		return TarifKeperawatan
+	case containsAny(label, "penunjang"):
+		return TarifPenunjang
+	}
+	return TarifProsedurNonBedah
+}
+
+func containsAny(s string, keywords ...string) bool {
+	for _, k := range keywords {
+		if strings.Contains(s, k) {
+			return true
+		}
+	}
+	return false
+}
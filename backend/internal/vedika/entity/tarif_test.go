@@ -0,0 +1,69 @@
+package entity
+
+import "testing"
+
+func TestTarifMappingClassify(t *testing.T) {
+	m := TarifMapping{
+		JenisPrw: map[string]string{"RJ001": TarifKonsultasi, "LAB01": TarifLaboratorium},
+		Pemisah:  map[string]string{" Ralan Paramedis ": TarifKeperawatan, "Obat": TarifObat},
+	}
+
+	tests := []struct {
+		name     string
+		mapping  TarifMapping
+		kategori string
+		item     BillingItem
+		want     string
+	}{
+		{"kd_jenis_prw wins over pemisah", m, "Tindakan", BillingItem{KdJenisPrw: "RJ001", Pemisah: "Obat"}, TarifKonsultasi},
+		{"kd_jenis_prw is trimmed", m, "Laborat", BillingItem{KdJenisPrw: " LAB01 "}, TarifLaboratorium},
+		{"unknown kd_jenis_prw falls back to pemisah", m, "Tindakan", BillingItem{KdJenisPrw: "XX", Pemisah: "obat"}, TarifObat},
+		{"pemisah ignores case and spaces", m, "Tindakan", BillingItem{Pemisah: "RALAN PARAMEDIS"}, TarifKeperawatan},
+		{"default before guessing", TarifMapping{Default: TarifPenunjang}, "Kamar", BillingItem{NamaPerawatan: "Kamar VIP"}, TarifPenunjang},
+		{"guess from category", TarifMapping{}, "Kamar", BillingItem{NamaPerawatan: "VIP A"}, TarifKamar},
+		{"guess from name", TarifMapping{}, "Tindakan", BillingItem{NamaPerawatan: "Rontgen Thorax"}, TarifRadiologi},
+		{"intensive care before room", TarifMapping{}, "Kamar", BillingItem{NamaPerawatan: "ICU"}, TarifRawatIntensif},
+		{"chemotherapy before drugs", TarifMapping{}, "Obat", BillingItem{NamaPerawatan: "Kemoterapi Siklus 1"}, TarifObatKemoterapi},
+		{"BMHP before drugs", TarifMapping{}, "Obat & BHP", BillingItem{NamaPerawatan: "Spuit 3cc"}, TarifBMHP},
+		{"unrecognised line", TarifMapping{}, "Tindakan", BillingItem{NamaPerawatan: "Nebulizer"}, TarifProsedurNonBedah},
+	}
+	for _, tt := range tests {
+		if got := tt.mapping.Classify(tt.kategori, tt.item); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyTarifTotals(t *testing.T) {
+	b := &BillingSummary{Categories: []BillingCategory{
+		{Kategori: "Tindakan", Items: []BillingItem{
+			{NamaPerawatan: "Konsul Spesialis", KdJenisPrw: "RJ001", TotalBiaya: 150000},
+			{NamaPerawatan: "Operasi Appendektomi", TotalBiaya: 4000000},
+		}},
+		{Kategori: "Obat", Items: []BillingItem{
+			{NamaPerawatan: "Paracetamol", TotalBiaya: 20000},
+			{NamaPerawatan: "Amoxicillin", TotalBiaya: 30000},
+		}},
+	}}
+	b.ClassifyTarif(TarifMapping{JenisPrw: map[string]string{"RJ001": TarifTenagaAhli}})
+
+	if got := b.Categories[0].Items[0].KategoriINACBG; got != TarifTenagaAhli {
+		t.Fatalf("mapped line tagged %s, want %s", got, TarifTenagaAhli)
+	}
+	if len(b.TarifINACBG) != len(TarifComponents) {
+		t.Fatalf("got %d totals, want one per component", len(b.TarifINACBG))
+	}
+	want := map[string]float64{
+		TarifTenagaAhli:    150000,
+		TarifProsedurBedah: 4000000,
+		TarifObat:          50000,
+	}
+	for i, total := range b.TarifINACBG {
+		if total.Kode != TarifComponents[i].Kode {
+			t.Fatalf("total %d is %s, want E-Klaim order %s", i, total.Kode, TarifComponents[i].Kode)
+		}
+		if total.Total != want[total.Kode] {
+			t.Errorf("%s: got %.0f, want %.0f", total.Kode, total.Total, want[total.Kode])
+		}
+	}
+}
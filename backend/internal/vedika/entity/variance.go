@@ -0,0 +1,89 @@
+package entity
+
+// Variance report groupings.
+const (
+	VarianceByDPJP   = "dpjp"
+	VarianceByUnit   = "unit"   // Poli for ralan, last bangsal for ranap
+	VarianceByCBG    = "cbg"    // INA-CBG code
+	VarianceByPeriod = "period" // Claim month
+)
+
+// IsValidVarianceGroup reports whether g is a supported grouping.
+func IsValidVarianceGroup(g string) bool {
+	switch g {
+	case VarianceByDPJP, VarianceByUnit, VarianceByCBG, VarianceByPeriod:
+		return true
+	}
+	return false
+}
+
+// VarianceFilter selects the grouped claims of the variance report.
+// Periods are YYYY-MM; ralan claims fall in their registration month and
+// ranap claims in their discharge month, as on the dashboard.
+type VarianceFilter struct {
+	PeriodFrom string
+	PeriodTo   string
+	Jenis      JenisPelayanan // Empty for both
+	Carabayar  []string       // penjab.kd_pj
+	GroupBy    string
+}
+
+// VarianceClaim is a grouped claim with its hospital and INA-CBG tariffs.
+type VarianceClaim struct {
+	NoRawat        string  `json:"no_rawat"`
+	NoSEP          string  `json:"no_sep"`
+	Jenis          string  `json:"jenis"` // ralan / ranap
+	Periode        string  `json:"periode"`
+	KdDokter       string  `json:"kd_dokter"`
+	Dokter         string  `json:"dokter"`
+	KdUnit         string  `json:"kd_unit"`
+	Unit           string  `json:"unit"`
+	CBGCode        string  `json:"cbg_code"`
+	CBGDescription string  `json:"cbg_description"`
+	TarifRS        float64 `json:"tarif_rs"`     // BillingSummary.JumlahTotal
+	TarifINACBG    float64 `json:"tarif_inacbg"` // Stored grouping total_tariff
+}
+
+// Selisih returns the INA-CBG tariff minus the hospital tariff; negative
+// when the hospital lost money on the claim.
+func (c *VarianceClaim) Selisih() float64 {
+	return c.TarifINACBG - c.TarifRS
+}
+
+// VarianceGroup aggregates the claims of one group.
+type VarianceGroup struct {
+	Key           string  `json:"key"`
+	Label         string  `json:"label"`
+	JumlahKlaim   int     `json:"jumlah_klaim"`
+	TarifRS       float64 `json:"tarif_rs"`
+	TarifINACBG   float64 `json:"tarif_inacbg"`
+	Selisih       float64 `json:"selisih"`        // TarifINACBG - TarifRS
+	SelisihPersen float64 `json:"selisih_persen"` // Selisih / TarifRS * 100
+	KlaimRugi     int     `json:"klaim_rugi"`     // Claims with a negative selisih
+	TotalRugi     float64 `json:"total_rugi"`     // Sum of negative selisih
+}
+
+// Add accumulates a claim into the group.
+func (g *VarianceGroup) Add(c *VarianceClaim) {
+	g.JumlahKlaim++
+	g.TarifRS += c.TarifRS
+	g.TarifINACBG += c.TarifINACBG
+	g.Selisih = g.TarifINACBG - g.TarifRS
+	if g.TarifRS != 0 {
+		g.SelisihPersen = g.Selisih / g.TarifRS * 100
+	}
+	if s := c.Selisih(); s < 0 {
+		g.KlaimRugi++
+		g.TotalRugi += s
+	}
+}
+
+// VarianceReport is the hospital tariff vs. INA-CBG tariff report.
+type VarianceReport struct {
+	PeriodFrom string          `json:"period_from"`
+	PeriodTo   string          `json:"period_to"`
+	Jenis      string          `json:"jenis,omitempty"`
+	GroupBy    string          `json:"group_by"`
+	Total      VarianceGroup   `json:"total"`
+	Groups     []VarianceGroup `json:"groups"` // Largest loss first, chronological by period
+}
@@ -0,0 +1,83 @@
+package entity
+
+import "time"
+
+// VerifierStatuses are the claim statuses visible in the verifier portal.
+// Verifiers decide on Lengkap claims; the others are visible for reading
+// and feedback (Setuju claims may also be re-opened).
+var VerifierStatuses = []ClaimStatus{StatusPengajuan, StatusPerbaikan, StatusLengkap, StatusSetuju}
+
+// IsVerifierStatus reports whether claims in status s are visible to verifiers.
+func IsVerifierStatus(s ClaimStatus) bool {
+	s = s.Normalize()
+	for _, v := range VerifierStatuses {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Verifier is an external BPJS verifier from mlite_users_vedika.
+type Verifier struct {
+	ID           int64      `json:"id"`
+	Username     string     `json:"username"`
+	Fullname     string     `json:"fullname"`
+	PasswordHash string     `json:"-"`
+	LockedUntil  *time.Time `json:"-"` // From mera_vedika_verifier_lockouts
+}
+
+// IsLocked reports whether the verifier's login is locked at the given time.
+func (v *Verifier) IsLocked(now time.Time) bool {
+	return v.LockedUntil != nil && now.Before(*v.LockedUntil)
+}
+
+// VerifierSession is a verifier portal login from mera_vedika_verifier_sessions.
+type VerifierSession struct {
+	ID         string
+	VerifierID int64
+	DeviceInfo string
+	IPAddress  string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	RevokedAt  *time.Time
+}
+
+// IsActive reports whether the session is neither revoked nor expired.
+func (s *VerifierSession) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// VerifierLoginRequest represents a verifier login.
+type VerifierLoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// VerifierLogin is a successful verifier login.
+type VerifierLogin struct {
+	Verifier    *Verifier `json:"verifier"`
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	TokenType   string    `json:"token_type"`
+}
+
+// VerifierStatusCount counts the claims of one status in the active period.
+type VerifierStatusCount struct {
+	Status ClaimStatus `json:"status"`
+	Ralan  int64       `json:"ralan"`
+	Ranap  int64       `json:"ranap"`
+	Total  int64       `json:"total"`
+}
+
+// VerifierDashboard summarises the active period for a verifier.
+type VerifierDashboard struct {
+	Period string                `json:"period"`
+	Counts []VerifierStatusCount `json:"counts"`
+}
+
+// VerifierDecisionRequest represents an approve or return decision.
+// Catatan is required when returning a claim.
+type VerifierDecisionRequest struct {
+	Catatan string `json:"catatan"`
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/pkg/response"
+)
+
+// GetAgingReport handles GET /admin/vedika/reports/aging
+// Query params: period (YYYY-MM) or date_from/date_to (YYYY-MM-DD), default
+// the active period; carabayar (comma-separated kd_pj, limited to the
+// allowed ones)
+func (h *DashboardHandler) GetAgingReport(c *gin.Context) {
+	actor := getActor(c)
+	ip := c.ClientIP()
+
+	rng, msg := parseDashboardRange(c)
+	if msg != "" {
+		response.BadRequest(c, "INVALID_PARAMS", msg)
+		return
+	}
+	filter := entity.AgingFilter{
+		Range:     rng,
+		Carabayar: queryCarabayar(c),
+	}
+
+	report, err := h.dashboardSvc.GetAgingReport(c.Request.Context(), filter, actor, ip)
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.Success(c, report)
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/internal/vedika/service"
+	"github.com/clinova/simrs/backend/pkg/response"
+)
+
+// AssignmentHandler handles claim assignment and work queue HTTP requests.
+type AssignmentHandler struct {
+	assignmentSvc *service.AssignmentService
+}
+
+// NewAssignmentHandler creates a new assignment handler.
+func NewAssignmentHandler(assignmentSvc *service.AssignmentService) *AssignmentHandler {
+	return &AssignmentHandler{assignmentSvc: assignmentSvc}
+}
+
+// Assign handles POST /admin/vedika/claim/assign/:no_rawat
+func (h *AssignmentHandler) Assign(c *gin.Context) {
+	noRawat := decodeNoRawat(c.Param("no_rawat"))
+	actor := getActor(c)
+	ip := c.ClientIP()
+
+	var req entity.AssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "INVALID_REQUEST", "user_id is required")
+		return
+	}
+
+	assignment, err := h.assignmentSvc.Assign(c.Request.Context(), noRawat, req, actor, ip)
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Klaim ditugaskan kepada "+assignment.Username, assignment)
+}
+
+// Unassign handles POST /admin/vedika/claim/unassign/:no_rawat
+func (h *AssignmentHandler) Unassign(c *gin.Context) {
+	noRawat := decodeNoRawat(c.Param("no_rawat"))
+	actor := getActor(c)
+	ip := c.ClientIP()
+
+	if err := h.assignmentSvc.Unassign(c.Request.Context(), noRawat, getGrantedPermissions(c), actor, ip); err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Penugasan klaim dilepas", gin.H{"no_rawat": noRawat})
+}
+
+// ClaimNext handles POST /admin/vedika/assignment/claim-next
+// Body (optional): date_from, date_to (default: active period), jenis (ralan|ranap).
+func (h *AssignmentHandler) ClaimNext(c *gin.Context) {
+	actor := getActor(c)
+	ip := c.ClientIP()
+
+	var req entity.ClaimNextRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "INVALID_REQUEST", "Invalid request body")
+			return
+		}
+	}
+	switch req.Jenis {
+	case "", entity.JenisRalan, entity.JenisRanap:
+	default:
+		response.BadRequest(c, "INVALID_PARAMS", "jenis must be ralan or ranap")
+		return
+	}
+
+	assignment, err := h.assignmentSvc.ClaimNext(c.Request.Context(), req, actor, ip)
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.Success(c, assignment)
+}
+
+// Distribute handles POST /admin/vedika/assignment/distribute
+func (h *AssignmentHandler) Distribute(c *gin.Context) {
+	actor := getActor(c)
+	ip := c.ClientIP()
+
+	result, err := h.assignmentSvc.Distribute(c.Request.Context(), actor, ip)
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
 
@@ -34,10 +36,45 @@ func (h *ClaimDetailHandler) GetClaimFullDetail(c *gin.Context) {
 		handleVedikaError(c, err)
 		return
 	}
+	setETag(c, detail.Version)
 
 	response.Success(c, detail)
 }
 
+// GetCompleteness handles GET /admin/vedika/claim/completeness/:no_rawat
+// Returns the "kelengkapan berkas" checklist checked before Lengkap.
+func (h *ClaimDetailHandler) GetCompleteness(c *gin.Context) {
+	noRawat := decodeNoRawatParam(c.Param("no_rawat"))
+	actor := getClaimActor(c)
+	ip := c.ClientIP()
+
+	report, err := h.claimDetailSvc.CheckCompleteness(c.Request.Context(), noRawat, actor, ip)
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.Success(c, report)
+}
+
+// GetClaimPDF handles GET /admin/vedika/claim/pdf/:no_rawat
+// Returns the full claim bundle (14 sections plus uploaded documents) as one PDF.
+func (h *ClaimDetailHandler) GetClaimPDF(c *gin.Context) {
+	noRawat := decodeNoRawatParam(c.Param("no_rawat"))
+	actor := getClaimActor(c)
+	ip := c.ClientIP()
+
+	data, err := h.claimDetailSvc.GenerateClaimPDF(c.Request.Context(), noRawat, actor, ip)
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	filename := "klaim-" + strings.ReplaceAll(noRawat, "/", "_") + ".pdf"
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
 // decodeNoRawatParam decodes URL-encoded no_rawat parameter.
 func decodeNoRawatParam(encoded string) string {
 	// If it came from a wildcard (*no_rawat), it might start with a /
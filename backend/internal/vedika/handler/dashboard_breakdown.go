@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/pkg/response"
+)
+
+// GetDashboardBreakdown handles GET /admin/vedika/dashboard/breakdown
+// Query params: period (YYYY-MM) or date_from/date_to (YYYY-MM-DD), default
+// the active period; group_by (unit|dpjp|carabayar, default unit); carabayar
+// (comma-separated kd_pj, limited to the allowed ones)
+func (h *DashboardHandler) GetDashboardBreakdown(c *gin.Context) {
+	actor := getActor(c)
+	ip := c.ClientIP()
+
+	rng, msg := parseDashboardRange(c)
+	if msg != "" {
+		response.BadRequest(c, "INVALID_PARAMS", msg)
+		return
+	}
+	filter := entity.BreakdownFilter{
+		Range:     rng,
+		Carabayar: queryCarabayar(c),
+		GroupBy:   c.DefaultQuery("group_by", entity.BreakdownByUnit),
+	}
+	if !entity.IsValidBreakdownGroup(filter.GroupBy) {
+		response.BadRequest(c, "INVALID_PARAMS", "group_by must be unit, dpjp or carabayar")
+		return
+	}
+
+	breakdown, err := h.dashboardSvc.GetDashboardBreakdown(c.Request.Context(), filter, actor, ip)
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.Success(c, breakdown)
+}
@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 
 	"github.com/clinova/simrs/backend/internal/auth/handler/middleware"
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
 	"github.com/clinova/simrs/backend/internal/vedika/service"
 	"github.com/clinova/simrs/backend/pkg/audit"
 	"github.com/clinova/simrs/backend/pkg/response"
@@ -21,42 +24,107 @@ func NewDashboardHandler(dashboardSvc *service.DashboardService) *DashboardHandl
 
 // GetDashboard handles GET /admin/vedika/dashboard
 // Returns summary cards with rencana, pengajuan, and maturasi counts.
+// Query params: period (YYYY-MM) or date_from/date_to (YYYY-MM-DD), default
+// the active period; compare (default true) adds the previous month's counts.
 func (h *DashboardHandler) GetDashboard(c *gin.Context) {
 	actor := getActor(c)
 	ip := c.ClientIP()
 
-	summary, err := h.dashboardSvc.GetDashboardSummary(c.Request.Context(), actor, ip)
+	rng, msg := parseDashboardRange(c)
+	if msg != "" {
+		response.BadRequest(c, "INVALID_PARAMS", msg)
+		return
+	}
+	compare, err := strconv.ParseBool(c.DefaultQuery("compare", "true"))
+	if err != nil {
+		response.BadRequest(c, "INVALID_PARAMS", "compare must be true or false")
+		return
+	}
+
+	summary, err := h.dashboardSvc.GetDashboardSummary(c.Request.Context(), rng, compare, actor, ip)
 	if err != nil {
 		handleVedikaError(c, err)
 		return
 	}
 
 	response.Success(c, gin.H{
-		"period":  summary.Period,
-		"summary": summary,
+		"period":    summary.Period,
+		"date_from": summary.DateFrom,
+		"date_to":   summary.DateTo,
+		"summary":   summary,
 	})
 }
 
 // GetDashboardTrend handles GET /admin/vedika/dashboard/trend
-// Returns daily aggregation data for charts.
+// Returns daily aggregation data for charts. Accepts the same range params
+// as GetDashboard.
 func (h *DashboardHandler) GetDashboardTrend(c *gin.Context) {
 	actor := getActor(c)
 	ip := c.ClientIP()
 
-	trend, err := h.dashboardSvc.GetDashboardTrend(c.Request.Context(), actor, ip)
+	rng, msg := parseDashboardRange(c)
+	if msg != "" {
+		response.BadRequest(c, "INVALID_PARAMS", msg)
+		return
+	}
+
+	trend, rng, err := h.dashboardSvc.GetDashboardTrend(c.Request.Context(), rng, actor, ip)
 	if err != nil {
 		handleVedikaError(c, err)
 		return
 	}
 
 	response.Success(c, gin.H{
-		"trend": trend,
+		"period":    rng.Period,
+		"date_from": rng.DateFrom,
+		"date_to":   rng.DateTo,
+		"trend":     trend,
 	})
 }
 
+// parseDashboardRange reads period or date_from/date_to. It returns a
+// message for invalid params, or "". An empty range means the active period,
+// filled in by the service.
+func parseDashboardRange(c *gin.Context) (entity.DashboardRange, string) {
+	period := c.Query("period")
+	dateFrom, dateTo := c.Query("date_from"), c.Query("date_to")
+
+	switch {
+	case period != "" && (dateFrom != "" || dateTo != ""):
+		return entity.DashboardRange{}, "use either period or date_from/date_to"
+	case period != "":
+		rng, err := entity.PeriodRange(period)
+		if err != nil {
+			return rng, "period must be YYYY-MM"
+		}
+		return rng, ""
+	case dateFrom != "" || dateTo != "":
+		if dateFrom == "" || dateTo == "" {
+			return entity.DashboardRange{}, "date_from and date_to are both required"
+		}
+		rng, err := entity.NewDashboardRange(dateFrom, dateTo)
+		if err != nil {
+			return rng, err.Error()
+		}
+		return rng, ""
+	}
+	return entity.DashboardRange{}, ""
+}
+
 // getActor extracts audit actor from gin context.
 func getActor(c *gin.Context) audit.Actor {
 	userID := middleware.GetUserID(c)
 	// Use userID as username fallback (same pattern as usermanagement handlers)
 	return audit.Actor{UserID: userID, Username: userID}
 }
+
+// getGrantedPermissions returns the effective permissions already resolved by
+// the permission middleware for the current request.
+func getGrantedPermissions(c *gin.Context) map[string]bool {
+	cache := middleware.GetPermissionCache(c)
+	if cache == nil {
+		return nil
+	}
+	perms, _ := cache.Get(middleware.GetUserID(c))
+	return perms
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+)
+
+func TestParseDashboardRange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		query   string
+		want    entity.DashboardRange
+		wantMsg bool
+	}{
+		{"nothing means the active period", "", entity.DashboardRange{}, false},
+		{"period", "period=2026-02", entity.DashboardRange{Period: "2026-02", DateFrom: "2026-02-01", DateTo: "2026-02-28"}, false},
+		{"bad period", "period=2026-2", entity.DashboardRange{}, true},
+		{"date range", "date_from=2026-02-03&date_to=2026-02-10", entity.DashboardRange{DateFrom: "2026-02-03", DateTo: "2026-02-10"}, false},
+		{"date range of a whole month", "date_from=2026-02-01&date_to=2026-02-28", entity.DashboardRange{Period: "2026-02", DateFrom: "2026-02-01", DateTo: "2026-02-28"}, false},
+		{"period and dates", "period=2026-02&date_from=2026-02-03", entity.DashboardRange{}, true},
+		{"only date_from", "date_from=2026-02-03", entity.DashboardRange{}, true},
+		{"only date_to", "date_to=2026-02-03", entity.DashboardRange{}, true},
+		{"reversed dates", "date_from=2026-02-10&date_to=2026-02-03", entity.DashboardRange{}, true},
+		{"too long", "date_from=2025-01-01&date_to=2026-06-30", entity.DashboardRange{}, true},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/admin/vedika/dashboard?"+tt.query, nil)
+
+		rng, msg := parseDashboardRange(c)
+		if (msg != "") != tt.wantMsg {
+			t.Errorf("%s: got message %q, want message %v", tt.name, msg, tt.wantMsg)
+			continue
+		}
+		if !tt.wantMsg && rng != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, rng, tt.want)
+		}
+	}
+}
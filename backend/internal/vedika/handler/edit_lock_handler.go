@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/clinova/simrs/backend/internal/vedika/service"
+	"github.com/clinova/simrs/backend/pkg/response"
+)
+
+// EditLockHandler handles claim edit lock HTTP requests.
+type EditLockHandler struct {
+	lockSvc *service.EditLockService
+}
+
+// NewEditLockHandler creates a new edit lock handler.
+func NewEditLockHandler(lockSvc *service.EditLockService) *EditLockHandler {
+	return &EditLockHandler{lockSvc: lockSvc}
+}
+
+// Acquire handles POST /admin/vedika/claim/lock/:no_rawat
+// Called when a coder opens a claim for editing.
+func (h *EditLockHandler) Acquire(c *gin.Context) {
+	noRawat := decodeNoRawat(c.Param("no_rawat"))
+	actor := getActor(c)
+	ip := c.ClientIP()
+
+	lock, err := h.lockSvc.Acquire(c.Request.Context(), noRawat, actor, ip)
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.Success(c, lock)
+}
+
+// Heartbeat handles PUT /admin/vedika/claim/lock/:no_rawat
+// Called periodically while the claim stays open.
+func (h *EditLockHandler) Heartbeat(c *gin.Context) {
+	noRawat := decodeNoRawat(c.Param("no_rawat"))
+	actor := getActor(c)
+
+	lock, err := h.lockSvc.Heartbeat(c.Request.Context(), noRawat, actor)
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.Success(c, lock)
+}
+
+// Release handles DELETE /admin/vedika/claim/lock/:no_rawat
+// Called when the coder closes the claim.
+func (h *EditLockHandler) Release(c *gin.Context) {
+	noRawat := decodeNoRawat(c.Param("no_rawat"))
+	actor := getActor(c)
+	ip := c.ClientIP()
+
+	if err := h.lockSvc.Release(c.Request.Context(), noRawat, actor, ip); err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Klaim ditutup", gin.H{"no_rawat": noRawat})
+}
+
+// Break handles POST /admin/vedika/claim/lock-break/:no_rawat
+func (h *EditLockHandler) Break(c *gin.Context) {
+	noRawat := decodeNoRawat(c.Param("no_rawat"))
+	actor := getActor(c)
+	ip := c.ClientIP()
+
+	lock, err := h.lockSvc.Break(c.Request.Context(), noRawat, actor, ip)
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Kunci klaim milik "+lock.Username+" dibuka paksa", lock)
+}
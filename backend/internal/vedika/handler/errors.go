@@ -3,12 +3,19 @@ package handler
 import (
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/internal/vedika/inacbg"
 	"github.com/clinova/simrs/backend/internal/vedika/repository"
+	"github.com/clinova/simrs/backend/internal/vedika/service"
+	"github.com/clinova/simrs/backend/internal/vedika/vclaim"
 	"github.com/clinova/simrs/backend/pkg/response"
+	"github.com/clinova/simrs/backend/pkg/secret"
+	"github.com/clinova/simrs/backend/pkg/storage"
 )
 
 // handleVedikaError handles common Vedika errors and returns appropriate HTTP responses.
@@ -27,6 +34,180 @@ func handleVedikaError(c *gin.Context, err error) {
 		return
 	}
 
+	var transitionErr *entity.TransitionError
+	if errors.As(err, &transitionErr) {
+		status := http.StatusConflict
+		message := "Perubahan status dari " + string(transitionErr.From) + " ke " + string(transitionErr.To) + " tidak diizinkan"
+		if len(transitionErr.Incomplete) > 0 {
+			message = "Klaim belum lengkap: " + strconv.Itoa(len(transitionErr.Incomplete)) + " item wajib kelengkapan berkas belum terpenuhi"
+		} else if transitionErr.OpenFeedback > 0 {
+			message = "Klaim tidak dapat keluar dari Perbaikan: masih ada " + strconv.Itoa(transitionErr.OpenFeedback) + " catatan perbaikan yang belum diselesaikan"
+		} else if transitionErr.RequiredPermission != "" {
+			status = http.StatusForbidden
+			message += " tanpa izin " + transitionErr.RequiredPermission
+		}
+		response.ErrorWithDetails(c, status, "INVALID_TRANSITION", message, transitionErr)
+		return
+	}
+
+	if errors.Is(err, entity.ErrStatusChanged) {
+		response.Error(c, http.StatusConflict, "STATUS_CHANGED",
+			"Status klaim telah diubah pengguna lain. Muat ulang dan ulangi perubahan.")
+		return
+	}
+
+	var conflictErr *entity.VersionConflictError
+	if errors.As(err, &conflictErr) {
+		setETag(c, conflictErr.Current.Version)
+		response.ErrorWithDetails(c, http.StatusPreconditionFailed, "VERSION_CONFLICT",
+			"Data medis klaim telah diubah pengguna lain. Muat ulang dan ulangi perubahan.", conflictErr)
+		return
+	}
+
+	var lockedErr *entity.ClaimLockedError
+	if errors.As(err, &lockedErr) {
+		response.ErrorWithDetails(c, http.StatusConflict, "CLAIM_LOCKED",
+			"Klaim sedang dibuka oleh "+lockedErr.Holder.Username, lockedErr)
+		return
+	}
+
+	if errors.Is(err, entity.ErrEditLockNotHeld) {
+		response.Error(c, http.StatusConflict, "LOCK_NOT_HELD", "Anda tidak memegang kunci klaim (belum dibuka, kedaluwarsa, atau dibuka paksa). Buka ulang klaim.")
+		return
+	}
+
+	if errors.Is(err, repository.ErrEditLockNotFound) {
+		response.Error(c, http.StatusNotFound, "LOCK_NOT_FOUND", "Klaim tidak sedang dikunci")
+		return
+	}
+
+	if errors.Is(err, entity.ErrVersionRequired) {
+		response.Error(c, http.StatusPreconditionRequired, "PRECONDITION_REQUIRED",
+			"Header If-Match dengan versi data medis wajib diisi")
+		return
+	}
+
+	if errors.Is(err, service.ErrClaimNotVisible) {
+		// Same response for unknown and out-of-scope claims
+		response.Error(c, http.StatusNotFound, "CLAIM_NOT_FOUND", "Klaim tidak ditemukan pada periode verifikasi")
+		return
+	}
+
+	if errors.Is(err, repository.ErrAssignmentNotFound) {
+		response.Error(c, http.StatusNotFound, "ASSIGNMENT_NOT_FOUND", "Klaim belum ditugaskan kepada coder")
+		return
+	}
+
+	if errors.Is(err, repository.ErrEpisodeNotFound) {
+		response.Error(c, http.StatusNotFound, "EPISODE_NOT_FOUND", "Episode pelayanan tidak ditemukan")
+		return
+	}
+
+	if errors.Is(err, repository.ErrCoderNotFound) {
+		response.Error(c, http.StatusBadRequest, "CODER_NOT_FOUND", "Coder tidak ditemukan atau tidak aktif")
+		return
+	}
+
+	if errors.Is(err, service.ErrAssignmentForbidden) {
+		response.Error(c, http.StatusForbidden, "ASSIGNMENT_FORBIDDEN", "Klaim ditugaskan kepada coder lain")
+		return
+	}
+
+	if errors.Is(err, service.ErrQueueEmpty) {
+		response.Error(c, http.StatusNotFound, "QUEUE_EMPTY", "Tidak ada klaim Rencana yang belum ditugaskan")
+		return
+	}
+
+	if errors.Is(err, repository.ErrFeedbackNotFound) {
+		response.Error(c, http.StatusNotFound, "FEEDBACK_NOT_FOUND", "Catatan perbaikan tidak ditemukan")
+		return
+	}
+
+	var codingErr *entity.CodingError
+	if errors.As(err, &codingErr) {
+		response.ErrorWithDetails(c, http.StatusUnprocessableEntity, "CODING_INVALID",
+			"Koding "+codingErr.Kind+" tidak valid: "+strconv.Itoa(len(codingErr.Validation.Errors))+" kesalahan", codingErr.Validation)
+		return
+	}
+
+	var uploadErr *entity.UploadError
+	if errors.As(err, &uploadErr) {
+		status := http.StatusBadRequest
+		switch uploadErr.Code {
+		case entity.UploadTooLarge:
+			status = http.StatusRequestEntityTooLarge
+		case entity.UploadUnsupportedType:
+			status = http.StatusUnsupportedMediaType
+		case entity.UploadDuplicate:
+			status = http.StatusConflict
+		}
+		response.ErrorWithDetails(c, status, uploadErr.Code, uploadErr.Message, uploadErr)
+		return
+	}
+
+	if errors.Is(err, repository.ErrDocumentNotFound) {
+		response.Error(c, http.StatusNotFound, "DOCUMENT_NOT_FOUND", "Berkas digital tidak ditemukan")
+		return
+	}
+
+	if errors.Is(err, storage.ErrInvalidKey) {
+		response.Error(c, http.StatusBadRequest, "INVALID_PATH", "Lokasi berkas tidak valid")
+		return
+	}
+
+	if errors.Is(err, service.ErrDocumentStorage) {
+		response.Error(c, http.StatusInternalServerError, "DOCUMENT_STORAGE_ERROR", "Penyimpanan berkas gagal: "+err.Error())
+		return
+	}
+
+	if errors.Is(err, repository.ErrSEPNotFound) {
+		response.Error(c, http.StatusBadRequest, "SEP_NOT_FOUND", "Episode belum memiliki SEP")
+		return
+	}
+
+	if errors.Is(err, vclaim.ErrNotConfigured) {
+		response.Error(c, http.StatusServiceUnavailable, "VCLAIM_NOT_CONFIGURED",
+			"Bridging VClaim BPJS belum dikonfigurasi. Please contact administrator.")
+		return
+	}
+
+	if errors.Is(err, vclaim.ErrNotFound) {
+		response.Error(c, http.StatusNotFound, "VCLAIM_NOT_FOUND", "Data tidak ditemukan di VClaim BPJS")
+		return
+	}
+
+	var vclaimErr *vclaim.APIError
+	if errors.As(err, &vclaimErr) {
+		response.ErrorWithDetails(c, http.StatusBadGateway, "VCLAIM_ERROR",
+			"VClaim BPJS menolak permintaan: "+vclaimErr.Message, gin.H{
+				"path": vclaimErr.Path,
+				"code": vclaimErr.Code,
+			})
+		return
+	}
+
+	if errors.Is(err, inacbg.ErrNotConfigured) || errors.Is(err, inacbg.ErrInvalidKey) || errors.Is(err, secret.ErrNoKey) {
+		response.Error(c, http.StatusServiceUnavailable, "INACBG_NOT_CONFIGURED",
+			"Bridging E-Klaim belum dikonfigurasi. Please contact administrator.")
+		return
+	}
+
+	var eklaimErr *inacbg.APIError
+	if errors.As(err, &eklaimErr) {
+		response.ErrorWithDetails(c, http.StatusBadGateway, "INACBG_ERROR",
+			"E-Klaim menolak "+eklaimErr.Method+": "+eklaimErr.Message, gin.H{
+				"method":   eklaimErr.Method,
+				"code":     eklaimErr.Code,
+				"error_no": eklaimErr.ErrorNo,
+			})
+		return
+	}
+
+	if errors.Is(err, repository.ErrGroupingNotFound) {
+		response.Error(c, http.StatusConflict, "INACBG_NOT_GROUPED", "Klaim belum di-grouping INA-CBG")
+		return
+	}
+
 	// Generic error
 	// Log to console for easier debugging since we can't see server logs easily
 	println("VEDIKA_ERROR:", err.Error())
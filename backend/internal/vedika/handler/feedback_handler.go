@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/internal/vedika/service"
+	"github.com/clinova/simrs/backend/pkg/response"
+)
+
+// FeedbackHandler handles claim correction note HTTP requests.
+type FeedbackHandler struct {
+	feedbackSvc *service.FeedbackService
+}
+
+// NewFeedbackHandler creates a new feedback handler.
+func NewFeedbackHandler(feedbackSvc *service.FeedbackService) *FeedbackHandler {
+	return &FeedbackHandler{feedbackSvc: feedbackSvc}
+}
+
+// ListFeedback handles GET /admin/vedika/claim/feedback/:no_rawat
+func (h *FeedbackHandler) ListFeedback(c *gin.Context) {
+	noRawat := decodeNoRawat(c.Param("no_rawat"))
+	actor := getActor(c)
+	ip := c.ClientIP()
+
+	threads, err := h.feedbackSvc.ListFeedback(c.Request.Context(), noRawat, actor, ip)
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.Success(c, threads)
+}
+
+// AddFeedback handles POST /admin/vedika/claim/feedback/:no_rawat
+func (h *FeedbackHandler) AddFeedback(c *gin.Context) {
+	noRawat := decodeNoRawat(c.Param("no_rawat"))
+	actor := getActor(c)
+	ip := c.ClientIP()
+
+	var req entity.FeedbackCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "INVALID_REQUEST", "catatan is required")
+		return
+	}
+
+	note, err := h.feedbackSvc.AddFeedback(c.Request.Context(), noRawat, req, actor, ip)
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.Created(c, note)
+}
+
+// ResolveFeedback handles POST /admin/vedika/feedback/:id/resolve
+func (h *FeedbackHandler) ResolveFeedback(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		response.BadRequest(c, "INVALID_PARAMS", "Invalid feedback id")
+		return
+	}
+	actor := getActor(c)
+	ip := c.ClientIP()
+
+	if err := h.feedbackSvc.ResolveFeedback(c.Request.Context(), id, actor, ip); err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Catatan perbaikan diselesaikan", gin.H{"id": id})
+}
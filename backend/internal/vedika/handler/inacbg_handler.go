@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/internal/vedika/service"
+	"github.com/clinova/simrs/backend/pkg/response"
+)
+
+// InacbgHandler handles E-Klaim INA-CBG bridging HTTP requests.
+type InacbgHandler struct {
+	inacbgSvc *service.InacbgService
+}
+
+// NewInacbgHandler creates a new INA-CBG bridging handler.
+func NewInacbgHandler(inacbgSvc *service.InacbgService) *InacbgHandler {
+	return &InacbgHandler{inacbgSvc: inacbgSvc}
+}
+
+// GetGrouping handles GET /admin/vedika/claim/inacbg/:no_rawat
+func (h *InacbgHandler) GetGrouping(c *gin.Context) {
+	noRawat := decodeNoRawat(c.Param("no_rawat"))
+	actor := getActor(c)
+	ip := c.ClientIP()
+
+	grouping, err := h.inacbgSvc.GetGrouping(c.Request.Context(), noRawat, actor, ip)
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.Success(c, grouping)
+}
+
+// GroupClaim handles POST /admin/vedika/claim/inacbg-grouper/:no_rawat
+func (h *InacbgHandler) GroupClaim(c *gin.Context) {
+	noRawat := decodeNoRawat(c.Param("no_rawat"))
+	actor := getActor(c)
+	ip := c.ClientIP()
+
+	var req entity.InacbgGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "INVALID_REQUEST", "coder_nik is required")
+		return
+	}
+
+	grouping, result, err := h.inacbgSvc.GroupClaim(c.Request.Context(), noRawat, req, actor, ip)
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Grouping INA-CBG berhasil", gin.H{
+		"grouping": grouping,
+		"grouper":  result,
+	})
+}
+
+// SendClaim handles POST /admin/vedika/claim/inacbg-send/:no_rawat
+func (h *InacbgHandler) SendClaim(c *gin.Context) {
+	noRawat := decodeNoRawat(c.Param("no_rawat"))
+	actor := getActor(c)
+	ip := c.ClientIP()
+
+	var req entity.InacbgSendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "INVALID_REQUEST", "coder_nik is required")
+		return
+	}
+
+	grouping, sent, err := h.inacbgSvc.SendClaim(c.Request.Context(), noRawat, req, actor, ip)
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Klaim berhasil dikirim ke E-Klaim", gin.H{
+		"grouping": grouping,
+		"sent":     sent.Data,
+	})
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/internal/vedika/service"
+	"github.com/clinova/simrs/backend/pkg/response"
+	"github.com/clinova/simrs/backend/pkg/xlsx"
+)
+
+// exportWriter writes export rows in one output format.
+type exportWriter interface {
+	WriteHeader(titles ...string) error
+	WriteRow(cells ...interface{}) error
+	Close() error
+}
+
+// ExportIndex handles GET /admin/vedika/index/export
+// Query params: date_from, date_to, status, jenis, search (as ListIndex),
+// format (xlsx|csv, default xlsx), columns (comma-separated keys, default all)
+func (h *WorkbenchHandler) ExportIndex(c *gin.Context) {
+	filter := h.parseIndexFilter(c)
+	actor := getActor(c)
+	ip := c.ClientIP()
+
+	// Validate required params
+	if filter.DateFrom == "" || filter.DateTo == "" {
+		response.BadRequest(c, "INVALID_PARAMS", "date_from and date_to are required")
+		return
+	}
+	if filter.Status == "" {
+		response.BadRequest(c, "INVALID_PARAMS", "status is required")
+		return
+	}
+
+	format := c.DefaultQuery("format", "xlsx")
+	if format != "xlsx" && format != "csv" {
+		response.BadRequest(c, "INVALID_FORMAT", "format must be xlsx or csv")
+		return
+	}
+
+	var keys []string
+	if v := c.Query("columns"); v != "" {
+		keys = strings.Split(v, ",")
+	}
+	columns, err := service.ResolveExportColumns(keys)
+	if err != nil {
+		response.BadRequest(c, "INVALID_COLUMNS", err.Error())
+		return
+	}
+
+	// Output starts with the first row so that errors raised before it
+	// still produce a JSON error response
+	var out exportWriter
+	start := func() error {
+		if out != nil {
+			return nil
+		}
+		filename := fmt.Sprintf("vedika-%s-%s-%s.%s", strings.ToLower(string(filter.Status)), filter.DateFrom, filter.DateTo, format)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		if format == "csv" {
+			c.Header("Content-Type", "text/csv; charset=utf-8")
+			out = newCSVExportWriter(c.Writer)
+		} else {
+			c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+			w, err := xlsx.NewWriter(c.Writer, "Klaim "+string(filter.Status))
+			if err != nil {
+				return err
+			}
+			out = w
+		}
+
+		titles := make([]string, len(columns))
+		for i, col := range columns {
+			titles[i] = col.Title
+		}
+		return out.WriteHeader(titles...)
+	}
+
+	_, err = h.workbenchSvc.ExportIndex(c.Request.Context(), filter, format, func(row *entity.IndexExportRow) error {
+		if err := start(); err != nil {
+			return err
+		}
+		cells := make([]interface{}, len(columns))
+		for i, col := range columns {
+			cells[i] = col.Value(row)
+		}
+		return out.WriteRow(cells...)
+	}, actor, ip)
+
+	if err == nil {
+		// Empty result: still send the header row
+		err = start()
+	}
+	if err != nil {
+		c.Error(err)
+		if !c.Writer.Written() {
+			// Nothing has reached the client yet, so it can still get a
+			// JSON error instead of the file
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			handleVedikaError(c, err)
+			return
+		}
+		log.Printf("Ekspor index vedika terputus: %v", err)
+		abortDownload(c)
+		return
+	}
+	if err := out.Close(); err != nil {
+		log.Printf("Gagal menutup ekspor index vedika: %v", err)
+		abortDownload(c)
+	}
+}
+
+// abortDownload ends a download that failed after its headers were sent.
+// The writer is left unfinished (an xlsx without its zip directory cannot be
+// opened) and the connection is dropped, so the client sees a failed
+// transfer rather than a truncated file that looks complete.
+func abortDownload(c *gin.Context) {
+	c.Abort()
+	if conn, _, err := c.Writer.Hijack(); err == nil {
+		conn.Close()
+	}
+}
+
+// csvExportWriter adapts encoding/csv to exportWriter.
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func newCSVExportWriter(w io.Writer) *csvExportWriter {
+	return &csvExportWriter{w: csv.NewWriter(w)}
+}
+
+func (e *csvExportWriter) WriteHeader(titles ...string) error {
+	return e.w.Write(titles)
+}
+
+func (e *csvExportWriter) WriteRow(cells ...interface{}) error {
+	record := make([]string, len(cells))
+	for i, v := range cells {
+		switch x := v.(type) {
+		case nil:
+		case string:
+			record[i] = x
+		case float64:
+			record[i] = strconv.FormatFloat(x, 'f', -1, 64)
+		default:
+			record[i] = fmt.Sprint(x)
+		}
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvExportWriter) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
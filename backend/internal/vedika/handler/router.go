@@ -2,6 +2,7 @@
 package handler
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/gin-gonic/gin"
@@ -11,6 +12,11 @@ import (
 	"github.com/clinova/simrs/backend/internal/vedika/repository"
 	vedikaService "github.com/clinova/simrs/backend/internal/vedika/service"
 	"github.com/clinova/simrs/backend/pkg/audit"
+	"github.com/clinova/simrs/backend/pkg/jwt"
+	"github.com/clinova/simrs/backend/pkg/password"
+	"github.com/clinova/simrs/backend/pkg/redis"
+	"github.com/clinova/simrs/backend/pkg/secret"
+	"github.com/clinova/simrs/backend/pkg/storage"
 )
 
 // Router handles Vedika route setup.
@@ -18,34 +24,88 @@ type Router struct {
 	dashboardHandler   *DashboardHandler
 	workbenchHandler   *WorkbenchHandler
 	claimDetailHandler *ClaimDetailHandler
+	feedbackHandler    *FeedbackHandler
+	inacbgHandler      *InacbgHandler
+	vclaimHandler      *VClaimHandler
+	verifierHandler    *VerifierHandler
+	assignmentHandler  *AssignmentHandler
+	editLockHandler    *EditLockHandler
+	assignmentSvc      *vedikaService.AssignmentService
 	jwtMiddleware      *middleware.JWTMiddleware
 	permMiddleware     *middleware.PermissionMiddleware
+	verifierLimiter    *middleware.LoginRateLimiter
+	heavyLimiter       *middleware.RateLimiter
 }
 
 // NewRouter creates a new Vedika router.
 func NewRouter(
 	db *sql.DB,
 	auditLogger *audit.Logger,
+	settingsEnv string,
+	settingsCipher *secret.Cipher,
+	documentStore storage.DocumentStore,
+	jwtManager *jwt.Manager,
+	passwordHasher *password.Hasher,
+	verifierLockout service.LockoutPolicy,
 	jwtMiddleware *middleware.JWTMiddleware,
 	permMiddleware *middleware.PermissionMiddleware,
+	redisClient *redis.Client,
+	heavyLimit middleware.RateLimitConfig,
 ) *Router {
 	// Initialize repositories
 	settingsRepo := repository.NewMySQLSettingsRepository(db)
 	dashboardRepo := repository.NewMySQLDashboardRepository(db)
 	indexRepo := repository.NewMySQLIndexRepository(db)
 	claimDetailRepo := repository.NewMySQLClaimDetailRepository(db)
+	feedbackRepo := repository.NewMySQLFeedbackRepository(db)
+	inacbgRepo := repository.NewMySQLInacbgRepository(db)
+	documentRepo := repository.NewMySQLDocumentRepository(db)
+	bridgingSettingsRepo := repository.NewMySQLBridgingSettingsRepository(db, settingsEnv, settingsCipher)
+	verifierRepo := repository.NewMySQLVerifierRepository(db)
+	assignmentRepo := repository.NewMySQLAssignmentRepository(db)
+	editLockRepo := repository.NewMySQLEditLockRepository(db)
 
 	// Initialize services
 	dashboardSvc := vedikaService.NewDashboardService(settingsRepo, dashboardRepo, auditLogger)
-	workbenchSvc := vedikaService.NewWorkbenchService(indexRepo, settingsRepo, auditLogger)
-	claimDetailSvc := vedikaService.NewClaimDetailService(claimDetailRepo, settingsRepo, auditLogger)
+	workbenchSvc := vedikaService.NewWorkbenchService(indexRepo, settingsRepo, feedbackRepo, documentRepo, documentStore, auditLogger)
+	claimDetailSvc := vedikaService.NewClaimDetailService(claimDetailRepo, settingsRepo, feedbackRepo, documentStore, auditLogger)
+	feedbackSvc := vedikaService.NewFeedbackService(feedbackRepo, auditLogger)
+	inacbgSvc := vedikaService.NewInacbgService(claimDetailRepo, inacbgRepo, bridgingSettingsRepo, auditLogger)
+	vclaimSvc := vedikaService.NewVClaimService(bridgingSettingsRepo, auditLogger)
+	verifierSvc := vedikaService.NewVerifierService(verifierRepo, settingsRepo, workbenchSvc, claimDetailSvc, feedbackSvc, jwtManager, passwordHasher, verifierLockout, auditLogger)
+	assignmentSvc := vedikaService.NewAssignmentService(assignmentRepo, settingsRepo, auditLogger)
+	editLockSvc := vedikaService.NewEditLockService(editLockRepo, auditLogger)
+
+	// Fetch SEPs missing from bridging_sep live from VClaim
+	claimDetailRepo.SetLiveSEPSource(vclaimSvc)
+
+	// Classify billing lines into INA-CBG cost components
+	claimDetailRepo.SetTarifMappingSource(settingsRepo)
+
+	// Block the Lengkap transition on an incomplete checklist
+	workbenchSvc.SetCompletenessChecker(claimDetailSvc)
+
+	// Expose the medical data version editors send back in If-Match
+	claimDetailSvc.SetMedicalVersionSource(workbenchSvc)
+
+	// Show who has a claim open and reject edits from everyone else
+	workbenchSvc.SetEditLockSource(editLockSvc)
 
 	return &Router{
 		dashboardHandler:   NewDashboardHandler(dashboardSvc),
 		workbenchHandler:   NewWorkbenchHandler(workbenchSvc),
 		claimDetailHandler: NewClaimDetailHandler(claimDetailSvc),
+		feedbackHandler:    NewFeedbackHandler(feedbackSvc),
+		inacbgHandler:      NewInacbgHandler(inacbgSvc),
+		vclaimHandler:      NewVClaimHandler(vclaimSvc),
+		verifierHandler:    NewVerifierHandler(verifierSvc),
+		assignmentHandler:  NewAssignmentHandler(assignmentSvc),
+		editLockHandler:    NewEditLockHandler(editLockSvc),
+		assignmentSvc:      assignmentSvc,
 		jwtMiddleware:      jwtMiddleware,
 		permMiddleware:     permMiddleware,
+		verifierLimiter:    middleware.NewLoginRateLimiterWithClient(redisClient, "veda-login"),
+		heavyLimiter:       middleware.NewRateLimiter(middleware.NewRateLimitStore(redisClient, "vedika-heavy", heavyLimit.Window, heavyLimit.MaxRequests), heavyLimit),
 	}
 }
 
@@ -60,6 +120,9 @@ func (r *Router) RegisterRoutes(engine *gin.Engine, permissionService *service.P
 		{
 			dashboard.GET("/dashboard", r.dashboardHandler.GetDashboard)
 			dashboard.GET("/dashboard/trend", r.dashboardHandler.GetDashboardTrend)
+			dashboard.GET("/dashboard/breakdown", r.dashboardHandler.GetDashboardBreakdown)
+			dashboard.GET("/reports/variance", r.dashboardHandler.GetVarianceReport)
+			dashboard.GET("/reports/aging", r.dashboardHandler.GetAgingReport)
 		}
 
 		// Master data (require vedika.claim.edit_medical_data)
@@ -75,17 +138,70 @@ func (r *Router) RegisterRoutes(engine *gin.Engine, permissionService *service.P
 		index.Use(r.permMiddleware.RequirePermission("vedika.read"))
 		{
 			index.GET("/index", r.workbenchHandler.ListIndex)
+			index.GET("/index/export", r.heavyLimiter.Middleware(), r.workbenchHandler.ExportIndex)
 		}
 
+		// BPJS VClaim lookups (require vedika.claim.read)
+		bpjs := vedika.Group("/bpjs")
+		bpjs.Use(r.permMiddleware.RequirePermission("vedika.claim.read"))
+		{
+			bpjs.GET("/sep/:no_sep", r.vclaimHandler.GetSEP)
+			bpjs.GET("/peserta/:no_kartu", r.vclaimHandler.GetPeserta)
+			bpjs.GET("/rujukan/:no_rujukan", r.vclaimHandler.GetRujukan)
+		}
+
+		// Work queues: coders take the next unassigned claim, supervisors
+		// distribute Rencana episodes across the configured pools
+		assignment := vedika.Group("/assignment")
+		{
+			assignment.POST("/claim-next", r.permMiddleware.RequirePermission("vedika.claim.edit_medical_data"), r.assignmentHandler.ClaimNext)
+			assignment.POST("/distribute", r.permMiddleware.RequirePermission("vedika.claim.assign"), r.assignmentHandler.Distribute)
+		}
+
+		// Resolve a feedback thread (coders or verifiers)
+		vedika.POST("/feedback/:id/resolve", r.permMiddleware.RequireAnyPermission("vedika.claim.update_status", "vedika.verify"), r.feedbackHandler.ResolveFeedback)
+
 		// Claim detail endpoints
 		claim := vedika.Group("/claim")
 		{
-			// View FULL claim detail - all 14 sections (require vedika.claim.read)
-			claim.GET("/full/*no_rawat", r.permMiddleware.RequirePermission("vedika.claim.read"), r.claimDetailHandler.GetClaimFullDetail)
+			// View FULL claim detail - all 14 sections (require vedika.claim.read, rate limited per user)
+			claim.GET("/full/*no_rawat", r.permMiddleware.RequirePermission("vedika.claim.read"), r.heavyLimiter.Middleware(), r.claimDetailHandler.GetClaimFullDetail)
+
+			// Print claim bundle PDF - all 14 sections + uploaded documents (require vedika.claim.read, rate limited per user)
+			claim.GET("/pdf/*no_rawat", r.permMiddleware.RequirePermission("vedika.claim.read"), r.heavyLimiter.Middleware(), r.claimDetailHandler.GetClaimPDF)
+
+			// Completeness checklist (require vedika.claim.read)
+			claim.GET("/completeness/*no_rawat", r.permMiddleware.RequirePermission("vedika.claim.read"), r.claimDetailHandler.GetCompleteness)
 
 			// View basic claim detail (require vedika.claim.read)
 			claim.GET("/detail/*no_rawat", r.permMiddleware.RequirePermission("vedika.claim.read"), r.workbenchHandler.GetClaimDetail)
 
+			// Status history timeline (require vedika.claim.read)
+			claim.GET("/history/*no_rawat", r.permMiddleware.RequirePermission("vedika.claim.read"), r.workbenchHandler.GetStatusHistory)
+
+			// Feedback threads (read: vedika.claim.read, write: coders or verifiers)
+			claim.GET("/feedback/*no_rawat", r.permMiddleware.RequirePermission("vedika.claim.read"), r.feedbackHandler.ListFeedback)
+			claim.POST("/feedback/*no_rawat", r.permMiddleware.RequireAnyPermission("vedika.claim.update_status", "vedika.verify"), r.feedbackHandler.AddFeedback)
+
+			// E-Klaim INA-CBG bridging (read: vedika.claim.read, write: vedika.claim.bridging)
+			claim.GET("/inacbg/*no_rawat", r.permMiddleware.RequirePermission("vedika.claim.read"), r.inacbgHandler.GetGrouping)
+			claim.POST("/inacbg-grouper/*no_rawat", r.permMiddleware.RequirePermission("vedika.claim.bridging"), r.inacbgHandler.GroupClaim)
+			claim.POST("/inacbg-send/*no_rawat", r.permMiddleware.RequirePermission("vedika.claim.bridging"), r.inacbgHandler.SendClaim)
+
+			// Assign to a coder (require vedika.claim.assign)
+			claim.POST("/assign/*no_rawat", r.permMiddleware.RequirePermission("vedika.claim.assign"), r.assignmentHandler.Assign)
+
+			// Release an assignment (supervisors, or coders for their own claims)
+			claim.POST("/unassign/*no_rawat", r.permMiddleware.RequireAnyPermission("vedika.claim.assign", "vedika.claim.edit_medical_data"), r.assignmentHandler.Unassign)
+
+			// Edit locks: open, heartbeat, close (require vedika.claim.edit_medical_data)
+			claim.POST("/lock/*no_rawat", r.permMiddleware.RequirePermission("vedika.claim.edit_medical_data"), r.editLockHandler.Acquire)
+			claim.PUT("/lock/*no_rawat", r.permMiddleware.RequirePermission("vedika.claim.edit_medical_data"), r.editLockHandler.Heartbeat)
+			claim.DELETE("/lock/*no_rawat", r.permMiddleware.RequirePermission("vedika.claim.edit_medical_data"), r.editLockHandler.Release)
+
+			// Break another coder's lock (require vedika.settings)
+			claim.POST("/lock-break/*no_rawat", r.permMiddleware.RequirePermission("vedika.settings"), r.editLockHandler.Break)
+
 			// Batch update status (require vedika.claim.update_status)
 			claim.POST("/batch-status", r.permMiddleware.RequirePermission("vedika.claim.update_status"), r.workbenchHandler.BatchUpdateStatus)
 
@@ -111,6 +227,9 @@ func (r *Router) RegisterRoutes(engine *gin.Engine, permissionService *service.P
 			// Delete documents (require vedika.claim.upload_document)
 			claim.DELETE("/documents/*no_rawat", r.permMiddleware.RequirePermission("vedika.claim.upload_document"), r.workbenchHandler.DeleteDocument)
 
+			// Download a document file (require vedika.claim.read)
+			claim.GET("/documents/file/*no_rawat", r.permMiddleware.RequirePermission("vedika.claim.read"), r.workbenchHandler.DownloadDocument)
+
 			// View resume (require vedika.claim.read_resume)
 			claim.GET("/resume/*no_rawat", r.permMiddleware.RequirePermission("vedika.claim.read_resume"), r.workbenchHandler.GetResume)
 
@@ -121,4 +240,43 @@ func (r *Router) RegisterRoutes(engine *gin.Engine, permissionService *service.P
 			claim.GET("/documents/master", r.permMiddleware.RequirePermission("vedika.claim.upload_document"), r.workbenchHandler.GetMasterDigitalDocs)
 		}
 	}
+
+	r.registerVerifierRoutes(engine)
+}
+
+// StartBackgroundJobs starts the automatic claim distribution loop, which
+// stops when ctx is done.
+func (r *Router) StartBackgroundJobs(ctx context.Context) {
+	r.assignmentSvc.StartAutoDistribution(ctx)
+}
+
+// registerVerifierRoutes registers the BPJS verifier portal. Verifiers log in
+// against mlite_users_vedika and their tokens are only accepted here.
+func (r *Router) registerVerifierRoutes(engine *gin.Engine) {
+	veda := engine.Group("/veda")
+	{
+		// Login with rate limiting - max 10 attempts per 5 minutes per username+IP
+		veda.POST("/login", r.verifierLimiter.Middleware(), r.verifierHandler.Login)
+
+		portal := veda.Group("")
+		portal.Use(r.verifierHandler.Authenticate())
+		{
+			portal.POST("/logout", r.verifierHandler.Logout)
+			portal.GET("/me", r.verifierHandler.Me)
+			portal.GET("/dashboard", r.verifierHandler.GetDashboard)
+			portal.GET("/claims", r.verifierHandler.ListClaims)
+
+			// Claims in Pengajuan/Perbaikan/Lengkap/Setuju within the active period only
+			claim := portal.Group("/claim")
+			{
+				claim.GET("/full/*no_rawat", r.heavyLimiter.MiddlewareBy(verifierSubject), r.verifierHandler.GetClaim)
+				claim.GET("/pdf/*no_rawat", r.heavyLimiter.MiddlewareBy(verifierSubject), r.verifierHandler.GetClaimPDF)
+				claim.GET("/history/*no_rawat", r.verifierHandler.GetStatusHistory)
+				claim.GET("/feedback/*no_rawat", r.verifierHandler.ListFeedback)
+				claim.POST("/feedback/*no_rawat", r.verifierHandler.AddFeedback)
+				claim.POST("/approve/*no_rawat", r.verifierHandler.Approve)
+				claim.POST("/return/*no_rawat", r.verifierHandler.Return)
+			}
+		}
+	}
 }
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/pkg/response"
+)
+
+// maxVarianceMonths caps the period range of the variance report.
+const maxVarianceMonths = 24
+
+// GetVarianceReport handles GET /admin/vedika/reports/variance
+// Query params: period (YYYY-MM, sets both ends) or period_from/period_to
+// (default active period), jenis (ralan|ranap), carabayar (comma-separated
+// kd_pj, limited to the allowed ones), group_by (dpjp|unit|cbg|period,
+// default cbg), format (json|csv, default json)
+func (h *DashboardHandler) GetVarianceReport(c *gin.Context) {
+	actor := getActor(c)
+	ip := c.ClientIP()
+
+	filter := entity.VarianceFilter{
+		PeriodFrom: c.Query("period_from"),
+		PeriodTo:   c.Query("period_to"),
+		Jenis:      entity.JenisPelayanan(c.Query("jenis")),
+		GroupBy:    c.DefaultQuery("group_by", entity.VarianceByCBG),
+	}
+	if period := c.Query("period"); period != "" {
+		filter.PeriodFrom, filter.PeriodTo = period, period
+	}
+	filter.Carabayar = queryCarabayar(c)
+
+	if msg := validateVarianceFilter(filter); msg != "" {
+		response.BadRequest(c, "INVALID_PARAMS", msg)
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		response.BadRequest(c, "INVALID_FORMAT", "format must be json or csv")
+		return
+	}
+
+	report, err := h.dashboardSvc.GetVarianceReport(c.Request.Context(), filter, actor, ip)
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	if format == "json" {
+		response.Success(c, report)
+		return
+	}
+
+	filename := fmt.Sprintf("vedika-selisih-%s-%s-%s.csv", report.GroupBy, report.PeriodFrom, report.PeriodTo)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+
+	out := newCSVExportWriter(c.Writer)
+	err = out.WriteHeader("Kode", "Kelompok", "Jumlah Klaim", "Tarif RS", "Tarif INA-CBG", "Selisih", "Selisih (%)", "Klaim Rugi", "Total Rugi")
+	for _, g := range append(report.Groups, report.Total) {
+		if err != nil {
+			break
+		}
+		err = out.WriteRow(g.Key, g.Label, g.JumlahKlaim, g.TarifRS, g.TarifINACBG, g.Selisih, g.SelisihPersen, g.KlaimRugi, g.TotalRugi)
+	}
+	if err == nil {
+		err = out.Close()
+	}
+	if err != nil {
+		// Headers are already sent; the truncated file is the only signal
+		println("VEDIKA_ERROR:", err.Error())
+	}
+}
+
+// validateVarianceFilter returns a message for an invalid filter, or "".
+// Empty periods are filled in by the service.
+func validateVarianceFilter(f entity.VarianceFilter) string {
+	var from, to time.Time
+	for _, p := range []struct {
+		name  string
+		value string
+		t     *time.Time
+	}{{"period_from", f.PeriodFrom, &from}, {"period_to", f.PeriodTo, &to}} {
+		if p.value == "" {
+			continue
+		}
+		t, err := time.Parse("2006-01", p.value)
+		if err != nil {
+			return p.name + " must be YYYY-MM"
+		}
+		*p.t = t
+	}
+	if !from.IsZero() && !to.IsZero() {
+		if to.Before(from) {
+			return "period_to must not be before period_from"
+		}
+		if to.After(from.AddDate(0, maxVarianceMonths-1, 0)) {
+			return fmt.Sprintf("period range must not exceed %d months", maxVarianceMonths)
+		}
+	}
+
+	if f.Jenis != "" && f.Jenis != entity.JenisRalan && f.Jenis != entity.JenisRanap {
+		return "jenis must be ralan or ranap"
+	}
+	if !entity.IsValidVarianceGroup(f.GroupBy) {
+		return "group_by must be dpjp, unit, cbg or period"
+	}
+	return ""
+}
+
+// queryCarabayar reads the comma-separated carabayar (kd_pj) query param.
+func queryCarabayar(c *gin.Context) []string {
+	var carabayar []string
+	for _, kd := range strings.Split(c.Query("carabayar"), ",") {
+		if kd = strings.TrimSpace(kd); kd != "" {
+			carabayar = append(carabayar, kd)
+		}
+	}
+	return carabayar
+}
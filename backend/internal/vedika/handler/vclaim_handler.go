@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/clinova/simrs/backend/internal/vedika/service"
+	"github.com/clinova/simrs/backend/pkg/response"
+)
+
+// VClaimHandler handles BPJS VClaim lookup HTTP requests.
+type VClaimHandler struct {
+	vclaimSvc *service.VClaimService
+}
+
+// NewVClaimHandler creates a new VClaim lookup handler.
+func NewVClaimHandler(vclaimSvc *service.VClaimService) *VClaimHandler {
+	return &VClaimHandler{vclaimSvc: vclaimSvc}
+}
+
+// GetSEP handles GET /admin/vedika/bpjs/sep/:no_sep
+func (h *VClaimHandler) GetSEP(c *gin.Context) {
+	sep, err := h.vclaimSvc.GetSEP(c.Request.Context(), c.Param("no_sep"), getActor(c), c.ClientIP())
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.Success(c, sep)
+}
+
+// GetPeserta handles GET /admin/vedika/bpjs/peserta/:no_kartu?tgl=YYYY-MM-DD
+func (h *VClaimHandler) GetPeserta(c *gin.Context) {
+	peserta, err := h.vclaimSvc.GetPeserta(c.Request.Context(), c.Param("no_kartu"), c.Query("tgl"), getActor(c), c.ClientIP())
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.Success(c, peserta)
+}
+
+// GetRujukan handles GET /admin/vedika/bpjs/rujukan/:no_rujukan?rs=1
+func (h *VClaimHandler) GetRujukan(c *gin.Context) {
+	fromRS := c.Query("rs") == "1" || c.Query("rs") == "true"
+
+	rujukan, err := h.vclaimSvc.GetRujukan(c.Request.Context(), c.Param("no_rujukan"), fromRS, getActor(c), c.ClientIP())
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.Success(c, rujukan)
+}
@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/internal/vedika/service"
+	"github.com/clinova/simrs/backend/pkg/audit"
+	"github.com/clinova/simrs/backend/pkg/jwt"
+	"github.com/clinova/simrs/backend/pkg/response"
+)
+
+// Context keys set by VerifierHandler.Authenticate.
+const (
+	contextKeyVerifier        = "vedika_verifier"         // *entity.Verifier
+	contextKeyVerifierSession = "vedika_verifier_session" // Verifier session id
+)
+
+// VerifierHandler handles the BPJS verifier portal (/veda) HTTP requests.
+type VerifierHandler struct {
+	verifierSvc *service.VerifierService
+}
+
+// NewVerifierHandler creates a new verifier portal handler.
+func NewVerifierHandler(verifierSvc *service.VerifierService) *VerifierHandler {
+	return &VerifierHandler{verifierSvc: verifierSvc}
+}
+
+// Authenticate accepts verifier tokens only; hospital access tokens are
+// rejected, as verifier tokens are by the admin JWT middleware.
+func (h *VerifierHandler) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+			response.Unauthorized(c, response.ErrCodeInvalidToken, "Header otorisasi tidak valid")
+			c.Abort()
+			return
+		}
+
+		verifier, session, err := h.verifierSvc.Authenticate(c.Request.Context(), parts[1])
+		if err != nil {
+			switch {
+			case errors.Is(err, jwt.ErrExpiredToken):
+				response.Unauthorized(c, response.ErrCodeExpiredToken, "Token telah kedaluwarsa")
+			case errors.Is(err, service.ErrVerifierSessionRevoked):
+				response.Unauthorized(c, response.ErrCodeSessionRevoked, "Sesi telah dibatalkan")
+			default:
+				response.Unauthorized(c, response.ErrCodeInvalidToken, "Token tidak valid")
+			}
+			c.Abort()
+			return
+		}
+
+		c.Set(contextKeyVerifier, verifier)
+		c.Set(contextKeyVerifierSession, session.ID)
+		c.Next()
+	}
+}
+
+// getVerifier returns the verifier set by Authenticate.
+func getVerifier(c *gin.Context) *entity.Verifier {
+	if v, exists := c.Get(contextKeyVerifier); exists {
+		return v.(*entity.Verifier)
+	}
+	return nil
+}
+
+// verifierSubject keys the verifier portal rate limits by username.
+func verifierSubject(c *gin.Context) string {
+	if v := getVerifier(c); v != nil {
+		return "verifier:" + v.Username
+	}
+	return ""
+}
+
+// getVerifierActor returns the audit actor of the authenticated verifier.
+func getVerifierActor(c *gin.Context) audit.Actor {
+	if v := getVerifier(c); v != nil {
+		return service.VerifierActor(v)
+	}
+	return audit.Actor{}
+}
+
+// Login handles POST /veda/login
+func (h *VerifierHandler) Login(c *gin.Context) {
+	var req entity.VerifierLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, response.ErrCodeValidationError, "Format data tidak valid")
+		return
+	}
+
+	result, err := h.verifierSvc.Login(c.Request.Context(), req, c.GetHeader("User-Agent"), c.ClientIP())
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidVerifierCredentials):
+			response.Unauthorized(c, response.ErrCodeInvalidCredentials, "Username atau password salah")
+		case errors.Is(err, service.ErrVerifierLocked):
+			response.Unauthorized(c, response.ErrCodeAccountLocked, "Akun terkunci sementara karena terlalu banyak percobaan login gagal")
+		default:
+			handleVedikaError(c, err)
+		}
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// Logout handles POST /veda/logout
+func (h *VerifierHandler) Logout(c *gin.Context) {
+	if err := h.verifierSvc.Logout(c.Request.Context(), c.GetString(contextKeyVerifierSession), getVerifierActor(c), c.ClientIP()); err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Berhasil logout", nil)
+}
+
+// Me handles GET /veda/me
+func (h *VerifierHandler) Me(c *gin.Context) {
+	response.Success(c, getVerifier(c))
+}
+
+// GetDashboard handles GET /veda/dashboard
+func (h *VerifierHandler) GetDashboard(c *gin.Context) {
+	dashboard, err := h.verifierSvc.GetDashboard(c.Request.Context(), getVerifierActor(c), c.ClientIP())
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.Success(c, dashboard)
+}
+
+// ListClaims handles GET /veda/claims
+// Query params: status (pengajuan|perbaikan|lengkap|setuju, default lengkap),
+// jenis, page, limit, search. Always limited to the active period.
+func (h *VerifierHandler) ListClaims(c *gin.Context) {
+	filter := entity.IndexFilter{
+		Status: entity.ClaimStatus(c.DefaultQuery("status", string(entity.StatusLengkap))).Normalize(),
+		Search: c.Query("search"),
+		Page:   1,
+		Limit:  10,
+	}
+	if !entity.IsVerifierStatus(filter.Status) {
+		response.BadRequest(c, "INVALID_PARAMS", "status must be pengajuan, perbaikan, lengkap or setuju")
+		return
+	}
+	switch c.Query("jenis") {
+	case "ralan":
+		filter.Jenis = entity.JenisRalan
+	case "ranap":
+		filter.Jenis = entity.JenisRanap
+	}
+	if page, err := strconv.Atoi(c.Query("page")); err == nil && page > 0 {
+		filter.Page = page
+	}
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil && limit > 0 && limit <= 100 {
+		filter.Limit = limit
+	}
+
+	result, err := h.verifierSvc.ListClaims(c.Request.Context(), filter, getVerifierActor(c), c.ClientIP())
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"filter": gin.H{
+			"status": filter.Status,
+			"jenis":  filter.Jenis,
+		},
+		"pagination": gin.H{
+			"page":  result.Page,
+			"limit": result.Limit,
+			"total": result.Total,
+		},
+		"items": result.Data,
+	})
+}
+
+// GetClaim handles GET /veda/claim/full/:no_rawat
+func (h *VerifierHandler) GetClaim(c *gin.Context) {
+	noRawat := decodeNoRawat(c.Param("no_rawat"))
+
+	detail, err := h.verifierSvc.GetClaim(c.Request.Context(), noRawat, getVerifierActor(c), c.ClientIP())
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.Success(c, detail)
+}
+
+// GetClaimPDF handles GET /veda/claim/pdf/:no_rawat
+func (h *VerifierHandler) GetClaimPDF(c *gin.Context) {
+	noRawat := decodeNoRawat(c.Param("no_rawat"))
+
+	data, err := h.verifierSvc.GetClaimPDF(c.Request.Context(), noRawat, getVerifierActor(c), c.ClientIP())
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	filename := "klaim-" + strings.ReplaceAll(noRawat, "/", "_") + ".pdf"
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
+// GetStatusHistory handles GET /veda/claim/history/:no_rawat
+func (h *VerifierHandler) GetStatusHistory(c *gin.Context) {
+	noRawat := decodeNoRawat(c.Param("no_rawat"))
+
+	timeline, err := h.verifierSvc.GetStatusHistory(c.Request.Context(), noRawat, getVerifierActor(c), c.ClientIP())
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.Success(c, timeline)
+}
+
+// ListFeedback handles GET /veda/claim/feedback/:no_rawat
+func (h *VerifierHandler) ListFeedback(c *gin.Context) {
+	noRawat := decodeNoRawat(c.Param("no_rawat"))
+
+	threads, err := h.verifierSvc.ListFeedback(c.Request.Context(), noRawat, getVerifierActor(c), c.ClientIP())
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.Success(c, threads)
+}
+
+// AddFeedback handles POST /veda/claim/feedback/:no_rawat
+func (h *VerifierHandler) AddFeedback(c *gin.Context) {
+	noRawat := decodeNoRawat(c.Param("no_rawat"))
+
+	var req entity.FeedbackCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Catatan) == "" {
+		response.BadRequest(c, "INVALID_REQUEST", "catatan is required")
+		return
+	}
+
+	note, err := h.verifierSvc.AddFeedback(c.Request.Context(), noRawat, req, getVerifierActor(c), c.ClientIP())
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.Created(c, note)
+}
+
+// Approve handles POST /veda/claim/approve/:no_rawat
+func (h *VerifierHandler) Approve(c *gin.Context) {
+	noRawat := decodeNoRawat(c.Param("no_rawat"))
+
+	var req entity.VerifierDecisionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "INVALID_REQUEST", "Invalid request body")
+			return
+		}
+	}
+
+	if err := h.verifierSvc.Approve(c.Request.Context(), noRawat, req, getVerifierActor(c), c.ClientIP()); err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Klaim disetujui", gin.H{"status": entity.StatusSetuju})
+}
+
+// Return handles POST /veda/claim/return/:no_rawat
+func (h *VerifierHandler) Return(c *gin.Context) {
+	noRawat := decodeNoRawat(c.Param("no_rawat"))
+
+	var req entity.VerifierDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Catatan) == "" {
+		response.BadRequest(c, "INVALID_REQUEST", "catatan is required")
+		return
+	}
+
+	if err := h.verifierSvc.Return(c.Request.Context(), noRawat, req, getVerifierActor(c), c.ClientIP()); err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Klaim dikembalikan untuk perbaikan", gin.H{"status": entity.StatusPerbaikan})
+}
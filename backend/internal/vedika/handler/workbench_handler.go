@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"path"
 	"strconv"
 	"strings"
 
@@ -14,6 +16,10 @@ import (
 	"github.com/clinova/simrs/backend/pkg/response"
 )
 
+// maxUploadRequestBytes caps a document upload request, above any
+// per-category limit in mera_vedika_document_rules.
+const maxUploadRequestBytes = 64 << 20
+
 // WorkbenchHandler handles Index workbench HTTP requests.
 type WorkbenchHandler struct {
 	workbenchSvc *service.WorkbenchService
@@ -25,7 +31,8 @@ func NewWorkbenchHandler(workbenchSvc *service.WorkbenchService) *WorkbenchHandl
 }
 
 // ListIndex handles GET /admin/vedika/index
-// Query params: date_from, date_to, status, jenis, page, limit, search
+// Query params: date_from, date_to, status, jenis, page, limit, search,
+// assigned_to (mera_users.id or "me")
 func (h *WorkbenchHandler) ListIndex(c *gin.Context) {
 	filter := h.parseIndexFilter(c)
 	actor := getActor(c)
@@ -49,10 +56,11 @@ func (h *WorkbenchHandler) ListIndex(c *gin.Context) {
 
 	response.Success(c, gin.H{
 		"filter": gin.H{
-			"date_from": filter.DateFrom,
-			"date_to":   filter.DateTo,
-			"status":    filter.Status,
-			"jenis":     filter.Jenis,
+			"date_from":   filter.DateFrom,
+			"date_to":     filter.DateTo,
+			"status":      filter.Status,
+			"jenis":       filter.Jenis,
+			"assigned_to": filter.AssignedTo,
 		},
 		"pagination": gin.H{
 			"page":  result.Page,
@@ -74,6 +82,7 @@ func (h *WorkbenchHandler) GetClaimDetail(c *gin.Context) {
 		handleVedikaError(c, err)
 		return
 	}
+	setETag(c, detail.Version)
 
 	response.Success(c, detail)
 }
@@ -90,7 +99,7 @@ func (h *WorkbenchHandler) UpdateStatus(c *gin.Context) {
 		return
 	}
 
-	if err := h.workbenchSvc.UpdateClaimStatus(c.Request.Context(), noRawat, req, actor, ip); err != nil {
+	if err := h.workbenchSvc.UpdateClaimStatus(c.Request.Context(), noRawat, req, getGrantedPermissions(c), actor, ip); err != nil {
 		handleVedikaError(c, err)
 		return
 	}
@@ -98,6 +107,21 @@ func (h *WorkbenchHandler) UpdateStatus(c *gin.Context) {
 	response.SuccessWithMessage(c, "Status berhasil diubah", gin.H{"status": req.Status})
 }
 
+// GetStatusHistory handles GET /admin/vedika/claim/history/:no_rawat
+func (h *WorkbenchHandler) GetStatusHistory(c *gin.Context) {
+	noRawat := decodeNoRawat(c.Param("no_rawat"))
+	actor := getActor(c)
+	ip := c.ClientIP()
+
+	timeline, err := h.workbenchSvc.GetStatusHistory(c.Request.Context(), noRawat, actor, ip)
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+
+	response.Success(c, timeline)
+}
+
 // BatchUpdateStatus handles POST /admin/vedika/claim/batch-status
 func (h *WorkbenchHandler) BatchUpdateStatus(c *gin.Context) {
 	actor := getActor(c)
@@ -109,7 +133,7 @@ func (h *WorkbenchHandler) BatchUpdateStatus(c *gin.Context) {
 		return
 	}
 
-	result, err := h.workbenchSvc.BatchUpdateClaimStatus(c.Request.Context(), req, actor, ip)
+	result, err := h.workbenchSvc.BatchUpdateClaimStatus(c.Request.Context(), req, getGrantedPermissions(c), actor, ip)
 	if err != nil {
 		handleVedikaError(c, err)
 		return
@@ -119,6 +143,7 @@ func (h *WorkbenchHandler) BatchUpdateStatus(c *gin.Context) {
 }
 
 // UpdateDiagnosis handles POST /admin/vedika/claim/:no_rawat/diagnosis
+// Requires If-Match with the medical data version (ETag of the claim detail).
 func (h *WorkbenchHandler) UpdateDiagnosis(c *gin.Context) {
 	noRawat := decodeNoRawat(c.Param("no_rawat"))
 	actor := getActor(c)
@@ -130,15 +155,18 @@ func (h *WorkbenchHandler) UpdateDiagnosis(c *gin.Context) {
 		return
 	}
 
-	if err := h.workbenchSvc.UpdateDiagnosis(c.Request.Context(), noRawat, req, actor, ip); err != nil {
+	validation, version, err := h.workbenchSvc.UpdateDiagnosis(c.Request.Context(), noRawat, req, ifMatchVersion(c), actor, ip)
+	if err != nil {
 		handleVedikaError(c, err)
 		return
 	}
+	setETag(c, version)
 
-	response.SuccessWithMessage(c, "Diagnosa berhasil diubah", nil)
+	response.SuccessWithMessage(c, "Diagnosa berhasil diubah", validation)
 }
 
 // SyncDiagnoses handles PUT /admin/vedika/claim/:no_rawat/diagnosis
+// Requires If-Match with the medical data version (ETag of the claim detail).
 func (h *WorkbenchHandler) SyncDiagnoses(c *gin.Context) {
 	noRawat := decodeNoRawat(c.Param("no_rawat"))
 	actor := getActor(c)
@@ -150,15 +178,18 @@ func (h *WorkbenchHandler) SyncDiagnoses(c *gin.Context) {
 		return
 	}
 
-	if err := h.workbenchSvc.SyncDiagnoses(c.Request.Context(), noRawat, req, actor, ip); err != nil {
+	validation, version, err := h.workbenchSvc.SyncDiagnoses(c.Request.Context(), noRawat, req, ifMatchVersion(c), actor, ip)
+	if err != nil {
 		handleVedikaError(c, err)
 		return
 	}
+	setETag(c, version)
 
-	response.SuccessWithMessage(c, "Daftar diagnosa berhasil diperbarui", nil)
+	response.SuccessWithMessage(c, "Daftar diagnosa berhasil diperbarui", validation)
 }
 
 // UpdateProcedure handles POST /admin/vedika/claim/:no_rawat/procedure
+// Requires If-Match with the medical data version (ETag of the claim detail).
 func (h *WorkbenchHandler) UpdateProcedure(c *gin.Context) {
 	noRawat := decodeNoRawat(c.Param("no_rawat"))
 	actor := getActor(c)
@@ -170,15 +201,18 @@ func (h *WorkbenchHandler) UpdateProcedure(c *gin.Context) {
 		return
 	}
 
-	if err := h.workbenchSvc.UpdateProcedure(c.Request.Context(), noRawat, req, actor, ip); err != nil {
+	validation, version, err := h.workbenchSvc.UpdateProcedure(c.Request.Context(), noRawat, req, ifMatchVersion(c), actor, ip)
+	if err != nil {
 		handleVedikaError(c, err)
 		return
 	}
+	setETag(c, version)
 
-	response.SuccessWithMessage(c, "Prosedur berhasil diubah", nil)
+	response.SuccessWithMessage(c, "Prosedur berhasil diubah", validation)
 }
 
 // SyncProcedures handles PUT /admin/vedika/claim/:no_rawat/procedure
+// Requires If-Match with the medical data version (ETag of the claim detail).
 func (h *WorkbenchHandler) SyncProcedures(c *gin.Context) {
 	noRawat := decodeNoRawat(c.Param("no_rawat"))
 	actor := getActor(c)
@@ -190,20 +224,38 @@ func (h *WorkbenchHandler) SyncProcedures(c *gin.Context) {
 		return
 	}
 
-	if err := h.workbenchSvc.SyncProcedures(c.Request.Context(), noRawat, req, actor, ip); err != nil {
+	validation, version, err := h.workbenchSvc.SyncProcedures(c.Request.Context(), noRawat, req, ifMatchVersion(c), actor, ip)
+	if err != nil {
 		handleVedikaError(c, err)
 		return
 	}
+	setETag(c, version)
 
-	response.SuccessWithMessage(c, "Daftar prosedur berhasil diperbarui", nil)
+	response.SuccessWithMessage(c, "Daftar prosedur berhasil diperbarui", validation)
 }
 
 // UploadDocument handles POST /admin/vedika/claim/documents/:no_rawat
+// Form fields: kode (master_berkas_digital category), file
 func (h *WorkbenchHandler) UploadDocument(c *gin.Context) {
 	noRawat := decodeNoRawat(c.Param("no_rawat"))
 	actor := getActor(c)
 	ip := c.ClientIP()
 
+	// Cap the request before multipart parsing; per-category limits are
+	// enforced by the service
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadRequestBytes)
+
+	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			response.Error(c, http.StatusRequestEntityTooLarge, entity.UploadTooLarge,
+				fmt.Sprintf("Ukuran unggahan melebihi batas %d MB", maxUploadRequestBytes>>20))
+			return
+		}
+		response.BadRequest(c, "INVALID_FILE", "File is required")
+		return
+	}
+
 	kode := c.PostForm("kode")
 	if kode == "" {
 		response.BadRequest(c, "INVALID_PARAMS", "kode (category) is required")
@@ -216,38 +268,16 @@ func (h *WorkbenchHandler) UploadDocument(c *gin.Context) {
 		return
 	}
 
-	// Clean noRawat for filename (replace slashes with underscores)
-	cleanNoRawat := strings.ReplaceAll(noRawat, "/", "_")
-	filename := fmt.Sprintf("%s_%s_%s", cleanNoRawat, kode, file.Filename)
-
-	// In simrs legacy, path is pages/upload/filename
-	dbPath := "pages/upload/" + filename
-
-	// Derive physical path from dynamic URL setting
-	// Default to 'webapps' if fetch fails to avoid breaking existing setups
-	subFolder := "webapps"
-	if baseURL, err := h.workbenchSvc.GetLegacyWebAppURL(c.Request.Context()); err == nil && baseURL != "" {
-		if u, err := url.Parse(baseURL); err == nil {
-			// Extract path from URL (e.g., "/webapps/" -> "webapps")
-			cleanedPath := strings.Trim(u.Path, "/")
-			subFolder = cleanedPath
-		}
-	}
-
-	// build uploadDir relative to Clinova root
-	// If subFolder is empty, it points to Laragon www root
-	uploadDir := "../../"
-	if subFolder != "" {
-		uploadDir += subFolder + "/"
-	}
-	uploadDir += "berkasrawat/pages/upload/"
-
-	if err := c.SaveUploadedFile(file, uploadDir+filename); err != nil {
-		response.Error(c, http.StatusInternalServerError, "UPLOAD_FAILED", "Failed to save file: "+err.Error())
+	src, err := file.Open()
+	if err != nil {
+		response.BadRequest(c, "INVALID_FILE", "File cannot be read")
 		return
 	}
+	defer src.Close()
 
-	if err := h.workbenchSvc.AddDigitalDocument(c.Request.Context(), noRawat, kode, dbPath, actor, ip); err != nil {
+	// Stored under the legacy key pages/upload/<file>, which is also the DB path
+	dbPath, err := h.workbenchSvc.UploadDigitalDocument(c.Request.Context(), noRawat, kode, file.Filename, src, file.Size, actor, ip)
+	if err != nil {
 		handleVedikaError(c, err)
 		return
 	}
@@ -277,6 +307,34 @@ func (h *WorkbenchHandler) DeleteDocument(c *gin.Context) {
 	response.SuccessWithMessage(c, "Berkas berhasil dihapus", nil)
 }
 
+// DownloadDocument handles GET /admin/vedika/claim/documents/file/:no_rawat?path=pages/upload/...
+// Streams a document recorded for the episode from the document store.
+func (h *WorkbenchHandler) DownloadDocument(c *gin.Context) {
+	noRawat := decodeNoRawat(c.Param("no_rawat"))
+	actor := getActor(c)
+	ip := c.ClientIP()
+
+	lokasiFile := c.Query("path")
+	if lokasiFile == "" {
+		response.BadRequest(c, "INVALID_PARAMS", "path is required")
+		return
+	}
+
+	body, info, err := h.workbenchSvc.OpenDigitalDocument(c.Request.Context(), noRawat, lokasiFile, actor, ip)
+	if err != nil {
+		handleVedikaError(c, err)
+		return
+	}
+	defer body.Close()
+
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", path.Base(lokasiFile)))
+	c.DataFromReader(http.StatusOK, info.Size, contentType, body, nil)
+}
+
 // GetMasterDigitalDocs handles GET /admin/vedika/documents/master
 func (h *WorkbenchHandler) GetMasterDigitalDocs(c *gin.Context) {
 	results, err := h.workbenchSvc.GetMasterDigitalDocs(c.Request.Context())
@@ -288,6 +346,7 @@ func (h *WorkbenchHandler) GetMasterDigitalDocs(c *gin.Context) {
 }
 
 // SaveResume handles POST /admin/vedika/claim/resume/:no_rawat
+// Requires If-Match with the medical data version (ETag of the resume).
 func (h *WorkbenchHandler) SaveResume(c *gin.Context) {
 	noRawat := decodeNoRawat(c.Param("no_rawat"))
 	actor := getActor(c)
@@ -299,10 +358,12 @@ func (h *WorkbenchHandler) SaveResume(c *gin.Context) {
 		return
 	}
 
-	if err := h.workbenchSvc.UpdateResume(c.Request.Context(), noRawat, &req, actor, ip); err != nil {
+	version, err := h.workbenchSvc.UpdateResume(c.Request.Context(), noRawat, &req, ifMatchVersion(c), actor, ip)
+	if err != nil {
 		handleVedikaError(c, err)
 		return
 	}
+	setETag(c, version)
 
 	response.SuccessWithMessage(c, "Resume medis berhasil disimpan", nil)
 }
@@ -318,6 +379,7 @@ func (h *WorkbenchHandler) GetResume(c *gin.Context) {
 		handleVedikaError(c, err)
 		return
 	}
+	setETag(c, resume.Version)
 
 	response.Success(c, resume)
 }
@@ -341,6 +403,15 @@ func (h *WorkbenchHandler) parseIndexFilter(c *gin.Context) entity.IndexFilter {
 		filter.Jenis = entity.JenisRanap
 	}
 
+	// Parse assigned coder; "me" is the caller's own queue
+	switch assignedTo := c.Query("assigned_to"); assignedTo {
+	case "":
+	case "me":
+		filter.AssignedTo = getActor(c).UserID
+	default:
+		filter.AssignedTo = assignedTo
+	}
+
 	// Parse pagination
 	if page, err := strconv.Atoi(c.Query("page")); err == nil && page > 0 {
 		filter.Page = page
@@ -395,3 +466,18 @@ func decodeNoRawat(encoded string) string {
 	}
 	return decoded
 }
+
+// ifMatchVersion returns the medical data version from the If-Match header,
+// without quotes or a weak prefix. Only the first listed tag is used.
+func ifMatchVersion(c *gin.Context) string {
+	tag, _, _ := strings.Cut(c.GetHeader("If-Match"), ",")
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+	return strings.Trim(tag, `"`)
+}
+
+// setETag exposes a medical data version as the response ETag.
+func setETag(c *gin.Context, version string) {
+	if version != "" {
+		c.Header("ETag", `"`+version+`"`)
+	}
+}
@@ -0,0 +1,248 @@
+package inacbg
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+)
+
+// ErrMissingSEP indicates the claim has no SEP and cannot be bridged.
+var ErrMissingSEP = errors.New("claim has no SEP")
+
+// ClaimOptions carries the values not derivable from ClaimFullDetail.
+type ClaimOptions struct {
+	CoderNIK string
+	// Tarif overrides the billing-derived tariff when set.
+	Tarif *TarifRS
+}
+
+// BuildNewClaim builds the new_claim data block from a claim.
+func BuildNewClaim(detail *entity.ClaimFullDetail) (NewClaimRequest, error) {
+	if detail.SEP == nil || detail.SEP.NoSEP == "" {
+		return NewClaimRequest{}, ErrMissingSEP
+	}
+
+	tglLahir := detail.Patient.TglLahir
+	if tglLahir == "" {
+		tglLahir = detail.SEP.TglLahir
+	}
+
+	return NewClaimRequest{
+		NomorKartu: detail.SEP.NoKartu,
+		NomorSEP:   detail.SEP.NoSEP,
+		NomorRM:    detail.Patient.NoRM,
+		NamaPasien: detail.Patient.NamaPasien,
+		TglLahir:   dateTime(tglLahir, ""),
+		Gender:     gender(detail.Patient.JenisKelamin),
+	}, nil
+}
+
+// BuildClaimData builds the set_claim_data block from a claim and its billing.
+func BuildClaimData(detail *entity.ClaimFullDetail, cfg Config, opts ClaimOptions) (ClaimData, error) {
+	if detail.SEP == nil || detail.SEP.NoSEP == "" {
+		return ClaimData{}, ErrMissingSEP
+	}
+
+	data := ClaimData{
+		NomorSEP:        detail.SEP.NoSEP,
+		NomorKartu:      detail.SEP.NoKartu,
+		KelasRawat:      kelasRawat(detail.SEP.KelasRawat),
+		DischargeStatus: DischargeAtasPersetujuan,
+		Diagnosa:        joinDiagnoses(detail.Diagnoses),
+		Procedure:       joinProcedures(detail.Procedures),
+		NamaDokter:      namaDokter(detail),
+		KodeTarif:       cfg.KodeTarif,
+		PayorID:         cfg.PayorID,
+		PayorCD:         cfg.PayorCD,
+		CoderNIK:        opts.CoderNIK,
+	}
+
+	// Admission window: registration for ralan, first/last room stay for ranap
+	data.TglMasuk = dateTime(detail.Patient.TglRegistrasi, detail.Patient.JamReg)
+	data.TglPulang = data.TglMasuk
+	soap := detail.SOAPExamsRalan
+	if strings.EqualFold(detail.StatusLanjut, "Ranap") {
+		data.JenisRawat = JenisRawatInap
+		soap = detail.SOAPExamsRanap
+		if n := len(detail.RoomStays); n > 0 {
+			first, last := detail.RoomStays[0], detail.RoomStays[n-1]
+			data.TglMasuk = dateTime(first.TglMasuk, first.JamMasuk)
+			data.TglPulang = dateTime(last.TglKeluar, last.JamKeluar)
+			data.DischargeStatus = dischargeStatus(last.StatusPulang)
+		}
+	} else {
+		data.JenisRawat = JenisRawatJalan
+	}
+
+	// Last recorded blood pressure
+	if n := len(soap); n > 0 {
+		data.Sistole, data.Diastole = bloodPressure(soap[n-1].Tensi)
+	}
+
+	if opts.Tarif != nil {
+		data.TarifRS = *opts.Tarif
+	} else {
+		data.TarifRS = TarifFromBilling(detail.Billing)
+	}
+
+	return data, nil
+}
+
+// TarifFromBilling sums billing lines into the E-Klaim cost components by
+// their INA-CBG category (see entity.BillingSummary.ClassifyTarif). Lines
+// not yet classified are guessed from their name.
+func TarifFromBilling(billing *entity.BillingSummary) TarifRS {
+	var tarif TarifRS
+	if billing == nil {
+		return tarif
+	}
+
+	var unmapped entity.TarifMapping
+	for _, cat := range billing.Categories {
+		for _, item := range cat.Items {
+			kategori := item.KategoriINACBG
+			if kategori == "" {
+				kategori = unmapped.Classify(cat.Kategori, item)
+			}
+			*tarif.component(kategori) += item.TotalBiaya
+		}
+	}
+	return tarif
+}
+
+// component returns the field of an entity.Tarif* component. Unknown
+// components count as prosedur non-bedah.
+func (t *TarifRS) component(kode string) *float64 {
+	switch kode {
+	case entity.TarifProsedurBedah:
+		return &t.ProsedurBedah
+	case entity.TarifKonsultasi:
+		return &t.Konsultasi
+	case entity.TarifTenagaAhli:
+		return &t.TenagaAhli
+	case entity.TarifKeperawatan:
+		return &t.Keperawatan
+	case entity.TarifPenunjang:
+		return &t.Penunjang
+	case entity.TarifRadiologi:
+		return &t.Radiologi
+	case entity.TarifLaboratorium:
+		return &t.Laboratorium
+	case entity.TarifPelayananDarah:
+		return &t.PelayananDarah
+	case entity.TarifRehabilitasi:
+		return &t.Rehabilitasi
+	case entity.TarifKamar:
+		return &t.Kamar
+	case entity.TarifRawatIntensif:
+		return &t.RawatIntensif
+	case entity.TarifObat:
+		return &t.Obat
+	case entity.TarifObatKronis:
+		return &t.ObatKronis
+	case entity.TarifObatKemoterapi:
+		return &t.ObatKemoterapi
+	case entity.TarifAlkes:
+		return &t.Alkes
+	case entity.TarifBMHP:
+		return &t.BMHP
+	case entity.TarifSewaAlat:
+		return &t.SewaAlat
+	}
+	return &t.ProsedurNonBedah
+}
+
+func joinDiagnoses(items []entity.DiagnosisItem) string {
+	sorted := append([]entity.DiagnosisItem{}, items...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Prioritas < sorted[j].Prioritas })
+	codes := make([]string, 0, len(sorted))
+	for _, d := range sorted {
+		if d.KodePenyakit != "" {
+			codes = append(codes, d.KodePenyakit)
+		}
+	}
+	return strings.Join(codes, "#")
+}
+
+func joinProcedures(items []entity.ProcedureItem) string {
+	sorted := append([]entity.ProcedureItem{}, items...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Prioritas < sorted[j].Prioritas })
+	codes := make([]string, 0, len(sorted))
+	for _, p := range sorted {
+		if p.Kode != "" {
+			codes = append(codes, p.Kode)
+		}
+	}
+	return strings.Join(codes, "#")
+}
+
+func namaDokter(detail *entity.ClaimFullDetail) string {
+	if len(detail.Patient.DPJPList) > 0 {
+		return strings.Join(detail.Patient.DPJPList, ", ")
+	}
+	if detail.Patient.Dokter != "" {
+		return detail.Patient.Dokter
+	}
+	return detail.SEP.DPJP
+}
+
+// dateTime joins a date and time into E-Klaim's YYYY-MM-DD HH:MM:SS format.
+func dateTime(date, clock string) string {
+	date = strings.TrimSpace(date)
+	if date == "" {
+		return ""
+	}
+	if len(date) > 10 {
+		return date
+	}
+	clock = strings.TrimSpace(clock)
+	if clock == "" {
+		clock = "00:00:00"
+	}
+	return date + " " + clock
+}
+
+func gender(jk string) string {
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(jk)), "P") {
+		return "2"
+	}
+	return "1"
+}
+
+func kelasRawat(kelas string) string {
+	for _, r := range kelas {
+		if r >= '1' && r <= '3' {
+			return string(r)
+		}
+	}
+	return "3"
+}
+
+// dischargeStatus maps kamar_inap.stts_pulang to the E-Klaim discharge code.
+func dischargeStatus(status string) string {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "rujuk":
+		return DischargeDirujuk
+	case "aps", "atas permintaan sendiri", "pulang paksa":
+		return DischargeAPS
+	case "meninggal", "+":
+		return DischargeMeninggal
+	case "lain-lain", "isoman":
+		return DischargeLainLain
+	}
+	return DischargeAtasPersetujuan
+}
+
+// bloodPressure parses "120/80" into sistole and diastole.
+func bloodPressure(tensi string) (int, int) {
+	parts := strings.SplitN(tensi, "/", 2)
+	if len(parts) != 2 {
+		return 0, 0
+	}
+	sistole, _ := strconv.Atoi(strings.TrimSpace(parts[0]))
+	diastole, _ := strconv.Atoi(strings.TrimSpace(parts[1]))
+	return sistole, diastole
+}
@@ -0,0 +1,51 @@
+package inacbg
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+)
+
+func TestTarifFromBillingCoversEveryComponent(t *testing.T) {
+	// One line per component, each with a distinct amount
+	billing := &entity.BillingSummary{}
+	cat := entity.BillingCategory{Kategori: "Tindakan"}
+	for i, c := range entity.TarifComponents {
+		cat.Items = append(cat.Items, entity.BillingItem{KategoriINACBG: c.Kode, TotalBiaya: float64(i + 1)})
+	}
+	billing.Categories = append(billing.Categories, cat)
+
+	raw, err := json.Marshal(TarifFromBilling(billing))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]float64
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range entity.TarifComponents {
+		if fields[c.Kode] != float64(i+1) {
+			t.Errorf("tarif_rs.%s = %v, want %d", c.Kode, fields[c.Kode], i+1)
+		}
+	}
+}
+
+func TestTarifFromBillingClassifiesUntaggedLines(t *testing.T) {
+	billing := &entity.BillingSummary{Categories: []entity.BillingCategory{
+		{Kategori: "Laborat", Items: []entity.BillingItem{{NamaPerawatan: "Darah Lengkap", TotalBiaya: 80000, KategoriINACBG: entity.TarifLaboratorium}}},
+		{Kategori: "Kamar", Items: []entity.BillingItem{{NamaPerawatan: "Kelas 1", TotalBiaya: 500000}}},
+		{Kategori: "Tindakan", Items: []entity.BillingItem{{NamaPerawatan: "Nebulizer", TotalBiaya: 60000, KategoriINACBG: "unknown"}}},
+	}}
+
+	tarif := TarifFromBilling(billing)
+	if tarif.Laboratorium != 80000 || tarif.Kamar != 500000 || tarif.ProsedurNonBedah != 60000 {
+		t.Fatalf("got %+v", tarif)
+	}
+	if tarif.Total() != 640000 {
+		t.Fatalf("total %.0f, want 640000", tarif.Total())
+	}
+	if got := TarifFromBilling(nil); got != (TarifRS{}) {
+		t.Fatalf("nil billing: got %+v", got)
+	}
+}
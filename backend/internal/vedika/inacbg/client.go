@@ -0,0 +1,248 @@
+// Package inacbg implements the E-Klaim INA-CBG web service bridging protocol.
+//
+// Every request is a JSON document {"metadata": {...}, "data": {...}} posted to
+// the E-Klaim ws.php endpoint, encrypted with the hospital's 256-bit key.
+// Responses are encrypted the same way and framed with ENCRYPTED DATA markers.
+package inacbg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SettingsModule is the mera_settings module holding E-Klaim configuration.
+const SettingsModule = "inacbg.eklaim"
+
+// Error numbers returned by E-Klaim that callers react to.
+const (
+	ErrNoDuplicateSEP = "E2007" // new_claim: nomor SEP sudah ada
+)
+
+var (
+	// ErrNotConfigured indicates the E-Klaim settings are incomplete.
+	ErrNotConfigured = errors.New("e-klaim bridging is not configured")
+)
+
+// APIError is a non-200 metadata block returned by E-Klaim.
+type APIError struct {
+	Method  string
+	Code    int
+	ErrorNo string
+	Message string
+}
+
+// Error implements error.
+func (e *APIError) Error() string {
+	if e.ErrorNo != "" {
+		return fmt.Sprintf("e-klaim %s failed (%d %s): %s", e.Method, e.Code, e.ErrorNo, e.Message)
+	}
+	return fmt.Sprintf("e-klaim %s failed (%d): %s", e.Method, e.Code, e.Message)
+}
+
+// IsDuplicateClaim reports whether err is new_claim's duplicate SEP error.
+func IsDuplicateClaim(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorNo == ErrNoDuplicateSEP
+}
+
+// Config holds E-Klaim connection and claim defaults.
+type Config struct {
+	BaseURL   string // Full ws.php URL, e.g. http://10.0.0.5/E-Klaim/ws.php
+	Key       []byte // 256-bit encryption key
+	KodeTarif string // Hospital tariff class, e.g. AP, BP, CS
+	PayorID   string // 3 = JKN
+	PayorCD   string // JKN
+	Timeout   time.Duration
+}
+
+// ConfigFromSettings builds a Config from the inacbg.eklaim settings
+// (base_url, encryption_key, kode_tarif, payor_id, payor_cd).
+func ConfigFromSettings(settings map[string]string) (Config, error) {
+	cfg := Config{
+		BaseURL:   strings.TrimSpace(settings["base_url"]),
+		KodeTarif: strings.TrimSpace(settings["kode_tarif"]),
+		PayorID:   strings.TrimSpace(settings["payor_id"]),
+		PayorCD:   strings.TrimSpace(settings["payor_cd"]),
+		Timeout:   30 * time.Second,
+	}
+	if cfg.BaseURL == "" || settings["encryption_key"] == "" || cfg.KodeTarif == "" {
+		return cfg, ErrNotConfigured
+	}
+
+	key, err := ParseKey(settings["encryption_key"])
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Key = key
+
+	if cfg.PayorID == "" {
+		cfg.PayorID = "3"
+	}
+	if cfg.PayorCD == "" {
+		cfg.PayorCD = "JKN"
+	}
+	return cfg, nil
+}
+
+// Client calls the E-Klaim web service.
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+}
+
+// NewClient creates a new E-Klaim client.
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.BaseURL == "" {
+		return nil, ErrNotConfigured
+	}
+	if len(cfg.Key) != 32 {
+		return nil, ErrInvalidKey
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	return &Client{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+	}, nil
+}
+
+// Config returns the client configuration.
+func (c *Client) Config() Config {
+	return c.cfg
+}
+
+// NewClaim registers a SEP in E-Klaim.
+func (c *Client) NewClaim(ctx context.Context, req NewClaimRequest) (*NewClaimResponse, error) {
+	var resp NewClaimResponse
+	if _, err := c.call(ctx, map[string]interface{}{"method": MethodNewClaim}, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SetClaimData fills in the clinical and tariff data of a claim.
+func (c *Client) SetClaimData(ctx context.Context, data ClaimData) error {
+	meta := map[string]interface{}{"method": MethodSetClaimData, "nomor_sep": data.NomorSEP}
+	_, err := c.call(ctx, meta, data, nil)
+	return err
+}
+
+// GroupStage1 runs the grouper and returns the CBG plus special CMG options.
+func (c *Client) GroupStage1(ctx context.Context, nomorSEP string) (*GrouperResult, error) {
+	meta := map[string]interface{}{"method": MethodGrouper, "stage": "1"}
+	return c.group(ctx, meta, map[string]string{"nomor_sep": nomorSEP})
+}
+
+// GroupStage2 regroups with the chosen special CMG codes.
+func (c *Client) GroupStage2(ctx context.Context, nomorSEP string, specialCMG []string) (*GrouperResult, error) {
+	meta := map[string]interface{}{"method": MethodGrouper, "stage": "2"}
+	return c.group(ctx, meta, map[string]string{
+		"nomor_sep":   nomorSEP,
+		"special_cmg": strings.Join(specialCMG, "#"),
+	})
+}
+
+// ClaimFinal locks the claim for submission.
+func (c *Client) ClaimFinal(ctx context.Context, nomorSEP, coderNIK string) error {
+	_, err := c.call(ctx, map[string]interface{}{"method": MethodClaimFinal}, map[string]string{
+		"nomor_sep": nomorSEP,
+		"coder_nik": coderNIK,
+	}, nil)
+	return err
+}
+
+// SendClaimIndividual sends a finalized claim to the data center.
+func (c *Client) SendClaimIndividual(ctx context.Context, nomorSEP string) (*SendClaimResult, error) {
+	var resp SendClaimResult
+	if _, err := c.call(ctx, map[string]interface{}{"method": MethodSendClaimIndividual}, map[string]string{
+		"nomor_sep": nomorSEP,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data == nil {
+		resp.Data = []SentClaim{}
+	}
+	return &resp, nil
+}
+
+func (c *Client) group(ctx context.Context, meta map[string]interface{}, data interface{}) (*GrouperResult, error) {
+	var result GrouperResult
+	env, err := c.call(ctx, meta, data, &result)
+	if err != nil {
+		return nil, err
+	}
+	result.SpecialCMGOption = env.SpecialCMGOption
+	result.TarifAlt = env.TarifAlt
+	return &result, nil
+}
+
+// call encrypts the request, posts it and decodes the response block into out.
+func (c *Client) call(ctx context.Context, meta map[string]interface{}, data interface{}, out interface{}) (*envelope, error) {
+	method, _ := meta["method"].(string)
+
+	body, err := json.Marshal(map[string]interface{}{"metadata": meta, "data": data})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+	encrypted, err := Encrypt(c.cfg.Key, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt %s request: %w", method, err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.BaseURL, strings.NewReader(encrypted))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build %s request: %w", method, err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("e-klaim %s request failed: %w", method, err)
+	}
+	defer httpResp.Body.Close()
+
+	raw, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response: %w", method, err)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, &APIError{Method: method, Code: httpResp.StatusCode, Message: strings.TrimSpace(string(raw))}
+	}
+
+	// Error responses before authentication are sent unencrypted
+	plain := bytes.TrimSpace(raw)
+	if !bytes.HasPrefix(plain, []byte("{")) {
+		if plain, err = Decrypt(c.cfg.Key, string(raw)); err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s response: %w", method, err)
+		}
+	}
+
+	var env envelope
+	if err := json.Unmarshal(plain, &env); err != nil {
+		return nil, fmt.Errorf("failed to decode %s response: %w", method, err)
+	}
+	if env.Metadata.Code != http.StatusOK {
+		return nil, &APIError{
+			Method:  method,
+			Code:    env.Metadata.Code,
+			ErrorNo: env.Metadata.ErrorNo,
+			Message: env.Metadata.Message,
+		}
+	}
+
+	if out != nil && len(env.Response) > 0 && string(env.Response) != "null" {
+		if err := json.Unmarshal(env.Response, out); err != nil {
+			return nil, fmt.Errorf("failed to decode %s response: %w", method, err)
+		}
+	}
+
+	return &env, nil
+}
@@ -0,0 +1,100 @@
+package inacbg_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/clinova/simrs/backend/internal/vedika/inacbg"
+	"github.com/clinova/simrs/backend/internal/vedika/inacbg/inacbgtest"
+)
+
+const testSEP = "0301R0011120V000001"
+
+func newTestClient(t *testing.T) (*inacbg.Client, *inacbgtest.Server) {
+	t.Helper()
+	key, err := inacbg.ParseKey(strings.Repeat("ab", 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := inacbgtest.NewServer(key)
+	t.Cleanup(srv.Close)
+
+	client, err := inacbg.NewClient(inacbg.Config{BaseURL: srv.URL, Key: key, KodeTarif: "CS"})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return client, srv
+}
+
+func TestClientBridgingFlow(t *testing.T) {
+	client, srv := newTestClient(t)
+	ctx := context.Background()
+
+	if _, err := client.NewClaim(ctx, inacbg.NewClaimRequest{NomorSEP: testSEP, NomorRM: "000001"}); err != nil {
+		t.Fatalf("NewClaim() error = %v", err)
+	}
+	_, err := client.NewClaim(ctx, inacbg.NewClaimRequest{NomorSEP: testSEP})
+	if !inacbg.IsDuplicateClaim(err) {
+		t.Fatalf("second NewClaim() error = %v, want duplicate SEP", err)
+	}
+
+	err = client.SetClaimData(ctx, inacbg.ClaimData{NomorSEP: testSEP, Diagnosa: "A09", Procedure: "45.13", TglPulang: "2026-01-02 10:00:00"})
+	if err != nil {
+		t.Fatalf("SetClaimData() error = %v", err)
+	}
+
+	stage1, err := client.GroupStage1(ctx, testSEP)
+	if err != nil {
+		t.Fatalf("GroupStage1() error = %v", err)
+	}
+	if stage1.CBG.Code != srv.CBG.Code || len(stage1.SpecialCMGOption) != 1 {
+		t.Fatalf("GroupStage1() = %+v, want CBG %s with one special CMG option", stage1, srv.CBG.Code)
+	}
+
+	stage2, err := client.GroupStage2(ctx, testSEP, []string{stage1.SpecialCMGOption[0].Code})
+	if err != nil {
+		t.Fatalf("GroupStage2() error = %v", err)
+	}
+	if want := float64(srv.CBG.Tariff + srv.SpecialCMGOptions[0].Tariff); stage2.TotalTariff() != want {
+		t.Fatalf("TotalTariff() = %v, want %v", stage2.TotalTariff(), want)
+	}
+
+	if err := client.ClaimFinal(ctx, testSEP, "3301010101010001"); err != nil {
+		t.Fatalf("ClaimFinal() error = %v", err)
+	}
+	sent, err := client.SendClaimIndividual(ctx, testSEP)
+	if err != nil {
+		t.Fatalf("SendClaimIndividual() error = %v", err)
+	}
+	if len(sent.Data) != 1 || sent.Data[0].SEP != testSEP {
+		t.Fatalf("SendClaimIndividual() = %+v", sent)
+	}
+	if claim, _ := srv.Claim(testSEP); !claim.Final || !claim.Sent {
+		t.Fatalf("server claim final=%v sent=%v, want both", claim.Final, claim.Sent)
+	}
+}
+
+func TestClientAPIError(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	err := client.ClaimFinal(context.Background(), "UNKNOWN", "3301010101010001")
+	var apiErr *inacbg.APIError
+	if !errors.As(err, &apiErr) || apiErr.Method != inacbg.MethodClaimFinal || apiErr.ErrorNo != "E2004" {
+		t.Fatalf("ClaimFinal() error = %v, want APIError E2004", err)
+	}
+}
+
+func TestClientWrongKey(t *testing.T) {
+	_, srv := newTestClient(t)
+	otherKey, _ := inacbg.ParseKey(strings.Repeat("cd", 32))
+	client, _ := inacbg.NewClient(inacbg.Config{BaseURL: srv.URL, Key: otherKey})
+
+	// The server answers undecryptable requests in plain JSON
+	_, err := client.NewClaim(context.Background(), inacbg.NewClaimRequest{NomorSEP: testSEP})
+	var apiErr *inacbg.APIError
+	if !errors.As(err, &apiErr) || apiErr.ErrorNo != "E2099" {
+		t.Fatalf("NewClaim() error = %v, want APIError E2099", err)
+	}
+}
@@ -0,0 +1,144 @@
+package inacbg
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	// ErrInvalidKey indicates the E-Klaim encryption key is not a 256-bit hex string.
+	ErrInvalidKey = errors.New("e-klaim encryption key must be 64 hex characters")
+	// ErrSignatureMismatch indicates the payload HMAC does not match.
+	ErrSignatureMismatch = errors.New("e-klaim payload signature mismatch")
+	// ErrMalformedPayload indicates the payload could not be decoded.
+	ErrMalformedPayload = errors.New("malformed e-klaim payload")
+)
+
+const (
+	signatureSize = 10
+	beginMarker   = "----BEGIN ENCRYPTED DATA----"
+	endMarker     = "----END ENCRYPTED DATA----"
+)
+
+// ParseKey decodes the hex encryption key configured in E-Klaim.
+func ParseKey(hexKey string) ([]byte, error) {
+	key, err := hex.DecodeString(strings.TrimSpace(hexKey))
+	if err != nil || len(key) != 32 {
+		return nil, ErrInvalidKey
+	}
+	return key, nil
+}
+
+// Encrypt seals a payload the way E-Klaim's inacbg_encrypt does:
+// base64(hmac_sha256(ciphertext)[:10] || iv || aes-256-cbc(ciphertext)),
+// split into 76-character lines.
+func Encrypt(key, plaintext []byte) (string, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", ErrInvalidKey
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return "", fmt.Errorf("failed to generate iv: %w", err)
+	}
+
+	padded := pkcs7Pad(plaintext, aes.BlockSize)
+	encrypted := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, padded)
+
+	payload := make([]byte, 0, signatureSize+len(iv)+len(encrypted))
+	payload = append(payload, sign(key, encrypted)...)
+	payload = append(payload, iv...)
+	payload = append(payload, encrypted...)
+
+	return chunkSplit(base64.StdEncoding.EncodeToString(payload), 76), nil
+}
+
+// Decrypt opens a payload produced by Encrypt or by the E-Klaim server.
+// The BEGIN/END ENCRYPTED DATA markers are stripped when present.
+func Decrypt(key []byte, payload string) ([]byte, error) {
+	payload = strings.Replace(payload, beginMarker, "", 1)
+	payload = strings.Replace(payload, endMarker, "", 1)
+	payload = strings.Join(strings.Fields(payload), "")
+
+	raw, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil || len(raw) < signatureSize+aes.BlockSize+aes.BlockSize {
+		return nil, ErrMalformedPayload
+	}
+
+	signature := raw[:signatureSize]
+	iv := raw[signatureSize : signatureSize+aes.BlockSize]
+	encrypted := raw[signatureSize+aes.BlockSize:]
+
+	if !hmac.Equal(signature, sign(key, encrypted)) {
+		return nil, ErrSignatureMismatch
+	}
+	if len(encrypted)%aes.BlockSize != 0 {
+		return nil, ErrMalformedPayload
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	decrypted := make([]byte, len(encrypted))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, encrypted)
+
+	return pkcs7Unpad(decrypted, aes.BlockSize)
+}
+
+// Frame wraps an encrypted payload in the markers used by E-Klaim responses.
+func Frame(encrypted string) string {
+	return beginMarker + "\n" + strings.TrimRight(encrypted, "\r\n") + "\n" + endMarker
+}
+
+func sign(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)[:signatureSize]
+}
+
+func pkcs7Pad(data []byte, blockSize int) []byte {
+	n := blockSize - len(data)%blockSize
+	return append(append([]byte{}, data...), bytes.Repeat([]byte{byte(n)}, n)...)
+}
+
+func pkcs7Unpad(data []byte, blockSize int) ([]byte, error) {
+	if len(data) == 0 || len(data)%blockSize != 0 {
+		return nil, ErrMalformedPayload
+	}
+	n := int(data[len(data)-1])
+	if n == 0 || n > blockSize || n > len(data) {
+		return nil, ErrMalformedPayload
+	}
+	for _, b := range data[len(data)-n:] {
+		if int(b) != n {
+			return nil, ErrMalformedPayload
+		}
+	}
+	return data[:len(data)-n], nil
+}
+
+// chunkSplit mirrors PHP chunk_split: CRLF after every n characters.
+func chunkSplit(s string, n int) string {
+	var b strings.Builder
+	for len(s) > n {
+		b.WriteString(s[:n])
+		b.WriteString("\r\n")
+		s = s[n:]
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+	return b.String()
+}
@@ -0,0 +1,139 @@
+package inacbg
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var testKey = mustParseKey("a0f1e2d3c4b5a6978877665544332211ffeeddccbbaa99887766554433221100")
+
+func mustParseKey(hexKey string) []byte {
+	key, err := ParseKey(hexKey)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		ok   bool
+	}{
+		{"valid", "a0f1e2d3c4b5a6978877665544332211ffeeddccbbaa99887766554433221100", true},
+		{"surrounding space", " a0f1e2d3c4b5a6978877665544332211ffeeddccbbaa99887766554433221100\n", true},
+		{"too short", "a0f1e2d3c4b5a697", false},
+		{"not hex", strings.Repeat("zz", 32), false},
+		{"empty", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParseKey(tt.key)
+			if tt.ok {
+				if err != nil || len(key) != 32 {
+					t.Fatalf("ParseKey() = %d bytes, %v; want 32 bytes", len(key), err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidKey) {
+				t.Fatalf("ParseKey() error = %v, want ErrInvalidKey", err)
+			}
+		})
+	}
+}
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	payloads := [][]byte{
+		{},
+		[]byte(`{"metadata":{"method":"new_claim"}}`),
+		bytes.Repeat([]byte("x"), 16), // Exact block size still gets a full padding block
+		[]byte(strings.Repeat(`{"diagnosa":"A09#K30"}`, 40)),
+	}
+	for _, plain := range payloads {
+		encrypted, err := Encrypt(testKey, plain)
+		if err != nil {
+			t.Fatalf("Encrypt() error = %v", err)
+		}
+		for _, line := range strings.Split(strings.TrimRight(encrypted, "\r\n"), "\r\n") {
+			if len(line) > 76 {
+				t.Fatalf("Encrypt() line of %d characters, want at most 76", len(line))
+			}
+		}
+
+		for _, payload := range []string{encrypted, Frame(encrypted)} {
+			got, err := Decrypt(testKey, payload)
+			if err != nil {
+				t.Fatalf("Decrypt() error = %v", err)
+			}
+			if !bytes.Equal(got, plain) {
+				t.Fatalf("Decrypt() = %q, want %q", got, plain)
+			}
+		}
+	}
+}
+
+func TestEncryptUsesFreshIV(t *testing.T) {
+	a, _ := Encrypt(testKey, []byte("same"))
+	b, _ := Encrypt(testKey, []byte("same"))
+	if a == b {
+		t.Fatal("Encrypt() returned the same payload twice")
+	}
+}
+
+func TestEncryptSignatureIsTruncatedHMAC(t *testing.T) {
+	encrypted, err := Encrypt(testKey, []byte(`{"ok":true}`))
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encrypted), ""))
+	if err != nil {
+		t.Fatalf("payload is not base64: %v", err)
+	}
+
+	// E-Klaim keeps only the first 10 bytes of HMAC-SHA256(ciphertext)
+	ciphertext := raw[signatureSize+16:]
+	mac := hmac.New(sha256.New, testKey)
+	mac.Write(ciphertext)
+	want := mac.Sum(nil)[:10]
+	if !bytes.Equal(raw[:signatureSize], want) {
+		t.Fatalf("signature = %x, want %x", raw[:signatureSize], want)
+	}
+}
+
+func TestDecryptRejectsTamperedPayload(t *testing.T) {
+	encrypted, _ := Encrypt(testKey, []byte(`{"ok":true}`))
+	raw, _ := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encrypted), ""))
+
+	tamper := func(i int) string {
+		b := append([]byte{}, raw...)
+		b[i] ^= 0x01
+		return base64.StdEncoding.EncodeToString(b)
+	}
+	otherKey := mustParseKey(strings.Repeat("11", 32))
+
+	tests := []struct {
+		name    string
+		key     []byte
+		payload string
+		want    error
+	}{
+		{"signature byte", testKey, tamper(0), ErrSignatureMismatch},
+		{"ciphertext byte", testKey, tamper(len(raw) - 1), ErrSignatureMismatch},
+		{"other key", otherKey, encrypted, ErrSignatureMismatch},
+		{"not base64", testKey, "not*base64", ErrMalformedPayload},
+		{"too short", testKey, base64.StdEncoding.EncodeToString(raw[:20]), ErrMalformedPayload},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decrypt(tt.key, tt.payload); !errors.Is(err, tt.want) {
+				t.Fatalf("Decrypt() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+// Package inacbgtest provides an in-process fake E-Klaim web service.
+//
+// It speaks the same encrypted protocol as E-Klaim's ws.php and keeps claims
+// in memory, so the bridging flow can be exercised without a real E-Klaim
+// installation:
+//
+//	srv := inacbgtest.NewServer(key)
+//	defer srv.Close()
+//	client, _ := inacbg.NewClient(inacbg.Config{BaseURL: srv.URL, Key: key, KodeTarif: "CS"})
+package inacbgtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/clinova/simrs/backend/internal/vedika/inacbg"
+)
+
+// Claim is the state the fake server keeps per SEP.
+type Claim struct {
+	NewClaim   inacbg.NewClaimRequest
+	Data       *inacbg.ClaimData
+	Grouping   map[string]interface{}
+	SpecialCMG []string
+	Final      bool
+	Sent       bool
+}
+
+// Server is a fake E-Klaim ws.php endpoint.
+type Server struct {
+	URL string // ws.php URL to configure as base_url
+
+	// CBG is returned by the grouper for every claim.
+	CBG inacbg.CBG
+	// SpecialCMGOptions are offered after stage 1 and priced in stage 2.
+	SpecialCMGOptions []inacbg.SpecialCMG
+
+	key    []byte
+	srv    *httptest.Server
+	mu     sync.Mutex
+	claims map[string]*Claim
+	nextID int
+}
+
+// NewServer starts a fake E-Klaim server using the given 256-bit key.
+func NewServer(key []byte) *Server {
+	s := &Server{
+		CBG: inacbg.CBG{
+			Code:        "Q-5-44-0",
+			Description: "PENYAKIT KRONIS KECIL LAIN-LAIN",
+			Tariff:      186000,
+		},
+		SpecialCMGOptions: []inacbg.SpecialCMG{
+			{Code: "YY01", Description: "SPECIAL PROCEDURE", Tariff: 1250000, Type: "Special Procedure"},
+		},
+		key:    key,
+		claims: make(map[string]*Claim),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/E-Klaim/ws.php", s.handle)
+	s.srv = httptest.NewServer(mux)
+	s.URL = s.srv.URL + "/E-Klaim/ws.php"
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Claim returns a copy of the stored state of a SEP.
+func (s *Server) Claim(nomorSEP string) (Claim, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.claims[nomorSEP]
+	if !ok {
+		return Claim{}, false
+	}
+	return *c, true
+}
+
+type request struct {
+	Metadata map[string]string `json:"metadata"`
+	Data     json.RawMessage   `json:"data"`
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	plain, err := inacbg.Decrypt(s.key, string(body))
+	if err != nil {
+		// E-Klaim answers undecryptable requests in plain JSON
+		writePlain(w, map[string]interface{}{
+			"metadata": inacbg.Metadata{Code: 400, Message: "Decryption failed", ErrorNo: "E2099"},
+		})
+		return
+	}
+
+	var req request
+	if err := json.Unmarshal(plain, &req); err != nil {
+		s.reply(w, errorMeta(400, "E2001", "Invalid JSON"), nil)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch req.Metadata["method"] {
+	case inacbg.MethodNewClaim:
+		s.newClaim(w, req)
+	case inacbg.MethodSetClaimData:
+		s.setClaimData(w, req)
+	case inacbg.MethodGrouper:
+		s.grouper(w, req)
+	case inacbg.MethodClaimFinal:
+		s.claimFinal(w, req)
+	case inacbg.MethodSendClaimIndividual:
+		s.sendClaim(w, req)
+	default:
+		s.reply(w, errorMeta(400, "E2000", "Method tidak dikenal"), nil)
+	}
+}
+
+func (s *Server) newClaim(w http.ResponseWriter, req request) {
+	var data inacbg.NewClaimRequest
+	if err := json.Unmarshal(req.Data, &data); err != nil || data.NomorSEP == "" {
+		s.reply(w, errorMeta(400, "E2002", "Nomor SEP kosong"), nil)
+		return
+	}
+	if _, ok := s.claims[data.NomorSEP]; ok {
+		s.reply(w, errorMeta(400, inacbg.ErrNoDuplicateSEP, "Duplikasi nomor SEP"), nil)
+		return
+	}
+
+	s.nextID++
+	s.claims[data.NomorSEP] = &Claim{NewClaim: data}
+	s.reply(w, okMeta(), map[string]interface{}{
+		"patient_id":            fmt.Sprint(s.nextID),
+		"admission_id":          fmt.Sprint(s.nextID),
+		"hospital_admission_id": fmt.Sprint(s.nextID),
+	})
+}
+
+func (s *Server) setClaimData(w http.ResponseWriter, req request) {
+	var data inacbg.ClaimData
+	if err := json.Unmarshal(req.Data, &data); err != nil {
+		s.reply(w, errorMeta(400, "E2001", "Invalid JSON"), nil)
+		return
+	}
+	claim, ok := s.claims[req.Metadata["nomor_sep"]]
+	if !ok {
+		s.reply(w, errorMeta(400, "E2004", "Nomor SEP tidak ditemukan"), nil)
+		return
+	}
+	if claim.Final {
+		s.reply(w, errorMeta(400, "E2010", "Klaim sudah final"), nil)
+		return
+	}
+
+	claim.Data = &data
+	claim.Grouping = nil
+	claim.SpecialCMG = nil
+	s.reply(w, okMeta(), nil)
+}
+
+func (s *Server) grouper(w http.ResponseWriter, req request) {
+	var data struct {
+		NomorSEP   string `json:"nomor_sep"`
+		SpecialCMG string `json:"special_cmg"`
+	}
+	_ = json.Unmarshal(req.Data, &data)
+
+	claim, ok := s.claims[data.NomorSEP]
+	if !ok {
+		s.reply(w, errorMeta(400, "E2004", "Nomor SEP tidak ditemukan"), nil)
+		return
+	}
+	if claim.Data == nil || claim.Data.Diagnosa == "" {
+		s.reply(w, errorMeta(400, "E2101", "Diagnosa belum diisi"), nil)
+		return
+	}
+
+	result := map[string]interface{}{
+		"cbg":            s.CBG,
+		"inacbg_version": "5.8",
+	}
+	extra := map[string]interface{}{}
+
+	switch req.Metadata["stage"] {
+	case "1":
+		claim.SpecialCMG = nil
+		if claim.Data.Procedure != "" {
+			extra["special_cmg_option"] = s.SpecialCMGOptions
+		}
+	case "2":
+		if claim.Grouping == nil {
+			s.reply(w, errorMeta(400, "E2102", "Grouper stage 1 belum dijalankan"), nil)
+			return
+		}
+		selected := []inacbg.SpecialCMG{}
+		claim.SpecialCMG = nil
+		for _, code := range strings.Split(data.SpecialCMG, "#") {
+			for _, opt := range s.SpecialCMGOptions {
+				if opt.Code == code {
+					selected = append(selected, opt)
+					claim.SpecialCMG = append(claim.SpecialCMG, code)
+				}
+			}
+		}
+		result["special_cmg"] = selected
+	default:
+		s.reply(w, errorMeta(400, "E2103", "Stage tidak dikenal"), nil)
+		return
+	}
+
+	claim.Grouping = result
+	s.replyWith(w, okMeta(), result, extra)
+}
+
+func (s *Server) claimFinal(w http.ResponseWriter, req request) {
+	var data struct {
+		NomorSEP string `json:"nomor_sep"`
+		CoderNIK string `json:"coder_nik"`
+	}
+	_ = json.Unmarshal(req.Data, &data)
+
+	claim, ok := s.claims[data.NomorSEP]
+	if !ok {
+		s.reply(w, errorMeta(400, "E2004", "Nomor SEP tidak ditemukan"), nil)
+		return
+	}
+	if claim.Grouping == nil {
+		s.reply(w, errorMeta(400, "E2104", "Klaim belum di-grouping"), nil)
+		return
+	}
+	if data.CoderNIK == "" {
+		s.reply(w, errorMeta(400, "E2105", "NIK coder kosong"), nil)
+		return
+	}
+
+	claim.Final = true
+	s.reply(w, okMeta(), nil)
+}
+
+func (s *Server) sendClaim(w http.ResponseWriter, req request) {
+	var data struct {
+		NomorSEP string `json:"nomor_sep"`
+	}
+	_ = json.Unmarshal(req.Data, &data)
+
+	claim, ok := s.claims[data.NomorSEP]
+	if !ok {
+		s.reply(w, errorMeta(400, "E2004", "Nomor SEP tidak ditemukan"), nil)
+		return
+	}
+	if !claim.Final {
+		s.reply(w, errorMeta(400, "E2106", "Klaim belum final"), nil)
+		return
+	}
+
+	claim.Sent = true
+	s.reply(w, okMeta(), map[string]interface{}{
+		"data": []inacbg.SentClaim{{
+			SEP:            data.NomorSEP,
+			TglPulang:      claim.Data.TglPulang,
+			KemkesDCStatus: "sent",
+			BPJSDCStatus:   "sent",
+		}},
+	})
+}
+
+func (s *Server) reply(w http.ResponseWriter, meta inacbg.Metadata, response interface{}) {
+	s.replyWith(w, meta, response, nil)
+}
+
+// replyWith encrypts and frames the response like ws.php does.
+func (s *Server) replyWith(w http.ResponseWriter, meta inacbg.Metadata, response interface{}, extra map[string]interface{}) {
+	body := map[string]interface{}{"metadata": meta}
+	if response != nil {
+		body["response"] = response
+	}
+	for k, v := range extra {
+		body[k] = v
+	}
+
+	raw, _ := json.Marshal(body)
+	encrypted, err := inacbg.Encrypt(s.key, raw)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = io.WriteString(w, inacbg.Frame(encrypted))
+}
+
+func writePlain(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func okMeta() inacbg.Metadata {
+	return inacbg.Metadata{Code: 200, Message: "Ok"}
+}
+
+func errorMeta(code int, errorNo, message string) inacbg.Metadata {
+	return inacbg.Metadata{Code: code, Message: message, ErrorNo: errorNo}
+}
@@ -0,0 +1,202 @@
+package inacbg
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// E-Klaim web service methods.
+const (
+	MethodNewClaim            = "new_claim"
+	MethodSetClaimData        = "set_claim_data"
+	MethodGrouper             = "grouper"
+	MethodClaimFinal          = "claim_final"
+	MethodSendClaimIndividual = "send_claim_individual"
+)
+
+// Jenis rawat and discharge status codes used by E-Klaim.
+const (
+	JenisRawatInap  = "1"
+	JenisRawatJalan = "2"
+
+	DischargeAtasPersetujuan = "1" // Atas persetujuan dokter
+	DischargeDirujuk         = "2"
+	DischargeAPS             = "3" // Atas permintaan sendiri
+	DischargeMeninggal       = "4"
+	DischargeLainLain        = "5"
+)
+
+// Amount is a rupiah amount; E-Klaim sends tariffs as strings or numbers.
+type Amount float64
+
+// UnmarshalJSON accepts "3600700", 3600700 and "".
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		*a = 0
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	*a = Amount(v)
+	return nil
+}
+
+// Metadata is the metadata block of every E-Klaim response.
+type Metadata struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	ErrorNo string `json:"error_no,omitempty"`
+}
+
+// envelope is the raw E-Klaim response.
+type envelope struct {
+	Metadata         Metadata          `json:"metadata"`
+	Response         json.RawMessage   `json:"response,omitempty"`
+	SpecialCMGOption []SpecialCMG      `json:"special_cmg_option,omitempty"`
+	TarifAlt         []AlternateTariff `json:"tarif_alt,omitempty"`
+}
+
+// NewClaimRequest is the data block of new_claim.
+type NewClaimRequest struct {
+	NomorKartu string `json:"nomor_kartu"`
+	NomorSEP   string `json:"nomor_sep"`
+	NomorRM    string `json:"nomor_rm"`
+	NamaPasien string `json:"nama_pasien"`
+	TglLahir   string `json:"tgl_lahir"` // YYYY-MM-DD HH:MM:SS
+	Gender     string `json:"gender"`    // 1 = laki-laki, 2 = perempuan
+}
+
+// NewClaimResponse is returned by new_claim.
+type NewClaimResponse struct {
+	PatientID           string `json:"patient_id"`
+	AdmissionID         string `json:"admission_id"`
+	HospitalAdmissionID string `json:"hospital_admission_id"`
+}
+
+// TarifRS is the hospital tariff split into E-Klaim cost components.
+type TarifRS struct {
+	ProsedurNonBedah float64 `json:"prosedur_non_bedah"`
+	ProsedurBedah    float64 `json:"prosedur_bedah"`
+	Konsultasi       float64 `json:"konsultasi"`
+	TenagaAhli       float64 `json:"tenaga_ahli"`
+	Keperawatan      float64 `json:"keperawatan"`
+	Penunjang        float64 `json:"penunjang"`
+	Radiologi        float64 `json:"radiologi"`
+	Laboratorium     float64 `json:"laboratorium"`
+	PelayananDarah   float64 `json:"pelayanan_darah"`
+	Rehabilitasi     float64 `json:"rehabilitasi"`
+	Kamar            float64 `json:"kamar"`
+	RawatIntensif    float64 `json:"rawat_intensif"`
+	Obat             float64 `json:"obat"`
+	ObatKronis       float64 `json:"obat_kronis"`
+	ObatKemoterapi   float64 `json:"obat_kemoterapi"`
+	Alkes            float64 `json:"alkes"`
+	BMHP             float64 `json:"bmhp"`
+	SewaAlat         float64 `json:"sewa_alat"`
+}
+
+// Total returns the sum of all components.
+func (t TarifRS) Total() float64 {
+	return t.ProsedurNonBedah + t.ProsedurBedah + t.Konsultasi + t.TenagaAhli +
+		t.Keperawatan + t.Penunjang + t.Radiologi + t.Laboratorium + t.PelayananDarah +
+		t.Rehabilitasi + t.Kamar + t.RawatIntensif + t.Obat + t.ObatKronis +
+		t.ObatKemoterapi + t.Alkes + t.BMHP + t.SewaAlat
+}
+
+// ClaimData is the data block of set_claim_data.
+type ClaimData struct {
+	NomorSEP          string  `json:"nomor_sep"`
+	NomorKartu        string  `json:"nomor_kartu"`
+	TglMasuk          string  `json:"tgl_masuk"`  // YYYY-MM-DD HH:MM:SS
+	TglPulang         string  `json:"tgl_pulang"` // YYYY-MM-DD HH:MM:SS
+	JenisRawat        string  `json:"jenis_rawat"`
+	KelasRawat        string  `json:"kelas_rawat"`
+	ADLSubAcute       int     `json:"adl_sub_acute"`
+	ADLChronic        int     `json:"adl_chronic"`
+	ICUIndikator      int     `json:"icu_indikator"`
+	ICULos            int     `json:"icu_los"`
+	VentilatorHour    int     `json:"ventilator_hour"`
+	UpgradeClassInd   int     `json:"upgrade_class_ind"`
+	UpgradeClassClass string  `json:"upgrade_class_class"`
+	UpgradeClassLos   int     `json:"upgrade_class_los"`
+	AddPaymentPct     float64 `json:"add_payment_pct"`
+	BirthWeight       int     `json:"birth_weight"`
+	Sistole           int     `json:"sistole"`
+	Diastole          int     `json:"diastole"`
+	DischargeStatus   string  `json:"discharge_status"`
+	Diagnosa          string  `json:"diagnosa"`  // ICD-10 codes joined with #
+	Procedure         string  `json:"procedure"` // ICD-9-CM codes joined with #
+	TarifRS           TarifRS `json:"tarif_rs"`
+	TarifPoliEks      float64 `json:"tarif_poli_eks"`
+	NamaDokter        string  `json:"nama_dokter"`
+	KodeTarif         string  `json:"kode_tarif"`
+	PayorID           string  `json:"payor_id"`
+	PayorCD           string  `json:"payor_cd"`
+	COBCD             string  `json:"cob_cd,omitempty"`
+	CoderNIK          string  `json:"coder_nik"`
+}
+
+// CBG is a grouped case-based group with its tariff.
+type CBG struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Tariff      Amount `json:"tariff"`
+}
+
+// SpecialCMG is a special case-mix group (procedure, drug, prosthesis, investigation).
+type SpecialCMG struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Tariff      Amount `json:"tariff,omitempty"`
+	Type        string `json:"type"`
+}
+
+// AlternateTariff is the CBG tariff for another class of care.
+type AlternateTariff struct {
+	Kelas       string `json:"kelas"`
+	TarifINACBG Amount `json:"tarif_inacbg"`
+}
+
+// GrouperResult is the outcome of grouper stage 1 or 2.
+type GrouperResult struct {
+	CBG              CBG               `json:"cbg"`
+	SubAcute         *CBG              `json:"sub_acute,omitempty"`
+	Chronic          *CBG              `json:"chronic,omitempty"`
+	SpecialCMG       []SpecialCMG      `json:"special_cmg,omitempty"`
+	INACBGVersion    string            `json:"inacbg_version,omitempty"`
+	SpecialCMGOption []SpecialCMG      `json:"special_cmg_option,omitempty"` // Options offered after stage 1
+	TarifAlt         []AlternateTariff `json:"tarif_alt,omitempty"`
+}
+
+// TotalTariff returns the CBG tariff plus sub-acute, chronic and special CMG tariffs.
+func (g *GrouperResult) TotalTariff() float64 {
+	total := float64(g.CBG.Tariff)
+	if g.SubAcute != nil {
+		total += float64(g.SubAcute.Tariff)
+	}
+	if g.Chronic != nil {
+		total += float64(g.Chronic.Tariff)
+	}
+	for _, s := range g.SpecialCMG {
+		total += float64(s.Tariff)
+	}
+	return total
+}
+
+// SentClaim is the per-SEP status returned by send_claim_individual.
+type SentClaim struct {
+	SEP            string `json:"SEP"`
+	TglPulang      string `json:"tgl_pulang"`
+	KemkesDCStatus string `json:"kemkes_dc_Status"`
+	BPJSDCStatus   string `json:"bpjs_dc_Status"`
+	COBDCStatus    string `json:"cob_dc_status"`
+}
+
+// SendClaimResult is returned by send_claim_individual.
+type SendClaimResult struct {
+	Data []SentClaim `json:"data"`
+}
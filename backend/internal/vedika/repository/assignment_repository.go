@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+)
+
+var (
+	// ErrAssignmentNotFound indicates the episode has no assigned coder.
+	ErrAssignmentNotFound = errors.New("claim assignment not found")
+	// ErrEpisodeNotFound indicates no reg_periksa row for the episode.
+	ErrEpisodeNotFound = errors.New("episode not found")
+	// ErrCoderNotFound indicates the assignee is not an active mera_users user.
+	ErrCoderNotFound = errors.New("coder not found or inactive")
+)
+
+// AssignmentRepository handles mera_vedika_assignment access.
+type AssignmentRepository interface {
+	// Get the assignment of an episode
+	Get(ctx context.Context, noRawat string) (*entity.ClaimAssignment, error)
+	// Get the jenis, unit and service date of an episode
+	GetCandidate(ctx context.Context, noRawat string) (*entity.AssignmentCandidate, error)
+	// List unassigned Rencana episodes served between two dates, oldest first
+	ListUnassigned(ctx context.Context, jenis entity.JenisPelayanan, dateFrom, dateTo string, carabayar []string, limit int) ([]entity.AssignmentCandidate, error)
+	// Assign an episode, replacing any existing assignment
+	Assign(ctx context.Context, a *entity.ClaimAssignment) error
+	// Assign an episode only if it is still unassigned; reports whether it was
+	AssignIfUnassigned(ctx context.Context, a *entity.ClaimAssignment) (bool, error)
+	// Remove the assignment of an episode
+	Unassign(ctx context.Context, noRawat string) error
+	// Get the latest assignment time of each user; users never assigned are absent
+	LastAssignedAt(ctx context.Context, userIDs []string) (map[string]time.Time, error)
+	// Resolve an active mera_users id to its username
+	GetActiveUsername(ctx context.Context, userID string) (string, error)
+}
+
+// MySQLAssignmentRepository implements AssignmentRepository.
+type MySQLAssignmentRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLAssignmentRepository creates a new assignment repository.
+func NewMySQLAssignmentRepository(db *sql.DB) *MySQLAssignmentRepository {
+	return &MySQLAssignmentRepository{db: db}
+}
+
+// Get returns the assignment of an episode.
+func (r *MySQLAssignmentRepository) Get(ctx context.Context, noRawat string) (*entity.ClaimAssignment, error) {
+	var a entity.ClaimAssignment
+	err := r.db.QueryRowContext(ctx, `
+		SELECT a.no_rawat, a.jenis, a.kd_unit, DATE_FORMAT(a.tgl_pelayanan, '%Y-%m-%d'),
+			a.user_id, COALESCE(u.username, a.user_id), a.assigned_by, a.assigned_at
+		FROM mera_vedika_assignment a
+		LEFT JOIN mera_users u ON a.user_id = u.id
+		WHERE a.no_rawat = ?
+	`, noRawat).Scan(&a.NoRawat, &a.Jenis, &a.KdUnit, &a.TglPelayanan,
+		&a.UserID, &a.Username, &a.AssignedBy, &a.AssignedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrAssignmentNotFound, noRawat)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignment: %w", err)
+	}
+	return &a, nil
+}
+
+// GetCandidate returns the jenis, unit and service date of an episode.
+// Ranap episodes use the last bangsal and the discharge date, falling back
+// to the registration date while the patient is still admitted.
+func (r *MySQLAssignmentRepository) GetCandidate(ctx context.Context, noRawat string) (*entity.AssignmentCandidate, error) {
+	var c entity.AssignmentCandidate
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			rp.no_rawat,
+			LOWER(rp.status_lanjut),
+			CASE
+				WHEN rp.status_lanjut = 'Ranap' THEN COALESCE((
+					SELECT km.kd_bangsal FROM kamar_inap ki
+					INNER JOIN kamar km ON ki.kd_kamar = km.kd_kamar
+					WHERE ki.no_rawat = rp.no_rawat
+					ORDER BY ki.tgl_masuk DESC, ki.jam_masuk DESC LIMIT 1
+				), '')
+				ELSE rp.kd_poli
+			END,
+			DATE_FORMAT(CASE
+				WHEN rp.status_lanjut = 'Ranap' THEN COALESCE((
+					SELECT MAX(ki.tgl_keluar) FROM kamar_inap ki
+					WHERE ki.no_rawat = rp.no_rawat AND ki.tgl_keluar IS NOT NULL
+				), rp.tgl_registrasi)
+				ELSE rp.tgl_registrasi
+			END, '%Y-%m-%d')
+		FROM reg_periksa rp
+		WHERE rp.no_rawat = ?
+	`, noRawat).Scan(&c.NoRawat, &c.Jenis, &c.KdUnit, &c.TglPelayanan)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrEpisodeNotFound, noRawat)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get episode: %w", err)
+	}
+	return &c, nil
+}
+
+// ListUnassigned lists Rencana episodes (not in mlite_vedika) without
+// an assignment. Date logic follows the index: ralan by tgl_registrasi,
+// ranap by tgl_keluar.
+func (r *MySQLAssignmentRepository) ListUnassigned(ctx context.Context, jenis entity.JenisPelayanan, dateFrom, dateTo string, carabayar []string, limit int) ([]entity.AssignmentCandidate, error) {
+	if len(carabayar) == 0 {
+		return []entity.AssignmentCandidate{}, nil
+	}
+
+	var query string
+	if jenis == entity.JenisRanap {
+		query = fmt.Sprintf(`
+			SELECT
+				rp.no_rawat,
+				'ranap',
+				COALESCE((
+					SELECT km.kd_bangsal FROM kamar_inap ki2
+					INNER JOIN kamar km ON ki2.kd_kamar = km.kd_kamar
+					WHERE ki2.no_rawat = rp.no_rawat
+					ORDER BY ki2.tgl_masuk DESC, ki2.jam_masuk DESC LIMIT 1
+				), ''),
+				DATE_FORMAT(MAX(ki.tgl_keluar), '%%Y-%%m-%%d')
+			FROM reg_periksa rp
+			INNER JOIN kamar_inap ki ON rp.no_rawat = ki.no_rawat
+			LEFT JOIN mlite_vedika mv ON rp.no_rawat = mv.no_rawat
+			LEFT JOIN mera_vedika_assignment a ON rp.no_rawat = a.no_rawat
+			WHERE rp.kd_pj IN (%s)
+			  AND ki.tgl_keluar IS NOT NULL
+			  AND ki.tgl_keluar BETWEEN ? AND ?
+			  AND rp.status_lanjut = 'Ranap'
+			  AND rp.stts != 'Batal'
+			  AND mv.no_rawat IS NULL
+			  AND a.no_rawat IS NULL
+			GROUP BY rp.no_rawat
+			ORDER BY MAX(ki.tgl_keluar), rp.no_rawat
+			LIMIT ?
+		`, buildPlaceholders(len(carabayar)))
+	} else {
+		query = fmt.Sprintf(`
+			SELECT
+				rp.no_rawat,
+				'ralan',
+				rp.kd_poli,
+				DATE_FORMAT(rp.tgl_registrasi, '%%Y-%%m-%%d')
+			FROM reg_periksa rp
+			LEFT JOIN mlite_vedika mv ON rp.no_rawat = mv.no_rawat
+			LEFT JOIN mera_vedika_assignment a ON rp.no_rawat = a.no_rawat
+			WHERE rp.kd_pj IN (%s)
+			  AND rp.tgl_registrasi BETWEEN ? AND ?
+			  AND rp.status_lanjut = 'Ralan'
+			  AND rp.stts != 'Batal'
+			  AND mv.no_rawat IS NULL
+			  AND a.no_rawat IS NULL
+			ORDER BY rp.tgl_registrasi, rp.no_rawat
+			LIMIT ?
+		`, buildPlaceholders(len(carabayar)))
+	}
+
+	args := append(toInterfaceSlice(carabayar), dateFrom, dateTo, limit)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unassigned episodes: %w", err)
+	}
+	defer rows.Close()
+
+	candidates := []entity.AssignmentCandidate{}
+	for rows.Next() {
+		var c entity.AssignmentCandidate
+		if err := rows.Scan(&c.NoRawat, &c.Jenis, &c.KdUnit, &c.TglPelayanan); err != nil {
+			return nil, fmt.Errorf("failed to scan unassigned episode: %w", err)
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+// Assign upserts the assignment of an episode.
+func (r *MySQLAssignmentRepository) Assign(ctx context.Context, a *entity.ClaimAssignment) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO mera_vedika_assignment (no_rawat, user_id, jenis, kd_unit, tgl_pelayanan, assigned_by, assigned_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			user_id = VALUES(user_id),
+			assigned_by = VALUES(assigned_by),
+			assigned_at = VALUES(assigned_at)
+	`, a.NoRawat, a.UserID, a.Jenis, a.KdUnit, a.TglPelayanan, a.AssignedBy, a.AssignedAt)
+	if err != nil {
+		return fmt.Errorf("failed to assign claim: %w", err)
+	}
+	return nil
+}
+
+// AssignIfUnassigned inserts the assignment unless the episode already has
+// one, so concurrent claim-next and distribution runs never steal a claim.
+func (r *MySQLAssignmentRepository) AssignIfUnassigned(ctx context.Context, a *entity.ClaimAssignment) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT IGNORE INTO mera_vedika_assignment (no_rawat, user_id, jenis, kd_unit, tgl_pelayanan, assigned_by, assigned_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, a.NoRawat, a.UserID, a.Jenis, a.KdUnit, a.TglPelayanan, a.AssignedBy, a.AssignedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to assign claim: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to assign claim: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Unassign removes the assignment of an episode.
+func (r *MySQLAssignmentRepository) Unassign(ctx context.Context, noRawat string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM mera_vedika_assignment WHERE no_rawat = ?`, noRawat)
+	if err != nil {
+		return fmt.Errorf("failed to unassign claim: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %s", ErrAssignmentNotFound, noRawat)
+	}
+	return nil
+}
+
+// LastAssignedAt returns the latest assigned_at of each user.
+func (r *MySQLAssignmentRepository) LastAssignedAt(ctx context.Context, userIDs []string) (map[string]time.Time, error) {
+	last := make(map[string]time.Time, len(userIDs))
+	if len(userIDs) == 0 {
+		return last, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT user_id, MAX(assigned_at) FROM mera_vedika_assignment
+		WHERE user_id IN (%s)
+		GROUP BY user_id
+	`, buildPlaceholders(len(userIDs)))
+
+	rows, err := r.db.QueryContext(ctx, query, toInterfaceSlice(userIDs)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last assignments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		var at time.Time
+		if err := rows.Scan(&userID, &at); err != nil {
+			return nil, fmt.Errorf("failed to scan last assignment: %w", err)
+		}
+		last[userID] = at
+	}
+	return last, rows.Err()
+}
+
+// GetActiveUsername resolves an active, non-deleted mera_users id.
+func (r *MySQLAssignmentRepository) GetActiveUsername(ctx context.Context, userID string) (string, error) {
+	var username string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT username FROM mera_users
+		WHERE id = ? AND is_active = 1 AND deleted_at IS NULL
+	`, userID).Scan(&username)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("%w: %s", ErrCoderNotFound, userID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get coder: %w", err)
+	}
+	return username, nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/clinova/simrs/backend/pkg/secret"
+)
+
+// BridgingSettingsRepository reads credentials of external bridging modules
+// (e.g. bpjs.vclaim, inacbg.eklaim) from mera_settings for one environment.
+type BridgingSettingsRepository interface {
+	// Get all active settings of a module, decrypted, keyed by setting_key
+	GetModuleSettings(ctx context.Context, module string) (map[string]string, error)
+}
+
+// MySQLBridgingSettingsRepository implements BridgingSettingsRepository.
+type MySQLBridgingSettingsRepository struct {
+	db          *sql.DB
+	environment string
+	cipher      *secret.Cipher // nil when SETTINGS_ENCRYPTION_KEY is not set
+}
+
+// NewMySQLBridgingSettingsRepository creates a new bridging settings repository.
+func NewMySQLBridgingSettingsRepository(db *sql.DB, environment string, cipher *secret.Cipher) *MySQLBridgingSettingsRepository {
+	return &MySQLBridgingSettingsRepository{db: db, environment: environment, cipher: cipher}
+}
+
+// GetModuleSettings returns the module settings for the configured environment.
+// JSON string values are unquoted; other JSON values are returned as raw text.
+func (r *MySQLBridgingSettingsRepository) GetModuleSettings(ctx context.Context, module string) (map[string]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT setting_key, setting_value, value_encrypted
+		FROM mera_settings
+		WHERE module = ? AND environment = ? AND is_active = 1
+	`, module, r.environment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s settings: %w", module, err)
+	}
+	defer rows.Close()
+
+	settings := make(map[string]string)
+	for rows.Next() {
+		var key string
+		var raw sql.NullString
+		var encrypted bool
+		if err := rows.Scan(&key, &raw, &encrypted); err != nil {
+			return nil, fmt.Errorf("failed to scan %s settings: %w", module, err)
+		}
+
+		value := raw.String
+		var str string
+		if err := json.Unmarshal([]byte(raw.String), &str); err == nil {
+			value = str
+		}
+
+		if encrypted && value != "" {
+			if r.cipher == nil {
+				return nil, fmt.Errorf("%s.%s: %w", module, key, secret.ErrNoKey)
+			}
+			if value, err = r.cipher.Decrypt(value); err != nil {
+				return nil, fmt.Errorf("failed to decrypt %s.%s: %w", module, key, err)
+			}
+		}
+
+		settings[key] = value
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating %s settings: %w", module, err)
+	}
+
+	if len(settings) == 0 {
+		return nil, fmt.Errorf("%w: %s (%s)", ErrSettingNotFound, module, r.environment)
+	}
+
+	return settings, nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -31,12 +32,27 @@ type ClaimDetailRepository interface {
 	GetBilling(ctx context.Context, noRawat string, statusLanjut string) (*entity.BillingSummary, error)
 	GetSPRI(ctx context.Context, noRawat string) (*entity.SPRIDetail, error)
 	GetDigitalDocuments(ctx context.Context, noRawat string) ([]entity.DigitalDocument, error)
+	GetDocumentCategoryNames(ctx context.Context) (map[string]string, error)
 	GetLabPAReports(ctx context.Context, noRawat string) ([]entity.LabPAReport, error)
 }
 
 // MySQLClaimDetailRepository implements ClaimDetailRepository.
 type MySQLClaimDetailRepository struct {
-	db *sql.DB
+	db           *sql.DB
+	liveSEP      LiveSEPSource
+	tarifMapping TarifMappingSource
+}
+
+// LiveSEPSource fetches a SEP from BPJS when bridging_sep has no row.
+// It returns nil, nil when the SEP is unknown or bridging is not configured.
+type LiveSEPSource interface {
+	FetchSEPDetail(ctx context.Context, noSEP string) (*entity.SEPDetail, error)
+}
+
+// TarifMappingSource provides the INA-CBG cost component mapping of billing
+// lines. It returns ErrSettingNotFound when no mapping is configured.
+type TarifMappingSource interface {
+	GetTarifMapping(ctx context.Context) (*entity.TarifMapping, error)
 }
 
 // NewMySQLClaimDetailRepository creates a new claim detail repository.
@@ -44,11 +60,24 @@ func NewMySQLClaimDetailRepository(db *sql.DB) *MySQLClaimDetailRepository {
 	return &MySQLClaimDetailRepository{db: db}
 }
 
+// SetLiveSEPSource enables the live SEP fallback of GetSEPDetail.
+func (r *MySQLClaimDetailRepository) SetLiveSEPSource(source LiveSEPSource) {
+	r.liveSEP = source
+}
+
+// SetTarifMappingSource enables the configured INA-CBG classification of
+// billing lines in GetBilling. Without it lines are classified by name.
+func (r *MySQLClaimDetailRepository) SetTarifMappingSource(source TarifMappingSource) {
+	r.tarifMapping = source
+}
+
 // =============================================================================
 // SECTION 1: SEP (Surat Eligibilitas Peserta)
 // =============================================================================
 
 // GetSEPDetail fetches SEP data from bridging_sep and bpjs_prb.
+// Without a bridging_sep row the SEP number registered in mlite_vedika is
+// looked up live through the LiveSEPSource, if one is set.
 func (r *MySQLClaimDetailRepository) GetSEPDetail(ctx context.Context, noRawat string) (*entity.SEPDetail, error) {
 	noRawat = strings.TrimPrefix(noRawat, "/")
 	query := `
@@ -103,7 +132,7 @@ func (r *MySQLClaimDetailRepository) GetSEPDetail(ctx context.Context, noRawat s
 		&sep.PRBStatus,
 	)
 	if err == sql.ErrNoRows {
-		return nil, nil // No SEP found
+		return r.getLiveSEPDetail(ctx, noRawat)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get SEP detail: %w", err)
@@ -112,6 +141,30 @@ func (r *MySQLClaimDetailRepository) GetSEPDetail(ctx context.Context, noRawat s
 	return &sep, nil
 }
 
+// getLiveSEPDetail fetches the SEP of a claim from BPJS.
+func (r *MySQLClaimDetailRepository) getLiveSEPDetail(ctx context.Context, noRawat string) (*entity.SEPDetail, error) {
+	if r.liveSEP == nil {
+		return nil, nil // No SEP found
+	}
+
+	var noSEP string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(nosep, '') FROM mlite_vedika WHERE no_rawat = ? AND nosep <> '' LIMIT 1
+	`, noRawat).Scan(&noSEP)
+	if err == sql.ErrNoRows {
+		return nil, nil // No SEP found
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SEP number: %w", err)
+	}
+
+	sep, err := r.liveSEP.FetchSEPDetail(ctx, noSEP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch SEP %s: %w", noSEP, err)
+	}
+	return sep, nil
+}
+
 // =============================================================================
 // SECTION 2: Patient & Registration
 // =============================================================================
@@ -1137,6 +1190,20 @@ func (r *MySQLClaimDetailRepository) GetBilling(ctx context.Context, noRawat str
 	// Generate terbilang (simple implementation)
 	billing.Terbilang = formatTerbilang(billing.JumlahBayar)
 
+	// Classify lines into INA-CBG cost components for E-Klaim tarif_rs
+	var mapping entity.TarifMapping
+	if r.tarifMapping != nil {
+		m, err := r.tarifMapping.GetTarifMapping(ctx)
+		switch {
+		case err == nil:
+			mapping = *m
+		case !errors.Is(err, ErrSettingNotFound):
+			// A broken mapping must not hide the billing; fall back to names
+			fmt.Printf("vedika_debug: error GetTarifMapping for %s: %v\n", noRawat, err)
+		}
+	}
+	billing.ClassifyTarif(mapping)
+
 	return billing, nil
 }
 
@@ -1248,6 +1315,25 @@ func (r *MySQLClaimDetailRepository) GetSPRI(ctx context.Context, noRawat string
 // SECTION 11-13: Digital Documents
 // =============================================================================
 
+// GetDocumentCategoryNames maps master_berkas_digital.kode to nama.
+func (r *MySQLClaimDetailRepository) GetDocumentCategoryNames(ctx context.Context) (map[string]string, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT kode, nama FROM master_berkas_digital")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document categories: %w", err)
+	}
+	defer rows.Close()
+
+	names := make(map[string]string)
+	for rows.Next() {
+		var kode, nama string
+		if err := rows.Scan(&kode, &nama); err != nil {
+			return nil, fmt.Errorf("failed to scan document category: %w", err)
+		}
+		names[kode] = nama
+	}
+	return names, rows.Err()
+}
+
 // GetDigitalDocuments fetches uploaded documents.
 func (r *MySQLClaimDetailRepository) GetDigitalDocuments(ctx context.Context, noRawat string) ([]entity.DigitalDocument, error) {
 	noRawat = strings.TrimPrefix(noRawat, "/")
@@ -1261,6 +1347,7 @@ func (r *MySQLClaimDetailRepository) GetDigitalDocuments(ctx context.Context, no
 		FROM berkas_digital_perawatan bdp
 		LEFT JOIN master_berkas_digital mbd ON bdp.kode = mbd.kode
 		WHERE bdp.no_rawat = ?
+		ORDER BY mbd.nama, bdp.lokasi_file
 	`
 
 	rows, err := r.db.QueryContext(ctx, query, noRawat)
@@ -1413,6 +1500,17 @@ func (r *MySQLClaimDetailRepository) GetClaimFullDetail(ctx context.Context, noR
 	return detail, nil
 }
 
+// actionPemisah names the pemisah of aggregated medical actions after the
+// billing.status values SIMRS writes for the same source tables.
+var actionPemisah = map[string]string{
+	"Tindakan Dokter (Ralan)":           "Ralan Dokter",
+	"Tindakan Perawat (Ralan)":          "Ralan Paramedis",
+	"Tindakan Dokter & Perawat (Ralan)": "Ralan Dokter Paramedis",
+	"Tindakan Dokter (Ranap)":           "Ranap Dokter",
+	"Tindakan Perawat (Ranap)":          "Ranap Paramedis",
+	"Tindakan Dokter & Perawat (Ranap)": "Ranap Dokter Paramedis",
+}
+
 // aggregateBillingDetails aggregates data from detail tables if the summary 'billing' table is empty.
 func (r *MySQLClaimDetailRepository) aggregateBillingDetails(ctx context.Context, noRawat string, billing *entity.BillingSummary) {
 	noRawat = strings.TrimPrefix(noRawat, "/")
@@ -1430,6 +1528,7 @@ func (r *MySQLClaimDetailRepository) aggregateBillingDetails(ctx context.Context
 		cat.Items = append(cat.Items, entity.BillingItem{
 			No:            1,
 			NamaPerawatan: "Biaya Registrasi/Administrasi",
+			Pemisah:       "Registrasi",
 			Biaya:         biayaReg,
 			Jumlah:        1,
 			TotalBiaya:    biayaReg,
@@ -1448,6 +1547,8 @@ func (r *MySQLClaimDetailRepository) aggregateBillingDetails(ctx context.Context
 				item := entity.BillingItem{
 					No:            i + 1,
 					NamaPerawatan: m.NamaObat,
+					Pemisah:       "Obat",
+					KdJenisPrw:    m.KodeBrng,
 					Biaya:         m.Biaya / m.Jumlah, // Standardize to unit price
 					Jumlah:        int(m.Jumlah),
 					TotalBiaya:    m.Biaya,
@@ -1473,6 +1574,7 @@ func (r *MySQLClaimDetailRepository) aggregateBillingDetails(ctx context.Context
 			item := entity.BillingItem{
 				No:            i + 1,
 				NamaPerawatan: fmt.Sprintf("%s (%s)", rs.Bangsal, rs.Kamar),
+				Pemisah:       "Kamar",
 				Biaya:         rs.Tarif,
 				Jumlah:        rs.LamaInap,
 				TotalBiaya:    rs.TotalBiaya,
@@ -1511,28 +1613,28 @@ func (r *MySQLClaimDetailRepository) aggregateBillingDetails(ctx context.Context
 			rowsOp.Scan(&jm, &alat, &ok, &sarana, &oml, &srp, &lain)
 
 			if jm > 0 {
-				cat.Items = append(cat.Items, entity.BillingItem{No: idx, NamaPerawatan: "Jasa Medis & Tim Operasi", Biaya: jm, Jumlah: 1, TotalBiaya: jm})
+				cat.Items = append(cat.Items, entity.BillingItem{No: idx, NamaPerawatan: "Jasa Medis & Tim Operasi", Pemisah: "Operasi", Biaya: jm, Jumlah: 1, TotalBiaya: jm})
 				cat.Subtotal += jm
 				idx++
 			}
 			if alat > 0 {
-				cat.Items = append(cat.Items, entity.BillingItem{No: idx, NamaPerawatan: "Biaya Alat Operasi", Biaya: alat, Jumlah: 1, TotalBiaya: alat})
+				cat.Items = append(cat.Items, entity.BillingItem{No: idx, NamaPerawatan: "Biaya Alat Operasi", Pemisah: "Operasi Alat", Biaya: alat, Jumlah: 1, TotalBiaya: alat})
 				cat.Subtotal += alat
 				idx++
 			}
 			if ok > 0 {
-				cat.Items = append(cat.Items, entity.BillingItem{No: idx, NamaPerawatan: "Sewa Kamar OK/VK", Biaya: ok, Jumlah: 1, TotalBiaya: ok})
+				cat.Items = append(cat.Items, entity.BillingItem{No: idx, NamaPerawatan: "Sewa Kamar OK/VK", Pemisah: "Operasi Sewa OK", Biaya: ok, Jumlah: 1, TotalBiaya: ok})
 				cat.Subtotal += ok
 				idx++
 			}
 			if sarana > 0 {
-				cat.Items = append(cat.Items, entity.BillingItem{No: idx, NamaPerawatan: "Sarana Operasi", Biaya: sarana, Jumlah: 1, TotalBiaya: sarana})
+				cat.Items = append(cat.Items, entity.BillingItem{No: idx, NamaPerawatan: "Sarana Operasi", Pemisah: "Operasi Sarana", Biaya: sarana, Jumlah: 1, TotalBiaya: sarana})
 				cat.Subtotal += sarana
 				idx++
 			}
 			if oml+srp+lain > 0 {
 				val := oml + srp + lain
-				cat.Items = append(cat.Items, entity.BillingItem{No: idx, NamaPerawatan: "Penunjang Operasi Lainnya", Biaya: val, Jumlah: 1, TotalBiaya: val})
+				cat.Items = append(cat.Items, entity.BillingItem{No: idx, NamaPerawatan: "Penunjang Operasi Lainnya", Pemisah: "Operasi Penunjang", Biaya: val, Jumlah: 1, TotalBiaya: val})
 				cat.Subtotal += val
 				idx++
 			}
@@ -1553,6 +1655,8 @@ func (r *MySQLClaimDetailRepository) aggregateBillingDetails(ctx context.Context
 				item := entity.BillingItem{
 					No:            i + 1,
 					NamaPerawatan: a.Nama,
+					Pemisah:       actionPemisah[a.Kategori],
+					KdJenisPrw:    a.Kode,
 					Biaya:         a.Cost,
 					Jumlah:        1,
 					TotalBiaya:    a.Cost,
@@ -1575,6 +1679,8 @@ func (r *MySQLClaimDetailRepository) aggregateBillingDetails(ctx context.Context
 			item := entity.BillingItem{
 				No:            i + 1,
 				NamaPerawatan: l.NamaTindakan,
+				Pemisah:       "Laborat",
+				KdJenisPrw:    l.Kode,
 				Biaya:         l.Biaya,
 				Jumlah:        1,
 				TotalBiaya:    l.Biaya,
@@ -1593,6 +1699,8 @@ func (r *MySQLClaimDetailRepository) aggregateBillingDetails(ctx context.Context
 			item := entity.BillingItem{
 				No:            i + 1,
 				NamaPerawatan: re.Nama,
+				Pemisah:       "Radiologi",
+				KdJenisPrw:    re.Kode,
 				Biaya:         re.Biaya,
 				Jumlah:        1,
 				TotalBiaya:    re.Biaya,
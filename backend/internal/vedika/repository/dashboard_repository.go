@@ -10,13 +10,17 @@ import (
 )
 
 // DashboardRepository handles dashboard-specific data access.
-// Uses active_period from mera_settings.
+// Counts cover an inclusive date range (YYYY-MM-DD); a period is its whole month.
 type DashboardRepository interface {
-	CountRencanaRalan(ctx context.Context, period string, carabayar []string) (int, error)
-	CountRencanaRanap(ctx context.Context, period string, carabayar []string) (int, error)
-	CountPengajuanByJenis(ctx context.Context, period string, jenis entity.JenisPelayanan) (int, error)
-	CountByStatusAndJenis(ctx context.Context, period string, status entity.ClaimStatus, jenis entity.JenisPelayanan) (int, error)
-	GetDailyTrend(ctx context.Context, period string, carabayar []string) ([]entity.DashboardTrendItem, error)
+	CountRencanaRalan(ctx context.Context, rng entity.DashboardRange, carabayar []string) (int, error)
+	CountRencanaRanap(ctx context.Context, rng entity.DashboardRange, carabayar []string) (int, error)
+	CountPengajuanByJenis(ctx context.Context, rng entity.DashboardRange, jenis entity.JenisPelayanan) (int, error)
+	CountByStatusAndJenis(ctx context.Context, rng entity.DashboardRange, status entity.ClaimStatus, jenis entity.JenisPelayanan) (int, error)
+	GetDailyTrend(ctx context.Context, rng entity.DashboardRange, carabayar []string) ([]entity.DashboardTrendItem, error)
+	ListVarianceClaims(ctx context.Context, filter entity.VarianceFilter) ([]entity.VarianceClaim, error)
+	CountWorkload(ctx context.Context, rng entity.DashboardRange) ([]entity.UserWorkload, error)
+	CountBreakdown(ctx context.Context, filter entity.BreakdownFilter) ([]entity.BreakdownCount, error)
+	CountAging(ctx context.Context, filter entity.AgingFilter) ([]entity.AgingCount, error)
 }
 
 // MySQLDashboardRepository implements DashboardRepository.
@@ -51,9 +55,9 @@ func toInterfaceSlice(s []string) []interface{} {
 }
 
 // CountRencanaRalan counts RALAN episodes not in mlite_vedika.
-// Uses reg_periksa.tgl_registrasi for period filtering.
+// Uses reg_periksa.tgl_registrasi for range filtering.
 // OPTIMIZED: Uses LEFT JOIN instead of NOT IN subquery for better performance.
-func (r *MySQLDashboardRepository) CountRencanaRalan(ctx context.Context, period string, carabayar []string) (int, error) {
+func (r *MySQLDashboardRepository) CountRencanaRalan(ctx context.Context, rng entity.DashboardRange, carabayar []string) (int, error) {
 	if len(carabayar) == 0 {
 		return 0, nil
 	}
@@ -63,13 +67,13 @@ func (r *MySQLDashboardRepository) CountRencanaRalan(ctx context.Context, period
 		INNER JOIN penjab pj ON rp.kd_pj = pj.kd_pj
 		LEFT JOIN mlite_vedika mv ON rp.no_rawat = mv.no_rawat AND mv.jenis = '2'
 		WHERE pj.kd_pj IN (%s)
-		  AND DATE_FORMAT(rp.tgl_registrasi, '%%Y-%%m') = ?
+		  AND rp.tgl_registrasi BETWEEN ? AND ?
 		  AND rp.status_lanjut = 'Ralan'
 		  AND UPPER(rp.stts) != 'BATAL'
 		  AND mv.no_rawat IS NULL
 	`, buildPlaceholders(len(carabayar)))
 
-	args := append(toInterfaceSlice(carabayar), period)
+	args := append(toInterfaceSlice(carabayar), rng.DateFrom, rng.DateTo)
 
 	var count int
 	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
@@ -80,9 +84,9 @@ func (r *MySQLDashboardRepository) CountRencanaRalan(ctx context.Context, period
 }
 
 // CountRencanaRanap counts RANAP episodes not in mlite_vedika.
-// Uses kamar_inap.tgl_keluar for period filtering. Only includes discharged patients.
+// Uses kamar_inap.tgl_keluar for range filtering. Only includes discharged patients.
 // OPTIMIZED: Uses LEFT JOIN instead of NOT IN subquery for better performance.
-func (r *MySQLDashboardRepository) CountRencanaRanap(ctx context.Context, period string, carabayar []string) (int, error) {
+func (r *MySQLDashboardRepository) CountRencanaRanap(ctx context.Context, rng entity.DashboardRange, carabayar []string) (int, error) {
 	if len(carabayar) == 0 {
 		return 0, nil
 	}
@@ -94,13 +98,13 @@ func (r *MySQLDashboardRepository) CountRencanaRanap(ctx context.Context, period
 		LEFT JOIN mlite_vedika mv ON rp.no_rawat = mv.no_rawat AND mv.jenis = '1'
 		WHERE pj.kd_pj IN (%s)
 		  AND ki.tgl_keluar IS NOT NULL
-		  AND DATE_FORMAT(ki.tgl_keluar, '%%Y-%%m') = ?
+		  AND ki.tgl_keluar BETWEEN ? AND ?
 		  AND rp.status_lanjut = 'Ranap'
 		  AND rp.stts != 'Batal'
 		  AND mv.no_rawat IS NULL
 	`, buildPlaceholders(len(carabayar)))
 
-	args := append(toInterfaceSlice(carabayar), period)
+	args := append(toInterfaceSlice(carabayar), rng.DateFrom, rng.DateTo)
 
 	var count int
 	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
@@ -111,23 +115,23 @@ func (r *MySQLDashboardRepository) CountRencanaRanap(ctx context.Context, period
 }
 
 // CountPengajuanByJenis counts episodes in mlite_vedika by jenis.
-func (r *MySQLDashboardRepository) CountPengajuanByJenis(ctx context.Context, period string, jenis entity.JenisPelayanan) (int, error) {
+func (r *MySQLDashboardRepository) CountPengajuanByJenis(ctx context.Context, rng entity.DashboardRange, jenis entity.JenisPelayanan) (int, error) {
 	// For backward compatibility or general count, but we can just use the new one with PENGAJUAN if needed.
 	// Actually, the user wants "Pengajuan" to be status-specific.
-	return r.CountByStatusAndJenis(ctx, period, entity.StatusPengajuan, jenis)
+	return r.CountByStatusAndJenis(ctx, rng, entity.StatusPengajuan, jenis)
 }
 
 // CountByStatusAndJenis counts episodes in mlite_vedika by status and jenis.
-func (r *MySQLDashboardRepository) CountByStatusAndJenis(ctx context.Context, period string, status entity.ClaimStatus, jenis entity.JenisPelayanan) (int, error) {
+func (r *MySQLDashboardRepository) CountByStatusAndJenis(ctx context.Context, rng entity.DashboardRange, status entity.ClaimStatus, jenis entity.JenisPelayanan) (int, error) {
 	query := `
 		SELECT COUNT(*) FROM mlite_vedika
-		WHERE DATE_FORMAT(tgl_registrasi, '%Y-%m') = ?
+		WHERE tgl_registrasi BETWEEN ? AND ?
 		  AND UPPER(status) = UPPER(?)
 		  AND jenis = ?
 	`
 
 	var count int
-	if err := r.db.QueryRowContext(ctx, query, period, string(status), jenis.ToDBValue()).Scan(&count); err != nil {
+	if err := r.db.QueryRowContext(ctx, query, rng.DateFrom, rng.DateTo, string(status), jenis.ToDBValue()).Scan(&count); err != nil {
 		return 0, fmt.Errorf("failed to count by status and jenis: %w", err)
 	}
 
@@ -136,7 +140,7 @@ func (r *MySQLDashboardRepository) CountByStatusAndJenis(ctx context.Context, pe
 
 // GetDailyTrend returns daily aggregation data for the dashboard chart.
 // OPTIMIZED: Uses single query with GROUP BY instead of N+1 queries.
-func (r *MySQLDashboardRepository) GetDailyTrend(ctx context.Context, period string, carabayar []string) ([]entity.DashboardTrendItem, error) {
+func (r *MySQLDashboardRepository) GetDailyTrend(ctx context.Context, rng entity.DashboardRange, carabayar []string) ([]entity.DashboardTrendItem, error) {
 	if len(carabayar) == 0 {
 		return []entity.DashboardTrendItem{}, nil
 	}
@@ -161,7 +165,7 @@ func (r *MySQLDashboardRepository) GetDailyTrend(ctx context.Context, period str
 			FROM reg_periksa rp
 			INNER JOIN penjab pj ON rp.kd_pj = pj.kd_pj
 			WHERE pj.kd_pj IN (%s)
-			  AND DATE_FORMAT(rp.tgl_registrasi, '%%Y-%%m') = ?
+			  AND rp.tgl_registrasi BETWEEN ? AND ?
 			  AND rp.status_lanjut = 'Ralan'
 			  AND rp.stts != 'Batal'
 			GROUP BY DATE(rp.tgl_registrasi)
@@ -176,7 +180,7 @@ func (r *MySQLDashboardRepository) GetDailyTrend(ctx context.Context, period str
 				COUNT(*) as pengajuan_ralan,
 				0 as pengajuan_ranap
 			FROM mlite_vedika
-			WHERE DATE_FORMAT(tgl_registrasi, '%%Y-%%m') = ?
+			WHERE tgl_registrasi BETWEEN ? AND ?
 			  AND jenis = '2'
 			GROUP BY DATE(tgl_registrasi)
 			
@@ -190,7 +194,7 @@ func (r *MySQLDashboardRepository) GetDailyTrend(ctx context.Context, period str
 				0 as pengajuan_ralan,
 				COUNT(*) as pengajuan_ranap
 			FROM mlite_vedika
-			WHERE DATE_FORMAT(tgl_registrasi, '%%Y-%%m') = ?
+			WHERE tgl_registrasi BETWEEN ? AND ?
 			  AND jenis = '1'
 			GROUP BY DATE(tgl_registrasi)
 		) combined
@@ -198,7 +202,8 @@ func (r *MySQLDashboardRepository) GetDailyTrend(ctx context.Context, period str
 		ORDER BY day
 	`, buildPlaceholders(len(carabayar)))
 
-	args := append(toInterfaceSlice(carabayar), period, period, period)
+	args := append(toInterfaceSlice(carabayar),
+		rng.DateFrom, rng.DateTo, rng.DateFrom, rng.DateTo, rng.DateFrom, rng.DateTo)
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -231,3 +236,361 @@ func (r *MySQLDashboardRepository) GetDailyTrend(ctx context.Context, period str
 
 	return trend, nil
 }
+
+// ListVarianceClaims returns the grouped claims (mera_vedika_inacbg) of the
+// period range with their hospital and INA-CBG tariffs.
+// The hospital tariff follows GetBilling: the latest mlite_billing.jumlah_total
+// (when that table exists), else the sum of billing.totalbiaya, else the
+// tarif_rs sent at grouping time.
+func (r *MySQLDashboardRepository) ListVarianceClaims(ctx context.Context, filter entity.VarianceFilter) ([]entity.VarianceClaim, error) {
+	if len(filter.Carabayar) == 0 {
+		return []entity.VarianceClaim{}, nil
+	}
+
+	withMliteBilling, err := tableExists(ctx, r.db, "mlite_billing")
+	if err != nil {
+		return nil, err
+	}
+	mliteBilling := "NULL"
+	if withMliteBilling {
+		mliteBilling = mliteBillingTotal("g.no_rawat")
+	}
+
+	jenisWhere := ""
+	args := toInterfaceSlice(filter.Carabayar)
+	switch filter.Jenis {
+	case entity.JenisRalan:
+		jenisWhere = "AND rp.status_lanjut = 'Ralan'"
+	case entity.JenisRanap:
+		jenisWhere = "AND rp.status_lanjut = 'Ranap'"
+	}
+	args = append(args, filter.PeriodFrom, filter.PeriodTo)
+
+	query := fmt.Sprintf(`
+		SELECT
+			v.no_rawat,
+			v.nosep,
+			v.jenis,
+			v.periode,
+			COALESCE(v.kd_dokter, ''),
+			COALESCE(d.nm_dokter, ''),
+			COALESCE(v.kd_unit, ''),
+			CASE WHEN v.jenis = 'ranap' THEN COALESCE(b.nm_bangsal, '') ELSE COALESCE(pol.nm_poli, '') END,
+			v.cbg_code,
+			v.cbg_description,
+			v.tarif_rs,
+			v.total_tariff
+		FROM (
+			SELECT
+				g.no_rawat,
+				g.nosep,
+				LOWER(rp.status_lanjut) as jenis,
+				CASE
+					WHEN rp.status_lanjut = 'Ranap' THEN DATE_FORMAT((
+						SELECT MAX(ki.tgl_keluar) FROM kamar_inap ki
+						WHERE ki.no_rawat = g.no_rawat AND ki.tgl_keluar IS NOT NULL
+					), '%%Y-%%m')
+					ELSE DATE_FORMAT(rp.tgl_registrasi, '%%Y-%%m')
+				END as periode,
+				CASE
+					WHEN rp.status_lanjut = 'Ranap' THEN COALESCE((
+						SELECT dr.kd_dokter FROM dpjp_ranap dr
+						WHERE dr.no_rawat = g.no_rawat
+						ORDER BY dr.nomor LIMIT 1
+					), rp.kd_dokter)
+					ELSE rp.kd_dokter
+				END as kd_dokter,
+				CASE
+					WHEN rp.status_lanjut = 'Ranap' THEN (
+						SELECT km.kd_bangsal FROM kamar_inap ki
+						INNER JOIN kamar km ON ki.kd_kamar = km.kd_kamar
+						WHERE ki.no_rawat = g.no_rawat
+						ORDER BY ki.tgl_masuk DESC, ki.jam_masuk DESC LIMIT 1
+					)
+					ELSE rp.kd_poli
+				END as kd_unit,
+				g.cbg_code,
+				g.cbg_description,
+				COALESCE(
+					%s,
+					NULLIF((SELECT SUM(bl.totalbiaya) FROM billing bl WHERE bl.no_rawat = g.no_rawat), 0),
+					g.tarif_rs
+				) as tarif_rs,
+				g.total_tariff
+			FROM mera_vedika_inacbg g
+			INNER JOIN reg_periksa rp ON g.no_rawat = rp.no_rawat
+			WHERE rp.kd_pj IN (%s)
+			  %s
+		) v
+		LEFT JOIN dokter d ON v.kd_dokter = d.kd_dokter
+		LEFT JOIN poliklinik pol ON v.jenis = 'ralan' AND v.kd_unit = pol.kd_poli
+		LEFT JOIN bangsal b ON v.jenis = 'ranap' AND v.kd_unit = b.kd_bangsal
+		WHERE v.periode BETWEEN ? AND ?
+		ORDER BY v.periode, v.no_rawat
+	`, mliteBilling, buildPlaceholders(len(filter.Carabayar)), jenisWhere)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list variance claims: %w", err)
+	}
+	defer rows.Close()
+
+	claims := []entity.VarianceClaim{}
+	for rows.Next() {
+		var c entity.VarianceClaim
+		if err := rows.Scan(
+			&c.NoRawat,
+			&c.NoSEP,
+			&c.Jenis,
+			&c.Periode,
+			&c.KdDokter,
+			&c.Dokter,
+			&c.KdUnit,
+			&c.Unit,
+			&c.CBGCode,
+			&c.CBGDescription,
+			&c.TarifRS,
+			&c.TarifINACBG,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan variance claim: %w", err)
+		}
+		claims = append(claims, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating variance rows: %w", err)
+	}
+
+	return claims, nil
+}
+
+// CountWorkload counts the claims assigned to each coder by status, for
+// episodes served in the range. Episodes not yet in mlite_vedika count as
+// Rencana. Coders are ordered by username.
+func (r *MySQLDashboardRepository) CountWorkload(ctx context.Context, rng entity.DashboardRange) ([]entity.UserWorkload, error) {
+	query := `
+		SELECT a.user_id, COALESCE(u.username, a.user_id), COALESCE(NULLIF(mv.status, ''), 'Rencana'), COUNT(*)
+		FROM mera_vedika_assignment a
+		LEFT JOIN mera_users u ON a.user_id = u.id
+		LEFT JOIN mlite_vedika mv ON a.no_rawat = mv.no_rawat
+		WHERE a.tgl_pelayanan BETWEEN ? AND ?
+		GROUP BY a.user_id, u.username, COALESCE(NULLIF(mv.status, ''), 'Rencana')
+		ORDER BY COALESCE(u.username, a.user_id)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, rng.DateFrom, rng.DateTo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count workload: %w", err)
+	}
+	defer rows.Close()
+
+	workload := []entity.UserWorkload{}
+	index := map[string]int{}
+	for rows.Next() {
+		var userID, username, status string
+		var n int
+		if err := rows.Scan(&userID, &username, &status, &n); err != nil {
+			return nil, fmt.Errorf("failed to scan workload: %w", err)
+		}
+		i, ok := index[userID]
+		if !ok {
+			i = len(workload)
+			index[userID] = i
+			workload = append(workload, entity.UserWorkload{UserID: userID, Username: username})
+		}
+		workload[i].Add(entity.ClaimStatus(status), n)
+	}
+	return workload, rows.Err()
+}
+
+// breakdownKeys returns the ralan and ranap group key expressions, and the
+// label expression and joins over the combined episodes (e), per grouping.
+// Ranap units and DPJP follow ListVarianceClaims.
+func breakdownKeys(groupBy string) (ralanKey, ranapKey, label, joins string) {
+	switch groupBy {
+	case entity.BreakdownByDPJP:
+		return "rp.kd_dokter", `COALESCE((
+					SELECT dr.kd_dokter FROM dpjp_ranap dr
+					WHERE dr.no_rawat = rp.no_rawat
+					ORDER BY dr.nomor LIMIT 1
+				), rp.kd_dokter)`,
+			"COALESCE(d.nm_dokter, '')",
+			"LEFT JOIN dokter d ON e.kd_group = d.kd_dokter"
+	case entity.BreakdownByCarabayar:
+		return "rp.kd_pj", "rp.kd_pj",
+			"COALESCE(pj.png_jawab, '')",
+			"LEFT JOIN penjab pj ON e.kd_group = pj.kd_pj"
+	default:
+		return "rp.kd_poli", `(
+					SELECT km.kd_bangsal FROM kamar_inap ki
+					INNER JOIN kamar km ON ki.kd_kamar = km.kd_kamar
+					WHERE ki.no_rawat = rp.no_rawat
+					ORDER BY ki.tgl_masuk DESC, ki.jam_masuk DESC LIMIT 1
+				)`,
+			"CASE WHEN e.jenis = 'ranap' THEN COALESCE(b.nm_bangsal, '') ELSE COALESCE(pol.nm_poli, '') END",
+			`LEFT JOIN poliklinik pol ON e.jenis = 'ralan' AND e.kd_group = pol.kd_poli
+		LEFT JOIN bangsal b ON e.jenis = 'ranap' AND e.kd_group = b.kd_bangsal`
+	}
+}
+
+// dashboardEpisodes selects the episodes of a range as the dashboard cards
+// count them: recorded episodes by mlite_vedika.tgl_registrasi, as in
+// CountByStatusAndJenis, and the rest as in CountRencanaRalan and
+// CountRencanaRanap. ralanCols and ranapCols are the select lists over rp
+// and mv; mv is NULL for episodes not yet recorded. Takes
+// dashboardEpisodesArgs.
+func dashboardEpisodes(ralanCols, ranapCols string, nCarabayar int) string {
+	return fmt.Sprintf(`
+			SELECT %[1]s
+			FROM reg_periksa rp
+			LEFT JOIN mlite_vedika mv ON rp.no_rawat = mv.no_rawat AND mv.jenis = '2'
+			WHERE rp.kd_pj IN (%[3]s)
+			  AND rp.tgl_registrasi BETWEEN ? AND ?
+			  AND rp.status_lanjut = 'Ralan'
+			  AND UPPER(rp.stts) != 'BATAL'
+			  AND mv.no_rawat IS NULL
+
+			UNION ALL
+
+			SELECT %[1]s
+			FROM mlite_vedika mv
+			INNER JOIN reg_periksa rp ON rp.no_rawat = mv.no_rawat
+			WHERE rp.kd_pj IN (%[3]s)
+			  AND mv.tgl_registrasi BETWEEN ? AND ?
+			  AND mv.jenis = '2'
+
+			UNION ALL
+
+			SELECT %[2]s
+			FROM reg_periksa rp
+			LEFT JOIN mlite_vedika mv ON rp.no_rawat = mv.no_rawat AND mv.jenis = '1'
+			WHERE rp.kd_pj IN (%[3]s)
+			  AND EXISTS (
+				SELECT 1 FROM kamar_inap ki
+				WHERE ki.no_rawat = rp.no_rawat AND ki.tgl_keluar BETWEEN ? AND ?
+			  )
+			  AND rp.status_lanjut = 'Ranap'
+			  AND rp.stts != 'Batal'
+			  AND mv.no_rawat IS NULL
+
+			UNION ALL
+
+			SELECT %[2]s
+			FROM mlite_vedika mv
+			INNER JOIN reg_periksa rp ON rp.no_rawat = mv.no_rawat
+			WHERE rp.kd_pj IN (%[3]s)
+			  AND mv.tgl_registrasi BETWEEN ? AND ?
+			  AND mv.jenis = '1'`, ralanCols, ranapCols, buildPlaceholders(nCarabayar))
+}
+
+// dashboardEpisodesArgs returns the arguments of dashboardEpisodes.
+func dashboardEpisodesArgs(rng entity.DashboardRange, carabayar []string) []interface{} {
+	var args []interface{}
+	for i := 0; i < 4; i++ {
+		args = append(args, toInterfaceSlice(carabayar)...)
+		args = append(args, rng.DateFrom, rng.DateTo)
+	}
+	return args
+}
+
+// CountBreakdown counts the episodes of the range by group, jenis and status.
+// Episodes are selected by dashboardEpisodes, with their mlite_vedika
+// status, or Rencana when not yet recorded there, so the totals match the
+// dashboard cards.
+func (r *MySQLDashboardRepository) CountBreakdown(ctx context.Context, filter entity.BreakdownFilter) ([]entity.BreakdownCount, error) {
+	if len(filter.Carabayar) == 0 {
+		return []entity.BreakdownCount{}, nil
+	}
+
+	ralanKey, ranapKey, label, joins := breakdownKeys(filter.GroupBy)
+	cols := `
+				COALESCE(%s, '') as kd_group,
+				'%s' as jenis,
+				COALESCE(NULLIF(mv.status, ''), 'Rencana') as status`
+	query := fmt.Sprintf(`
+		SELECT e.kd_group, %s, e.jenis, e.status, COUNT(*)
+		FROM (%s
+		) e
+		%s
+		GROUP BY e.kd_group, %s, e.jenis, e.status
+	`, label, dashboardEpisodes(fmt.Sprintf(cols, ralanKey, "ralan"), fmt.Sprintf(cols, ranapKey, "ranap"), len(filter.Carabayar)), joins, label)
+
+	args := dashboardEpisodesArgs(filter.Range, filter.Carabayar)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count breakdown: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []entity.BreakdownCount{}
+	for rows.Next() {
+		var c entity.BreakdownCount
+		var jenis, status string
+		if err := rows.Scan(&c.Key, &c.Label, &jenis, &status, &c.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan breakdown: %w", err)
+		}
+		c.Jenis = entity.JenisPelayanan(jenis)
+		c.Status = entity.ClaimStatus(status)
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+// CountAging counts the episodes of the range by jenis, status, age and time
+// in status. Episodes are selected as in CountBreakdown. Age counts from
+// registration (ralan) or the last discharge (ranap); Rencana episodes have
+// been in their status since then, others since their last change to it in
+// the status history.
+func (r *MySQLDashboardRepository) CountAging(ctx context.Context, filter entity.AgingFilter) ([]entity.AgingCount, error) {
+	if len(filter.Carabayar) == 0 {
+		return []entity.AgingCount{}, nil
+	}
+
+	cols := `
+				rp.no_rawat,
+				'%s' as jenis,
+				COALESCE(NULLIF(mv.status, ''), 'Rencana') as status,
+				%s as tgl_layanan,
+				mv.tanggal as tgl_tercatat`
+	ralanCols := fmt.Sprintf(cols, "ralan", "rp.tgl_registrasi")
+	ranapCols := fmt.Sprintf(cols, "ranap", "(SELECT MAX(ki.tgl_keluar) FROM kamar_inap ki WHERE ki.no_rawat = rp.no_rawat)")
+	query := fmt.Sprintf(`
+		SELECT
+			e.jenis,
+			e.status,
+			COALESCE(DATEDIFF(CURDATE(), e.tgl_layanan), 0) as age_days,
+			COALESCE(DATEDIFF(CURDATE(), CASE
+				WHEN e.status = 'Rencana' THEN e.tgl_layanan
+				ELSE COALESCE((
+					SELECT MAX(h.created_at) FROM mera_vedika_status_history h
+					WHERE h.no_rawat = e.no_rawat AND UPPER(h.new_status) = UPPER(e.status)
+				), e.tgl_tercatat)
+			END), 0) as days_in_status,
+			COUNT(*)
+		FROM (%s
+		) e
+		GROUP BY e.jenis, e.status, age_days, days_in_status
+	`, dashboardEpisodes(ralanCols, ranapCols, len(filter.Carabayar)))
+
+	args := dashboardEpisodesArgs(filter.Range, filter.Carabayar)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count aging: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []entity.AgingCount{}
+	for rows.Next() {
+		var c entity.AgingCount
+		var jenis, status string
+		if err := rows.Scan(&jenis, &status, &c.AgeDays, &c.DaysInStatus, &c.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan aging: %w", err)
+		}
+		c.Jenis = entity.JenisPelayanan(jenis)
+		c.Status = entity.ClaimStatus(status)
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+)
+
+func TestDashboardEpisodesArgsMatchPlaceholders(t *testing.T) {
+	carabayar := []string{"BPJ", "A09"}
+	query := dashboardEpisodes("rp.no_rawat", "rp.no_rawat", len(carabayar))
+	args := dashboardEpisodesArgs(entity.DashboardRange{DateFrom: "2026-01-01", DateTo: "2026-01-31"}, carabayar)
+	if n := strings.Count(query, "?"); n != len(args) {
+		t.Fatalf("query has %d placeholders, got %d args", n, len(args))
+	}
+	if !strings.Contains(query, "mv.tgl_registrasi BETWEEN ? AND ?") {
+		t.Fatal("recorded episodes are not selected by mlite_vedika.tgl_registrasi")
+	}
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+)
+
+var (
+	// ErrDocumentCategoryNotFound indicates the kode is not in master_berkas_digital.
+	ErrDocumentCategoryNotFound = errors.New("digital document category not found")
+	// ErrDuplicateDocument indicates the same content is already stored for the episode.
+	ErrDuplicateDocument = errors.New("duplicate digital document")
+)
+
+// DocumentRepository handles upload rules and metadata of digital documents
+// (mera_vedika_document_rules, mera_vedika_documents).
+type DocumentRepository interface {
+	// Get the upload rule of a category, falling back to the defaults
+	GetRule(ctx context.Context, kode string) (*entity.DocumentRule, error)
+	// Find the file of an episode with the given content hash (nil if none)
+	FindByHash(ctx context.Context, noRawat string, sha256 string) (*entity.DocumentFile, error)
+	// Record the metadata of an uploaded file
+	SaveFile(ctx context.Context, f *entity.DocumentFile) error
+	// Remove the metadata of a file
+	DeleteFile(ctx context.Context, noRawat string, lokasiFile string) error
+}
+
+// MySQLDocumentRepository implements DocumentRepository.
+type MySQLDocumentRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLDocumentRepository creates a new document repository.
+func NewMySQLDocumentRepository(db *sql.DB) *MySQLDocumentRepository {
+	return &MySQLDocumentRepository{db: db}
+}
+
+// GetRule returns the upload rule of a master_berkas_digital category.
+func (r *MySQLDocumentRepository) GetRule(ctx context.Context, kode string) (*entity.DocumentRule, error) {
+	var rule entity.DocumentRule
+	var maxSizeKB sql.NullInt64
+	var allowedTypes sql.NullString
+	var convert sql.NullBool
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT m.kode, m.nama, dr.max_size_kb, dr.allowed_types, dr.convert_to_pdf
+		FROM master_berkas_digital m
+		LEFT JOIN mera_vedika_document_rules dr ON dr.kode = m.kode
+		WHERE m.kode = ?
+	`, kode).Scan(&rule.Kode, &rule.Nama, &maxSizeKB, &allowedTypes, &convert)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrDocumentCategoryNotFound, kode)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document rule: %w", err)
+	}
+
+	rule.MaxSizeKB = entity.DefaultDocumentMaxSizeKB
+	if maxSizeKB.Valid && maxSizeKB.Int64 > 0 {
+		rule.MaxSizeKB = int(maxSizeKB.Int64)
+	}
+	types := entity.DefaultDocumentTypes
+	if allowedTypes.Valid && strings.TrimSpace(allowedTypes.String) != "" {
+		types = allowedTypes.String
+	}
+	for _, t := range strings.Split(types, ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			rule.AllowedTypes = append(rule.AllowedTypes, t)
+		}
+	}
+	rule.ConvertToPDF = convert.Valid && convert.Bool
+
+	return &rule, nil
+}
+
+// FindByHash returns the file of an episode with the given content hash.
+func (r *MySQLDocumentRepository) FindByHash(ctx context.Context, noRawat string, sha256 string) (*entity.DocumentFile, error) {
+	var f entity.DocumentFile
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, no_rawat, kode, lokasi_file, sha256, original_name, content_type,
+			size, converted, uploaded_by, uploaded_at
+		FROM mera_vedika_documents
+		WHERE no_rawat = ? AND sha256 = ?
+	`, noRawat, sha256).Scan(
+		&f.ID,
+		&f.NoRawat,
+		&f.Kode,
+		&f.LokasiFile,
+		&f.SHA256,
+		&f.OriginalName,
+		&f.ContentType,
+		&f.Size,
+		&f.Converted,
+		&f.UploadedBy,
+		&f.UploadedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find document by hash: %w", err)
+	}
+	return &f, nil
+}
+
+// SaveFile records the metadata of an uploaded file. The unique key on
+// (no_rawat, sha256) turns concurrent duplicate uploads into ErrDuplicateDocument.
+func (r *MySQLDocumentRepository) SaveFile(ctx context.Context, f *entity.DocumentFile) error {
+	if f.ID == "" {
+		f.ID = uuid.New().String()
+	}
+	f.UploadedBy = resolveUsername(ctx, r.db, f.UploadedBy)
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO mera_vedika_documents (
+			id, no_rawat, kode, lokasi_file, sha256, original_name, content_type,
+			size, converted, uploaded_by, uploaded_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW())
+	`,
+		f.ID, f.NoRawat, f.Kode, f.LokasiFile, f.SHA256, f.OriginalName, f.ContentType,
+		f.Size, f.Converted, f.UploadedBy,
+	)
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+		return fmt.Errorf("%w: %s", ErrDuplicateDocument, f.SHA256)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save document metadata: %w", err)
+	}
+	return nil
+}
+
+// DeleteFile removes the metadata of a file. Files uploaded before the
+// metadata table existed have no row, which is not an error.
+func (r *MySQLDocumentRepository) DeleteFile(ctx context.Context, noRawat string, lokasiFile string) error {
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM mera_vedika_documents WHERE no_rawat = ? AND lokasi_file = ?
+	`, noRawat, lokasiFile)
+	if err != nil {
+		return fmt.Errorf("failed to delete document metadata: %w", err)
+	}
+	return nil
+}
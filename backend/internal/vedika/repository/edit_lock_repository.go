@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+)
+
+// ErrEditLockNotFound indicates the claim has no lock row.
+var ErrEditLockNotFound = errors.New("edit lock not found")
+
+// EditLockRepository handles mera_vedika_edit_lock access.
+type EditLockRepository interface {
+	// Take the lock if it is free or expired, or renew it if userID holds
+	// it; returns the lock as stored afterwards, whoever holds it
+	Acquire(ctx context.Context, noRawat, userID string, now, expiresAt time.Time) (*entity.EditLock, error)
+	// Extend an unexpired lock held by userID; reports whether it was
+	Renew(ctx context.Context, noRawat, userID string, now, expiresAt time.Time) (bool, error)
+	// Get the lock row of a claim, expired or not
+	Get(ctx context.Context, noRawat string) (*entity.EditLock, error)
+	// Delete the lock if held by userID; reports whether it was
+	Release(ctx context.Context, noRawat, userID string) (bool, error)
+}
+
+// MySQLEditLockRepository implements EditLockRepository.
+type MySQLEditLockRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLEditLockRepository creates a new edit lock repository.
+func NewMySQLEditLockRepository(db *sql.DB) *MySQLEditLockRepository {
+	return &MySQLEditLockRepository{db: db}
+}
+
+// Acquire upserts the lock in one statement so concurrent openers cannot
+// both win. ON DUPLICATE KEY UPDATE assigns left to right: acquired_at and
+// user_id are decided on the old expiry, then expires_at is only pushed
+// forward when user_id is now the caller.
+func (r *MySQLEditLockRepository) Acquire(ctx context.Context, noRawat, userID string, now, expiresAt time.Time) (*entity.EditLock, error) {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO mera_vedika_edit_lock (no_rawat, user_id, acquired_at, expires_at)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			acquired_at = IF(expires_at <= VALUES(acquired_at), VALUES(acquired_at), acquired_at),
+			user_id = IF(expires_at <= VALUES(acquired_at), VALUES(user_id), user_id),
+			expires_at = IF(user_id = VALUES(user_id), VALUES(expires_at), expires_at)
+	`, noRawat, userID, now, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire edit lock: %w", err)
+	}
+	return r.Get(ctx, noRawat)
+}
+
+// Renew extends an unexpired lock held by userID.
+func (r *MySQLEditLockRepository) Renew(ctx context.Context, noRawat, userID string, now, expiresAt time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE mera_vedika_edit_lock SET expires_at = ?
+		WHERE no_rawat = ? AND user_id = ? AND expires_at > ?
+	`, expiresAt, noRawat, userID, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to renew edit lock: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to renew edit lock: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Get returns the lock row of a claim with the holder's username.
+func (r *MySQLEditLockRepository) Get(ctx context.Context, noRawat string) (*entity.EditLock, error) {
+	var l entity.EditLock
+	err := r.db.QueryRowContext(ctx, `
+		SELECT l.no_rawat, l.user_id, COALESCE(u.username, l.user_id), l.acquired_at, l.expires_at
+		FROM mera_vedika_edit_lock l
+		LEFT JOIN mera_users u ON l.user_id = u.id
+		WHERE l.no_rawat = ?
+	`, noRawat).Scan(&l.NoRawat, &l.UserID, &l.Username, &l.AcquiredAt, &l.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrEditLockNotFound, noRawat)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get edit lock: %w", err)
+	}
+	return &l, nil
+}
+
+// Release deletes the lock if held by userID.
+func (r *MySQLEditLockRepository) Release(ctx context.Context, noRawat, userID string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM mera_vedika_edit_lock WHERE no_rawat = ? AND user_id = ?`, noRawat, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to release edit lock: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to release edit lock: %w", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+)
+
+var (
+	// ErrFeedbackNotFound indicates the requested feedback note does not exist.
+	ErrFeedbackNotFound = errors.New("feedback note not found")
+	// ErrSEPNotFound indicates the episode has no SEP to attach feedback to.
+	ErrSEPNotFound = errors.New("episode has no SEP")
+)
+
+// FeedbackRepository handles mlite_vedika_feedback access.
+// Notes are keyed by SEP; replies point at their root note via parent_id.
+type FeedbackRepository interface {
+	// Resolve the SEP number of an episode
+	GetNoSEP(ctx context.Context, noRawat string) (string, error)
+	// List all notes (roots and replies) for a SEP, oldest first
+	ListBySEP(ctx context.Context, noSEP string) ([]entity.FeedbackNote, error)
+	// List unresolved root notes for a SEP
+	ListOpenBySEP(ctx context.Context, noSEP string) ([]entity.FeedbackNote, error)
+	// Count unresolved root notes for a SEP
+	CountOpenBySEP(ctx context.Context, noSEP string) (int, error)
+	// Get a single note
+	GetByID(ctx context.Context, id int64) (*entity.FeedbackNote, error)
+	// Add a note or reply
+	Create(ctx context.Context, note *entity.FeedbackNote) error
+	// Mark a root note (and therefore its thread) as resolved
+	Resolve(ctx context.Context, id int64, username string) error
+}
+
+// MySQLFeedbackRepository implements FeedbackRepository.
+type MySQLFeedbackRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLFeedbackRepository creates a new feedback repository.
+func NewMySQLFeedbackRepository(db *sql.DB) *MySQLFeedbackRepository {
+	return &MySQLFeedbackRepository{db: db}
+}
+
+const feedbackColumns = `
+	id, nosep, parent_id, DATE_FORMAT(tanggal, '%Y-%m-%d'), catatan, username,
+	is_resolved, COALESCE(resolved_by, ''), resolved_at, created_at
+`
+
+// GetNoSEP returns the SEP number of an episode from bridging_sep.
+func (r *MySQLFeedbackRepository) GetNoSEP(ctx context.Context, noRawat string) (string, error) {
+	var noSEP string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(no_sep, '') FROM bridging_sep WHERE no_rawat = ? LIMIT 1
+	`, noRawat).Scan(&noSEP)
+	if err == sql.ErrNoRows || (err == nil && noSEP == "") {
+		return "", fmt.Errorf("%w: %s", ErrSEPNotFound, noRawat)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get SEP: %w", err)
+	}
+	return noSEP, nil
+}
+
+// ListBySEP returns all notes for a SEP ordered by id.
+func (r *MySQLFeedbackRepository) ListBySEP(ctx context.Context, noSEP string) ([]entity.FeedbackNote, error) {
+	return r.queryNotes(ctx, `
+		SELECT `+feedbackColumns+`
+		FROM mlite_vedika_feedback
+		WHERE nosep = ?
+		ORDER BY id
+	`, noSEP)
+}
+
+// ListOpenBySEP returns unresolved root notes for a SEP ordered by id.
+func (r *MySQLFeedbackRepository) ListOpenBySEP(ctx context.Context, noSEP string) ([]entity.FeedbackNote, error) {
+	return r.queryNotes(ctx, `
+		SELECT `+feedbackColumns+`
+		FROM mlite_vedika_feedback
+		WHERE nosep = ? AND parent_id IS NULL AND is_resolved = 0
+		ORDER BY id
+	`, noSEP)
+}
+
+// CountOpenBySEP counts unresolved root notes for a SEP.
+func (r *MySQLFeedbackRepository) CountOpenBySEP(ctx context.Context, noSEP string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM mlite_vedika_feedback
+		WHERE nosep = ? AND parent_id IS NULL AND is_resolved = 0
+	`, noSEP).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count open feedback: %w", err)
+	}
+	return count, nil
+}
+
+// GetByID returns a single note.
+func (r *MySQLFeedbackRepository) GetByID(ctx context.Context, id int64) (*entity.FeedbackNote, error) {
+	notes, err := r.queryNotes(ctx, `
+		SELECT `+feedbackColumns+`
+		FROM mlite_vedika_feedback
+		WHERE id = ?
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(notes) == 0 {
+		return nil, fmt.Errorf("%w: %d", ErrFeedbackNotFound, id)
+	}
+	return &notes[0], nil
+}
+
+// Create inserts a note and sets its ID.
+func (r *MySQLFeedbackRepository) Create(ctx context.Context, note *entity.FeedbackNote) error {
+	note.Username = resolveUsername(ctx, r.db, note.Username)
+
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO mlite_vedika_feedback (nosep, parent_id, tanggal, catatan, username, is_resolved, created_at)
+		VALUES (?, ?, CURDATE(), ?, ?, 0, NOW())
+	`, note.NoSEP, note.ParentID, note.Catatan, note.Username)
+	if err != nil {
+		return fmt.Errorf("failed to add feedback: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get feedback id: %w", err)
+	}
+	note.ID = id
+	return nil
+}
+
+// Resolve marks a root note as resolved.
+func (r *MySQLFeedbackRepository) Resolve(ctx context.Context, id int64, username string) error {
+	username = resolveUsername(ctx, r.db, username)
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE mlite_vedika_feedback
+		SET is_resolved = 1, resolved_by = ?, resolved_at = NOW()
+		WHERE id = ? AND parent_id IS NULL
+	`, username, id)
+	if err != nil {
+		return fmt.Errorf("failed to resolve feedback: %w", err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		// Either missing or already resolved; distinguish for the caller
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryNotes runs a note query and scans the rows.
+func (r *MySQLFeedbackRepository) queryNotes(ctx context.Context, query string, args ...interface{}) ([]entity.FeedbackNote, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+	defer rows.Close()
+
+	var notes []entity.FeedbackNote
+	for rows.Next() {
+		var n entity.FeedbackNote
+		var parentID sql.NullInt64
+		var resolvedAt, createdAt sql.NullTime
+		if err := rows.Scan(
+			&n.ID,
+			&n.NoSEP,
+			&parentID,
+			&n.Tanggal,
+			&n.Catatan,
+			&n.Username,
+			&n.IsResolved,
+			&n.ResolvedBy,
+			&resolvedAt,
+			&createdAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan feedback: %w", err)
+		}
+		if parentID.Valid {
+			n.ParentID = &parentID.Int64
+		}
+		if resolvedAt.Valid {
+			n.ResolvedAt = &resolvedAt.Time
+		}
+		if createdAt.Valid {
+			n.CreatedAt = &createdAt.Time
+		}
+		notes = append(notes, n)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating feedback rows: %w", err)
+	}
+
+	if notes == nil {
+		notes = []entity.FeedbackNote{}
+	}
+
+	return notes, nil
+}
+
+// resolveUsername maps a mera_users ID to its username.
+// Actors built by getActor carry the user ID in the Username field.
+func resolveUsername(ctx context.Context, db *sql.DB, idOrUsername string) string {
+	var username string
+	err := db.QueryRowContext(ctx, "SELECT username FROM mera_users WHERE id = ? OR username = ?", idOrUsername, idOrUsername).Scan(&username)
+	if err != nil || strings.TrimSpace(username) == "" {
+		return idOrUsername
+	}
+	return username
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+)
+
+var (
+	// ErrGroupingNotFound indicates the claim has not been grouped in E-Klaim yet.
+	ErrGroupingNotFound = errors.New("inacbg grouping not found")
+)
+
+// InacbgRepository handles mera_vedika_inacbg access.
+type InacbgRepository interface {
+	// Get the stored grouping of a claim
+	GetByNoRawat(ctx context.Context, noRawat string) (*entity.InacbgGrouping, error)
+	// Insert or replace the grouping of a claim (resets status to grouped)
+	SaveGrouping(ctx context.Context, g *entity.InacbgGrouping) error
+	// Move a grouping to final or sent
+	UpdateStatus(ctx context.Context, noRawat string, status entity.InacbgStatus) error
+}
+
+// MySQLInacbgRepository implements InacbgRepository.
+type MySQLInacbgRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLInacbgRepository creates a new INA-CBG grouping repository.
+func NewMySQLInacbgRepository(db *sql.DB) *MySQLInacbgRepository {
+	return &MySQLInacbgRepository{db: db}
+}
+
+// GetByNoRawat returns the stored grouping of a claim.
+func (r *MySQLInacbgRepository) GetByNoRawat(ctx context.Context, noRawat string) (*entity.InacbgGrouping, error) {
+	var g entity.InacbgGrouping
+	var specialCMG string
+	var finalizedAt, sentAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, no_rawat, nosep, cbg_code, cbg_description, cbg_tariff,
+			COALESCE(special_cmg, ''), total_tariff, tarif_rs, kelas_rawat,
+			COALESCE(inacbg_version, ''), status, coder_nik, grouped_by, grouped_at,
+			finalized_at, sent_at
+		FROM mera_vedika_inacbg
+		WHERE no_rawat = ?
+	`, noRawat).Scan(
+		&g.ID,
+		&g.NoRawat,
+		&g.NoSEP,
+		&g.CBGCode,
+		&g.CBGDescription,
+		&g.CBGTariff,
+		&specialCMG,
+		&g.TotalTariff,
+		&g.TarifRS,
+		&g.KelasRawat,
+		&g.INACBGVersion,
+		&g.Status,
+		&g.CoderNIK,
+		&g.GroupedBy,
+		&g.GroupedAt,
+		&finalizedAt,
+		&sentAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrGroupingNotFound, noRawat)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inacbg grouping: %w", err)
+	}
+
+	g.SpecialCMG = []string{}
+	if specialCMG != "" {
+		g.SpecialCMG = strings.Split(specialCMG, "#")
+	}
+	if finalizedAt.Valid {
+		g.FinalizedAt = &finalizedAt.Time
+	}
+	if sentAt.Valid {
+		g.SentAt = &sentAt.Time
+	}
+
+	return &g, nil
+}
+
+// SaveGrouping upserts the grouping of a claim.
+func (r *MySQLInacbgRepository) SaveGrouping(ctx context.Context, g *entity.InacbgGrouping) error {
+	if g.ID == "" {
+		g.ID = uuid.New().String()
+	}
+	g.Status = entity.InacbgGrouped
+	g.GroupedBy = resolveUsername(ctx, r.db, g.GroupedBy)
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO mera_vedika_inacbg (
+			id, no_rawat, nosep, cbg_code, cbg_description, cbg_tariff, special_cmg,
+			total_tariff, tarif_rs, kelas_rawat, inacbg_version, status, coder_nik,
+			grouped_by, grouped_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW())
+		ON DUPLICATE KEY UPDATE
+			nosep = VALUES(nosep),
+			cbg_code = VALUES(cbg_code),
+			cbg_description = VALUES(cbg_description),
+			cbg_tariff = VALUES(cbg_tariff),
+			special_cmg = VALUES(special_cmg),
+			total_tariff = VALUES(total_tariff),
+			tarif_rs = VALUES(tarif_rs),
+			kelas_rawat = VALUES(kelas_rawat),
+			inacbg_version = VALUES(inacbg_version),
+			status = VALUES(status),
+			coder_nik = VALUES(coder_nik),
+			grouped_by = VALUES(grouped_by),
+			grouped_at = NOW(),
+			finalized_at = NULL,
+			sent_at = NULL
+	`,
+		g.ID, g.NoRawat, g.NoSEP, g.CBGCode, g.CBGDescription, g.CBGTariff,
+		strings.Join(g.SpecialCMG, "#"), g.TotalTariff, g.TarifRS, g.KelasRawat,
+		g.INACBGVersion, g.Status, g.CoderNIK, g.GroupedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save inacbg grouping: %w", err)
+	}
+	return nil
+}
+
+// UpdateStatus stamps finalized_at or sent_at together with the new status.
+func (r *MySQLInacbgRepository) UpdateStatus(ctx context.Context, noRawat string, status entity.InacbgStatus) error {
+	column := "finalized_at"
+	if status == entity.InacbgSent {
+		column = "sent_at"
+	}
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE mera_vedika_inacbg SET status = ?, `+column+` = NOW() WHERE no_rawat = ?
+	`, status, noRawat)
+	if err != nil {
+		return fmt.Errorf("failed to update inacbg status: %w", err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return fmt.Errorf("%w: %s", ErrGroupingNotFound, noRawat)
+	}
+	return nil
+}
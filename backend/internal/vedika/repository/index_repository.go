@@ -3,23 +3,33 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/google/uuid"
+
 	"github.com/clinova/simrs/backend/internal/vedika/entity"
 )
 
+// ErrDocumentNotFound indicates no berkas_digital_perawatan row matches.
+var ErrDocumentNotFound = errors.New("digital document not found")
+
 // IndexRepository handles Index workbench data access.
 // Uses explicit date range filtering, NOT active_period.
 type IndexRepository interface {
 	// List episodes by date range and status
 	ListByDateRange(ctx context.Context, filter entity.IndexFilter) (*entity.PaginatedResult[entity.ClaimEpisode], error)
+	// Stream every episode matching the filter (no pagination), with coding and billing data
+	StreamByDateRange(ctx context.Context, filter entity.IndexFilter, fn func(*entity.IndexExportRow) error) error
 	// Get claim detail
 	GetClaimDetail(ctx context.Context, noRawat string) (*entity.ClaimDetail, error)
 	// Get episode status (RENCANA if not in mlite_vedika)
 	GetEpisodeStatus(ctx context.Context, noRawat string) (entity.ClaimStatus, error)
-	// Update claim status
-	UpdateClaimStatus(ctx context.Context, noRawat string, status entity.ClaimStatus, username string, catatan string) error
+	// Update claim status; fails with entity.ErrStatusChanged unless the current status is still from
+	UpdateClaimStatus(ctx context.Context, noRawat string, from, status entity.ClaimStatus, username string, catatan string) error
+	// Get claim status timeline
+	GetStatusHistory(ctx context.Context, noRawat string) ([]entity.StatusHistoryEntry, error)
 	// Get diagnoses
 	GetDiagnoses(ctx context.Context, noRawat string) ([]entity.DiagnosisItem, error)
 	// Get procedures
@@ -30,6 +40,8 @@ type IndexRepository interface {
 	SyncDiagnoses(ctx context.Context, noRawat string, diagnoses []entity.DiagnosisUpdateRequest, statusLanjut string) error
 	// Get episode type (Ralan/Ranap)
 	GetEpisodeType(ctx context.Context, noRawat string) (string, error)
+	// Get the patient facts checked by the coding validator
+	GetCodingPatient(ctx context.Context, noRawat string) (*entity.CodingPatient, error)
 	// Search ICD-10
 	SearchICD10(ctx context.Context, query string) ([]entity.ICD10Item, error)
 	// Add/Update procedure
@@ -44,12 +56,16 @@ type IndexRepository interface {
 	GetResume(ctx context.Context, noRawat string) (*entity.MedicalResume, error)
 	// Update resume
 	UpdateResume(ctx context.Context, noRawat string, resume *entity.MedicalResume) error
+	// Lock the claim's medical data against other edits until unlock is called
+	LockMedicalData(ctx context.Context, noRawat string) (unlock func(), err error)
 	// Get master digital document types
 	GetMasterDigitalDocs(ctx context.Context) ([]entity.ICD10Item, error)
 	// Add digital document record
 	AddDigitalDocument(ctx context.Context, noRawat string, kode string, lokasiFile string) error
 	// Delete digital document record
 	DeleteDigitalDocument(ctx context.Context, noRawat string, kode string, lokasiFile string) error
+	// Check a file is recorded for the episode
+	HasDigitalDocument(ctx context.Context, noRawat string, lokasiFile string) (bool, error)
 }
 
 // MySQLIndexRepository implements IndexRepository.
@@ -85,27 +101,12 @@ func (r *MySQLIndexRepository) ListByDateRange(ctx context.Context, filter entit
 // listRencana lists episodes NOT in mlite_vedika.
 func (r *MySQLIndexRepository) listRencana(ctx context.Context, filter entity.IndexFilter) (*entity.PaginatedResult[entity.ClaimEpisode], error) {
 	var query, countQuery string
-	var args, countArgs []interface{}
+	baseWhere, whereArgs := rencanaWhere(filter)
+	countArgs := whereArgs
+	args := append([]interface{}{}, whereArgs...)
 
 	if filter.Jenis == entity.JenisRanap {
 		// RANAP: use kamar_inap.tgl_keluar
-		baseWhere := `
-			ki.tgl_keluar IS NOT NULL
-			AND ki.tgl_keluar BETWEEN ? AND ?
-			AND rp.status_lanjut = 'Ranap'
-			AND rp.stts != 'Batal'
-			AND rp.no_rawat NOT IN (SELECT no_rawat FROM mlite_vedika)
-		`
-		countArgs = []interface{}{filter.DateFrom, filter.DateTo}
-		args = []interface{}{filter.DateFrom, filter.DateTo}
-
-		if filter.Search != "" {
-			baseWhere += " AND (p.nm_pasien LIKE ? OR rp.no_rawat LIKE ? OR rp.no_rkm_medis LIKE ?)"
-			searchPattern := "%" + filter.Search + "%"
-			countArgs = append(countArgs, searchPattern, searchPattern, searchPattern)
-			args = append(args, searchPattern, searchPattern, searchPattern)
-		}
-
 		countQuery = fmt.Sprintf(`
 			SELECT COUNT(DISTINCT rp.no_rawat) FROM reg_periksa rp
 			INNER JOIN pasien p ON rp.no_rkm_medis = p.no_rkm_medis
@@ -122,7 +123,9 @@ func (r *MySQLIndexRepository) listRencana(ctx context.Context, filter entity.In
 				DATE(MAX(ki.tgl_keluar)) as tgl_pelayanan,
 				COALESCE(b.nm_bangsal, '') as unit,
 				COALESCE(d.nm_dokter, '') as dokter,
-				pj.png_jawab as cara_bayar
+				pj.png_jawab as cara_bayar,
+				`+assignedColumn("rp.no_rawat")+`,
+				DATEDIFF(CURDATE(), MAX(ki.tgl_keluar)) as age_days
 			FROM reg_periksa rp
 			INNER JOIN pasien p ON rp.no_rkm_medis = p.no_rkm_medis
 			INNER JOIN penjab pj ON rp.kd_pj = pj.kd_pj
@@ -137,22 +140,6 @@ func (r *MySQLIndexRepository) listRencana(ctx context.Context, filter entity.In
 		`, baseWhere)
 	} else {
 		// RALAN: use reg_periksa.tgl_registrasi
-		baseWhere := `
-			rp.tgl_registrasi BETWEEN ? AND ?
-			AND rp.status_lanjut = 'Ralan'
-			AND rp.stts != 'Batal'
-			AND rp.no_rawat NOT IN (SELECT no_rawat FROM mlite_vedika)
-		`
-		countArgs = []interface{}{filter.DateFrom, filter.DateTo}
-		args = []interface{}{filter.DateFrom, filter.DateTo}
-
-		if filter.Search != "" {
-			baseWhere += " AND (p.nm_pasien LIKE ? OR rp.no_rawat LIKE ? OR rp.no_rkm_medis LIKE ?)"
-			searchPattern := "%" + filter.Search + "%"
-			countArgs = append(countArgs, searchPattern, searchPattern, searchPattern)
-			args = append(args, searchPattern, searchPattern, searchPattern)
-		}
-
 		countQuery = fmt.Sprintf(`
 			SELECT COUNT(*) FROM reg_periksa rp
 			INNER JOIN pasien p ON rp.no_rkm_medis = p.no_rkm_medis
@@ -168,7 +155,9 @@ func (r *MySQLIndexRepository) listRencana(ctx context.Context, filter entity.In
 				DATE(rp.tgl_registrasi) as tgl_pelayanan,
 				COALESCE(pol.nm_poli, '') as unit,
 				COALESCE(d.nm_dokter, '') as dokter,
-				pj.png_jawab as cara_bayar
+				pj.png_jawab as cara_bayar,
+				`+assignedColumn("rp.no_rawat")+`,
+				DATEDIFF(CURDATE(), rp.tgl_registrasi) as age_days
 			FROM reg_periksa rp
 			INNER JOIN pasien p ON rp.no_rkm_medis = p.no_rkm_medis
 			INNER JOIN penjab pj ON rp.kd_pj = pj.kd_pj
@@ -199,6 +188,7 @@ func (r *MySQLIndexRepository) listRencana(ctx context.Context, filter entity.In
 	var episodes []entity.ClaimEpisode
 	for rows.Next() {
 		var ep entity.ClaimEpisode
+		var aging entity.ClaimAging
 		if err := rows.Scan(
 			&ep.NoRawat,
 			&ep.NoRkmMedis,
@@ -208,10 +198,15 @@ func (r *MySQLIndexRepository) listRencana(ctx context.Context, filter entity.In
 			&ep.Unit,
 			&ep.Dokter,
 			&ep.CaraBayar,
+			&ep.AssignedTo,
+			&aging.AgeDays,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan rencana: %w", err)
 		}
 		ep.Status = entity.StatusRencana
+		// Rencana since service
+		aging.DaysInStatus = aging.AgeDays
+		ep.Aging = &aging
 		episodes = append(episodes, ep)
 	}
 
@@ -235,22 +230,7 @@ func (r *MySQLIndexRepository) listRencana(ctx context.Context, filter entity.In
 
 // listByStatus lists episodes in mlite_vedika filtered by status.
 func (r *MySQLIndexRepository) listByStatus(ctx context.Context, filter entity.IndexFilter) (*entity.PaginatedResult[entity.ClaimEpisode], error) {
-	whereClause := `
-		UPPER(mv.status) = UPPER(?)
-		AND mv.tgl_registrasi BETWEEN ? AND ?
-	`
-	args := []interface{}{string(filter.Status), filter.DateFrom, filter.DateTo}
-
-	if filter.Jenis != "" {
-		whereClause += " AND mv.jenis = ?"
-		args = append(args, filter.Jenis.ToDBValue())
-	}
-
-	if filter.Search != "" {
-		whereClause += " AND (p.nm_pasien LIKE ? OR mv.no_rawat LIKE ? OR mv.no_rkm_medis LIKE ?)"
-		searchPattern := "%" + filter.Search + "%"
-		args = append(args, searchPattern, searchPattern, searchPattern)
-	}
+	whereClause, args := statusWhere(filter)
 
 	// Count query
 	countQuery := fmt.Sprintf(`
@@ -279,7 +259,13 @@ func (r *MySQLIndexRepository) listByStatus(ctx context.Context, filter entity.I
 			END as unit,
 			COALESCE(d.nm_dokter, '') as dokter,
 			COALESCE(pj.png_jawab, '') as cara_bayar,
-			mv.status
+			mv.status,
+			`+assignedColumn("mv.no_rawat")+`,
+			COALESCE(DATEDIFF(CURDATE(), CASE
+				WHEN mv.jenis = '1' THEN COALESCE(MAX(ki.tgl_keluar), mv.tgl_registrasi)
+				ELSE mv.tgl_registrasi
+			END), 0) as age_days,
+			`+daysInStatusColumn()+`
 		FROM mlite_vedika mv
 		INNER JOIN pasien p ON mv.no_rkm_medis = p.no_rkm_medis
 		LEFT JOIN reg_periksa rp ON mv.no_rawat = rp.no_rawat
@@ -307,6 +293,7 @@ func (r *MySQLIndexRepository) listByStatus(ctx context.Context, filter entity.I
 	for rows.Next() {
 		var ep entity.ClaimEpisode
 		var status string
+		var aging entity.ClaimAging
 		if err := rows.Scan(
 			&ep.NoRawat,
 			&ep.NoRkmMedis,
@@ -317,10 +304,14 @@ func (r *MySQLIndexRepository) listByStatus(ctx context.Context, filter entity.I
 			&ep.Dokter,
 			&ep.CaraBayar,
 			&status,
+			&ep.AssignedTo,
+			&aging.AgeDays,
+			&aging.DaysInStatus,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan by status: %w", err)
 		}
 		ep.Status = entity.ClaimStatus(status)
+		ep.Aging = &aging
 		episodes = append(episodes, ep)
 	}
 
@@ -342,6 +333,298 @@ func (r *MySQLIndexRepository) listByStatus(ctx context.Context, filter entity.I
 	}, nil
 }
 
+// tableExists reports whether the current schema has the named table. The
+// mLITE tables (e.g. mlite_billing) are created by the host application, not
+// by our migrations, and may be missing on a plain SIMRS Khanza database.
+func tableExists(ctx context.Context, db *sql.DB, name string) (bool, error) {
+	var n int
+	err := db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM information_schema.tables
+		WHERE table_schema = DATABASE() AND table_name = ?
+	`, name).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("failed to check table %s: %w", name, err)
+	}
+	return n > 0, nil
+}
+
+// mliteBillingTotal returns a subquery of the latest mlite_billing total of
+// the episode identified by noRawatCol, as mLITE itself picks it (highest
+// id_billing).
+func mliteBillingTotal(noRawatCol string) string {
+	return "(SELECT mb.jumlah_total FROM mlite_billing mb WHERE mb.no_rawat = " + noRawatCol + " ORDER BY mb.id_billing DESC LIMIT 1)"
+}
+
+// exportColumns returns the enrichment columns of the index export for the
+// episode identified by noRawatCol. The mlite_billing total is only read
+// when withMliteBilling is set.
+func exportColumns(noRawatCol string, withMliteBilling bool) string {
+	mliteBilling := "NULL"
+	if withMliteBilling {
+		mliteBilling = mliteBillingTotal("{no_rawat}")
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(`
+		COALESCE(p.no_peserta, '') as no_kartu,
+		COALESCE((
+			SELECT GROUP_CONCAT(dp.kd_penyakit ORDER BY dp.prioritas SEPARATOR ';')
+			FROM diagnosa_pasien dp WHERE dp.no_rawat = {no_rawat}
+		), '') as diagnosa,
+		COALESCE((
+			SELECT GROUP_CONCAT(pp.kode ORDER BY pp.prioritas SEPARATOR ';')
+			FROM prosedur_pasien pp WHERE pp.no_rawat = {no_rawat}
+		), '') as prosedur,
+		COALESCE(
+			{mlite_billing},
+			(SELECT SUM(bl.totalbiaya) FROM billing bl WHERE bl.no_rawat = {no_rawat}),
+			0
+		) as total_billing
+	`, "{mlite_billing}", mliteBilling), "{no_rawat}", noRawatCol)
+}
+
+// StreamByDateRange streams every episode matching the filter, in the same
+// order as ListByDateRange but without pagination, calling fn for each row.
+// Billing totals come from mlite_billing when that table exists, else the
+// legacy billing table.
+func (r *MySQLIndexRepository) StreamByDateRange(ctx context.Context, filter entity.IndexFilter, fn func(*entity.IndexExportRow) error) error {
+	if filter.DateFrom == "" || filter.DateTo == "" {
+		return fmt.Errorf("date_from and date_to are required")
+	}
+	if !filter.Status.IsValid() {
+		return fmt.Errorf("invalid status: %s", filter.Status)
+	}
+	filter.Status = filter.Status.Normalize()
+
+	withMliteBilling, err := tableExists(ctx, r.db, "mlite_billing")
+	if err != nil {
+		return err
+	}
+
+	var query string
+	var args []interface{}
+
+	switch {
+	case filter.Status == entity.StatusRencana && filter.Jenis == entity.JenisRanap:
+		var where string
+		where, args = rencanaWhere(filter)
+		query = fmt.Sprintf(`
+			SELECT
+				rp.no_rawat,
+				rp.no_rkm_medis,
+				p.nm_pasien,
+				'ranap' as jenis,
+				DATE(MAX(ki.tgl_keluar)) as tgl_pelayanan,
+				COALESCE(b.nm_bangsal, '') as unit,
+				COALESCE(d.nm_dokter, '') as dokter,
+				pj.png_jawab as cara_bayar,
+				'Rencana' as status,
+				COALESCE((SELECT bs.no_sep FROM bridging_sep bs WHERE bs.no_rawat = rp.no_rawat LIMIT 1), '') as no_sep,
+				%s
+			FROM reg_periksa rp
+			INNER JOIN pasien p ON rp.no_rkm_medis = p.no_rkm_medis
+			INNER JOIN penjab pj ON rp.kd_pj = pj.kd_pj
+			INNER JOIN kamar_inap ki ON rp.no_rawat = ki.no_rawat
+			LEFT JOIN kamar km ON ki.kd_kamar = km.kd_kamar
+			LEFT JOIN bangsal b ON km.kd_bangsal = b.kd_bangsal
+			LEFT JOIN dokter d ON rp.kd_dokter = d.kd_dokter
+			WHERE %s
+			GROUP BY rp.no_rawat
+			ORDER BY MAX(ki.tgl_keluar) DESC
+		`, exportColumns("rp.no_rawat", withMliteBilling), where)
+
+	case filter.Status == entity.StatusRencana:
+		var where string
+		where, args = rencanaWhere(filter)
+		query = fmt.Sprintf(`
+			SELECT
+				rp.no_rawat,
+				rp.no_rkm_medis,
+				p.nm_pasien,
+				'ralan' as jenis,
+				DATE(rp.tgl_registrasi) as tgl_pelayanan,
+				COALESCE(pol.nm_poli, '') as unit,
+				COALESCE(d.nm_dokter, '') as dokter,
+				pj.png_jawab as cara_bayar,
+				'Rencana' as status,
+				COALESCE((SELECT bs.no_sep FROM bridging_sep bs WHERE bs.no_rawat = rp.no_rawat LIMIT 1), '') as no_sep,
+				%s
+			FROM reg_periksa rp
+			INNER JOIN pasien p ON rp.no_rkm_medis = p.no_rkm_medis
+			INNER JOIN penjab pj ON rp.kd_pj = pj.kd_pj
+			LEFT JOIN poliklinik pol ON rp.kd_poli = pol.kd_poli
+			LEFT JOIN dokter d ON rp.kd_dokter = d.kd_dokter
+			WHERE %s
+			ORDER BY rp.tgl_registrasi DESC
+		`, exportColumns("rp.no_rawat", withMliteBilling), where)
+
+	default:
+		var where string
+		where, args = statusWhere(filter)
+		query = fmt.Sprintf(`
+			SELECT
+				mv.no_rawat,
+				mv.no_rkm_medis,
+				p.nm_pasien,
+				CASE WHEN mv.jenis = '1' THEN 'ranap' ELSE 'ralan' END as jenis,
+				DATE(mv.tgl_registrasi) as tgl_pelayanan,
+				CASE
+					WHEN mv.jenis = '1' THEN COALESCE(b.nm_bangsal, '')
+					ELSE COALESCE(pol.nm_poli, '')
+				END as unit,
+				COALESCE(d.nm_dokter, '') as dokter,
+				COALESCE(pj.png_jawab, '') as cara_bayar,
+				mv.status,
+				COALESCE(mv.nosep, '') as no_sep,
+				%s
+			FROM mlite_vedika mv
+			INNER JOIN pasien p ON mv.no_rkm_medis = p.no_rkm_medis
+			LEFT JOIN reg_periksa rp ON mv.no_rawat = rp.no_rawat
+			LEFT JOIN penjab pj ON rp.kd_pj = pj.kd_pj
+			LEFT JOIN poliklinik pol ON rp.kd_poli = pol.kd_poli
+			LEFT JOIN dokter d ON rp.kd_dokter = d.kd_dokter
+			LEFT JOIN kamar_inap ki ON mv.no_rawat = ki.no_rawat AND mv.jenis = '1'
+			LEFT JOIN kamar km ON ki.kd_kamar = km.kd_kamar
+			LEFT JOIN bangsal b ON km.kd_bangsal = b.kd_bangsal
+			WHERE %s
+			GROUP BY mv.no_rawat
+			ORDER BY mv.tanggal DESC
+		`, exportColumns("mv.no_rawat", withMliteBilling), where)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to export index: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row entity.IndexExportRow
+		var status, diagnosa, prosedur string
+		if err := rows.Scan(
+			&row.NoRawat,
+			&row.NoRkmMedis,
+			&row.NamaPasien,
+			&row.Jenis,
+			&row.TglPelayanan,
+			&row.Unit,
+			&row.Dokter,
+			&row.CaraBayar,
+			&status,
+			&row.NoSEP,
+			&row.NoKartu,
+			&diagnosa,
+			&prosedur,
+			&row.TotalBilling,
+		); err != nil {
+			return fmt.Errorf("failed to scan index export: %w", err)
+		}
+		row.Status = entity.ClaimStatus(status)
+
+		// Diagnoses are ordered by prioritas: the first one is the primary
+		if codes := splitCodes(diagnosa); len(codes) > 0 {
+			row.DiagnosaUtama = codes[0]
+			row.DiagnosaSekunder = codes[1:]
+		}
+		row.Prosedur = splitCodes(prosedur)
+
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// splitCodes splits a GROUP_CONCAT list of codes.
+func splitCodes(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ";")
+}
+
+// rencanaWhere builds the WHERE clause for episodes NOT in mlite_vedika.
+// RANAP filters on kamar_inap.tgl_keluar, RALAN on reg_periksa.tgl_registrasi.
+func rencanaWhere(filter entity.IndexFilter) (string, []interface{}) {
+	var where string
+	if filter.Jenis == entity.JenisRanap {
+		where = `
+			ki.tgl_keluar IS NOT NULL
+			AND ki.tgl_keluar BETWEEN ? AND ?
+			AND rp.status_lanjut = 'Ranap'
+			AND rp.stts != 'Batal'
+			AND rp.no_rawat NOT IN (SELECT no_rawat FROM mlite_vedika)
+		`
+	} else {
+		where = `
+			rp.tgl_registrasi BETWEEN ? AND ?
+			AND rp.status_lanjut = 'Ralan'
+			AND rp.stts != 'Batal'
+			AND rp.no_rawat NOT IN (SELECT no_rawat FROM mlite_vedika)
+		`
+	}
+	args := []interface{}{filter.DateFrom, filter.DateTo}
+
+	if filter.Search != "" {
+		where += " AND (p.nm_pasien LIKE ? OR rp.no_rawat LIKE ? OR rp.no_rkm_medis LIKE ?)"
+		searchPattern := "%" + filter.Search + "%"
+		args = append(args, searchPattern, searchPattern, searchPattern)
+	}
+
+	if filter.AssignedTo != "" {
+		where += " AND rp.no_rawat IN (SELECT no_rawat FROM mera_vedika_assignment WHERE user_id = ?)"
+		args = append(args, filter.AssignedTo)
+	}
+
+	return where, args
+}
+
+// assignedColumn selects the username of the coder assigned to the episode
+// identified by noRawatCol, or an empty string when unassigned.
+func assignedColumn(noRawatCol string) string {
+	return `COALESCE((
+		SELECT COALESCE(u.username, a.user_id) FROM mera_vedika_assignment a
+		LEFT JOIN mera_users u ON a.user_id = u.id
+		WHERE a.no_rawat = ` + noRawatCol + `
+	), '') as assigned_to`
+}
+
+// daysInStatusColumn selects the days the mlite_vedika episode (mv) has been
+// in its current status: since its last change to that status in the status
+// history, else since it was recorded in mlite_vedika.
+func daysInStatusColumn() string {
+	return `COALESCE(DATEDIFF(CURDATE(), COALESCE((
+		SELECT MAX(h.created_at) FROM mera_vedika_status_history h
+		WHERE h.no_rawat = mv.no_rawat AND UPPER(h.new_status) = UPPER(mv.status)
+	), mv.tanggal)), 0) as days_in_status`
+}
+
+// statusWhere builds the WHERE clause for episodes in mlite_vedika.
+func statusWhere(filter entity.IndexFilter) (string, []interface{}) {
+	where := `
+		UPPER(mv.status) = UPPER(?)
+		AND mv.tgl_registrasi BETWEEN ? AND ?
+	`
+	args := []interface{}{string(filter.Status), filter.DateFrom, filter.DateTo}
+
+	if filter.Jenis != "" {
+		where += " AND mv.jenis = ?"
+		args = append(args, filter.Jenis.ToDBValue())
+	}
+
+	if filter.Search != "" {
+		where += " AND (p.nm_pasien LIKE ? OR mv.no_rawat LIKE ? OR mv.no_rkm_medis LIKE ?)"
+		searchPattern := "%" + filter.Search + "%"
+		args = append(args, searchPattern, searchPattern, searchPattern)
+	}
+
+	if filter.AssignedTo != "" {
+		where += " AND mv.no_rawat IN (SELECT no_rawat FROM mera_vedika_assignment WHERE user_id = ?)"
+		args = append(args, filter.AssignedTo)
+	}
+
+	return where, args
+}
+
 // GetClaimDetail returns full claim context.
 func (r *MySQLIndexRepository) GetClaimDetail(ctx context.Context, noRawat string) (*entity.ClaimDetail, error) {
 	query := `
@@ -438,17 +721,18 @@ func (r *MySQLIndexRepository) GetEpisodeStatus(ctx context.Context, noRawat str
 }
 
 // UpdateClaimStatus updates or inserts claim status in mlite_vedika.
-func (r *MySQLIndexRepository) UpdateClaimStatus(ctx context.Context, noRawat string, status entity.ClaimStatus, username string, catatan string) error {
+// from is the status the caller checked the transition against; the write
+// fails with entity.ErrStatusChanged when the locked row holds another one.
+// The previous status is recorded in mera_vedika_status_history and a non-empty
+// catatan is added to the SEP's feedback, both within the same transaction.
+// The note opens a thread only when the claim moves to Perbaikan.
+func (r *MySQLIndexRepository) UpdateClaimStatus(ctx context.Context, noRawat string, from, status entity.ClaimStatus, username string, catatan string) error {
 	// 1. Resolve real username if 'username' is actually a UserID (passed by getActor)
-	var realUsername string
-	err := r.db.QueryRowContext(ctx, "SELECT username FROM mera_users WHERE id = ? OR username = ?", username, username).Scan(&realUsername)
-	if err == nil {
-		username = realUsername
-	}
+	username = resolveUsername(ctx, r.db, username)
 
 	// 2. Fetch episode metadata from reg_periksa
 	var noRkmMedis, tglRegistrasiRaw, jenis string
-	err = r.db.QueryRowContext(ctx, `
+	err := r.db.QueryRowContext(ctx, `
 		SELECT no_rkm_medis, tgl_registrasi, 
 		CASE WHEN status_lanjut = 'Ranap' THEN '1' ELSE '2' END
 		FROM reg_periksa WHERE no_rawat = ?
@@ -466,9 +750,34 @@ func (r *MySQLIndexRepository) UpdateClaimStatus(ctx context.Context, noRawat st
 	// Get SEP if exists
 	var noSEP string
 	r.db.QueryRowContext(ctx, `SELECT COALESCE(no_sep, '') FROM bridging_sep WHERE no_rawat = ?`, noRawat).Scan(&noSEP)
+	// Feedback threads hang off the SEP, so a note would be lost without one
+	if strings.TrimSpace(catatan) != "" && noSEP == "" {
+		return fmt.Errorf("%w: catatan requires a SEP", ErrSEPNotFound)
+	}
 
-	// Upsert into mlite_vedika
-	_, err = r.db.ExecContext(ctx, `
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 3. Lock the current row (if any) and capture the previous status
+	var oldStatus string
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(status, '') FROM mlite_vedika WHERE no_rawat = ? FOR UPDATE
+	`, noRawat).Scan(&oldStatus)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get current status: %w", err)
+	}
+	if oldStatus == "" {
+		oldStatus = string(entity.StatusRencana)
+	}
+	if current := entity.ClaimStatus(oldStatus).Normalize(); current != from.Normalize() {
+		return fmt.Errorf("%w: %s is now %s, not %s", entity.ErrStatusChanged, noRawat, current, from.Normalize())
+	}
+
+	// 4. Upsert into mlite_vedika
+	_, err = tx.ExecContext(ctx, `
 		INSERT INTO mlite_vedika (tanggal, no_rkm_medis, no_rawat, tgl_registrasi, nosep, jenis, status, username)
 		VALUES (CURDATE(), ?, ?, ?, ?, ?, ?, ?)
 		ON DUPLICATE KEY UPDATE status = ?, username = ?
@@ -477,21 +786,78 @@ func (r *MySQLIndexRepository) UpdateClaimStatus(ctx context.Context, noRawat st
 		return fmt.Errorf("failed to update status: %w", err)
 	}
 
-	// Add feedback if catatan provided
+	// 5. Append to status history
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO mera_vedika_status_history (id, no_rawat, nosep, old_status, new_status, username, catatan, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, NOW())
+	`, uuid.New().String(), noRawat, noSEP, oldStatus, string(status), username, strings.TrimSpace(catatan))
+	if err != nil {
+		return fmt.Errorf("failed to record status history: %w", err)
+	}
+
+	// 6. Catatan is kept on the SEP's feedback. Only a return to Perbaikan
+	// opens a thread that must be resolved; other notes are stored resolved
+	// so they never block the next transition.
 	if strings.TrimSpace(catatan) != "" {
-		_, err = r.db.ExecContext(ctx, `
-			INSERT INTO mlite_vedika_feedback (nosep, tanggal, catatan, username)
-			VALUES (?, CURDATE(), ?, ?)
-		`, noSEP, catatan, username)
+		if status.Normalize() == entity.StatusPerbaikan {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO mlite_vedika_feedback (nosep, parent_id, tanggal, catatan, username, is_resolved, created_at)
+				VALUES (?, NULL, CURDATE(), ?, ?, 0, NOW())
+			`, noSEP, catatan, username)
+		} else {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO mlite_vedika_feedback (nosep, parent_id, tanggal, catatan, username, is_resolved, resolved_by, resolved_at, created_at)
+				VALUES (?, NULL, CURDATE(), ?, ?, 1, ?, NOW(), NOW())
+			`, noSEP, catatan, username, username)
+		}
 		if err != nil {
-			// Non-fatal, just log
-			fmt.Printf("failed to add feedback: %v\n", err)
+			return fmt.Errorf("failed to add feedback: %w", err)
 		}
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit status update: %w", err)
+	}
+
 	return nil
 }
 
+// GetStatusHistory returns the status timeline of a claim, oldest first.
+func (r *MySQLIndexRepository) GetStatusHistory(ctx context.Context, noRawat string) ([]entity.StatusHistoryEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, no_rawat, nosep, old_status, new_status, username, COALESCE(catatan, ''), created_at
+		FROM mera_vedika_status_history
+		WHERE no_rawat = ?
+		ORDER BY seq
+	`, noRawat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []entity.StatusHistoryEntry
+	for rows.Next() {
+		var h entity.StatusHistoryEntry
+		var oldStatus, newStatus string
+		if err := rows.Scan(&h.ID, &h.NoRawat, &h.NoSEP, &oldStatus, &newStatus, &h.Username, &h.Catatan, &h.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan status history: %w", err)
+		}
+		h.OldStatus = entity.ClaimStatus(oldStatus).Normalize()
+		h.NewStatus = entity.ClaimStatus(newStatus).Normalize()
+		history = append(history, h)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating status history: %w", err)
+	}
+
+	if history == nil {
+		history = []entity.StatusHistoryEntry{}
+	}
+
+	return history, nil
+}
+
 // GetEpisodeType returns the episode type (Ralan or Ranap) from reg_periksa.
 func (r *MySQLIndexRepository) GetEpisodeType(ctx context.Context, noRawat string) (string, error) {
 	var statusLanjut string
@@ -502,6 +868,25 @@ func (r *MySQLIndexRepository) GetEpisodeType(ctx context.Context, noRawat strin
 	return statusLanjut, nil
 }
 
+// GetCodingPatient returns the sex, birth date and registration date of the
+// episode's patient.
+func (r *MySQLIndexRepository) GetCodingPatient(ctx context.Context, noRawat string) (*entity.CodingPatient, error) {
+	var p entity.CodingPatient
+	var tglLahir, tglRegistrasi sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+		SELECT p.jk, p.tgl_lahir, rp.tgl_registrasi, rp.status_lanjut
+		FROM reg_periksa rp
+		INNER JOIN pasien p ON rp.no_rkm_medis = p.no_rkm_medis
+		WHERE rp.no_rawat = ?
+	`, noRawat).Scan(&p.JenisKelamin, &tglLahir, &tglRegistrasi, &p.StatusLanjut)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get coding patient: %w", err)
+	}
+	p.TglLahir = tglLahir.Time
+	p.TglRegistrasi = tglRegistrasi.Time
+	return &p, nil
+}
+
 // GetDiagnoses returns diagnoses for an episode.
 func (r *MySQLIndexRepository) GetDiagnoses(ctx context.Context, noRawat string) ([]entity.DiagnosisItem, error) {
 	noRawat = strings.TrimPrefix(noRawat, "/")
@@ -608,14 +993,10 @@ func (r *MySQLIndexRepository) SyncDiagnoses(ctx context.Context, noRawat string
 
 	// 2. Insert new ones
 	for _, d := range diagnoses {
-		statusDx := d.StatusDx
-		if statusDx == "" {
-			statusDx = "Sekunder"
-		}
-
 		// Map status to Ralan/Ranap based on episode
 		// SIMRS Legacy uses 'status' column for Ralan/Ranap
-		// and 'prioritas' column (1 for Utama, >1 for Sekunder)
+		// and 'prioritas' column (1 for Utama, >1 for Sekunder; the service
+		// derives it from status_dx before calling)
 		// and 'status_penyakit' column (usually 'Lama')
 
 		_, err = tx.ExecContext(ctx, `
@@ -766,6 +1147,34 @@ func (r *MySQLIndexRepository) GetDocuments(ctx context.Context, noRawat string)
 	return docs, nil
 }
 
+// LockMedicalData holds the claim's mera_vedika_medical_edit row with
+// SELECT ... FOR UPDATE until unlock is called, blocking other edits of the
+// claim on every server instance. The edit's own writes use other
+// connections, so they must not touch that row.
+func (r *MySQLIndexRepository) LockMedicalData(ctx context.Context, noRawat string) (func(), error) {
+	// Create the row outside the locking transaction; INSERT IGNORE on an
+	// existing row would take a shared lock that FOR UPDATE then upgrades,
+	// which deadlocks two concurrent editors
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT IGNORE INTO mera_vedika_medical_edit (no_rawat) VALUES (?)
+	`, noRawat); err != nil {
+		return nil, fmt.Errorf("failed to create medical edit guard: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	var locked string
+	if err := tx.QueryRowContext(ctx, `
+		SELECT no_rawat FROM mera_vedika_medical_edit WHERE no_rawat = ? FOR UPDATE
+	`, noRawat).Scan(&locked); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to lock medical data: %w", err)
+	}
+	return func() { tx.Rollback() }, nil
+}
+
 // GetResume returns medical resume for an episode.
 func (r *MySQLIndexRepository) GetResume(ctx context.Context, noRawat string) (*entity.MedicalResume, error) {
 	// First check if Ranap or Ralan
@@ -934,12 +1343,28 @@ func (r *MySQLIndexRepository) AddDigitalDocument(ctx context.Context, noRawat s
 
 // DeleteDigitalDocument deletes a record from berkas_digital_perawatan.
 func (r *MySQLIndexRepository) DeleteDigitalDocument(ctx context.Context, noRawat string, kode string, lokasiFile string) error {
-	_, err := r.db.ExecContext(ctx, `
+	res, err := r.db.ExecContext(ctx, `
 		DELETE FROM berkas_digital_perawatan 
 		WHERE no_rawat = ? AND kode = ? AND lokasi_file = ?
 	`, noRawat, kode, lokasiFile)
 	if err != nil {
 		return fmt.Errorf("failed to delete digital document: %w", err)
 	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return ErrDocumentNotFound
+	}
 	return nil
 }
+
+// HasDigitalDocument reports whether lokasiFile is recorded for noRawat.
+func (r *MySQLIndexRepository) HasDigitalDocument(ctx context.Context, noRawat string, lokasiFile string) (bool, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM berkas_digital_perawatan
+		WHERE no_rawat = ? AND lokasi_file = ?
+	`, noRawat, lokasiFile).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check digital document: %w", err)
+	}
+	return count > 0, nil
+}
@@ -21,6 +21,12 @@ type SettingsRepository interface {
 	GetActivePeriod(ctx context.Context) (string, error)
 	GetAllowedCarabayar(ctx context.Context) ([]string, error)
 	GetLegacyWebAppURL(ctx context.Context) (string, error)
+	GetInstitution(ctx context.Context) (*entity.Institution, error)
+	GetCompletenessConfig(ctx context.Context) (*entity.CompletenessConfig, error)
+	GetCodingConfig(ctx context.Context) (*entity.CodingConfig, error)
+	GetTarifMapping(ctx context.Context) (*entity.TarifMapping, error)
+	GetAssignmentConfig(ctx context.Context) (*entity.AssignmentConfig, error)
+	GetSLAConfig(ctx context.Context) (*entity.SLAConfig, error)
 }
 
 // MySQLSettingsRepository implements SettingsRepository using MySQL.
@@ -114,3 +120,136 @@ func (r *MySQLSettingsRepository) GetLegacyWebAppURL(ctx context.Context) (strin
 
 	return url, nil
 }
+
+// GetInstitution returns the hospital identity printed on claim documents.
+func (r *MySQLSettingsRepository) GetInstitution(ctx context.Context) (*entity.Institution, error) {
+	setting, err := r.getSetting(ctx, "institution")
+	if err != nil {
+		return nil, err
+	}
+
+	var inst entity.Institution
+	if err := json.Unmarshal([]byte(setting.SettingValue), &inst); err != nil {
+		return nil, fmt.Errorf("invalid institution format: %w", err)
+	}
+
+	return &inst, nil
+}
+
+// GetCompletenessConfig returns the required documents and advisory checks
+// of the completeness checker.
+func (r *MySQLSettingsRepository) GetCompletenessConfig(ctx context.Context) (*entity.CompletenessConfig, error) {
+	setting, err := r.getSetting(ctx, "completeness")
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg entity.CompletenessConfig
+	if err := json.Unmarshal([]byte(setting.SettingValue), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid completeness format: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+// GetCodingConfig returns the BPJS-specific rules of the coding validator.
+func (r *MySQLSettingsRepository) GetCodingConfig(ctx context.Context) (*entity.CodingConfig, error) {
+	setting, err := r.getSetting(ctx, "coding")
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg entity.CodingConfig
+	if err := json.Unmarshal([]byte(setting.SettingValue), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid coding format: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+// GetTarifMapping returns the classification of billing lines into INA-CBG
+// cost components. Every mapped component must be one of entity.TarifComponents.
+func (r *MySQLSettingsRepository) GetTarifMapping(ctx context.Context) (*entity.TarifMapping, error) {
+	setting, err := r.getSetting(ctx, "tarif_mapping")
+	if err != nil {
+		return nil, err
+	}
+
+	var m entity.TarifMapping
+	if err := json.Unmarshal([]byte(setting.SettingValue), &m); err != nil {
+		return nil, fmt.Errorf("invalid tarif_mapping format: %w", err)
+	}
+
+	check := func(field, key, kode string) error {
+		if !entity.IsTarifComponent(kode) {
+			return fmt.Errorf("invalid tarif_mapping: %s %q maps to unknown component %q", field, key, kode)
+		}
+		return nil
+	}
+	for key, kode := range m.JenisPrw {
+		if err := check("kd_jenis_prw", key, kode); err != nil {
+			return nil, err
+		}
+	}
+	for key, kode := range m.Pemisah {
+		if err := check("pemisah", key, kode); err != nil {
+			return nil, err
+		}
+	}
+	if m.Default != "" {
+		if err := check("default", "", m.Default); err != nil {
+			return nil, err
+		}
+	}
+
+	return &m, nil
+}
+
+// GetAssignmentConfig returns the automatic distribution configuration.
+func (r *MySQLSettingsRepository) GetAssignmentConfig(ctx context.Context) (*entity.AssignmentConfig, error) {
+	setting, err := r.getSetting(ctx, "assignment")
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg entity.AssignmentConfig
+	if err := json.Unmarshal([]byte(setting.SettingValue), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid assignment format: %w", err)
+	}
+
+	switch cfg.GroupBy {
+	case "":
+		cfg.GroupBy = entity.AssignByJenis
+	case entity.AssignByJenis, entity.AssignByUnit:
+	default:
+		return nil, fmt.Errorf("invalid assignment: unknown group_by %q", cfg.GroupBy)
+	}
+	if cfg.LookbackDays <= 0 {
+		cfg.LookbackDays = 7
+	}
+	if cfg.IntervalMinutes <= 0 {
+		cfg.IntervalMinutes = 15
+	}
+
+	return &cfg, nil
+}
+
+// GetSLAConfig returns the claim SLA thresholds per jenis.
+func (r *MySQLSettingsRepository) GetSLAConfig(ctx context.Context) (*entity.SLAConfig, error) {
+	setting, err := r.getSetting(ctx, "sla")
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg entity.SLAConfig
+	if err := json.Unmarshal([]byte(setting.SettingValue), &cfg); err != nil {
+		return nil, fmt.Errorf("invalid sla format: %w", err)
+	}
+	for _, t := range []entity.SLAThreshold{cfg.Ralan, cfg.Ranap} {
+		if t.SubmitDays < 0 || t.StatusDays < 0 {
+			return nil, fmt.Errorf("invalid sla: thresholds must not be negative")
+		}
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+)
+
+var (
+	// ErrVerifierNotFound indicates no verifier with the given username or id.
+	ErrVerifierNotFound = errors.New("verifier not found")
+	// ErrClaimNotRecorded indicates the episode is not in mlite_vedika.
+	ErrClaimNotRecorded = errors.New("claim not recorded in mlite_vedika")
+)
+
+// VerifierRepository handles the BPJS verifier realm: verifier accounts in
+// mlite_users_vedika and the claim lookups the verifier portal is scoped to.
+type VerifierRepository interface {
+	// Get a verifier by username
+	GetByUsername(ctx context.Context, username string) (*entity.Verifier, error)
+	// Get a verifier by id
+	GetByID(ctx context.Context, id int64) (*entity.Verifier, error)
+	// Get the status and registration date (YYYY-MM-DD) of a recorded claim
+	GetClaimScope(ctx context.Context, noRawat string) (entity.ClaimStatus, string, error)
+	// Count recorded claims per status and jenis registered between two dates
+	CountByStatus(ctx context.Context, dateFrom, dateTo string, statuses []entity.ClaimStatus) ([]entity.VerifierStatusCount, error)
+
+	// Count a failed login, restarting the count after resetBefore; returns the new count
+	RecordFailedLogin(ctx context.Context, verifierID int64, resetBefore time.Time) (int, error)
+	// Lock a verifier's login until the given time
+	LockUntil(ctx context.Context, verifierID int64, until time.Time) error
+	// Clear the failed-login count and lockout
+	ResetFailedLogins(ctx context.Context, verifierID int64) error
+
+	// Create a verifier session
+	CreateSession(ctx context.Context, session *entity.VerifierSession) error
+	// Get a verifier session by id; nil when not found
+	GetSession(ctx context.Context, id string) (*entity.VerifierSession, error)
+	// Revoke a verifier session
+	RevokeSession(ctx context.Context, id string) error
+}
+
+// MySQLVerifierRepository implements VerifierRepository.
+type MySQLVerifierRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLVerifierRepository creates a new verifier repository.
+func NewMySQLVerifierRepository(db *sql.DB) *MySQLVerifierRepository {
+	return &MySQLVerifierRepository{db: db}
+}
+
+// verifierColumns selects a verifier with its lockout state.
+const verifierColumns = `
+	SELECT v.id, v.username, v.fullname, v.password, l.locked_until
+	FROM mlite_users_vedika v
+	LEFT JOIN mera_vedika_verifier_lockouts l ON l.verifier_id = v.id
+`
+
+// GetByUsername returns a verifier by username.
+func (r *MySQLVerifierRepository) GetByUsername(ctx context.Context, username string) (*entity.Verifier, error) {
+	return r.getVerifier(ctx, verifierColumns+"WHERE v.username = ?", username)
+}
+
+// GetByID returns a verifier by id.
+func (r *MySQLVerifierRepository) GetByID(ctx context.Context, id int64) (*entity.Verifier, error) {
+	return r.getVerifier(ctx, verifierColumns+"WHERE v.id = ?", id)
+}
+
+func (r *MySQLVerifierRepository) getVerifier(ctx context.Context, query string, arg interface{}) (*entity.Verifier, error) {
+	var v entity.Verifier
+	var lockedUntil sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, arg).Scan(&v.ID, &v.Username, &v.Fullname, &v.PasswordHash, &lockedUntil)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %v", ErrVerifierNotFound, arg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verifier: %w", err)
+	}
+	if lockedUntil.Valid {
+		v.LockedUntil = &lockedUntil.Time
+	}
+	return &v, nil
+}
+
+// GetClaimScope returns the status and registration date of a claim recorded
+// in mlite_vedika.
+func (r *MySQLVerifierRepository) GetClaimScope(ctx context.Context, noRawat string) (entity.ClaimStatus, string, error) {
+	var status, tglRegistrasi string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COALESCE(status, ''), DATE_FORMAT(tgl_registrasi, '%Y-%m-%d')
+		FROM mlite_vedika WHERE no_rawat = ?
+	`, noRawat).Scan(&status, &tglRegistrasi)
+	if err == sql.ErrNoRows {
+		return "", "", fmt.Errorf("%w: %s", ErrClaimNotRecorded, noRawat)
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get claim scope: %w", err)
+	}
+	return entity.ClaimStatus(status), tglRegistrasi, nil
+}
+
+// CountByStatus counts claims registered between dateFrom and dateTo per
+// status, split into ralan and ranap. Every requested status is returned,
+// in the given order.
+func (r *MySQLVerifierRepository) CountByStatus(ctx context.Context, dateFrom, dateTo string, statuses []entity.ClaimStatus) ([]entity.VerifierStatusCount, error) {
+	counts := make([]entity.VerifierStatusCount, len(statuses))
+	index := make(map[entity.ClaimStatus]int, len(statuses))
+	placeholders := make([]string, len(statuses))
+	args := []interface{}{dateFrom, dateTo}
+	for i, s := range statuses {
+		counts[i].Status = s
+		index[s] = i
+		placeholders[i] = "?"
+		args = append(args, strings.ToUpper(string(s)))
+	}
+	if len(statuses) == 0 {
+		return counts, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT UPPER(status), jenis, COUNT(*)
+		FROM mlite_vedika
+		WHERE tgl_registrasi BETWEEN ? AND ?
+		  AND UPPER(status) IN (%s)
+		GROUP BY UPPER(status), jenis
+	`, strings.Join(placeholders, ", "))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count claims by status: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status, jenis string
+		var n int64
+		if err := rows.Scan(&status, &jenis, &n); err != nil {
+			return nil, fmt.Errorf("failed to scan claim count: %w", err)
+		}
+		pos, ok := index[entity.ClaimStatus(status).Normalize()]
+		if !ok {
+			continue
+		}
+		if jenis == entity.JenisRanap.ToDBValue() {
+			counts[pos].Ranap += n
+		} else {
+			counts[pos].Ralan += n
+		}
+		counts[pos].Total += n
+	}
+	return counts, rows.Err()
+}
+
+// RecordFailedLogin counts a failed login of the verifier. The count
+// restarts at 1 when the previous failure is older than resetBefore.
+func (r *MySQLVerifierRepository) RecordFailedLogin(ctx context.Context, verifierID int64, resetBefore time.Time) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO mera_vedika_verifier_lockouts (verifier_id, failed_login_count, last_failed_login_at)
+		VALUES (?, 1, ?)
+		ON DUPLICATE KEY UPDATE
+			failed_login_count = IF(last_failed_login_at IS NULL OR last_failed_login_at < ?, 1, failed_login_count + 1),
+			last_failed_login_at = VALUES(last_failed_login_at)
+	`, verifierID, time.Now(), resetBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record failed login: %w", err)
+	}
+
+	var count int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT failed_login_count FROM mera_vedika_verifier_lockouts WHERE verifier_id = ?
+	`, verifierID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to read failed login count: %w", err)
+	}
+	return count, tx.Commit()
+}
+
+// LockUntil locks the verifier's login until the given time.
+func (r *MySQLVerifierRepository) LockUntil(ctx context.Context, verifierID int64, until time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE mera_vedika_verifier_lockouts SET locked_until = ? WHERE verifier_id = ?
+	`, until, verifierID)
+	if err != nil {
+		return fmt.Errorf("failed to lock verifier: %w", err)
+	}
+	return nil
+}
+
+// ResetFailedLogins clears the verifier's failed-login count and lockout.
+func (r *MySQLVerifierRepository) ResetFailedLogins(ctx context.Context, verifierID int64) error {
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM mera_vedika_verifier_lockouts WHERE verifier_id = ?
+	`, verifierID)
+	if err != nil {
+		return fmt.Errorf("failed to reset failed logins: %w", err)
+	}
+	return nil
+}
+
+// CreateSession stores a new verifier session, assigning its id when empty.
+func (r *MySQLVerifierRepository) CreateSession(ctx context.Context, session *entity.VerifierSession) error {
+	if session.ID == "" {
+		session.ID = uuid.New().String()
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO mera_vedika_verifier_sessions (id, verifier_id, device_info, ip_address, created_at, expires_at)
+		VALUES (?, ?, ?, ?, NOW(), ?)
+	`, session.ID, session.VerifierID, session.DeviceInfo, session.IPAddress, session.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create verifier session: %w", err)
+	}
+	return nil
+}
+
+// GetSession returns a verifier session by id, or nil when none exists.
+func (r *MySQLVerifierRepository) GetSession(ctx context.Context, id string) (*entity.VerifierSession, error) {
+	var s entity.VerifierSession
+	var deviceInfo, ipAddress sql.NullString
+	var revokedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, verifier_id, device_info, ip_address, created_at, expires_at, revoked_at
+		FROM mera_vedika_verifier_sessions WHERE id = ?
+	`, id).Scan(&s.ID, &s.VerifierID, &deviceInfo, &ipAddress, &s.CreatedAt, &s.ExpiresAt, &revokedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verifier session: %w", err)
+	}
+	s.DeviceInfo = deviceInfo.String
+	s.IPAddress = ipAddress.String
+	if revokedAt.Valid {
+		s.RevokedAt = &revokedAt.Time
+	}
+	return &s, nil
+}
+
+// RevokeSession revokes a verifier session. Revoking twice keeps the first
+// revocation time.
+func (r *MySQLVerifierRepository) RevokeSession(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE mera_vedika_verifier_sessions SET revoked_at = NOW()
+		WHERE id = ? AND revoked_at IS NULL
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke verifier session: %w", err)
+	}
+	return nil
+}
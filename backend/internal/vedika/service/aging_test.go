@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/internal/vedika/repository"
+	"github.com/clinova/simrs/backend/pkg/audit"
+)
+
+// fakeSettingsRepo serves fixed vedika settings.
+type fakeSettingsRepo struct {
+	repository.SettingsRepository
+	activePeriod string
+	carabayar    []string
+	sla          *entity.SLAConfig
+}
+
+func (r *fakeSettingsRepo) GetActivePeriod(ctx context.Context) (string, error) {
+	return r.activePeriod, nil
+}
+
+func (r *fakeSettingsRepo) GetAllowedCarabayar(ctx context.Context) ([]string, error) {
+	return r.carabayar, nil
+}
+
+func (r *fakeSettingsRepo) GetSLAConfig(ctx context.Context) (*entity.SLAConfig, error) {
+	if r.sla == nil {
+		return nil, repository.ErrSettingNotFound
+	}
+	return r.sla, nil
+}
+
+// fakeDashboardRepo returns fixed aging counts and keeps the last filter.
+type fakeDashboardRepo struct {
+	repository.DashboardRepository
+	aging       []entity.AgingCount
+	agingFilter entity.AgingFilter
+}
+
+func (r *fakeDashboardRepo) CountAging(ctx context.Context, filter entity.AgingFilter) ([]entity.AgingCount, error) {
+	r.agingFilter = filter
+	return r.aging, nil
+}
+
+func TestGetAgingReport(t *testing.T) {
+	settings := &fakeSettingsRepo{
+		activePeriod: "2026-01",
+		carabayar:    []string{"BPJ", "A09"},
+		sla: &entity.SLAConfig{
+			Ralan: entity.SLAThreshold{SubmitDays: 7, StatusDays: 7},
+			Ranap: entity.SLAThreshold{SubmitDays: 30, StatusDays: 14},
+		},
+	}
+	dashboard := &fakeDashboardRepo{aging: []entity.AgingCount{
+		{Jenis: entity.JenisRalan, Status: entity.StatusRencana, AgeDays: 10, Count: 4},          // Overdue
+		{Jenis: entity.JenisRanap, Status: entity.StatusRencana, AgeDays: 10, Count: 2},          // Within SLA
+		{Jenis: entity.JenisRanap, Status: "pengajuan", AgeDays: 20, DaysInStatus: 15, Count: 1}, // Legacy name, overdue
+		{Jenis: entity.JenisRalan, Status: entity.StatusSetuju, AgeDays: 45, DaysInStatus: 40, Count: 5},
+		{Jenis: entity.JenisRalan, Status: "Batal", AgeDays: 1, Count: 9}, // Unknown status, skipped
+	}}
+	s := NewDashboardService(settings, dashboard, newTestAuditLogger(t))
+
+	report, err := s.GetAgingReport(context.Background(), entity.AgingFilter{Carabayar: []string{"BPJ", "UMU"}}, audit.Actor{Username: "admin"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Period != "2026-01" || report.DateFrom != "2026-01-01" || report.DateTo != "2026-01-31" {
+		t.Fatalf("range %+v, want the active period", report.DashboardRange)
+	}
+	if !reflect.DeepEqual(dashboard.agingFilter.Carabayar, []string{"BPJ"}) {
+		t.Fatalf("carabayar %v, want only the allowed BPJ", dashboard.agingFilter.Carabayar)
+	}
+
+	rows := map[entity.ClaimStatus]entity.AgingRow{}
+	for _, row := range report.Statuses {
+		rows[row.Status] = row
+	}
+	if len(report.Statuses) != 5 {
+		t.Fatalf("got %d status rows, want 5", len(report.Statuses))
+	}
+
+	rencana := rows[entity.StatusRencana]
+	if rencana.Total != (entity.ClaimCount{Ralan: 4, Ranap: 2}) || rencana.Overdue != (entity.ClaimCount{Ralan: 4}) {
+		t.Errorf("rencana total %+v overdue %+v", rencana.Total, rencana.Overdue)
+	}
+	if rencana.Buckets[1].Bucket != entity.AgingBucket8to14 || rencana.Buckets[1].ClaimCount != (entity.ClaimCount{Ralan: 4, Ranap: 2}) {
+		t.Errorf("rencana 8-14 bucket %+v", rencana.Buckets[1])
+	}
+	if got := rows[entity.StatusPengajuan].Overdue; got != (entity.ClaimCount{Ranap: 1}) {
+		t.Errorf("pengajuan overdue %+v, want 1 ranap", got)
+	}
+	if got := rows[entity.StatusSetuju].Overdue; got != (entity.ClaimCount{}) {
+		t.Errorf("setuju overdue %+v, want none", got)
+	}
+	if report.Total.Total != (entity.ClaimCount{Ralan: 9, Ranap: 3}) || report.Total.Overdue != (entity.ClaimCount{Ralan: 4, Ranap: 1}) {
+		t.Errorf("total %+v overdue %+v", report.Total.Total, report.Total.Overdue)
+	}
+}
+
+func TestGetAgingReportDefaultSLA(t *testing.T) {
+	settings := &fakeSettingsRepo{carabayar: []string{"BPJ"}}
+	s := NewDashboardService(settings, &fakeDashboardRepo{}, newTestAuditLogger(t))
+
+	rng := entity.DashboardRange{DateFrom: "2026-01-05", DateTo: "2026-01-20"}
+	report, err := s.GetAgingReport(context.Background(), entity.AgingFilter{Range: rng}, audit.Actor{Username: "admin"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.SLA != entity.DefaultSLAConfig {
+		t.Fatalf("SLA %+v, want the default", report.SLA)
+	}
+	if report.DashboardRange != rng {
+		t.Fatalf("range %+v, want the requested %+v", report.DashboardRange, rng)
+	}
+}
@@ -0,0 +1,422 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/internal/vedika/repository"
+	"github.com/clinova/simrs/backend/pkg/audit"
+)
+
+var (
+	// ErrQueueEmpty indicates claim-next found no unassigned Rencana episode.
+	ErrQueueEmpty = errors.New("no unassigned claim in queue")
+	// ErrAssignmentForbidden indicates an attempt to release another coder's
+	// claim without vedika.claim.assign.
+	ErrAssignmentForbidden = errors.New("claim is assigned to another coder")
+)
+
+// distributeBatch caps the episodes of each jenis one distribution run picks up.
+const distributeBatch = 500
+
+// claimNextBatch is how many queue heads claim-next tries before giving up
+// on concurrent coders taking them first.
+const claimNextBatch = 10
+
+// AssignmentService manages coder assignments and work queues.
+type AssignmentService struct {
+	assignmentRepo repository.AssignmentRepository
+	settingsRepo   repository.SettingsRepository
+	auditLogger    *audit.Logger
+
+	// Serialises distribution runs so two runs never balance against the
+	// same stale last-assigned times.
+	distributeMu sync.Mutex
+}
+
+// NewAssignmentService creates a new assignment service.
+func NewAssignmentService(
+	assignmentRepo repository.AssignmentRepository,
+	settingsRepo repository.SettingsRepository,
+	auditLogger *audit.Logger,
+) *AssignmentService {
+	return &AssignmentService{
+		assignmentRepo: assignmentRepo,
+		settingsRepo:   settingsRepo,
+		auditLogger:    auditLogger,
+	}
+}
+
+// Assign assigns an episode to a coder, replacing any existing assignment.
+func (s *AssignmentService) Assign(ctx context.Context, noRawat string, req entity.AssignRequest, actor audit.Actor, ip string) (*entity.ClaimAssignment, error) {
+	username, err := s.assignmentRepo.GetActiveUsername(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	candidate, err := s.assignmentRepo.GetCandidate(ctx, noRawat)
+	if err != nil {
+		return nil, err
+	}
+
+	var previous string
+	if old, err := s.assignmentRepo.Get(ctx, noRawat); err == nil {
+		previous = old.Username
+	} else if !errors.Is(err, repository.ErrAssignmentNotFound) {
+		return nil, err
+	}
+
+	a := &entity.ClaimAssignment{
+		AssignmentCandidate: *candidate,
+		UserID:              req.UserID,
+		Username:            username,
+		AssignedBy:          actor.Username,
+		AssignedAt:          time.Now(),
+	}
+	if err := s.assignmentRepo.Assign(ctx, a); err != nil {
+		return nil, err
+	}
+
+	summary := fmt.Sprintf("Menugaskan klaim %s kepada %s", noRawat, username)
+	if previous != "" && previous != username {
+		summary = fmt.Sprintf("Memindahkan klaim %s dari %s kepada %s", noRawat, previous, username)
+	}
+
+	// Audit log - INSERT
+	s.auditLogger.LogInsert(audit.InsertParams{
+		Module: "vedika",
+		Entity: audit.Entity{
+			Table:      "mera_vedika_assignment",
+			PrimaryKey: map[string]string{"no_rawat": noRawat},
+		},
+		InsertedData: map[string]interface{}{
+			"action":   "assign_claim",
+			"user_id":  a.UserID,
+			"username": username,
+			"previous": previous,
+		},
+		BusinessKey: noRawat,
+		Actor:       actor,
+		IP:          ip,
+		Summary:     summary,
+	})
+
+	return a, nil
+}
+
+// Unassign releases an episode. Without vedika.claim.assign a coder may
+// only release claims assigned to themselves.
+// granted holds the actor's effective permissions.
+func (s *AssignmentService) Unassign(ctx context.Context, noRawat string, granted map[string]bool, actor audit.Actor, ip string) error {
+	a, err := s.assignmentRepo.Get(ctx, noRawat)
+	if err != nil {
+		return err
+	}
+	if !granted[entity.PermAssign] && a.UserID != actor.UserID {
+		return fmt.Errorf("%w: %s", ErrAssignmentForbidden, noRawat)
+	}
+
+	if err := s.assignmentRepo.Unassign(ctx, noRawat); err != nil {
+		return err
+	}
+
+	// Audit log - DELETE
+	s.auditLogger.LogDelete(audit.DeleteParams{
+		Module: "vedika",
+		Entity: audit.Entity{
+			Table:      "mera_vedika_assignment",
+			PrimaryKey: map[string]string{"no_rawat": noRawat},
+		},
+		DeletedData: map[string]interface{}{
+			"user_id":     a.UserID,
+			"username":    a.Username,
+			"assigned_by": a.AssignedBy,
+		},
+		BusinessKey: noRawat,
+		Actor:       actor,
+		IP:          ip,
+		Summary:     fmt.Sprintf("Melepas penugasan klaim %s dari %s", noRawat, a.Username),
+	})
+
+	return nil
+}
+
+// ClaimNext assigns the oldest unassigned Rencana episode to the caller.
+// Dates default to the active period; an empty jenis takes from both queues.
+func (s *AssignmentService) ClaimNext(ctx context.Context, req entity.ClaimNextRequest, actor audit.Actor, ip string) (*entity.ClaimAssignment, error) {
+	username, err := s.assignmentRepo.GetActiveUsername(ctx, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DateFrom == "" || req.DateTo == "" {
+		period, err := s.settingsRepo.GetActivePeriod(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get active period: %w", err)
+		}
+		dateFrom, dateTo, err := periodBounds(period)
+		if err != nil {
+			return nil, err
+		}
+		if req.DateFrom == "" {
+			req.DateFrom = dateFrom
+		}
+		if req.DateTo == "" {
+			req.DateTo = dateTo
+		}
+	}
+
+	carabayar, err := s.settingsRepo.GetAllowedCarabayar(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allowed carabayar: %w", err)
+	}
+
+	jenisList := []entity.JenisPelayanan{entity.JenisRalan, entity.JenisRanap}
+	if req.Jenis != "" {
+		jenisList = []entity.JenisPelayanan{req.Jenis}
+	}
+	candidates, err := s.listUnassigned(ctx, jenisList, req.DateFrom, req.DateTo, carabayar, claimNextBatch)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, candidate := range candidates {
+		a := &entity.ClaimAssignment{
+			AssignmentCandidate: candidate,
+			UserID:              actor.UserID,
+			Username:            username,
+			AssignedBy:          actor.Username,
+			AssignedAt:          time.Now(),
+		}
+		ok, err := s.assignmentRepo.AssignIfUnassigned(ctx, a)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			// Another coder took it first
+			continue
+		}
+
+		// Audit log - INSERT
+		s.auditLogger.LogInsert(audit.InsertParams{
+			Module: "vedika",
+			Entity: audit.Entity{
+				Table:      "mera_vedika_assignment",
+				PrimaryKey: map[string]string{"no_rawat": a.NoRawat},
+			},
+			InsertedData: map[string]interface{}{
+				"action":   "claim_next",
+				"user_id":  a.UserID,
+				"username": username,
+			},
+			BusinessKey: a.NoRawat,
+			Actor:       actor,
+			IP:          ip,
+			Summary:     fmt.Sprintf("%s mengambil klaim %s dari antrian", username, a.NoRawat),
+		})
+
+		return a, nil
+	}
+
+	return nil, ErrQueueEmpty
+}
+
+// Distribute assigns unassigned Rencana episodes served within the
+// configured lookback window, round-robin within each pool to the coder
+// assigned least recently. It runs regardless of the enabled flag, which
+// only governs the automatic runs.
+func (s *AssignmentService) Distribute(ctx context.Context, actor audit.Actor, ip string) (*entity.DistributeResult, error) {
+	cfg, err := s.settingsRepo.GetAssignmentConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignment config: %w", err)
+	}
+	return s.distribute(ctx, cfg, actor, ip)
+}
+
+// StartAutoDistribution runs Distribute in the background every
+// interval_minutes while vedika.assignment is enabled, until ctx is done.
+// The setting is re-read on each run, so enabling or retuning it needs no
+// restart.
+func (s *AssignmentService) StartAutoDistribution(ctx context.Context) {
+	go func() {
+		actor := audit.Actor{UserID: entity.AssignedBySystem, Username: entity.AssignedBySystem}
+		for {
+			interval := 15 * time.Minute
+
+			cfg, err := s.settingsRepo.GetAssignmentConfig(ctx)
+			switch {
+			case ctx.Err() != nil:
+				return
+			case errors.Is(err, repository.ErrSettingNotFound):
+				// Not configured; keep polling in case it is added
+			case err != nil:
+				log.Printf("Gagal membaca pengaturan penugasan klaim: %v", err)
+			default:
+				interval = time.Duration(cfg.IntervalMinutes) * time.Minute
+				if cfg.Enabled {
+					if _, err := s.distribute(ctx, cfg, actor, ""); err != nil && ctx.Err() == nil {
+						log.Printf("Gagal membagikan klaim otomatis: %v", err)
+					}
+				}
+			}
+
+			timer := time.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+}
+
+// distribute performs one distribution run with the given configuration.
+func (s *AssignmentService) distribute(ctx context.Context, cfg *entity.AssignmentConfig, actor audit.Actor, ip string) (*entity.DistributeResult, error) {
+	s.distributeMu.Lock()
+	defer s.distributeMu.Unlock()
+
+	result := &entity.DistributeResult{PerUser: map[string]int{}, StartedAt: time.Now()}
+
+	carabayar, err := s.settingsRepo.GetAllowedCarabayar(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allowed carabayar: %w", err)
+	}
+
+	dateTo := result.StartedAt.Format("2006-01-02")
+	dateFrom := result.StartedAt.AddDate(0, 0, -cfg.LookbackDays).Format("2006-01-02")
+	candidates, err := s.listUnassigned(ctx, []entity.JenisPelayanan{entity.JenisRalan, entity.JenisRanap}, dateFrom, dateTo, carabayar, distributeBatch)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return result, nil
+	}
+
+	// Resolve every pooled coder once; inactive coders are skipped
+	usernames := map[string]string{}
+	var userIDs []string
+	addUsers := func(ids []string) error {
+		for _, id := range ids {
+			if _, seen := usernames[id]; seen {
+				continue
+			}
+			username, err := s.assignmentRepo.GetActiveUsername(ctx, id)
+			if errors.Is(err, repository.ErrCoderNotFound) {
+				username = ""
+			} else if err != nil {
+				return err
+			}
+			usernames[id] = username
+			if username != "" {
+				userIDs = append(userIDs, id)
+			}
+		}
+		return nil
+	}
+	for _, pool := range cfg.Pools {
+		if err := addUsers(pool); err != nil {
+			return nil, err
+		}
+	}
+	if err := addUsers(cfg.Default); err != nil {
+		return nil, err
+	}
+
+	last, err := s.assignmentRepo.LastAssignedAt(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, candidate := range candidates {
+		// Least recently assigned active coder; ties go to pool order
+		userID := ""
+		for _, id := range cfg.Pool(candidate) {
+			if usernames[id] == "" {
+				continue
+			}
+			if userID == "" || last[id].Before(last[userID]) {
+				userID = id
+			}
+		}
+		if userID == "" {
+			result.Unpooled++
+			continue
+		}
+
+		a := &entity.ClaimAssignment{
+			AssignmentCandidate: candidate,
+			UserID:              userID,
+			Username:            usernames[userID],
+			AssignedBy:          entity.AssignedBySystem,
+			AssignedAt:          time.Now(),
+		}
+		ok, err := s.assignmentRepo.AssignIfUnassigned(ctx, a)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			// Claimed or assigned meanwhile
+			continue
+		}
+		last[userID] = a.AssignedAt
+		result.Assigned++
+		result.PerUser[a.Username]++
+	}
+
+	if result.Assigned > 0 {
+		// Audit log - INSERT
+		s.auditLogger.LogInsert(audit.InsertParams{
+			Module: "vedika",
+			Entity: audit.Entity{
+				Table:      "mera_vedika_assignment",
+				PrimaryKey: map[string]string{"started_at": result.StartedAt.Format(time.RFC3339)},
+			},
+			InsertedData: map[string]interface{}{
+				"action":    "distribute_claims",
+				"assigned":  result.Assigned,
+				"unpooled":  result.Unpooled,
+				"per_user":  result.PerUser,
+				"date_from": dateFrom,
+				"date_to":   dateTo,
+			},
+			BusinessKey: dateFrom + ".." + dateTo,
+			Actor:       actor,
+			IP:          ip,
+			Summary:     fmt.Sprintf("Membagikan %d klaim Rencana kepada %d coder", result.Assigned, len(result.PerUser)),
+		})
+	}
+
+	return result, nil
+}
+
+// listUnassigned merges the unassigned queues of several jenis, oldest
+// service date first.
+func (s *AssignmentService) listUnassigned(ctx context.Context, jenisList []entity.JenisPelayanan, dateFrom, dateTo string, carabayar []string, limit int) ([]entity.AssignmentCandidate, error) {
+	var candidates []entity.AssignmentCandidate
+	for _, jenis := range jenisList {
+		list, err := s.assignmentRepo.ListUnassigned(ctx, jenis, dateFrom, dateTo, carabayar, limit)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, list...)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].TglPelayanan < candidates[j].TglPelayanan
+	})
+	return candidates, nil
+}
+
+// periodBounds returns the first and last day (YYYY-MM-DD) of a period (YYYY-MM).
+func periodBounds(period string) (string, string, error) {
+	start, err := time.Parse("2006-01", period)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid active_period %q: %w", period, err)
+	}
+	return start.Format("2006-01-02"), start.AddDate(0, 1, -1).Format("2006-01-02"), nil
+}
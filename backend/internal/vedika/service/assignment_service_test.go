@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/internal/vedika/repository"
+	"github.com/clinova/simrs/backend/pkg/audit"
+)
+
+// fakeAssignmentRepo holds queues and assignments in memory.
+type fakeAssignmentRepo struct {
+	repository.AssignmentRepository
+	coders      map[string]string // Active user id -> username
+	last        map[string]time.Time
+	queues      map[entity.JenisPelayanan][]entity.AssignmentCandidate
+	taken       map[string]bool // Episodes another coder claims first
+	assignments map[string]string
+	order       []string // no_rawat in assignment order
+}
+
+func (r *fakeAssignmentRepo) GetActiveUsername(ctx context.Context, userID string) (string, error) {
+	if username, ok := r.coders[userID]; ok {
+		return username, nil
+	}
+	return "", repository.ErrCoderNotFound
+}
+
+func (r *fakeAssignmentRepo) LastAssignedAt(ctx context.Context, userIDs []string) (map[string]time.Time, error) {
+	last := map[string]time.Time{}
+	for _, id := range userIDs {
+		if t, ok := r.last[id]; ok {
+			last[id] = t
+		}
+	}
+	return last, nil
+}
+
+func (r *fakeAssignmentRepo) ListUnassigned(ctx context.Context, jenis entity.JenisPelayanan, dateFrom, dateTo string, carabayar []string, limit int) ([]entity.AssignmentCandidate, error) {
+	return r.queues[jenis], nil
+}
+
+func (r *fakeAssignmentRepo) AssignIfUnassigned(ctx context.Context, a *entity.ClaimAssignment) (bool, error) {
+	if r.taken[a.NoRawat] || r.assignments[a.NoRawat] != "" {
+		return false, nil
+	}
+	r.assignments[a.NoRawat] = a.UserID
+	r.order = append(r.order, a.NoRawat)
+	return true, nil
+}
+
+func newFakeAssignmentRepo() *fakeAssignmentRepo {
+	return &fakeAssignmentRepo{
+		coders:      map[string]string{"u-a": "coder_a", "u-b": "coder_b", "u-d": "coder_d"},
+		last:        map[string]time.Time{},
+		queues:      map[entity.JenisPelayanan][]entity.AssignmentCandidate{},
+		taken:       map[string]bool{},
+		assignments: map[string]string{},
+	}
+}
+
+func candidate(noRawat, jenis, unit, tgl string) entity.AssignmentCandidate {
+	return entity.AssignmentCandidate{NoRawat: noRawat, Jenis: jenis, KdUnit: unit, TglPelayanan: tgl}
+}
+
+func TestDistributeBalancesLeastRecentlyAssigned(t *testing.T) {
+	repo := newFakeAssignmentRepo()
+	repo.last["u-a"] = time.Now().Add(-time.Hour) // u-b never had a claim
+	repo.queues[entity.JenisRalan] = []entity.AssignmentCandidate{
+		candidate("r1", "ralan", "INT", "2026-01-01"),
+		candidate("r2", "ralan", "INT", "2026-01-02"),
+		candidate("r3", "ralan", "INT", "2026-01-03"),
+		candidate("r4", "ralan", "INT", "2026-01-04"),
+	}
+	repo.queues[entity.JenisRanap] = []entity.AssignmentCandidate{
+		candidate("i1", "ranap", "VIP", "2026-01-02"),
+	}
+	cfg := &entity.AssignmentConfig{
+		GroupBy: entity.AssignByJenis,
+		Pools:   map[string][]string{"ralan": {"u-a", "u-b", "u-inactive"}},
+	}
+	s := NewAssignmentService(repo, &fakeSettingsRepo{carabayar: []string{"BPJ"}}, newTestAuditLogger(t))
+
+	result, err := s.distribute(context.Background(), cfg, audit.Actor{Username: entity.AssignedBySystem}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"r1": "u-b", "r2": "u-a", "r3": "u-b", "r4": "u-a"}
+	for noRawat, userID := range want {
+		if repo.assignments[noRawat] != userID {
+			t.Errorf("%s assigned to %q, want %s", noRawat, repo.assignments[noRawat], userID)
+		}
+	}
+	if result.Assigned != 4 || result.Unpooled != 1 {
+		t.Fatalf("assigned %d unpooled %d, want 4 and 1", result.Assigned, result.Unpooled)
+	}
+	if !reflect.DeepEqual(result.PerUser, map[string]int{"coder_a": 2, "coder_b": 2}) {
+		t.Fatalf("per user %v", result.PerUser)
+	}
+	// Oldest service date first across both queues; i1 had no pool
+	if !reflect.DeepEqual(repo.order, []string{"r1", "r2", "r3", "r4"}) {
+		t.Fatalf("assignment order %v", repo.order)
+	}
+}
+
+func TestDistributeSkipsClaimsTakenMeanwhile(t *testing.T) {
+	repo := newFakeAssignmentRepo()
+	repo.queues[entity.JenisRalan] = []entity.AssignmentCandidate{
+		candidate("r1", "ralan", "INT", "2026-01-01"),
+		candidate("r2", "ralan", "INT", "2026-01-02"),
+		candidate("r3", "ralan", "INT", "2026-01-03"),
+	}
+	repo.taken["r1"] = true
+	cfg := &entity.AssignmentConfig{Default: []string{"u-a", "u-b"}}
+	s := NewAssignmentService(repo, &fakeSettingsRepo{}, newTestAuditLogger(t))
+
+	result, err := s.distribute(context.Background(), cfg, audit.Actor{Username: entity.AssignedBySystem}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Assigned != 2 {
+		t.Fatalf("assigned %d, want 2", result.Assigned)
+	}
+	// The coder who lost r1 is still next in line
+	if repo.assignments["r2"] != "u-a" || repo.assignments["r3"] != "u-b" {
+		t.Fatalf("assignments %v, want r2 to u-a and r3 to u-b", repo.assignments)
+	}
+}
+
+func TestDistributeByUnit(t *testing.T) {
+	repo := newFakeAssignmentRepo()
+	repo.queues[entity.JenisRalan] = []entity.AssignmentCandidate{
+		candidate("r1", "ralan", "INT", "2026-01-01"),
+		candidate("r2", "ralan", "OBG", "2026-01-01"),
+	}
+	repo.queues[entity.JenisRanap] = []entity.AssignmentCandidate{
+		candidate("i1", "ranap", "VIP", "2026-01-01"),
+	}
+	cfg := &entity.AssignmentConfig{
+		GroupBy: entity.AssignByUnit,
+		Pools:   map[string][]string{"INT": {"u-a"}, "VIP": {"u-b"}, "OBG": {}},
+		Default: []string{"u-d"},
+	}
+	s := NewAssignmentService(repo, &fakeSettingsRepo{}, newTestAuditLogger(t))
+
+	if _, err := s.distribute(context.Background(), cfg, audit.Actor{Username: entity.AssignedBySystem}, ""); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"r1": "u-a", "r2": "u-d", "i1": "u-b"}
+	if !reflect.DeepEqual(repo.assignments, want) {
+		t.Fatalf("assignments %v, want %v", repo.assignments, want)
+	}
+}
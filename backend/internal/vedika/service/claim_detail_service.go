@@ -2,34 +2,59 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path"
 	"strings"
+	"time"
 
+	"github.com/clinova/simrs/backend/internal/vedika/claimpdf"
 	"github.com/clinova/simrs/backend/internal/vedika/entity"
 	"github.com/clinova/simrs/backend/internal/vedika/repository"
 	"github.com/clinova/simrs/backend/pkg/audit"
+	"github.com/clinova/simrs/backend/pkg/storage"
 )
 
 // ClaimDetailService handles business logic for claim detail view.
 type ClaimDetailService struct {
-	claimRepo    repository.ClaimDetailRepository
-	settingsRepo repository.SettingsRepository
-	auditLogger  *audit.Logger
+	claimRepo     repository.ClaimDetailRepository
+	settingsRepo  repository.SettingsRepository
+	feedbackRepo  repository.FeedbackRepository
+	documentStore storage.DocumentStore
+	auditLogger   *audit.Logger
+	versions      MedicalVersionSource
+}
+
+// MedicalVersionSource reports the medical data version of a claim.
+type MedicalVersionSource interface {
+	MedicalDataVersion(ctx context.Context, noRawat string) (string, error)
 }
 
 // NewClaimDetailService creates a new claim detail service.
 func NewClaimDetailService(
 	claimRepo repository.ClaimDetailRepository,
 	settingsRepo repository.SettingsRepository,
+	feedbackRepo repository.FeedbackRepository,
+	documentStore storage.DocumentStore,
 	auditLogger *audit.Logger,
 ) *ClaimDetailService {
 	return &ClaimDetailService{
-		claimRepo:    claimRepo,
-		settingsRepo: settingsRepo,
-		auditLogger:  auditLogger,
+		claimRepo:     claimRepo,
+		settingsRepo:  settingsRepo,
+		feedbackRepo:  feedbackRepo,
+		documentStore: documentStore,
+		auditLogger:   auditLogger,
 	}
 }
 
+// SetMedicalVersionSource attaches the medical data version to the full
+// claim detail, so editors can send it back in If-Match.
+func (s *ClaimDetailService) SetMedicalVersionSource(versions MedicalVersionSource) {
+	s.versions = versions
+}
+
 // GetClaimFullDetail returns comprehensive claim data for all 14 sections.
 func (s *ClaimDetailService) GetClaimFullDetail(
 	ctx context.Context,
@@ -48,6 +73,12 @@ func (s *ClaimDetailService) GetClaimFullDetail(
 		return nil, fmt.Errorf("failed to get claim detail: %w", err)
 	}
 
+	if s.versions != nil {
+		if detail.Version, err = s.versions.MedicalDataVersion(ctx, noRawat); err != nil {
+			return nil, err
+		}
+	}
+
 	// Fetch dynamic legacy webapp URL from settings
 	baseURL, _ := s.settingsRepo.GetLegacyWebAppURL(ctx)
 	if baseURL != "" {
@@ -62,6 +93,16 @@ func (s *ClaimDetailService) GetClaimFullDetail(
 		}
 	}
 
+	// Attach unresolved correction notes
+	detail.OpenFeedback = []entity.FeedbackNote{}
+	if detail.SEP != nil && detail.SEP.NoSEP != "" {
+		notes, err := s.feedbackRepo.ListOpenBySEP(ctx, detail.SEP.NoSEP)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get open feedback: %w", err)
+		}
+		detail.OpenFeedback = notes
+	}
+
 	// Audit log - READ
 	s.auditLogger.LogInsert(audit.InsertParams{
 		Module: "vedika",
@@ -81,3 +122,137 @@ func (s *ClaimDetailService) GetClaimFullDetail(
 
 	return detail, nil
 }
+
+// GenerateClaimPDF renders the full claim bundle of an episode (all 14
+// sections plus uploaded documents) as a single PDF.
+func (s *ClaimDetailService) GenerateClaimPDF(
+	ctx context.Context,
+	noRawat string,
+	actor audit.Actor,
+	ip string,
+) ([]byte, error) {
+	if _, err := s.settingsRepo.GetAllowedCarabayar(ctx); err != nil {
+		return nil, fmt.Errorf("VEDIKA_SETTINGS_MISSING: Pengaturan Vedika belum lengkap")
+	}
+
+	detail, err := s.claimRepo.GetClaimFullDetail(ctx, noRawat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get claim detail: %w", err)
+	}
+
+	// Institution identity is optional; the title is printed without it
+	var institution string
+	if inst, err := s.settingsRepo.GetInstitution(ctx); err == nil {
+		institution = inst.Nama
+		if address := strings.TrimSpace(strings.Trim(inst.Alamat+", "+inst.Kota, ", ")); address != "" {
+			institution += " - " + address
+		}
+	}
+
+	baseURL, _ := s.settingsRepo.GetLegacyWebAppURL(ctx)
+	root := LegacyWebAppRoot(baseURL)
+
+	data, err := claimpdf.Render(detail, claimpdf.Options{
+		Institution: institution,
+		LoadFile: func(name string) ([]byte, error) {
+			// Stored paths come from the database; never leave the webapps root
+			clean := strings.TrimPrefix(path.Clean("/"+name), "/")
+
+			// Uploaded documents live in the document store, keyed by lokasi_file
+			if key, ok := strings.CutPrefix(clean, "berkasrawat/"); ok {
+				body, _, err := s.documentStore.Get(ctx, key)
+				if err != nil {
+					return nil, err
+				}
+				defer body.Close()
+				return io.ReadAll(body)
+			}
+			return os.ReadFile(root + clean)
+		},
+		PrintedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render claim pdf: %w", err)
+	}
+
+	// Audit log - READ
+	s.auditLogger.LogInsert(audit.InsertParams{
+		Module: "vedika",
+		Entity: audit.Entity{
+			Table:      "claim_detail_full",
+			PrimaryKey: map[string]string{"no_rawat": noRawat},
+		},
+		InsertedData: map[string]interface{}{
+			"action":    "print_claim_pdf",
+			"no_rawat":  noRawat,
+			"documents": len(detail.Documents),
+			"size":      len(data),
+		},
+		BusinessKey: noRawat,
+		Actor:       actor,
+		IP:          ip,
+		Summary:     fmt.Sprintf("Mencetak PDF berkas klaim %s", noRawat),
+	})
+
+	return data, nil
+}
+
+// EvaluateCompleteness builds the "kelengkapan berkas" checklist of a claim
+// from its full detail and the vedika.completeness setting. Without the
+// setting only the built-in checks are applied.
+func (s *ClaimDetailService) EvaluateCompleteness(ctx context.Context, noRawat string) (*entity.CompletenessReport, error) {
+	detail, err := s.claimRepo.GetClaimFullDetail(ctx, noRawat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get claim detail: %w", err)
+	}
+
+	cfg := &entity.CompletenessConfig{}
+	if c, err := s.settingsRepo.GetCompletenessConfig(ctx); err == nil {
+		cfg = c
+	} else if !errors.Is(err, repository.ErrSettingNotFound) {
+		return nil, err
+	}
+
+	names := map[string]string{}
+	if len(cfg.RequiredDocumentsRalan) > 0 || len(cfg.RequiredDocumentsRanap) > 0 {
+		if names, err = s.claimRepo.GetDocumentCategoryNames(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return entity.CheckCompleteness(noRawat, detail, *cfg, names), nil
+}
+
+// CheckCompleteness returns the completeness checklist of a claim.
+func (s *ClaimDetailService) CheckCompleteness(
+	ctx context.Context,
+	noRawat string,
+	actor audit.Actor,
+	ip string,
+) (*entity.CompletenessReport, error) {
+	report, err := s.EvaluateCompleteness(ctx, noRawat)
+	if err != nil {
+		return nil, err
+	}
+
+	// Audit log - READ
+	s.auditLogger.LogInsert(audit.InsertParams{
+		Module: "vedika",
+		Entity: audit.Entity{
+			Table:      "claim_detail_full",
+			PrimaryKey: map[string]string{"no_rawat": noRawat},
+		},
+		InsertedData: map[string]interface{}{
+			"action":   "check_completeness",
+			"no_rawat": noRawat,
+			"complete": report.Complete,
+			"failed":   len(report.FailedMandatory()),
+		},
+		BusinessKey: noRawat,
+		Actor:       actor,
+		IP:          ip,
+		Summary:     fmt.Sprintf("Memeriksa kelengkapan berkas klaim %s", noRawat),
+	})
+
+	return report, nil
+}
@@ -31,13 +31,14 @@ func NewDashboardService(
 	}
 }
 
-// GetDashboardSummary returns the dashboard summary with all counts and maturasi.
-// OPTIMIZED: Uses parallel execution for independent database queries.
-func (s *DashboardService) GetDashboardSummary(ctx context.Context, actor audit.Actor, ip string) (*entity.DashboardSummary, error) {
-	// Get required settings (must be sequential as other queries depend on these)
-	period, err := s.settingsRepo.GetActivePeriod(ctx)
+// GetDashboardSummary returns the dashboard counts and maturasi of rng, or
+// of the active period when rng is empty. With compare, the summary also
+// carries the counts of the same range one month earlier and the deltas.
+// The active period setting is only read, never changed.
+func (s *DashboardService) GetDashboardSummary(ctx context.Context, rng entity.DashboardRange, compare bool, actor audit.Actor, ip string) (*entity.DashboardSummary, error) {
+	rng, err := s.resolveRange(ctx, rng)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get active period: %w", err)
+		return nil, err
 	}
 
 	carabayar, err := s.settingsRepo.GetAllowedCarabayar(ctx)
@@ -45,6 +46,73 @@ func (s *DashboardService) GetDashboardSummary(ctx context.Context, actor audit.
 		return nil, fmt.Errorf("failed to get allowed carabayar: %w", err)
 	}
 
+	counts, work, err := s.countSummary(ctx, rng, carabayar, true)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &entity.DashboardSummary{
+		DashboardRange:  rng,
+		DashboardCounts: *counts,
+		Workload:        work,
+	}
+
+	if compare {
+		prevRng := rng.PreviousMonth()
+		prev, _, err := s.countSummary(ctx, prevRng, carabayar, false)
+		if err != nil {
+			return nil, err
+		}
+		summary.Comparison = &entity.DashboardComparison{
+			DashboardRange: prevRng,
+			Previous:       *prev,
+			Delta:          counts.Sub(*prev),
+		}
+	}
+
+	// Write audit log (async, non-blocking)
+	go s.auditLogger.LogInsert(audit.InsertParams{
+		Module: "vedika",
+		Entity: audit.Entity{
+			Table:      "dashboard",
+			PrimaryKey: map[string]string{"period": rng.Label()},
+		},
+		InsertedData: map[string]interface{}{
+			"action":    "view_dashboard",
+			"period":    rng.Period,
+			"date_from": rng.DateFrom,
+			"date_to":   rng.DateTo,
+			"compare":   compare,
+		},
+		BusinessKey: rng.Label(),
+		Actor:       actor,
+		IP:          ip,
+		Summary:     fmt.Sprintf("Melihat dashboard Vedika periode %s", rng.Label()),
+	})
+
+	return summary, nil
+}
+
+// resolveRange returns rng, or the active period when rng is empty.
+func (s *DashboardService) resolveRange(ctx context.Context, rng entity.DashboardRange) (entity.DashboardRange, error) {
+	if rng.DateFrom != "" {
+		return rng, nil
+	}
+	period, err := s.settingsRepo.GetActivePeriod(ctx)
+	if err != nil {
+		return rng, fmt.Errorf("failed to get active period: %w", err)
+	}
+	rng, err = entity.PeriodRange(period)
+	if err != nil {
+		return rng, fmt.Errorf("invalid active_period: %w", err)
+	}
+	return rng, nil
+}
+
+// countSummary counts the dashboard cards of rng, and the coder workload
+// when withWorkload is set.
+// OPTIMIZED: Uses parallel execution for independent database queries.
+func (s *DashboardService) countSummary(ctx context.Context, rng entity.DashboardRange, carabayar []string, withWorkload bool) (*entity.DashboardCounts, []entity.UserWorkload, error) {
 	// Execute all count queries in parallel
 	var (
 		wg      sync.WaitGroup
@@ -53,6 +121,7 @@ func (s *DashboardService) GetDashboardSummary(ctx context.Context, actor audit.
 		lenk    entity.ClaimCount
 		perb    entity.ClaimCount
 		setuju  entity.ClaimCount
+		work    []entity.UserWorkload
 
 		errRenRalan error
 		errRenRanap error
@@ -64,6 +133,7 @@ func (s *DashboardService) GetDashboardSummary(ctx context.Context, actor audit.
 		errPerRanap error
 		errSetRalan error
 		errSetRanap error
+		errWork     error
 	)
 
 	wg.Add(10)
@@ -71,126 +141,92 @@ func (s *DashboardService) GetDashboardSummary(ctx context.Context, actor audit.
 	// Rencana
 	go func() {
 		defer wg.Done()
-		rencana.Ralan, errRenRalan = s.dashboardRepo.CountRencanaRalan(ctx, period, carabayar)
+		rencana.Ralan, errRenRalan = s.dashboardRepo.CountRencanaRalan(ctx, rng, carabayar)
 	}()
 	go func() {
 		defer wg.Done()
-		rencana.Ranap, errRenRanap = s.dashboardRepo.CountRencanaRanap(ctx, period, carabayar)
+		rencana.Ranap, errRenRanap = s.dashboardRepo.CountRencanaRanap(ctx, rng, carabayar)
 	}()
 
 	// Pengajuan
 	go func() {
 		defer wg.Done()
-		pen.Ralan, errPenRalan = s.dashboardRepo.CountByStatusAndJenis(ctx, period, entity.StatusPengajuan, entity.JenisRalan)
+		pen.Ralan, errPenRalan = s.dashboardRepo.CountByStatusAndJenis(ctx, rng, entity.StatusPengajuan, entity.JenisRalan)
 	}()
 	go func() {
 		defer wg.Done()
-		pen.Ranap, errPenRanap = s.dashboardRepo.CountByStatusAndJenis(ctx, period, entity.StatusPengajuan, entity.JenisRanap)
+		pen.Ranap, errPenRanap = s.dashboardRepo.CountByStatusAndJenis(ctx, rng, entity.StatusPengajuan, entity.JenisRanap)
 	}()
 
 	// Lengkap
 	go func() {
 		defer wg.Done()
-		lenk.Ralan, errLenRalan = s.dashboardRepo.CountByStatusAndJenis(ctx, period, entity.StatusLengkap, entity.JenisRalan)
+		lenk.Ralan, errLenRalan = s.dashboardRepo.CountByStatusAndJenis(ctx, rng, entity.StatusLengkap, entity.JenisRalan)
 	}()
 	go func() {
 		defer wg.Done()
-		lenk.Ranap, errLenRanap = s.dashboardRepo.CountByStatusAndJenis(ctx, period, entity.StatusLengkap, entity.JenisRanap)
+		lenk.Ranap, errLenRanap = s.dashboardRepo.CountByStatusAndJenis(ctx, rng, entity.StatusLengkap, entity.JenisRanap)
 	}()
 
 	// Perbaikan
 	go func() {
 		defer wg.Done()
-		perb.Ralan, errPerRalan = s.dashboardRepo.CountByStatusAndJenis(ctx, period, entity.StatusPerbaikan, entity.JenisRalan)
+		perb.Ralan, errPerRalan = s.dashboardRepo.CountByStatusAndJenis(ctx, rng, entity.StatusPerbaikan, entity.JenisRalan)
 	}()
 	go func() {
 		defer wg.Done()
-		perb.Ranap, errPerRanap = s.dashboardRepo.CountByStatusAndJenis(ctx, period, entity.StatusPerbaikan, entity.JenisRanap)
+		perb.Ranap, errPerRanap = s.dashboardRepo.CountByStatusAndJenis(ctx, rng, entity.StatusPerbaikan, entity.JenisRanap)
 	}()
 
 	// Setuju
 	go func() {
 		defer wg.Done()
-		setuju.Ralan, errSetRalan = s.dashboardRepo.CountByStatusAndJenis(ctx, period, entity.StatusSetuju, entity.JenisRalan)
+		setuju.Ralan, errSetRalan = s.dashboardRepo.CountByStatusAndJenis(ctx, rng, entity.StatusSetuju, entity.JenisRalan)
 	}()
 	go func() {
 		defer wg.Done()
-		setuju.Ranap, errSetRanap = s.dashboardRepo.CountByStatusAndJenis(ctx, period, entity.StatusSetuju, entity.JenisRanap)
+		setuju.Ranap, errSetRanap = s.dashboardRepo.CountByStatusAndJenis(ctx, rng, entity.StatusSetuju, entity.JenisRanap)
 	}()
 
+	// Coder workload
+	if withWorkload {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			work, errWork = s.dashboardRepo.CountWorkload(ctx, rng)
+		}()
+	}
+
 	wg.Wait()
 
 	// Error handling
 	if errRenRalan != nil || errRenRanap != nil || errPenRalan != nil || errPenRanap != nil ||
 		errLenRalan != nil || errLenRanap != nil || errPerRalan != nil || errPerRanap != nil ||
-		errSetRalan != nil || errSetRanap != nil {
-		return nil, fmt.Errorf("failed to fetch dashboard counts")
-	}
-
-	// Calculate maturasi
-	// Total processed = pengajuan + lengkap + perbaikan + setuju
-	pengajuanTotalRalan := pen.Ralan + lenk.Ralan + perb.Ralan + setuju.Ralan
-	pengajuanTotalRanap := pen.Ranap + lenk.Ranap + perb.Ranap + setuju.Ranap
-
-	totalRencanaRalan := rencana.Ralan + pengajuanTotalRalan
-	totalRencanaRanap := rencana.Ranap + pengajuanTotalRanap
-
-	var maturasi entity.MaturasiPersen
-	if totalRencanaRalan > 0 {
-		maturasi.Ralan = float64(pengajuanTotalRalan) / float64(totalRencanaRalan) * 100
-	}
-	if totalRencanaRanap > 0 {
-		maturasi.Ranap = float64(pengajuanTotalRanap) / float64(totalRencanaRanap) * 100
-	}
-
-	summary := &entity.DashboardSummary{
-		Period: period,
-		Rencana: entity.ClaimCount{
-			Ralan: totalRencanaRalan,
-			Ranap: totalRencanaRanap,
-		},
-		Pengajuan: pen,
-		Lengkap:   lenk,
-		Perbaikan: perb,
-		Maturasi:  maturasi,
+		errSetRalan != nil || errSetRanap != nil || errWork != nil {
+		return nil, nil, fmt.Errorf("failed to fetch dashboard counts")
 	}
 
-	// Write audit log (async, non-blocking)
-	go s.auditLogger.LogInsert(audit.InsertParams{
-		Module: "vedika",
-		Entity: audit.Entity{
-			Table:      "dashboard",
-			PrimaryKey: map[string]string{"period": period},
-		},
-		InsertedData: map[string]interface{}{
-			"action": "view_dashboard",
-			"period": period,
-		},
-		BusinessKey: period,
-		Actor:       actor,
-		IP:          ip,
-		Summary:     fmt.Sprintf("Melihat dashboard Vedika periode %s", period),
-	})
-
-	return summary, nil
+	counts := entity.NewDashboardCounts(rencana, pen, lenk, perb, setuju)
+	return &counts, work, nil
 }
 
-// GetDashboardTrend returns daily trend data for the dashboard chart.
-func (s *DashboardService) GetDashboardTrend(ctx context.Context, actor audit.Actor, ip string) ([]entity.DashboardTrendItem, error) {
-	period, err := s.settingsRepo.GetActivePeriod(ctx)
+// GetDashboardTrend returns daily trend data of rng for the dashboard chart,
+// or of the active period when rng is empty, with the range it covers.
+func (s *DashboardService) GetDashboardTrend(ctx context.Context, rng entity.DashboardRange, actor audit.Actor, ip string) ([]entity.DashboardTrendItem, entity.DashboardRange, error) {
+	rng, err := s.resolveRange(ctx, rng)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get active period: %w", err)
+		return nil, rng, err
 	}
 
 	carabayar, err := s.settingsRepo.GetAllowedCarabayar(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get allowed carabayar: %w", err)
+		return nil, rng, fmt.Errorf("failed to get allowed carabayar: %w", err)
 	}
 
-	trend, err := s.dashboardRepo.GetDailyTrend(ctx, period, carabayar)
+	trend, err := s.dashboardRepo.GetDailyTrend(ctx, rng, carabayar)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get daily trend: %w", err)
+		return nil, rng, fmt.Errorf("failed to get daily trend: %w", err)
 	}
 
-	return trend, nil
+	return trend, rng, nil
 }
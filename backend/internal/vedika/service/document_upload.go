@@ -0,0 +1,182 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/jpeg" // register JPEG decoder
+	_ "image/png"  // register PNG decoder
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/pkg/pdf"
+)
+
+// scanMargin is the blank border around an image converted to PDF, in points.
+const scanMargin = 20
+
+// maxFilenameLength caps the sanitized base name, excluding the extension.
+const maxFilenameLength = 80
+
+// documentTypes maps sniffed MIME types to upload document types and the
+// extension they are stored with.
+var documentTypes = map[string]struct{ docType, ext string }{
+	"application/pdf": {entity.DocumentTypePDF, "pdf"},
+	"image/jpeg":      {entity.DocumentTypeJPEG, "jpg"},
+	"image/png":       {entity.DocumentTypePNG, "png"},
+}
+
+// preparedUpload is an uploaded file that passed validation and is ready
+// to be stored.
+type preparedUpload struct {
+	Data         []byte
+	Filename     string // Sanitized, with the extension of the stored format
+	ContentType  string // Of Data
+	SHA256       string // Of the uploaded content, before conversion
+	OriginalName string
+	Converted    bool
+}
+
+// prepareUpload runs the validation pipeline on an uploaded file: size limit,
+// content sniffing, format check against the category rule, integrity check,
+// hashing, optional image-to-PDF conversion and filename sanitizing. The
+// client-supplied content type is never trusted. Rejections are returned as
+// *entity.UploadError.
+func prepareUpload(rule *entity.DocumentRule, filename string, body io.Reader, size int64) (*preparedUpload, error) {
+	reject := func(code, message string) *entity.UploadError {
+		return &entity.UploadError{Code: code, Kode: rule.Kode, Message: message}
+	}
+
+	limit := rule.MaxBytes()
+	tooLarge := func() error {
+		e := reject(entity.UploadTooLarge, fmt.Sprintf("Ukuran berkas %s melebihi batas %d KB", rule.Nama, rule.MaxSizeKB))
+		e.MaxSizeKB = rule.MaxSizeKB
+		return e
+	}
+	if size > limit {
+		return nil, tooLarge()
+	}
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, tooLarge()
+	}
+	if len(data) == 0 {
+		return nil, reject(entity.UploadCorruptFile, "Berkas kosong")
+	}
+
+	// Sniff the real format from content
+	mimeType := http.DetectContentType(data)
+	if i := strings.IndexByte(mimeType, ';'); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	format, ok := documentTypes[mimeType]
+	if !ok || !rule.Allows(format.docType) {
+		e := reject(entity.UploadUnsupportedType, fmt.Sprintf("Format berkas %s tidak diizinkan untuk kategori %s", mimeType, rule.Nama))
+		e.DetectedType = mimeType
+		e.AllowedTypes = rule.AllowedTypes
+		return nil, e
+	}
+
+	// Reject truncated or unreadable files before they reach the claim bundle
+	if format.docType == entity.DocumentTypePDF {
+		r, err := pdf.NewReader(data)
+		if err != nil {
+			return nil, reject(entity.UploadCorruptFile, "PDF rusak atau terenkripsi: "+err.Error())
+		}
+		if pages, err := r.Pages(); err != nil || len(pages) == 0 {
+			return nil, reject(entity.UploadCorruptFile, "PDF tidak memiliki halaman yang dapat dibaca")
+		}
+	} else if cfg, _, err := image.DecodeConfig(bytes.NewReader(data)); err != nil || cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, reject(entity.UploadCorruptFile, "Gambar rusak atau tidak dapat dibaca")
+	} else if pdf.ImageTooLarge(cfg.Width, cfg.Height, pdf.DefaultMaxImagePixels) {
+		// Checked from the header: the claim bundle could not embed it either
+		return nil, reject(entity.UploadTooLarge, fmt.Sprintf("Resolusi gambar %dx%d melebihi batas %d megapiksel", cfg.Width, cfg.Height, pdf.DefaultMaxImagePixels/1_000_000))
+	}
+
+	sum := sha256.Sum256(data)
+	up := &preparedUpload{
+		Data:         data,
+		ContentType:  mimeType,
+		SHA256:       hex.EncodeToString(sum[:]),
+		OriginalName: filename,
+	}
+
+	ext := format.ext
+	if rule.ConvertToPDF && format.docType != entity.DocumentTypePDF {
+		converted, err := imageToPDF(data, filename)
+		if err != nil {
+			return nil, reject(entity.UploadCorruptFile, "Gambar tidak dapat dikonversi ke PDF: "+err.Error())
+		}
+		up.Data = converted
+		up.ContentType = "application/pdf"
+		up.Converted = true
+		ext = "pdf"
+	}
+
+	up.Filename = sanitizeFilename(filename, ext)
+	return up, nil
+}
+
+// sanitizeFilename reduces an uploaded file name to a safe base name
+// ([A-Za-z0-9._-]) with the given extension, so it can be joined into a
+// storage key and a legacy lokasi_file.
+func sanitizeFilename(filename, ext string) string {
+	base := path.Base(strings.ReplaceAll(filename, "\\", "/"))
+	base = strings.TrimSuffix(base, path.Ext(base))
+	base = safeName(base)
+	if len(base) > maxFilenameLength {
+		base = strings.TrimRight(base[:maxFilenameLength], "._-")
+	}
+	if base == "" {
+		base = "berkas"
+	}
+	return base + "." + ext
+}
+
+// safeName replaces every character outside [A-Za-z0-9._-] with an
+// underscore, collapsing runs, and trims leading and trailing separators.
+func safeName(s string) string {
+	var b strings.Builder
+	lastUnderscore := false
+	for _, c := range s {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '.', c == '-':
+			b.WriteRune(c)
+			lastUnderscore = false
+		default:
+			if !lastUnderscore {
+				b.WriteByte('_')
+				lastUnderscore = true
+			}
+		}
+	}
+	return strings.Trim(b.String(), "._-")
+}
+
+// imageToPDF places a JPEG or PNG scan on a single A4 page, scaled to fit
+// inside the margins and centered.
+func imageToPDF(data []byte, title string) ([]byte, error) {
+	doc := pdf.New()
+	doc.SetTitle(title)
+
+	img, err := doc.AddImage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	page := doc.AddPage()
+	pw, ph := page.Size()
+	scale := min((pw-2*scanMargin)/float64(img.Width), (ph-2*scanMargin)/float64(img.Height))
+	w, h := float64(img.Width)*scale, float64(img.Height)*scale
+	page.Image(img, (pw-w)/2, (ph-h)/2, w, h)
+
+	return doc.Bytes()
+}
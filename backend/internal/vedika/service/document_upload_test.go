@@ -0,0 +1,32 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+)
+
+func TestPrepareUploadRejectsOversizedImage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	// Declare 60000x60000 in the IHDR chunk and fix its CRC
+	binary.BigEndian.PutUint32(data[16:], 60000)
+	binary.BigEndian.PutUint32(data[20:], 60000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+
+	rule := &entity.DocumentRule{Kode: "001", Nama: "Scan", MaxSizeKB: 1024, AllowedTypes: []string{entity.DocumentTypePNG}, ConvertToPDF: true}
+	_, err := prepareUpload(rule, "scan.png", bytes.NewReader(data), int64(len(data)))
+	var upErr *entity.UploadError
+	if !errors.As(err, &upErr) || upErr.Code != entity.UploadTooLarge {
+		t.Fatalf("prepareUpload = %v, want %s", err, entity.UploadTooLarge)
+	}
+}
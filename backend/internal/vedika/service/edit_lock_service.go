@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/internal/vedika/repository"
+	"github.com/clinova/simrs/backend/pkg/audit"
+)
+
+// EditLockService manages soft edit locks on claims. Locks tell coders who
+// has a claim open; the medical data version still guards the writes.
+type EditLockService struct {
+	lockRepo    repository.EditLockRepository
+	auditLogger *audit.Logger
+}
+
+// NewEditLockService creates a new edit lock service.
+func NewEditLockService(lockRepo repository.EditLockRepository, auditLogger *audit.Logger) *EditLockService {
+	return &EditLockService{
+		lockRepo:    lockRepo,
+		auditLogger: auditLogger,
+	}
+}
+
+// Acquire takes the claim's lock for the actor, or renews it if the actor
+// already holds it. Returns *entity.ClaimLockedError while another coder
+// holds an unexpired lock.
+func (s *EditLockService) Acquire(ctx context.Context, noRawat string, actor audit.Actor, ip string) (*entity.EditLock, error) {
+	// Whole seconds, as stored, so a new acquisition reads back equal to now
+	now := time.Now().Truncate(time.Second)
+	lock, err := s.lockRepo.Acquire(ctx, noRawat, actor.UserID, now, now.Add(entity.EditLockTTL))
+	if err != nil {
+		return nil, err
+	}
+	if lock.UserID != actor.UserID {
+		return nil, &entity.ClaimLockedError{NoRawat: noRawat, Holder: lock}
+	}
+
+	// Audit only new acquisitions; re-opening a held claim is a renewal
+	if lock.AcquiredAt.Equal(now) {
+		s.auditLogger.LogInsert(audit.InsertParams{
+			Module: "vedika",
+			Entity: audit.Entity{
+				Table:      "mera_vedika_edit_lock",
+				PrimaryKey: map[string]string{"no_rawat": noRawat},
+			},
+			InsertedData: map[string]interface{}{
+				"action":     "acquire_edit_lock",
+				"user_id":    lock.UserID,
+				"expires_at": lock.ExpiresAt,
+			},
+			BusinessKey: noRawat,
+			Actor:       actor,
+			IP:          ip,
+			Summary:     fmt.Sprintf("Membuka klaim %s untuk diedit", noRawat),
+		})
+	}
+
+	return lock, nil
+}
+
+// Heartbeat extends the actor's lock. Returns entity.ErrEditLockNotHeld if
+// the lock expired or was broken, or *entity.ClaimLockedError if another
+// coder has taken it since.
+func (s *EditLockService) Heartbeat(ctx context.Context, noRawat string, actor audit.Actor) (*entity.EditLock, error) {
+	now := time.Now()
+	renewed, err := s.lockRepo.Renew(ctx, noRawat, actor.UserID, now, now.Add(entity.EditLockTTL))
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := s.lockRepo.Get(ctx, noRawat)
+	if errors.Is(err, repository.ErrEditLockNotFound) {
+		return nil, fmt.Errorf("%w: %s", entity.ErrEditLockNotHeld, noRawat)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !renewed || lock.UserID != actor.UserID {
+		if lock.UserID != actor.UserID && lock.Active(now) {
+			return nil, &entity.ClaimLockedError{NoRawat: noRawat, Holder: lock}
+		}
+		return nil, fmt.Errorf("%w: %s", entity.ErrEditLockNotHeld, noRawat)
+	}
+	return lock, nil
+}
+
+// Release drops the actor's lock. Releasing a lock the actor does not hold
+// is a no-op, so clients can release unconditionally on close.
+func (s *EditLockService) Release(ctx context.Context, noRawat string, actor audit.Actor, ip string) error {
+	released, err := s.lockRepo.Release(ctx, noRawat, actor.UserID)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return nil
+	}
+
+	// Audit log - DELETE
+	s.auditLogger.LogDelete(audit.DeleteParams{
+		Module: "vedika",
+		Entity: audit.Entity{
+			Table:      "mera_vedika_edit_lock",
+			PrimaryKey: map[string]string{"no_rawat": noRawat},
+		},
+		DeletedData: map[string]interface{}{
+			"action":  "release_edit_lock",
+			"user_id": actor.UserID,
+		},
+		BusinessKey: noRawat,
+		Actor:       actor,
+		IP:          ip,
+		Summary:     fmt.Sprintf("Menutup klaim %s", noRawat),
+	})
+
+	return nil
+}
+
+// Break removes another coder's lock (vedika.settings). Unexpired locks can
+// be broken too, e.g. when a coder left a claim open and went off shift; the
+// audit records whether the lock had expired. The broken holder's next
+// heartbeat or edit fails with entity.ErrEditLockNotHeld.
+//
+// Only the holder that was read is removed, so a lock taken over in between
+// is left alone and reported as repository.ErrEditLockNotFound.
+func (s *EditLockService) Break(ctx context.Context, noRawat string, actor audit.Actor, ip string) (*entity.EditLock, error) {
+	lock, err := s.lockRepo.Get(ctx, noRawat)
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := s.lockRepo.Release(ctx, noRawat, lock.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return nil, repository.ErrEditLockNotFound
+	}
+
+	// Audit log - DELETE
+	s.auditLogger.LogDelete(audit.DeleteParams{
+		Module: "vedika",
+		Entity: audit.Entity{
+			Table:      "mera_vedika_edit_lock",
+			PrimaryKey: map[string]string{"no_rawat": noRawat},
+		},
+		DeletedData: map[string]interface{}{
+			"action":      "break_edit_lock",
+			"user_id":     lock.UserID,
+			"username":    lock.Username,
+			"acquired_at": lock.AcquiredAt,
+			"expires_at":  lock.ExpiresAt,
+			"expired":     !lock.Active(time.Now()),
+		},
+		BusinessKey: noRawat,
+		Actor:       actor,
+		IP:          ip,
+		Summary:     fmt.Sprintf("Membuka paksa kunci klaim %s milik %s", noRawat, lock.Username),
+	})
+
+	return lock, nil
+}
+
+// ActiveLock returns the unexpired lock of a claim, or nil when it is free.
+func (s *EditLockService) ActiveLock(ctx context.Context, noRawat string) (*entity.EditLock, error) {
+	lock, err := s.lockRepo.Get(ctx, noRawat)
+	if errors.Is(err, repository.ErrEditLockNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !lock.Active(time.Now()) {
+		return nil, nil
+	}
+	return lock, nil
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/internal/vedika/repository"
+	"github.com/clinova/simrs/backend/pkg/audit"
+)
+
+// FeedbackService handles correction notes exchanged between coders and verifiers.
+type FeedbackService struct {
+	feedbackRepo repository.FeedbackRepository
+	auditLogger  *audit.Logger
+}
+
+// NewFeedbackService creates a new feedback service.
+func NewFeedbackService(feedbackRepo repository.FeedbackRepository, auditLogger *audit.Logger) *FeedbackService {
+	return &FeedbackService{
+		feedbackRepo: feedbackRepo,
+		auditLogger:  auditLogger,
+	}
+}
+
+// ListFeedback returns all notes of a claim grouped into threads.
+func (s *FeedbackService) ListFeedback(ctx context.Context, noRawat string, actor audit.Actor, ip string) (*entity.FeedbackThreads, error) {
+	noSEP, err := s.feedbackRepo.GetNoSEP(ctx, noRawat)
+	if err != nil {
+		return nil, err
+	}
+
+	notes, err := s.feedbackRepo.ListBySEP(ctx, noSEP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feedback: %w", err)
+	}
+
+	threads := entity.BuildFeedbackThreads(notes)
+	result := &entity.FeedbackThreads{
+		NoRawat: noRawat,
+		NoSEP:   noSEP,
+		Threads: threads,
+	}
+	for _, t := range threads {
+		if !t.IsResolved {
+			result.OpenCount++
+		}
+	}
+
+	// Audit log - READ
+	s.auditLogger.LogInsert(audit.InsertParams{
+		Module: "vedika",
+		Entity: audit.Entity{
+			Table:      "mlite_vedika_feedback",
+			PrimaryKey: map[string]string{"nosep": noSEP},
+		},
+		InsertedData: map[string]interface{}{
+			"action":   "list_feedback",
+			"no_rawat": noRawat,
+		},
+		BusinessKey: noRawat,
+		Actor:       actor,
+		IP:          ip,
+		Summary:     fmt.Sprintf("Melihat catatan perbaikan klaim %s", noRawat),
+	})
+
+	return result, nil
+}
+
+// AddFeedback adds a root note or a reply to an existing thread.
+// Replies to replies are attached to the thread's root note.
+func (s *FeedbackService) AddFeedback(ctx context.Context, noRawat string, req entity.FeedbackCreateRequest, actor audit.Actor, ip string) (*entity.FeedbackNote, error) {
+	catatan := strings.TrimSpace(req.Catatan)
+	if catatan == "" {
+		return nil, fmt.Errorf("catatan is required")
+	}
+
+	noSEP, err := s.feedbackRepo.GetNoSEP(ctx, noRawat)
+	if err != nil {
+		return nil, err
+	}
+
+	note := &entity.FeedbackNote{
+		NoSEP:    noSEP,
+		Catatan:  catatan,
+		Username: actor.Username,
+	}
+
+	if req.ParentID != nil {
+		parent, err := s.feedbackRepo.GetByID(ctx, *req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.NoSEP != noSEP {
+			return nil, fmt.Errorf("%w: %d", repository.ErrFeedbackNotFound, *req.ParentID)
+		}
+		rootID := parent.ID
+		if parent.ParentID != nil {
+			rootID = *parent.ParentID
+		}
+		note.ParentID = &rootID
+	}
+
+	if err := s.feedbackRepo.Create(ctx, note); err != nil {
+		return nil, fmt.Errorf("failed to add feedback: %w", err)
+	}
+
+	// Audit log - WRITE
+	inserted := map[string]interface{}{
+		"id":    note.ID,
+		"nosep": noSEP,
+	}
+	if note.ParentID != nil {
+		inserted["parent_id"] = *note.ParentID
+	}
+	s.auditLogger.LogInsert(audit.InsertParams{
+		Module: "vedika",
+		Entity: audit.Entity{
+			Table:      "mlite_vedika_feedback",
+			PrimaryKey: map[string]string{"id": strconv.FormatInt(note.ID, 10)},
+		},
+		InsertedData: inserted,
+		BusinessKey:  noRawat,
+		Actor:        actor,
+		IP:           ip,
+		Summary:      fmt.Sprintf("Menambahkan catatan perbaikan klaim %s", noRawat),
+	})
+
+	return note, nil
+}
+
+// ResolveFeedback marks a thread as resolved. Resolving a reply resolves its root.
+func (s *FeedbackService) ResolveFeedback(ctx context.Context, id int64, actor audit.Actor, ip string) error {
+	note, err := s.feedbackRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if note.ParentID != nil {
+		id = *note.ParentID
+	}
+
+	if err := s.feedbackRepo.Resolve(ctx, id, actor.Username); err != nil {
+		return fmt.Errorf("failed to resolve feedback: %w", err)
+	}
+
+	// Audit log - WRITE
+	s.auditLogger.LogUpdate(audit.UpdateParams{
+		Module: "vedika",
+		Entity: audit.Entity{
+			Table:      "mlite_vedika_feedback",
+			PrimaryKey: map[string]string{"id": strconv.FormatInt(id, 10)},
+		},
+		ChangedColumns: map[string]audit.ColumnChange{
+			"is_resolved": {Old: false, New: true},
+		},
+		Where:       map[string]interface{}{"id": id},
+		BusinessKey: note.NoSEP,
+		Actor:       actor,
+		IP:          ip,
+		Summary:     fmt.Sprintf("Menyelesaikan catatan perbaikan #%d SEP %s", id, note.NoSEP),
+	})
+
+	return nil
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/internal/vedika/inacbg"
+	"github.com/clinova/simrs/backend/internal/vedika/repository"
+	"github.com/clinova/simrs/backend/pkg/audit"
+)
+
+// InacbgService bridges claims to the E-Klaim INA-CBG web service.
+// Flow: new_claim -> set_claim_data -> grouper (stage 1, optional stage 2)
+// -> claim_final -> send_claim_individual.
+type InacbgService struct {
+	claimRepo        repository.ClaimDetailRepository
+	inacbgRepo       repository.InacbgRepository
+	bridgingSettings repository.BridgingSettingsRepository
+	auditLogger      *audit.Logger
+}
+
+// NewInacbgService creates a new INA-CBG bridging service.
+func NewInacbgService(
+	claimRepo repository.ClaimDetailRepository,
+	inacbgRepo repository.InacbgRepository,
+	bridgingSettings repository.BridgingSettingsRepository,
+	auditLogger *audit.Logger,
+) *InacbgService {
+	return &InacbgService{
+		claimRepo:        claimRepo,
+		inacbgRepo:       inacbgRepo,
+		bridgingSettings: bridgingSettings,
+		auditLogger:      auditLogger,
+	}
+}
+
+// GetGrouping returns the stored grouping of a claim.
+func (s *InacbgService) GetGrouping(ctx context.Context, noRawat string, actor audit.Actor, ip string) (*entity.InacbgGrouping, error) {
+	grouping, err := s.inacbgRepo.GetByNoRawat(ctx, noRawat)
+	if err != nil {
+		return nil, err
+	}
+
+	// Audit log - READ
+	s.auditLogger.LogInsert(audit.InsertParams{
+		Module: "vedika",
+		Entity: audit.Entity{
+			Table:      "mera_vedika_inacbg",
+			PrimaryKey: map[string]string{"no_rawat": noRawat},
+		},
+		InsertedData: map[string]interface{}{
+			"action":   "view_inacbg_grouping",
+			"no_rawat": noRawat,
+		},
+		BusinessKey: noRawat,
+		Actor:       actor,
+		IP:          ip,
+		Summary:     fmt.Sprintf("Melihat hasil grouping INA-CBG klaim %s", noRawat),
+	})
+
+	return grouping, nil
+}
+
+// GroupClaim sends the claim data to E-Klaim and runs the grouper.
+// Stage 2 runs when special CMG codes are given. The result is stored and
+// returned together with the special CMG options offered by stage 1.
+func (s *InacbgService) GroupClaim(ctx context.Context, noRawat string, req entity.InacbgGroupRequest, actor audit.Actor, ip string) (*entity.InacbgGrouping, *inacbg.GrouperResult, error) {
+	client, err := s.client(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	detail, err := s.claimRepo.GetClaimFullDetail(ctx, noRawat)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get claim detail: %w", err)
+	}
+
+	newClaim, err := inacbg.BuildNewClaim(detail)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", repository.ErrSEPNotFound, noRawat)
+	}
+	// Re-grouping an existing claim reuses its E-Klaim registration
+	if _, err := client.NewClaim(ctx, newClaim); err != nil && !inacbg.IsDuplicateClaim(err) {
+		return nil, nil, err
+	}
+
+	data, err := inacbg.BuildClaimData(detail, client.Config(), inacbg.ClaimOptions{CoderNIK: req.CoderNIK})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := client.SetClaimData(ctx, data); err != nil {
+		return nil, nil, err
+	}
+
+	result, err := client.GroupStage1(ctx, data.NomorSEP)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(req.SpecialCMG) > 0 {
+		options := result.SpecialCMGOption
+		if result, err = client.GroupStage2(ctx, data.NomorSEP, req.SpecialCMG); err != nil {
+			return nil, nil, err
+		}
+		result.SpecialCMGOption = options
+	}
+
+	grouping := &entity.InacbgGrouping{
+		NoRawat:        noRawat,
+		NoSEP:          data.NomorSEP,
+		CBGCode:        result.CBG.Code,
+		CBGDescription: result.CBG.Description,
+		CBGTariff:      float64(result.CBG.Tariff),
+		SpecialCMG:     []string{},
+		TotalTariff:    result.TotalTariff(),
+		TarifRS:        data.TarifRS.Total(),
+		KelasRawat:     data.KelasRawat,
+		INACBGVersion:  result.INACBGVersion,
+		CoderNIK:       req.CoderNIK,
+		GroupedBy:      actor.Username,
+	}
+	for _, cmg := range result.SpecialCMG {
+		grouping.SpecialCMG = append(grouping.SpecialCMG, cmg.Code)
+	}
+
+	if err := s.inacbgRepo.SaveGrouping(ctx, grouping); err != nil {
+		return nil, nil, err
+	}
+
+	// Audit log - WRITE
+	s.auditLogger.LogUpdate(audit.UpdateParams{
+		Module: "vedika",
+		Entity: audit.Entity{
+			Table:      "mera_vedika_inacbg",
+			PrimaryKey: map[string]string{"no_rawat": noRawat},
+		},
+		ChangedColumns: map[string]audit.ColumnChange{
+			"cbg_code":     {Old: nil, New: grouping.CBGCode},
+			"total_tariff": {Old: nil, New: grouping.TotalTariff},
+			"status":       {Old: nil, New: string(entity.InacbgGrouped)},
+		},
+		Where:       map[string]interface{}{"no_rawat": noRawat},
+		BusinessKey: noRawat,
+		Actor:       actor,
+		IP:          ip,
+		Summary:     fmt.Sprintf("Grouping INA-CBG klaim %s (SEP %s): %s", noRawat, grouping.NoSEP, grouping.CBGCode),
+	})
+
+	return grouping, result, nil
+}
+
+// SendClaim finalizes a grouped claim and sends it to the data center.
+func (s *InacbgService) SendClaim(ctx context.Context, noRawat string, req entity.InacbgSendRequest, actor audit.Actor, ip string) (*entity.InacbgGrouping, *inacbg.SendClaimResult, error) {
+	grouping, err := s.inacbgRepo.GetByNoRawat(ctx, noRawat)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := s.client(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// A claim that failed after claim_final is resumed at send
+	if grouping.Status == entity.InacbgGrouped {
+		if err := client.ClaimFinal(ctx, grouping.NoSEP, req.CoderNIK); err != nil {
+			return nil, nil, err
+		}
+		if err := s.inacbgRepo.UpdateStatus(ctx, noRawat, entity.InacbgFinal); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	sent, err := client.SendClaimIndividual(ctx, grouping.NoSEP)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.inacbgRepo.UpdateStatus(ctx, noRawat, entity.InacbgSent); err != nil {
+		return nil, nil, err
+	}
+
+	// Audit log - WRITE
+	s.auditLogger.LogUpdate(audit.UpdateParams{
+		Module: "vedika",
+		Entity: audit.Entity{
+			Table:      "mera_vedika_inacbg",
+			PrimaryKey: map[string]string{"no_rawat": noRawat},
+		},
+		ChangedColumns: map[string]audit.ColumnChange{
+			"status": {Old: string(grouping.Status), New: string(entity.InacbgSent)},
+		},
+		Where:       map[string]interface{}{"no_rawat": noRawat},
+		BusinessKey: noRawat,
+		Actor:       actor,
+		IP:          ip,
+		Summary:     fmt.Sprintf("Mengirim klaim %s (SEP %s) ke E-Klaim", noRawat, grouping.NoSEP),
+	})
+
+	updated, err := s.inacbgRepo.GetByNoRawat(ctx, noRawat)
+	if err != nil {
+		return nil, nil, err
+	}
+	return updated, sent, nil
+}
+
+// client builds an E-Klaim client from the inacbg.eklaim settings.
+func (s *InacbgService) client(ctx context.Context) (*inacbg.Client, error) {
+	settings, err := s.bridgingSettings.GetModuleSettings(ctx, inacbg.SettingsModule)
+	if errors.Is(err, repository.ErrSettingNotFound) {
+		return nil, fmt.Errorf("%w: %v", inacbg.ErrNotConfigured, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := inacbg.ConfigFromSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+	return inacbg.NewClient(cfg)
+}
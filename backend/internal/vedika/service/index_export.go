@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/pkg/audit"
+)
+
+// ExportColumn is a selectable column of the index export.
+type ExportColumn struct {
+	Key   string
+	Title string
+	Value func(row *entity.IndexExportRow) interface{}
+}
+
+// IndexExportColumns lists every export column in default order.
+var IndexExportColumns = []ExportColumn{
+	{"no_rawat", "No. Rawat", func(r *entity.IndexExportRow) interface{} { return r.NoRawat }},
+	{"no_rm", "No. RM", func(r *entity.IndexExportRow) interface{} { return r.NoRkmMedis }},
+	{"nama_pasien", "Nama Pasien", func(r *entity.IndexExportRow) interface{} { return r.NamaPasien }},
+	{"no_kartu", "No. Kartu", func(r *entity.IndexExportRow) interface{} { return r.NoKartu }},
+	{"no_sep", "No. SEP", func(r *entity.IndexExportRow) interface{} { return r.NoSEP }},
+	{"jenis", "Jenis", func(r *entity.IndexExportRow) interface{} { return r.Jenis }},
+	{"tgl_pelayanan", "Tgl. Pelayanan", func(r *entity.IndexExportRow) interface{} { return r.TglPelayanan }},
+	{"unit", "Unit", func(r *entity.IndexExportRow) interface{} { return r.Unit }},
+	{"dokter", "Dokter", func(r *entity.IndexExportRow) interface{} { return r.Dokter }},
+	{"cara_bayar", "Cara Bayar", func(r *entity.IndexExportRow) interface{} { return r.CaraBayar }},
+	{"status", "Status", func(r *entity.IndexExportRow) interface{} { return string(r.Status) }},
+	{"diagnosa_utama", "Diagnosa Utama", func(r *entity.IndexExportRow) interface{} { return r.DiagnosaUtama }},
+	{"diagnosa_sekunder", "Diagnosa Sekunder", func(r *entity.IndexExportRow) interface{} { return strings.Join(r.DiagnosaSekunder, ";") }},
+	{"prosedur", "Prosedur", func(r *entity.IndexExportRow) interface{} { return strings.Join(r.Prosedur, ";") }},
+	{"total_billing", "Total Billing", func(r *entity.IndexExportRow) interface{} { return r.TotalBilling }},
+}
+
+// ResolveExportColumns returns the columns named by keys, in the given
+// order, or every column when keys is empty.
+func ResolveExportColumns(keys []string) ([]ExportColumn, error) {
+	if len(keys) == 0 {
+		return IndexExportColumns, nil
+	}
+
+	byKey := make(map[string]ExportColumn, len(IndexExportColumns))
+	for _, col := range IndexExportColumns {
+		byKey[col.Key] = col
+	}
+
+	columns := make([]ExportColumn, 0, len(keys))
+	for _, key := range keys {
+		col, ok := byKey[strings.TrimSpace(key)]
+		if !ok {
+			return nil, fmt.Errorf("unknown export column: %s", key)
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+// ExportIndex streams every episode matching the filter to fn, ignoring
+// pagination, and audits the export with the number of rows written. A
+// failed export is audited too, with the rows written before the failure.
+func (s *WorkbenchService) ExportIndex(
+	ctx context.Context,
+	filter entity.IndexFilter,
+	format string,
+	fn func(*entity.IndexExportRow) error,
+	actor audit.Actor,
+	ip string,
+) (int, error) {
+	if filter.DateFrom == "" || filter.DateTo == "" {
+		return 0, fmt.Errorf("date_from and date_to are required")
+	}
+	if filter.Status == "" {
+		return 0, fmt.Errorf("status is required")
+	}
+
+	count := 0
+	err := s.indexRepo.StreamByDateRange(ctx, filter, func(row *entity.IndexExportRow) error {
+		count++
+		return fn(row)
+	})
+
+	data := map[string]interface{}{
+		"action":    "export_index",
+		"format":    format,
+		"status":    string(filter.Status),
+		"jenis":     string(filter.Jenis),
+		"date_from": filter.DateFrom,
+		"date_to":   filter.DateTo,
+		"search":    filter.Search,
+		"rows":      count,
+	}
+	summary := fmt.Sprintf("Mengekspor %d klaim status %s periode %s s/d %s (%s)", count, filter.Status, filter.DateFrom, filter.DateTo, format)
+	if err != nil {
+		data["error"] = err.Error()
+		summary = fmt.Sprintf("Ekspor klaim status %s periode %s s/d %s (%s) gagal setelah %d baris", filter.Status, filter.DateFrom, filter.DateTo, format, count)
+	}
+
+	// Audit log - EXPORT
+	s.auditLogger.LogInsert(audit.InsertParams{
+		Module: "vedika",
+		Entity: audit.Entity{
+			Table:      "index",
+			PrimaryKey: map[string]string{"date_from": filter.DateFrom, "date_to": filter.DateTo},
+		},
+		InsertedData: data,
+		BusinessKey:  fmt.Sprintf("%s_%s", filter.DateFrom, filter.DateTo),
+		Actor:        actor,
+		IP:           ip,
+		Summary:      summary,
+	})
+
+	if err != nil {
+		return count, fmt.Errorf("failed to export index: %w", err)
+	}
+	return count, nil
+}
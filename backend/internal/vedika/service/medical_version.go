@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/pkg/audit"
+)
+
+// GetMedicalDataState returns the editable medical data of a claim and its version.
+func (s *WorkbenchService) GetMedicalDataState(ctx context.Context, noRawat string) (*entity.MedicalDataState, error) {
+	diagnoses, err := s.indexRepo.GetDiagnoses(ctx, noRawat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diagnoses: %w", err)
+	}
+	procedures, err := s.indexRepo.GetProcedures(ctx, noRawat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get procedures: %w", err)
+	}
+	resume, err := s.indexRepo.GetResume(ctx, noRawat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resume: %w", err)
+	}
+	return entity.NewMedicalDataState(noRawat, diagnoses, procedures, resume), nil
+}
+
+// MedicalDataVersion returns the current medical data version of a claim.
+func (s *WorkbenchService) MedicalDataVersion(ctx context.Context, noRawat string) (string, error) {
+	state, err := s.GetMedicalDataState(ctx, noRawat)
+	if err != nil {
+		return "", err
+	}
+	return state.Version, nil
+}
+
+// beginEdit locks the claim for a medical data edit and checks that no
+// other coder holds its edit lock and that the editor's If-Match version is
+// still current. The lock is a database row lock, so the check and the write
+// are atomic across server instances. The caller must call the returned
+// unlock once the write (and endEdit) is done.
+func (s *WorkbenchService) beginEdit(ctx context.Context, noRawat, ifMatch string, actor audit.Actor) (func(), error) {
+	if ifMatch == "" {
+		return nil, entity.ErrVersionRequired
+	}
+	unlock, err := s.indexRepo.LockMedicalData(ctx, noRawat)
+	if err != nil {
+		return nil, err
+	}
+	// Checked under the row lock, so the edit lock cannot change hands
+	// between the check and the write
+	if err := s.checkEditLock(ctx, noRawat, actor); err != nil {
+		unlock()
+		return nil, err
+	}
+	state, err := s.GetMedicalDataState(ctx, noRawat)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	if state.Version != ifMatch {
+		unlock()
+		return nil, &entity.VersionConflictError{NoRawat: noRawat, Expected: ifMatch, Current: state}
+	}
+	return unlock, nil
+}
+
+// checkEditLock requires the actor to hold the claim's unexpired edit lock.
+// Another coder's lock fails with *entity.ClaimLockedError; a free claim
+// (never opened, expired or broken) fails with entity.ErrEditLockNotHeld.
+func (s *WorkbenchService) checkEditLock(ctx context.Context, noRawat string, actor audit.Actor) error {
+	if s.lockSource == nil {
+		return nil
+	}
+	lock, err := s.lockSource.ActiveLock(ctx, noRawat)
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		return fmt.Errorf("%w: %s", entity.ErrEditLockNotHeld, noRawat)
+	}
+	if lock.UserID != actor.UserID {
+		return &entity.ClaimLockedError{NoRawat: noRawat, Holder: lock}
+	}
+	return nil
+}
+
+// endEdit returns the version after a successful edit, read while the claim
+// is still locked so the editor's next If-Match reflects only its own write.
+// An empty version means it could not be read; the edit itself succeeded.
+func (s *WorkbenchService) endEdit(ctx context.Context, noRawat string) string {
+	version, err := s.MedicalDataVersion(ctx, noRawat)
+	if err != nil {
+		return ""
+	}
+	return version
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/pkg/audit"
+)
+
+type fakeLockSource struct {
+	lock *entity.EditLock
+}
+
+func (f fakeLockSource) ActiveLock(ctx context.Context, noRawat string) (*entity.EditLock, error) {
+	return f.lock, nil
+}
+
+func TestCheckEditLockRequiresHolder(t *testing.T) {
+	actor := audit.Actor{UserID: "u1", Username: "coder1"}
+	held := &entity.EditLock{NoRawat: "2026/01/01/000001", UserID: "u1", ExpiresAt: time.Now().Add(time.Minute)}
+	other := &entity.EditLock{NoRawat: "2026/01/01/000001", UserID: "u2", Username: "coder2", ExpiresAt: time.Now().Add(time.Minute)}
+
+	s := &WorkbenchService{}
+	s.SetEditLockSource(fakeLockSource{lock: held})
+	if err := s.checkEditLock(context.Background(), held.NoRawat, actor); err != nil {
+		t.Fatalf("holder: got %v, want nil", err)
+	}
+
+	s.SetEditLockSource(fakeLockSource{lock: other})
+	var locked *entity.ClaimLockedError
+	if err := s.checkEditLock(context.Background(), held.NoRawat, actor); !errors.As(err, &locked) {
+		t.Fatalf("other holder: got %v, want ClaimLockedError", err)
+	}
+
+	s.SetEditLockSource(fakeLockSource{})
+	if err := s.checkEditLock(context.Background(), held.NoRawat, actor); !errors.Is(err, entity.ErrEditLockNotHeld) {
+		t.Fatalf("free claim: got %v, want ErrEditLockNotHeld", err)
+	}
+}
+
+// rowLockRepo records whether the claim's row lock is held.
+type rowLockRepo struct {
+	fakeIndexRepo
+	held bool
+}
+
+func (r *rowLockRepo) LockMedicalData(ctx context.Context, noRawat string) (func(), error) {
+	r.held = true
+	return func() { r.held = false }, nil
+}
+
+// rowLockCheckingSource fails the edit lock check and records whether the
+// row lock was held when it ran.
+type rowLockCheckingSource struct {
+	repo    *rowLockRepo
+	heldNow *bool
+}
+
+func (f rowLockCheckingSource) ActiveLock(ctx context.Context, noRawat string) (*entity.EditLock, error) {
+	*f.heldNow = f.repo.held
+	return nil, nil
+}
+
+func TestBeginEditChecksEditLockUnderRowLock(t *testing.T) {
+	repo := &rowLockRepo{}
+	var heldDuringCheck bool
+	s := &WorkbenchService{indexRepo: repo}
+	s.SetEditLockSource(rowLockCheckingSource{repo: repo, heldNow: &heldDuringCheck})
+
+	_, err := s.beginEdit(context.Background(), "2026/01/01/000001", "v1", audit.Actor{UserID: "u1"})
+	if !errors.Is(err, entity.ErrEditLockNotHeld) {
+		t.Fatalf("got %v, want ErrEditLockNotHeld", err)
+	}
+	if !heldDuringCheck {
+		t.Fatal("edit lock was checked before the row lock was taken")
+	}
+	if repo.held {
+		t.Fatal("row lock not released after a failed edit lock check")
+	}
+}
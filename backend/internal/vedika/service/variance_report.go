@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/pkg/audit"
+)
+
+// GetVarianceReport compares the hospital tariff of every grouped claim in
+// the period range with its INA-CBG tariff and aggregates the variance by
+// filter.GroupBy. Periods default to the active period; carabayar is limited
+// to the allowed carabayar setting.
+func (s *DashboardService) GetVarianceReport(ctx context.Context, filter entity.VarianceFilter, actor audit.Actor, ip string) (*entity.VarianceReport, error) {
+	if filter.PeriodFrom == "" || filter.PeriodTo == "" {
+		period, err := s.settingsRepo.GetActivePeriod(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get active period: %w", err)
+		}
+		if filter.PeriodFrom == "" {
+			filter.PeriodFrom = period
+		}
+		if filter.PeriodTo == "" {
+			filter.PeriodTo = period
+		}
+	}
+	if filter.GroupBy == "" {
+		filter.GroupBy = entity.VarianceByCBG
+	}
+
+	allowed, err := s.settingsRepo.GetAllowedCarabayar(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allowed carabayar: %w", err)
+	}
+	filter.Carabayar = intersectCarabayar(allowed, filter.Carabayar)
+
+	claims, err := s.dashboardRepo.ListVarianceClaims(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variance claims: %w", err)
+	}
+
+	report := &entity.VarianceReport{
+		PeriodFrom: filter.PeriodFrom,
+		PeriodTo:   filter.PeriodTo,
+		Jenis:      string(filter.Jenis),
+		GroupBy:    filter.GroupBy,
+		Total:      entity.VarianceGroup{Key: "total", Label: "Total"},
+		Groups:     groupVariance(claims, filter.GroupBy),
+	}
+	for i := range claims {
+		report.Total.Add(&claims[i])
+	}
+
+	// Audit log - READ
+	s.auditLogger.LogInsert(audit.InsertParams{
+		Module: "vedika",
+		Entity: audit.Entity{
+			Table:      "mera_vedika_inacbg",
+			PrimaryKey: map[string]string{"period_from": filter.PeriodFrom, "period_to": filter.PeriodTo},
+		},
+		InsertedData: map[string]interface{}{
+			"action":      "view_variance_report",
+			"period_from": filter.PeriodFrom,
+			"period_to":   filter.PeriodTo,
+			"jenis":       string(filter.Jenis),
+			"group_by":    filter.GroupBy,
+			"claims":      len(claims),
+		},
+		BusinessKey: fmt.Sprintf("%s_%s", filter.PeriodFrom, filter.PeriodTo),
+		Actor:       actor,
+		IP:          ip,
+		Summary:     fmt.Sprintf("Melihat laporan selisih tarif RS vs INA-CBG periode %s s/d %s per %s", filter.PeriodFrom, filter.PeriodTo, filter.GroupBy),
+	})
+
+	return report, nil
+}
+
+// groupVariance aggregates claims by dimension. Groups are ordered by the
+// largest loss first, or chronologically when grouped by period.
+func groupVariance(claims []entity.VarianceClaim, groupBy string) []entity.VarianceGroup {
+	index := make(map[string]int)
+	groups := []entity.VarianceGroup{}
+	for i := range claims {
+		c := &claims[i]
+		key, label := varianceKey(c, groupBy)
+		pos, ok := index[key]
+		if !ok {
+			pos = len(groups)
+			index[key] = pos
+			groups = append(groups, entity.VarianceGroup{Key: key, Label: label})
+		}
+		groups[pos].Add(c)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groupBy == entity.VarianceByPeriod {
+			return groups[i].Key < groups[j].Key
+		}
+		if groups[i].TotalRugi != groups[j].TotalRugi {
+			return groups[i].TotalRugi < groups[j].TotalRugi
+		}
+		return groups[i].Selisih < groups[j].Selisih
+	})
+	return groups
+}
+
+// varianceKey returns the group key and display label of a claim. Except
+// for period groups, the key is prefixed with the jenis: unit codes are poli
+// codes for ralan and bangsal codes for ranap and may collide, and ralan and
+// ranap claims of the same DPJP or CBG are reported separately.
+func varianceKey(c *entity.VarianceClaim, groupBy string) (string, string) {
+	var key, label string
+	switch groupBy {
+	case entity.VarianceByDPJP:
+		key, label = c.KdDokter, c.Dokter
+	case entity.VarianceByUnit:
+		key, label = c.KdUnit, c.Unit
+	case entity.VarianceByPeriod:
+		key, label = c.Periode, c.Periode
+	default:
+		key, label = c.CBGCode, c.CBGCode
+		if c.CBGDescription != "" {
+			label += " - " + c.CBGDescription
+		}
+	}
+	if key == "" {
+		key = "-"
+	}
+	if label == "" {
+		label = key
+	}
+	if groupBy != entity.VarianceByPeriod {
+		key = c.Jenis + ":" + key
+		label += " (" + jenisLabel(c.Jenis) + ")"
+	}
+	return key, label
+}
+
+// jenisLabel returns the display name of a jenis value.
+func jenisLabel(jenis string) string {
+	if jenis == string(entity.JenisRanap) {
+		return "Ranap"
+	}
+	return "Ralan"
+}
+
+// intersectCarabayar limits requested carabayar codes to the allowed ones.
+// An empty request means every allowed code.
+func intersectCarabayar(allowed, requested []string) []string {
+	if len(requested) == 0 {
+		return allowed
+	}
+	ok := make(map[string]bool, len(allowed))
+	for _, kd := range allowed {
+		ok[kd] = true
+	}
+	result := []string{}
+	for _, kd := range requested {
+		if ok[kd] {
+			result = append(result, kd)
+		}
+	}
+	return result
+}
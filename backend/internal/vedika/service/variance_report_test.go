@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+)
+
+func TestGroupVarianceSeparatesJenis(t *testing.T) {
+	claims := []entity.VarianceClaim{
+		{Jenis: "ralan", KdUnit: "U01", Unit: "Poli Umum", Periode: "2026-01", TarifRS: 100, TarifINACBG: 80},
+		{Jenis: "ranap", KdUnit: "U01", Unit: "Bangsal Melati", Periode: "2026-01", TarifRS: 1000, TarifINACBG: 900},
+		{Jenis: "ralan", KdUnit: "U01", Unit: "Poli Umum", Periode: "2026-02", TarifRS: 100, TarifINACBG: 120},
+	}
+
+	units := groupVariance(claims, entity.VarianceByUnit)
+	if len(units) != 2 {
+		t.Fatalf("got %d unit groups, want 2: %+v", len(units), units)
+	}
+	byKey := map[string]entity.VarianceGroup{}
+	for _, g := range units {
+		byKey[g.Key] = g
+	}
+	if g := byKey["ralan:U01"]; g.JumlahKlaim != 2 || g.Label != "Poli Umum (Ralan)" {
+		t.Errorf("ralan group = %+v", g)
+	}
+	if g := byKey["ranap:U01"]; g.JumlahKlaim != 1 || g.Label != "Bangsal Melati (Ranap)" {
+		t.Errorf("ranap group = %+v", g)
+	}
+
+	periods := groupVariance(claims, entity.VarianceByPeriod)
+	if len(periods) != 2 || periods[0].Key != "2026-01" || periods[0].JumlahKlaim != 2 || periods[1].Key != "2026-02" {
+		t.Errorf("period groups = %+v", periods)
+	}
+}
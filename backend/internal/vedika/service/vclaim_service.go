@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/internal/vedika/repository"
+	"github.com/clinova/simrs/backend/internal/vedika/vclaim"
+	"github.com/clinova/simrs/backend/pkg/audit"
+	"github.com/clinova/simrs/backend/pkg/secret"
+)
+
+// VClaimService looks up SEP, peserta and rujukan data in BPJS VClaim.
+type VClaimService struct {
+	bridgingSettings repository.BridgingSettingsRepository
+	auditLogger      *audit.Logger
+}
+
+// NewVClaimService creates a new VClaim bridging service.
+func NewVClaimService(bridgingSettings repository.BridgingSettingsRepository, auditLogger *audit.Logger) *VClaimService {
+	return &VClaimService{
+		bridgingSettings: bridgingSettings,
+		auditLogger:      auditLogger,
+	}
+}
+
+// FetchSEPDetail implements repository.LiveSEPSource. An unknown SEP or
+// missing VClaim configuration yields nil, nil so claim detail still loads.
+func (s *VClaimService) FetchSEPDetail(ctx context.Context, noSEP string) (*entity.SEPDetail, error) {
+	client, err := s.client(ctx)
+	if errors.Is(err, vclaim.ErrNotConfigured) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sep, err := client.GetSEP(ctx, noSEP)
+	if errors.Is(err, vclaim.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sep.ToSEPDetail(), nil
+}
+
+// GetSEP looks up a SEP by number.
+func (s *VClaimService) GetSEP(ctx context.Context, noSEP string, actor audit.Actor, ip string) (*vclaim.SEP, error) {
+	client, err := s.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sep, err := client.GetSEP(ctx, noSEP)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logLookup("view_vclaim_sep", "no_sep", noSEP, actor, ip,
+		fmt.Sprintf("Melihat SEP %s dari VClaim", noSEP))
+
+	return sep, nil
+}
+
+// GetPeserta looks up a member by card number, or by NIK for 16-digit input.
+// tglSEP is YYYY-MM-DD and defaults to today.
+func (s *VClaimService) GetPeserta(ctx context.Context, noKartu, tglSEP string, actor audit.Actor, ip string) (*vclaim.Peserta, error) {
+	client, err := s.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var peserta *vclaim.Peserta
+	if len(noKartu) == 16 {
+		peserta, err = client.GetPesertaByNIK(ctx, noKartu, tglSEP)
+	} else {
+		peserta, err = client.GetPesertaByKartu(ctx, noKartu, tglSEP)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.logLookup("view_vclaim_peserta", "no_kartu", noKartu, actor, ip,
+		fmt.Sprintf("Melihat data peserta %s dari VClaim", noKartu))
+
+	return peserta, nil
+}
+
+// GetRujukan looks up a referral; fromRS selects hospital (FKRTL) referrals.
+func (s *VClaimService) GetRujukan(ctx context.Context, noRujukan string, fromRS bool, actor audit.Actor, ip string) (*vclaim.Rujukan, error) {
+	client, err := s.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rujukan, err := client.GetRujukan(ctx, noRujukan, fromRS)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logLookup("view_vclaim_rujukan", "no_rujukan", noRujukan, actor, ip,
+		fmt.Sprintf("Melihat rujukan %s dari VClaim", noRujukan))
+
+	return rujukan, nil
+}
+
+// logLookup writes the READ audit log of a VClaim lookup.
+func (s *VClaimService) logLookup(action, keyName, key string, actor audit.Actor, ip, summary string) {
+	s.auditLogger.LogInsert(audit.InsertParams{
+		Module: "vedika",
+		Entity: audit.Entity{
+			Table:      "bpjs_vclaim",
+			PrimaryKey: map[string]string{keyName: key},
+		},
+		InsertedData: map[string]interface{}{
+			"action": action,
+			keyName:  key,
+		},
+		BusinessKey: key,
+		Actor:       actor,
+		IP:          ip,
+		Summary:     summary,
+	})
+}
+
+// client builds a VClaim client from the bpjs.vclaim settings.
+func (s *VClaimService) client(ctx context.Context) (*vclaim.Client, error) {
+	settings, err := s.bridgingSettings.GetModuleSettings(ctx, vclaim.SettingsModule)
+	if errors.Is(err, repository.ErrSettingNotFound) || errors.Is(err, secret.ErrNoKey) {
+		return nil, fmt.Errorf("%w: %v", vclaim.ErrNotConfigured, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := vclaim.ConfigFromSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+	return vclaim.NewClient(cfg)
+}
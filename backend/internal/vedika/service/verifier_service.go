@@ -0,0 +1,395 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	authService "github.com/clinova/simrs/backend/internal/auth/service"
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/internal/vedika/repository"
+	"github.com/clinova/simrs/backend/pkg/audit"
+	"github.com/clinova/simrs/backend/pkg/jwt"
+	"github.com/clinova/simrs/backend/pkg/password"
+)
+
+var (
+	// ErrInvalidVerifierCredentials indicates a failed verifier login.
+	ErrInvalidVerifierCredentials = errors.New("invalid verifier credentials")
+	// ErrVerifierLocked indicates a verifier login locked after too many
+	// failed attempts.
+	ErrVerifierLocked = errors.New("verifier login locked")
+	// ErrVerifierSessionRevoked indicates a verifier token whose session was
+	// revoked (logout), has expired or does not exist.
+	ErrVerifierSessionRevoked = errors.New("verifier session revoked")
+	// ErrClaimNotVisible indicates a claim outside the verifier portal's scope:
+	// not recorded, not in a verifier status or outside the active period.
+	ErrClaimNotVisible = errors.New("claim not visible to verifier")
+)
+
+// VerifierActorPrefix marks audit actors from the verifier realm, so their
+// user IDs never collide with mera_users IDs.
+const VerifierActorPrefix = "veda:"
+
+// Failed verifier login reasons recorded in the audit log, as for staff logins.
+const (
+	failedVerifierLoginUnknownUser     = "unknown_user"
+	failedVerifierLoginInvalidPassword = "invalid_password"
+	failedVerifierLoginAccountLocked   = "account_locked"
+)
+
+// VerifierService backs the BPJS verifier portal. Verifiers authenticate
+// against mlite_users_vedika and only see claims in VerifierStatuses within
+// the active period; reads and decisions reuse the admin services.
+type VerifierService struct {
+	verifierRepo   repository.VerifierRepository
+	settingsRepo   repository.SettingsRepository
+	workbenchSvc   *WorkbenchService
+	claimDetailSvc *ClaimDetailService
+	feedbackSvc    *FeedbackService
+	jwtManager     *jwt.Manager
+	passwordHasher *password.Hasher
+	lockout        authService.LockoutPolicy
+	auditLogger    *audit.Logger
+}
+
+// NewVerifierService creates a new verifier portal service.
+func NewVerifierService(
+	verifierRepo repository.VerifierRepository,
+	settingsRepo repository.SettingsRepository,
+	workbenchSvc *WorkbenchService,
+	claimDetailSvc *ClaimDetailService,
+	feedbackSvc *FeedbackService,
+	jwtManager *jwt.Manager,
+	passwordHasher *password.Hasher,
+	lockout authService.LockoutPolicy,
+	auditLogger *audit.Logger,
+) *VerifierService {
+	return &VerifierService{
+		verifierRepo:   verifierRepo,
+		settingsRepo:   settingsRepo,
+		workbenchSvc:   workbenchSvc,
+		claimDetailSvc: claimDetailSvc,
+		feedbackSvc:    feedbackSvc,
+		jwtManager:     jwtManager,
+		passwordHasher: passwordHasher,
+		lockout:        lockout,
+		auditLogger:    auditLogger,
+	}
+}
+
+// VerifierActor returns the audit actor of a verifier.
+func VerifierActor(v *entity.Verifier) audit.Actor {
+	return audit.Actor{UserID: VerifierActorPrefix + strconv.FormatInt(v.ID, 10), Username: v.Username}
+}
+
+// Login checks verifier credentials and issues a verifier token bound to a
+// new verifier session. Failed logins count towards the same lockout policy
+// as staff logins and are audited.
+func (s *VerifierService) Login(ctx context.Context, req entity.VerifierLoginRequest, deviceInfo, ip string) (*entity.VerifierLogin, error) {
+	username := strings.TrimSpace(req.Username)
+	verifier, err := s.verifierRepo.GetByUsername(ctx, username)
+	if errors.Is(err, repository.ErrVerifierNotFound) {
+		s.recordFailedLogin(ctx, nil, username, ip, failedVerifierLoginUnknownUser)
+		return nil, ErrInvalidVerifierCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A locked login is refused before the password is checked, so
+	// guessing cannot continue during the lockout.
+	if verifier.IsLocked(time.Now()) {
+		s.recordFailedLogin(ctx, verifier, verifier.Username, ip, failedVerifierLoginAccountLocked)
+		return nil, ErrVerifierLocked
+	}
+
+	if err := s.passwordHasher.Verify(req.Password, verifier.PasswordHash); err != nil {
+		if s.recordFailedLogin(ctx, verifier, verifier.Username, ip, failedVerifierLoginInvalidPassword) {
+			return nil, ErrVerifierLocked
+		}
+		return nil, ErrInvalidVerifierCredentials
+	}
+
+	if err := s.verifierRepo.ResetFailedLogins(ctx, verifier.ID); err != nil {
+		log.Printf("Gagal mereset login gagal verifikator %s: %v", verifier.Username, err)
+	}
+
+	session := &entity.VerifierSession{
+		ID:         uuid.New().String(),
+		VerifierID: verifier.ID,
+		DeviceInfo: deviceInfo,
+		IPAddress:  ip,
+	}
+	token, expiresAt, err := s.jwtManager.GenerateVerifierToken(strconv.FormatInt(verifier.ID, 10), session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue verifier token: %w", err)
+	}
+	session.ExpiresAt = expiresAt
+	if err := s.verifierRepo.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	// Audit log - LOGIN
+	s.auditLogger.LogInsert(audit.InsertParams{
+		Module: "vedika",
+		Entity: audit.Entity{
+			Table:      "mlite_users_vedika",
+			PrimaryKey: map[string]string{"id": strconv.FormatInt(verifier.ID, 10)},
+		},
+		InsertedData: map[string]interface{}{
+			"action":     "verifier_login",
+			"username":   verifier.Username,
+			"session_id": session.ID,
+		},
+		BusinessKey: verifier.Username,
+		Actor:       VerifierActor(verifier),
+		IP:          ip,
+		Summary:     fmt.Sprintf("Verifikator %s login ke portal veda", verifier.Username),
+	})
+
+	return &entity.VerifierLogin{
+		Verifier:    verifier,
+		AccessToken: token,
+		ExpiresAt:   expiresAt,
+		TokenType:   "Bearer",
+	}, nil
+}
+
+// recordFailedLogin records a failed verifier login through the staff
+// lockout; verifier is nil for an unknown username. It reports whether the
+// verifier is now locked.
+func (s *VerifierService) recordFailedLogin(ctx context.Context, verifier *entity.Verifier, username, ip, reason string) bool {
+	f := authService.FailedLogin{
+		Username: username,
+		IP:       ip,
+		Reason:   reason,
+		Module:   "vedika",
+		Actor:    audit.Actor{Username: username},
+		Data:     map[string]interface{}{"realm": "veda"},
+		Summary:  fmt.Sprintf("Login verifikator gagal untuk %s dari IP %s (%s)", username, ip, reason),
+	}
+	if verifier != nil {
+		f.Actor = VerifierActor(verifier)
+		f.Data["verifier_id"] = verifier.ID
+		if reason != failedVerifierLoginAccountLocked {
+			f.Count = func(ctx context.Context, resetBefore time.Time) (int, error) {
+				return s.verifierRepo.RecordFailedLogin(ctx, verifier.ID, resetBefore)
+			}
+			f.Lock = func(ctx context.Context, until time.Time) error {
+				return s.verifierRepo.LockUntil(ctx, verifier.ID, until)
+			}
+		}
+	}
+	return s.lockout.RecordFailedLogin(ctx, s.auditLogger, f)
+}
+
+// Authenticate resolves a verifier token to its verifier and session. The
+// session must still be active; deleting the verifier from
+// mlite_users_vedika also revokes its tokens.
+func (s *VerifierService) Authenticate(ctx context.Context, token string) (*entity.Verifier, *entity.VerifierSession, error) {
+	claims, err := s.jwtManager.ValidateVerifierToken(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, err := strconv.ParseInt(claims.UserID, 10, 64)
+	if err != nil {
+		return nil, nil, jwt.ErrInvalidClaims
+	}
+
+	session, err := s.verifierRepo.GetSession(ctx, claims.SessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if session == nil || session.VerifierID != id || !session.IsActive(time.Now()) {
+		return nil, nil, ErrVerifierSessionRevoked
+	}
+
+	verifier, err := s.verifierRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return verifier, session, nil
+}
+
+// Logout revokes the verifier session, invalidating its token.
+func (s *VerifierService) Logout(ctx context.Context, sessionID string, actor audit.Actor, ip string) error {
+	if err := s.verifierRepo.RevokeSession(ctx, sessionID); err != nil {
+		return err
+	}
+
+	// Audit log - LOGOUT
+	s.auditLogger.LogInsert(audit.InsertParams{
+		Module: "vedika",
+		Entity: audit.Entity{
+			Table:      "mera_vedika_verifier_sessions",
+			PrimaryKey: map[string]string{"id": sessionID},
+		},
+		InsertedData: map[string]interface{}{
+			"action":     "verifier_logout",
+			"session_id": sessionID,
+		},
+		BusinessKey: actor.Username,
+		Actor:       actor,
+		IP:          ip,
+		Summary:     fmt.Sprintf("Verifikator %s logout dari portal veda", actor.Username),
+	})
+	return nil
+}
+
+// GetDashboard counts the claims of every verifier status in the active period.
+func (s *VerifierService) GetDashboard(ctx context.Context, actor audit.Actor, ip string) (*entity.VerifierDashboard, error) {
+	period, dateFrom, dateTo, err := s.activePeriod(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.verifierRepo.CountByStatus(ctx, dateFrom, dateTo, entity.VerifierStatuses)
+	if err != nil {
+		return nil, err
+	}
+
+	// Audit log - READ
+	s.auditLogger.LogInsert(audit.InsertParams{
+		Module: "vedika",
+		Entity: audit.Entity{
+			Table:      "mlite_vedika",
+			PrimaryKey: map[string]string{"period": period},
+		},
+		InsertedData: map[string]interface{}{
+			"action": "verifier_dashboard",
+			"period": period,
+		},
+		BusinessKey: period,
+		Actor:       actor,
+		IP:          ip,
+		Summary:     fmt.Sprintf("Verifikator melihat dashboard periode %s", period),
+	})
+
+	return &entity.VerifierDashboard{Period: period, Counts: counts}, nil
+}
+
+// ListClaims lists the claims of one verifier status in the active period.
+// The filter's date range is replaced by the active period.
+func (s *VerifierService) ListClaims(ctx context.Context, filter entity.IndexFilter, actor audit.Actor, ip string) (*entity.PaginatedResult[entity.ClaimEpisode], error) {
+	filter.Status = filter.Status.Normalize()
+	if !entity.IsVerifierStatus(filter.Status) {
+		return nil, fmt.Errorf("%w: status %s", ErrClaimNotVisible, filter.Status)
+	}
+
+	_, dateFrom, dateTo, err := s.activePeriod(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filter.DateFrom, filter.DateTo = dateFrom, dateTo
+
+	return s.workbenchSvc.ListIndex(ctx, filter, actor, ip)
+}
+
+// GetClaim returns the full detail of a visible claim.
+func (s *VerifierService) GetClaim(ctx context.Context, noRawat string, actor audit.Actor, ip string) (*entity.ClaimFullDetail, error) {
+	if err := s.ensureVisible(ctx, noRawat); err != nil {
+		return nil, err
+	}
+	return s.claimDetailSvc.GetClaimFullDetail(ctx, noRawat, actor, ip)
+}
+
+// GetClaimPDF renders the claim bundle PDF of a visible claim.
+func (s *VerifierService) GetClaimPDF(ctx context.Context, noRawat string, actor audit.Actor, ip string) ([]byte, error) {
+	if err := s.ensureVisible(ctx, noRawat); err != nil {
+		return nil, err
+	}
+	return s.claimDetailSvc.GenerateClaimPDF(ctx, noRawat, actor, ip)
+}
+
+// GetStatusHistory returns the status timeline of a visible claim.
+func (s *VerifierService) GetStatusHistory(ctx context.Context, noRawat string, actor audit.Actor, ip string) (*entity.StatusTimeline, error) {
+	if err := s.ensureVisible(ctx, noRawat); err != nil {
+		return nil, err
+	}
+	return s.workbenchSvc.GetStatusHistory(ctx, noRawat, actor, ip)
+}
+
+// ListFeedback returns the correction notes of a visible claim.
+func (s *VerifierService) ListFeedback(ctx context.Context, noRawat string, actor audit.Actor, ip string) (*entity.FeedbackThreads, error) {
+	if err := s.ensureVisible(ctx, noRawat); err != nil {
+		return nil, err
+	}
+	return s.feedbackSvc.ListFeedback(ctx, noRawat, actor, ip)
+}
+
+// AddFeedback posts a correction note or reply on a visible claim.
+func (s *VerifierService) AddFeedback(ctx context.Context, noRawat string, req entity.FeedbackCreateRequest, actor audit.Actor, ip string) (*entity.FeedbackNote, error) {
+	if err := s.ensureVisible(ctx, noRawat); err != nil {
+		return nil, err
+	}
+	return s.feedbackSvc.AddFeedback(ctx, noRawat, req, actor, ip)
+}
+
+// Approve moves a visible claim to Setuju.
+func (s *VerifierService) Approve(ctx context.Context, noRawat string, req entity.VerifierDecisionRequest, actor audit.Actor, ip string) error {
+	return s.decide(ctx, noRawat, entity.StatusSetuju, req.Catatan, actor, ip)
+}
+
+// Return sends a visible claim back to the hospital as Perbaikan. The
+// catatan opens a feedback thread on the SEP.
+func (s *VerifierService) Return(ctx context.Context, noRawat string, req entity.VerifierDecisionRequest, actor audit.Actor, ip string) error {
+	if strings.TrimSpace(req.Catatan) == "" {
+		return fmt.Errorf("catatan is required")
+	}
+	return s.decide(ctx, noRawat, entity.StatusPerbaikan, req.Catatan, actor, ip)
+}
+
+// decide applies a verifier decision through the claim workflow, with the
+// verifier holding only vedika.verify.
+func (s *VerifierService) decide(ctx context.Context, noRawat string, to entity.ClaimStatus, catatan string, actor audit.Actor, ip string) error {
+	if err := s.ensureVisible(ctx, noRawat); err != nil {
+		return err
+	}
+	granted := map[string]bool{entity.PermVerify: true}
+	req := entity.StatusUpdateRequest{Status: to, Catatan: catatan}
+	return s.workbenchSvc.UpdateClaimStatus(ctx, noRawat, req, granted, actor, ip)
+}
+
+// ensureVisible rejects claims outside the verifier portal's scope.
+func (s *VerifierService) ensureVisible(ctx context.Context, noRawat string) error {
+	status, tglRegistrasi, err := s.verifierRepo.GetClaimScope(ctx, noRawat)
+	if errors.Is(err, repository.ErrClaimNotRecorded) {
+		return fmt.Errorf("%w: %s", ErrClaimNotVisible, noRawat)
+	}
+	if err != nil {
+		return err
+	}
+	if !entity.IsVerifierStatus(status) {
+		return fmt.Errorf("%w: %s", ErrClaimNotVisible, noRawat)
+	}
+
+	_, dateFrom, dateTo, err := s.activePeriod(ctx)
+	if err != nil {
+		return err
+	}
+	if tglRegistrasi < dateFrom || tglRegistrasi > dateTo {
+		return fmt.Errorf("%w: %s", ErrClaimNotVisible, noRawat)
+	}
+	return nil
+}
+
+// activePeriod returns the active period (YYYY-MM) and its first and last
+// day (YYYY-MM-DD).
+func (s *VerifierService) activePeriod(ctx context.Context) (string, string, string, error) {
+	period, err := s.settingsRepo.GetActivePeriod(ctx)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to get active period: %w", err)
+	}
+	dateFrom, dateTo, err := periodBounds(period)
+	if err != nil {
+		return "", "", "", err
+	}
+	return period, dateFrom, dateTo, nil
+}
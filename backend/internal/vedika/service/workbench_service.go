@@ -1,35 +1,80 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
 
+	"github.com/clinova/simrs/backend/internal/vedika/coding"
 	"github.com/clinova/simrs/backend/internal/vedika/entity"
 	"github.com/clinova/simrs/backend/internal/vedika/repository"
 	"github.com/clinova/simrs/backend/pkg/audit"
+	"github.com/clinova/simrs/backend/pkg/storage"
 )
 
+// ErrDocumentStorage indicates the document store could not complete an
+// upload, download or delete.
+var ErrDocumentStorage = errors.New("document storage error")
+
 // WorkbenchService handles Index workbench business logic.
 // Uses explicit date range filtering, NOT active_period.
 type WorkbenchService struct {
-	indexRepo    repository.IndexRepository
-	settingsRepo repository.SettingsRepository
-	auditLogger  *audit.Logger
+	indexRepo     repository.IndexRepository
+	settingsRepo  repository.SettingsRepository
+	feedbackRepo  repository.FeedbackRepository
+	documentRepo  repository.DocumentRepository
+	documentStore storage.DocumentStore
+	auditLogger   *audit.Logger
+	completeness  CompletenessChecker
+	lockSource    EditLockSource
+}
+
+// CompletenessChecker evaluates the "kelengkapan berkas" checklist of a claim.
+type CompletenessChecker interface {
+	EvaluateCompleteness(ctx context.Context, noRawat string) (*entity.CompletenessReport, error)
+}
+
+// EditLockSource reports the soft edit lock a coder holds on a claim.
+type EditLockSource interface {
+	ActiveLock(ctx context.Context, noRawat string) (*entity.EditLock, error)
 }
 
 // NewWorkbenchService creates a new workbench service.
 func NewWorkbenchService(
 	indexRepo repository.IndexRepository,
 	settingsRepo repository.SettingsRepository,
+	feedbackRepo repository.FeedbackRepository,
+	documentRepo repository.DocumentRepository,
+	documentStore storage.DocumentStore,
 	auditLogger *audit.Logger,
 ) *WorkbenchService {
 	return &WorkbenchService{
-		indexRepo:    indexRepo,
-		settingsRepo: settingsRepo,
-		auditLogger:  auditLogger,
+		indexRepo:     indexRepo,
+		settingsRepo:  settingsRepo,
+		feedbackRepo:  feedbackRepo,
+		documentRepo:  documentRepo,
+		documentStore: documentStore,
+		auditLogger:   auditLogger,
 	}
 }
 
+// SetCompletenessChecker enables the completeness check that guards the
+// Lengkap transition.
+func (s *WorkbenchService) SetCompletenessChecker(checker CompletenessChecker) {
+	s.completeness = checker
+}
+
+// SetEditLockSource shows the lock holder in the claim detail and requires
+// edits to come from the coder holding the lock.
+func (s *WorkbenchService) SetEditLockSource(locks EditLockSource) {
+	s.lockSource = locks
+}
+
 // ListIndex lists episodes by date range and status.
 func (s *WorkbenchService) ListIndex(ctx context.Context, filter entity.IndexFilter, actor audit.Actor, ip string) (*entity.PaginatedResult[entity.ClaimEpisode], error) {
 	// Validate required filters
@@ -53,6 +98,12 @@ func (s *WorkbenchService) ListIndex(ctx context.Context, filter entity.IndexFil
 		return nil, fmt.Errorf("failed to list index: %w", err)
 	}
 
+	sla, err := slaConfig(ctx, s.settingsRepo)
+	if err != nil {
+		return nil, err
+	}
+	evaluateAging(result.Data, sla)
+
 	// Audit log - READ
 	s.auditLogger.LogInsert(audit.InsertParams{
 		Module: "vedika",
@@ -84,6 +135,15 @@ func (s *WorkbenchService) GetClaimDetail(ctx context.Context, noRawat string, a
 		return nil, fmt.Errorf("failed to get claim detail: %w", err)
 	}
 
+	if detail.Version, err = s.MedicalDataVersion(ctx, noRawat); err != nil {
+		return nil, err
+	}
+	if s.lockSource != nil {
+		if detail.Lock, err = s.lockSource.ActiveLock(ctx, noRawat); err != nil {
+			return nil, err
+		}
+	}
+
 	// Fetch dynamic legacy webapp URL from settings
 	url, err := s.settingsRepo.GetLegacyWebAppURL(ctx)
 	if err == nil {
@@ -110,18 +170,33 @@ func (s *WorkbenchService) GetClaimDetail(ctx context.Context, noRawat string, a
 	return detail, nil
 }
 
-func (s *WorkbenchService) UpdateClaimStatus(ctx context.Context, noRawat string, req entity.StatusUpdateRequest, actor audit.Actor, ip string) error {
+// UpdateClaimStatus moves a claim along the workflow graph.
+// granted holds the actor's effective permissions, used for per-transition checks.
+func (s *WorkbenchService) UpdateClaimStatus(ctx context.Context, noRawat string, req entity.StatusUpdateRequest, granted map[string]bool, actor audit.Actor, ip string) error {
 	// Normalize and validate status
 	req.Status = req.Status.Normalize()
 	if !req.Status.IsValid() {
 		return fmt.Errorf("invalid status: %s", req.Status)
 	}
 
-	// Get current status for audit
-	oldStatus, _ := s.indexRepo.GetEpisodeStatus(ctx, noRawat)
+	// Get current status and enforce the workflow
+	oldStatus, err := s.indexRepo.GetEpisodeStatus(ctx, noRawat)
+	if err != nil {
+		return fmt.Errorf("failed to get current status: %w", err)
+	}
+	oldStatus = oldStatus.Normalize()
+	if err := entity.CheckTransition(noRawat, oldStatus, req.Status, granted); err != nil {
+		return err
+	}
+	if err := s.checkOpenFeedback(ctx, noRawat, oldStatus, req.Status); err != nil {
+		return err
+	}
+	if err := s.checkCompleteness(ctx, noRawat, oldStatus, req.Status); err != nil {
+		return err
+	}
 
-	// Update status
-	if err := s.indexRepo.UpdateClaimStatus(ctx, noRawat, req.Status, actor.Username, req.Catatan); err != nil {
+	// Update status; the repository re-checks oldStatus under the row lock
+	if err := s.indexRepo.UpdateClaimStatus(ctx, noRawat, oldStatus, req.Status, actor.Username, req.Catatan); err != nil {
 		return fmt.Errorf("failed to update status: %w", err)
 	}
 
@@ -145,7 +220,9 @@ func (s *WorkbenchService) UpdateClaimStatus(ctx context.Context, noRawat string
 	return nil
 }
 
-func (s *WorkbenchService) BatchUpdateClaimStatus(ctx context.Context, req entity.BatchStatusUpdateRequest, actor audit.Actor, ip string) (*entity.BatchUpdateResult, error) {
+// BatchUpdateClaimStatus applies the same target status to many claims.
+// Claims whose current status cannot move to the target are counted as failed.
+func (s *WorkbenchService) BatchUpdateClaimStatus(ctx context.Context, req entity.BatchStatusUpdateRequest, granted map[string]bool, actor audit.Actor, ip string) (*entity.BatchUpdateResult, error) {
 	// Normalize and validate status
 	req.Status = req.Status.Normalize()
 	if !req.Status.IsValid() {
@@ -159,12 +236,41 @@ func (s *WorkbenchService) BatchUpdateClaimStatus(ctx context.Context, req entit
 	result := &entity.BatchUpdateResult{}
 
 	for _, noRawat := range req.NoRawatList {
-		// Get current status for audit
-		oldStatus, _ := s.indexRepo.GetEpisodeStatus(ctx, noRawat)
+		// Get current status and enforce the workflow
+		oldStatus, err := s.indexRepo.GetEpisodeStatus(ctx, noRawat)
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, entity.BatchItemError{NoRawat: noRawat, Message: err.Error()})
+			continue
+		}
+		oldStatus = oldStatus.Normalize()
+		if err := entity.CheckTransition(noRawat, oldStatus, req.Status, granted); err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, entity.BatchItemError{NoRawat: noRawat, Code: "INVALID_TRANSITION", Message: err.Error()})
+			continue
+		}
+		err = s.checkOpenFeedback(ctx, noRawat, oldStatus, req.Status)
+		if err == nil {
+			err = s.checkCompleteness(ctx, noRawat, oldStatus, req.Status)
+		}
+		if err != nil {
+			code := ""
+			if errors.Is(err, entity.ErrInvalidTransition) {
+				code = "INVALID_TRANSITION"
+			}
+			result.Failed++
+			result.Errors = append(result.Errors, entity.BatchItemError{NoRawat: noRawat, Code: code, Message: err.Error()})
+			continue
+		}
 
-		// Update status
-		if err := s.indexRepo.UpdateClaimStatus(ctx, noRawat, req.Status, actor.Username, req.Catatan); err != nil {
+		// Update status; the repository re-checks oldStatus under the row lock
+		if err := s.indexRepo.UpdateClaimStatus(ctx, noRawat, oldStatus, req.Status, actor.Username, req.Catatan); err != nil {
+			code := ""
+			if errors.Is(err, entity.ErrStatusChanged) {
+				code = "STATUS_CHANGED"
+			}
 			result.Failed++
+			result.Errors = append(result.Errors, entity.BatchItemError{NoRawat: noRawat, Code: code, Message: err.Error()})
 			continue
 		}
 		result.Updated++
@@ -190,10 +296,135 @@ func (s *WorkbenchService) BatchUpdateClaimStatus(ctx context.Context, req entit
 	return result, nil
 }
 
-// UpdateDiagnosis updates or adds a diagnosis.
-func (s *WorkbenchService) UpdateDiagnosis(ctx context.Context, noRawat string, req entity.DiagnosisUpdateRequest, actor audit.Actor, ip string) error {
+// checkOpenFeedback blocks a claim from leaving Perbaikan while its SEP
+// still has unresolved correction notes.
+func (s *WorkbenchService) checkOpenFeedback(ctx context.Context, noRawat string, from, to entity.ClaimStatus) error {
+	if from != entity.StatusPerbaikan || to == entity.StatusPerbaikan {
+		return nil
+	}
+
+	noSEP, err := s.feedbackRepo.GetNoSEP(ctx, noRawat)
+	if errors.Is(err, repository.ErrSEPNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	open, err := s.feedbackRepo.CountOpenBySEP(ctx, noSEP)
+	if err != nil {
+		return err
+	}
+	if open > 0 {
+		return &entity.TransitionError{
+			NoRawat:      noRawat,
+			From:         from,
+			To:           to,
+			OpenFeedback: open,
+		}
+	}
+	return nil
+}
+
+// checkCompleteness blocks a claim from entering Lengkap while mandatory
+// checklist items fail. Setuju is only reachable from Lengkap, so every
+// approved claim has passed this check.
+func (s *WorkbenchService) checkCompleteness(ctx context.Context, noRawat string, from, to entity.ClaimStatus) error {
+	if to != entity.StatusLengkap || s.completeness == nil {
+		return nil
+	}
+
+	report, err := s.completeness.EvaluateCompleteness(ctx, noRawat)
+	if err != nil {
+		return err
+	}
+	if !report.Complete {
+		return &entity.TransitionError{
+			NoRawat:    noRawat,
+			From:       from,
+			To:         to,
+			Incomplete: report.FailedMandatory(),
+		}
+	}
+	return nil
+}
+
+// GetStatusHistory returns the status timeline of a claim.
+func (s *WorkbenchService) GetStatusHistory(ctx context.Context, noRawat string, actor audit.Actor, ip string) (*entity.StatusTimeline, error) {
+	current, err := s.indexRepo.GetEpisodeStatus(ctx, noRawat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current status: %w", err)
+	}
+
+	history, err := s.indexRepo.GetStatusHistory(ctx, noRawat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status history: %w", err)
+	}
+
+	// Audit log - READ
+	s.auditLogger.LogInsert(audit.InsertParams{
+		Module: "vedika",
+		Entity: audit.Entity{
+			Table:      "mera_vedika_status_history",
+			PrimaryKey: map[string]string{"no_rawat": noRawat},
+		},
+		InsertedData: map[string]interface{}{
+			"action":   "view_status_history",
+			"no_rawat": noRawat,
+		},
+		BusinessKey: noRawat,
+		Actor:       actor,
+		IP:          ip,
+		Summary:     fmt.Sprintf("Melihat riwayat status klaim %s", noRawat),
+	})
+
+	return entity.NewStatusTimeline(noRawat, current, history), nil
+}
+
+// UpdateDiagnosis updates or adds a diagnosis. The episode's diagnosis set
+// including the change is validated first; blocking issues are returned as
+// *entity.CodingError. ifMatch is the medical data version the edit is based
+// on; the new version is returned.
+func (s *WorkbenchService) UpdateDiagnosis(ctx context.Context, noRawat string, req entity.DiagnosisUpdateRequest, ifMatch string, actor audit.Actor, ip string) (*entity.CodingValidation, string, error) {
+	unlock, err := s.beginEdit(ctx, noRawat, ifMatch, actor)
+	if err != nil {
+		return nil, "", err
+	}
+	defer unlock()
+
+	existing, err := s.indexRepo.GetDiagnoses(ctx, noRawat)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get diagnoses: %w", err)
+	}
+
+	// Validate the set as it will be after the upsert
+	req.KodePenyakit = coding.Normalize(req.KodePenyakit)
+	lines := []entity.DiagnosisUpdateRequest{}
+	maxPrioritas := 0
+	for _, d := range existing {
+		if coding.Normalize(d.KodePenyakit) == req.KodePenyakit {
+			continue
+		}
+		lines = append(lines, entity.DiagnosisUpdateRequest{KodePenyakit: d.KodePenyakit, Prioritas: d.Prioritas})
+		maxPrioritas = max(maxPrioritas, d.Prioritas)
+	}
+	if coding.IsPrimary(req) {
+		req.StatusDx, req.Prioritas = "Utama", 1
+	} else {
+		req.StatusDx = "Sekunder"
+		if req.Prioritas <= 1 {
+			req.Prioritas = max(maxPrioritas, 1) + 1
+		}
+	}
+	lines = append(lines, req)
+
+	validation, err := s.validateDiagnoses(ctx, noRawat, lines)
+	if err != nil {
+		return nil, "", err
+	}
+
 	if err := s.indexRepo.AddDiagnosis(ctx, noRawat, req); err != nil {
-		return fmt.Errorf("failed to update diagnosis: %w", err)
+		return nil, "", fmt.Errorf("failed to update diagnosis: %w", err)
 	}
 
 	// Audit log - WRITE (do NOT log diagnosis text)
@@ -207,6 +438,8 @@ func (s *WorkbenchService) UpdateDiagnosis(ctx context.Context, noRawat string,
 			"action":      "update_diagnosis",
 			"kd_penyakit": req.KodePenyakit,
 			"status_dx":   req.StatusDx,
+			"prioritas":   req.Prioritas,
+			"warnings":    len(validation.Warnings),
 		},
 		BusinessKey: noRawat,
 		Actor:       actor,
@@ -214,20 +447,35 @@ func (s *WorkbenchService) UpdateDiagnosis(ctx context.Context, noRawat string,
 		Summary:     fmt.Sprintf("Mengubah diagnosa klaim %s: %s", noRawat, req.KodePenyakit),
 	})
 
-	return nil
+	return validation, s.endEdit(ctx, noRawat), nil
 }
 
-// SyncDiagnoses updates all diagnoses for an episode in one batch.
-func (s *WorkbenchService) SyncDiagnoses(ctx context.Context, noRawat string, req entity.DiagnosisSyncRequest, actor audit.Actor, ip string) error {
+// SyncDiagnoses updates all diagnoses for an episode in one batch. The set
+// is validated first and stored with the primary diagnosis at prioritas 1.
+// ifMatch is the medical data version the edit is based on; the new version
+// is returned.
+func (s *WorkbenchService) SyncDiagnoses(ctx context.Context, noRawat string, req entity.DiagnosisSyncRequest, ifMatch string, actor audit.Actor, ip string) (*entity.CodingValidation, string, error) {
+	unlock, err := s.beginEdit(ctx, noRawat, ifMatch, actor)
+	if err != nil {
+		return nil, "", err
+	}
+	defer unlock()
+
+	validation, err := s.validateDiagnoses(ctx, noRawat, req.Diagnoses)
+	if err != nil {
+		return nil, "", err
+	}
+	diagnoses := coding.OrderDiagnoses(req.Diagnoses)
+
 	// 1. Get episode type (Ralan/Ranap) to pass to repository
 	statusLanjut, err := s.indexRepo.GetEpisodeType(ctx, noRawat)
 	if err != nil {
-		return fmt.Errorf("failed to get episode type: %w", err)
+		return nil, "", fmt.Errorf("failed to get episode type: %w", err)
 	}
 
 	// 2. Sync diagnoses
-	if err := s.indexRepo.SyncDiagnoses(ctx, noRawat, req.Diagnoses, statusLanjut); err != nil {
-		return fmt.Errorf("failed to sync diagnoses: %w", err)
+	if err := s.indexRepo.SyncDiagnoses(ctx, noRawat, diagnoses, statusLanjut); err != nil {
+		return nil, "", fmt.Errorf("failed to sync diagnoses: %w", err)
 	}
 
 	// Audit log - WRITE
@@ -243,10 +491,55 @@ func (s *WorkbenchService) SyncDiagnoses(ctx context.Context, noRawat string, re
 		BusinessKey: noRawat,
 		Actor:       actor,
 		IP:          ip,
-		Summary:     fmt.Sprintf("Sinkronisasi diagnosa klaim %s (%d item)", noRawat, len(req.Diagnoses)),
+		Summary:     fmt.Sprintf("Sinkronisasi diagnosa klaim %s (%d item, %d peringatan)", noRawat, len(diagnoses), len(validation.Warnings)),
 	})
 
-	return nil
+	return validation, s.endEdit(ctx, noRawat), nil
+}
+
+// validateDiagnoses runs the coding validator on a complete diagnosis set.
+func (s *WorkbenchService) validateDiagnoses(ctx context.Context, noRawat string, lines []entity.DiagnosisUpdateRequest) (*entity.CodingValidation, error) {
+	validator, err := s.codingValidator(ctx)
+	if err != nil {
+		return nil, err
+	}
+	patient, err := s.indexRepo.GetCodingPatient(ctx, noRawat)
+	if err != nil {
+		return nil, err
+	}
+
+	validation := validator.ValidateDiagnoses(patient, lines)
+	if !validation.Valid {
+		return nil, &entity.CodingError{NoRawat: noRawat, Kind: "diagnosis", Validation: validation}
+	}
+	return validation, nil
+}
+
+// validateProcedures runs the coding validator on a complete procedure set.
+func (s *WorkbenchService) validateProcedures(ctx context.Context, noRawat string, lines []entity.ProcedureUpdateRequest) (*entity.CodingValidation, error) {
+	validator, err := s.codingValidator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	validation := validator.ValidateProcedures(lines)
+	if !validation.Valid {
+		return nil, &entity.CodingError{NoRawat: noRawat, Kind: "procedure", Validation: validation}
+	}
+	return validation, nil
+}
+
+// codingValidator builds the validator from the vedika.coding setting.
+// Without the setting only the built-in ICD rules apply.
+func (s *WorkbenchService) codingValidator(ctx context.Context) (*coding.Validator, error) {
+	cfg, err := s.settingsRepo.GetCodingConfig(ctx)
+	if errors.Is(err, repository.ErrSettingNotFound) {
+		return coding.NewValidator(entity.CodingConfig{}), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return coding.NewValidator(*cfg), nil
 }
 
 // SearchICD10 searches for ICD-10 entries.
@@ -254,10 +547,37 @@ func (s *WorkbenchService) SearchICD10(ctx context.Context, query string) ([]ent
 	return s.indexRepo.SearchICD10(ctx, query)
 }
 
-// UpdateProcedure updates or adds a procedure.
-func (s *WorkbenchService) UpdateProcedure(ctx context.Context, noRawat string, req entity.ProcedureUpdateRequest, actor audit.Actor, ip string) error {
+// UpdateProcedure updates or adds a procedure. ifMatch is the medical data
+// version the edit is based on; the new version is returned.
+func (s *WorkbenchService) UpdateProcedure(ctx context.Context, noRawat string, req entity.ProcedureUpdateRequest, ifMatch string, actor audit.Actor, ip string) (*entity.CodingValidation, string, error) {
+	unlock, err := s.beginEdit(ctx, noRawat, ifMatch, actor)
+	if err != nil {
+		return nil, "", err
+	}
+	defer unlock()
+
+	existing, err := s.indexRepo.GetProcedures(ctx, noRawat)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get procedures: %w", err)
+	}
+
+	// Validate the set as it will be after the upsert
+	req.Kode = coding.Normalize(req.Kode)
+	lines := []entity.ProcedureUpdateRequest{}
+	for _, p := range existing {
+		if coding.Normalize(p.Kode) != req.Kode {
+			lines = append(lines, entity.ProcedureUpdateRequest{Kode: p.Kode, Prioritas: p.Prioritas})
+		}
+	}
+	lines = append(lines, req)
+
+	validation, err := s.validateProcedures(ctx, noRawat, lines)
+	if err != nil {
+		return nil, "", err
+	}
+
 	if err := s.indexRepo.AddProcedure(ctx, noRawat, req); err != nil {
-		return fmt.Errorf("failed to update procedure: %w", err)
+		return nil, "", fmt.Errorf("failed to update procedure: %w", err)
 	}
 
 	// Audit log - WRITE (do NOT log procedure text)
@@ -277,13 +597,27 @@ func (s *WorkbenchService) UpdateProcedure(ctx context.Context, noRawat string,
 		Summary:     fmt.Sprintf("Mengubah prosedur klaim %s: %s", noRawat, req.Kode),
 	})
 
-	return nil
+	return validation, s.endEdit(ctx, noRawat), nil
 }
 
-// SyncProcedures updates all procedures for an episode in one batch.
-func (s *WorkbenchService) SyncProcedures(ctx context.Context, noRawat string, req entity.ProcedureSyncRequest, actor audit.Actor, ip string) error {
-	if err := s.indexRepo.SyncProcedures(ctx, noRawat, req.Procedures); err != nil {
-		return fmt.Errorf("failed to sync procedures: %w", err)
+// SyncProcedures updates all procedures for an episode in one batch. The
+// set is validated first and renumbered from prioritas 1. ifMatch is the
+// medical data version the edit is based on; the new version is returned.
+func (s *WorkbenchService) SyncProcedures(ctx context.Context, noRawat string, req entity.ProcedureSyncRequest, ifMatch string, actor audit.Actor, ip string) (*entity.CodingValidation, string, error) {
+	unlock, err := s.beginEdit(ctx, noRawat, ifMatch, actor)
+	if err != nil {
+		return nil, "", err
+	}
+	defer unlock()
+
+	validation, err := s.validateProcedures(ctx, noRawat, req.Procedures)
+	if err != nil {
+		return nil, "", err
+	}
+	procedures := coding.OrderProcedures(req.Procedures)
+
+	if err := s.indexRepo.SyncProcedures(ctx, noRawat, procedures); err != nil {
+		return nil, "", fmt.Errorf("failed to sync procedures: %w", err)
 	}
 
 	// Audit log - WRITE
@@ -299,10 +633,10 @@ func (s *WorkbenchService) SyncProcedures(ctx context.Context, noRawat string, r
 		BusinessKey: noRawat,
 		Actor:       actor,
 		IP:          ip,
-		Summary:     fmt.Sprintf("Sinkronisasi prosedur klaim %s (%d item)", noRawat, len(req.Procedures)),
+		Summary:     fmt.Sprintf("Sinkronisasi prosedur klaim %s (%d item)", noRawat, len(procedures)),
 	})
 
-	return nil
+	return validation, s.endEdit(ctx, noRawat), nil
 }
 
 // SearchICD9 searches for ICD-9-CM entries.
@@ -312,10 +646,12 @@ func (s *WorkbenchService) SearchICD9(ctx context.Context, query string) ([]enti
 
 // GetResume returns medical resume.
 func (s *WorkbenchService) GetResume(ctx context.Context, noRawat string, actor audit.Actor, ip string) (*entity.MedicalResume, error) {
-	resume, err := s.indexRepo.GetResume(ctx, noRawat)
+	state, err := s.GetMedicalDataState(ctx, noRawat)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get resume: %w", err)
+		return nil, err
 	}
+	resume := state.Resume
+	resume.Version = state.Version
 
 	// Audit log - READ
 	s.auditLogger.LogInsert(audit.InsertParams{
@@ -337,10 +673,17 @@ func (s *WorkbenchService) GetResume(ctx context.Context, noRawat string, actor
 	return resume, nil
 }
 
-// UpdateResume updates medical resume.
-func (s *WorkbenchService) UpdateResume(ctx context.Context, noRawat string, req *entity.MedicalResume, actor audit.Actor, ip string) error {
+// UpdateResume updates medical resume. ifMatch is the medical data version
+// the edit is based on; the new version is returned.
+func (s *WorkbenchService) UpdateResume(ctx context.Context, noRawat string, req *entity.MedicalResume, ifMatch string, actor audit.Actor, ip string) (string, error) {
+	unlock, err := s.beginEdit(ctx, noRawat, ifMatch, actor)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
 	if err := s.indexRepo.UpdateResume(ctx, noRawat, req); err != nil {
-		return fmt.Errorf("failed to update resume: %w", err)
+		return "", fmt.Errorf("failed to update resume: %w", err)
 	}
 
 	// Audit log - WRITE
@@ -359,7 +702,29 @@ func (s *WorkbenchService) UpdateResume(ctx context.Context, noRawat string, req
 		Summary:     fmt.Sprintf("Memperbarui resume medis %s", noRawat),
 	})
 
-	return nil
+	return s.endEdit(ctx, noRawat), nil
+}
+
+// freeLokasiFile returns a storage key for name that no other document of the
+// episode uses, so a different file with the same name is never overwritten.
+// A taken name gets the first 8 hash characters appended, then a counter.
+func (s *WorkbenchService) freeLokasiFile(ctx context.Context, noRawat, name, sha256 string) (string, error) {
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	candidate := name
+	for i := 1; ; i++ {
+		taken, err := s.indexRepo.HasDigitalDocument(ctx, noRawat, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+		candidate = base + "_" + sha256[:8] + ext
+		if i > 1 {
+			candidate = fmt.Sprintf("%s_%s_%d%s", base, sha256[:8], i, ext)
+		}
+	}
 }
 
 // GetMasterDigitalDocs returns document categories.
@@ -367,10 +732,83 @@ func (s *WorkbenchService) GetMasterDigitalDocs(ctx context.Context) ([]entity.I
 	return s.indexRepo.GetMasterDigitalDocs(ctx)
 }
 
-// AddDigitalDocument adds a digital document record.
-func (s *WorkbenchService) AddDigitalDocument(ctx context.Context, noRawat string, kode string, lokasiFile string, actor audit.Actor, ip string) error {
+// UploadDigitalDocument validates an uploaded file against the upload rule
+// of its category, stores it and records it in berkas_digital_perawatan.
+// The file is stored under the legacy key
+// pages/upload/<no_rawat>_<kode>_<filename>, which is also the DB value.
+// Validation failures are returned as *entity.UploadError.
+func (s *WorkbenchService) UploadDigitalDocument(
+	ctx context.Context,
+	noRawat string,
+	kode string,
+	filename string,
+	body io.Reader,
+	size int64,
+	actor audit.Actor,
+	ip string,
+) (string, error) {
+	if err := s.checkEditLock(ctx, noRawat, actor); err != nil {
+		return "", err
+	}
+
+	rule, err := s.documentRepo.GetRule(ctx, kode)
+	if errors.Is(err, repository.ErrDocumentCategoryNotFound) {
+		return "", &entity.UploadError{Code: entity.UploadUnknownCategory, Kode: kode, Message: "Kategori berkas " + kode + " tidak ditemukan"}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	up, err := prepareUpload(rule, filename, body, size)
+	if err != nil {
+		return "", err
+	}
+
+	existing, err := s.documentRepo.FindByHash(ctx, noRawat, up.SHA256)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil {
+		return "", duplicateUploadError(rule, existing.LokasiFile)
+	}
+
+	// Clean noRawat for filename (replace slashes with underscores)
+	cleanNoRawat := strings.ReplaceAll(noRawat, "/", "_")
+	lokasiFile, err := s.freeLokasiFile(ctx, noRawat,
+		fmt.Sprintf("pages/upload/%s_%s_%s", cleanNoRawat, safeName(kode), up.Filename), up.SHA256)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.documentStore.Put(ctx, lokasiFile, bytes.NewReader(up.Data), int64(len(up.Data)), up.ContentType); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrDocumentStorage, err)
+	}
+
 	if err := s.indexRepo.AddDigitalDocument(ctx, noRawat, kode, lokasiFile); err != nil {
-		return fmt.Errorf("failed to add digital document: %w", err)
+		// Do not leave an unreferenced file behind
+		s.documentStore.Delete(ctx, lokasiFile)
+		return "", fmt.Errorf("failed to add digital document: %w", err)
+	}
+
+	err = s.documentRepo.SaveFile(ctx, &entity.DocumentFile{
+		NoRawat:      noRawat,
+		Kode:         kode,
+		LokasiFile:   lokasiFile,
+		SHA256:       up.SHA256,
+		OriginalName: up.OriginalName,
+		ContentType:  up.ContentType,
+		Size:         int64(len(up.Data)),
+		Converted:    up.Converted,
+		UploadedBy:   actor.Username,
+	})
+	if err != nil {
+		s.indexRepo.DeleteDigitalDocument(ctx, noRawat, kode, lokasiFile)
+		s.documentStore.Delete(ctx, lokasiFile)
+		if errors.Is(err, repository.ErrDuplicateDocument) {
+			// Lost a race against an identical upload
+			return "", duplicateUploadError(rule, "")
+		}
+		return "", err
 	}
 
 	// Audit log - WRITE
@@ -381,9 +819,14 @@ func (s *WorkbenchService) AddDigitalDocument(ctx context.Context, noRawat strin
 			PrimaryKey: map[string]string{"no_rawat": noRawat, "kode": kode},
 		},
 		InsertedData: map[string]interface{}{
-			"no_rawat":    noRawat,
-			"kode":        kode,
-			"lokasi_file": lokasiFile,
+			"no_rawat":      noRawat,
+			"kode":          kode,
+			"lokasi_file":   lokasiFile,
+			"original_name": up.OriginalName,
+			"content_type":  up.ContentType,
+			"size":          len(up.Data),
+			"sha256":        up.SHA256,
+			"converted":     up.Converted,
 		},
 		BusinessKey: noRawat,
 		Actor:       actor,
@@ -391,14 +834,35 @@ func (s *WorkbenchService) AddDigitalDocument(ctx context.Context, noRawat strin
 		Summary:     fmt.Sprintf("Mengunggah berkas digital %s kategori %s", noRawat, kode),
 	})
 
-	return nil
+	return lokasiFile, nil
 }
 
-// DeleteDigitalDocument deletes a digital document record.
+// duplicateUploadError rejects content already uploaded for the episode.
+func duplicateUploadError(rule *entity.DocumentRule, existing string) *entity.UploadError {
+	return &entity.UploadError{
+		Code:     entity.UploadDuplicate,
+		Kode:     rule.Kode,
+		Message:  "Berkas yang sama sudah diunggah untuk episode ini",
+		Existing: existing,
+	}
+}
+
+// DeleteDigitalDocument deletes a digital document record and its file.
 func (s *WorkbenchService) DeleteDigitalDocument(ctx context.Context, noRawat string, kode string, lokasiFile string, actor audit.Actor, ip string) error {
+	if err := s.checkEditLock(ctx, noRawat, actor); err != nil {
+		return err
+	}
 	if err := s.indexRepo.DeleteDigitalDocument(ctx, noRawat, kode, lokasiFile); err != nil {
 		return fmt.Errorf("failed to delete digital document: %w", err)
 	}
+	if err := s.documentRepo.DeleteFile(ctx, noRawat, lokasiFile); err != nil {
+		return err
+	}
+
+	// The record is gone; a file already missing from storage is fine
+	if err := s.documentStore.Delete(ctx, lokasiFile); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("%w: %w", ErrDocumentStorage, err)
+	}
 
 	// Audit log - WRITE
 	s.auditLogger.LogDelete(audit.DeleteParams{
@@ -417,7 +881,73 @@ func (s *WorkbenchService) DeleteDigitalDocument(ctx context.Context, noRawat st
 	return nil
 }
 
+// OpenDigitalDocument opens a document file of an episode for download.
+// Only files recorded in berkas_digital_perawatan for noRawat are served.
+func (s *WorkbenchService) OpenDigitalDocument(
+	ctx context.Context,
+	noRawat string,
+	lokasiFile string,
+	actor audit.Actor,
+	ip string,
+) (io.ReadCloser, *storage.ObjectInfo, error) {
+	exists, err := s.indexRepo.HasDigitalDocument(ctx, noRawat, lokasiFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get digital document: %w", err)
+	}
+	if !exists {
+		return nil, nil, repository.ErrDocumentNotFound
+	}
+
+	body, info, err := s.documentStore.Get(ctx, lokasiFile)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, repository.ErrDocumentNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrDocumentStorage, err)
+	}
+
+	// Audit log - READ
+	s.auditLogger.LogInsert(audit.InsertParams{
+		Module: "vedika",
+		Entity: audit.Entity{
+			Table:      "berkas_digital_perawatan",
+			PrimaryKey: map[string]string{"no_rawat": noRawat},
+		},
+		InsertedData: map[string]interface{}{
+			"action":      "download_document",
+			"no_rawat":    noRawat,
+			"lokasi_file": lokasiFile,
+		},
+		BusinessKey: noRawat,
+		Actor:       actor,
+		IP:          ip,
+		Summary:     fmt.Sprintf("Mengunduh berkas digital %s (%s)", noRawat, path.Base(lokasiFile)),
+	})
+
+	return body, info, nil
+}
+
 // GetLegacyWebAppURL returns the base URL for the legacy web application.
 func (s *WorkbenchService) GetLegacyWebAppURL(ctx context.Context) (string, error) {
 	return s.settingsRepo.GetLegacyWebAppURL(ctx)
 }
+
+// LegacyWebAppRoot derives the on-disk legacy webapps directory, relative to
+// the Clinova root, from the legacy_webapp_url setting. The path of the URL
+// names the folder (e.g. "/webapps/" -> "../../webapps/"); it defaults to
+// "webapps" when the URL is empty or invalid so existing setups keep working.
+func LegacyWebAppRoot(baseURL string) string {
+	subFolder := "webapps"
+	if baseURL != "" {
+		if u, err := url.Parse(baseURL); err == nil {
+			subFolder = strings.Trim(u.Path, "/")
+		}
+	}
+
+	// If subFolder is empty, it points to Laragon www root
+	root := "../../"
+	if subFolder != "" {
+		root += subFolder + "/"
+	}
+	return root
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+	"github.com/clinova/simrs/backend/internal/vedika/repository"
+	"github.com/clinova/simrs/backend/pkg/audit"
+)
+
+// fakeIndexRepo holds the status of claims and their history in memory.
+// History is kept in insertion order, as seq orders it in MySQL; every
+// entry gets the same created_at, like changes within one second.
+type fakeIndexRepo struct {
+	repository.IndexRepository
+	status  map[string]entity.ClaimStatus
+	history []entity.StatusHistoryEntry
+	files   map[string]bool
+}
+
+func (r *fakeIndexRepo) HasDigitalDocument(ctx context.Context, noRawat, lokasiFile string) (bool, error) {
+	return r.files[lokasiFile], nil
+}
+
+func (r *fakeIndexRepo) GetEpisodeStatus(ctx context.Context, noRawat string) (entity.ClaimStatus, error) {
+	if status, ok := r.status[noRawat]; ok {
+		return status, nil
+	}
+	return entity.StatusRencana, nil
+}
+
+func (r *fakeIndexRepo) UpdateClaimStatus(ctx context.Context, noRawat string, from, status entity.ClaimStatus, username, catatan string) error {
+	r.status[noRawat] = status
+	r.history = append(r.history, entity.StatusHistoryEntry{
+		ID:        fmt.Sprintf("h%d", len(r.history)+1),
+		NoRawat:   noRawat,
+		OldStatus: from,
+		NewStatus: status,
+		Username:  username,
+		Catatan:   catatan,
+		CreatedAt: fakeHistoryTime,
+	})
+	return nil
+}
+
+var fakeHistoryTime = time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+
+func (r *fakeIndexRepo) GetStatusHistory(ctx context.Context, noRawat string) ([]entity.StatusHistoryEntry, error) {
+	history := []entity.StatusHistoryEntry{}
+	for _, h := range r.history {
+		if h.NoRawat == noRawat {
+			history = append(history, h)
+		}
+	}
+	return history, nil
+}
+
+// fakeFeedbackRepo reports a fixed number of open notes on every SEP.
+type fakeFeedbackRepo struct {
+	repository.FeedbackRepository
+	open int
+}
+
+func (r *fakeFeedbackRepo) GetNoSEP(ctx context.Context, noRawat string) (string, error) {
+	return "0301R0010126V000001", nil
+}
+
+func (r *fakeFeedbackRepo) CountOpenBySEP(ctx context.Context, noSEP string) (int, error) {
+	return r.open, nil
+}
+
+// fakeCompleteness returns a fixed report and counts evaluations.
+type fakeCompleteness struct {
+	report *entity.CompletenessReport
+	calls  int
+}
+
+func (f *fakeCompleteness) EvaluateCompleteness(ctx context.Context, noRawat string) (*entity.CompletenessReport, error) {
+	f.calls++
+	return f.report, nil
+}
+
+func newTestAuditLogger(t *testing.T) *audit.Logger {
+	t.Helper()
+	logger, err := audit.NewLogger(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger
+}
+
+func TestUpdateClaimStatusRequiresCompletenessForLengkap(t *testing.T) {
+	ctx := context.Background()
+	const noRawat = "2026/01/01/000001"
+	granted := map[string]bool{entity.PermUpdateStatus: true}
+	actor := audit.Actor{UserID: "u1", Username: "coder1"}
+
+	incomplete := &entity.CompletenessReport{
+		NoRawat: noRawat,
+		Items: []entity.ChecklistItem{
+			{Key: entity.CheckSEP, Mandatory: true, Passed: true},
+			{Key: entity.CheckResume, Mandatory: true, Passed: false, Message: "Resume belum ditandatangani DPJP"},
+			{Key: entity.CheckSPRI, Mandatory: false, Passed: false},
+		},
+	}
+	index := &fakeIndexRepo{status: map[string]entity.ClaimStatus{noRawat: entity.StatusPengajuan}}
+	checker := &fakeCompleteness{report: incomplete}
+	s := NewWorkbenchService(index, nil, nil, nil, nil, newTestAuditLogger(t))
+	s.SetCompletenessChecker(checker)
+
+	err := s.UpdateClaimStatus(ctx, noRawat, entity.StatusUpdateRequest{Status: entity.StatusLengkap}, granted, actor, "")
+	var transitionErr *entity.TransitionError
+	if !errors.As(err, &transitionErr) {
+		t.Fatalf("incomplete claim: got %v, want TransitionError", err)
+	}
+	if len(transitionErr.Incomplete) != 1 || transitionErr.Incomplete[0].Key != entity.CheckResume {
+		t.Fatalf("incomplete items %+v, want only the resume", transitionErr.Incomplete)
+	}
+	if index.status[noRawat] != entity.StatusPengajuan {
+		t.Fatalf("status changed to %s despite the failed checklist", index.status[noRawat])
+	}
+
+	// Other transitions do not run the checklist
+	if err := s.UpdateClaimStatus(ctx, noRawat, entity.StatusUpdateRequest{Status: entity.StatusPerbaikan}, granted, actor, ""); err != nil {
+		t.Fatalf("move to Perbaikan: %v", err)
+	}
+	if checker.calls != 1 {
+		t.Fatalf("checklist evaluated %d times, want 1", checker.calls)
+	}
+
+	index.status[noRawat] = entity.StatusPengajuan
+	checker.report = &entity.CompletenessReport{NoRawat: noRawat, Complete: true}
+	if err := s.UpdateClaimStatus(ctx, noRawat, entity.StatusUpdateRequest{Status: entity.StatusLengkap}, granted, actor, ""); err != nil {
+		t.Fatalf("complete claim: %v", err)
+	}
+	if index.status[noRawat] != entity.StatusLengkap {
+		t.Fatalf("status %s, want Lengkap", index.status[noRawat])
+	}
+}
+
+func TestGetStatusHistoryKeepsChangeOrder(t *testing.T) {
+	ctx := context.Background()
+	const noRawat = "2026/01/01/000001"
+	granted := map[string]bool{entity.PermUpdateStatus: true}
+	actor := audit.Actor{UserID: "u1", Username: "coder1"}
+
+	index := &fakeIndexRepo{status: map[string]entity.ClaimStatus{}}
+	s := NewWorkbenchService(index, nil, &fakeFeedbackRepo{}, nil, nil, newTestAuditLogger(t))
+
+	// Several changes within the same second
+	steps := []entity.ClaimStatus{
+		entity.StatusPengajuan,
+		entity.StatusPerbaikan,
+		entity.StatusPengajuan,
+		entity.StatusPerbaikan,
+		entity.StatusLengkap,
+	}
+	for _, status := range steps {
+		if err := s.UpdateClaimStatus(ctx, noRawat, entity.StatusUpdateRequest{Status: status}, granted, actor, ""); err != nil {
+			t.Fatalf("move to %s: %v", status, err)
+		}
+	}
+
+	timeline, err := s.GetStatusHistory(ctx, noRawat, actor, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if timeline.CurrentStatus != entity.StatusLengkap {
+		t.Fatalf("current status %s, want Lengkap", timeline.CurrentStatus)
+	}
+	if timeline.PerbaikanRounds != 2 {
+		t.Fatalf("perbaikan rounds %d, want 2", timeline.PerbaikanRounds)
+	}
+	if len(timeline.History) != len(steps) {
+		t.Fatalf("got %d history entries, want %d", len(timeline.History), len(steps))
+	}
+
+	// Each entry starts where the previous one ended
+	previous := entity.StatusRencana
+	for i, h := range timeline.History {
+		if h.OldStatus != previous || h.NewStatus != steps[i] {
+			t.Fatalf("entry %d: %s -> %s, want %s -> %s", i, h.OldStatus, h.NewStatus, previous, steps[i])
+		}
+		previous = h.NewStatus
+	}
+}
+
+func TestFreeLokasiFileSkipsTakenNames(t *testing.T) {
+	const sha = "0123456789abcdef"
+	repo := &fakeIndexRepo{files: map[string]bool{}}
+	s := &WorkbenchService{indexRepo: repo}
+	ctx := context.Background()
+
+	want := []string{
+		"pages/upload/x_sep.pdf",
+		"pages/upload/x_sep_01234567.pdf",
+		"pages/upload/x_sep_01234567_2.pdf",
+		"pages/upload/x_sep_01234567_3.pdf",
+	}
+	for i, w := range want {
+		got, err := s.freeLokasiFile(ctx, "2026/01/01/000001", "pages/upload/x_sep.pdf", sha)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != w {
+			t.Fatalf("upload %d: got %q, want %q", i+1, got, w)
+		}
+		repo.files[got] = true
+	}
+}
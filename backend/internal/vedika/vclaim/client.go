@@ -0,0 +1,236 @@
+// Package vclaim implements the BPJS Kesehatan VClaim REST bridging protocol.
+//
+// Every request carries X-cons-id, X-timestamp, X-signature and user_key
+// headers. Responses are {"metaData": {...}, "response": "..."} where the
+// response field is AES-256-CBC encrypted and lz-string compressed.
+package vclaim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SettingsModule is the mera_settings module holding VClaim configuration.
+const SettingsModule = "bpjs.vclaim"
+
+// CodeNotFound is the metaData code VClaim returns when no data matches.
+const CodeNotFound = "201"
+
+var (
+	// ErrNotConfigured indicates the VClaim settings are incomplete.
+	ErrNotConfigured = errors.New("vclaim bridging is not configured")
+
+	// ErrNotFound indicates VClaim has no data for the requested key.
+	ErrNotFound = errors.New("vclaim data not found")
+)
+
+// APIError is a non-200 metaData block returned by VClaim.
+type APIError struct {
+	Path    string
+	Code    string
+	Message string
+}
+
+// Error implements error.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("vclaim %s failed (%s): %s", e.Path, e.Code, e.Message)
+}
+
+// Code is a metaData code; VClaim sends it as a string or a number.
+type Code string
+
+// UnmarshalJSON accepts both "200" and 200.
+func (c *Code) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*c = Code(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	*c = Code(n.String())
+	return nil
+}
+
+// MetaData is the status block of every VClaim response.
+type MetaData struct {
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+}
+
+type envelope struct {
+	MetaData MetaData        `json:"metaData"`
+	Response json.RawMessage `json:"response"`
+}
+
+// Config holds VClaim connection credentials.
+type Config struct {
+	BaseURL   string // e.g. https://apijkn.bpjs-kesehatan.go.id/vclaim-rest
+	ConsID    string
+	SecretKey string
+	UserKey   string
+	Timeout   time.Duration
+}
+
+// ConfigFromSettings builds a Config from the bpjs.vclaim settings
+// (base_url, consumer_id, secret_key, user_key).
+func ConfigFromSettings(settings map[string]string) (Config, error) {
+	cfg := Config{
+		BaseURL:   strings.TrimRight(strings.TrimSpace(settings["base_url"]), "/"),
+		ConsID:    strings.TrimSpace(settings["consumer_id"]),
+		SecretKey: strings.TrimSpace(settings["secret_key"]),
+		UserKey:   strings.TrimSpace(settings["user_key"]),
+		Timeout:   30 * time.Second,
+	}
+	if cfg.BaseURL == "" || cfg.ConsID == "" || cfg.SecretKey == "" || cfg.UserKey == "" {
+		return cfg, ErrNotConfigured
+	}
+	return cfg, nil
+}
+
+// Client calls the VClaim REST service.
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewClient creates a new VClaim client.
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.BaseURL == "" || cfg.ConsID == "" || cfg.SecretKey == "" {
+		return nil, ErrNotConfigured
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	return &Client{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		now:        time.Now,
+	}, nil
+}
+
+// GetSEP looks up a SEP by its number.
+func (c *Client) GetSEP(ctx context.Context, noSEP string) (*SEP, error) {
+	var sep SEP
+	if err := c.get(ctx, "SEP/"+url.PathEscape(noSEP), &sep); err != nil {
+		return nil, err
+	}
+	return &sep, nil
+}
+
+// GetPesertaByKartu looks up a member by card number on the given SEP date (YYYY-MM-DD).
+func (c *Client) GetPesertaByKartu(ctx context.Context, noKartu, tglSEP string) (*Peserta, error) {
+	return c.peserta(ctx, "nokartu", noKartu, tglSEP)
+}
+
+// GetPesertaByNIK looks up a member by NIK on the given SEP date (YYYY-MM-DD).
+func (c *Client) GetPesertaByNIK(ctx context.Context, nik, tglSEP string) (*Peserta, error) {
+	return c.peserta(ctx, "nik", nik, tglSEP)
+}
+
+// GetRujukan looks up a referral by number. fromRS selects referrals issued
+// by hospitals (FKRTL) instead of primary care (FKTP).
+func (c *Client) GetRujukan(ctx context.Context, noRujukan string, fromRS bool) (*Rujukan, error) {
+	path := "Rujukan/" + url.PathEscape(noRujukan)
+	if fromRS {
+		path = "Rujukan/RS/" + url.PathEscape(noRujukan)
+	}
+
+	var resp struct {
+		Rujukan Rujukan `json:"rujukan"`
+	}
+	if err := c.get(ctx, path, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Rujukan, nil
+}
+
+func (c *Client) peserta(ctx context.Context, by, value, tglSEP string) (*Peserta, error) {
+	if tglSEP == "" {
+		tglSEP = c.now().Format("2006-01-02")
+	}
+	path := fmt.Sprintf("Peserta/%s/%s/tglSEP/%s", by, url.PathEscape(value), url.PathEscape(tglSEP))
+
+	var resp struct {
+		Peserta Peserta `json:"peserta"`
+	}
+	if err := c.get(ctx, path, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Peserta, nil
+}
+
+// get signs the request, calls path and decrypts the response into out.
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	timestamp := strconv.FormatInt(c.now().UTC().Unix(), 10)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.BaseURL+"/"+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build %s request: %w", path, err)
+	}
+	httpReq.Header.Set("X-cons-id", c.cfg.ConsID)
+	httpReq.Header.Set("X-timestamp", timestamp)
+	httpReq.Header.Set("X-signature", Signature(c.cfg.ConsID, c.cfg.SecretKey, timestamp))
+	httpReq.Header.Set("user_key", c.cfg.UserKey)
+	httpReq.Header.Set("Accept", "application/json")
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("vclaim %s request failed: %w", path, err)
+	}
+	defer httpResp.Body.Close()
+
+	raw, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", path, err)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		if httpResp.StatusCode != http.StatusOK {
+			return &APIError{Path: path, Code: strconv.Itoa(httpResp.StatusCode), Message: strings.TrimSpace(string(raw))}
+		}
+		return fmt.Errorf("failed to decode %s response: %w", path, err)
+	}
+
+	switch env.MetaData.Code {
+	case "200":
+	case CodeNotFound:
+		return fmt.Errorf("%w: %s", ErrNotFound, env.MetaData.Message)
+	default:
+		return &APIError{Path: path, Code: string(env.MetaData.Code), Message: env.MetaData.Message}
+	}
+
+	if out == nil || len(env.Response) == 0 || string(env.Response) == "null" {
+		return nil
+	}
+
+	// The response field is an encrypted string; some services return plain JSON
+	var encrypted string
+	if err := json.Unmarshal(env.Response, &encrypted); err != nil {
+		if err := json.Unmarshal(env.Response, out); err != nil {
+			return fmt.Errorf("failed to decode %s response: %w", path, err)
+		}
+		return nil
+	}
+
+	plain, err := DecryptResponse(c.cfg.ConsID, c.cfg.SecretKey, timestamp, encrypted)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt %s response: %w", path, err)
+	}
+	if err := json.Unmarshal(plain, out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", path, err)
+	}
+	return nil
+}
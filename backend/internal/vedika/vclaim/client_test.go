@@ -0,0 +1,84 @@
+package vclaim_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/clinova/simrs/backend/internal/vedika/vclaim"
+	"github.com/clinova/simrs/backend/internal/vedika/vclaim/vclaimtest"
+)
+
+func newTestServer(t *testing.T) *vclaimtest.Server {
+	t.Helper()
+	srv := vclaimtest.NewServer("1234", "secret", "userkey")
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClientLookups(t *testing.T) {
+	srv := newTestServer(t)
+	srv.AddSEP(vclaim.SEP{NoSEP: "0301R0011120V000001", Diagnosa: "A09 - Diarrhoea", Peserta: vclaim.SEPPeserta{Nama: "BUDI"}})
+	srv.AddPeserta(vclaim.Peserta{NoKartu: "0001234567890", NIK: "3301010101010001", Nama: "BUDI"})
+	srv.AddRujukan(vclaim.Rujukan{NoKunjungan: "030107010217Y001465", Keluhan: "nyeri perut"}, false)
+
+	client, err := vclaim.NewClient(srv.Config())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	ctx := context.Background()
+
+	sep, err := client.GetSEP(ctx, "0301R0011120V000001")
+	if err != nil {
+		t.Fatalf("GetSEP() error = %v", err)
+	}
+	if sep.Diagnosa != "A09 - Diarrhoea" || sep.Peserta.Nama != "BUDI" {
+		t.Fatalf("GetSEP() = %+v", sep)
+	}
+
+	for name, lookup := range map[string]func() (*vclaim.Peserta, error){
+		"kartu": func() (*vclaim.Peserta, error) { return client.GetPesertaByKartu(ctx, "0001234567890", "2026-01-02") },
+		"nik":   func() (*vclaim.Peserta, error) { return client.GetPesertaByNIK(ctx, "3301010101010001", "") },
+	} {
+		p, err := lookup()
+		if err != nil || p.Nama != "BUDI" {
+			t.Fatalf("peserta by %s = %+v, %v", name, p, err)
+		}
+	}
+
+	rujukan, err := client.GetRujukan(ctx, "030107010217Y001465", false)
+	if err != nil || rujukan.Keluhan != "nyeri perut" {
+		t.Fatalf("GetRujukan() = %+v, %v", rujukan, err)
+	}
+	if _, err := client.GetRujukan(ctx, "030107010217Y001465", true); !errors.Is(err, vclaim.ErrNotFound) {
+		t.Fatalf("GetRujukan(fromRS) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestClientNotFound(t *testing.T) {
+	srv := newTestServer(t)
+	client, _ := vclaim.NewClient(srv.Config())
+
+	if _, err := client.GetSEP(context.Background(), "UNKNOWN"); !errors.Is(err, vclaim.ErrNotFound) {
+		t.Fatalf("GetSEP() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestClientBadCredentials(t *testing.T) {
+	srv := newTestServer(t)
+	cfg := srv.Config()
+	cfg.SecretKey = "wrong"
+	client, _ := vclaim.NewClient(cfg)
+
+	_, err := client.GetSEP(context.Background(), "0301R0011120V000001")
+	var apiErr *vclaim.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "401" {
+		t.Fatalf("GetSEP() error = %v, want APIError 401", err)
+	}
+}
+
+func TestNewClientRequiresCredentials(t *testing.T) {
+	if _, err := vclaim.NewClient(vclaim.Config{BaseURL: "http://localhost"}); !errors.Is(err, vclaim.ErrNotConfigured) {
+		t.Fatalf("NewClient() error = %v, want ErrNotConfigured", err)
+	}
+}
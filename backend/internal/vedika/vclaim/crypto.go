@@ -0,0 +1,77 @@
+package vclaim
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+
+	"github.com/clinova/simrs/backend/pkg/lzstring"
+)
+
+// ErrDecrypt indicates a response payload could not be decrypted.
+var ErrDecrypt = errors.New("failed to decrypt vclaim response")
+
+// Signature computes the X-signature header:
+// base64(HMAC-SHA256(consID + "&" + timestamp, secretKey)).
+func Signature(consID, secretKey, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(secretKey))
+	mac.Write([]byte(consID + "&" + timestamp))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// DecryptResponse opens an encrypted "response" field. The key is
+// sha256(consID + secretKey + timestamp), the IV its first 16 bytes, and the
+// AES-256-CBC plaintext is lz-string compressed (EncodedURIComponent).
+func DecryptResponse(consID, secretKey, timestamp, payload string) ([]byte, error) {
+	key, iv := responseKey(consID, secretKey, timestamp)
+
+	encrypted, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil || len(encrypted) == 0 || len(encrypted)%aes.BlockSize != 0 {
+		return nil, ErrDecrypt
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	decrypted := make([]byte, len(encrypted))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, encrypted)
+
+	n := int(decrypted[len(decrypted)-1])
+	if n == 0 || n > aes.BlockSize || !bytes.HasSuffix(decrypted, bytes.Repeat([]byte{byte(n)}, n)) {
+		return nil, ErrDecrypt
+	}
+
+	plain, err := lzstring.DecompressFromEncodedURIComponent(string(decrypted[:len(decrypted)-n]))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return []byte(plain), nil
+}
+
+// EncryptResponse is the inverse of DecryptResponse, as done by the BPJS gateway.
+func EncryptResponse(consID, secretKey, timestamp string, plain []byte) (string, error) {
+	key, iv := responseKey(consID, secretKey, timestamp)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	compressed := []byte(lzstring.CompressToEncodedURIComponent(string(plain)))
+	n := aes.BlockSize - len(compressed)%aes.BlockSize
+	compressed = append(compressed, bytes.Repeat([]byte{byte(n)}, n)...)
+
+	encrypted := make([]byte, len(compressed))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, compressed)
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+func responseKey(consID, secretKey, timestamp string) ([]byte, []byte) {
+	sum := sha256.Sum256([]byte(consID + secretKey + timestamp))
+	return sum[:], sum[:aes.BlockSize]
+}
@@ -0,0 +1,77 @@
+package vclaim
+
+import (
+	"errors"
+	"testing"
+)
+
+const (
+	testConsID    = "1234"
+	testSecretKey = "secret"
+	testTimestamp = "1700000000"
+)
+
+func TestSignature(t *testing.T) {
+	// base64(HMAC-SHA256("1234&1700000000", "secret"))
+	want := "6np+GvYzQ5NMpW0AaYXiFr8TmI4wq6h0Bf+MDiAriiU="
+	if got := Signature(testConsID, testSecretKey, testTimestamp); got != want {
+		t.Fatalf("Signature() = %q, want %q", got, want)
+	}
+}
+
+func TestDecryptResponse(t *testing.T) {
+	// AES-256-CBC with key sha256("1234secret1700000000") over the
+	// lz-string of the JSON below, produced outside this package
+	payload := "9FjK9Sg+hG2q3ZEMuMq3roBjoObEjGojGbx7kiuobi/l27S4eZMu1QCsPMBX9jzNdz6aMqMuqeX78FXxrlOkZOBOzwf+LXIaLU1zYi4jE40hShTF8ENB6B/uzqGqQ8Wl"
+	want := `{"noSep":"0301R0011120V000001","peserta":{"nama":"BUDI"}}`
+
+	got, err := DecryptResponse(testConsID, testSecretKey, testTimestamp, payload)
+	if err != nil {
+		t.Fatalf("DecryptResponse() error = %v", err)
+	}
+	if string(got) != want {
+		t.Fatalf("DecryptResponse() = %s, want %s", got, want)
+	}
+}
+
+func TestEncryptDecryptResponseRoundTrip(t *testing.T) {
+	for _, plain := range []string{
+		`{}`,
+		`{"peserta":{"nama":"SITI NURHALIZA","noKartu":"0001234567890"}}`,
+		`{"catatan":"pasien rujukan — kontrol ulang ✓"}`,
+	} {
+		encrypted, err := EncryptResponse(testConsID, testSecretKey, testTimestamp, []byte(plain))
+		if err != nil {
+			t.Fatalf("EncryptResponse() error = %v", err)
+		}
+		got, err := DecryptResponse(testConsID, testSecretKey, testTimestamp, encrypted)
+		if err != nil {
+			t.Fatalf("DecryptResponse() error = %v", err)
+		}
+		if string(got) != plain {
+			t.Fatalf("DecryptResponse() = %s, want %s", got, plain)
+		}
+	}
+}
+
+func TestDecryptResponseRejectsBadInput(t *testing.T) {
+	encrypted, _ := EncryptResponse(testConsID, testSecretKey, testTimestamp, []byte(`{"ok":true}`))
+
+	tests := []struct {
+		name      string
+		timestamp string
+		payload   string
+	}{
+		{"other timestamp", "1700000001", encrypted},
+		{"not base64", testTimestamp, "not*base64"},
+		{"empty", testTimestamp, ""},
+		{"partial block", testTimestamp, "AAAA"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecryptResponse(testConsID, testSecretKey, tt.timestamp, tt.payload); !errors.Is(err, ErrDecrypt) {
+				t.Fatalf("DecryptResponse() error = %v, want ErrDecrypt", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package vclaim
+
+import (
+	"strings"
+
+	"github.com/clinova/simrs/backend/internal/vedika/entity"
+)
+
+// KodeNama is the {kode, nama} pair VClaim uses for reference data.
+type KodeNama struct {
+	Kode string `json:"kode"`
+	Nama string `json:"nama"`
+}
+
+// KodeKeterangan is the {kode, keterangan} pair used in peserta data.
+type KodeKeterangan struct {
+	Kode       string `json:"kode"`
+	Keterangan string `json:"keterangan"`
+}
+
+// Peserta is a BPJS member as returned by the Peserta and Rujukan services.
+type Peserta struct {
+	NoKartu       string         `json:"noKartu"`
+	NIK           string         `json:"nik"`
+	Nama          string         `json:"nama"`
+	Pisa          string         `json:"pisa"`
+	Sex           string         `json:"sex"`
+	TglLahir      string         `json:"tglLahir"`
+	TglCetakKartu string         `json:"tglCetakKartu"`
+	TglTAT        string         `json:"tglTAT"`
+	TglTMT        string         `json:"tglTMT"`
+	HakKelas      KodeKeterangan `json:"hakKelas"`
+	JenisPeserta  KodeKeterangan `json:"jenisPeserta"`
+	StatusPeserta KodeKeterangan `json:"statusPeserta"`
+	MR            struct {
+		NoMR      string `json:"noMR"`
+		NoTelepon string `json:"noTelepon"`
+	} `json:"mr"`
+	ProvUmum struct {
+		KdProvider string `json:"kdProvider"`
+		NmProvider string `json:"nmProvider"`
+	} `json:"provUmum"`
+	COB struct {
+		NmAsuransi string `json:"nmAsuransi"`
+		NoAsuransi string `json:"noAsuransi"`
+	} `json:"cob"`
+}
+
+// SEPPeserta is the peserta block embedded in a SEP.
+type SEPPeserta struct {
+	NoKartu    string `json:"noKartu"`
+	Nama       string `json:"nama"`
+	TglLahir   string `json:"tglLahir"`
+	Kelamin    string `json:"kelamin"`
+	JnsPeserta string `json:"jnsPeserta"`
+	HakKelas   string `json:"hakKelas"`
+	NoMR       string `json:"noMr"`
+	Asuransi   string `json:"asuransi"`
+}
+
+// SEP is a Surat Eligibilitas Peserta as returned by GET SEP/{noSEP}.
+type SEP struct {
+	NoSEP         string     `json:"noSep"`
+	TglSEP        string     `json:"tglSep"`
+	JnsPelayanan  string     `json:"jnsPelayanan"`
+	KelasRawat    string     `json:"kelasRawat"`
+	Diagnosa      string     `json:"diagnosa"`
+	NoRujukan     string     `json:"noRujukan"`
+	Poli          string     `json:"poli"`
+	PoliEksekutif string     `json:"poliEksekutif"`
+	Catatan       string     `json:"catatan"`
+	Penjamin      string     `json:"penjamin"`
+	COB           string     `json:"cob"`
+	Peserta       SEPPeserta `json:"peserta"`
+	DPJP          struct {
+		KdDPJP string `json:"kdDPJP"`
+		NmDPJP string `json:"nmDPJP"`
+	} `json:"dpjp"`
+	KlsRawat struct {
+		KlsRawatHak  string `json:"klsRawatHak"`
+		KlsRawatNaik string `json:"klsRawatNaik"`
+	} `json:"klsRawat"`
+}
+
+// Rujukan is a referral as returned by the Rujukan services.
+type Rujukan struct {
+	NoKunjungan  string   `json:"noKunjungan"`
+	TglKunjungan string   `json:"tglKunjungan"`
+	Keluhan      string   `json:"keluhan"`
+	Diagnosa     KodeNama `json:"diagnosa"`
+	Pelayanan    KodeNama `json:"pelayanan"`
+	PoliRujukan  KodeNama `json:"poliRujukan"`
+	ProvPerujuk  KodeNama `json:"provPerujuk"`
+	Peserta      Peserta  `json:"peserta"`
+}
+
+// ToSEPDetail maps a live SEP onto the claim detail SEP section.
+// Fields only stored locally (no_telp, prb, tgl_rujukan, ...) stay empty.
+func (s *SEP) ToSEPDetail() *entity.SEPDetail {
+	kelasRawat := s.KelasRawat
+	if kelasRawat == "" {
+		kelasRawat = s.KlsRawat.KlsRawatHak
+	}
+
+	jenisKelamin := s.Peserta.Kelamin
+	if strings.HasPrefix(strings.ToUpper(jenisKelamin), "L") {
+		jenisKelamin = "L"
+	} else if strings.HasPrefix(strings.ToUpper(jenisKelamin), "P") {
+		jenisKelamin = "P"
+	}
+
+	return &entity.SEPDetail{
+		NoSEP:          s.NoSEP,
+		TglSEP:         s.TglSEP,
+		NoKartu:        s.Peserta.NoKartu,
+		NoRM:           s.Peserta.NoMR,
+		NamaPeserta:    s.Peserta.Nama,
+		Peserta:        s.Peserta.JnsPeserta,
+		TglLahir:       s.Peserta.TglLahir,
+		JenisKelamin:   jenisKelamin,
+		JenisPelayanan: s.JnsPelayanan,
+		KelasRawat:     kelasRawat,
+		KelasHak:       s.Peserta.HakKelas,
+		PoliTujuan:     s.Poli,
+		DPJP:           s.DPJP.NmDPJP,
+		DiagnosaAwal:   s.Diagnosa,
+		Catatan:        s.Catatan,
+		COB:            s.COB,
+		Live:           true,
+	}
+}
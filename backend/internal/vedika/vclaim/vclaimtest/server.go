@@ -0,0 +1,160 @@
+// Package vclaimtest provides an in-process fake BPJS VClaim REST service.
+//
+// It checks the signature headers, encrypts responses the way the BPJS
+// gateway does and serves SEP, peserta and rujukan data kept in memory:
+//
+//	srv := vclaimtest.NewServer("1234", "secret", "userkey")
+//	defer srv.Close()
+//	srv.AddSEP(vclaim.SEP{NoSEP: "0301R0011120V000001"})
+//	client, _ := vclaim.NewClient(srv.Config())
+package vclaimtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/clinova/simrs/backend/internal/vedika/vclaim"
+)
+
+// Server is a fake VClaim REST endpoint.
+type Server struct {
+	URL string // base URL to configure as base_url
+
+	consID    string
+	secretKey string
+	userKey   string
+
+	srv     *httptest.Server
+	mu      sync.Mutex
+	seps    map[string]vclaim.SEP
+	peserta map[string]vclaim.Peserta // by noKartu and by NIK
+	rujukan map[string]vclaim.Rujukan // by "FKTP/" or "RS/" + noKunjungan
+}
+
+// NewServer starts a fake VClaim server accepting the given credentials.
+func NewServer(consID, secretKey, userKey string) *Server {
+	s := &Server{
+		consID:    consID,
+		secretKey: secretKey,
+		userKey:   userKey,
+		seps:      make(map[string]vclaim.SEP),
+		peserta:   make(map[string]vclaim.Peserta),
+		rujukan:   make(map[string]vclaim.Rujukan),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.handle))
+	s.URL = s.srv.URL + "/vclaim-rest"
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Config returns a client configuration pointing at the server.
+func (s *Server) Config() vclaim.Config {
+	return vclaim.Config{BaseURL: s.URL, ConsID: s.consID, SecretKey: s.secretKey, UserKey: s.userKey}
+}
+
+// AddSEP stores a SEP served by GET SEP/{noSep}.
+func (s *Server) AddSEP(sep vclaim.SEP) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seps[sep.NoSEP] = sep
+}
+
+// AddPeserta stores a member served by the Peserta lookups.
+func (s *Server) AddPeserta(p vclaim.Peserta) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p.NoKartu != "" {
+		s.peserta["nokartu/"+p.NoKartu] = p
+	}
+	if p.NIK != "" {
+		s.peserta["nik/"+p.NIK] = p
+	}
+}
+
+// AddRujukan stores a referral; fromRS selects the Rujukan/RS service.
+func (s *Server) AddRujukan(r vclaim.Rujukan, fromRS bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rujukan[rujukanKey(r.NoKunjungan, fromRS)] = r
+}
+
+func rujukanKey(noRujukan string, fromRS bool) string {
+	if fromRS {
+		return "RS/" + noRujukan
+	}
+	return "FKTP/" + noRujukan
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	timestamp := r.Header.Get("X-timestamp")
+	if r.Header.Get("X-cons-id") != s.consID ||
+		r.Header.Get("user_key") != s.userKey ||
+		r.Header.Get("X-signature") != vclaim.Signature(s.consID, s.secretKey, timestamp) {
+		s.reply(w, timestamp, "401", "Authentication failed", nil)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/vclaim-rest/")
+	parts := strings.Split(path, "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case len(parts) == 2 && parts[0] == "SEP":
+		if sep, ok := s.seps[parts[1]]; ok {
+			s.reply(w, timestamp, "200", "OK", sep)
+			return
+		}
+		s.reply(w, timestamp, vclaim.CodeNotFound, "Data SEP tidak ditemukan", nil)
+
+	case len(parts) == 5 && parts[0] == "Peserta" && parts[3] == "tglSEP":
+		if p, ok := s.peserta[parts[1]+"/"+parts[2]]; ok {
+			s.reply(w, timestamp, "200", "OK", map[string]interface{}{"peserta": p})
+			return
+		}
+		s.reply(w, timestamp, vclaim.CodeNotFound, "Data peserta tidak ditemukan", nil)
+
+	case len(parts) == 2 && parts[0] == "Rujukan", len(parts) == 3 && parts[0] == "Rujukan" && parts[1] == "RS":
+		fromRS := len(parts) == 3
+		if rujukan, ok := s.rujukan[rujukanKey(parts[len(parts)-1], fromRS)]; ok {
+			s.reply(w, timestamp, "200", "OK", map[string]interface{}{"rujukan": rujukan})
+			return
+		}
+		s.reply(w, timestamp, vclaim.CodeNotFound, "Data rujukan tidak ditemukan", nil)
+
+	default:
+		s.reply(w, timestamp, "404", "Service not found", nil)
+	}
+}
+
+// reply writes the metaData envelope, encrypting the response when present.
+func (s *Server) reply(w http.ResponseWriter, timestamp, code, message string, response interface{}) {
+	body := map[string]interface{}{
+		"metaData": map[string]string{"code": code, "message": message},
+		"response": nil,
+	}
+	if response != nil {
+		plain, err := json.Marshal(response)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		encrypted, err := vclaim.EncryptResponse(s.consID, s.secretKey, timestamp, plain)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		body["response"] = encrypted
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
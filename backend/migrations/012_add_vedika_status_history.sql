@@ -0,0 +1,28 @@
+-- ============================================
+-- Migration: 012_add_vedika_status_history
+-- Purpose: Persistent per-claim status history for Vedika
+-- ============================================
+-- mlite_vedika only keeps the latest status (upsert), so every status
+-- change is appended here in the same transaction as the upsert.
+-- seq orders the timeline: created_at has second resolution and bulk
+-- updates routinely write several rows per claim within one second.
+-- ============================================
+
+SET NAMES utf8mb4;
+
+CREATE TABLE IF NOT EXISTS mera_vedika_status_history (
+    id CHAR(36) NOT NULL,
+    seq BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    no_rawat VARCHAR(50) NOT NULL,
+    nosep VARCHAR(50) NOT NULL DEFAULT '',
+    old_status VARCHAR(20) NOT NULL,
+    new_status VARCHAR(20) NOT NULL,
+    username VARCHAR(100) NOT NULL,
+    catatan TEXT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (id),
+    UNIQUE KEY uk_mera_vedika_status_history_seq (seq),
+    INDEX idx_mera_vedika_status_history_no_rawat (no_rawat, created_at),
+    INDEX idx_mera_vedika_status_history_new_status (new_status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,31 @@
+-- ============================================
+-- Migration: 013_add_vedika_feedback_threading
+-- Purpose: Reply threading and resolved flag for Vedika feedback notes
+-- ============================================
+-- Root notes have parent_id NULL and carry the resolved flag.
+-- Replies point at their root note. A claim cannot leave Perbaikan
+-- while its SEP still has unresolved root notes. Existing notes are
+-- backfilled as resolved.
+-- ============================================
+
+SET NAMES utf8mb4;
+
+ALTER TABLE mlite_vedika_feedback
+    ADD COLUMN IF NOT EXISTS parent_id INT NULL AFTER nosep,
+    ADD COLUMN IF NOT EXISTS is_resolved TINYINT(1) NOT NULL DEFAULT 0 AFTER username,
+    ADD COLUMN IF NOT EXISTS resolved_by VARCHAR(100) NULL AFTER is_resolved,
+    ADD COLUMN IF NOT EXISTS resolved_at DATETIME NULL AFTER resolved_by,
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMP NULL AFTER resolved_at;
+
+-- Notes written before threading existed are history, not open threads:
+-- without this every claim now in Perbaikan would be blocked. Only those
+-- rows lack created_at, so re-running the migration leaves new notes alone.
+UPDATE mlite_vedika_feedback
+SET is_resolved = 1, resolved_at = tanggal, created_at = tanggal
+WHERE created_at IS NULL;
+
+ALTER TABLE mlite_vedika_feedback
+    MODIFY COLUMN created_at TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_mlite_vedika_feedback_open
+    ON mlite_vedika_feedback (nosep, parent_id, is_resolved);
@@ -0,0 +1,60 @@
+-- ============================================
+-- Migration: 014_add_inacbg_bridging
+-- Purpose: E-Klaim INA-CBG bridging settings, grouping results and permission
+-- ============================================
+-- inacbg.eklaim settings:
+--   base_url       : full ws.php URL, e.g. http://10.0.0.5/E-Klaim/ws.php
+--   encryption_key : 64 hex chars from E-Klaim setup (value_encrypted = 1,
+--                    store output of `go run ./cmd/encrypt-setting <key>`)
+--   kode_tarif     : hospital tariff class (AP, AS, BP, BS, CP, CS, DP, DS, RSCM, ...)
+--   payor_id       : 3 = JKN
+--   payor_cd       : JKN
+-- ============================================
+
+SET NAMES utf8mb4;
+
+CREATE TABLE IF NOT EXISTS mera_vedika_inacbg (
+    id CHAR(36) NOT NULL,
+    no_rawat VARCHAR(50) NOT NULL,
+    nosep VARCHAR(50) NOT NULL,
+    cbg_code VARCHAR(20) NOT NULL,
+    cbg_description VARCHAR(255) NOT NULL DEFAULT '',
+    cbg_tariff DECIMAL(15,2) NOT NULL DEFAULT 0,
+    special_cmg VARCHAR(255) NULL,
+    total_tariff DECIMAL(15,2) NOT NULL DEFAULT 0,
+    tarif_rs DECIMAL(15,2) NOT NULL DEFAULT 0,
+    kelas_rawat VARCHAR(5) NOT NULL DEFAULT '',
+    inacbg_version VARCHAR(20) NULL,
+    status ENUM('grouped', 'final', 'sent') NOT NULL DEFAULT 'grouped',
+    coder_nik VARCHAR(30) NOT NULL DEFAULT '',
+    grouped_by VARCHAR(100) NOT NULL,
+    grouped_at DATETIME NOT NULL,
+    finalized_at DATETIME NULL,
+    sent_at DATETIME NULL,
+
+    PRIMARY KEY (id),
+    UNIQUE KEY uniq_mera_vedika_inacbg_no_rawat (no_rawat),
+    INDEX idx_mera_vedika_inacbg_nosep (nosep),
+    INDEX idx_mera_vedika_inacbg_cbg (cbg_code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO mera_settings (module, setting_key, environment, setting_value, value_type, value_encrypted, scope, is_active, created_by)
+VALUES
+    ('inacbg.eklaim', 'base_url', 'dev', '""', 'string', 0, 'hospital', 1, 'migration'),
+    ('inacbg.eklaim', 'encryption_key', 'dev', '""', 'string', 1, 'hospital', 1, 'migration'),
+    ('inacbg.eklaim', 'kode_tarif', 'dev', '""', 'string', 0, 'hospital', 1, 'migration'),
+    ('inacbg.eklaim', 'payor_id', 'dev', '"3"', 'string', 0, 'hospital', 1, 'migration'),
+    ('inacbg.eklaim', 'payor_cd', 'dev', '"JKN"', 'string', 0, 'hospital', 1, 'migration'),
+    ('inacbg.eklaim', 'base_url', 'prod', '""', 'string', 0, 'hospital', 1, 'migration'),
+    ('inacbg.eklaim', 'encryption_key', 'prod', '""', 'string', 1, 'hospital', 1, 'migration'),
+    ('inacbg.eklaim', 'kode_tarif', 'prod', '""', 'string', 0, 'hospital', 1, 'migration'),
+    ('inacbg.eklaim', 'payor_id', 'prod', '"3"', 'string', 0, 'hospital', 1, 'migration'),
+    ('inacbg.eklaim', 'payor_cd', 'prod', '"JKN"', 'string', 0, 'hospital', 1, 'migration')
+ON DUPLICATE KEY UPDATE
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = 'migration';
+
+INSERT INTO mera_permissions (id, code, domain, action, description) VALUES
+    (UUID(), 'vedika.claim.bridging', 'vedika', 'claim.bridging', 'Group and send claims to E-Klaim INA-CBG')
+ON DUPLICATE KEY UPDATE
+    description = VALUES(description);
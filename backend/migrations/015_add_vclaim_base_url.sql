@@ -0,0 +1,21 @@
+-- ============================================
+-- Migration: 015_add_vclaim_base_url
+-- Purpose: VClaim REST base URL for the bpjs.vclaim bridging client
+-- ============================================
+-- bpjs.vclaim settings (credentials seeded by 010):
+--   base_url    : VClaim REST base URL without trailing slash
+--   consumer_id : X-cons-id            (value_encrypted = 1)
+--   secret_key  : X-signature HMAC key (value_encrypted = 1)
+--   user_key    : user_key header      (value_encrypted = 1)
+-- Encrypted values are stored as output of `go run ./cmd/encrypt-setting <value>`.
+-- ============================================
+
+SET NAMES utf8mb4;
+
+INSERT INTO mera_settings (module, setting_key, environment, setting_value, value_type, value_encrypted, scope, is_active, created_by)
+VALUES
+    ('bpjs.vclaim', 'base_url', 'dev', '"https://apijkn-dev.bpjs-kesehatan.go.id/vclaim-rest-dev"', 'string', 0, 'hospital', 1, 'migration'),
+    ('bpjs.vclaim', 'base_url', 'prod', '"https://apijkn.bpjs-kesehatan.go.id/vclaim-rest"', 'string', 0, 'hospital', 1, 'migration')
+ON DUPLICATE KEY UPDATE
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = 'migration';
@@ -0,0 +1,17 @@
+-- ============================================
+-- Migration: 016_add_institution_setting
+-- Purpose: Hospital identity printed on the claim bundle PDF
+-- ============================================
+-- vedika.institution (json):
+--   nama, alamat, kota, telepon, email
+-- Replace the placeholder values with the hospital's own identity.
+-- ============================================
+
+SET NAMES utf8mb4;
+
+INSERT INTO mera_settings (module, setting_key, setting_value, value_type, scope, is_active, created_by)
+VALUES
+    ('vedika', 'institution', '{"nama":"RUMAH SAKIT","alamat":"","kota":"","telepon":"","email":""}', 'json', 'hospital', 1, 'system')
+ON DUPLICATE KEY UPDATE
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = 'migration';
@@ -0,0 +1,43 @@
+-- ============================================
+-- Migration: 017_add_document_upload_rules
+-- Purpose: Per-category upload rules and content hashes of digital documents
+-- ============================================
+-- mera_vedika_document_rules: one optional row per master_berkas_digital.kode.
+--   max_size_kb    : upload size limit (categories without a row: 10240)
+--   allowed_types  : comma-separated pdf, jpeg, png (default: all three)
+--   convert_to_pdf : store JPEG/PNG scans as single-page A4 PDFs
+-- mera_vedika_documents: upload metadata of berkas_digital_perawatan files.
+--   Files are de-duplicated per episode by the SHA-256 of the uploaded content.
+--   Files uploaded by the legacy application have no row.
+-- ============================================
+
+SET NAMES utf8mb4;
+
+CREATE TABLE IF NOT EXISTS mera_vedika_document_rules (
+    kode VARCHAR(10) NOT NULL,
+    max_size_kb INT UNSIGNED NOT NULL DEFAULT 10240,
+    allowed_types VARCHAR(50) NOT NULL DEFAULT 'pdf,jpeg,png',
+    convert_to_pdf TINYINT(1) NOT NULL DEFAULT 0,
+    updated_by VARCHAR(100) NOT NULL DEFAULT 'migration',
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (kode)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS mera_vedika_documents (
+    id CHAR(36) NOT NULL,
+    no_rawat VARCHAR(50) NOT NULL,
+    kode VARCHAR(10) NOT NULL,
+    lokasi_file VARCHAR(500) NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    original_name VARCHAR(255) NOT NULL DEFAULT '',
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    converted TINYINT(1) NOT NULL DEFAULT 0,
+    uploaded_by VARCHAR(100) NOT NULL,
+    uploaded_at DATETIME NOT NULL,
+
+    PRIMARY KEY (id),
+    UNIQUE KEY uniq_mera_vedika_documents_hash (no_rawat, sha256),
+    INDEX idx_mera_vedika_documents_file (no_rawat, lokasi_file(191))
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
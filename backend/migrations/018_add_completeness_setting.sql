@@ -0,0 +1,21 @@
+-- ============================================
+-- Migration: 018_add_completeness_setting
+-- Purpose: Completeness checklist ("kelengkapan berkas") required before LENGKAP
+-- ============================================
+-- vedika.completeness (json):
+--   required_documents_ralan : master_berkas_digital.kode required for Ralan claims
+--   required_documents_ranap : master_berkas_digital.kode required for Ranap claims
+--   advisory                 : check keys reported without blocking LENGKAP
+--                              (sep, diagnosa_utama, resume, laporan_operasi,
+--                              spri, document:<kode>)
+-- Fill the document lists with the hospital's own category codes.
+-- ============================================
+
+SET NAMES utf8mb4;
+
+INSERT INTO mera_settings (module, setting_key, setting_value, value_type, scope, is_active, created_by)
+VALUES
+    ('vedika', 'completeness', '{"required_documents_ralan":[],"required_documents_ranap":[],"advisory":[]}', 'json', 'hospital', 1, 'system')
+ON DUPLICATE KEY UPDATE
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = 'migration';
@@ -0,0 +1,20 @@
+-- ============================================
+-- Migration: 019_add_coding_setting
+-- Purpose: ICD-10 / ICD-9-CM coding validation before saving diagnoses
+-- ============================================
+-- vedika.coding (json):
+--   unacceptable_primary : ICD-10 codes or code prefixes BPJS does not accept
+--                          as primary diagnosis (B95-B97 organism codes, Z37
+--                          outcome of delivery)
+-- Built-in rules (one primary, no duplicates, no asterisk or V01-Y98 primary,
+-- sex/age-specific codes) apply without configuration.
+-- ============================================
+
+SET NAMES utf8mb4;
+
+INSERT INTO mera_settings (module, setting_key, setting_value, value_type, scope, is_active, created_by)
+VALUES
+    ('vedika', 'coding', '{"unacceptable_primary":["B95","B96","B97","Z37"]}', 'json', 'hospital', 1, 'system')
+ON DUPLICATE KEY UPDATE
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = 'migration';
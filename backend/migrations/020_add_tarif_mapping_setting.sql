@@ -0,0 +1,26 @@
+-- ============================================
+-- Migration: 020_add_tarif_mapping_setting
+-- Purpose: Classify billing lines into the INA-CBG cost components (tarif_rs)
+-- ============================================
+-- vedika.tarif_mapping (json):
+--   kd_jenis_prw : tariff code (jns_perawatan, jns_perawatan_inap, lab,
+--                  radiologi, databarang) -> component; checked first
+--   pemisah      : billing pemisah -> component, case-insensitive
+--   default      : component for unmatched lines; empty guesses from the
+--                  line name (unrecognised lines become prosedur_non_bedah)
+-- Components: prosedur_non_bedah, prosedur_bedah, konsultasi, tenaga_ahli,
+--   keperawatan, penunjang, radiologi, laboratorium, pelayanan_darah,
+--   rehabilitasi, kamar, rawat_intensif, obat, obat_kronis, obat_kemoterapi,
+--   alkes, bmhp, sewa_alat
+-- Map the hospital's own konsultasi, tenaga ahli, rehabilitasi and ICU
+-- tariff codes under kd_jenis_prw.
+-- ============================================
+
+SET NAMES utf8mb4;
+
+INSERT INTO mera_settings (module, setting_key, setting_value, value_type, scope, is_active, created_by)
+VALUES
+    ('vedika', 'tarif_mapping', '{"kd_jenis_prw":{},"pemisah":{"Registrasi":"konsultasi","Kamar":"kamar","Harian":"kamar","Obat":"obat","Laborat":"laboratorium","Radiologi":"radiologi","Operasi":"prosedur_bedah","Operasi Sarana":"prosedur_bedah","Operasi Sewa OK":"prosedur_bedah","Operasi Alat":"alkes","Operasi Penunjang":"penunjang","Ralan Dokter":"prosedur_non_bedah","Ranap Dokter":"prosedur_non_bedah","Ralan Dokter Paramedis":"prosedur_non_bedah","Ranap Dokter Paramedis":"prosedur_non_bedah","Ralan Paramedis":"keperawatan","Ranap Paramedis":"keperawatan"},"default":""}', 'json', 'hospital', 1, 'system')
+ON DUPLICATE KEY UPDATE
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = 'migration';
@@ -0,0 +1,45 @@
+-- ============================================
+-- Migration: 021_add_vedika_assignment
+-- Purpose: Claim assignment / coder work queues
+-- ============================================
+-- mera_vedika_assignment: at most one coder (mera_users.id) per episode.
+--   tgl_pelayanan : ralan registration date, ranap discharge date
+--   kd_unit       : kd_poli (ralan) or last kd_bangsal (ranap)
+--   assigned_by   : username, or 'system' for automatic distribution
+-- vedika.assignment (json):
+--   enabled          : distribute new Rencana episodes automatically
+--   group_by         : jenis | unit - how pools are keyed
+--   pools            : jenis (ralan, ranap) or kd_poli / kd_bangsal -> user ids
+--   default          : user ids for episodes without a matching pool
+--   lookback_days    : how far back unassigned Rencana episodes are picked up
+--   interval_minutes : how often automatic distribution runs
+-- Within a pool, episodes go round-robin to the coder assigned least recently.
+-- ============================================
+
+SET NAMES utf8mb4;
+
+CREATE TABLE IF NOT EXISTS mera_vedika_assignment (
+    no_rawat VARCHAR(50) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    jenis ENUM('ralan', 'ranap') NOT NULL,
+    kd_unit VARCHAR(20) NOT NULL DEFAULT '',
+    tgl_pelayanan DATE NOT NULL,
+    assigned_by VARCHAR(100) NOT NULL,
+    assigned_at DATETIME NOT NULL,
+
+    PRIMARY KEY (no_rawat),
+    INDEX idx_mera_vedika_assignment_user (user_id, assigned_at),
+    INDEX idx_mera_vedika_assignment_tgl (tgl_pelayanan)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO mera_settings (module, setting_key, setting_value, value_type, scope, is_active, created_by)
+VALUES
+    ('vedika', 'assignment', '{"enabled":false,"group_by":"jenis","pools":{"ralan":[],"ranap":[]},"default":[],"lookback_days":7,"interval_minutes":15}', 'json', 'hospital', 1, 'system')
+ON DUPLICATE KEY UPDATE
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = 'migration';
+
+INSERT INTO mera_permissions (id, code, domain, action, description) VALUES
+    (UUID(), 'vedika.claim.assign', 'vedika', 'claim.assign', 'Assign claims to coders and run distribution')
+ON DUPLICATE KEY UPDATE
+    description = VALUES(description);
@@ -0,0 +1,21 @@
+-- ============================================
+-- Migration: 022_add_vedika_edit_lock
+-- Purpose: Soft edit locks on claims
+-- ============================================
+-- mera_vedika_edit_lock: at most one editor (mera_users.id) per episode.
+--   acquired_at : when the current holder opened the claim
+--   expires_at  : pushed forward by each heartbeat; a lock past it is free
+-- Expired rows are simply taken over by the next editor.
+-- ============================================
+
+SET NAMES utf8mb4;
+
+CREATE TABLE IF NOT EXISTS mera_vedika_edit_lock (
+    no_rawat VARCHAR(50) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    acquired_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+
+    PRIMARY KEY (no_rawat),
+    INDEX idx_mera_vedika_edit_lock_user (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
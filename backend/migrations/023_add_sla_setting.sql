@@ -0,0 +1,20 @@
+-- ============================================
+-- Migration: 023_add_sla_setting
+-- Purpose: Claim aging and SLA thresholds
+-- ============================================
+-- vedika.sla (json), per jenis (ralan, ranap):
+--   submit_days : max days from registration (ralan) or discharge (ranap)
+--                 while the claim is still Rencana
+--   status_days : max days a claim may stay in Pengajuan, Perbaikan or
+--                 Lengkap, counted from its last status change
+-- 0 disables a threshold. Setuju claims are never overdue.
+-- ============================================
+
+SET NAMES utf8mb4;
+
+INSERT INTO mera_settings (module, setting_key, setting_value, value_type, scope, is_active, created_by)
+VALUES
+    ('vedika', 'sla', '{"ralan":{"submit_days":30,"status_days":14},"ranap":{"submit_days":30,"status_days":14}}', 'json', 'hospital', 1, 'system')
+ON DUPLICATE KEY UPDATE
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = 'migration';
@@ -0,0 +1,40 @@
+-- ============================================
+-- Migration: 024_add_user_mfa
+-- Purpose: TOTP two-factor login for staff accounts
+-- ============================================
+-- mera_user_mfa: one TOTP secret per user (mera_users.id).
+--   secret_encrypted : base32 secret, encrypted with SETTINGS_ENCRYPTION_KEY
+--   enabled_at       : NULL while enrollment is pending confirmation
+--   last_used_step   : last accepted 30 second step; older codes are rejected
+-- mera_user_mfa_recovery_codes: single-use recovery codes (bcrypt hashes).
+-- Which users must use MFA is configured by MFA_REQUIRED_ROLES and
+-- MFA_REQUIRED_PERMISSIONS.
+-- ============================================
+
+SET NAMES utf8mb4;
+
+CREATE TABLE IF NOT EXISTS mera_user_mfa (
+    user_id CHAR(36) NOT NULL,
+    secret_encrypted TEXT NOT NULL,
+    enabled_at TIMESTAMP NULL,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (user_id),
+    CONSTRAINT fk_mera_user_mfa_user
+        FOREIGN KEY (user_id) REFERENCES mera_users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS mera_user_mfa_recovery_codes (
+    id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    code_hash VARCHAR(255) NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (id),
+    INDEX idx_mera_user_mfa_recovery_codes_user (user_id, used_at),
+    CONSTRAINT fk_mera_user_mfa_recovery_codes_user
+        FOREIGN KEY (user_id) REFERENCES mera_users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
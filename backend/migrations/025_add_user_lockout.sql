@@ -0,0 +1,18 @@
+-- ============================================
+-- Migration: 025_add_user_lockout
+-- Purpose: Persistent failed-login tracking and account lockout
+-- ============================================
+-- mera_users:
+--   failed_login_count   : consecutive failed logins, reset on success or unlock
+--   last_failed_login_at : counter restarts when the last failure is older
+--                          than LOGIN_LOCKOUT_RESET_AFTER
+--   locked_until         : login refused until this time (NULL = not locked)
+-- Lockout length doubles with each failure past LOGIN_LOCKOUT_THRESHOLD.
+-- ============================================
+
+SET NAMES utf8mb4;
+
+ALTER TABLE mera_users
+    ADD COLUMN failed_login_count INT NOT NULL DEFAULT 0 AFTER last_login_at,
+    ADD COLUMN last_failed_login_at TIMESTAMP NULL AFTER failed_login_count,
+    ADD COLUMN locked_until TIMESTAMP NULL AFTER last_failed_login_at;
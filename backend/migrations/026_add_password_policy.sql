@@ -0,0 +1,38 @@
+-- ============================================
+-- Migration: 026_add_password_policy
+-- Purpose: Password history and expiry
+-- ============================================
+-- mera_users:
+--   password_changed_at  : last password change; passwords older than
+--                          PASSWORD_MAX_AGE must be changed at login.
+--                          Existing passwords start aging at migration time.
+--   must_change_password : set when an administrator reset the password,
+--                          or created the user asking for a change;
+--                          cleared by the user's own change
+-- mera_user_password_history: bcrypt hashes of the last PASSWORD_HISTORY
+--   passwords of each user, which may not be reused. seq orders them:
+--   created_at has second resolution and a create followed by a reset can
+--   share a second.
+-- ============================================
+
+SET NAMES utf8mb4;
+
+ALTER TABLE mera_users
+    ADD COLUMN password_changed_at TIMESTAMP NULL AFTER password_hash,
+    ADD COLUMN must_change_password BOOLEAN NOT NULL DEFAULT FALSE AFTER password_changed_at;
+
+UPDATE mera_users SET password_changed_at = NOW() WHERE password_changed_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS mera_user_password_history (
+    id CHAR(36) NOT NULL,
+    seq BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id CHAR(36) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (id),
+    UNIQUE KEY uk_mera_user_password_history_seq (seq),
+    INDEX idx_mera_user_password_history_user (user_id, seq),
+    CONSTRAINT fk_mera_user_password_history_user
+        FOREIGN KEY (user_id) REFERENCES mera_users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
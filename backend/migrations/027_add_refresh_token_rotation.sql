@@ -0,0 +1,20 @@
+-- ============================================
+-- Migration: 027_add_refresh_token_rotation
+-- Purpose: Rotate refresh tokens within a session and detect reuse
+-- ============================================
+-- mera_login_sessions:
+--   refresh_token_hash          : hash of the current refresh token, replaced
+--                                 on every /auth/refresh
+--   previous_refresh_token_hash : hash replaced by the last rotation
+--   refresh_rotated_at          : time of the last rotation
+-- A refresh token of the session that is no longer current revokes the
+-- session. Only the previous token, replayed within JWT_REFRESH_REUSE_GRACE
+-- of the rotation, is refused without revoking (concurrent refresh).
+-- ============================================
+
+SET NAMES utf8mb4;
+
+ALTER TABLE mera_login_sessions
+    ADD COLUMN previous_refresh_token_hash VARCHAR(255) NULL AFTER refresh_token_hash,
+    ADD COLUMN refresh_rotated_at TIMESTAMP NULL AFTER previous_refresh_token_hash,
+    ADD INDEX idx_mera_login_sessions_refresh_token_hash (refresh_token_hash);
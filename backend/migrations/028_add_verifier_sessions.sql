@@ -0,0 +1,35 @@
+-- ============================================
+-- Migration: 028_add_verifier_sessions
+-- Purpose: Revocable verifier portal sessions and verifier login lockout
+-- ============================================
+-- mera_vedika_verifier_sessions: one row per /veda/login; the verifier
+-- token carries its id (sid) and is refused once the row is revoked
+-- (logout) or past expires_at.
+-- mera_vedika_verifier_lockouts: failed-login counter and lockout of
+-- mlite_users_vedika accounts, with the same policy as mera_users
+-- (LOGIN_LOCKOUT_*). Kept apart so the mLITE table stays unchanged.
+-- ============================================
+
+SET NAMES utf8mb4;
+
+CREATE TABLE IF NOT EXISTS mera_vedika_verifier_sessions (
+    id CHAR(36) NOT NULL,
+    verifier_id INT NOT NULL,
+    device_info VARCHAR(500),
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NULL,
+
+    PRIMARY KEY (id),
+    INDEX idx_mera_vedika_verifier_sessions_verifier (verifier_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS mera_vedika_verifier_lockouts (
+    verifier_id INT NOT NULL,
+    failed_login_count INT NOT NULL DEFAULT 0,
+    last_failed_login_at TIMESTAMP NULL,
+    locked_until TIMESTAMP NULL,
+
+    PRIMARY KEY (verifier_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,21 @@
+-- ============================================
+-- Migration: 029_add_vedika_medical_edit_guard
+-- Purpose: Serialise medical data edits of a claim across server instances
+-- ============================================
+-- mera_vedika_medical_edit: one row per edited episode. A diagnosis,
+-- procedure or resume edit holds the row with SELECT ... FOR UPDATE from
+-- its If-Match version check until the write is done, so two edits based
+-- on the same version cannot both pass the check.
+-- The row lives apart from reg_periksa because the edits insert child rows
+-- (diagnosa_pasien, prosedur_pasien) whose foreign key checks would wait on
+-- a lock held on the episode itself.
+-- ============================================
+
+SET NAMES utf8mb4;
+
+CREATE TABLE IF NOT EXISTS mera_vedika_medical_edit (
+    no_rawat VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (no_rawat)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -22,6 +22,15 @@ type TokenType string
 const (
 	AccessToken  TokenType = "access"
 	RefreshToken TokenType = "refresh"
+	// VerifierToken is issued to external BPJS verifiers. It is rejected by
+	// ValidateAccessToken so verifiers cannot reach hospital endpoints.
+	VerifierToken TokenType = "verifier"
+	// MFAToken is the short-lived challenge returned by a password login
+	// that still needs a second factor. It carries no session.
+	MFAToken TokenType = "mfa"
+	// PasswordChangeToken is returned by a login whose password must be
+	// changed first. It only authorizes setting the new password.
+	PasswordChangeToken TokenType = "password_change"
 )
 
 type Claims struct {
@@ -103,6 +112,65 @@ func (m *Manager) GenerateTokenPair(userID, sessionID string) (*TokenPair, error
 	}, nil
 }
 
+// GenerateVerifierToken issues a verifier token for the given verifier ID
+// and verifier session, valid for the access token lifetime.
+func (m *Manager) GenerateVerifierToken(verifierID, sessionID string) (string, time.Time, error) {
+	now := time.Now()
+	expiry := now.Add(m.accessTokenExpiry)
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   verifierID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiry),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+		UserID:    verifierID,
+		SessionID: sessionID,
+		TokenType: VerifierToken,
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiry, nil
+}
+
+// GenerateMFAToken issues an MFA challenge token for the given user ID,
+// valid for ttl.
+func (m *Manager) GenerateMFAToken(userID string, ttl time.Duration) (string, time.Time, error) {
+	return m.generateChallengeToken(userID, MFAToken, ttl)
+}
+
+// GeneratePasswordChangeToken issues a password change token for the given
+// user ID, valid for ttl.
+func (m *Manager) GeneratePasswordChangeToken(userID string, ttl time.Duration) (string, time.Time, error) {
+	return m.generateChallengeToken(userID, PasswordChangeToken, ttl)
+}
+
+func (m *Manager) generateChallengeToken(userID string, tokenType TokenType, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiry := now.Add(ttl)
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   userID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiry),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+		UserID:    userID,
+		TokenType: tokenType,
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiry, nil
+}
+
 func (m *Manager) ValidateToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -148,6 +216,39 @@ func (m *Manager) ValidateRefreshToken(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
+func (m *Manager) ValidateVerifierToken(tokenString string) (*Claims, error) {
+	claims, err := m.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != VerifierToken {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (m *Manager) ValidateMFAToken(tokenString string) (*Claims, error) {
+	claims, err := m.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != MFAToken {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (m *Manager) ValidatePasswordChangeToken(tokenString string) (*Claims, error) {
+	claims, err := m.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != PasswordChangeToken {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
 func HashToken(token string) string {
 	hash := sha256.Sum256([]byte(token))
 	return hex.EncodeToString(hash[:])
@@ -0,0 +1,256 @@
+// Package lzstring implements the URI-safe variant of the lz-string codec
+// (compressToEncodedURIComponent / decompressFromEncodedURIComponent).
+//
+// lz-string works on UTF-16 code units, so strings are converted to and
+// from UTF-16 at the boundary.
+package lzstring
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf16"
+)
+
+var ErrInvalidInput = errors.New("invalid lz-string input")
+
+const keyStrURISafe = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+-$"
+
+var uriSafeIndex = func() map[byte]int {
+	m := make(map[byte]int, len(keyStrURISafe))
+	for i := 0; i < len(keyStrURISafe); i++ {
+		m[keyStrURISafe[i]] = i
+	}
+	return m
+}()
+
+// DecompressFromEncodedURIComponent decodes output of compressToEncodedURIComponent.
+func DecompressFromEncodedURIComponent(input string) (string, error) {
+	if input == "" {
+		return "", nil
+	}
+	input = strings.ReplaceAll(input, " ", "+")
+
+	values := make([]int, len(input))
+	for i := 0; i < len(input); i++ {
+		v, ok := uriSafeIndex[input[i]]
+		if !ok {
+			return "", ErrInvalidInput
+		}
+		values[i] = v
+	}
+
+	units, err := decompress(values, 32)
+	if err != nil {
+		return "", err
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+// CompressToEncodedURIComponent encodes a string the way lz-string does.
+func CompressToEncodedURIComponent(input string) string {
+	return compress(utf16.Encode([]rune(input)), 6, func(v int) byte { return keyStrURISafe[v] })
+}
+
+type bitReader struct {
+	values     []int
+	resetValue int
+	val        int
+	position   int
+	index      int
+}
+
+func (r *bitReader) read(numBits int) int {
+	bits := 0
+	for power := 1; power != 1<<numBits; power <<= 1 {
+		resb := r.val & r.position
+		r.position >>= 1
+		if r.position == 0 {
+			r.position = r.resetValue
+			// Reading past the end is allowed once; the caller checks index
+			r.val = 0
+			if r.index < len(r.values) {
+				r.val = r.values[r.index]
+			}
+			r.index++
+		}
+		if resb > 0 {
+			bits |= power
+		}
+	}
+	return bits
+}
+
+func decompress(values []int, resetValue int) ([]uint16, error) {
+	r := &bitReader{values: values, resetValue: resetValue, val: values[0], position: resetValue, index: 1}
+
+	dictionary := [][]uint16{{0}, {1}, {2}}
+	enlargeIn, numBits := 4, 3
+
+	var c []uint16
+	switch next := r.read(2); next {
+	case 0, 1:
+		width := 8
+		if next == 1 {
+			width = 16
+		}
+		c = []uint16{uint16(r.read(width))}
+	default:
+		return nil, nil
+	}
+
+	dictionary = append(dictionary, c)
+	w := c
+	result := append([]uint16{}, c...)
+
+	for {
+		if r.index > len(values) {
+			return nil, ErrInvalidInput
+		}
+
+		code := r.read(numBits)
+		switch code {
+		case 0, 1:
+			width := 8
+			if code == 1 {
+				width = 16
+			}
+			dictionary = append(dictionary, []uint16{uint16(r.read(width))})
+			code = len(dictionary) - 1
+			enlargeIn--
+		case 2:
+			return result, nil
+		}
+
+		if enlargeIn == 0 {
+			enlargeIn = 1 << numBits
+			numBits++
+		}
+
+		var entry []uint16
+		switch {
+		case code < len(dictionary):
+			entry = dictionary[code]
+		case code == len(dictionary):
+			entry = append(append([]uint16{}, w...), w[0])
+		default:
+			return nil, ErrInvalidInput
+		}
+		result = append(result, entry...)
+
+		dictionary = append(dictionary, append(append([]uint16{}, w...), entry[0]))
+		enlargeIn--
+		w = entry
+
+		if enlargeIn == 0 {
+			enlargeIn = 1 << numBits
+			numBits++
+		}
+	}
+}
+
+type bitWriter struct {
+	bitsPerChar int
+	toChar      func(int) byte
+	out         []byte
+	val         int
+	position    int
+}
+
+// write emits the numBits low bits of value, least significant first.
+func (w *bitWriter) write(value, numBits int) {
+	for i := 0; i < numBits; i++ {
+		w.val = (w.val << 1) | (value & 1)
+		if w.position == w.bitsPerChar-1 {
+			w.position = 0
+			w.out = append(w.out, w.toChar(w.val))
+			w.val = 0
+		} else {
+			w.position++
+		}
+		value >>= 1
+	}
+}
+
+func (w *bitWriter) flush() {
+	for {
+		w.val <<= 1
+		if w.position == w.bitsPerChar-1 {
+			w.out = append(w.out, w.toChar(w.val))
+			return
+		}
+		w.position++
+	}
+}
+
+func compress(input []uint16, bitsPerChar int, toChar func(int) byte) string {
+	dictionary := map[string]int{}
+	toCreate := map[string]bool{}
+	enlargeIn, dictSize, numBits := 2, 3, 2
+	bw := &bitWriter{bitsPerChar: bitsPerChar, toChar: toChar}
+
+	key := func(units []uint16) string {
+		b := make([]byte, 0, len(units)*2)
+		for _, u := range units {
+			b = append(b, byte(u>>8), byte(u))
+		}
+		return string(b)
+	}
+
+	emit := func(wUnits []uint16) {
+		wKey := key(wUnits)
+		if toCreate[wKey] {
+			if wUnits[0] < 256 {
+				bw.write(0, numBits)
+				bw.write(int(wUnits[0]), 8)
+			} else {
+				bw.write(1, numBits)
+				bw.write(int(wUnits[0]), 16)
+			}
+			enlargeIn--
+			if enlargeIn == 0 {
+				enlargeIn = 1 << numBits
+				numBits++
+			}
+			delete(toCreate, wKey)
+		} else {
+			bw.write(dictionary[wKey], numBits)
+		}
+		enlargeIn--
+		if enlargeIn == 0 {
+			enlargeIn = 1 << numBits
+			numBits++
+		}
+	}
+
+	var w []uint16
+	for _, unit := range input {
+		c := []uint16{unit}
+		cKey := key(c)
+		if _, ok := dictionary[cKey]; !ok {
+			dictionary[cKey] = dictSize
+			dictSize++
+			toCreate[cKey] = true
+		}
+
+		wc := append(append([]uint16{}, w...), unit)
+		if _, ok := dictionary[key(wc)]; ok {
+			w = wc
+			continue
+		}
+
+		emit(w)
+		dictionary[key(wc)] = dictSize
+		dictSize++
+		w = c
+	}
+
+	if len(w) > 0 {
+		emit(w)
+	}
+
+	// End of stream marker
+	bw.write(2, numBits)
+	bw.flush()
+
+	return string(bw.out)
+}
@@ -0,0 +1,64 @@
+package lzstring
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	inputs := []string{
+		"a",
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		`{"metaData":{"code":"200"},"response":{"peserta":{"nama":"BUDI SANTOSO"}}}`,
+		strings.Repeat(`{"kode":"A09","nama":"Diarrhoea and gastroenteritis"},`, 50),
+		"rujukan ✓ — 😀",        // Multi-byte and surrogate pairs
+		string(rune(0x10FFFF)), // Largest code point
+	}
+	for _, in := range inputs {
+		compressed := CompressToEncodedURIComponent(in)
+		if strings.ContainsAny(compressed, "/=") {
+			t.Fatalf("compressed %q is not URI safe: %q", in, compressed)
+		}
+		got, err := DecompressFromEncodedURIComponent(compressed)
+		if err != nil {
+			t.Fatalf("Decompress(%q) error = %v", compressed, err)
+		}
+		if got != in {
+			t.Fatalf("round trip = %q, want %q", got, in)
+		}
+	}
+}
+
+func TestCompressShrinksRepetitiveInput(t *testing.T) {
+	in := strings.Repeat(`{"kdPoli":"INT","nmPoli":"PENYAKIT DALAM"}`, 100)
+	if out := CompressToEncodedURIComponent(in); len(out) >= len(in)/4 {
+		t.Fatalf("compressed %d bytes to %d, want under a quarter", len(in), len(out))
+	}
+}
+
+func TestDecompressEmpty(t *testing.T) {
+	got, err := DecompressFromEncodedURIComponent("")
+	if err != nil || got != "" {
+		t.Fatalf("Decompress(\"\") = %q, %v", got, err)
+	}
+}
+
+func TestDecompressSpaceAsPlus(t *testing.T) {
+	// Form decoding turns + into a space; both must decode the same
+	in := strings.Repeat("vclaim+response ", 20)
+	compressed := CompressToEncodedURIComponent(in)
+	if !strings.Contains(compressed, "+") {
+		t.Skip("compressed form has no +")
+	}
+	got, err := DecompressFromEncodedURIComponent(strings.ReplaceAll(compressed, "+", " "))
+	if err != nil || got != in {
+		t.Fatalf("Decompress() = %q, %v", got, err)
+	}
+}
+
+func TestDecompressInvalidInput(t *testing.T) {
+	if _, err := DecompressFromEncodedURIComponent("not/valid="); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("Decompress() error = %v, want ErrInvalidInput", err)
+	}
+}
@@ -0,0 +1,154 @@
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MaxLength is the longest password bcrypt accepts, in bytes.
+const MaxLength = 72
+
+// Policy violations reported by Check.
+const (
+	ViolationTooShort         = "too_short"
+	ViolationTooLong          = "too_long"
+	ViolationMissingUpper     = "missing_upper"
+	ViolationMissingLower     = "missing_lower"
+	ViolationMissingDigit     = "missing_digit"
+	ViolationMissingSymbol    = "missing_symbol"
+	ViolationCommon           = "common"
+	ViolationContainsUsername = "contains_username"
+	ViolationReused           = "reused" // Reported by callers checking the password history
+)
+
+//go:embed common-passwords.txt
+var commonPasswordsFile string
+
+var commonPasswords = parseList(commonPasswordsFile)
+
+// Policy describes the passwords users may choose.
+type Policy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	RejectCommon  bool // Reject passwords on the bundled common-password list
+}
+
+// PolicyError lists the rules a password breaks.
+type PolicyError struct {
+	Violations []string
+	MinLength  int
+}
+
+func (e *PolicyError) Error() string {
+	return "password does not meet policy: " + strings.Join(e.Violations, ", ")
+}
+
+// Messages returns a user-facing message for each violation.
+func (e *PolicyError) Messages() []string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		switch v {
+		case ViolationTooShort:
+			messages[i] = fmt.Sprintf("Password minimal %d karakter", e.MinLength)
+		case ViolationTooLong:
+			messages[i] = fmt.Sprintf("Password maksimal %d byte", MaxLength)
+		case ViolationMissingUpper:
+			messages[i] = "Password harus mengandung huruf besar"
+		case ViolationMissingLower:
+			messages[i] = "Password harus mengandung huruf kecil"
+		case ViolationMissingDigit:
+			messages[i] = "Password harus mengandung angka"
+		case ViolationMissingSymbol:
+			messages[i] = "Password harus mengandung simbol"
+		case ViolationCommon:
+			messages[i] = "Password terlalu umum dan mudah ditebak"
+		case ViolationContainsUsername:
+			messages[i] = "Password tidak boleh mengandung username"
+		case ViolationReused:
+			messages[i] = "Password sudah pernah dipakai sebelumnya"
+		default:
+			messages[i] = v
+		}
+	}
+	return messages
+}
+
+// Check returns a *PolicyError when plain breaks the policy. username, when
+// set, may not appear in the password.
+func (p Policy) Check(plain, username string) error {
+	var violations []string
+	if len([]rune(plain)) < p.MinLength {
+		violations = append(violations, ViolationTooShort)
+	}
+	if len(plain) > MaxLength {
+		violations = append(violations, ViolationTooLong)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range plain {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		violations = append(violations, ViolationMissingUpper)
+	}
+	if p.RequireLower && !lower {
+		violations = append(violations, ViolationMissingLower)
+	}
+	if p.RequireDigit && !digit {
+		violations = append(violations, ViolationMissingDigit)
+	}
+	if p.RequireSymbol && !symbol {
+		violations = append(violations, ViolationMissingSymbol)
+	}
+
+	if p.RejectCommon && IsCommon(plain) {
+		violations = append(violations, ViolationCommon)
+	}
+	if len(username) >= 3 && strings.Contains(strings.ToLower(plain), strings.ToLower(username)) {
+		violations = append(violations, ViolationContainsUsername)
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations, MinLength: p.MinLength}
+	}
+	return nil
+}
+
+// IsCommon reports whether plain is on the common-password list, ignoring
+// case and trailing digits or symbols ("Sayang123!" matches "sayang").
+func IsCommon(plain string) bool {
+	lower := strings.ToLower(plain)
+	if commonPasswords[lower] {
+		return true
+	}
+	base := strings.TrimRightFunc(lower, func(r rune) bool { return !unicode.IsLetter(r) })
+	return len(base) >= 4 && commonPasswords[base]
+}
+
+func parseList(data string) map[string]bool {
+	list := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		list[strings.ToLower(line)] = true
+	}
+	return list
+}
@@ -0,0 +1,87 @@
+package password
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	strict := Policy{
+		MinLength:     10,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		RejectCommon:  true,
+	}
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		plain    string
+		username string
+		want     []string
+	}{
+		{"valid", strict, "Kunci-Gudang-42", "", nil},
+		{"too short", strict, "Ab1!xyz", "", []string{ViolationTooShort}},
+		{"min length counts runes", Policy{MinLength: 4}, "ééé", "", []string{ViolationTooShort}},
+		{"too long", Policy{}, strings.Repeat("a", MaxLength+1), "", []string{ViolationTooLong}},
+		{"max length is allowed", Policy{}, strings.Repeat("a", MaxLength), "", nil},
+		{"missing upper", strict, "kunci-gudang-42", "", []string{ViolationMissingUpper}},
+		{"missing lower", strict, "KUNCI-GUDANG-42", "", []string{ViolationMissingLower}},
+		{"missing digit", strict, "Kunci-Gudang-XY", "", []string{ViolationMissingDigit}},
+		{"missing symbol", strict, "KunciGudang42", "", []string{ViolationMissingSymbol}},
+		{"space counts as symbol", strict, "Kunci Gudang 42", "", nil},
+		{"several violations", strict, "abc", "", []string{ViolationTooShort, ViolationMissingUpper, ViolationMissingDigit, ViolationMissingSymbol}},
+		{"common", Policy{RejectCommon: true}, "password", "", []string{ViolationCommon}},
+		{"common with suffix", Policy{RejectCommon: true}, "Sayang123!", "", []string{ViolationCommon}},
+		{"common not checked", Policy{}, "password", "", nil},
+		{"contains username", Policy{}, "xDrAndi99x", "drandi", []string{ViolationContainsUsername}},
+		{"short username ignored", Policy{}, "xabx", "ab", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(tt.plain, tt.username)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("got %v, want nil", err)
+				}
+				return
+			}
+			var policyErr *PolicyError
+			if !errors.As(err, &policyErr) {
+				t.Fatalf("got %v, want *PolicyError", err)
+			}
+			if !reflect.DeepEqual(policyErr.Violations, tt.want) {
+				t.Fatalf("violations %v, want %v", policyErr.Violations, tt.want)
+			}
+			if len(policyErr.Messages()) != len(tt.want) {
+				t.Fatalf("got %d messages for %d violations", len(policyErr.Messages()), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestIsCommon(t *testing.T) {
+	tests := []struct {
+		plain string
+		want  bool
+	}{
+		{"password", true},
+		{"PASSWORD", true},
+		{"rahasia2024", true},
+		{"Qwerty!!", true},
+		{"admin1", true},
+		{"mei2024", false}, // Base "mei" is listed but too short to strip a suffix from
+		{"mei", true},
+		{"kunci-gudang", false},
+		{"123", false},
+	}
+	for _, tt := range tests {
+		if got := IsCommon(tt.plain); got != tt.want {
+			t.Errorf("IsCommon(%q) = %v, want %v", tt.plain, got, tt.want)
+		}
+	}
+}
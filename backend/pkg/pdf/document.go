@@ -0,0 +1,486 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"unicode/utf16"
+)
+
+// A4 page size in points.
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// Document is a PDF under construction.
+type Document struct {
+	objects []Object // object number i+1
+	pages   []*Page
+	fonts   map[Font]Ref
+	title   string
+
+	maxImagePixels int
+}
+
+// New creates an empty document.
+func New() *Document {
+	return &Document{fonts: make(map[Font]Ref), maxImagePixels: DefaultMaxImagePixels}
+}
+
+// SetMaxImagePixels sets the pixel limit of AddImage.
+func (d *Document) SetMaxImagePixels(n int) {
+	d.maxImagePixels = n
+}
+
+// SetTitle sets the document title shown by viewers.
+func (d *Document) SetTitle(title string) {
+	d.title = title
+}
+
+// Pages returns the pages in order.
+func (d *Document) Pages() []*Page {
+	return d.pages
+}
+
+// add stores obj as a new indirect object.
+func (d *Document) add(obj Object) Ref {
+	d.objects = append(d.objects, obj)
+	return Ref{Num: len(d.objects)}
+}
+
+func (d *Document) set(ref Ref, obj Object) {
+	d.objects[ref.Num-1] = obj
+}
+
+func (d *Document) fontRef(f Font) Ref {
+	if ref, ok := d.fonts[f]; ok {
+		return ref
+	}
+	ref := d.add(Dict{
+		"Type":     Name("Font"),
+		"Subtype":  Name("Type1"),
+		"BaseFont": f.baseFont(),
+		"Encoding": Name("WinAnsiEncoding"),
+	})
+	d.fonts[f] = ref
+	return ref
+}
+
+// Page is a page of a Document. Drawing methods take coordinates in points
+// from the top-left corner of the page.
+type Page struct {
+	doc     *Document
+	width   float64
+	height  float64
+	content bytes.Buffer
+	fonts   map[Font]bool
+	images  map[Name]Ref
+
+	// Set for pages imported from another PDF
+	imported Dict
+	origin   [2]float64 // MediaBox lower-left corner
+	rotate   int        // /Rotate normalized to 0, 90, 180 or 270
+}
+
+// AddPage appends a blank A4 portrait page.
+func (d *Document) AddPage() *Page {
+	p := &Page{
+		doc:    d,
+		width:  A4Width,
+		height: A4Height,
+		fonts:  make(map[Font]bool),
+		images: make(map[Name]Ref),
+	}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Size returns the page width and height in points.
+func (p *Page) Size() (float64, float64) {
+	return p.width, p.height
+}
+
+// Imported reports whether the page was copied from another PDF.
+func (p *Page) Imported() bool {
+	return p.imported != nil
+}
+
+func (p *Page) fontName(f Font) Name {
+	p.fonts[f] = true
+	if p.imported != nil {
+		return "Mera" + f.resourceName()
+	}
+	return f.resourceName()
+}
+
+// Text draws s with its baseline at (x, y).
+func (p *Page) Text(x, y float64, font Font, size float64, s string) {
+	var buf bytes.Buffer
+	writeString(&buf, encodeWinAnsi(s))
+	fmt.Fprintf(&p.content, "BT /%s %s Tf 1 0 0 1 %s %s Tm %s Tj ET\n",
+		p.fontName(font), formatNumber(size), formatNumber(x), formatNumber(p.height-y), buf.String())
+}
+
+// Line draws a black line of the given width.
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%s w 0 G %s %s m %s %s l S\n",
+		formatNumber(width), formatNumber(x1), formatNumber(p.height-y1), formatNumber(x2), formatNumber(p.height-y2))
+}
+
+// Rect draws a rectangle with its top-left corner at (x, y). fillGray
+// (0 = black, 1 = white) fills it when >= 0; strokeWidth outlines it
+// when > 0.
+func (p *Page) Rect(x, y, w, h, fillGray, strokeWidth float64) {
+	rect := fmt.Sprintf("%s %s %s %s re", formatNumber(x), formatNumber(p.height-y-h), formatNumber(w), formatNumber(h))
+	switch {
+	case fillGray >= 0 && strokeWidth > 0:
+		fmt.Fprintf(&p.content, "q %s g %s w 0 G %s B Q\n", formatNumber(fillGray), formatNumber(strokeWidth), rect)
+	case fillGray >= 0:
+		fmt.Fprintf(&p.content, "q %s g %s f Q\n", formatNumber(fillGray), rect)
+	case strokeWidth > 0:
+		fmt.Fprintf(&p.content, "%s w 0 G %s S\n", formatNumber(strokeWidth), rect)
+	}
+}
+
+// Image draws img scaled into the box with top-left corner (x, y).
+func (p *Page) Image(img *Image, x, y, w, h float64) {
+	name := Name(fmt.Sprintf("MeraIm%d", img.ref.Num))
+	p.images[name] = img.ref
+	fmt.Fprintf(&p.content, "q %s 0 0 %s %s %s cm /%s Do Q\n",
+		formatNumber(w), formatNumber(h), formatNumber(x), formatNumber(p.height-y-h), name)
+}
+
+// ImportPDF appends all pages of a PDF file and returns them. Drawing on
+// an imported page overlays its original content.
+func (d *Document) ImportPDF(data []byte) ([]*Page, error) {
+	r, err := NewReader(data)
+	if err != nil {
+		return nil, err
+	}
+	srcPages, err := r.Pages()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &copier{doc: d, src: r, refs: make(map[int]Ref)}
+	var pages []*Page
+	for _, src := range srcPages {
+		page := Dict{}
+		for k, v := range src {
+			switch k {
+			case "Parent", "B", "Thumb", "StructParents", "Annots":
+				continue
+			}
+			page[k] = c.copy(v)
+		}
+		if annots := c.copyAnnots(src["Annots"]); len(annots) > 0 {
+			page["Annots"] = annots
+		}
+
+		box := mediaBox(r.Resolve(src["MediaBox"]), r)
+		p := &Page{
+			doc:      d,
+			width:    box[2] - box[0],
+			height:   box[3] - box[1],
+			fonts:    make(map[Font]bool),
+			images:   make(map[Name]Ref),
+			imported: page,
+			origin:   [2]float64{box[0], box[1]},
+			rotate:   ((intValue(r.Resolve(src["Rotate"]), 0)%360 + 360) % 360) / 90 * 90,
+		}
+		// Overlay coordinates follow the page as displayed
+		if p.rotate%180 != 0 {
+			p.width, p.height = p.height, p.width
+		}
+		d.pages = append(d.pages, p)
+		pages = append(pages, p)
+	}
+	return pages, nil
+}
+
+func mediaBox(obj Object, r *Reader) [4]float64 {
+	box := [4]float64{0, 0, A4Width, A4Height}
+	arr, ok := obj.(Array)
+	if !ok || len(arr) != 4 {
+		return box
+	}
+	for i := range box {
+		v, ok := floatValue(r.Resolve(arr[i]))
+		if !ok {
+			return [4]float64{0, 0, A4Width, A4Height}
+		}
+		box[i] = v
+	}
+	if box[0] > box[2] {
+		box[0], box[2] = box[2], box[0]
+	}
+	if box[1] > box[3] {
+		box[1], box[3] = box[3], box[1]
+	}
+	return box
+}
+
+// copier deep-copies objects from a Reader, renumbering references.
+type copier struct {
+	doc  *Document
+	src  *Reader
+	refs map[int]Ref
+}
+
+func (c *copier) copy(obj Object) Object {
+	switch v := obj.(type) {
+	case Ref:
+		if ref, ok := c.refs[v.Num]; ok {
+			return ref
+		}
+		ref := c.doc.add(nil)
+		c.refs[v.Num] = ref
+		c.doc.set(ref, c.copy(c.src.Resolve(v)))
+		return ref
+	case Array:
+		out := make(Array, len(v))
+		for i, item := range v {
+			out[i] = c.copy(item)
+		}
+		return out
+	case Dict:
+		return c.copyDict(v)
+	case *Stream:
+		dict := c.copyDict(v.Dict)
+		delete(dict, "Length")
+		return &Stream{Dict: dict, Data: v.Data}
+	}
+	return obj
+}
+
+// copyDict skips keys that point back into the source document structure.
+func (c *copier) copyDict(d Dict) Dict {
+	out := make(Dict, len(d))
+	for k, v := range d {
+		switch k {
+		case "Parent", "P", "Dest", "B", "StructParent":
+			continue
+		}
+		out[k] = c.copy(v)
+	}
+	return out
+}
+
+// copyAnnots keeps annotations except links, whose targets are not copied.
+func (c *copier) copyAnnots(obj Object) Array {
+	arr, _ := c.src.Resolve(obj).(Array)
+	var out Array
+	for _, item := range arr {
+		annot, ok := c.src.Resolve(item).(Dict)
+		if !ok || annot["Subtype"] == Name("Link") || annot["Subtype"] == Name("Popup") {
+			continue
+		}
+		out = append(out, c.copy(item))
+	}
+	return out
+}
+
+// Bytes renders the document.
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteTo renders the document to w.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	// Fonts are shared objects; create them before the per-write objects
+	for _, p := range d.pages {
+		for f := range p.fonts {
+			d.fontRef(f)
+		}
+	}
+
+	pagesRef := d.add(nil)
+	kids := make(Array, 0, len(d.pages))
+	for _, p := range d.pages {
+		kids = append(kids, d.add(p.dict(pagesRef)))
+	}
+	d.set(pagesRef, Dict{"Type": Name("Pages"), "Kids": kids, "Count": len(kids)})
+
+	catalog := d.add(Dict{"Type": Name("Catalog"), "Pages": pagesRef})
+	info := Dict{"Producer": String("MERA SIMRS")}
+	if d.title != "" {
+		info["Title"] = textString(d.title)
+	}
+	infoRef := d.add(info)
+
+	cw := &countingWriter{w: w}
+	io.WriteString(cw, "%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+
+	offsets := make([]int64, len(d.objects))
+	var buf bytes.Buffer
+	for i, obj := range d.objects {
+		offsets[i] = cw.n
+		buf.Reset()
+		fmt.Fprintf(&buf, "%d 0 obj\n", i+1)
+		writeObject(&buf, obj)
+		buf.WriteString("\nendobj\n")
+		cw.Write(buf.Bytes())
+	}
+
+	xrefOffset := cw.n
+	fmt.Fprintf(cw, "xref\n0 %d\n0000000000 65535 f \n", len(d.objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(cw, "%010d 00000 n \n", off)
+	}
+
+	buf.Reset()
+	writeDict(&buf, Dict{"Size": len(d.objects) + 1, "Root": catalog, "Info": infoRef})
+	fmt.Fprintf(cw, "trailer\n%s\nstartxref\n%d\n%%%%EOF\n", buf.String(), xrefOffset)
+
+	// Objects are final once written
+	d.objects = d.objects[:pagesRef.Num-1]
+	return cw.n, cw.err
+}
+
+// dict builds the page dictionary, adding content and resource objects.
+func (p *Page) dict(parent Ref) Dict {
+	if p.imported != nil {
+		return p.importedDict(parent)
+	}
+
+	resources := Dict{"ProcSet": Array{Name("PDF"), Name("Text"), Name("ImageC"), Name("ImageB")}}
+	if len(p.fonts) > 0 {
+		fonts := Dict{}
+		for f := range p.fonts {
+			fonts[f.resourceName()] = p.doc.fontRef(f)
+		}
+		resources["Font"] = fonts
+	}
+	if len(p.images) > 0 {
+		resources["XObject"] = p.xobjects()
+	}
+
+	return Dict{
+		"Type":      Name("Page"),
+		"Parent":    parent,
+		"MediaBox":  Array{0, 0, p.width, p.height},
+		"Resources": resources,
+		"Contents":  p.doc.add(contentStream(p.content.Bytes())),
+	}
+}
+
+// importedDict wraps the original content in q/Q and appends the overlay.
+func (p *Page) importedDict(parent Ref) Dict {
+	page := Dict{}
+	for k, v := range p.imported {
+		page[k] = v
+	}
+	page["Type"] = Name("Page")
+	page["Parent"] = parent
+	if p.content.Len() == 0 {
+		return page
+	}
+
+	var contents Array
+	switch v := page["Contents"].(type) {
+	case Array:
+		contents = append(contents, v...)
+	case nil:
+	default:
+		contents = Array{v}
+	}
+	overlay := fmt.Sprintf("Q q 1 0 0 1 %s %s cm %s cm\n",
+		formatNumber(p.origin[0]), formatNumber(p.origin[1]), p.rotationMatrix())
+	contents = append(Array{p.doc.add(contentStream([]byte("q\n")))}, contents...)
+	contents = append(contents, p.doc.add(contentStream(append([]byte(overlay), append(p.content.Bytes(), "Q\n"...)...))))
+	page["Contents"] = contents
+
+	// Merge overlay fonts and images into a private copy of the resources
+	resources := Dict{}
+	if res, ok := p.doc.resolve(page["Resources"]).(Dict); ok {
+		for k, v := range res {
+			resources[k] = v
+		}
+	}
+	if len(p.fonts) > 0 {
+		fonts := Dict{}
+		if existing, ok := p.doc.resolve(resources["Font"]).(Dict); ok {
+			for k, v := range existing {
+				fonts[k] = v
+			}
+		}
+		for f := range p.fonts {
+			fonts["Mera"+f.resourceName()] = p.doc.fontRef(f)
+		}
+		resources["Font"] = fonts
+	}
+	if len(p.images) > 0 {
+		xobjects := Dict{}
+		if existing, ok := p.doc.resolve(resources["XObject"]).(Dict); ok {
+			for k, v := range existing {
+				xobjects[k] = v
+			}
+		}
+		for name, ref := range p.images {
+			xobjects[name] = ref
+		}
+		resources["XObject"] = xobjects
+	}
+	page["Resources"] = resources
+	return page
+}
+
+// rotationMatrix maps displayed coordinates to unrotated page space.
+func (p *Page) rotationMatrix() string {
+	// width and height are the displayed size
+	w, h := formatNumber(p.width), formatNumber(p.height)
+	switch p.rotate {
+	case 90:
+		return "0 1 -1 0 " + h + " 0"
+	case 180:
+		return "-1 0 0 -1 " + w + " " + h
+	case 270:
+		return "0 -1 1 0 0 " + w
+	}
+	return "1 0 0 1 0 0"
+}
+
+func (p *Page) xobjects() Dict {
+	xobjects := Dict{}
+	for name, ref := range p.images {
+		xobjects[name] = ref
+	}
+	return xobjects
+}
+
+// resolve follows references to objects already in the document.
+func (d *Document) resolve(obj Object) Object {
+	for i := 0; i < 32; i++ {
+		ref, ok := obj.(Ref)
+		if !ok {
+			return obj
+		}
+		if ref.Num < 1 || ref.Num > len(d.objects) {
+			return nil
+		}
+		obj = d.objects[ref.Num-1]
+	}
+	return nil
+}
+
+func contentStream(data []byte) *Stream {
+	return &Stream{Dict: Dict{"Filter": Name("FlateDecode")}, Data: deflate(data)}
+}
+
+// textString encodes s as a UTF-16BE text string with byte order mark.
+func textString(s string) String {
+	out := []byte{0xfe, 0xff}
+	for _, u := range utf16.Encode([]rune(s)) {
+		out = append(out, byte(u>>8), byte(u))
+	}
+	return String(out)
+}
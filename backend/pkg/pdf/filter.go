@@ -0,0 +1,173 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrUnsupportedFilter indicates a stream filter the reader cannot decode.
+var ErrUnsupportedFilter = errors.New("unsupported pdf stream filter")
+
+// ErrStreamTooLarge indicates a stream that inflates past maxDecodedStream.
+var ErrStreamTooLarge = errors.New("pdf stream too large")
+
+// maxDecodedStream caps the inflated size of one stream, so a small
+// compressed stream in an uploaded file cannot expand to gigabytes. Object
+// and xref streams are far smaller.
+const maxDecodedStream = 32 << 20
+
+// decodeStream returns the decoded data of a stream. Only FlateDecode
+// (with PNG/TIFF predictors) is supported, which covers the object and
+// xref streams the reader needs; page content is copied undecoded.
+func decodeStream(s *Stream, resolve func(Object) Object) ([]byte, error) {
+	filters := []Object{}
+	switch f := resolve(s.Dict["Filter"]).(type) {
+	case nil:
+	case Name:
+		filters = append(filters, f)
+	case Array:
+		filters = append(filters, f...)
+	}
+
+	params := []Object{}
+	switch p := resolve(s.Dict["DecodeParms"]).(type) {
+	case Dict:
+		params = append(params, p)
+	case Array:
+		params = append(params, p...)
+	}
+
+	data := s.Data
+	for i, f := range filters {
+		name, _ := resolve(f).(Name)
+		if name != "FlateDecode" && name != "Fl" {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedFilter, name)
+		}
+
+		zr, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+		decoded, err := io.ReadAll(io.LimitReader(zr, maxDecodedStream+1))
+		if len(decoded) > maxDecodedStream {
+			return nil, fmt.Errorf("%w: inflates past %d bytes", ErrStreamTooLarge, maxDecodedStream)
+		}
+		// Truncated streams are common; keep what was inflated
+		if err != nil && len(decoded) == 0 {
+			return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+		data = decoded
+
+		if i < len(params) {
+			if p, ok := resolve(params[i]).(Dict); ok {
+				if data, err = unpredict(data, p); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return data, nil
+}
+
+// unpredict reverses PNG (>= 10) and TIFF (2) predictors.
+func unpredict(data []byte, params Dict) ([]byte, error) {
+	predictor := intValue(params["Predictor"], 1)
+	if predictor == 1 {
+		return data, nil
+	}
+	colors := intValue(params["Colors"], 1)
+	bpc := intValue(params["BitsPerComponent"], 8)
+	columns := intValue(params["Columns"], 1)
+
+	bpp := (colors*bpc + 7) / 8
+	rowLen := (colors*bpc*columns + 7) / 8
+	if rowLen <= 0 {
+		return nil, ErrMalformed
+	}
+
+	if predictor == 2 {
+		if bpc != 8 {
+			return nil, fmt.Errorf("%w: tiff predictor with %d bits", ErrUnsupportedFilter, bpc)
+		}
+		out := append([]byte{}, data...)
+		for row := 0; row+rowLen <= len(out); row += rowLen {
+			for i := bpp; i < rowLen; i++ {
+				out[row+i] += out[row+i-bpp]
+			}
+		}
+		return out, nil
+	}
+
+	out := make([]byte, 0, len(data))
+	prev := make([]byte, rowLen)
+	for pos := 0; pos+1+rowLen <= len(data); pos += rowLen + 1 {
+		filter := data[pos]
+		row := append([]byte{}, data[pos+1:pos+1+rowLen]...)
+		for i := range row {
+			var left, upLeft byte
+			if i >= bpp {
+				left = row[i-bpp]
+				upLeft = prev[i-bpp]
+			}
+			up := prev[i]
+			switch filter {
+			case 0:
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += up
+			case 3:
+				row[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[i] += paeth(left, up, upLeft)
+			default:
+				return nil, fmt.Errorf("%w: png filter %d", ErrMalformed, filter)
+			}
+		}
+		out = append(out, row...)
+		prev = row
+	}
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	if pa <= pb && pa <= pc {
+		return a
+	}
+	if pb <= pc {
+		return b
+	}
+	return c
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func intValue(obj Object, def int) int {
+	switch v := obj.(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return def
+}
+
+func floatValue(obj Object) (float64, bool) {
+	switch v := obj.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
@@ -0,0 +1,112 @@
+package pdf
+
+// Font is one of the standard 14 fonts used by the writer.
+type Font int
+
+// Supported fonts. Both use WinAnsiEncoding.
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+func (f Font) resourceName() Name {
+	if f == HelveticaBold {
+		return "F2"
+	}
+	return "F1"
+}
+
+func (f Font) baseFont() Name {
+	if f == HelveticaBold {
+		return "Helvetica-Bold"
+	}
+	return "Helvetica"
+}
+
+// Glyph widths in 1/1000 em for the printable ASCII range 32..126.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// winAnsiExtra maps the non-Latin-1 characters of WinAnsiEncoding (0x80-0x9F).
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91,
+	'’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98,
+	'™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// latinBase maps accented Latin-1 letters to the ASCII letter whose width
+// they share; other high characters fall back to a default width.
+const latinBase = "AAAAAAACEEEEIIIIDNOOOOO*OUUUUYPsaaaaaaaceeeeiiiidnooooo/ouuuuypy"
+
+// encodeWinAnsi converts UTF-8 text to WinAnsiEncoding, replacing
+// characters the standard fonts cannot show with '?'.
+func encodeWinAnsi(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == '\t':
+			out = append(out, ' ')
+		case r >= 32 && r <= 126, r >= 0xA0 && r <= 0xFF:
+			out = append(out, byte(r))
+		case r < 32:
+			// Control characters are dropped
+		default:
+			if b, ok := winAnsiExtra[r]; ok {
+				out = append(out, b)
+			} else {
+				out = append(out, '?')
+			}
+		}
+	}
+	return out
+}
+
+// charWidth returns the width of an encoded byte in 1/1000 em.
+func (f Font) charWidth(c byte) int {
+	widths := &helveticaWidths
+	if f == HelveticaBold {
+		widths = &helveticaBoldWidths
+	}
+
+	switch {
+	case c >= 32 && c <= 126:
+		return widths[c-32]
+	case c >= 0xC0:
+		base := latinBase[c-0xC0]
+		switch base {
+		case '*', '/':
+			return 584
+		case 'P':
+			return 667
+		}
+		return widths[base-32]
+	case c == 0x97, c == 0x89, c == 0x99:
+		return 1000
+	}
+	return 556
+}
+
+// TextWidth returns the width of s in points at the given size.
+func TextWidth(font Font, size float64, s string) float64 {
+	total := 0
+	for _, c := range encodeWinAnsi(s) {
+		total += font.charWidth(c)
+	}
+	return float64(total) * size / 1000
+}
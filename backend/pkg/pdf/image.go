@@ -0,0 +1,126 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"  // register GIF decoder
+	_ "image/jpeg" // register JPEG decoder
+	_ "image/png"  // register PNG decoder
+)
+
+// ErrUnsupportedImage indicates image data that cannot be embedded.
+var ErrUnsupportedImage = errors.New("unsupported image format")
+
+// ErrImageTooLarge indicates an image with more pixels than the document
+// accepts. The header is checked before decoding, so a small file declaring
+// huge dimensions cannot exhaust memory.
+var ErrImageTooLarge = errors.New("image dimensions too large")
+
+// DefaultMaxImagePixels is the pixel limit of a new Document: 40 MP, above a
+// 600 dpi A4 scan.
+const DefaultMaxImagePixels = 40_000_000
+
+// ImageTooLarge reports whether a width x height image exceeds maxPixels.
+func ImageTooLarge(width, height, maxPixels int) bool {
+	return int64(width)*int64(height) > int64(maxPixels)
+}
+
+// Image is an image XObject added to a Document.
+type Image struct {
+	ref    Ref
+	Width  int
+	Height int
+}
+
+// AddImage embeds a JPEG, PNG or GIF image. JPEG data is embedded as is;
+// other formats are flattened onto white and stored losslessly. Images over
+// the document's pixel limit fail with ErrImageTooLarge.
+func (d *Document) AddImage(data []byte) (*Image, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, ErrUnsupportedImage
+	}
+	if ImageTooLarge(cfg.Width, cfg.Height, d.maxImagePixels) {
+		return nil, fmt.Errorf("%w: %dx%d", ErrImageTooLarge, cfg.Width, cfg.Height)
+	}
+
+	dict := Dict{
+		"Type":             Name("XObject"),
+		"Subtype":          Name("Image"),
+		"Width":            cfg.Width,
+		"Height":           cfg.Height,
+		"BitsPerComponent": 8,
+	}
+
+	if format == "jpeg" {
+		switch cfg.ColorModel {
+		case color.GrayModel:
+			dict["ColorSpace"] = Name("DeviceGray")
+		case color.CMYKModel:
+			// Adobe CMYK JPEGs are stored inverted
+			dict["ColorSpace"] = Name("DeviceCMYK")
+			dict["Decode"] = Array{1, 0, 1, 0, 1, 0, 1, 0}
+		default:
+			dict["ColorSpace"] = Name("DeviceRGB")
+		}
+		dict["Filter"] = Name("DCTDecode")
+		ref := d.add(&Stream{Dict: dict, Data: data})
+		return &Image{ref: ref, Width: cfg.Width, Height: cfg.Height}, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+
+	raw, gray := flatten(img)
+	if gray {
+		dict["ColorSpace"] = Name("DeviceGray")
+	} else {
+		dict["ColorSpace"] = Name("DeviceRGB")
+	}
+	dict["Filter"] = Name("FlateDecode")
+	ref := d.add(&Stream{Dict: dict, Data: deflate(raw)})
+	return &Image{ref: ref, Width: cfg.Width, Height: cfg.Height}, nil
+}
+
+// flatten converts img to 8-bit gray or RGB samples composited on white.
+func flatten(img image.Image) ([]byte, bool) {
+	b := img.Bounds()
+	_, gray := img.(*image.Gray)
+
+	channels := 3
+	if gray {
+		channels = 1
+	}
+	out := make([]byte, 0, b.Dx()*b.Dy()*channels)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, a := img.At(x, y).RGBA()
+			// Premultiplied: add the white background for the uncovered part
+			white := 0xffff - a
+			r, g, bl = (r+white)>>8, (g+white)>>8, (bl+white)>>8
+			if gray {
+				out = append(out, byte(r))
+			} else {
+				out = append(out, byte(r), byte(g), byte(bl))
+			}
+		}
+	}
+	return out, gray
+}
+
+func deflate(data []byte) []byte {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	return buf.Bytes()
+}
@@ -0,0 +1,69 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 0x80, A: 0xff})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// oversizedPNG returns a small valid PNG whose IHDR declares width x height.
+func oversizedPNG(t *testing.T, width, height uint32) []byte {
+	t.Helper()
+	data := encodePNG(t, 2, 2)
+	// Signature (8), chunk length (4), "IHDR" (4), then width and height
+	binary.BigEndian.PutUint32(data[16:], width)
+	binary.BigEndian.PutUint32(data[20:], height)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestAddImageRejectsOversizedHeader(t *testing.T) {
+	data := oversizedPNG(t, 60000, 60000)
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(data)); err != nil || cfg.Width != 60000 {
+		t.Fatalf("crafted PNG header: %+v, %v", cfg, err)
+	}
+
+	doc := New()
+	if _, err := doc.AddImage(data); !errors.Is(err, ErrImageTooLarge) {
+		t.Fatalf("AddImage = %v, want ErrImageTooLarge", err)
+	}
+}
+
+func TestAddImagePixelLimit(t *testing.T) {
+	data := encodePNG(t, 40, 30)
+
+	doc := New()
+	doc.SetMaxImagePixels(40*30 - 1)
+	if _, err := doc.AddImage(data); !errors.Is(err, ErrImageTooLarge) {
+		t.Fatalf("AddImage over the limit = %v, want ErrImageTooLarge", err)
+	}
+
+	doc.SetMaxImagePixels(40 * 30)
+	img, err := doc.AddImage(data)
+	if err != nil {
+		t.Fatalf("AddImage at the limit: %v", err)
+	}
+	if img.Width != 40 || img.Height != 30 {
+		t.Fatalf("image is %dx%d, want 40x30", img.Width, img.Height)
+	}
+}
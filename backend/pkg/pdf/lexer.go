@@ -0,0 +1,264 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+)
+
+// ErrMalformed indicates input that could not be parsed as PDF.
+var ErrMalformed = errors.New("malformed pdf")
+
+func isWhitespace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+// lexer parses PDF objects from a byte slice.
+type lexer struct {
+	data []byte
+	pos  int
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isWhitespace(c) {
+			l.pos++
+			continue
+		}
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		return
+	}
+}
+
+// keyword reads a run of regular characters (a keyword or number).
+func (l *lexer) keyword() string {
+	l.skipSpace()
+	start := l.pos
+	for l.pos < len(l.data) && !isWhitespace(l.data[l.pos]) && !isDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+// expect consumes kw or fails.
+func (l *lexer) expect(kw string) error {
+	if l.keyword() != kw {
+		return ErrMalformed
+	}
+	return nil
+}
+
+// object parses the next object. Integers followed by "gen R" become Refs.
+func (l *lexer) object() (Object, error) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, ErrMalformed
+	}
+
+	switch c := l.data[l.pos]; {
+	case c == '/':
+		return l.name(), nil
+	case c == '(':
+		return l.literalString()
+	case c == '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			return l.dict()
+		}
+		return l.hexString()
+	case c == '[':
+		l.pos++
+		arr := Array{}
+		for {
+			l.skipSpace()
+			if l.pos >= len(l.data) {
+				return nil, ErrMalformed
+			}
+			if l.data[l.pos] == ']' {
+				l.pos++
+				return arr, nil
+			}
+			item, err := l.object()
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, item)
+		}
+	case isDelimiter(c):
+		return nil, ErrMalformed
+	}
+
+	word := l.keyword()
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	case "":
+		return nil, ErrMalformed
+	}
+
+	n, err := strconv.Atoi(word)
+	if err != nil {
+		f, ferr := strconv.ParseFloat(word, 64)
+		if ferr != nil {
+			return nil, ErrMalformed
+		}
+		return f, nil
+	}
+
+	// Look ahead for "gen R"
+	save := l.pos
+	if gen, err := strconv.Atoi(l.keyword()); err == nil && l.keyword() == "R" {
+		return Ref{Num: n, Gen: gen}, nil
+	}
+	l.pos = save
+	return n, nil
+}
+
+func (l *lexer) name() Name {
+	l.pos++ // '/'
+	var buf bytes.Buffer
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isWhitespace(c) || isDelimiter(c) {
+			break
+		}
+		if c == '#' && l.pos+2 < len(l.data) {
+			if v, err := strconv.ParseUint(string(l.data[l.pos+1:l.pos+3]), 16, 8); err == nil {
+				buf.WriteByte(byte(v))
+				l.pos += 3
+				continue
+			}
+		}
+		buf.WriteByte(c)
+		l.pos++
+	}
+	return Name(buf.String())
+}
+
+func (l *lexer) dict() (Object, error) {
+	l.pos += 2 // "<<"
+	d := Dict{}
+	for {
+		l.skipSpace()
+		if l.pos+1 >= len(l.data) {
+			return nil, ErrMalformed
+		}
+		if l.data[l.pos] == '>' && l.data[l.pos+1] == '>' {
+			l.pos += 2
+			return d, nil
+		}
+		if l.data[l.pos] != '/' {
+			return nil, ErrMalformed
+		}
+		key := l.name()
+		val, err := l.object()
+		if err != nil {
+			return nil, err
+		}
+		d[key] = val
+	}
+}
+
+func (l *lexer) literalString() (Object, error) {
+	l.pos++ // '('
+	var buf bytes.Buffer
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return String(buf.Bytes()), nil
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return nil, ErrMalformed
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				buf.WriteByte('\n')
+			case 'r':
+				buf.WriteByte('\r')
+			case 't':
+				buf.WriteByte('\t')
+			case 'b':
+				buf.WriteByte('\b')
+			case 'f':
+				buf.WriteByte('\f')
+			case '\r':
+				// Line continuation
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					buf.WriteByte(byte(v))
+				} else {
+					buf.WriteByte(e)
+				}
+			}
+			continue
+		}
+		buf.WriteByte(c)
+	}
+	return nil, ErrMalformed
+}
+
+func (l *lexer) hexString() (Object, error) {
+	l.pos++ // '<'
+	var out []byte
+	var hi int = -1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		if c == '>' {
+			if hi >= 0 {
+				out = append(out, byte(hi<<4))
+			}
+			return String(out), nil
+		}
+		if isWhitespace(c) {
+			continue
+		}
+		v, err := strconv.ParseUint(string(c), 16, 8)
+		if err != nil {
+			return nil, ErrMalformed
+		}
+		if hi < 0 {
+			hi = int(v)
+		} else {
+			out = append(out, byte(hi<<4|int(v)))
+			hi = -1
+		}
+	}
+	return nil, ErrMalformed
+}
@@ -0,0 +1,170 @@
+// Package pdf is a small pure-Go PDF writer with page import support.
+//
+// It draws text in the standard Helvetica fonts, lines, rectangles and
+// JPEG/PNG/GIF images, and can append the pages of existing PDF files,
+// which is enough to assemble printable documents without cgo or external
+// tools.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Object is a PDF object: nil, bool, int, float64, String, Name, Array,
+// Dict, Ref or *Stream.
+type Object interface{}
+
+// Name is a PDF name object, written as /Name.
+type Name string
+
+// String is a PDF string object.
+type String []byte
+
+// Array is a PDF array object.
+type Array []Object
+
+// Dict is a PDF dictionary object.
+type Dict map[Name]Object
+
+// Ref is an indirect object reference.
+type Ref struct {
+	Num int
+	Gen int
+}
+
+// Stream is a stream object; Data is stored encoded, as named by /Filter.
+type Stream struct {
+	Dict Dict
+	Data []byte
+}
+
+// writeObject serializes obj in PDF syntax.
+func writeObject(w *bytes.Buffer, obj Object) {
+	switch v := obj.(type) {
+	case nil:
+		w.WriteString("null")
+	case bool:
+		if v {
+			w.WriteString("true")
+		} else {
+			w.WriteString("false")
+		}
+	case int:
+		w.WriteString(strconv.Itoa(v))
+	case int64:
+		w.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		w.WriteString(formatNumber(v))
+	case Name:
+		writeName(w, v)
+	case String:
+		writeString(w, v)
+	case Array:
+		w.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				w.WriteByte(' ')
+			}
+			writeObject(w, item)
+		}
+		w.WriteByte(']')
+	case Dict:
+		writeDict(w, v)
+	case Ref:
+		fmt.Fprintf(w, "%d %d R", v.Num, v.Gen)
+	case *Stream:
+		dict := make(Dict, len(v.Dict)+1)
+		for k, val := range v.Dict {
+			dict[k] = val
+		}
+		dict["Length"] = len(v.Data)
+		writeDict(w, dict)
+		w.WriteString("\nstream\n")
+		w.Write(v.Data)
+		w.WriteString("\nendstream")
+	default:
+		w.WriteString("null")
+	}
+}
+
+func writeDict(w *bytes.Buffer, d Dict) {
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		keys = append(keys, string(k))
+	}
+	sort.Strings(keys)
+
+	w.WriteString("<<")
+	for _, k := range keys {
+		writeName(w, Name(k))
+		w.WriteByte(' ')
+		writeObject(w, d[Name(k)])
+	}
+	w.WriteString(">>")
+}
+
+func writeName(w *bytes.Buffer, n Name) {
+	w.WriteByte('/')
+	for i := 0; i < len(n); i++ {
+		c := n[i]
+		if c < '!' || c > '~' || c == '#' || isDelimiter(c) {
+			fmt.Fprintf(w, "#%02X", c)
+			continue
+		}
+		w.WriteByte(c)
+	}
+}
+
+func writeString(w *bytes.Buffer, s []byte) {
+	w.WriteByte('(')
+	for _, c := range s {
+		switch c {
+		case '(', ')', '\\':
+			w.WriteByte('\\')
+			w.WriteByte(c)
+		case '\r':
+			w.WriteString(`\r`)
+		case '\n':
+			w.WriteString(`\n`)
+		default:
+			w.WriteByte(c)
+		}
+	}
+	w.WriteByte(')')
+}
+
+// formatNumber writes a real with at most 4 decimals and no exponent.
+func formatNumber(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 4, 64)
+	for len(s) > 1 && s[len(s)-1] == '0' {
+		s = s[:len(s)-1]
+	}
+	if s[len(s)-1] == '.' {
+		s = s[:len(s)-1]
+	}
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// countingWriter tracks the byte offset for the xref table.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
@@ -0,0 +1,480 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var (
+	// ErrEncrypted indicates an encrypted PDF, which cannot be imported.
+	ErrEncrypted = errors.New("pdf is encrypted")
+
+	// ErrNoPages indicates a PDF without a readable page tree.
+	ErrNoPages = errors.New("pdf has no pages")
+)
+
+type xrefEntry struct {
+	offset    int // byte offset, or index inside the object stream
+	objStream int // containing object stream number, 0 if none
+}
+
+// Reader gives access to the objects and pages of an existing PDF.
+type Reader struct {
+	data    []byte
+	xref    map[int]xrefEntry
+	trailer Dict
+	cache   map[int]Object
+	loading map[int]bool
+	decoded map[int][]byte // Decoded object streams
+}
+
+// NewReader parses the cross-reference data of a PDF file. Damaged
+// cross-reference tables are rebuilt by scanning the file for objects.
+func NewReader(data []byte) (*Reader, error) {
+	if !bytes.Contains(data[:min(len(data), 1024)], []byte("%PDF-")) {
+		return nil, fmt.Errorf("%w: missing header", ErrMalformed)
+	}
+
+	r := &Reader{
+		data:    data,
+		xref:    make(map[int]xrefEntry),
+		cache:   make(map[int]Object),
+		loading: make(map[int]bool),
+		decoded: make(map[int][]byte),
+	}
+	if err := r.readXref(); err != nil || r.trailer["Root"] == nil {
+		r.xref = make(map[int]xrefEntry)
+		r.cache = make(map[int]Object)
+		if err := r.reconstruct(); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.trailer["Encrypt"] != nil {
+		return nil, ErrEncrypted
+	}
+	return r, nil
+}
+
+// Resolve follows indirect references. Missing objects resolve to nil.
+func (r *Reader) Resolve(obj Object) Object {
+	for i := 0; i < 32; i++ {
+		ref, ok := obj.(Ref)
+		if !ok {
+			return obj
+		}
+		obj = r.object(ref.Num)
+	}
+	return nil
+}
+
+// Pages returns the page dictionaries in order, with inherited
+// Resources, MediaBox, CropBox and Rotate copied into each page.
+func (r *Reader) Pages() ([]Dict, error) {
+	root, _ := r.Resolve(r.trailer["Root"]).(Dict)
+	if root == nil {
+		return nil, ErrNoPages
+	}
+
+	var pages []Dict
+	seen := map[int]bool{}
+	var walk func(node Object, inherited Dict, depth int)
+	walk = func(node Object, inherited Dict, depth int) {
+		if ref, ok := node.(Ref); ok {
+			if seen[ref.Num] {
+				return
+			}
+			seen[ref.Num] = true
+		}
+		dict, _ := r.Resolve(node).(Dict)
+		if dict == nil || depth > 64 {
+			return
+		}
+
+		attrs := Dict{}
+		for k, v := range inherited {
+			attrs[k] = v
+		}
+		for _, k := range []Name{"Resources", "MediaBox", "CropBox", "Rotate"} {
+			if v, ok := dict[k]; ok {
+				attrs[k] = v
+			}
+		}
+
+		kids, isTree := r.Resolve(dict["Kids"]).(Array)
+		if isTree && dict["Type"] != Name("Page") {
+			for _, kid := range kids {
+				walk(kid, attrs, depth+1)
+			}
+			return
+		}
+
+		page := Dict{}
+		for k, v := range dict {
+			page[k] = v
+		}
+		for k, v := range attrs {
+			page[k] = v
+		}
+		pages = append(pages, page)
+	}
+	walk(root["Pages"], Dict{}, 0)
+
+	if len(pages) == 0 {
+		return nil, ErrNoPages
+	}
+	return pages, nil
+}
+
+// object loads object num, caching the result.
+func (r *Reader) object(num int) Object {
+	if obj, ok := r.cache[num]; ok {
+		return obj
+	}
+	entry, ok := r.xref[num]
+	if !ok || r.loading[num] {
+		return nil
+	}
+	r.loading[num] = true
+	defer delete(r.loading, num)
+
+	var obj Object
+	var err error
+	if entry.objStream != 0 {
+		obj, err = r.objectFromStream(entry.objStream, entry.offset)
+	} else {
+		obj, err = r.objectAt(entry.offset, num)
+	}
+	if err != nil {
+		obj = nil
+	}
+	r.cache[num] = obj
+	return obj
+}
+
+// objectAt parses "num gen obj ... endobj" at offset.
+func (r *Reader) objectAt(offset, num int) (Object, error) {
+	if offset < 0 || offset >= len(r.data) {
+		return nil, ErrMalformed
+	}
+	l := &lexer{data: r.data, pos: offset}
+	if n, err := strconv.Atoi(l.keyword()); err != nil || (num >= 0 && n != num) {
+		return nil, ErrMalformed
+	}
+	if _, err := strconv.Atoi(l.keyword()); err != nil {
+		return nil, ErrMalformed
+	}
+	if err := l.expect("obj"); err != nil {
+		return nil, err
+	}
+
+	obj, err := l.object()
+	if err != nil {
+		return nil, err
+	}
+
+	dict, ok := obj.(Dict)
+	if !ok {
+		return obj, nil
+	}
+	save := l.pos
+	if l.keyword() != "stream" {
+		l.pos = save
+		return dict, nil
+	}
+	return r.streamData(dict, l.pos)
+}
+
+// streamData reads stream bytes following the "stream" keyword at pos.
+func (r *Reader) streamData(dict Dict, pos int) (*Stream, error) {
+	if pos < len(r.data) && r.data[pos] == '\r' {
+		pos++
+	}
+	if pos < len(r.data) && r.data[pos] == '\n' {
+		pos++
+	}
+
+	length, ok := r.Resolve(dict["Length"]).(int)
+	end := pos + length
+	if ok && length >= 0 && end <= len(r.data) {
+		rest := r.data[end:min(len(r.data), end+32)]
+		if bytes.HasPrefix(bytes.TrimLeft(rest, "\r\n \t\f\x00"), []byte("endstream")) {
+			return &Stream{Dict: dict, Data: r.data[pos:end]}, nil
+		}
+	}
+
+	// Wrong or missing /Length: search for endstream
+	idx := bytes.Index(r.data[pos:], []byte("endstream"))
+	if idx < 0 {
+		return nil, ErrMalformed
+	}
+	end = pos + idx
+	for end > pos && (r.data[end-1] == '\n' || r.data[end-1] == '\r') {
+		end--
+	}
+	return &Stream{Dict: dict, Data: r.data[pos:end]}, nil
+}
+
+// objectFromStream loads the index-th object of object stream num.
+func (r *Reader) objectFromStream(num, index int) (Object, error) {
+	stream, ok := r.object(num).(*Stream)
+	if !ok {
+		return nil, ErrMalformed
+	}
+	// Decode once, not once per object it holds; a failed decode is kept
+	// as empty data
+	data, ok := r.decoded[num]
+	if !ok {
+		var err error
+		data, err = decodeStream(stream, r.Resolve)
+		r.decoded[num] = data
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	n := intValue(r.Resolve(stream.Dict["N"]), 0)
+	first := intValue(r.Resolve(stream.Dict["First"]), 0)
+	if index >= n || first > len(data) {
+		return nil, ErrMalformed
+	}
+
+	l := &lexer{data: data}
+	offset := -1
+	for i := 0; i <= index; i++ {
+		if _, err := strconv.Atoi(l.keyword()); err != nil {
+			return nil, ErrMalformed
+		}
+		var err error
+		if offset, err = strconv.Atoi(l.keyword()); err != nil {
+			return nil, ErrMalformed
+		}
+	}
+
+	l.pos = first + offset
+	return l.object()
+}
+
+// readXref follows startxref and the /Prev chain.
+func (r *Reader) readXref() error {
+	idx := bytes.LastIndex(r.data, []byte("startxref"))
+	if idx < 0 {
+		return ErrMalformed
+	}
+	l := &lexer{data: r.data, pos: idx + len("startxref")}
+	offset, err := strconv.Atoi(l.keyword())
+	if err != nil {
+		return ErrMalformed
+	}
+
+	visited := map[int]bool{}
+	for offset > 0 && !visited[offset] {
+		visited[offset] = true
+		trailer, err := r.readXrefSection(offset)
+		if err != nil {
+			return err
+		}
+		if r.trailer == nil {
+			r.trailer = trailer
+		}
+		// Hybrid files keep newer entries in an xref stream
+		if stm, ok := trailer["XRefStm"].(int); ok && !visited[stm] {
+			visited[stm] = true
+			if _, err := r.readXrefSection(stm); err != nil {
+				return err
+			}
+		}
+		offset = intValue(trailer["Prev"], 0)
+	}
+	if r.trailer == nil {
+		return ErrMalformed
+	}
+	return nil
+}
+
+// readXrefSection reads a table or stream at offset. Entries already
+// known from a newer section are kept.
+func (r *Reader) readXrefSection(offset int) (Dict, error) {
+	if offset >= len(r.data) {
+		return nil, ErrMalformed
+	}
+	l := &lexer{data: r.data, pos: offset}
+	if l.keyword() != "xref" {
+		return r.readXrefStream(offset)
+	}
+
+	for {
+		word := l.keyword()
+		if word == "trailer" {
+			break
+		}
+		start, err := strconv.Atoi(word)
+		if err != nil {
+			return nil, ErrMalformed
+		}
+		count, err := strconv.Atoi(l.keyword())
+		if err != nil {
+			return nil, ErrMalformed
+		}
+		for i := 0; i < count; i++ {
+			off, err1 := strconv.Atoi(l.keyword())
+			_, err2 := strconv.Atoi(l.keyword())
+			kind := l.keyword()
+			if err1 != nil || err2 != nil {
+				return nil, ErrMalformed
+			}
+			num := start + i
+			if _, known := r.xref[num]; known || kind != "n" {
+				continue
+			}
+			r.xref[num] = xrefEntry{offset: off}
+		}
+	}
+
+	obj, err := l.object()
+	if err != nil {
+		return nil, err
+	}
+	trailer, ok := obj.(Dict)
+	if !ok {
+		return nil, ErrMalformed
+	}
+	return trailer, nil
+}
+
+func (r *Reader) readXrefStream(offset int) (Dict, error) {
+	obj, err := r.objectAt(offset, -1)
+	if err != nil {
+		return nil, err
+	}
+	stream, ok := obj.(*Stream)
+	if !ok || stream.Dict["Type"] != Name("XRef") {
+		return nil, ErrMalformed
+	}
+	data, err := decodeStream(stream, func(o Object) Object { return o })
+	if err != nil {
+		return nil, err
+	}
+
+	w, _ := stream.Dict["W"].(Array)
+	if len(w) != 3 {
+		return nil, ErrMalformed
+	}
+	widths := []int{intValue(w[0], 0), intValue(w[1], 0), intValue(w[2], 0)}
+	rowLen := widths[0] + widths[1] + widths[2]
+	if rowLen == 0 {
+		return nil, ErrMalformed
+	}
+
+	index, _ := stream.Dict["Index"].(Array)
+	if index == nil {
+		index = Array{0, intValue(stream.Dict["Size"], 0)}
+	}
+
+	pos := 0
+	for i := 0; i+1 < len(index); i += 2 {
+		start, count := intValue(index[i], 0), intValue(index[i+1], 0)
+		for j := 0; j < count && pos+rowLen <= len(data); j++ {
+			fields := [3]int{1, 0, 0} // type defaults to 1 when W[0] is 0
+			p := pos
+			for k := 0; k < 3; k++ {
+				if widths[k] == 0 {
+					continue
+				}
+				v := 0
+				for b := 0; b < widths[k]; b++ {
+					v = v<<8 | int(data[p])
+					p++
+				}
+				fields[k] = v
+			}
+			pos += rowLen
+
+			num := start + j
+			if _, known := r.xref[num]; known {
+				continue
+			}
+			switch fields[0] {
+			case 1:
+				r.xref[num] = xrefEntry{offset: fields[1]}
+			case 2:
+				r.xref[num] = xrefEntry{objStream: fields[1], offset: fields[2]}
+			}
+		}
+	}
+	return stream.Dict, nil
+}
+
+var objHeader = regexp.MustCompile(`(?m)(?:^|[\r\n\s])(\d+)\s+(\d+)\s+obj\b`)
+
+// reconstruct rebuilds the xref by scanning for "n g obj" headers.
+func (r *Reader) reconstruct() error {
+	for _, m := range objHeader.FindAllSubmatchIndex(r.data, -1) {
+		num, err := strconv.Atoi(string(r.data[m[2]:m[3]]))
+		if err != nil {
+			continue
+		}
+		// Later definitions win, as with incremental updates
+		r.xref[num] = xrefEntry{offset: m[2]}
+	}
+
+	// Register objects packed in object streams
+	for num := range r.xref {
+		if s, ok := r.object(num).(*Stream); ok && s.Dict["Type"] == Name("ObjStm") {
+			r.indexObjStream(num, s)
+		}
+	}
+
+	r.trailer = Dict{}
+	if idx := bytes.LastIndex(r.data, []byte("trailer")); idx >= 0 {
+		l := &lexer{data: r.data, pos: idx + len("trailer")}
+		if obj, err := l.object(); err == nil {
+			if d, ok := obj.(Dict); ok {
+				r.trailer = d
+			}
+		}
+	}
+	if r.trailer["Root"] != nil {
+		return nil
+	}
+
+	// Without a trailer, use an xref stream dictionary or the catalog
+	for num := range r.xref {
+		obj := r.object(num)
+		if s, ok := obj.(*Stream); ok && s.Dict["Type"] == Name("XRef") {
+			if s.Dict["Root"] != nil {
+				r.trailer = s.Dict
+				return nil
+			}
+		}
+		if d, ok := obj.(Dict); ok && d["Type"] == Name("Catalog") {
+			r.trailer["Root"] = Ref{Num: num}
+			return nil
+		}
+	}
+	return ErrNoPages
+}
+
+func (r *Reader) indexObjStream(num int, s *Stream) {
+	data, err := decodeStream(s, r.Resolve)
+	if err != nil {
+		return
+	}
+	n := intValue(r.Resolve(s.Dict["N"]), 0)
+	l := &lexer{data: data}
+	for i := 0; i < n; i++ {
+		objNum, err := strconv.Atoi(l.keyword())
+		if err != nil {
+			return
+		}
+		if _, err := strconv.Atoi(l.keyword()); err != nil {
+			return
+		}
+		if _, known := r.xref[objNum]; !known {
+			r.xref[objNum] = xrefEntry{objStream: num, offset: i}
+		}
+	}
+}
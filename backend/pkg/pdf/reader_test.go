@@ -0,0 +1,181 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// sampleDocument writes a document with a text page, an image page and a
+// drawing page.
+func sampleDocument(t *testing.T) []byte {
+	t.Helper()
+	doc := New()
+	doc.SetTitle("Uji")
+
+	p := doc.AddPage()
+	p.Text(50, 50, Helvetica, 12, "Halaman satu")
+
+	img, err := doc.AddImage(encodePNG(t, 8, 8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.AddPage().Image(img, 50, 50, 100, 100)
+
+	p = doc.AddPage()
+	p.Line(10, 10, 100, 100, 1)
+	p.Rect(20, 20, 50, 50, 0.5, 1)
+
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	data := sampleDocument(t)
+
+	r, err := NewReader(data)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	pages, err := r.Pages()
+	if err != nil {
+		t.Fatalf("Pages: %v", err)
+	}
+	if len(pages) != 3 {
+		t.Fatalf("got %d pages, want 3", len(pages))
+	}
+	for i, page := range pages {
+		if page["Type"] != Name("Page") {
+			t.Errorf("page %d has /Type %v", i, page["Type"])
+		}
+		if box, ok := r.Resolve(page["MediaBox"]).(Array); !ok || len(box) != 4 {
+			t.Errorf("page %d MediaBox = %v", i, page["MediaBox"])
+		}
+	}
+}
+
+func TestImportPDFRoundTrip(t *testing.T) {
+	src := sampleDocument(t)
+
+	doc := New()
+	doc.AddPage().Text(50, 50, HelveticaBold, 14, "Sampul")
+	imported, err := doc.ImportPDF(src)
+	if err != nil {
+		t.Fatalf("ImportPDF: %v", err)
+	}
+	if len(imported) != 3 || !imported[0].Imported() {
+		t.Fatalf("imported %d pages, want 3 imported pages", len(imported))
+	}
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(data)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	if pages, err := r.Pages(); err != nil || len(pages) != 4 {
+		t.Fatalf("Pages = %d, %v; want 4", len(pages), err)
+	}
+}
+
+func TestDamagedXrefIsRebuilt(t *testing.T) {
+	data := sampleDocument(t)
+	// Point startxref past the end of the file
+	idx := bytes.LastIndex(data, []byte("startxref"))
+	damaged := append(append([]byte{}, data[:idx]...), []byte("startxref\n99999999\n%%EOF\n")...)
+
+	r, err := NewReader(damaged)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	if pages, err := r.Pages(); err != nil || len(pages) != 3 {
+		t.Fatalf("Pages = %d, %v; want 3", len(pages), err)
+	}
+}
+
+func TestMalformedInputReturnsError(t *testing.T) {
+	tests := map[string][]byte{
+		"no header":         []byte("bukan pdf"),
+		"header only":       []byte("%PDF-1.7\n"),
+		"garbage xref":      []byte("%PDF-1.7\nxref\n0 zz\nabc\ntrailer\n<< /Size 3 >>\nstartxref\n9\n%%EOF\n"),
+		"unterminated dict": []byte("%PDF-1.7\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R\nstartxref\n0\n"),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := readPages(data); err == nil {
+				t.Fatal("got no error")
+			}
+		})
+	}
+}
+
+func TestTruncatedInputDoesNotPanic(t *testing.T) {
+	data := sampleDocument(t)
+	for n := 0; n < len(data); n += 7 {
+		func() {
+			defer func() {
+				if p := recover(); p != nil {
+					t.Fatalf("panic reading %d of %d bytes: %v", n, len(data), p)
+				}
+			}()
+			readPages(data[:n])
+		}()
+	}
+}
+
+func readPages(data []byte) ([]Dict, error) {
+	r, err := NewReader(data)
+	if err != nil {
+		return nil, err
+	}
+	return r.Pages()
+}
+
+func deflated(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	return buf.Bytes()
+}
+
+func TestDecodeStreamLimit(t *testing.T) {
+	small := &Stream{Dict: Dict{"Filter": Name("FlateDecode")}, Data: deflated(t, []byte("1 0 2 5 (a) (b)"))}
+	got, err := decodeStream(small, func(o Object) Object { return o })
+	if err != nil || string(got) != "1 0 2 5 (a) (b)" {
+		t.Fatalf("decodeStream = %q, %v", got, err)
+	}
+
+	bomb := &Stream{Dict: Dict{"Filter": Name("FlateDecode")}, Data: deflated(t, make([]byte, maxDecodedStream+1))}
+	if len(bomb.Data) > 128<<10 {
+		t.Fatalf("bomb is %d bytes compressed, expected well under 128 KB", len(bomb.Data))
+	}
+	if _, err := decodeStream(bomb, func(o Object) Object { return o }); !errors.Is(err, ErrStreamTooLarge) {
+		t.Fatalf("decodeStream = %v, want ErrStreamTooLarge", err)
+	}
+}
+
+func TestObjectStreamBombIsSkipped(t *testing.T) {
+	bomb := deflated(t, make([]byte, maxDecodedStream+1))
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	buf.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	buf.WriteString("2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n")
+	buf.WriteString("3 0 obj\n<< /Type /Page /Parent 2 0 R /MediaBox [0 0 100 100] >>\nendobj\n")
+	fmt.Fprintf(&buf, "4 0 obj\n<< /Type /ObjStm /N 1 /First 4 /Filter /FlateDecode /Length %d >>\nstream\n", len(bomb))
+	buf.Write(bomb)
+	buf.WriteString("\nendstream\nendobj\ntrailer\n<< /Root 1 0 R /Size 5 >>\n%%EOF\n")
+
+	pages, err := readPages(buf.Bytes())
+	if err != nil || len(pages) != 1 {
+		t.Fatalf("Pages = %d, %v; want 1", len(pages), err)
+	}
+}
@@ -0,0 +1,333 @@
+// Package redis is a minimal RESP2 client for Redis-compatible servers
+// (Redis, Valkey, KeyDB). It covers what the backend needs - plain commands
+// and MULTI/EXEC transactions - without pulling in a client library.
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned by a closed client.
+var ErrClosed = errors.New("redis: client closed")
+
+// Error is an error reply from the server. The connection stays usable.
+type Error string
+
+func (e Error) Error() string { return "redis: " + string(e) }
+
+// Config contains connection settings.
+type Config struct {
+	Addr        string // host:port
+	Password    string
+	DB          int
+	DialTimeout time.Duration // Default 5s
+	IOTimeout   time.Duration // Per command when ctx has no deadline; default 3s
+	PoolSize    int           // Idle connections kept; default 10
+}
+
+// Client is a pooled RESP client, safe for concurrent use.
+type Client struct {
+	cfg    Config
+	mu     sync.Mutex
+	idle   []*conn
+	closed bool
+}
+
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+// NewClient returns a client for cfg. Connections are opened on demand.
+func NewClient(cfg Config) *Client {
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = 5 * time.Second
+	}
+	if cfg.IOTimeout <= 0 {
+		cfg.IOTimeout = 3 * time.Second
+	}
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = 10
+	}
+	return &Client{cfg: cfg}
+}
+
+// Ping checks the server is reachable.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, "PING")
+	return err
+}
+
+// PingRetry pings the server up to attempts times, doubling delay after
+// each failure, and returns the last error. It stops early when ctx is done.
+func (c *Client) PingRetry(ctx context.Context, attempts int, delay time.Duration) error {
+	var err error
+	for i := 1; ; i++ {
+		if err = c.Ping(ctx); err == nil || i >= attempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// Do sends one command and returns its reply: string for simple and bulk
+// strings, int64 for integers, []interface{} for arrays, nil for nil
+// replies, or an Error.
+func (c *Client) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	replies, err := c.roundTrip(ctx, [][]interface{}{args})
+	if err != nil {
+		return nil, err
+	}
+	if e, ok := replies[0].(Error); ok {
+		return nil, e
+	}
+	return replies[0], nil
+}
+
+// Tx runs the commands in a MULTI/EXEC transaction and returns their
+// replies. An error reply from any command is returned as the error.
+func (c *Client) Tx(ctx context.Context, cmds ...[]interface{}) ([]interface{}, error) {
+	batch := make([][]interface{}, 0, len(cmds)+2)
+	batch = append(batch, []interface{}{"MULTI"})
+	batch = append(batch, cmds...)
+	batch = append(batch, []interface{}{"EXEC"})
+
+	replies, err := c.roundTrip(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range replies {
+		if e, ok := r.(Error); ok {
+			return nil, e
+		}
+	}
+	results, ok := replies[len(replies)-1].([]interface{})
+	if !ok {
+		return nil, Error("transaction aborted")
+	}
+	for _, r := range results {
+		if e, ok := r.(Error); ok {
+			return nil, e
+		}
+	}
+	return results, nil
+}
+
+// Close closes idle connections. Connections in use are closed when
+// released.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	for _, cn := range c.idle {
+		cn.Close()
+	}
+	c.idle = nil
+	return nil
+}
+
+// roundTrip pipelines the commands on one connection and reads one reply
+// per command. A connection that fails mid-way is discarded.
+func (c *Client) roundTrip(ctx context.Context, cmds [][]interface{}) ([]interface{}, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(c.cfg.IOTimeout)
+	}
+	cn.SetDeadline(deadline)
+
+	replies, err := cn.exchange(cmds)
+	if err != nil {
+		cn.Close()
+		return nil, err
+	}
+	c.put(cn)
+	return replies, nil
+}
+
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, ErrClosed
+	}
+	if n := len(c.idle); n > 0 {
+		cn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return cn, nil
+	}
+	c.mu.Unlock()
+	return c.dial(ctx)
+}
+
+func (c *Client) put(cn *conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || len(c.idle) >= c.cfg.PoolSize {
+		cn.Close()
+		return
+	}
+	c.idle = append(c.idle, cn)
+}
+
+func (c *Client) dial(ctx context.Context) (*conn, error) {
+	d := net.Dialer{Timeout: c.cfg.DialTimeout}
+	nc, err := d.DialContext(ctx, "tcp", c.cfg.Addr)
+	if err != nil {
+		return nil, err
+	}
+	cn := &conn{Conn: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}
+
+	var setup [][]interface{}
+	if c.cfg.Password != "" {
+		setup = append(setup, []interface{}{"AUTH", c.cfg.Password})
+	}
+	if c.cfg.DB != 0 {
+		setup = append(setup, []interface{}{"SELECT", c.cfg.DB})
+	}
+	if len(setup) == 0 {
+		return cn, nil
+	}
+
+	cn.SetDeadline(time.Now().Add(c.cfg.IOTimeout))
+	replies, err := cn.exchange(setup)
+	if err == nil {
+		for _, r := range replies {
+			if e, ok := r.(Error); ok {
+				err = e
+				break
+			}
+		}
+	}
+	if err != nil {
+		cn.Close()
+		return nil, err
+	}
+	return cn, nil
+}
+
+func (cn *conn) exchange(cmds [][]interface{}) ([]interface{}, error) {
+	for _, args := range cmds {
+		if err := writeCommand(cn.w, args); err != nil {
+			return nil, err
+		}
+	}
+	if err := cn.w.Flush(); err != nil {
+		return nil, err
+	}
+	replies := make([]interface{}, len(cmds))
+	for i := range cmds {
+		r, err := ReadReply(cn.r)
+		if err != nil {
+			return nil, err
+		}
+		replies[i] = r
+	}
+	return replies, nil
+}
+
+// writeCommand writes args as a RESP array of bulk strings.
+func writeCommand(w *bufio.Writer, args []interface{}) error {
+	fmt.Fprintf(w, "*%d\r\n", len(args))
+	for _, a := range args {
+		var s string
+		switch v := a.(type) {
+		case string:
+			s = v
+		case []byte:
+			s = string(v)
+		case int:
+			s = strconv.Itoa(v)
+		case int64:
+			s = strconv.FormatInt(v, 10)
+		case float64:
+			s = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return fmt.Errorf("redis: unsupported argument type %T", a)
+		}
+		if _, err := fmt.Fprintf(w, "$%d\r\n%s\r\n", len(s), s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadReply reads one RESP2 reply. Error replies are returned as an Error
+// value, not as the error result.
+func ReadReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return Error(line[1:]), nil
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = ReadReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("redis: malformed line %q", line)
+	}
+	return line[:len(line)-2], nil
+}
@@ -0,0 +1,107 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/clinova/simrs/backend/pkg/redis"
+	"github.com/clinova/simrs/backend/pkg/redis/redistest"
+)
+
+func newServer(t *testing.T, password string) *redistest.Server {
+	t.Helper()
+	srv, err := redistest.NewServer(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClientAuthAndDo(t *testing.T) {
+	srv := newServer(t, "rahasia")
+	ctx := context.Background()
+
+	client := redis.NewClient(srv.Config())
+	defer client.Close()
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	if _, err := client.Do(ctx, "ZADD", "k", 1, "a"); err != nil {
+		t.Fatalf("ZADD: %v", err)
+	}
+	reply, err := client.Do(ctx, "ZCARD", "k")
+	if err != nil || reply != int64(1) {
+		t.Fatalf("ZCARD = %v, %v; want 1", reply, err)
+	}
+
+	var replyErr redis.Error
+	if _, err := client.Do(ctx, "NOPE"); !errors.As(err, &replyErr) {
+		t.Fatalf("unknown command: got %v, want an error reply", err)
+	}
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("connection unusable after an error reply: %v", err)
+	}
+
+	wrong := srv.Config()
+	wrong.Password = "salah"
+	bad := redis.NewClient(wrong)
+	defer bad.Close()
+	if err := bad.Ping(ctx); !errors.As(err, &replyErr) {
+		t.Fatalf("wrong password: got %v, want an error reply", err)
+	}
+}
+
+func TestClientTx(t *testing.T) {
+	srv := newServer(t, "")
+	ctx := context.Background()
+	client := redis.NewClient(srv.Config())
+	defer client.Close()
+
+	results, err := client.Tx(ctx,
+		[]interface{}{"ZADD", "k", 1, "a", 2, "b"},
+		[]interface{}{"ZREM", "k", "a"},
+		[]interface{}{"ZCARD", "k"},
+		[]interface{}{"PEXPIRE", "k", 1000},
+	)
+	if err != nil {
+		t.Fatalf("Tx: %v", err)
+	}
+	if results[0] != int64(2) || results[1] != int64(1) || results[2] != int64(1) || results[3] != int64(1) {
+		t.Fatalf("Tx results = %v", results)
+	}
+
+	srv.FastForward(2 * time.Second)
+	if n := srv.Keys(); n != 0 {
+		t.Fatalf("%d keys left after expiry, want 0", n)
+	}
+
+	if _, err := client.Tx(ctx, []interface{}{"ZADD", "k", "x", "a"}); err == nil {
+		t.Fatal("Tx with a bad score succeeded")
+	}
+}
+
+func TestClientPingRetry(t *testing.T) {
+	srv := newServer(t, "")
+	cfg := srv.Config()
+	srv.Close()
+
+	client := redis.NewClient(cfg)
+	defer client.Close()
+	start := time.Now()
+	if err := client.PingRetry(context.Background(), 3, 10*time.Millisecond); err == nil {
+		t.Fatal("PingRetry succeeded against a closed server")
+	}
+	// Two waits: 10ms and 20ms
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Fatalf("PingRetry returned after %s, want at least 30ms of backoff", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := client.PingRetry(ctx, 3, time.Hour); err == nil {
+		t.Fatal("PingRetry succeeded with a cancelled context")
+	}
+}
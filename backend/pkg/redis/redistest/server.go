@@ -0,0 +1,450 @@
+// Package redistest provides an in-process, miniredis-style RESP server
+// for exercising redis.Client and the rate limit store without a real
+// Redis. It supports the sorted set and key commands the backend uses,
+// plus MULTI/EXEC and AUTH.
+package redistest
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clinova/simrs/backend/pkg/redis"
+)
+
+type key struct {
+	zset     map[string]float64
+	expireAt time.Time // Zero when the key does not expire
+}
+
+// Server is a fake Redis server listening on a loopback port.
+type Server struct {
+	ln       net.Listener
+	password string
+
+	mu     sync.Mutex
+	keys   map[string]*key
+	conns  map[net.Conn]bool
+	offset time.Duration // Added to the wall clock by FastForward
+	wg     sync.WaitGroup
+}
+
+// NewServer starts a fake server. A non-empty password requires AUTH.
+func NewServer(password string) (*Server, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{ln: ln, password: password, keys: map[string]*key{}, conns: map[net.Conn]bool{}}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the host:port of the server.
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// Config returns a client config pointing at the server.
+func (s *Server) Config() redis.Config {
+	return redis.Config{Addr: s.Addr(), Password: s.password}
+}
+
+// Close stops the server and drops client connections.
+func (s *Server) Close() {
+	s.ln.Close()
+	s.mu.Lock()
+	for nc := range s.conns {
+		nc.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// FastForward moves the server clock, expiring keys whose TTL has passed.
+// Scores written by clients are not affected.
+func (s *Server) FastForward(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offset += d
+}
+
+// Keys returns the number of live keys.
+func (s *Server) Keys() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for name := range s.keys {
+		if s.lookup(name) != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		nc, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[nc] = true
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go s.handle(nc)
+	}
+}
+
+type session struct {
+	authed bool
+	queue  [][]string // Commands queued after MULTI
+	inTx   bool
+}
+
+func (s *Server) handle(nc net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, nc)
+		s.mu.Unlock()
+		nc.Close()
+	}()
+
+	r := bufio.NewReader(nc)
+	w := bufio.NewWriter(nc)
+	sess := &session{authed: s.password == ""}
+	for {
+		reply, err := redis.ReadReply(r)
+		if err != nil {
+			return
+		}
+		items, ok := reply.([]interface{})
+		if !ok || len(items) == 0 {
+			writeReply(w, redis.Error("ERR protocol error"))
+			w.Flush()
+			continue
+		}
+		args := make([]string, len(items))
+		for i, it := range items {
+			args[i] = fmt.Sprint(it)
+		}
+		writeReply(w, s.dispatch(sess, args))
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) dispatch(sess *session, args []string) interface{} {
+	name := strings.ToUpper(args[0])
+	if name == "AUTH" {
+		if len(args) != 2 {
+			return wrongArgs(name)
+		}
+		if s.password == "" || args[1] != s.password {
+			return redis.Error("WRONGPASS invalid username-password pair")
+		}
+		sess.authed = true
+		return "OK"
+	}
+	if !sess.authed {
+		return redis.Error("NOAUTH Authentication required.")
+	}
+
+	switch name {
+	case "MULTI":
+		if sess.inTx {
+			return redis.Error("ERR MULTI calls can not be nested")
+		}
+		sess.inTx, sess.queue = true, nil
+		return "OK"
+	case "DISCARD":
+		if !sess.inTx {
+			return redis.Error("ERR DISCARD without MULTI")
+		}
+		sess.inTx, sess.queue = false, nil
+		return "OK"
+	case "EXEC":
+		if !sess.inTx {
+			return redis.Error("ERR EXEC without MULTI")
+		}
+		queue := sess.queue
+		sess.inTx, sess.queue = false, nil
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		results := make([]interface{}, len(queue))
+		for i, cmd := range queue {
+			results[i] = s.exec(cmd)
+		}
+		return results
+	}
+
+	if sess.inTx {
+		sess.queue = append(sess.queue, args)
+		return "QUEUED"
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.exec(args)
+}
+
+// exec runs one data command. The caller holds s.mu.
+func (s *Server) exec(args []string) interface{} {
+	name := strings.ToUpper(args[0])
+	switch name {
+	case "PING":
+		return "PONG"
+
+	case "SELECT":
+		if len(args) != 2 {
+			return wrongArgs(name)
+		}
+		return "OK"
+
+	case "DEL":
+		if len(args) < 2 {
+			return wrongArgs(name)
+		}
+		var n int64
+		for _, k := range args[1:] {
+			if s.lookup(k) != nil {
+				n++
+			}
+			delete(s.keys, k)
+		}
+		return n
+
+	case "ZADD":
+		if len(args) < 4 || len(args)%2 != 0 {
+			return wrongArgs(name)
+		}
+		k := s.lookup(args[1])
+		if k == nil {
+			k = &key{zset: map[string]float64{}}
+			s.keys[args[1]] = k
+		}
+		var added int64
+		for i := 2; i < len(args); i += 2 {
+			score, err := strconv.ParseFloat(args[i], 64)
+			if err != nil {
+				return redis.Error("ERR value is not a valid float")
+			}
+			if _, exists := k.zset[args[i+1]]; !exists {
+				added++
+			}
+			k.zset[args[i+1]] = score
+		}
+		return added
+
+	case "ZREM":
+		if len(args) < 3 {
+			return wrongArgs(name)
+		}
+		k := s.lookup(args[1])
+		if k == nil {
+			return int64(0)
+		}
+		var n int64
+		for _, member := range args[2:] {
+			if _, ok := k.zset[member]; ok {
+				delete(k.zset, member)
+				n++
+			}
+		}
+		if len(k.zset) == 0 {
+			delete(s.keys, args[1])
+		}
+		return n
+
+	case "ZCARD":
+		if len(args) != 2 {
+			return wrongArgs(name)
+		}
+		if k := s.lookup(args[1]); k != nil {
+			return int64(len(k.zset))
+		}
+		return int64(0)
+
+	case "ZCOUNT", "ZREMRANGEBYSCORE":
+		if len(args) != 4 {
+			return wrongArgs(name)
+		}
+		min, minEx, err1 := parseBound(args[2])
+		max, maxEx, err2 := parseBound(args[3])
+		if err1 != nil || err2 != nil {
+			return redis.Error("ERR min or max is not a float")
+		}
+		k := s.lookup(args[1])
+		if k == nil {
+			return int64(0)
+		}
+		var n int64
+		for member, score := range k.zset {
+			if inRange(score, min, minEx, max, maxEx) {
+				n++
+				if name == "ZREMRANGEBYSCORE" {
+					delete(k.zset, member)
+				}
+			}
+		}
+		if len(k.zset) == 0 {
+			delete(s.keys, args[1])
+		}
+		return n
+
+	case "ZRANGE":
+		if len(args) != 4 {
+			return wrongArgs(name)
+		}
+		k := s.lookup(args[1])
+		if k == nil {
+			return []interface{}{}
+		}
+		members := make([]string, 0, len(k.zset))
+		for m := range k.zset {
+			members = append(members, m)
+		}
+		sort.Slice(members, func(i, j int) bool {
+			if k.zset[members[i]] != k.zset[members[j]] {
+				return k.zset[members[i]] < k.zset[members[j]]
+			}
+			return members[i] < members[j]
+		})
+		start, err1 := strconv.Atoi(args[2])
+		stop, err2 := strconv.Atoi(args[3])
+		if err1 != nil || err2 != nil {
+			return redis.Error("ERR value is not an integer or out of range")
+		}
+		start, stop = clampRange(start, stop, len(members))
+		out := []interface{}{}
+		for i := start; i <= stop; i++ {
+			out = append(out, members[i])
+		}
+		return out
+
+	case "PEXPIRE", "EXPIRE":
+		if len(args) != 3 {
+			return wrongArgs(name)
+		}
+		n, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return redis.Error("ERR value is not an integer or out of range")
+		}
+		k := s.lookup(args[1])
+		if k == nil {
+			return int64(0)
+		}
+		unit := time.Millisecond
+		if name == "EXPIRE" {
+			unit = time.Second
+		}
+		k.expireAt = s.now().Add(time.Duration(n) * unit)
+		return int64(1)
+
+	case "PTTL":
+		if len(args) != 2 {
+			return wrongArgs(name)
+		}
+		k := s.lookup(args[1])
+		if k == nil {
+			return int64(-2)
+		}
+		if k.expireAt.IsZero() {
+			return int64(-1)
+		}
+		return k.expireAt.Sub(s.now()).Milliseconds()
+	}
+	return redis.Error(fmt.Sprintf("ERR unknown command '%s'", args[0]))
+}
+
+func (s *Server) now() time.Time {
+	return time.Now().Add(s.offset)
+}
+
+// lookup returns a live key, dropping it when expired. The caller holds s.mu.
+func (s *Server) lookup(name string) *key {
+	k, ok := s.keys[name]
+	if !ok {
+		return nil
+	}
+	if !k.expireAt.IsZero() && !s.now().Before(k.expireAt) {
+		delete(s.keys, name)
+		return nil
+	}
+	return k
+}
+
+func parseBound(v string) (float64, bool, error) {
+	exclusive := strings.HasPrefix(v, "(")
+	v = strings.TrimPrefix(v, "(")
+	switch strings.ToLower(v) {
+	case "-inf":
+		return math.Inf(-1), exclusive, nil
+	case "+inf", "inf":
+		return math.Inf(1), exclusive, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	return f, exclusive, err
+}
+
+func inRange(score, min float64, minEx bool, max float64, maxEx bool) bool {
+	if score < min || (minEx && score == min) {
+		return false
+	}
+	if score > max || (maxEx && score == max) {
+		return false
+	}
+	return true
+}
+
+func clampRange(start, stop, n int) (int, int) {
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+	return start, stop
+}
+
+func wrongArgs(name string) redis.Error {
+	return redis.Error(fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(name)))
+}
+
+func writeReply(w *bufio.Writer, v interface{}) {
+	switch r := v.(type) {
+	case nil:
+		w.WriteString("$-1\r\n")
+	case string:
+		if r == "OK" || r == "PONG" || r == "QUEUED" {
+			fmt.Fprintf(w, "+%s\r\n", r)
+		} else {
+			fmt.Fprintf(w, "$%d\r\n%s\r\n", len(r), r)
+		}
+	case int64:
+		fmt.Fprintf(w, ":%d\r\n", r)
+	case redis.Error:
+		fmt.Fprintf(w, "-%s\r\n", string(r))
+	case []interface{}:
+		fmt.Fprintf(w, "*%d\r\n", len(r))
+		for _, item := range r {
+			writeReply(w, item)
+		}
+	}
+}
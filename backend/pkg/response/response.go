@@ -15,8 +15,9 @@ type Response struct {
 }
 
 type ErrorInfo struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
 }
 
 const (
@@ -29,6 +30,12 @@ const (
 	ErrCodePermissionDenied   = "PERMISSION_DENIED"
 	ErrCodeValidationError    = "VALIDATION_ERROR"
 	ErrCodeInternalError      = "INTERNAL_ERROR"
+	ErrCodeAccountLocked      = "ACCOUNT_LOCKED"
+	ErrCodeInvalidMFACode     = "INVALID_MFA_CODE"
+	ErrCodeMFAUnavailable     = "MFA_UNAVAILABLE"
+	ErrCodeMFAState           = "MFA_STATE_CONFLICT"
+	ErrCodePasswordPolicy     = "PASSWORD_POLICY_VIOLATION"
+	ErrCodeRefreshTokenReused = "REFRESH_TOKEN_REUSED"
 )
 
 func Success(c *gin.Context, data interface{}) {
@@ -47,6 +54,10 @@ func Error(c *gin.Context, statusCode int, code, message string) {
 	c.JSON(statusCode, Response{Success: false, Error: &ErrorInfo{Code: code, Message: message}})
 }
 
+func ErrorWithDetails(c *gin.Context, statusCode int, code, message string, details interface{}) {
+	c.JSON(statusCode, Response{Success: false, Error: &ErrorInfo{Code: code, Message: message, Details: details}})
+}
+
 func BadRequest(c *gin.Context, code, message string) {
 	Error(c, http.StatusBadRequest, code, message)
 }
@@ -0,0 +1,66 @@
+// Package secret provides AES-GCM encryption for sensitive setting values.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+)
+
+var (
+	ErrNoKey         = errors.New("settings encryption key is not configured")
+	ErrInvalidSecret = errors.New("invalid encrypted value")
+)
+
+// Cipher encrypts and decrypts values stored with value_encrypted = 1.
+// Ciphertext format: base64(nonce || AES-256-GCM sealed data).
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher derives an AES-256 key from the configured passphrase.
+func NewCipher(passphrase string) (*Cipher, error) {
+	if passphrase == "" {
+		return nil, ErrNoKey
+	}
+
+	key := sha256.Sum256([]byte(passphrase))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *Cipher) Decrypt(encoded string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrInvalidSecret
+	}
+	size := c.aead.NonceSize()
+	if len(raw) < size {
+		return "", ErrInvalidSecret
+	}
+	plain, err := c.aead.Open(nil, raw[:size], raw[size:], nil)
+	if err != nil {
+		return "", ErrInvalidSecret
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// LocalStore keeps documents on the local filesystem under a root directory.
+type LocalStore struct {
+	root string
+}
+
+// NewLocalStore creates a store rooted at root. The directory is created on
+// first write if it does not exist.
+func NewLocalStore(root string) (*LocalStore, error) {
+	if root == "" {
+		return nil, errors.New("local document storage root is required")
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid local document storage root: %w", err)
+	}
+	return &LocalStore{root: abs}, nil
+}
+
+// Root returns the absolute root directory.
+func (s *LocalStore) Root() string {
+	return s.root
+}
+
+func (s *LocalStore) path(key string) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes the document to a temporary file and renames it into place so
+// readers never see a partial file.
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return fmt.Errorf("failed to create document directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create document: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write document: %w", err)
+	}
+	if size >= 0 && written != size {
+		return fmt.Errorf("failed to write document: wrote %d of %d bytes", written, size)
+	}
+
+	// Match the permissions of files written by the legacy PHP uploader
+	os.Chmod(tmp.Name(), 0o644)
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("failed to store document: %w", err)
+	}
+	return nil
+}
+
+// Get opens the document file.
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open document: %w", err)
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("failed to open document: %w", err)
+	}
+	if st.IsDir() {
+		f.Close()
+		return nil, nil, ErrNotFound
+	}
+
+	return f, &ObjectInfo{
+		Key:         key,
+		Size:        st.Size(),
+		ContentType: mime.TypeByExtension(path.Ext(key)),
+		ModTime:     st.ModTime(),
+	}, nil
+}
+
+// Delete removes the document file.
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Package storage provides backends for uploaded claim documents.
+//
+// Documents are addressed by slash-separated keys relative to the store
+// root, e.g. "pages/upload/2026_01_01_000001_001_ktp.pdf", which is the
+// value kept in berkas_digital_perawatan.lokasi_file by the legacy system.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound indicates the key does not exist in the store.
+	ErrNotFound = errors.New("document not found in storage")
+	// ErrInvalidKey indicates a key that is empty, absolute or escapes the root.
+	ErrInvalidKey = errors.New("invalid document key")
+)
+
+// ObjectInfo describes a stored document.
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// DocumentStore stores uploaded claim documents.
+type DocumentStore interface {
+	// Put stores size bytes from r under key, replacing any existing document.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the document stored under key. The caller closes the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	// Delete removes the document stored under key.
+	Delete(ctx context.Context, key string) error
+}
+
+// Config selects and configures a DocumentStore.
+type Config struct {
+	Driver    string // local or s3
+	LocalRoot string // local: directory that holds pages/upload/...
+	S3        S3Config
+}
+
+// New creates the DocumentStore selected by cfg.Driver.
+func New(cfg Config) (DocumentStore, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocalStore(cfg.LocalRoot)
+	case "s3":
+		return NewS3Store(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown document storage driver %q", cfg.Driver)
+	}
+}
+
+// CleanKey validates key and returns it in canonical form.
+func CleanKey(key string) (string, error) {
+	key = strings.ReplaceAll(key, "\\", "/")
+	if key == "" || strings.HasPrefix(key, "/") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+	return path.Clean(key), nil
+}
@@ -0,0 +1,148 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/clinova/simrs/backend/pkg/storage"
+	"github.com/clinova/simrs/backend/pkg/storage/storagetest"
+)
+
+// testStore runs the DocumentStore contract against store.
+func testStore(t *testing.T, store storage.DocumentStore) {
+	ctx := context.Background()
+	key := "pages/upload/2026_01_01_000001_001_ktp.pdf"
+	data := []byte("%PDF-1.4 test document")
+
+	if err := store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), "application/pdf"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	rc, info, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	got, _ := io.ReadAll(rc)
+	rc.Close()
+	if !bytes.Equal(got, data) {
+		t.Fatalf("Get() = %q, want %q", got, data)
+	}
+	if info.Size != int64(len(data)) || info.ContentType != "application/pdf" || info.ModTime.IsZero() {
+		t.Fatalf("Get() info = %+v", info)
+	}
+
+	// Put replaces an existing document
+	replaced := []byte("%PDF-1.4 replaced")
+	if err := store.Put(ctx, key, bytes.NewReader(replaced), int64(len(replaced)), "application/pdf"); err != nil {
+		t.Fatalf("Put() replace error = %v", err)
+	}
+	rc, _, _ = store.Get(ctx, key)
+	got, _ = io.ReadAll(rc)
+	rc.Close()
+	if !bytes.Equal(got, replaced) {
+		t.Fatalf("Get() after replace = %q, want %q", got, replaced)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, _, err := store.Get(ctx, key); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("Get() after delete error = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, key); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("Delete() of missing key error = %v, want ErrNotFound", err)
+	}
+
+	for _, bad := range []string{"", "/etc/passwd", "../secret.pdf", "pages/../../secret.pdf"} {
+		if err := store.Put(ctx, bad, strings.NewReader("x"), 1, ""); !errors.Is(err, storage.ErrInvalidKey) {
+			t.Fatalf("Put(%q) error = %v, want ErrInvalidKey", bad, err)
+		}
+		if _, _, err := store.Get(ctx, bad); !errors.Is(err, storage.ErrInvalidKey) {
+			t.Fatalf("Get(%q) error = %v, want ErrInvalidKey", bad, err)
+		}
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore() error = %v", err)
+	}
+	testStore(t, store)
+}
+
+func TestLocalStoreRejectsShortWrite(t *testing.T) {
+	store, _ := storage.NewLocalStore(t.TempDir())
+	ctx := context.Background()
+
+	err := store.Put(ctx, "pages/upload/short.pdf", strings.NewReader("abc"), 10, "application/pdf")
+	if err == nil {
+		t.Fatal("Put() with size larger than the body succeeded")
+	}
+	if _, _, err := store.Get(ctx, "pages/upload/short.pdf"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("Get() after failed Put error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	srv := storagetest.NewServer("berkas", "AKIATEST", "secret")
+	defer srv.Close()
+
+	store, err := storage.NewS3Store(srv.Config())
+	if err != nil {
+		t.Fatalf("NewS3Store() error = %v", err)
+	}
+	testStore(t, store)
+}
+
+func TestS3StorePrefix(t *testing.T) {
+	srv := storagetest.NewServer("berkas", "AKIATEST", "secret")
+	defer srv.Close()
+
+	cfg := srv.Config()
+	cfg.Prefix = "berkasrawat/"
+	store, _ := storage.NewS3Store(cfg)
+
+	data := []byte("scan")
+	if err := store.Put(context.Background(), "pages/upload/a b.jpg", bytes.NewReader(data), int64(len(data)), "image/jpeg"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got, ok := srv.Object("berkasrawat/pages/upload/a b.jpg"); !ok || !bytes.Equal(got, data) {
+		t.Fatalf("object under prefix = %q, %v", got, ok)
+	}
+}
+
+func TestS3StoreBadCredentials(t *testing.T) {
+	srv := storagetest.NewServer("berkas", "AKIATEST", "secret")
+	defer srv.Close()
+
+	cfg := srv.Config()
+	cfg.SecretKey = "wrong"
+	store, _ := storage.NewS3Store(cfg)
+
+	err := store.Put(context.Background(), "pages/upload/x.pdf", strings.NewReader("x"), 1, "application/pdf")
+	var s3Err *storage.S3Error
+	if !errors.As(err, &s3Err) || s3Err.Code != "SignatureDoesNotMatch" {
+		t.Fatalf("Put() error = %v, want SignatureDoesNotMatch", err)
+	}
+	if srv.Keys() != 0 {
+		t.Fatalf("server stored %d objects, want 0", srv.Keys())
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := storage.New(storage.Config{Driver: "ftp"}); err == nil {
+		t.Fatal("New() accepted an unknown driver")
+	}
+	store, err := storage.New(storage.Config{LocalRoot: t.TempDir()})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, ok := store.(*storage.LocalStore); !ok {
+		t.Fatalf("New() default driver = %T, want *LocalStore", store)
+	}
+}
@@ -0,0 +1,153 @@
+// Package storagetest provides an in-memory, MinIO-style S3 server for
+// exercising storage.S3Store without a real object store.
+package storagetest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clinova/simrs/backend/pkg/storage"
+)
+
+type object struct {
+	data        []byte
+	contentType string
+	modTime     time.Time
+}
+
+// Server is a fake path-style S3 endpoint holding one bucket. It verifies
+// Signature Version 4 on every request.
+type Server struct {
+	*httptest.Server
+
+	bucket    string
+	accessKey string
+	secretKey string
+	region    string
+
+	mu      sync.Mutex
+	objects map[string]object
+}
+
+// NewServer starts a fake S3 server for bucket.
+func NewServer(bucket, accessKey, secretKey string) *Server {
+	s := &Server{
+		bucket:    bucket,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		region:    "us-east-1",
+		objects:   map[string]object{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Config returns an S3Config pointing at the server.
+func (s *Server) Config() storage.S3Config {
+	return storage.S3Config{
+		Endpoint:  s.URL,
+		Region:    s.region,
+		Bucket:    s.bucket,
+		AccessKey: s.accessKey,
+		SecretKey: s.secretKey,
+		PathStyle: true,
+	}
+}
+
+// Object returns the stored bytes of key (including any prefix).
+func (s *Server) Object(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.objects[key]
+	return o.data, ok
+}
+
+// Keys returns the number of stored objects.
+func (s *Server) Keys() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.objects)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if code, msg := s.verify(r); code != "" {
+		writeError(w, http.StatusForbidden, code, msg)
+		return
+	}
+
+	prefix := "/" + s.bucket + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		writeError(w, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, prefix)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+			return
+		}
+		s.objects[key] = object{data: data, contentType: r.Header.Get("Content-Type"), modTime: time.Now().UTC()}
+		w.WriteHeader(http.StatusOK)
+
+	case http.MethodGet, http.MethodHead:
+		o, ok := s.objects[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		if o.contentType != "" {
+			w.Header().Set("Content-Type", o.contentType)
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(o.data)))
+		w.Header().Set("Last-Modified", o.modTime.Format(http.TimeFormat))
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			w.Write(o.data)
+		}
+
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed")
+	}
+}
+
+// verify recomputes the request signature with the shared secret.
+func (s *Server) verify(r *http.Request) (string, string) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential="+s.accessKey+"/") {
+		return "InvalidAccessKeyId", "The access key ID you provided does not exist in our records."
+	}
+	t, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return "AccessDenied", "X-Amz-Date is missing or invalid"
+	}
+
+	check := r.Clone(r.Context())
+	check.URL.Host = r.Host
+	check.Body = nil
+	storage.SignV4(check, s.region, s.accessKey, s.secretKey, t)
+	if check.Header.Get("Authorization") != auth {
+		return "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided."
+	}
+	return "", ""
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code><Message>%s</Message></Error>`, code, message)
+}
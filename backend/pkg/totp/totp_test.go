@@ -0,0 +1,100 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 seed from RFC 6238 appendix B ("12345678901234567890").
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFC6238Vectors(t *testing.T) {
+	// The RFC lists 8-digit codes; these are their last 6 digits.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("T=%d: %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("T=%d: got %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestCodeAcceptsPaddedLowercaseSecret(t *testing.T) {
+	got, err := Code("gezdgnbvgy3tqojqgezdgnbvgy3tqojq====", Step(time.Unix(59, 0)))
+	if err != nil || got != "287082" {
+		t.Fatalf("got %q, %v; want 287082", got, err)
+	}
+	if _, err := Code("not base32!", 1); err != ErrInvalidSecret {
+		t.Fatalf("invalid secret: got %v, want ErrInvalidSecret", err)
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	tests := []struct {
+		offset int64
+		ok     bool
+	}{
+		{-2, false},
+		{-1, true},
+		{0, true},
+		{1, true},
+		{2, false},
+	}
+	for _, tt := range tests {
+		code, err := Code(rfcSecret, current+tt.offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		step, ok := Validate(rfcSecret, code, now)
+		if ok != tt.ok {
+			t.Errorf("offset %d: got ok=%v, want %v", tt.offset, ok, tt.ok)
+			continue
+		}
+		if ok && step != current+tt.offset {
+			t.Errorf("offset %d: matched step %d, want %d", tt.offset, step, current+tt.offset)
+		}
+	}
+}
+
+func TestValidateRejectsMalformedCodes(t *testing.T) {
+	now := time.Unix(59, 0)
+	for _, code := range []string{"", "28708", "2870820", "94287082"} {
+		if _, ok := Validate(rfcSecret, code, now); ok {
+			t.Errorf("code %q accepted", code)
+		}
+	}
+	if _, ok := Validate(rfcSecret, " 287082 ", now); !ok {
+		t.Error("code with surrounding spaces rejected")
+	}
+}
+
+func TestGenerateSecretRoundTrip(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	code, err := Code(secret, Step(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Validate(secret, code, now); !ok {
+		t.Fatal("code for a generated secret rejected")
+	}
+}
@@ -0,0 +1,260 @@
+// Package xlsx writes single-sheet Office Open XML spreadsheets.
+//
+// Rows are streamed straight into the zip archive, so arbitrarily large
+// exports never need to be buffered in memory.
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrClosed is returned when writing to a closed Writer.
+var ErrClosed = errors.New("xlsx: writer closed")
+
+// Cell styles, indexes into cellXfs of styles.xml.
+const (
+	styleDefault = 0
+	styleHeader  = 1
+	styleNumber  = 2
+	styleDate    = 3
+)
+
+// Writer streams rows into a single worksheet.
+type Writer struct {
+	zw     *zip.Writer
+	sheet  *bufio.Writer
+	row    int
+	closed bool
+}
+
+// NewWriter starts a workbook with one sheet named sheetName.
+func NewWriter(w io.Writer, sheetName string) (*Writer, error) {
+	zw := zip.NewWriter(w)
+
+	static := []struct{ name, body string }{
+		{"[Content_Types].xml", contentTypes},
+		{"_rels/.rels", rootRels},
+		{"xl/workbook.xml", fmt.Sprintf(workbook, escape(sheetTitle(sheetName)))},
+		{"xl/_rels/workbook.xml.rels", workbookRels},
+		{"xl/styles.xml", styles},
+	}
+	for _, f := range static {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(fw, f.body); err != nil {
+			return nil, err
+		}
+	}
+
+	fw, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	sheet := bufio.NewWriter(fw)
+	sheet.WriteString(sheetHeader)
+
+	return &Writer{zw: zw, sheet: sheet}, nil
+}
+
+// WriteHeader writes a bold row of column titles.
+func (w *Writer) WriteHeader(titles ...string) error {
+	cells := make([]interface{}, len(titles))
+	for i, t := range titles {
+		cells[i] = t
+	}
+	return w.writeRow(cells, styleHeader)
+}
+
+// WriteRow writes one row. Supported cell values are string, bool, the Go
+// integer and float types (written as numbers) and time.Time (written as a
+// date); nil leaves the cell empty and anything else is formatted with %v.
+func (w *Writer) WriteRow(cells ...interface{}) error {
+	return w.writeRow(cells, styleDefault)
+}
+
+func (w *Writer) writeRow(cells []interface{}, style int) error {
+	if w.closed {
+		return ErrClosed
+	}
+	w.row++
+
+	b := w.sheet
+	fmt.Fprintf(b, `<row r="%d">`, w.row)
+	for i, v := range cells {
+		if v == nil {
+			continue
+		}
+		ref := columnName(i) + strconv.Itoa(w.row)
+
+		var number string
+		switch x := v.(type) {
+		case string:
+			writeString(b, ref, x, style)
+			continue
+		case bool:
+			n := "0"
+			if x {
+				n = "1"
+			}
+			fmt.Fprintf(b, `<c r="%s" t="b"><v>%s</v></c>`, ref, n)
+			continue
+		case time.Time:
+			if x.IsZero() {
+				continue
+			}
+			fmt.Fprintf(b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, styleDate, strconv.FormatFloat(serialDate(x), 'f', -1, 64))
+			continue
+		case int:
+			number = strconv.Itoa(x)
+		case int32:
+			number = strconv.FormatInt(int64(x), 10)
+		case int64:
+			number = strconv.FormatInt(x, 10)
+		case float32:
+			number = strconv.FormatFloat(float64(x), 'f', -1, 32)
+		case float64:
+			number = strconv.FormatFloat(x, 'f', -1, 64)
+		default:
+			writeString(b, ref, fmt.Sprint(v), style)
+			continue
+		}
+
+		s := style
+		if s == styleDefault {
+			s = styleNumber
+		}
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, s, number)
+	}
+	b.WriteString(`</row>`)
+	return nil
+}
+
+// Close finishes the sheet and the archive. It does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	w.sheet.WriteString(sheetFooter)
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+func writeString(b *bufio.Writer, ref, s string, style int) {
+	if style == styleDefault {
+		fmt.Fprintf(b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, escape(s))
+		return
+	}
+	fmt.Fprintf(b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, escape(s))
+}
+
+// columnName converts a zero-based index to A, B, ..., Z, AA, ...
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// serialDate converts t to an Excel serial date (days since 1899-12-30).
+func serialDate(t time.Time) float64 {
+	y, m, d := t.Date()
+	days := time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Sub(time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)).Hours() / 24
+	secs := t.Hour()*3600 + t.Minute()*60 + t.Second()
+	return days + float64(secs)/86400
+}
+
+// sheetTitle applies Excel's sheet name rules: at most 31 characters and
+// none of : \ / ? * [ ].
+func sheetTitle(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`:\/?*[]`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" {
+		name = "Sheet1"
+	}
+	if r := []rune(name); len(r) > 31 {
+		name = string(r[:31])
+	}
+	return name
+}
+
+// escape escapes text for XML and drops characters XML 1.0 cannot carry.
+func escape(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' || r >= 0x20 {
+			return r
+		}
+		return -1
+	}, s)))
+	return sb.String()
+}
+
+const contentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const rootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const workbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>` +
+	`</workbook>`
+
+const workbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// styles defines the cellXfs used above: default, bold header, #,##0.## and
+// yyyy-mm-dd hh:mm.
+const styles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="2"><numFmt numFmtId="164" formatCode="#,##0.##"/><numFmt numFmtId="165" formatCode="yyyy-mm-dd hh:mm"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="4">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`</styleSheet>`
+
+// sheetHeader freezes the first row so column titles stay visible.
+const sheetHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>` +
+	`<sheetData>`
+
+const sheetFooter = `</sheetData></worksheet>`
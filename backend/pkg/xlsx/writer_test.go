@@ -0,0 +1,182 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+type sheetXML struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Style  string `xml:"s,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readParts unzips a workbook and checks every part is well-formed XML.
+func readParts(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := xml.NewDecoder(bytes.NewReader(body))
+		for {
+			if _, err := d.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s is not well-formed: %v", f.Name, err)
+			}
+		}
+		parts[f.Name] = body
+	}
+	return parts
+}
+
+func TestWriterCells(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "Klaim")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteHeader("No Rawat", "Biaya"); err != nil {
+		t.Fatal(err)
+	}
+	noon := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	if err := w.WriteRow("A&B <x>\x01", 1500000.5, 42, int64(7), true, nil, noon, time.Time{}, struct{ N int }{3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow("late"); !errors.Is(err, ErrClosed) {
+		t.Fatalf("write after close: got %v, want ErrClosed", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("second close: %v", err)
+	}
+
+	parts := readParts(t, buf.Bytes())
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Fatalf("missing part %s", name)
+		}
+	}
+
+	var sheet sheetXML
+	if err := xml.Unmarshal(parts["xl/worksheets/sheet1.xml"], &sheet); err != nil {
+		t.Fatal(err)
+	}
+	if len(sheet.Rows) != 2 || sheet.Rows[0].R != 1 || sheet.Rows[1].R != 2 {
+		t.Fatalf("got rows %+v, want rows 1 and 2", sheet.Rows)
+	}
+
+	header := sheet.Rows[0].Cells
+	if len(header) != 2 || header[0].Inline != "No Rawat" || header[0].Style != "1" || header[1].Ref != "B1" {
+		t.Fatalf("header cells %+v", header)
+	}
+
+	type cell struct{ ref, typ, style, value string }
+	var got []cell
+	for _, c := range sheet.Rows[1].Cells {
+		v := c.Value
+		if c.Type == "inlineStr" {
+			v = c.Inline
+		}
+		got = append(got, cell{c.Ref, c.Type, c.Style, v})
+	}
+	want := []cell{
+		{"A2", "inlineStr", "", "A&B <x>"}, // Control characters are dropped
+		{"B2", "", "2", "1500000.5"},
+		{"C2", "", "2", "42"},
+		{"D2", "", "2", "7"},
+		{"E2", "b", "", "1"},
+		// F2 is nil and H2 a zero time, both left empty
+		{"G2", "", "3", "46023.5"},
+		{"I2", "inlineStr", "", "{3}"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got cells %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("cell %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWriterSheetName(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "Klaim [Jan/Feb] & seterusnya sampai akhir tahun")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var wb struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(readParts(t, buf.Bytes())["xl/workbook.xml"], &wb); err != nil {
+		t.Fatal(err)
+	}
+	if len(wb.Sheets) != 1 {
+		t.Fatalf("got %d sheets, want 1", len(wb.Sheets))
+	}
+	if got, want := wb.Sheets[0].Name, "Klaim _Jan_Feb_ & seterusnya sa"; got != want {
+		t.Fatalf("sheet name %q, want %q", got, want)
+	}
+	if got := sheetTitle(""); got != "Sheet1" {
+		t.Fatalf("empty sheet name became %q, want Sheet1", got)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %s, want %s", i, got, want)
+		}
+	}
+}
+
+func TestSerialDate(t *testing.T) {
+	tests := []struct {
+		t    time.Time
+		want float64
+	}{
+		{time.Date(1899, 12, 31, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 45292},
+		{time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC), 45292.25},
+		// Local wall time is kept, not converted to UTC
+		{time.Date(2024, 1, 1, 6, 0, 0, 0, time.FixedZone("WIB", 7*3600)), 45292.25},
+	}
+	for _, tt := range tests {
+		if got := serialDate(tt.t); got != tt.want {
+			t.Errorf("serialDate(%v) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+}